@@ -0,0 +1,167 @@
+package admin
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/apps"
+	"github.com/rimba47prayoga/gorim.git/conf"
+	"github.com/rimba47prayoga/gorim.git/errors"
+	"github.com/rimba47prayoga/gorim.git/interfaces"
+	"github.com/rimba47prayoga/gorim.git/permissions"
+	"gorm.io/gorm/schema"
+)
+
+// Site serves a data management API of models for internal tools, models are named by
+// table and get CRUD routes with search, ordering and filtering, example:
+// admin.Default.Mount(api.Group("/admin"))
+//
+//	GET		/					metadata of models and their fields
+//	GET		/:model				?search=, ?sort=-created_at, ?status=paid, ?total__gte=10
+//	POST	/:model
+//	GET		/:model/:pk
+//	PUT		/:model/:pk
+//	PATCH	/:model/:pk
+//	DELETE	/:model/:pk
+//
+// Filters are json or column names of fields with optional operator: gt, gte, lt, lte,
+// ne, contains, in of comma separated values and isnull of true or false. Writes skip
+// serializers and their signals, gorm hooks and signals.GormPlugin still run.
+type Site struct {
+	// Permissions default permissions.IsAdminUser, authentication of the group applies.
+	Permissions		[]interfaces.IPermission
+	Throttles		[]interfaces.IThrottle
+	// Models default models of registered apps, see apps.Models.
+	Models			[]interface{}
+	// HiddenFields are neither rendered nor written, by json or column name, default "password".
+	HiddenFields	[]string
+	mu				sync.RWMutex
+	models			map[string]*modelAdmin
+	names			[]string
+}
+
+// Default is the site mounted by projects created with startproject.
+var Default = &Site{}
+
+func (s *Site) GetPermissions() []interfaces.IPermission {
+	if s.Permissions != nil {
+		return s.Permissions
+	}
+	return []interfaces.IPermission{&permissions.IsAdminUser{}}
+}
+
+func (s *Site) GetHiddenFields() []string {
+	if s.HiddenFields != nil {
+		return s.HiddenFields
+	}
+	return []string{"password"}
+}
+
+// Mount registers models and adds routes of the site to group.
+func (s *Site) Mount(group *gorim.Group) {
+	s.register()
+	group.Add(http.MethodGet, "", s.serve(s.index))
+	group.Add(http.MethodGet, "/:model", s.serveModel(s.list))
+	group.Add(http.MethodPost, "/:model", s.serveModel(s.create))
+	group.Add(http.MethodGet, "/:model/:pk", s.serveModel(s.retrieve))
+	group.Add(http.MethodPut, "/:model/:pk", s.serveModel(s.update))
+	group.Add(http.MethodPatch, "/:model/:pk", s.serveModel(s.update))
+	group.Add(http.MethodDelete, "/:model/:pk", s.serveModel(s.destroy))
+}
+
+// register parses models, models of apps are labeled by the app.
+func (s *Site) register() {
+	models := s.Models
+	if models == nil {
+		models = apps.Models()
+	}
+	labels := map[reflect.Type]string{}
+	for _, app := range apps.All() {
+		for _, model := range app.Models {
+			labels[indirect(reflect.TypeOf(model))] = app.Name
+		}
+	}
+	namer := schema.Namer(schema.NamingStrategy{})
+	if conf.DB != nil {
+		namer = conf.DB.NamingStrategy
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.models = map[string]*modelAdmin{}
+	s.names = nil
+	for _, model := range models {
+		parsed, err := schema.Parse(model, &sync.Map{}, namer)
+		if err != nil {
+			panic(err)
+		}
+		if _, ok := s.models[parsed.Table]; ok {
+			continue
+		}
+		s.models[parsed.Table] = newModelAdmin(parsed, labels[parsed.ModelType], s.GetHiddenFields())
+		s.names = append(s.names, parsed.Table)
+	}
+}
+
+func (s *Site) model(name string) (*modelAdmin, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	model, ok := s.models[name]
+	return model, ok
+}
+
+// serve checks permissions and throttles of the site before handler.
+func (s *Site) serve(handler gorim.HandlerFunc) gorim.HandlerFunc {
+	return func(c gorim.Context) error {
+		for _, permission := range s.GetPermissions() {
+			if !permission.HasPermission(c) {
+				return permissions.Deny(c, permission)
+			}
+		}
+		for _, throttle := range s.Throttles {
+			if allowed, wait := throttle.AllowRequest(c); !allowed {
+				return errors.Handle(&errors.ThrottledError{Wait: wait}, c)
+			}
+		}
+		return handler(c)
+	}
+}
+
+func (s *Site) serveModel(handler func(gorim.Context, *modelAdmin) error) gorim.HandlerFunc {
+	return s.serve(func(c gorim.Context) error {
+		model, ok := s.model(c.Param("model"))
+		if !ok {
+			return errors.Handle(&errors.ObjectNotFoundError{Message: "Resource not found"}, c)
+		}
+		return handler(c, model)
+	})
+}
+
+// index lists metadata of models.
+func (s *Site) index(c gorim.Context) error {
+	s.mu.RLock()
+	models := make([]ModelInfo, 0, len(s.names))
+	for _, name := range s.names {
+		models = append(models, s.models[name].info)
+	}
+	s.mu.RUnlock()
+	return c.Respond(http.StatusOK, map[string]any{"models": models})
+}
+
+func indirect(typ reflect.Type) reflect.Type {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ
+}
+
+func contains(names []string, field *schema.Field, jsonName string) bool {
+	for _, name := range names {
+		if strings.EqualFold(name, jsonName) || name == field.DBName {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,270 @@
+package admin
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/conf"
+	"github.com/rimba47prayoga/gorim.git/errors"
+	"github.com/rimba47prayoga/gorim.git/i18n"
+	"github.com/rimba47prayoga/gorim.git/pagination"
+	"github.com/rimba47prayoga/gorim.git/renderers"
+	"github.com/rimba47prayoga/gorim.git/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// reserved query params aren't filters.
+func reserved(name string) bool {
+	switch name {
+	case "page", "page_size", "sort", "search", renderers.FormatParam, i18n.LanguageParam:
+		return true
+	}
+	return false
+}
+
+func (s *Site) list(c gorim.Context, model *modelAdmin) error {
+	ctx := c.Request().Context()
+	queryset := conf.GetReadDB(ctx).Model(model.new().Interface())
+	queryset, err := model.filter(c, queryset)
+	if err != nil {
+		return errors.Handle(err, c)
+	}
+	queryset = model.search(queryset, c.QueryParam("search"))
+	page := pagination.InitPagination(c, queryset)
+	if err := queryset.Session(&gorm.Session{}).Count(&page.TotalRows).Error; err != nil {
+		return errors.Handle(err, c)
+	}
+	page.TotalPages = int(math.Ceil(float64(page.TotalRows) / float64(page.GetLimit())))
+	queryset = model.order(queryset, c.QueryParam("sort"))
+	results := reflect.New(reflect.SliceOf(model.schema.ModelType))
+	err = queryset.Offset(page.GetOffset()).Limit(page.GetLimit()).Find(results.Interface()).Error
+	if err != nil {
+		return errors.Handle(err, c)
+	}
+	if page.Results, err = model.represent(results.Interface()); err != nil {
+		return errors.Handle(err, c)
+	}
+	return c.Respond(http.StatusOK, page)
+}
+
+func (s *Site) retrieve(c gorim.Context, model *modelAdmin) error {
+	instance, err := model.get(conf.GetReadDB(c.Request().Context()), c.Param("pk"))
+	if err != nil {
+		return errors.Handle(err, c)
+	}
+	data, err := model.represent(instance.Interface())
+	if err != nil {
+		return errors.Handle(err, c)
+	}
+	return c.Respond(http.StatusOK, data)
+}
+
+func (s *Site) create(c gorim.Context, model *modelAdmin) error {
+	body, err := model.body(c, true, true)
+	if err != nil {
+		return errors.Handle(err, c)
+	}
+	instance := model.new()
+	if err := json.Unmarshal(body, instance.Interface()); err != nil {
+		return errors.Handle(errors.BadRequest(err.Error()), c)
+	}
+	if err := conf.GetDB(c.Request().Context()).Create(instance.Interface()).Error; err != nil {
+		return errors.Handle(errors.BadRequest(err.Error()), c)
+	}
+	data, err := model.represent(instance.Interface())
+	if err != nil {
+		return errors.Handle(err, c)
+	}
+	return c.Respond(http.StatusCreated, data)
+}
+
+// update replaces fields of the body, PUT requires required fields.
+func (s *Site) update(c gorim.Context, model *modelAdmin) error {
+	ctx := conf.UsePrimary(c.Request().Context())
+	instance, err := model.get(conf.GetDB(ctx), c.Param("pk"))
+	if err != nil {
+		return errors.Handle(err, c)
+	}
+	body, err := model.body(c, c.Request().Method == http.MethodPut, false)
+	if err != nil {
+		return errors.Handle(err, c)
+	}
+	if err := json.Unmarshal(body, instance.Interface()); err != nil {
+		return errors.Handle(errors.BadRequest(err.Error()), c)
+	}
+	if err := conf.GetDB(ctx).Save(instance.Interface()).Error; err != nil {
+		return errors.Handle(errors.BadRequest(err.Error()), c)
+	}
+	data, err := model.represent(instance.Interface())
+	if err != nil {
+		return errors.Handle(err, c)
+	}
+	return c.Respond(http.StatusOK, data)
+}
+
+func (s *Site) destroy(c gorim.Context, model *modelAdmin) error {
+	ctx := conf.UsePrimary(c.Request().Context())
+	instance, err := model.get(conf.GetDB(ctx), c.Param("pk"))
+	if err != nil {
+		return errors.Handle(err, c)
+	}
+	if err := conf.GetDB(ctx).Delete(instance.Interface()).Error; err != nil {
+		return errors.Handle(err, c)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// get loads instance by primary key.
+func (m *modelAdmin) get(db *gorm.DB, pk string) (reflect.Value, error) {
+	field := m.schema.PrioritizedPrimaryField
+	if field == nil {
+		return reflect.Value{}, errors.NotFound("Not found.")
+	}
+	value, err := utils.ParseFieldString(m.new().Interface(), field.Name, pk)
+	if err != nil {
+		return reflect.Value{}, errors.NotFound("Not found.")
+	}
+	instance := m.new()
+	err = db.Where(clause.Eq{Column: clause.Column{Name: field.DBName}, Value: value}).Take(instance.Interface()).Error
+	if err == gorm.ErrRecordNotFound {
+		return reflect.Value{}, errors.NotFound("Not found.")
+	}
+	return instance, err
+}
+
+// body returns json object of the request with writable fields only.
+func (m *modelAdmin) body(c gorim.Context, required bool, creating bool) ([]byte, error) {
+	var data map[string]json.RawMessage
+	if err := json.NewDecoder(c.Request().Body).Decode(&data); err != nil {
+		return nil, &errors.APIError{Status: http.StatusBadRequest, Message: "Malformed request body.", Code: errors.CodeParseError}
+	}
+	writable := map[string]json.RawMessage{}
+	for name, value := range data {
+		field, ok := m.fields[name]
+		if !ok || m.readOnly[name] || (field.PrimaryKey && !creating) {
+			continue
+		}
+		writable[name] = value
+	}
+	if required {
+		validationErrors := errors.ValidationErrors{}
+		for _, info := range m.info.Fields {
+			if _, ok := writable[info.Name]; info.Required && !ok && !(info.PrimaryKey && !creating) {
+				validationErrors.Add(info.Name, "This field is required.")
+			}
+		}
+		if len(validationErrors) > 0 {
+			return nil, validationErrors
+		}
+	}
+	return json.Marshal(writable)
+}
+
+// filter applies query params naming fields, example: ?status=paid&total__gte=10.
+func (m *modelAdmin) filter(c gorim.Context, queryset *gorm.DB) (*gorm.DB, error) {
+	validationErrors := errors.ValidationErrors{}
+	for param, values := range c.QueryParams() {
+		if reserved(param) || len(values) == 0 {
+			continue
+		}
+		name, operator, _ := strings.Cut(param, "__")
+		field, ok := m.field(name)
+		if !ok {
+			continue
+		}
+		column := clause.Column{Name: field.DBName}
+		raw := values[len(values) - 1]
+		if operator == "isnull" {
+			isNull, err := strconv.ParseBool(raw)
+			if err != nil {
+				validationErrors.Add(param, "Invalid value.")
+				continue
+			}
+			sql := "? IS NOT NULL"
+			if isNull {
+				sql = "? IS NULL"
+			}
+			queryset = queryset.Where(clause.Expr{SQL: sql, Vars: []any{column}})
+			continue
+		}
+		if operator == "contains" {
+			queryset = queryset.Where(clause.Like{Column: column, Value: "%" + raw + "%"})
+			continue
+		}
+		if operator == "in" {
+			var parsed []any
+			for _, item := range strings.Split(raw, ",") {
+				value, err := utils.ParseFieldString(m.new().Interface(), field.Name, item)
+				if err != nil {
+					validationErrors.Add(param, "Invalid value.")
+					break
+				}
+				parsed = append(parsed, value)
+			}
+			queryset = queryset.Where(clause.IN{Column: column, Values: parsed})
+			continue
+		}
+		value, err := utils.ParseFieldString(m.new().Interface(), field.Name, raw)
+		if err != nil {
+			validationErrors.Add(param, "Invalid value.")
+			continue
+		}
+		switch operator {
+		case "":
+			queryset = queryset.Where(clause.Eq{Column: column, Value: value})
+		case "ne":
+			queryset = queryset.Where(clause.Neq{Column: column, Value: value})
+		case "gt":
+			queryset = queryset.Where(clause.Gt{Column: column, Value: value})
+		case "gte":
+			queryset = queryset.Where(clause.Gte{Column: column, Value: value})
+		case "lt":
+			queryset = queryset.Where(clause.Lt{Column: column, Value: value})
+		case "lte":
+			queryset = queryset.Where(clause.Lte{Column: column, Value: value})
+		}
+	}
+	if len(validationErrors) > 0 {
+		return nil, validationErrors
+	}
+	return queryset, nil
+}
+
+// search matches term in string fields.
+func (m *modelAdmin) search(queryset *gorm.DB, term string) *gorm.DB {
+	if term == "" || len(m.info.SearchFields) == 0 {
+		return queryset
+	}
+	var expressions []clause.Expression
+	for _, name := range m.info.SearchFields {
+		column := clause.Column{Name: m.fields[name].DBName}
+		expressions = append(expressions, clause.Like{Column: column, Value: "%" + term + "%"})
+	}
+	return queryset.Where(clause.Or(expressions...))
+}
+
+// order sorts by comma separated fields, "-" descending, unknown fields are ignored,
+// default primary key.
+func (m *modelAdmin) order(queryset *gorm.DB, sort string) *gorm.DB {
+	ordered := false
+	for _, name := range strings.Split(sort, ",") {
+		name = strings.TrimSpace(name)
+		desc := strings.HasPrefix(name, "-")
+		field, ok := m.field(strings.TrimPrefix(name, "-"))
+		if !ok {
+			continue
+		}
+		queryset = queryset.Order(clause.OrderByColumn{Column: clause.Column{Name: field.DBName}, Desc: desc})
+		ordered = true
+	}
+	if field := m.schema.PrioritizedPrimaryField; !ordered && field != nil {
+		queryset = queryset.Order(clause.OrderByColumn{Column: clause.Column{Name: field.DBName}})
+	}
+	return queryset
+}
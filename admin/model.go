@@ -0,0 +1,144 @@
+package admin
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm/schema"
+)
+
+// ModelInfo describes a model of the site, fields are named as they're rendered.
+type ModelInfo struct {
+	Name			string		`json:"name"`
+	App				string		`json:"app,omitempty"`
+	PrimaryKey		string		`json:"primary_key"`
+	Fields			[]FieldInfo	`json:"fields"`
+	// SearchFields are matched by the search param.
+	SearchFields	[]string	`json:"search_fields"`
+}
+
+type FieldInfo struct {
+	Name		string	`json:"name"`
+	Column		string	`json:"column"`
+	// Type is the data type of gorm, example: "string", "int", "time" or "uuid".
+	Type		string	`json:"type"`
+	PrimaryKey	bool	`json:"primary_key,omitempty"`
+	Required	bool	`json:"required"`
+	ReadOnly	bool	`json:"read_only,omitempty"`
+}
+
+// modelAdmin serves a model of the site.
+type modelAdmin struct {
+	schema		*schema.Schema
+	info		ModelInfo
+	// fields by json name, hidden fields excluded.
+	fields		map[string]*schema.Field
+	// jsonNames of fields by Go name.
+	jsonNames	map[string]string
+	hidden		[]string
+	readOnly	map[string]bool
+}
+
+func newModelAdmin(parsed *schema.Schema, app string, hiddenFields []string) *modelAdmin {
+	model := &modelAdmin{
+		schema: parsed,
+		fields: map[string]*schema.Field{},
+		jsonNames: map[string]string{},
+		readOnly: map[string]bool{},
+		info: ModelInfo{Name: parsed.Table, App: app, Fields: []FieldInfo{}, SearchFields: []string{}},
+	}
+	for _, field := range parsed.Fields {
+		name, ok := jsonName(field)
+		if field.DBName == "" || !ok {
+			continue
+		}
+		if contains(hiddenFields, field, name) {
+			model.hidden = append(model.hidden, name)
+			continue
+		}
+		model.fields[name] = field
+		model.jsonNames[field.Name] = name
+		readOnly := (field.PrimaryKey && field.AutoIncrement) || field.AutoCreateTime > 0 || field.AutoUpdateTime > 0
+		if readOnly {
+			model.readOnly[name] = true
+		}
+		dataType := string(field.DataType)
+		if dataType == "" {
+			dataType = indirect(field.FieldType).Kind().String()
+		}
+		model.info.Fields = append(model.info.Fields, FieldInfo{
+			Name: name,
+			Column: field.DBName,
+			Type: dataType,
+			PrimaryKey: field.PrimaryKey,
+			Required: field.NotNull && !field.HasDefaultValue && !readOnly,
+			ReadOnly: readOnly,
+		})
+		if field.DataType == schema.String {
+			model.info.SearchFields = append(model.info.SearchFields, name)
+		}
+	}
+	if field := parsed.PrioritizedPrimaryField; field != nil {
+		model.info.PrimaryKey = model.jsonNames[field.Name]
+	}
+	return model
+}
+
+// jsonName returns the name of field in json, false when it isn't rendered.
+func jsonName(field *schema.Field) (string, bool) {
+	name, _, _ := strings.Cut(field.StructField.Tag.Get("json"), ",")
+	if name == "-" {
+		return "", false
+	}
+	if name == "" {
+		name = field.Name
+	}
+	return name, true
+}
+
+// field returns field by json or column name.
+func (m *modelAdmin) field(name string) (*schema.Field, bool) {
+	if field, ok := m.fields[name]; ok {
+		return field, true
+	}
+	for _, field := range m.fields {
+		if field.DBName == name {
+			return field, true
+		}
+	}
+	return nil, false
+}
+
+func (m *modelAdmin) new() reflect.Value {
+	return reflect.New(m.schema.ModelType)
+}
+
+// represent renders instance or slice of instances without hidden fields.
+func (m *modelAdmin) represent(data any) (any, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var value any
+	if err := json.Unmarshal(encoded, &value); err != nil {
+		return nil, err
+	}
+	switch value := value.(type) {
+	case map[string]any:
+		m.hide(value)
+	case []any:
+		for _, item := range value {
+			if object, ok := item.(map[string]any); ok {
+				m.hide(object)
+			}
+		}
+	}
+	return value, nil
+}
+
+func (m *modelAdmin) hide(object map[string]any) {
+	for _, name := range m.hidden {
+		delete(object, name)
+	}
+}
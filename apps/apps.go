@@ -0,0 +1,151 @@
+package apps
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/routers"
+)
+
+// AppConfig describes an app of the project, register it in init of the app package so
+// importing the package is enough to install it:
+//
+//	func init() {
+//		apps.Register(apps.AppConfig{
+//			Name: "blog",
+//			Models: []interface{}{&Post{}, &Comment{}},
+//			Routes: RouterPost,
+//			Ready: func() {
+//				audit.Register[Post]()
+//			},
+//		})
+//	}
+type AppConfig struct {
+	// Name labels the app, it must be unique.
+	Name		string
+	// Prefix of routes under the group passed to Mount, default "/" + Name with "_" replaced
+	// by "-", "/" mounts at the group.
+	Prefix		string
+	// Models are migrated and used by fixtures and the shell.
+	Models		[]interface{}
+	// Routes registers viewsets of the app on its group.
+	Routes		func(*gorim.Group)
+	// Ready runs once before the app is used, connect signals of the app there.
+	Ready		func()
+}
+
+// GetPrefix returns prefix of the app routes.
+func (a AppConfig) GetPrefix() string {
+	if a.Prefix == "" {
+		return "/" + strings.ReplaceAll(a.Name, "_", "-")
+	}
+	if a.Prefix == "/" {
+		return ""
+	}
+	return a.Prefix
+}
+
+type routeKey struct {
+	method		string
+	path		string
+}
+
+var (
+	mu			sync.RWMutex
+	registry	[]AppConfig
+	ready		= map[string]bool{}
+	routeApps	= map[routeKey]string{}
+)
+
+// Register adds apps in order, it panics when name is empty or already registered.
+func Register(configs ...AppConfig) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, config := range configs {
+		if config.Name == "" {
+			panic("apps: app must have a name")
+		}
+		for _, registered := range registry {
+			if registered.Name == config.Name {
+				panic(fmt.Sprintf("apps: %s is already registered", config.Name))
+			}
+		}
+		registry = append(registry, config)
+	}
+}
+
+// All returns registered apps in order of registration.
+func All() []AppConfig {
+	mu.RLock()
+	defer mu.RUnlock()
+	return append([]AppConfig{}, registry...)
+}
+
+// Get returns app registered by name.
+func Get(name string) (AppConfig, bool) {
+	for _, app := range All() {
+		if app.Name == name {
+			return app, true
+		}
+	}
+	return AppConfig{}, false
+}
+
+// Models returns models of every app.
+func Models() []interface{} {
+	models := []interface{}{}
+	for _, app := range All() {
+		models = append(models, app.Models...)
+	}
+	return models
+}
+
+// Ready calls Ready of apps which haven't run it yet.
+func Ready() {
+	for _, app := range All() {
+		mu.Lock()
+		done := ready[app.Name]
+		ready[app.Name] = true
+		mu.Unlock()
+		if !done && app.Ready != nil {
+			app.Ready()
+		}
+	}
+}
+
+// Mount calls Ready then registers routes of every app under its prefix of group,
+// example: apps.Mount(server.Group("/api/v1"))
+func Mount(group *gorim.Group) {
+	Ready()
+	for _, app := range All() {
+		if app.Routes == nil {
+			continue
+		}
+		before := routeKeys()
+		app.Routes(group.Group(app.GetPrefix()))
+		mu.Lock()
+		for key := range routeKeys() {
+			if !before[key] {
+				routeApps[key] = app.Name
+			}
+		}
+		mu.Unlock()
+	}
+}
+
+// AppOf returns name of the app which mounted route, empty for routes outside of apps.
+func AppOf(route routers.RouteInfo) string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return routeApps[routeKey{method: route.Method, path: route.Path}]
+}
+
+func routeKeys() map[routeKey]bool {
+	keys := map[routeKey]bool{}
+	for _, route := range routers.Routes() {
+		keys[routeKey{method: route.Method, path: route.Path}] = true
+	}
+	return keys
+}
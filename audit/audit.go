@@ -0,0 +1,178 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/conf"
+	"github.com/rimba47prayoga/gorim.git/middlewares"
+	"github.com/rimba47prayoga/gorim.git/signals"
+	"github.com/rimba47prayoga/gorim.git/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// pending holds values of instances being updated, loaded before save to diff after it.
+// Entries of saves failing are cleared, by the response of their request or by
+// clearPending when the update fails.
+var pending sync.Map
+
+var registerClear sync.Once
+
+// clearPending is a callback after gorm updates, it deletes the entry of the instance
+// when the update fails since PostSave isn't sent for it.
+func clearPending(tx *gorm.DB) {
+	if tx.Error == nil {
+		return
+	}
+	if tx.Statement.Dest != nil {
+		pending.Delete(tx.Statement.Dest)
+	}
+	if tx.Statement.Model != nil {
+		pending.Delete(tx.Statement.Model)
+	}
+}
+
+// Register records create, update and delete of model T done by serializers and viewsets,
+// exclude are json names of fields left out, fields with json:"-" are never recorded,
+// example: audit.Register[Product]("internal_notes")
+func Register[T any](exclude ...string) {
+	if gorim.IsModel(new(T)) {
+		exclude = append(exclude, "updated_at")
+	}
+	signals.Connect(signals.PreSave, func(event signals.Event[T]) {
+		if event.Created {
+			return
+		}
+		registerClear.Do(func() {
+			db(event.Context).Callback().Update().After("gorm:update").Register("audit:clear_pending", clearPending)
+		})
+		old := new(T)
+		id := ObjectID(event.Instance)
+		if err := db(event.Context).Where(primaryKey(event.Instance) + " = ?", id).First(old).Error; err == nil {
+			pending.Store(event.Instance, values(old, exclude))
+			if event.Context != nil {
+				// saves raising errors such as conflicts don't reach the database.
+				instance := event.Instance
+				event.Context.Response().Before(func() {
+					pending.Delete(instance)
+				})
+			}
+		}
+	})
+	signals.Connect(signals.PostSave, func(event signals.Event[T]) {
+		current := values(event.Instance, exclude)
+		if event.Created {
+			record(event.Context, event.Instance, ActionCreate, diff(nil, current))
+			return
+		}
+		old, ok := pending.LoadAndDelete(event.Instance)
+		if !ok {
+			return
+		}
+		if changes := diff(old.(map[string]any), current); len(changes) > 0 {
+			record(event.Context, event.Instance, ActionUpdate, changes)
+		}
+	})
+	signals.Connect(signals.PostDelete, func(event signals.Event[T]) {
+		record(event.Context, event.Instance, ActionDelete, diff(values(event.Instance, exclude), nil))
+	})
+}
+
+// History returns queryset of entries of instance, order it with Order("id") for oldest first.
+func History(db *gorm.DB, instance interface{}) *gorm.DB {
+	return db.Model(&LogEntry{}).
+		Where("model = ? AND object_id = ?", ModelName(instance), ObjectID(instance))
+}
+
+// ModelName returns table name of the model, it's what entries are keyed by.
+func ModelName(instance interface{}) string {
+	if parsed := parse(instance); parsed != nil {
+		return parsed.Table
+	}
+	return utils.GetStructName(instance)
+}
+
+// ObjectID returns primary key of instance as string.
+func ObjectID(instance interface{}) string {
+	parsed := parse(instance)
+	if parsed == nil || parsed.PrioritizedPrimaryField == nil {
+		return ""
+	}
+	value, _ := parsed.PrioritizedPrimaryField.ValueOf(conf.DB.Statement.Context, reflect.ValueOf(instance).Elem())
+	return fmt.Sprint(value)
+}
+
+func primaryKey(instance interface{}) string {
+	parsed := parse(instance)
+	if parsed == nil || parsed.PrioritizedPrimaryField == nil {
+		return "id"
+	}
+	return parsed.PrioritizedPrimaryField.DBName
+}
+
+func parse(instance interface{}) *schema.Schema {
+	statement := &gorm.Statement{DB: conf.DB}
+	if err := statement.Parse(instance); err != nil {
+		return nil
+	}
+	return statement.Schema
+}
+
+func db(c echo.Context) *gorm.DB {
+	if c == nil {
+		return conf.DB
+	}
+	return conf.GetDB(c.Request().Context())
+}
+
+// values returns json fields of instance, so names and values match the api.
+func values(instance interface{}, exclude []string) map[string]any {
+	data, err := json.Marshal(instance)
+	if err != nil {
+		return nil
+	}
+	result := map[string]any{}
+	json.Unmarshal(data, &result)
+	for _, name := range exclude {
+		delete(result, name)
+	}
+	return result
+}
+
+func diff(old map[string]any, current map[string]any) Changes {
+	changes := Changes{}
+	for name, value := range current {
+		if previous, ok := old[name]; !ok || !reflect.DeepEqual(previous, value) {
+			changes[name] = Change{Old: old[name], New: value}
+		}
+	}
+	for name, value := range old {
+		if _, ok := current[name]; !ok {
+			changes[name] = Change{Old: value}
+		}
+	}
+	return changes
+}
+
+func record(c echo.Context, instance interface{}, action string, changes Changes) {
+	entry := LogEntry{
+		Model: ModelName(instance),
+		ObjectID: ObjectID(instance),
+		Action: action,
+		Changes: changes,
+	}
+	if c != nil {
+		if userID, ok := utils.GetUserID(c.Get(gorim.UserContextKey)); ok {
+			entry.UserID = &userID
+		}
+		entry.RequestID, _ = c.Get(middlewares.RequestIDContextKey).(string)
+	}
+	if err := db(c).Create(&entry).Error; err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,57 @@
+package audit
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	ActionCreate	= "create"
+	ActionUpdate	= "update"
+	ActionDelete	= "delete"
+)
+
+// Change is old and new value of a field, old is nil on create and new is nil on delete.
+type Change struct {
+	Old		any		`json:"old"`
+	New		any		`json:"new"`
+}
+
+// Changes maps json name of field to its change, stored as json text.
+type Changes map[string]Change
+
+func (c Changes) Value() (driver.Value, error) {
+	data, err := json.Marshal(c)
+	return string(data), err
+}
+
+func (c *Changes) Scan(value interface{}) error {
+	switch value := value.(type) {
+	case nil:
+		*c = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(value, c)
+	case string:
+		return json.Unmarshal([]byte(value), c)
+	}
+	return fmt.Errorf("cannot convert %T to Changes", value)
+}
+
+// LogEntry records who changed an object, migrate it with the models using audit.
+type LogEntry struct {
+	ID			uint		`gorm:"primarykey" json:"id"`
+	Model		string		`gorm:"type:varchar(100);index:idx_audit_object" json:"model"`
+	ObjectID	string		`gorm:"type:varchar(64);index:idx_audit_object" json:"object_id"`
+	Action		string		`gorm:"type:varchar(10)" json:"action"`
+	Changes		Changes		`gorm:"type:text" json:"changes"`
+	UserID		*uint		`json:"user_id"`
+	RequestID	string		`gorm:"type:varchar(64)" json:"request_id"`
+	CreatedAt	time.Time	`json:"created_at"`
+}
+
+func (LogEntry) TableName() string {
+	return "gorim_audit_log"
+}
@@ -0,0 +1,169 @@
+package authentication
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/conf"
+	"github.com/rimba47prayoga/gorim.git/utils"
+	"gorm.io/gorm"
+)
+
+const APIKeyContextKey = "api_key"
+
+// APIKey is stored hashed, the raw key is only shown once on creation.
+type APIKey struct {
+	ID			uint			`gorm:"primarykey" json:"id"`
+	Name		string			`gorm:"type:varchar(255)" json:"name"`
+	Prefix		string			`gorm:"type:varchar(16);uniqueIndex;not null" json:"prefix"`
+	HashedKey	string			`gorm:"type:varchar(64);not null" json:"-"`
+	UserID		*uint			`gorm:"index" json:"user_id"`
+	Scopes		string			`gorm:"type:text" json:"scopes"`
+	ExpiresAt	*time.Time		`gorm:"type:timestamp" json:"expires_at"`
+	RevokedAt	*time.Time		`gorm:"type:timestamp" json:"revoked_at"`
+	LastUsedAt	*time.Time		`gorm:"type:timestamp" json:"last_used_at"`
+	CreatedAt	time.Time		`gorm:"type:timestamp" json:"created_at"`
+}
+
+func (m APIKey) TableName() string {
+	return "gorim_api_keys"
+}
+
+func (m *APIKey) GetScopes() []string {
+	if m.Scopes == "" {
+		return []string{}
+	}
+	return strings.Split(m.Scopes, ",")
+}
+
+// HasScope returns true for matching scope or wildcard "*".
+func (m *APIKey) HasScope(scope string) bool {
+	scopes := m.GetScopes()
+	return utils.Contains(scopes, scope) || utils.Contains(scopes, "*")
+}
+
+func (m *APIKey) IsValid() bool {
+	now := time.Now()
+	if m.RevokedAt != nil {
+		return false
+	}
+	return m.ExpiresAt == nil || now.Before(*m.ExpiresAt)
+}
+
+func (m *APIKey) Revoke(db *gorm.DB) error {
+	now := time.Now()
+	m.RevokedAt = &now
+	return db.Model(m).Update("revoked_at", now).Error
+}
+
+func hashAPIKey(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
+
+// CreateAPIKey creates a new key and returns the raw key with format "<prefix>.<secret>".
+func CreateAPIKey(db *gorm.DB, name string, userID *uint, scopes []string, expiresAt *time.Time) (string, *APIKey, error) {
+	prefix, err := GenerateKey()
+	if err != nil {
+		return "", nil, err
+	}
+	secret, err := GenerateKey()
+	if err != nil {
+		return "", nil, err
+	}
+	apiKey := APIKey{
+		Name: name,
+		Prefix: prefix[:16],
+		HashedKey: hashAPIKey(secret),
+		UserID: userID,
+		Scopes: strings.Join(scopes, ","),
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	}
+	if err := db.Create(&apiKey).Error; err != nil {
+		return "", nil, err
+	}
+	return apiKey.Prefix + "." + secret, &apiKey, nil
+}
+
+// APIKeyAuthentication authenticates requests by `X-API-Key: <key>` header.
+// The key owner becomes the request user, keys without owner authenticate as the APIKey itself.
+type APIKeyAuthentication struct {
+	Header		string
+	// GetUser loads the key owner, default loads conf.USER_MODEL.
+	GetUser		func(userID uint) (interface{}, error)
+}
+
+func (a *APIKeyAuthentication) GetHeader() string {
+	if a.Header == "" {
+		return "X-API-Key"
+	}
+	return a.Header
+}
+
+func (a *APIKeyAuthentication) Authenticate(c echo.Context) (interface{}, error) {
+	rawKey := c.Request().Header.Get(a.GetHeader())
+	if rawKey == "" {
+		return nil, nil
+	}
+	prefix, secret, found := strings.Cut(rawKey, ".")
+	if !found {
+		return nil, fmt.Errorf("invalid api key")
+	}
+	var apiKey APIKey
+	if err := conf.DB.Where("prefix = ?", prefix).First(&apiKey).Error; err != nil {
+		return nil, fmt.Errorf("invalid api key")
+	}
+	if subtle.ConstantTimeCompare([]byte(apiKey.HashedKey), []byte(hashAPIKey(secret))) != 1 {
+		return nil, fmt.Errorf("invalid api key")
+	}
+	if !apiKey.IsValid() {
+		return nil, fmt.Errorf("api key expired or revoked")
+	}
+	now := time.Now()
+	apiKey.LastUsedAt = &now
+	conf.DB.Model(&apiKey).UpdateColumn("last_used_at", now)
+	c.Set(APIKeyContextKey, &apiKey)
+
+	if apiKey.UserID == nil {
+		return &apiKey, nil
+	}
+	if a.GetUser != nil {
+		return a.GetUser(*apiKey.UserID)
+	}
+	return DefaultGetUser(*apiKey.UserID)
+}
+
+func (a *APIKeyAuthentication) Middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return Chain(a)(next)
+}
+
+// GetAPIKey returns the APIKey used to authenticate the request.
+func GetAPIKey(c gorim.Context) *APIKey {
+	apiKey, _ := c.Get(APIKeyContextKey).(*APIKey)
+	return apiKey
+}
+
+// HasScopes permission requires the request to be authenticated by api key having all scopes.
+type HasScopes struct {
+	Scopes		[]string
+}
+
+func (p *HasScopes) HasPermission(ctx gorim.Context) bool {
+	apiKey := GetAPIKey(ctx)
+	if apiKey == nil {
+		return false
+	}
+	for _, scope := range p.Scopes {
+		if !apiKey.HasScope(scope) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,83 @@
+package authentication
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/conf"
+	"github.com/rimba47prayoga/gorim.git/errors"
+	"github.com/rimba47prayoga/gorim.git/models"
+	"github.com/rimba47prayoga/gorim.git/permissions"
+)
+
+const AuthenticatorContextKey = "authenticator"
+
+// Authenticator returns nil user and nil error when the request has no credentials
+// it understands, and error when credentials are present but invalid.
+type Authenticator interface {
+	Authenticate(echo.Context) (interface{}, error)
+}
+
+// AuthenticateFunc is an adapter to use ordinary function as Authenticator.
+type AuthenticateFunc func(echo.Context) (interface{}, error)
+
+func (f AuthenticateFunc) Authenticate(c echo.Context) (interface{}, error) {
+	return f(c)
+}
+
+// DefaultAuthenticators is used by Chain when called without authenticators.
+var DefaultAuthenticators []Authenticator
+
+// Chain tries authenticators in order, the first one returning a user wins.
+// Responds 401 when credentials are invalid and leaves anonymous requests to be handled by permissions.
+// Use it globally with server.Use(...) or per route group.
+func Chain(authenticators ...Authenticator) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			chain := authenticators
+			if len(chain) == 0 {
+				chain = DefaultAuthenticators
+			}
+			for _, authenticator := range chain {
+				user, err := authenticator.Authenticate(c)
+				if err != nil {
+					permissions.FireDenied(permissions.DenialEvent{
+						Request: c.Request(),
+						Status: http.StatusUnauthorized,
+						Code: errors.CodeAuthenticationFailed,
+						Reason: err.Error(),
+					})
+					return errors.Handle(&errors.AuthenticationFailedError{Message: err.Error()}, c)
+				}
+				if user != nil {
+					c.Set(gorim.UserContextKey, user)
+					c.Set(AuthenticatorContextKey, authenticator)
+					break
+				}
+			}
+			return next(c)
+		}
+	}
+}
+
+// Middleware wraps single AuthenticateFunc.
+func Middleware(authenticate AuthenticateFunc) echo.MiddlewareFunc {
+	return Chain(authenticate)
+}
+
+// GetAuthenticator returns the authenticator which authenticated the request.
+func GetAuthenticator(c echo.Context) Authenticator {
+	authenticator, _ := c.Get(AuthenticatorContextKey).(Authenticator)
+	return authenticator
+}
+
+// DefaultGetUser loads conf.USER_MODEL by primary key.
+func DefaultGetUser(userID interface{}) (interface{}, error) {
+	user, err := models.GetUserByID(conf.DB, userID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found")
+	}
+	return user, nil
+}
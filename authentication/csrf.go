@@ -0,0 +1,118 @@
+package authentication
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/errors"
+	"github.com/rimba47prayoga/gorim.git/permissions"
+	"github.com/rimba47prayoga/gorim.git/utils"
+)
+
+const CSRFContextKey = "csrf_token"
+
+// CSRF protects session authenticated requests with double submit cookie,
+// unsafe requests must send the csrftoken cookie value in X-CSRFToken header.
+// Requests authenticated by token, JWT or API key are exempt since browsers
+// don't attach those credentials automatically.
+type CSRF struct {
+	CookieName	string
+	HeaderName	string
+	MaxAge		time.Duration
+	Path		string
+	Domain		string
+	Secure		bool
+	SameSite	http.SameSite
+	Skipper		func(c echo.Context) bool
+}
+
+func NewCSRF() *CSRF {
+	return &CSRF{
+		CookieName: "csrftoken",
+		HeaderName: "X-CSRFToken",
+		MaxAge: 365 * 24 * time.Hour,
+		Path: "/",
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+func newCSRFToken() string {
+	bytes := make([]byte, 32)
+	rand.Read(bytes)
+	return hex.EncodeToString(bytes)
+}
+
+// Middleware issues the cookie when missing and verifies unsafe session authenticated requests,
+// it must run after Chain. Skipped requests don't get the cookie.
+func (p *CSRF) Middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if p.Skipper != nil && p.Skipper(c) {
+			return next(c)
+		}
+		cookieToken := p.GetToken(c)
+		if utils.Contains(permissions.SafeMethods, c.Request().Method) {
+			return next(c)
+		}
+		if _, ok := GetAuthenticator(c).(*SessionAuthentication); !ok {
+			return next(c)
+		}
+		headerToken := c.Request().Header.Get(p.HeaderName)
+		if headerToken == "" || subtle.ConstantTimeCompare([]byte(headerToken), []byte(cookieToken)) != 1 {
+			permissions.FireDenied(permissions.DenialEvent{
+				Request: c.Request(),
+				User: c.Get(gorim.UserContextKey),
+				Status: http.StatusForbidden,
+				Code: "csrf_failed",
+				Reason: "CSRF token missing or incorrect",
+			})
+			return errors.Handle(&errors.PermissionDeniedError{
+				Message: "CSRF Failed: CSRF token missing or incorrect.",
+				Code: "csrf_failed",
+			}, c)
+		}
+		return next(c)
+	}
+}
+
+// GetToken returns the CSRF token of the request, issuing the cookie when missing.
+func (p *CSRF) GetToken(c echo.Context) string {
+	if token, ok := c.Get(CSRFContextKey).(string); ok {
+		return token
+	}
+	if cookie, err := c.Cookie(p.CookieName); err == nil && cookie.Value != "" {
+		c.Set(CSRFContextKey, cookie.Value)
+		return cookie.Value
+	}
+	return p.Rotate(c)
+}
+
+// Rotate issues a new token, called on login so a token known before login can't be reused.
+func (p *CSRF) Rotate(c echo.Context) string {
+	token := newCSRFToken()
+	c.SetCookie(&http.Cookie{
+		Name: p.CookieName,
+		Value: token,
+		Path: p.Path,
+		Domain: p.Domain,
+		Expires: time.Now().Add(p.MaxAge),
+		MaxAge: int(p.MaxAge.Seconds()),
+		Secure: p.Secure,
+		// readable by javascript to be sent back in the header.
+		HttpOnly: false,
+		SameSite: p.SameSite,
+	})
+	c.Set(CSRFContextKey, token)
+	return token
+}
+
+// TokenView returns the token for clients which can't read the cookie.
+func (p *CSRF) TokenView(c gorim.Context) error {
+	return c.Respond(http.StatusOK, gorim.Response{
+		"csrf_token": p.GetToken(c),
+	})
+}
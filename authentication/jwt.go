@@ -0,0 +1,231 @@
+package authentication
+
+import (
+	"crypto/rsa"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/errors"
+	"github.com/rimba47prayoga/gorim.git/settings"
+	"github.com/rimba47prayoga/gorim.git/utils"
+)
+
+// JWTClaimsContextKey stores claims of the access token authenticating the request.
+const JWTClaimsContextKey = "jwt_claims"
+
+const (
+	AccessTokenType		= "access"
+	RefreshTokenType	= "refresh"
+)
+
+// TokenPair is returned when obtaining or refreshing tokens.
+type TokenPair struct {
+	Access		string		`json:"access"`
+	Refresh		string		`json:"refresh"`
+}
+
+// JWTAuthentication authenticates requests by `Authorization: Bearer <token>` header.
+type JWTAuthentication struct {
+	// HS256 or RS256, default HS256.
+	SigningMethod			string
+	// SecretKey used by HS256.
+	SecretKey				[]byte
+	// PrivateKey and PublicKey used by RS256.
+	PrivateKey				*rsa.PrivateKey
+	PublicKey				*rsa.PublicKey
+	AccessTokenLifetime		time.Duration
+	RefreshTokenLifetime	time.Duration
+	Issuer					string
+	Audience				string
+	HeaderPrefix			string
+	UserIDClaim				string
+	// Claims returns extra claims added to the access token.
+	Claims					func(user interface{}) jwt.MapClaims
+	// GetUser loads the user from validated claims, default loads conf.USER_MODEL by UserIDClaim.
+	GetUser					func(claims jwt.MapClaims) (interface{}, error)
+}
+
+func (a *JWTAuthentication) GetSigningMethod() jwt.SigningMethod {
+	if a.SigningMethod == "RS256" {
+		return jwt.SigningMethodRS256
+	}
+	return jwt.SigningMethodHS256
+}
+
+func (a *JWTAuthentication) GetUserIDClaim() string {
+	if a.UserIDClaim == "" {
+		return "user_id"
+	}
+	return a.UserIDClaim
+}
+
+func (a *JWTAuthentication) GetHeaderPrefix() string {
+	if a.HeaderPrefix == "" {
+		return "Bearer"
+	}
+	return a.HeaderPrefix
+}
+
+func (a *JWTAuthentication) GetAccessTokenLifetime() time.Duration {
+	if a.AccessTokenLifetime == 0 {
+		return settings.Get().Auth.AccessTokenLifetime
+	}
+	return a.AccessTokenLifetime
+}
+
+func (a *JWTAuthentication) GetRefreshTokenLifetime() time.Duration {
+	if a.RefreshTokenLifetime == 0 {
+		return settings.Get().Auth.RefreshTokenLifetime
+	}
+	return a.RefreshTokenLifetime
+}
+
+func (a *JWTAuthentication) signingKey() interface{} {
+	if a.SigningMethod == "RS256" {
+		return a.PrivateKey
+	}
+	return a.SecretKey
+}
+
+func (a *JWTAuthentication) verifyingKey() interface{} {
+	if a.SigningMethod == "RS256" {
+		return a.PublicKey
+	}
+	return a.SecretKey
+}
+
+func (a *JWTAuthentication) newToken(claims jwt.MapClaims, tokenType string, lifetime time.Duration) (string, error) {
+	now := time.Now()
+	claims["token_type"] = tokenType
+	claims["iat"] = now.Unix()
+	claims["exp"] = now.Add(lifetime).Unix()
+	if a.Issuer != "" {
+		claims["iss"] = a.Issuer
+	}
+	if a.Audience != "" {
+		claims["aud"] = a.Audience
+	}
+	token := jwt.NewWithClaims(a.GetSigningMethod(), claims)
+	return token.SignedString(a.signingKey())
+}
+
+// ObtainTokenPair creates access and refresh token for the user.
+func (a *JWTAuthentication) ObtainTokenPair(user interface{}) (*TokenPair, error) {
+	userID, ok := utils.GetUserID(user)
+	if !ok {
+		return nil, fmt.Errorf("user has no ID field")
+	}
+	accessClaims := jwt.MapClaims{}
+	if a.Claims != nil {
+		accessClaims = a.Claims(user)
+	}
+	accessClaims[a.GetUserIDClaim()] = userID
+	access, err := a.newToken(accessClaims, AccessTokenType, a.GetAccessTokenLifetime())
+	if err != nil {
+		return nil, err
+	}
+	refreshClaims := jwt.MapClaims{
+		a.GetUserIDClaim(): userID,
+	}
+	refresh, err := a.newToken(refreshClaims, RefreshTokenType, a.GetRefreshTokenLifetime())
+	if err != nil {
+		return nil, err
+	}
+	return &TokenPair{Access: access, Refresh: refresh}, nil
+}
+
+// ParseToken validates signature, expiry, issuer, audience and token type.
+func (a *JWTAuthentication) ParseToken(tokenString string, tokenType string) (jwt.MapClaims, error) {
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{a.GetSigningMethod().Alg()}),
+		jwt.WithExpirationRequired(),
+	}
+	if a.Issuer != "" {
+		options = append(options, jwt.WithIssuer(a.Issuer))
+	}
+	if a.Audience != "" {
+		options = append(options, jwt.WithAudience(a.Audience))
+	}
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		return a.verifyingKey(), nil
+	}, options...)
+	if err != nil {
+		return nil, err
+	}
+	if claims["token_type"] != tokenType {
+		return nil, fmt.Errorf("token has wrong type")
+	}
+	return claims, nil
+}
+
+// Refresh validates the refresh token and returns a new token pair.
+func (a *JWTAuthentication) Refresh(refreshToken string) (*TokenPair, error) {
+	claims, err := a.ParseToken(refreshToken, RefreshTokenType)
+	if err != nil {
+		return nil, err
+	}
+	user, err := a.LoadUser(claims)
+	if err != nil {
+		return nil, err
+	}
+	return a.ObtainTokenPair(user)
+}
+
+func (a *JWTAuthentication) LoadUser(claims jwt.MapClaims) (interface{}, error) {
+	if a.GetUser != nil {
+		return a.GetUser(claims)
+	}
+	userID, ok := claims[a.GetUserIDClaim()]
+	if !ok {
+		return nil, fmt.Errorf("token contained no recognizable user identification")
+	}
+	return DefaultGetUser(userID)
+}
+
+// Authenticate returns nil user when there's no authorization header,
+// so the request is handled by permissions.
+func (a *JWTAuthentication) Authenticate(c echo.Context) (interface{}, error) {
+	header := c.Request().Header.Get(echo.HeaderAuthorization)
+	if header == "" {
+		return nil, nil
+	}
+	prefix := a.GetHeaderPrefix() + " "
+	if !strings.HasPrefix(header, prefix) {
+		return nil, nil
+	}
+	claims, err := a.ParseToken(strings.TrimPrefix(header, prefix), AccessTokenType)
+	if err != nil {
+		return nil, err
+	}
+	user, err := a.LoadUser(claims)
+	if user != nil {
+		c.Set(JWTClaimsContextKey, claims)
+	}
+	return user, err
+}
+
+// Middleware authenticates the request, responds 401 for invalid token.
+func (a *JWTAuthentication) Middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return Chain(a)(next)
+}
+
+// RefreshView handles `{"refresh": "<token>"}` and responds new token pair.
+func (a *JWTAuthentication) RefreshView(c gorim.Context) error {
+	var body struct {
+		Refresh		string		`json:"refresh"`
+	}
+	if err := c.Bind(&body); err != nil || body.Refresh == "" {
+		return errors.Handle(&errors.APIError{Status: http.StatusBadRequest, Message: "refresh is required"}, c)
+	}
+	pair, err := a.Refresh(body.Refresh)
+	if err != nil {
+		return errors.Handle(&errors.AuthenticationFailedError{Message: err.Error()}, c)
+	}
+	return c.Respond(http.StatusOK, pair)
+}
@@ -0,0 +1,221 @@
+package ldap
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strings"
+
+	goldap "github.com/go-ldap/ldap/v3"
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git/authentication"
+	"github.com/rimba47prayoga/gorim.git/conf"
+	"github.com/rimba47prayoga/gorim.git/models"
+	"github.com/rimba47prayoga/gorim.git/rbac"
+	"github.com/rimba47prayoga/gorim.git/utils"
+)
+
+// Entry is the LDAP user found by search.
+type Entry struct {
+	DN			string
+	Attributes	map[string][]string
+	Groups		[]string
+}
+
+func (e *Entry) GetAttribute(name string) string {
+	if values := e.Attributes[name]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// LDAPAuthentication authenticates HTTP Basic credentials against LDAP / Active Directory.
+// Service account binds with BindDN to search the user, then binds as the user to verify password.
+type LDAPAuthentication struct {
+	// URL, example: ldaps://ad.example.com:636
+	URL				string
+	BindDN			string
+	BindPassword	string
+	BaseDN			string
+	// UserFilter, %s is replaced by escaped username, default "(uid=%s)",
+	// use "(sAMAccountName=%s)" for Active Directory.
+	UserFilter		string
+	// Attributes fetched from user entry, default: mail, cn and memberOf.
+	Attributes		[]string
+	GroupAttribute	string
+	EmailAttribute	string
+	// GroupRoles maps group DN to rbac role name.
+	GroupRoles		map[string]string
+	TLSConfig		*tls.Config
+	StartTLS		bool
+	// GetUser maps the LDAP entry to local user, default gets or creates models.User by email
+	// and syncs rbac roles from GroupRoles.
+	GetUser			func(entry *Entry, roles []string) (interface{}, error)
+}
+
+func (a *LDAPAuthentication) getUserFilter() string {
+	if a.UserFilter == "" {
+		return "(uid=%s)"
+	}
+	return a.UserFilter
+}
+
+func (a *LDAPAuthentication) getGroupAttribute() string {
+	if a.GroupAttribute == "" {
+		return "memberOf"
+	}
+	return a.GroupAttribute
+}
+
+func (a *LDAPAuthentication) getEmailAttribute() string {
+	if a.EmailAttribute == "" {
+		return "mail"
+	}
+	return a.EmailAttribute
+}
+
+func (a *LDAPAuthentication) getAttributes() []string {
+	if len(a.Attributes) > 0 {
+		return a.Attributes
+	}
+	return []string{"cn", a.getEmailAttribute(), a.getGroupAttribute()}
+}
+
+func (a *LDAPAuthentication) connect() (*goldap.Conn, error) {
+	conn, err := goldap.DialURL(a.URL, goldap.DialWithTLSConfig(a.TLSConfig))
+	if err != nil {
+		return nil, err
+	}
+	if a.StartTLS {
+		if err := conn.StartTLS(a.TLSConfig); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+// CheckCredentials verifies username and password, returns the user entry.
+func (a *LDAPAuthentication) CheckCredentials(username string, password string) (*Entry, error) {
+	if username == "" || password == "" {
+		return nil, fmt.Errorf("invalid username or password")
+	}
+	conn, err := a.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := conn.Bind(a.BindDN, a.BindPassword); err != nil {
+		return nil, fmt.Errorf("ldap service bind failed: %w", err)
+	}
+	search := goldap.NewSearchRequest(
+		a.BaseDN,
+		goldap.ScopeWholeSubtree, goldap.NeverDerefAliases, 2, 0, false,
+		fmt.Sprintf(a.getUserFilter(), goldap.EscapeFilter(username)),
+		a.getAttributes(),
+		nil,
+	)
+	result, err := conn.Search(search)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Entries) != 1 {
+		return nil, fmt.Errorf("invalid username or password")
+	}
+	ldapEntry := result.Entries[0]
+	if err := conn.Bind(ldapEntry.DN, password); err != nil {
+		return nil, fmt.Errorf("invalid username or password")
+	}
+	entry := Entry{
+		DN: ldapEntry.DN,
+		Attributes: map[string][]string{},
+		Groups: ldapEntry.GetAttributeValues(a.getGroupAttribute()),
+	}
+	for _, attribute := range ldapEntry.Attributes {
+		entry.Attributes[attribute.Name] = attribute.Values
+	}
+	return &entry, nil
+}
+
+// GetRoles maps entry groups to role names, group DN is compared case-insensitively.
+func (a *LDAPAuthentication) GetRoles(entry *Entry) []string {
+	roles := []string{}
+	for _, group := range entry.Groups {
+		for groupDN, role := range a.GroupRoles {
+			if strings.EqualFold(groupDN, group) {
+				roles = append(roles, role)
+			}
+		}
+	}
+	return roles
+}
+
+// GetManagedRoles returns role names of GroupRoles, DefaultGetUser removes those the
+// entry groups no longer grant, roles assigned otherwise are kept.
+func (a *LDAPAuthentication) GetManagedRoles() []string {
+	roles := []string{}
+	for _, role := range a.GroupRoles {
+		if !utils.Contains(roles, role) {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+func (a *LDAPAuthentication) Authenticate(c echo.Context) (interface{}, error) {
+	username, password, ok := c.Request().BasicAuth()
+	if !ok {
+		return nil, nil
+	}
+	entry, err := a.CheckCredentials(username, password)
+	if err != nil {
+		return nil, err
+	}
+	roles := a.GetRoles(entry)
+	if a.GetUser != nil {
+		return a.GetUser(entry, roles)
+	}
+	return DefaultGetUser(a.getEmailAttribute(), entry, roles, a.GetManagedRoles())
+}
+
+func (a *LDAPAuthentication) Middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return authentication.Chain(a)(next)
+}
+
+// DefaultGetUser gets or creates models.User by email and assigns the mapped rbac roles,
+// managed roles which aren't mapped anymore are removed, example: the user left the group.
+func DefaultGetUser(emailAttribute string, entry *Entry, roles []string, managed []string) (interface{}, error) {
+	email := entry.GetAttribute(emailAttribute)
+	if email == "" {
+		return nil, fmt.Errorf("ldap entry has no %s attribute", emailAttribute)
+	}
+	user := models.User{Email: email}
+	err := conf.DB.Where("email = ?", email).Attrs(models.User{
+		Username: entry.GetAttribute("cn"),
+	}).FirstOrCreate(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, roleName := range roles {
+		var role rbac.Role
+		if err := conf.DB.Where("name = ?", roleName).First(&role).Error; err != nil {
+			continue
+		}
+		if err := rbac.AssignRole(conf.DB, user.ID, &role); err != nil {
+			return nil, err
+		}
+	}
+	for _, roleName := range managed {
+		if utils.Contains(roles, roleName) {
+			continue
+		}
+		var role rbac.Role
+		if err := conf.DB.Where("name = ?", roleName).First(&role).Error; err != nil {
+			continue
+		}
+		if err := rbac.RemoveRole(conf.DB, user.ID, &role); err != nil {
+			return nil, err
+		}
+	}
+	return &user, nil
+}
@@ -0,0 +1,277 @@
+package authentication
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git/conf"
+	"github.com/rimba47prayoga/gorim.git/models"
+)
+
+type jsonWebKey struct {
+	Kid		string		`json:"kid"`
+	Kty		string		`json:"kty"`
+	Use		string		`json:"use"`
+	N		string		`json:"n"`
+	E		string		`json:"e"`
+	Crv		string		`json:"crv"`
+	X		string		`json:"x"`
+	Y		string		`json:"y"`
+}
+
+// OIDCAuthentication validates bearer tokens issued by an OpenID Connect provider
+// (Keycloak, Auth0, ...) using the provider JWKS.
+type OIDCAuthentication struct {
+	// Issuer, example: https://auth.example.com/realms/main
+	Issuer			string
+	Audience		string
+	// JWKSURL is discovered from {Issuer}/.well-known/openid-configuration when empty.
+	JWKSURL			string
+	HTTPClient		*http.Client
+	// CacheTTL of fetched keys, default 1 hour.
+	CacheTTL		time.Duration
+	// RefetchInterval is the least time between fetches of keys, so tokens of unknown
+	// kids can't make every request fetch them, default 1 minute.
+	RefetchInterval	time.Duration
+	// GetUser maps validated claims to local user, default finds conf.USER_MODEL by email
+	// claim, which the provider must have verified by email_verified claim.
+	GetUser			func(claims jwt.MapClaims) (interface{}, error)
+
+	mu				sync.RWMutex
+	keys			map[string]interface{}
+	fetchedAt		time.Time
+	attemptedAt		time.Time
+}
+
+func (a *OIDCAuthentication) getHTTPClient() *http.Client {
+	if a.HTTPClient == nil {
+		return &http.Client{Timeout: 10 * time.Second}
+	}
+	return a.HTTPClient
+}
+
+func (a *OIDCAuthentication) getCacheTTL() time.Duration {
+	if a.CacheTTL == 0 {
+		return time.Hour
+	}
+	return a.CacheTTL
+}
+
+func (a *OIDCAuthentication) getRefetchInterval() time.Duration {
+	if a.RefetchInterval == 0 {
+		return time.Minute
+	}
+	return a.RefetchInterval
+}
+
+// allowFetch reports whether keys may be fetched, at most once per RefetchInterval.
+func (a *OIDCAuthentication) allowFetch() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if time.Since(a.attemptedAt) < a.getRefetchInterval() {
+		return false
+	}
+	a.attemptedAt = time.Now()
+	return true
+}
+
+func (a *OIDCAuthentication) getJSON(url string, target interface{}) error {
+	resp, err := a.getHTTPClient().Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch %s: status %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(target)
+}
+
+func (a *OIDCAuthentication) discoverJWKSURL() (string, error) {
+	a.mu.RLock()
+	jwksURL := a.JWKSURL
+	a.mu.RUnlock()
+	if jwksURL != "" {
+		return jwksURL, nil
+	}
+	var configuration struct {
+		JWKSURI		string		`json:"jwks_uri"`
+	}
+	url := strings.TrimSuffix(a.Issuer, "/") + "/.well-known/openid-configuration"
+	if err := a.getJSON(url, &configuration); err != nil {
+		return "", err
+	}
+	if configuration.JWKSURI == "" {
+		return "", fmt.Errorf("jwks_uri not found in openid configuration")
+	}
+	a.mu.Lock()
+	a.JWKSURL = configuration.JWKSURI
+	a.mu.Unlock()
+	return configuration.JWKSURI, nil
+}
+
+// FetchKeys downloads and caches the provider signing keys.
+func (a *OIDCAuthentication) FetchKeys() error {
+	url, err := a.discoverJWKSURL()
+	if err != nil {
+		return err
+	}
+	var jwks struct {
+		Keys	[]jsonWebKey	`json:"keys"`
+	}
+	if err := a.getJSON(url, &jwks); err != nil {
+		return err
+	}
+	keys := map[string]interface{}{}
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := parseJSONWebKey(jwk)
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	a.mu.Lock()
+	a.keys = keys
+	a.fetchedAt = time.Now()
+	a.mu.Unlock()
+	return nil
+}
+
+func (a *OIDCAuthentication) getKey(kid string) (interface{}, error) {
+	a.mu.RLock()
+	key, ok := a.keys[kid]
+	expired := time.Since(a.fetchedAt) > a.getCacheTTL()
+	a.mu.RUnlock()
+	if ok && !expired {
+		return key, nil
+	}
+	// refetch on unknown kid to support key rotation.
+	if !a.allowFetch() {
+		if ok {
+			return key, nil
+		}
+		return nil, fmt.Errorf("signing key %s not found", kid)
+	}
+	if err := a.FetchKeys(); err != nil {
+		if ok {
+			// keep using the cached key while the provider is unreachable.
+			return key, nil
+		}
+		return nil, err
+	}
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if key, ok := a.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("signing key %s not found", kid)
+}
+
+// ParseToken validates signature, expiry, issuer and audience.
+func (a *OIDCAuthentication) ParseToken(tokenString string) (jwt.MapClaims, error) {
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuer(a.Issuer),
+	}
+	if a.Audience != "" {
+		options = append(options, jwt.WithAudience(a.Audience))
+	}
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return a.getKey(kid)
+	}, options...)
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (a *OIDCAuthentication) Authenticate(c echo.Context) (interface{}, error) {
+	header := c.Request().Header.Get(echo.HeaderAuthorization)
+	if !strings.HasPrefix(header, "Bearer ") {
+		return nil, nil
+	}
+	claims, err := a.ParseToken(strings.TrimPrefix(header, "Bearer "))
+	if err != nil {
+		return nil, err
+	}
+	if a.GetUser != nil {
+		return a.GetUser(claims)
+	}
+	email, _ := claims["email"].(string)
+	if email == "" {
+		return nil, fmt.Errorf("token contained no email claim")
+	}
+	// unverified emails may be set to the email of another user.
+	if verified, _ := claims["email_verified"].(bool); !verified {
+		return nil, fmt.Errorf("token email is not verified")
+	}
+	user := models.NewUser()
+	if err := conf.DB.Where("email = ?", email).First(user).Error; err != nil {
+		return nil, fmt.Errorf("user not found")
+	}
+	return user, nil
+}
+
+func (a *OIDCAuthentication) Middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return Chain(a)(next)
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	bytes, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(bytes), nil
+}
+
+func parseJSONWebKey(jwk jsonWebKey) (interface{}, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeBigInt(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		default:
+			return nil, fmt.Errorf("unsupported curve %s", jwk.Crv)
+		}
+		x, err := decodeBigInt(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %s", jwk.Kty)
+}
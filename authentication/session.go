@@ -0,0 +1,109 @@
+package authentication
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/errors"
+	"github.com/rimba47prayoga/gorim.git/sessions"
+	"github.com/rimba47prayoga/gorim.git/utils"
+)
+
+const SessionUserIDKey = "_auth_user_id"
+
+// SessionAuthentication authenticates requests by session cookie,
+// intended for browser-based frontends.
+type SessionAuthentication struct {
+	Manager		*sessions.Manager
+	// GetUser loads the session user, default loads conf.USER_MODEL.
+	GetUser		func(userID uint) (interface{}, error)
+	// CSRF token is rotated on login when set.
+	CSRF		*CSRF
+}
+
+func NewSessionAuthentication(store sessions.Store) *SessionAuthentication {
+	return &SessionAuthentication{
+		Manager: sessions.NewManager(store),
+	}
+}
+
+func (a *SessionAuthentication) Authenticate(c echo.Context) (interface{}, error) {
+	session, err := a.Manager.Get(c)
+	if err != nil || session == nil {
+		// stale or unknown session cookie is treated as anonymous.
+		return nil, nil
+	}
+	var userID uint
+	switch value := session.Values[SessionUserIDKey].(type) {
+	case uint:
+		userID = value
+	case float64:
+		// values decoded from json store.
+		userID = uint(value)
+	default:
+		return nil, nil
+	}
+	if a.GetUser != nil {
+		return a.GetUser(userID)
+	}
+	return DefaultGetUser(userID)
+}
+
+func (a *SessionAuthentication) Middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return Chain(a)(next)
+}
+
+// Login starts a new session for the user.
+func (a *SessionAuthentication) Login(c gorim.Context, user interface{}) error {
+	userID, ok := utils.GetUserID(user)
+	if !ok {
+		return echo.NewHTTPError(http.StatusInternalServerError, "user has no ID field")
+	}
+	// rotate the session to prevent session fixation.
+	if session, _ := a.Manager.Get(c); session != nil {
+		if err := a.Manager.Store.Delete(session.ID); err != nil {
+			return err
+		}
+	}
+	_, err := a.Manager.Create(c, map[string]interface{}{
+		SessionUserIDKey: userID,
+	})
+	if err != nil {
+		return err
+	}
+	if a.CSRF != nil {
+		a.CSRF.Rotate(c)
+	}
+	c.SetUser(user)
+	return nil
+}
+
+func (a *SessionAuthentication) Logout(c gorim.Context) error {
+	return a.Manager.Destroy(c)
+}
+
+// LoginView logs the user in with email and password.
+func (a *SessionAuthentication) LoginView(c gorim.Context) error {
+	var credentials Credentials
+	if err := c.Bind(&credentials); err != nil || credentials.Email == "" || credentials.Password == "" {
+		return errors.Handle(errors.BadRequest("email and password are required"), c)
+	}
+	user, err := CheckCredentials(credentials)
+	if err != nil {
+		return errors.Handle(errors.BadRequest(err.Error()), c)
+	}
+	if err := a.Login(c, user); err != nil {
+		return errors.Handle(&errors.InternalServerError{Message: err.Error()}, c)
+	}
+	return c.Respond(http.StatusOK, gorim.Response{
+		"message": "Successfully logged in.",
+	})
+}
+
+func (a *SessionAuthentication) LogoutView(c gorim.Context) error {
+	if err := a.Logout(c); err != nil {
+		return errors.Handle(&errors.InternalServerError{Message: err.Error()}, c)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,162 @@
+package authentication
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/errors"
+	"github.com/rimba47prayoga/gorim.git/conf"
+	"github.com/rimba47prayoga/gorim.git/interfaces"
+	"github.com/rimba47prayoga/gorim.git/models"
+	"github.com/rimba47prayoga/gorim.git/utils"
+	"gorm.io/gorm"
+)
+
+// Token is the database-backed authentication token, one per user.
+type Token struct {
+	Key			string			`gorm:"type:varchar(40);primarykey" json:"token"`
+	UserID		uint			`gorm:"uniqueIndex;not null" json:"-"`
+	CreatedAt	time.Time		`gorm:"type:timestamp" json:"created_at"`
+}
+
+func (m Token) TableName() string {
+	return "gorim_tokens"
+}
+
+// GenerateKey returns a random 40 characters hex key.
+func GenerateKey() (string, error) {
+	bytes := make([]byte, 20)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+// GetOrCreateToken returns the token of the user, creating it if not exists.
+func GetOrCreateToken(db *gorm.DB, user interface{}) (*Token, error) {
+	userID, ok := utils.GetUserID(user)
+	if !ok {
+		return nil, fmt.Errorf("user has no ID field")
+	}
+	var token Token
+	err := db.Where("user_id = ?", userID).First(&token).Error
+	if err == nil {
+		return &token, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+	key, err := GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	token = Token{
+		Key: key,
+		UserID: userID,
+		CreatedAt: time.Now(),
+	}
+	if err := db.Create(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// RevokeToken deletes the token of the user, the next GetOrCreateToken issues a new key.
+func RevokeToken(db *gorm.DB, user interface{}) error {
+	userID, ok := utils.GetUserID(user)
+	if !ok {
+		return fmt.Errorf("user has no ID field")
+	}
+	return db.Where("user_id = ?", userID).Delete(&Token{}).Error
+}
+
+// TokenAuthentication authenticates requests by `Authorization: Token <key>` header.
+type TokenAuthentication struct {
+	Keyword		string
+	// GetUser loads the token owner, default loads conf.USER_MODEL.
+	GetUser		func(userID uint) (interface{}, error)
+}
+
+func (a *TokenAuthentication) GetKeyword() string {
+	if a.Keyword == "" {
+		return "Token"
+	}
+	return a.Keyword
+}
+
+func (a *TokenAuthentication) Authenticate(c echo.Context) (interface{}, error) {
+	header := c.Request().Header.Get(echo.HeaderAuthorization)
+	prefix := a.GetKeyword() + " "
+	if !strings.HasPrefix(header, prefix) {
+		return nil, nil
+	}
+	key := strings.TrimSpace(strings.TrimPrefix(header, prefix))
+	if key == "" || strings.Contains(key, " ") {
+		return nil, fmt.Errorf("invalid token header")
+	}
+	var token Token
+	if err := conf.DB.Where(&Token{Key: key}).First(&token).Error; err != nil {
+		return nil, fmt.Errorf("invalid token")
+	}
+	if a.GetUser != nil {
+		return a.GetUser(token.UserID)
+	}
+	return DefaultGetUser(token.UserID)
+}
+
+func (a *TokenAuthentication) Middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return Chain(a)(next)
+}
+
+// Credentials is the request body of ObtainAuthTokenView.
+type Credentials struct {
+	Email		string		`json:"email" form:"email"`
+	Password	string		`json:"password" form:"password"`
+}
+
+// CheckCredentials returns conf.USER_MODEL whose conf.USERNAME_FIELD is the email and
+// password matches.
+func CheckCredentials(credentials Credentials) (interface{}, error) {
+	user, err := models.GetUserByUsername(conf.DB, credentials.Email)
+	if err != nil || !checkPassword(user, credentials.Password) {
+		return nil, fmt.Errorf("unable to log in with provided credentials")
+	}
+	return user, nil
+}
+
+// checkPassword uses interfaces.IUser, otherwise compares Password field with utils.VerifyPassword.
+func checkPassword(user interface{}, password string) bool {
+	if checker, ok := user.(interfaces.IUser); ok {
+		return checker.CheckPassword(password)
+	}
+	hashed, err := utils.GetStructValue(user, "Password")
+	if err != nil {
+		return false
+	}
+	hashedPassword, ok := hashed.(string)
+	return ok && utils.VerifyPassword(password, hashedPassword)
+}
+
+// ObtainAuthTokenView issues token for valid credentials,
+// example: server.POST("/api-token-auth", authentication.ObtainAuthTokenView)
+func ObtainAuthTokenView(c gorim.Context) error {
+	var credentials Credentials
+	if err := c.Bind(&credentials); err != nil || credentials.Email == "" || credentials.Password == "" {
+		return errors.Handle(&errors.APIError{Status: http.StatusBadRequest, Message: "email and password are required"}, c)
+	}
+	user, err := CheckCredentials(credentials)
+	if err != nil {
+		return errors.Handle(&errors.APIError{Status: http.StatusBadRequest, Message: err.Error()}, c)
+	}
+	token, err := GetOrCreateToken(conf.DB, user)
+	if err != nil {
+		return errors.Handle(&errors.InternalServerError{Message: err.Error()}, c)
+	}
+	return c.Respond(http.StatusOK, token)
+}
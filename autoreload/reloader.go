@@ -0,0 +1,122 @@
+package autoreload
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+// ChildEnv is set in environment of the server started by Reloader.
+const ChildEnv = "GORIM_RUN_MAIN"
+
+// IsChild reports whether the process was started by Reloader.
+func IsChild() bool {
+	return os.Getenv(ChildEnv) == "true"
+}
+
+// Reloader builds Package, runs it with Args and restarts it after watched files change.
+// A failed build is printed and the previous server keeps running until the next change.
+type Reloader struct {
+	Watcher		Watcher
+	// Package built with go build, default ".".
+	Package		string
+	Args		[]string
+	Out			io.Writer
+	// StopTimeout is how long the server has to exit after interrupt before it's killed.
+	StopTimeout	time.Duration
+}
+
+// Run reloads until ctx is done, the binary is built in a temporary directory.
+func (r *Reloader) Run(ctx context.Context) error {
+	if r.Out == nil {
+		r.Out = os.Stdout
+	}
+	if r.Package == "" {
+		r.Package = "."
+	}
+	dir, err := os.MkdirTemp("", "gorim-reload")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	binary := filepath.Join(dir, "server")
+	if runtime.GOOS == "windows" {
+		binary += ".exe"
+	}
+
+	var server *process
+	defer func() {
+		r.stop(server)
+	}()
+	for {
+		if err := r.build(ctx, binary); err != nil {
+			fmt.Fprintf(r.Out, "Build failed, waiting for changes:\n%s\n", err)
+		} else {
+			r.stop(server)
+			server = r.start(binary)
+		}
+		changed := r.Watcher.Wait(ctx)
+		if changed == nil {
+			return nil
+		}
+		fmt.Fprintf(r.Out, "%s changed, reloading.\n", changed[0])
+	}
+}
+
+func (r *Reloader) build(ctx context.Context, binary string) error {
+	build := exec.CommandContext(ctx, "go", "build", "-o", binary, r.Package)
+	output, err := build.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s%w", output, err)
+	}
+	return nil
+}
+
+// process is the running server, done is closed when it exits.
+type process struct {
+	cmd		*exec.Cmd
+	done	chan struct{}
+}
+
+func (r *Reloader) start(binary string) *process {
+	cmd := exec.Command(binary, r.Args...)
+	cmd.Env = append(os.Environ(), ChildEnv + "=true")
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = r.Out
+	cmd.Stderr = r.Out
+	if err := cmd.Start(); err != nil {
+		fmt.Fprintln(r.Out, err)
+		return nil
+	}
+	server := &process{cmd: cmd, done: make(chan struct{})}
+	go func() {
+		cmd.Wait()
+		close(server.done)
+	}()
+	return server
+}
+
+// stop interrupts server so it can shut down, then kills it after StopTimeout.
+func (r *Reloader) stop(server *process) {
+	if server == nil {
+		return
+	}
+	timeout := r.StopTimeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	if runtime.GOOS == "windows" || server.cmd.Process.Signal(os.Interrupt) != nil {
+		server.cmd.Process.Kill()
+	}
+	select {
+	case <-server.done:
+	case <-time.After(timeout):
+		server.cmd.Process.Kill()
+		<-server.done
+	}
+}
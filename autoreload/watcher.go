@@ -0,0 +1,110 @@
+package autoreload
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Watcher polls modification time of files under Root, it needs no file system events
+// so it works the same on every platform and in containers with mounted sources.
+type Watcher struct {
+	Root		string
+	// Extensions of watched files, default DefaultExtensions.
+	Extensions	[]string
+	// Exclude lists names of directories which aren't walked, default DefaultExclude.
+	Exclude		[]string
+	// Interval between polls, default one second.
+	Interval	time.Duration
+}
+
+var DefaultExtensions = []string{".go", ".mod", ".sum", ".env", ".yaml", ".yml", ".json", ".tmpl", ".html"}
+var DefaultExclude = []string{".git", "node_modules", "vendor", "tmp", "static", "media"}
+
+// Snapshot returns modification time of watched files by path.
+func (w *Watcher) Snapshot() map[string]time.Time {
+	extensions := w.Extensions
+	if extensions == nil {
+		extensions = DefaultExtensions
+	}
+	exclude := w.Exclude
+	if exclude == nil {
+		exclude = DefaultExclude
+	}
+	files := map[string]time.Time{}
+	root := w.Root
+	if root == "" {
+		root = "."
+	}
+	filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if entry.IsDir() {
+			if path != root && (strings.HasPrefix(entry.Name(), ".") || contains(exclude, entry.Name())) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !contains(extensions, filepath.Ext(path)) && !contains(extensions, entry.Name()) {
+			return nil
+		}
+		if info, err := entry.Info(); err == nil {
+			files[path] = info.ModTime()
+		}
+		return nil
+	})
+	return files
+}
+
+// Wait blocks until watched files are created, changed or removed and returns their paths,
+// it returns nil when ctx is done.
+func (w *Watcher) Wait(ctx context.Context) []string {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	previous := w.Snapshot()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		current := w.Snapshot()
+		if changed := changes(previous, current); len(changed) > 0 {
+			return changed
+		}
+		previous = current
+	}
+}
+
+func changes(previous map[string]time.Time, current map[string]time.Time) []string {
+	changed := []string{}
+	for path, modified := range current {
+		if before, ok := previous[path]; !ok || !before.Equal(modified) {
+			changed = append(changed, path)
+		}
+	}
+	for path := range previous {
+		if _, ok := current[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+func contains(values []string, value string) bool {
+	for _, item := range values {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,205 @@
+package batch
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/conf"
+	"github.com/rimba47prayoga/gorim.git/errors"
+	"github.com/rimba47prayoga/gorim.git/interfaces"
+	"github.com/rimba47prayoga/gorim.git/permissions"
+	"github.com/rimba47prayoga/gorim.git/routers"
+	"gorm.io/gorm"
+)
+
+var methods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions,
+}
+
+// errRollback rolls back the transaction of atomic batches.
+var errRollback = stderrors.New("batch: rollback")
+
+// Request of a batch, path is absolute with query, example: "/api/v1/orders?page=2".
+type Request struct {
+	Method	string				`json:"method"`
+	Path	string				`json:"path"`
+	Headers	map[string]string	`json:"headers,omitempty"`
+	Body	json.RawMessage		`json:"body,omitempty"`
+}
+
+// Response of a request, bodies of JSON responses are kept as JSON, others are strings.
+type Response struct {
+	Status	int					`json:"status"`
+	Headers	map[string]string	`json:"headers,omitempty"`
+	Body	any					`json:"body,omitempty"`
+}
+
+// Endpoint serves a JSON array of requests in one round trip and responds with the
+// array of their responses in the same order, example:
+//
+//	(&batch.Endpoint{}).Register(api, "/batch")
+//
+//	POST /api/v1/batch?atomic=true
+//	[
+//	  {"method": "POST", "path": "/api/v1/orders", "body": {"product": 1}},
+//	  {"method": "GET", "path": "/api/v1/orders?status=open"}
+//	]
+//
+// Requests run one after another through the server with the headers of the batch,
+// so middlewares, authentication, permissions and throttles apply to every request.
+// Atomic batches run in one transaction of conf.DB, the first response with an error
+// status rolls it back and the following requests aren't run, they respond 424 Failed
+// Dependency.
+type Endpoint struct {
+	// Permissions and Throttles of the endpoint, checked before requests are run.
+	Permissions	[]interfaces.IPermission
+	Throttles	[]interfaces.IThrottle
+	// MaxRequests of a batch, default 20.
+	MaxRequests	int
+}
+
+func (b *Endpoint) GetMaxRequests() int {
+	if b.MaxRequests > 0 {
+		return b.MaxRequests
+	}
+	return 20
+}
+
+// Register adds POST route of the endpoint to group.
+func (b *Endpoint) Register(group *gorim.Group, path string) {
+	group.Add(http.MethodPost, path, b.Serve)
+}
+
+// Serve checks permissions and throttles, validates the batch and runs its requests.
+func (b *Endpoint) Serve(c gorim.Context) error {
+	for _, permission := range b.Permissions {
+		if !permission.HasPermission(c) {
+			return permissions.Deny(c, permission)
+		}
+	}
+	for _, throttle := range b.Throttles {
+		if allowed, wait := throttle.AllowRequest(c); !allowed {
+			return errors.Handle(&errors.ThrottledError{Wait: wait}, c)
+		}
+	}
+	var requests []Request
+	if err := json.NewDecoder(c.Request().Body).Decode(&requests); err != nil {
+		return errors.Handle(&errors.APIError{
+			Message: "Body must be a JSON array of requests.",
+			Status: http.StatusBadRequest,
+			Code: errors.CodeParseError,
+		}, c)
+	}
+	if err := b.validate(c, requests); err != nil {
+		return errors.Handle(err, c)
+	}
+	if c.QueryParam("atomic") != "true" {
+		responses := make([]*Response, 0, len(requests))
+		for _, request := range requests {
+			response, err := run(c, request)
+			if err != nil {
+				return errors.Handle(err, c)
+			}
+			responses = append(responses, response)
+		}
+		return c.JSON(http.StatusOK, responses)
+	}
+	if conf.DB == nil {
+		return errors.Handle(errors.ServerError("Atomic batches need conf.DB."), c)
+	}
+	outer := c.Request()
+	defer c.SetRequest(outer)
+	responses := make([]*Response, 0, len(requests))
+	err := conf.GetDB(outer.Context()).Transaction(func(tx *gorm.DB) error {
+		c.SetRequest(outer.WithContext(conf.WithDB(outer.Context(), tx)))
+		for _, request := range requests {
+			response, err := run(c, request)
+			if err != nil {
+				return err
+			}
+			responses = append(responses, response)
+			if response.Status >= http.StatusBadRequest {
+				return errRollback
+			}
+		}
+		return nil
+	})
+	if err != nil && err != errRollback {
+		return errors.Handle(err, c)
+	}
+	for len(responses) < len(requests) {
+		responses = append(responses, &Response{
+			Status: http.StatusFailedDependency,
+			Body: map[string]any{
+				"error": "Request wasn't run, an earlier request of the atomic batch failed.",
+				"code": "failed_dependency",
+			},
+		})
+	}
+	return c.JSON(http.StatusOK, responses)
+}
+
+// validate checks every request before any is run, batches can't be nested.
+func (b *Endpoint) validate(c gorim.Context, requests []Request) error {
+	if len(requests) == 0 {
+		return errors.Invalid("requests", "This list may not be empty.")
+	}
+	if len(requests) > b.GetMaxRequests() {
+		return errors.Invalid("requests", fmt.Sprintf("Ensure this list has at most %d requests.", b.GetMaxRequests()))
+	}
+	for i := range requests {
+		request := &requests[i]
+		request.Method = strings.ToUpper(request.Method)
+		if !slices.Contains(methods, request.Method) {
+			return errors.Invalid("requests", fmt.Sprintf("Request %d: %q is not a valid method.", i, request.Method))
+		}
+		if !strings.HasPrefix(request.Path, "/") || strings.HasPrefix(request.Path, "//") {
+			return errors.Invalid("requests", fmt.Sprintf("Request %d: path must be absolute, example: /api/v1/orders.", i))
+		}
+		path, _, _ := strings.Cut(request.Path, "?")
+		if path == c.Request().URL.Path {
+			return errors.Invalid("requests", fmt.Sprintf("Request %d: batches can't be nested.", i))
+		}
+	}
+	return nil
+}
+
+// run dispatches request through the server of c.
+func run(c gorim.Context, request Request) (*Response, error) {
+	header := http.Header{}
+	for name, value := range request.Headers {
+		header.Set(name, value)
+	}
+	var body []byte
+	if len(request.Body) > 0 && string(request.Body) != "null" {
+		body = request.Body
+	}
+	recorder, err := routers.DispatchPath(c, request.Method, request.Path, header, body)
+	if err != nil {
+		return nil, err
+	}
+	return responseOf(recorder), nil
+}
+
+func responseOf(recorder *httptest.ResponseRecorder) *Response {
+	response := &Response{Status: recorder.Code, Headers: map[string]string{}}
+	for name, values := range recorder.Header() {
+		response.Headers[name] = strings.Join(values, ", ")
+	}
+	if recorder.Body.Len() == 0 {
+		return response
+	}
+	if strings.HasPrefix(recorder.Header().Get(echo.HeaderContentType), echo.MIMEApplicationJSON) && json.Valid(recorder.Body.Bytes()) {
+		response.Body = json.RawMessage(recorder.Body.Bytes())
+	} else {
+		response.Body = recorder.Body.String()
+	}
+	return response
+}
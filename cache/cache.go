@@ -0,0 +1,188 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/rimba47prayoga/gorim.git/signals"
+)
+
+// Forever is a ttl of values which don't expire.
+const Forever time.Duration = -1
+
+// Backend stores raw values, keys are already namespaced by Cache.
+type Backend interface {
+	// Get returns false when key is missing or expired.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores value, zero ttl doesn't expire.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Add stores value only when key is missing, returns whether it was stored.
+	Add(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+	Delete(ctx context.Context, keys ...string) error
+	// Incr adds delta to the integer at key, missing key starts from zero and expires after ttl.
+	Incr(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error)
+	// DeleteMatching deletes keys starting with prefix whose rest matches pattern,
+	// where * matches any characters and ? one character.
+	DeleteMatching(ctx context.Context, prefix string, pattern string) error
+}
+
+// Cache encodes values as JSON in Backend under keys "<Prefix>:<Version>:<key>", so
+// caches sharing a backend don't collide and bumping Version invalidates the old keys.
+type Cache struct {
+	Backend		Backend
+	// Prefix namespaces keys, default "gorim".
+	Prefix		string
+	// Version is part of keys, default 1.
+	Version		int
+	// TTL is used when zero ttl is passed, zero doesn't expire.
+	TTL			time.Duration
+}
+
+// Default is used by CacheStore of throttling and sessions and by Middleware when
+// they don't set a cache.
+var Default = New(NewMemoryBackend())
+
+func New(backend Backend) *Cache {
+	return &Cache{
+		Backend: backend,
+		Prefix: "gorim",
+		Version: 1,
+	}
+}
+
+// Namespace returns cache sharing the backend with keys under name, example:
+// cache.Default.Namespace("products").Clear(ctx) deletes only keys of products.
+func (c *Cache) Namespace(name string) *Cache {
+	namespace := *c
+	namespace.Prefix = c.GetPrefix() + ":" + name
+	return &namespace
+}
+
+func (c *Cache) GetPrefix() string {
+	if c.Prefix == "" {
+		return "gorim"
+	}
+	return c.Prefix
+}
+
+func (c *Cache) GetVersion() int {
+	if c.Version == 0 {
+		return 1
+	}
+	return c.Version
+}
+
+// Key returns key of the backend.
+func (c *Cache) Key(key string) string {
+	return c.GetPrefix() + ":" + strconv.Itoa(c.GetVersion()) + ":" + key
+}
+
+func (c *Cache) ttl(ttl time.Duration) time.Duration {
+	if ttl == 0 {
+		ttl = c.TTL
+	}
+	if ttl < 0 {
+		return 0
+	}
+	return ttl
+}
+
+// Get decodes value of key into target, returns false when key is missing.
+func (c *Cache) Get(ctx context.Context, key string, target interface{}) (bool, error) {
+	data, ok, err := c.Backend.Get(ctx, c.Key(key))
+	if err != nil || !ok {
+		return false, err
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		return false, fmt.Errorf("cache: %s: %w", key, err)
+	}
+	return true, nil
+}
+
+// Set stores value of key for ttl, zero ttl is TTL of the cache and Forever doesn't expire.
+func (c *Cache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("cache: %s: %w", key, err)
+	}
+	return c.Backend.Set(ctx, c.Key(key), data, c.ttl(ttl))
+}
+
+// Add is Set when key is missing, returns whether value was stored.
+func (c *Cache) Add(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false, fmt.Errorf("cache: %s: %w", key, err)
+	}
+	return c.Backend.Add(ctx, c.Key(key), data, c.ttl(ttl))
+}
+
+func (c *Cache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	backendKeys := make([]string, len(keys))
+	for i, key := range keys {
+		backendKeys[i] = c.Key(key)
+	}
+	return c.Backend.Delete(ctx, backendKeys...)
+}
+
+// Incr adds delta to counter of key, ttl applies when the counter is created.
+func (c *Cache) Incr(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	return c.Backend.Incr(ctx, c.Key(key), delta, c.ttl(ttl))
+}
+
+// Clear deletes every key of the cache and its namespaces, of all versions.
+func (c *Cache) Clear(ctx context.Context) error {
+	return c.Backend.DeleteMatching(ctx, c.GetPrefix() + ":", "*")
+}
+
+// DeletePattern deletes keys of the current version matching pattern, * matches any
+// characters, example: c.DeletePattern(ctx, "users:*")
+func (c *Cache) DeletePattern(ctx context.Context, pattern string) error {
+	return c.Backend.DeleteMatching(ctx, c.Key(""), pattern)
+}
+
+// GetOrSet returns cached value of key, on miss it stores result of fn for ttl, example:
+//
+//	product, err := cache.GetOrSet(ctx, cache.Default, "product:1", time.Minute, func() (Product, error) {
+//		var product Product
+//		return product, db.First(&product, 1).Error
+//	})
+//
+// Errors of fn aren't cached, errors of the cache fall back to fn.
+func GetOrSet[T any](ctx context.Context, c *Cache, key string, ttl time.Duration, fn func() (T, error)) (T, error) {
+	var value T
+	if ok, err := c.Get(ctx, key, &value); err == nil && ok {
+		return value, nil
+	}
+	value, err := fn()
+	if err != nil {
+		return value, err
+	}
+	c.Set(ctx, key, value, ttl)
+	return value, nil
+}
+
+// ClearOnChange clears c when instances of model T are saved or deleted, so a
+// namespace caching T, such as responses of its viewset, isn't stale. Bulk changes
+// clear it when signals.GormPlugin is used, example:
+//
+//	products := cache.Default.Namespace("products")
+//	cache.ClearOnChange[Product](products)
+func ClearOnChange[T any](c *Cache) {
+	clearCache := func(event signals.Event[T]) {
+		ctx := context.Background()
+		if event.Context != nil {
+			ctx = event.Context.Request().Context()
+		}
+		c.Clear(ctx)
+	}
+	signals.Connect(signals.PostSave, clearCache)
+	signals.Connect(signals.PostDelete, clearCache)
+	signals.Connect(signals.PostChange, clearCache)
+}
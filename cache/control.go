@@ -0,0 +1,166 @@
+package cache
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git"
+)
+
+const (
+	HeaderCacheControl	= "Cache-Control"
+	HeaderExpires		= "Expires"
+)
+
+// Control declares how browsers and shared caches such as CDNs may store responses,
+// see Apply, example: cache.Control{Public: true, MaxAge: time.Minute, SharedMaxAge: time.Hour}
+type Control struct {
+	// Public lets shared caches store responses of anonymous requests.
+	Public					bool
+	// Private limits storing to the browser, responses of authenticated requests are
+	// always private.
+	Private					bool
+	// NoCache requires revalidation before a stored response is used.
+	NoCache					bool
+	// NoStore forbids storing the response.
+	NoStore					bool
+	MaxAge					time.Duration
+	// SharedMaxAge is s-maxage of shared caches, dropped for private responses.
+	SharedMaxAge			time.Duration
+	StaleWhileRevalidate	time.Duration
+	StaleIfError			time.Duration
+	MustRevalidate			bool
+	Immutable				bool
+	// NoStoreAuthenticated forbids storing responses of authenticated requests
+	// instead of making them private.
+	NoStoreAuthenticated	bool
+	// Vary lists request headers selecting the response, Authorization and Cookie are
+	// added to responses which aren't private, since they may authenticate requests.
+	Vary					[]string
+}
+
+// NoStore is Control of responses which must never be stored.
+var NoStore = Control{NoStore: true}
+
+// Value returns Cache-Control of the response, private when authenticated.
+func (cc Control) Value(authenticated bool) string {
+	if cc.NoStore || (authenticated && cc.NoStoreAuthenticated) {
+		if authenticated || cc.Private {
+			return "private, no-store"
+		}
+		return "no-store"
+	}
+	private := cc.Private || authenticated
+	directives := []string{}
+	if private {
+		directives = append(directives, "private")
+	} else if cc.Public {
+		directives = append(directives, "public")
+	}
+	if cc.NoCache {
+		directives = append(directives, "no-cache")
+	}
+	if cc.MaxAge > 0 || (cc.Public && !private) {
+		directives = append(directives, "max-age=" + seconds(cc.MaxAge))
+	}
+	if cc.SharedMaxAge > 0 && !private {
+		directives = append(directives, "s-maxage=" + seconds(cc.SharedMaxAge))
+	}
+	if cc.StaleWhileRevalidate > 0 {
+		directives = append(directives, "stale-while-revalidate=" + seconds(cc.StaleWhileRevalidate))
+	}
+	if cc.StaleIfError > 0 {
+		directives = append(directives, "stale-if-error=" + seconds(cc.StaleIfError))
+	}
+	if cc.MustRevalidate {
+		directives = append(directives, "must-revalidate")
+	}
+	if cc.Immutable {
+		directives = append(directives, "immutable")
+	}
+	return strings.Join(directives, ", ")
+}
+
+// Apply sets Cache-Control, Expires and Vary of the response when it's written, so
+// they match the final status and user: error responses get no-store since shared
+// caches mustn't keep them, and users authenticated after Apply, example: by
+// middlewares of the route, get private responses. Expires mirrors max-age for
+// HTTP/1.0 caches.
+func (cc Control) Apply(c echo.Context) {
+	vary := cc.Vary
+	if !cc.Private && !cc.NoStore {
+		vary = append(append([]string{}, vary...), echo.HeaderAuthorization, echo.HeaderCookie)
+	}
+	AddVary(c, vary...)
+	response := c.Response()
+	response.Before(func() {
+		authenticated := IsAuthenticated(c)
+		header := response.Header()
+		if response.Status >= http.StatusBadRequest {
+			header.Set(HeaderCacheControl, NoStore.Value(authenticated))
+			header.Set(HeaderExpires, expired)
+			return
+		}
+		value := cc.Value(authenticated)
+		if value == "" {
+			return
+		}
+		header.Set(HeaderCacheControl, value)
+		if cc.MaxAge > 0 && !strings.Contains(value, "no-") {
+			header.Set(HeaderExpires, time.Now().Add(cc.MaxAge).UTC().Format(http.TimeFormat))
+		} else {
+			header.Set(HeaderExpires, expired)
+		}
+	})
+}
+
+// expired is Expires of responses which mustn't be reused.
+var expired = time.Unix(0, 0).UTC().Format(http.TimeFormat)
+
+// IsAuthenticated reports whether the response depends on credentials of the request,
+// the user is set by authentication or the request has Authorization header.
+func IsAuthenticated(c echo.Context) bool {
+	return c.Get(gorim.UserContextKey) != nil || c.Request().Header.Get(echo.HeaderAuthorization) != ""
+}
+
+// AddVary adds headers to Vary of the response, skipping headers it already has.
+func AddVary(c echo.Context, headers ...string) {
+	header := c.Response().Header()
+	existing := map[string]bool{}
+	for _, value := range header.Values(echo.HeaderVary) {
+		for _, name := range strings.Split(value, ",") {
+			existing[http.CanonicalHeaderKey(strings.TrimSpace(name))] = true
+		}
+	}
+	for _, name := range headers {
+		name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+		if name == "" || existing[name] || existing["*"] {
+			continue
+		}
+		existing[name] = true
+		header.Add(echo.HeaderVary, name)
+	}
+}
+
+// ControlMiddleware applies control to responses of GET and HEAD requests, other
+// methods get no-store, example: group.Use(cache.ControlMiddleware(cache.Control{Public: true, MaxAge: time.Minute}))
+func ControlMiddleware(control Control) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			method := c.Request().Method
+			if method == http.MethodGet || method == http.MethodHead {
+				control.Apply(c)
+			} else {
+				NoStore.Apply(c)
+			}
+			return next(c)
+		}
+	}
+}
+
+func seconds(duration time.Duration) string {
+	return strconv.Itoa(int(duration.Seconds()))
+}
@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/rimba47prayoga/gorim.git/signals"
+	"gorm.io/gorm/schema"
+)
+
+var placeholder = regexp.MustCompile(`\{(\w+)\}`)
+
+// InvalidateOnChange deletes keys of Default matching patterns when instances of model T
+// change, * matches any characters. {field} placeholders are replaced by the field of the
+// changed instance, by Go or column name, and by * when many rows changed. Changes through
+// gorm, such as bulk updates and deletes, need signals.GormPlugin, example:
+//
+//	conf.DB.Use(signals.GormPlugin{})
+//	cache.InvalidateOnChange[User]("users:*", "profile:{id}")
+func InvalidateOnChange[T any](patterns ...string) {
+	invalidate := func(event signals.Event[T]) {
+		ctx := context.Background()
+		if event.Context != nil {
+			ctx = event.Context.Request().Context()
+		}
+		for _, pattern := range patterns {
+			pattern = expandPattern(pattern, event.Instance)
+			if err := Default.DeletePattern(ctx, pattern); err != nil {
+				slog.Error("cache invalidation failed", "pattern", pattern, "error", err)
+			}
+		}
+	}
+	signals.Connect(signals.PostSave, invalidate)
+	signals.Connect(signals.PostDelete, invalidate)
+	signals.Connect(signals.PostChange, invalidate)
+}
+
+// expandPattern replaces {field} placeholders of pattern by fields of instance.
+func expandPattern[T any](pattern string, instance *T) string {
+	return placeholder.ReplaceAllStringFunc(pattern, func(match string) string {
+		if instance == nil {
+			return "*"
+		}
+		value, ok := fieldValue(reflect.ValueOf(instance).Elem(), match[1:len(match) - 1])
+		if !ok {
+			return "*"
+		}
+		return fmt.Sprint(value)
+	})
+}
+
+// fieldValue finds field by Go name or snake case column name, embedded structs included.
+func fieldValue(value reflect.Value, name string) (any, bool) {
+	if value.Kind() != reflect.Struct {
+		return nil, false
+	}
+	valueType := value.Type()
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if field.Anonymous {
+			if found, ok := fieldValue(reflect.Indirect(value.Field(i)), name); ok {
+				return found, true
+			}
+			continue
+		}
+		if strings.EqualFold(field.Name, name) || columnName(field) == name {
+			found := reflect.Indirect(value.Field(i))
+			if !found.IsValid() {
+				return nil, false
+			}
+			return found.Interface(), true
+		}
+	}
+	return nil, false
+}
+
+// columnName returns column of gorm tag or the column of gorm naming strategy.
+func columnName(field reflect.StructField) string {
+	for _, setting := range strings.Split(field.Tag.Get("gorm"), ";") {
+		if column, ok := strings.CutPrefix(setting, "column:"); ok {
+			return column
+		}
+	}
+	return schema.NamingStrategy{}.ColumnName("", field.Name)
+}
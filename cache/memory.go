@@ -0,0 +1,184 @@
+package cache
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	value		[]byte
+	expiresAt	time.Time
+}
+
+func (e memoryEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && now.After(e.expiresAt)
+}
+
+// MemoryBackend keeps values in process memory, use RedisBackend with several processes.
+// Expired values are removed when read and by sweeps every SweepInterval writes.
+type MemoryBackend struct {
+	// MaxEntries evicts values closest to expiry past the limit, zero doesn't limit.
+	MaxEntries	int
+	mu			sync.Mutex
+	entries		map[string]memoryEntry
+	writes		int
+}
+
+// SweepInterval is the number of writes between sweeps of expired values.
+const SweepInterval = 1000
+
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{
+		entries: map[string]memoryEntry{},
+	}
+}
+
+func (b *MemoryBackend) get(key string, now time.Time) (memoryEntry, bool) {
+	entry, ok := b.entries[key]
+	if ok && entry.expired(now) {
+		delete(b.entries, key)
+		return entry, false
+	}
+	return entry, ok
+}
+
+func (b *MemoryBackend) set(key string, value []byte, ttl time.Duration, now time.Time) {
+	entry := memoryEntry{value: append([]byte{}, value...)}
+	if ttl > 0 {
+		entry.expiresAt = now.Add(ttl)
+	}
+	b.entries[key] = entry
+	b.writes++
+	if b.writes % SweepInterval == 0 {
+		b.sweep(now)
+	}
+	if b.MaxEntries > 0 && len(b.entries) > b.MaxEntries {
+		b.sweep(now)
+		b.evict(key)
+	}
+}
+
+func (b *MemoryBackend) sweep(now time.Time) {
+	for key, entry := range b.entries {
+		if entry.expired(now) {
+			delete(b.entries, key)
+		}
+	}
+}
+
+// evict removes values expiring first until MaxEntries, values without expiry last.
+func (b *MemoryBackend) evict(keep string) {
+	for len(b.entries) > b.MaxEntries {
+		victim := ""
+		var victimEntry memoryEntry
+		for key, entry := range b.entries {
+			if key == keep {
+				continue
+			}
+			if victim == "" || (!entry.expiresAt.IsZero() && (victimEntry.expiresAt.IsZero() || entry.expiresAt.Before(victimEntry.expiresAt))) {
+				victim, victimEntry = key, entry
+			}
+		}
+		if victim == "" {
+			return
+		}
+		delete(b.entries, victim)
+	}
+}
+
+func (b *MemoryBackend) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	entry, ok := b.get(key, time.Now())
+	if !ok {
+		return nil, false, nil
+	}
+	return append([]byte{}, entry.value...), true, nil
+}
+
+func (b *MemoryBackend) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.set(key, value, ttl, time.Now())
+	return nil
+}
+
+func (b *MemoryBackend) Add(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	if _, ok := b.get(key, now); ok {
+		return false, nil
+	}
+	b.set(key, value, ttl, now)
+	return true, nil
+}
+
+func (b *MemoryBackend) Delete(ctx context.Context, keys ...string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, key := range keys {
+		delete(b.entries, key)
+	}
+	return nil
+}
+
+func (b *MemoryBackend) Incr(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	entry, ok := b.get(key, now)
+	if !ok {
+		b.set(key, []byte(strconv.FormatInt(delta, 10)), ttl, now)
+		return delta, nil
+	}
+	value, err := strconv.ParseInt(string(entry.value), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	value += delta
+	entry.value = []byte(strconv.FormatInt(value, 10))
+	b.entries[key] = entry
+	return value, nil
+}
+
+func (b *MemoryBackend) DeleteMatching(ctx context.Context, prefix string, pattern string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for key := range b.entries {
+		if strings.HasPrefix(key, prefix) && Match(pattern, key[len(prefix):]) {
+			delete(b.entries, key)
+		}
+	}
+	return nil
+}
+
+// Match reports whether key matches pattern, * matches any characters and ? one character.
+func Match(pattern string, key string) bool {
+	// star and its key position for backtracking.
+	star, starKey := -1, 0
+	p, k := 0, 0
+	for k < len(key) {
+		switch {
+		case p < len(pattern) && (pattern[p] == '?' || pattern[p] == key[k]):
+			p++
+			k++
+		case p < len(pattern) && pattern[p] == '*':
+			star, starKey = p, k
+			p++
+		case star >= 0:
+			p = star + 1
+			starKey++
+			k = starKey
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
@@ -0,0 +1,165 @@
+package cache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/utils"
+)
+
+// StatusHeader tells whether the response was served from the cache, HIT or MISS.
+const StatusHeader = "X-Cache"
+
+// MiddlewareConfig of Middleware.
+type MiddlewareConfig struct {
+	// Cache defaults to Default namespaced by "responses".
+	Cache		*Cache
+	// TTL of responses, default 1 minute.
+	TTL			time.Duration
+	// KeyFunc defaults to method, URI, Accept and Accept-Language headers, host, tenant and
+	// user of the request. Requests it returns empty key for aren't cached.
+	KeyFunc		func(gorim.Context) string
+	Skipper		func(gorim.Context) bool
+}
+
+// response is the stored response.
+type response struct {
+	Status		int			`json:"status"`
+	Header		http.Header	`json:"header"`
+	Body		[]byte		`json:"body"`
+}
+
+// Middleware caches 200 responses of GET and HEAD requests, it must run after authentication
+// since responses are cached per user. Clear the cache when data changes, example:
+//
+//	products := cache.Default.Namespace("products")
+//	cache.ClearOnChange[Product](products)
+//	routeGroup := group.Group("/products", cache.Middleware(cache.MiddlewareConfig{Cache: products}))
+//	routers.NewDefaultRouter[*ProductViewSet](routeGroup, NewProductViewSet)
+func Middleware(config MiddlewareConfig) echo.MiddlewareFunc {
+	if config.Cache == nil {
+		config.Cache = Default.Namespace("responses")
+	}
+	if config.TTL == 0 {
+		config.TTL = time.Minute
+	}
+	if config.KeyFunc == nil {
+		config.KeyFunc = defaultKey
+	}
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			request := c.Request()
+			if request.Method != http.MethodGet && request.Method != http.MethodHead {
+				return next(c)
+			}
+			ctx := gorim.NewContext(c)
+			if config.Skipper != nil && config.Skipper(ctx) {
+				return next(c)
+			}
+			key := config.KeyFunc(ctx)
+			if key == "" {
+				return next(c)
+			}
+			return serve(c, config.Cache, key, config.TTL, func() error {
+				return next(c)
+			})
+		}
+	}
+}
+
+// serve writes response cached under key, otherwise it records response of next and
+// caches it when it's 200.
+func serve(c echo.Context, store *Cache, key string, ttl time.Duration, next func() error) error {
+	request := c.Request()
+	var cached response
+	if ok, _ := store.Get(request.Context(), key, &cached); ok {
+		header := c.Response().Header()
+		for name, values := range cached.Header {
+			header[name] = values
+		}
+		header.Set(StatusHeader, "HIT")
+		c.Response().WriteHeader(cached.Status)
+		if request.Method == http.MethodHead {
+			return nil
+		}
+		_, err := c.Response().Write(cached.Body)
+		return err
+	}
+	c.Response().Header().Set(StatusHeader, "MISS")
+	recorder := &responseRecorder{ResponseWriter: c.Response().Writer}
+	c.Response().Writer = recorder
+	defer func() {
+		c.Response().Writer = recorder.ResponseWriter
+	}()
+	if err := next(); err != nil {
+		return err
+	}
+	if c.Response().Status != http.StatusOK || request.Method == http.MethodHead {
+		return nil
+	}
+	header := c.Response().Header().Clone()
+	header.Del(StatusHeader)
+	header.Del("Set-Cookie")
+	store.Set(request.Context(), key, &response{
+		Status: http.StatusOK,
+		Header: header,
+		Body: recorder.body.Bytes(),
+	}, ttl)
+	return nil
+}
+
+func defaultKey(ctx gorim.Context) string {
+	request := ctx.Request()
+	user, ok := userKey(ctx)
+	if !ok {
+		return ""
+	}
+	hash := sha256.Sum256([]byte(
+		request.Method + " " + request.URL.RequestURI() + "\n" +
+		request.Header.Get(echo.HeaderAccept) + "\n" +
+		request.Header.Get("Accept-Language") + "\n" +
+		scopeKey(ctx) + "\n" +
+		user,
+	))
+	return "response:" + hex.EncodeToString(hash[:])
+}
+
+// scopeKey separates responses of hosts and tenants sharing the same paths.
+func scopeKey(ctx gorim.Context) string {
+	tenant, _ := ctx.Get(gorim.TenantContextKey).(string)
+	return ctx.Request().Host + "\n" + tenant
+}
+
+// userKey identifies the user of the request, false when the user has no ID, so its
+// responses can't be told apart from other users' and mustn't be cached.
+func userKey(ctx gorim.Context) (string, bool) {
+	if !ctx.IsAuthenticated() {
+		return "anonymous", true
+	}
+	id, err := utils.GetStructValue(ctx.User(), "ID")
+	if err != nil || id == nil || reflect.ValueOf(id).IsZero() {
+		return "", false
+	}
+	return fmt.Sprintf("user_%v", id), true
+}
+
+type responseRecorder struct {
+	http.ResponseWriter
+	body		bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
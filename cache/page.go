@@ -0,0 +1,156 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/conf"
+	"github.com/rimba47prayoga/gorim.git/rbac"
+	"github.com/rimba47prayoga/gorim.git/utils"
+)
+
+// Vary selects requests sharing a cached page.
+type Vary int
+
+const (
+	// VaryByUser caches pages per user, anonymous requests share pages.
+	VaryByUser Vary = iota
+	// VaryByRole shares pages between users having the same roles.
+	VaryByRole
+	// VaryNone shares pages between every user, for public data only.
+	VaryNone
+)
+
+// Page caches rendered responses of viewset actions keyed by path, sorted query params,
+// Accept headers, host, tenant and the user or roles of the request, example:
+//
+//	var productPages = &cache.Page{TTL: 5 * time.Minute, Vary: cache.VaryByRole}
+//
+//	mixins.NewModelViewSet(mixins.GenericViewSetParams[Product]{..., CachePage: productPages})
+//	cache.ClearOnChange[Product](productPages.GetCache())
+//
+// Only 200 responses of GET requests are cached, after permissions and throttles are checked.
+// Responses of authenticated users without ID aren't cached unless Vary or KeyFunc tell
+// them apart.
+type Page struct {
+	// Cache defaults to Default namespaced by "pages".
+	Cache		*Cache
+	// TTL of pages, default 1 minute.
+	TTL			time.Duration
+	// Actions cached, default List and Retrieve.
+	Actions		[]string
+	Vary		Vary
+	// Roles returns roles of the user for VaryByRole, default roles of rbac.
+	Roles		func(gorim.Context) []string
+	// KeyFunc replaces the vary part of the key, pages are still separated by path.
+	KeyFunc		func(gorim.Context) string
+}
+
+func (p *Page) GetCache() *Cache {
+	if p.Cache == nil {
+		return Default.Namespace("pages")
+	}
+	return p.Cache
+}
+
+func (p *Page) GetTTL() time.Duration {
+	if p.TTL == 0 {
+		return time.Minute
+	}
+	return p.TTL
+}
+
+// Caches reports whether pages of action are cached.
+func (p *Page) Caches(action string) bool {
+	if len(p.Actions) == 0 {
+		return action == "List" || action == "Retrieve"
+	}
+	return utils.Contains(p.Actions, action)
+}
+
+// Serve writes the cached page of the request, otherwise it runs action and caches its response.
+func (p *Page) Serve(c gorim.Context, action func() error) error {
+	if c.Request().Method != http.MethodGet && c.Request().Method != http.MethodHead {
+		return action()
+	}
+	key, ok := p.key(c)
+	if !ok {
+		return action()
+	}
+	return serve(c, p.pathCache(c.Request().URL.Path), key, p.GetTTL(), action)
+}
+
+// Invalidate deletes every cached page.
+func (p *Page) Invalidate(ctx context.Context) error {
+	return p.GetCache().Clear(ctx)
+}
+
+// InvalidatePath deletes cached pages of path for every user and query, example:
+// page.InvalidatePath(ctx, "/api/v1/products/1") after changing product 1 outside of the viewset.
+func (p *Page) InvalidatePath(ctx context.Context, path string) error {
+	return p.pathCache(path).Clear(ctx)
+}
+
+func (p *Page) pathCache(path string) *Cache {
+	return p.GetCache().Namespace(strings.TrimSuffix(path, "/"))
+}
+
+// key returns key of the page, false when the user of the request can't be identified.
+func (p *Page) key(c gorim.Context) (string, bool) {
+	request := c.Request()
+	query := request.URL.Query()
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var builder strings.Builder
+	for _, name := range names {
+		values := append([]string{}, query[name]...)
+		sort.Strings(values)
+		for _, value := range values {
+			builder.WriteString(name + "=" + value + "&")
+		}
+	}
+	builder.WriteString("\n" + request.Header.Get(echo.HeaderAccept))
+	builder.WriteString("\n" + request.Header.Get("Accept-Language"))
+	builder.WriteString("\n" + scopeKey(c))
+	vary, ok := p.vary(c)
+	if !ok {
+		return "", false
+	}
+	builder.WriteString("\n" + vary)
+	hash := sha256.Sum256([]byte(builder.String()))
+	return hex.EncodeToString(hash[:]), true
+}
+
+func (p *Page) vary(c gorim.Context) (string, bool) {
+	if p.KeyFunc != nil {
+		return p.KeyFunc(c), true
+	}
+	switch {
+	case p.Vary == VaryNone:
+		return "", true
+	case !c.IsAuthenticated():
+		return "anonymous", true
+	case p.Vary == VaryByRole:
+		var roles []string
+		if p.Roles != nil {
+			roles = append(roles, p.Roles(c)...)
+		} else if userID, ok := utils.GetUserID(c.User()); ok {
+			roles = rbac.GetUserRoles(conf.DB, userID)
+		} else {
+			return "", false
+		}
+		sort.Strings(roles)
+		return "roles:" + strings.Join(roles, ","), true
+	}
+	return userKey(c)
+}
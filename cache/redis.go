@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// incrScript increments KEYS[1] by ARGV[1] and sets expiry ARGV[2] (ms) when the key is created.
+var incrScript = redis.NewScript(`
+local value = redis.call("INCRBY", KEYS[1], ARGV[1])
+if value == tonumber(ARGV[1]) and tonumber(ARGV[2]) > 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return value
+`)
+
+// RedisBackend shares values between processes.
+type RedisBackend struct {
+	Client		redis.UniversalClient
+	// ScanCount is the COUNT hint of SCAN used by DeleteMatching, default 500.
+	ScanCount	int64
+}
+
+func NewRedisBackend(client redis.UniversalClient) *RedisBackend {
+	return &RedisBackend{
+		Client: client,
+	}
+}
+
+func (b *RedisBackend) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	data, err := b.Client.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+func (b *RedisBackend) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return b.Client.Set(ctx, key, value, ttl).Err()
+}
+
+func (b *RedisBackend) Add(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	return b.Client.SetNX(ctx, key, value, ttl).Result()
+}
+
+func (b *RedisBackend) Delete(ctx context.Context, keys ...string) error {
+	return b.Client.Del(ctx, keys...).Err()
+}
+
+func (b *RedisBackend) Incr(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	return incrScript.Run(ctx, b.Client, []string{key}, delta, ttl.Milliseconds()).Int64()
+}
+
+// DeleteMatching scans keys matching prefix and pattern, keys of a cluster are scanned
+// on every master.
+func (b *RedisBackend) DeleteMatching(ctx context.Context, prefix string, pattern string) error {
+	match := escapeGlob(prefix) + escapeGlob(pattern, '*', '?')
+	if cluster, ok := b.Client.(*redis.ClusterClient); ok {
+		return cluster.ForEachMaster(ctx, func(ctx context.Context, client *redis.Client) error {
+			return b.deleteMatching(ctx, client, match)
+		})
+	}
+	return b.deleteMatching(ctx, b.Client, match)
+}
+
+func (b *RedisBackend) deleteMatching(ctx context.Context, client redis.Cmdable, match string) error {
+	count := b.ScanCount
+	if count == 0 {
+		count = 500
+	}
+	var cursor uint64
+	for {
+		keys, next, err := client.Scan(ctx, cursor, match, count).Result()
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			// keys are deleted one by one, keys of a cluster may be in different slots.
+			if err := client.Del(ctx, key).Err(); err != nil {
+				return err
+			}
+		}
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+// escapeGlob escapes characters of SCAN MATCH patterns except keep.
+func escapeGlob(value string, keep ...byte) string {
+	escaped := make([]byte, 0, len(value))
+	for i := 0; i < len(value); i++ {
+		switch char := value[i]; char {
+		case '*', '?', '[', ']', '\\':
+			if !bytes.Contains(keep, []byte{char}) {
+				escaped = append(escaped, '\\')
+			}
+		}
+		escaped = append(escaped, value[i])
+	}
+	return string(escaped)
+}
+
+func (b *RedisBackend) Close() error {
+	return b.Client.Close()
+}
@@ -0,0 +1,37 @@
+package cache
+
+import (
+	"io"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rimba47prayoga/gorim.git/settings"
+)
+
+// FromSettings returns Cache of settings.Get().Cache, backed by redis when URL is set
+// and by memory otherwise, example: cache.Default, err = cache.FromSettings()
+func FromSettings() (*Cache, error) {
+	config := settings.Get().Cache
+	var backend Backend = NewMemoryBackend()
+	if config.URL != "" {
+		options, err := redis.ParseURL(config.URL)
+		if err != nil {
+			return nil, err
+		}
+		backend = NewRedisBackend(redis.NewClient(options))
+	}
+	return &Cache{
+		Backend: backend,
+		Prefix: config.Prefix,
+		Version: config.Version,
+		TTL: config.TTL,
+	}, nil
+}
+
+// Close closes the backend when it holds connections, register it with
+// server.OnShutdown so redis connections are closed on shutdown.
+func (c *Cache) Close() error {
+	if closer, ok := c.Backend.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
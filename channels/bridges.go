@@ -0,0 +1,61 @@
+package channels
+
+import (
+	"github.com/rimba47prayoga/gorim.git/sse"
+	"github.com/rimba47prayoga/gorim.git/websocket"
+)
+
+// StreamEvents writes messages of subscription to stream as events named by their type
+// until the stream ends, then closes subscription, example:
+//
+//	return c.EventStream(func(stream *sse.Stream) error {
+//		subscription, err := channels.Subscribe(stream.Context(), "dashboard")
+//		if err != nil {
+//			return err
+//		}
+//		return channels.StreamEvents(stream, subscription)
+//	})
+func StreamEvents(stream *sse.Stream, subscription Subscription) error {
+	defer subscription.Close()
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case message, ok := <-subscription.Messages():
+			if !ok {
+				return nil
+			}
+			// slow clients miss messages instead of holding back the subscription.
+			stream.TrySend(sse.Event{Event: message.Type, Data: []byte(message.Data)})
+		}
+	}
+}
+
+// Forward sends messages of subscription to session as json in the background until
+// the connection closes, then closes subscription, example in OnOpen:
+//
+//	subscription, err := channels.Subscribe(s.Request().Context(), "user:" + userID)
+//	if err != nil {
+//		s.Close(websocket.CloseInternalError, "")
+//		return
+//	}
+//	channels.Forward(s, subscription)
+func Forward(session *websocket.Session, subscription Subscription) {
+	done := session.Request().Context().Done()
+	go func() {
+		defer subscription.Close()
+		for {
+			select {
+			case <-done:
+				return
+			case message, ok := <-subscription.Messages():
+				if !ok {
+					return
+				}
+				if err := session.Send(message); err != nil {
+					return
+				}
+			}
+		}
+	}()
+}
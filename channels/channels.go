@@ -0,0 +1,57 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Message is published to a group and received by subscriptions which joined it.
+type Message struct {
+	Group	string			`json:"group"`
+	Type	string			`json:"type"`
+	Data	json.RawMessage	`json:"data"`
+}
+
+// Decode unmarshals data of the message into v.
+func (m *Message) Decode(v any) error {
+	return json.Unmarshal(m.Data, v)
+}
+
+// Backend fans out messages of groups to subscriptions, messages aren't stored, only
+// subscriptions open when a message is published receive it.
+type Backend interface {
+	Publish(ctx context.Context, message *Message) error
+	Subscribe(ctx context.Context, groups ...string) (Subscription, error)
+}
+
+// Subscription receives messages of the groups it joined until it's closed. Messages
+// are dropped when its buffer is full, so a slow receiver doesn't hold back others.
+type Subscription interface {
+	Messages() <-chan *Message
+	Join(ctx context.Context, groups ...string) error
+	Leave(ctx context.Context, groups ...string) error
+	// Close leaves every group and closes Messages.
+	Close() error
+}
+
+// Default is shared by processes only with the redis backend, configure it with
+// FromSettings so messages published by workers reach connections of servers.
+var Default Backend = NewMemoryBackend()
+
+// DefaultBufferSize is how many messages wait for a subscription.
+var DefaultBufferSize = 64
+
+// Publish sends data as json to subscriptions of group in Default, example:
+// channels.Publish(ctx, "user:" + id, "notification", notification)
+func Publish(ctx context.Context, group string, messageType string, data any) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return Default.Publish(ctx, &Message{Group: group, Type: messageType, Data: encoded})
+}
+
+// Subscribe returns subscription of Default joining groups.
+func Subscribe(ctx context.Context, groups ...string) (Subscription, error) {
+	return Default.Subscribe(ctx, groups...)
+}
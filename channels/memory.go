@@ -0,0 +1,120 @@
+package channels
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryBackend fans out messages within the process.
+type MemoryBackend struct {
+	// BufferSize of subscriptions, default DefaultBufferSize.
+	BufferSize	int
+	mu			sync.RWMutex
+	groups		map[string]map[*memorySubscription]struct{}
+}
+
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{groups: map[string]map[*memorySubscription]struct{}{}}
+}
+
+func (b *MemoryBackend) Publish(ctx context.Context, message *Message) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for subscription := range b.groups[message.Group] {
+		subscription.deliver(message)
+	}
+	return nil
+}
+
+func (b *MemoryBackend) Subscribe(ctx context.Context, groups ...string) (Subscription, error) {
+	size := b.BufferSize
+	if size <= 0 {
+		size = DefaultBufferSize
+	}
+	subscription := &memorySubscription{
+		backend: b,
+		messages: make(chan *Message, size),
+		groups: map[string]struct{}{},
+	}
+	return subscription, subscription.Join(ctx, groups...)
+}
+
+type memorySubscription struct {
+	backend		*MemoryBackend
+	messages	chan *Message
+	mu			sync.Mutex
+	groups		map[string]struct{}
+	closed		bool
+}
+
+func (s *memorySubscription) Messages() <-chan *Message {
+	return s.messages
+}
+
+func (s *memorySubscription) deliver(message *Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	select {
+	case s.messages <- message:
+	default:
+	}
+}
+
+func (s *memorySubscription) Join(ctx context.Context, groups ...string) error {
+	s.backend.mu.Lock()
+	defer s.backend.mu.Unlock()
+	for _, group := range groups {
+		if s.backend.groups[group] == nil {
+			s.backend.groups[group] = map[*memorySubscription]struct{}{}
+		}
+		s.backend.groups[group][s] = struct{}{}
+		s.mu.Lock()
+		s.groups[group] = struct{}{}
+		s.mu.Unlock()
+	}
+	return nil
+}
+
+func (s *memorySubscription) Leave(ctx context.Context, groups ...string) error {
+	s.backend.mu.Lock()
+	defer s.backend.mu.Unlock()
+	for _, group := range groups {
+		s.leave(group)
+	}
+	return nil
+}
+
+// leave removes the subscription from group, the lock of the backend is held.
+func (s *memorySubscription) leave(group string) {
+	delete(s.backend.groups[group], s)
+	if len(s.backend.groups[group]) == 0 {
+		delete(s.backend.groups, group)
+	}
+	s.mu.Lock()
+	delete(s.groups, group)
+	s.mu.Unlock()
+}
+
+func (s *memorySubscription) Close() error {
+	s.backend.mu.Lock()
+	defer s.backend.mu.Unlock()
+	s.mu.Lock()
+	groups := make([]string, 0, len(s.groups))
+	for group := range s.groups {
+		groups = append(groups, group)
+	}
+	s.mu.Unlock()
+	for _, group := range groups {
+		s.leave(group)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.messages)
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+package channels
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/errors"
+	"github.com/rimba47prayoga/gorim.git/interfaces"
+	"github.com/rimba47prayoga/gorim.git/permissions"
+)
+
+// LongPoll serves messages of groups to clients polling with a cursor, for clients
+// behind proxies breaking websockets and server-sent events, example:
+//
+//	notifications := &channels.LongPoll{
+//		Permissions: []interfaces.IPermission{&permissions.IsAuthenticated{}},
+//		Groups: func(c gorim.Context) ([]string, error) {
+//			return []string{fmt.Sprint("user:", c.User().(*models.User).ID)}, nil
+//		},
+//	}
+//	notifications.Register(api, "/notifications/poll")
+//
+// Clients start without cursor, which answers the current cursor at once, then poll
+// with the cursor of the last response: ?cursor=... waits until messages arrive or
+// Timeout passes and answers {"cursor": ..., "messages": [...], "reset": false}.
+// Messages are kept in memory of the process for History, so reset is true when the
+// cursor is older than the kept messages or from another process, clients reload their
+// state then and continue with the new cursor. Route polls of a client to the same
+// process with sticky sessions when running many.
+type LongPoll struct {
+	Permissions	[]interfaces.IPermission
+	Throttles	[]interfaces.IThrottle
+	// Groups returns groups the request receives messages of.
+	Groups		func(c gorim.Context) ([]string, error)
+	// Backend default Default.
+	Backend		Backend
+	// Timeout of a poll without messages, keep it below timeouts of proxies, default
+	// 25 seconds.
+	Timeout		time.Duration
+	// History is how many messages are kept per group, default 100.
+	History		int
+	// IdleTimeout leaves groups not polled for this long, default one minute.
+	IdleTimeout	time.Duration
+	mu				sync.Mutex
+	instance		string
+	subscription	Subscription
+	sequence		uint64
+	groups			map[string]*pollGroup
+	// changed is closed and replaced when messages arrive.
+	changed			chan struct{}
+}
+
+type pollGroup struct {
+	messages	[]pollMessage
+	// since is the sequence before the first message kept, older cursors missed messages.
+	since		uint64
+	polled		time.Time
+}
+
+type pollMessage struct {
+	sequence	uint64
+	message		*Message
+}
+
+// PollResponse is the body of polls.
+type PollResponse struct {
+	Cursor		string		`json:"cursor"`
+	Messages	[]*Message	`json:"messages"`
+	// Reset is true when messages may have been missed since the cursor.
+	Reset		bool		`json:"reset"`
+}
+
+func (p *LongPoll) GetBackend() Backend {
+	if p.Backend != nil {
+		return p.Backend
+	}
+	return Default
+}
+
+func (p *LongPoll) GetTimeout() time.Duration {
+	if p.Timeout > 0 {
+		return p.Timeout
+	}
+	return 25 * time.Second
+}
+
+func (p *LongPoll) GetHistory() int {
+	if p.History > 0 {
+		return p.History
+	}
+	return 100
+}
+
+func (p *LongPoll) GetIdleTimeout() time.Duration {
+	if p.IdleTimeout > 0 {
+		return p.IdleTimeout
+	}
+	return time.Minute
+}
+
+// Register adds route of the poll to group.
+func (p *LongPoll) Register(group *gorim.Group, path string) {
+	group.Add(http.MethodGet, path, p.Serve)
+}
+
+// Serve checks permissions and throttles and answers messages of the groups of the
+// request after the cursor.
+func (p *LongPoll) Serve(c gorim.Context) error {
+	for _, permission := range p.Permissions {
+		if !permission.HasPermission(c) {
+			return permissions.Deny(c, permission)
+		}
+	}
+	for _, throttle := range p.Throttles {
+		if allowed, wait := throttle.AllowRequest(c); !allowed {
+			return errors.Handle(&errors.ThrottledError{Wait: wait}, c)
+		}
+	}
+	var groups []string
+	if p.Groups != nil {
+		var err error
+		if groups, err = p.Groups(c); err != nil {
+			return errors.Handle(err, c)
+		}
+	}
+	ctx := c.Request().Context()
+	if err := p.join(ctx, groups); err != nil {
+		return errors.Handle(err, c)
+	}
+	cursor := c.QueryParam("cursor")
+	timeout := time.NewTimer(p.GetTimeout())
+	defer timeout.Stop()
+	for {
+		response, changed := p.poll(groups, cursor)
+		if cursor == "" || response.Reset || len(response.Messages) > 0 {
+			return c.Respond(http.StatusOK, response)
+		}
+		select {
+		case <-changed:
+		case <-timeout.C:
+			return c.Respond(http.StatusOK, response)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// join subscribes to groups not joined yet and leaves groups idle for IdleTimeout.
+func (p *LongPoll) join(ctx context.Context, groups []string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.subscription == nil {
+		subscription, err := p.GetBackend().Subscribe(context.WithoutCancel(ctx))
+		if err != nil {
+			return err
+		}
+		p.subscription = subscription
+		p.instance = uuid.NewString()
+		p.groups = map[string]*pollGroup{}
+		p.changed = make(chan struct{})
+		go p.receive(subscription)
+	}
+	now := time.Now()
+	var joining, idle []string
+	for _, group := range groups {
+		if state, ok := p.groups[group]; ok {
+			state.polled = now
+			continue
+		}
+		joining = append(joining, group)
+	}
+	for group, state := range p.groups {
+		if now.Sub(state.polled) > p.GetIdleTimeout() {
+			idle = append(idle, group)
+			delete(p.groups, group)
+		}
+	}
+	if len(idle) > 0 {
+		p.subscription.Leave(ctx, idle...)
+	}
+	if len(joining) == 0 {
+		return nil
+	}
+	if err := p.subscription.Join(ctx, joining...); err != nil {
+		return err
+	}
+	for _, group := range joining {
+		p.groups[group] = &pollGroup{since: p.sequence, polled: now}
+	}
+	return nil
+}
+
+func (p *LongPoll) receive(subscription Subscription) {
+	for message := range subscription.Messages() {
+		p.mu.Lock()
+		if state, ok := p.groups[message.Group]; ok {
+			p.sequence++
+			state.messages = append(state.messages, pollMessage{sequence: p.sequence, message: message})
+			if extra := len(state.messages) - p.GetHistory(); extra > 0 {
+				state.since = state.messages[extra - 1].sequence
+				state.messages = append([]pollMessage{}, state.messages[extra:]...)
+			}
+			close(p.changed)
+			p.changed = make(chan struct{})
+		}
+		p.mu.Unlock()
+	}
+}
+
+// poll returns messages of groups after cursor and the channel closed when more arrive.
+func (p *LongPoll) poll(groups []string, cursor string) (*PollResponse, <-chan struct{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	response := &PollResponse{
+		Cursor: p.instance + ":" + strconv.FormatUint(p.sequence, 10),
+		Messages: []*Message{},
+	}
+	if cursor == "" {
+		return response, p.changed
+	}
+	instance, value, _ := strings.Cut(cursor, ":")
+	after, err := strconv.ParseUint(value, 10, 64)
+	if err != nil || instance != p.instance || after > p.sequence {
+		response.Reset = true
+		return response, p.changed
+	}
+	var found []pollMessage
+	for _, group := range groups {
+		state, ok := p.groups[group]
+		if !ok || after < state.since {
+			response.Reset = true
+			return response, p.changed
+		}
+		for _, kept := range state.messages {
+			if kept.sequence > after {
+				found = append(found, kept)
+			}
+		}
+	}
+	// messages of groups are merged in order of arrival.
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].sequence < found[j].sequence
+	})
+	for _, kept := range found {
+		response.Messages = append(response.Messages, kept.message)
+	}
+	return response, p.changed
+}
+
+// Close closes the subscription of the poll.
+func (p *LongPoll) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.subscription == nil {
+		return nil
+	}
+	err := p.subscription.Close()
+	p.subscription = nil
+	return err
+}
@@ -0,0 +1,119 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisBackend fans out messages through redis pub/sub to every process, each
+// subscription holds a connection of the client.
+type RedisBackend struct {
+	Client		redis.UniversalClient
+	// Prefix of redis channels, default "gorim:channels:".
+	Prefix		string
+	// BufferSize of subscriptions, default DefaultBufferSize.
+	BufferSize	int
+}
+
+func NewRedisBackend(client redis.UniversalClient) *RedisBackend {
+	return &RedisBackend{Client: client}
+}
+
+func (b *RedisBackend) GetPrefix() string {
+	if b.Prefix == "" {
+		return "gorim:channels:"
+	}
+	return b.Prefix
+}
+
+func (b *RedisBackend) channels(groups []string) []string {
+	channels := make([]string, len(groups))
+	for i, group := range groups {
+		channels[i] = b.GetPrefix() + group
+	}
+	return channels
+}
+
+func (b *RedisBackend) Publish(ctx context.Context, message *Message) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	return b.Client.Publish(ctx, b.GetPrefix() + message.Group, data).Err()
+}
+
+func (b *RedisBackend) Subscribe(ctx context.Context, groups ...string) (Subscription, error) {
+	size := b.BufferSize
+	if size <= 0 {
+		size = DefaultBufferSize
+	}
+	pubsub := b.Client.Subscribe(ctx, b.channels(groups)...)
+	if len(groups) > 0 {
+		// waits for the confirmation so messages published after Subscribe are received.
+		if _, err := pubsub.Receive(ctx); err != nil {
+			pubsub.Close()
+			return nil, err
+		}
+	}
+	subscription := &redisSubscription{
+		backend: b,
+		pubsub: pubsub,
+		messages: make(chan *Message, size),
+	}
+	go subscription.receive()
+	return subscription, nil
+}
+
+// Close closes the client.
+func (b *RedisBackend) Close() error {
+	return b.Client.Close()
+}
+
+type redisSubscription struct {
+	backend		*RedisBackend
+	pubsub		*redis.PubSub
+	messages	chan *Message
+	closeOnce	sync.Once
+}
+
+func (s *redisSubscription) Messages() <-chan *Message {
+	return s.messages
+}
+
+func (s *redisSubscription) receive() {
+	defer close(s.messages)
+	for received := range s.pubsub.Channel() {
+		message := &Message{}
+		if err := json.Unmarshal([]byte(received.Payload), message); err != nil {
+			slog.Error("channels: invalid message", "channel", received.Channel, "error", err)
+			continue
+		}
+		message.Group = strings.TrimPrefix(received.Channel, s.backend.GetPrefix())
+		select {
+		case s.messages <- message:
+		default:
+		}
+	}
+}
+
+func (s *redisSubscription) Join(ctx context.Context, groups ...string) error {
+	return s.pubsub.Subscribe(ctx, s.backend.channels(groups)...)
+}
+
+func (s *redisSubscription) Leave(ctx context.Context, groups ...string) error {
+	return s.pubsub.Unsubscribe(ctx, s.backend.channels(groups)...)
+}
+
+// Close closes the connection, Messages is closed once pending messages are received.
+func (s *redisSubscription) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		err = s.pubsub.Close()
+	})
+	return err
+}
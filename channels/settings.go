@@ -0,0 +1,30 @@
+package channels
+
+import (
+	"io"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rimba47prayoga/gorim.git/settings"
+)
+
+// FromSettings returns Backend of settings.Get().Channels, backed by redis when URL is
+// set and by memory otherwise, example: channels.Default, err = channels.FromSettings()
+func FromSettings() (Backend, error) {
+	config := settings.Get().Channels
+	if config.URL == "" {
+		return NewMemoryBackend(), nil
+	}
+	options, err := redis.ParseURL(config.URL)
+	if err != nil {
+		return nil, err
+	}
+	return &RedisBackend{Client: redis.NewClient(options), Prefix: config.Prefix}, nil
+}
+
+// Close closes Default when it holds connections, register it with server.OnShutdown.
+func Close() error {
+	if closer, ok := Default.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/rimba47prayoga/gorim.git/apps"
+	"github.com/rimba47prayoga/gorim.git/conf"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gorm.io/gorm"
+)
+
+// Command is a management command of the project, register it before Execute:
+//
+//	cmd.Register(cmd.Command{
+//		Name: "closepolls",
+//		Short: "Close polls older than the given days.",
+//		Flags: func(flags *pflag.FlagSet) {
+//			flags.Int("days", 30, "Age of polls in days")
+//		},
+//		Run: func(ctx *cmd.CommandContext) error {
+//			days, _ := ctx.Flags.GetInt("days")
+//			return ctx.DB.Model(&Poll{}).Where("created_at < ?", time.Now().AddDate(0, 0, -days)).Update("closed", true).Error
+//		},
+//	})
+type Command struct {
+	// Use is name of the command followed by its arguments, example: "closepolls [id...]"
+	Use			string
+	Short		string
+	Long		string
+	Aliases		[]string
+	Args		cobra.PositionalArgs
+	Flags		func(*pflag.FlagSet)
+	// NoSettings runs the command without loading settings.
+	NoSettings	bool
+	Run			func(*CommandContext) error
+}
+
+// CommandContext is passed to Run of the command.
+type CommandContext struct {
+	context.Context
+	Args		[]string
+	Flags		*pflag.FlagSet
+	// DB is conf.DB, nil for commands with NoSettings.
+	DB			*gorm.DB
+	Out			io.Writer
+	Command		*cobra.Command
+}
+
+// noSettingsAnnotation marks commands running without settings.
+const noSettingsAnnotation = "gorim:no_settings"
+
+var settingsOnce sync.Once
+
+// LoadSettings calls conf.Configure once, it's called before commands run so main of
+// the project can set conf.Configure instead of configuring, then commands without
+// settings such as startapp work before the database is up. Registered apps are made
+// ready after it.
+func LoadSettings() {
+	settingsOnce.Do(func() {
+		if conf.Configure != nil {
+			conf.Configure()
+		}
+		apps.Ready()
+	})
+}
+
+// Register adds command to the CLI, it replaces registered command with the same name.
+func Register(command Command) *cobra.Command {
+	cobraCmd := &cobra.Command{
+		Use: command.Use,
+		Short: command.Short,
+		Long: command.Long,
+		Aliases: command.Aliases,
+		Args: command.Args,
+		RunE: func(c *cobra.Command, args []string) error {
+			ctx := &CommandContext{
+				Context: c.Context(),
+				Args: args,
+				Flags: c.Flags(),
+				Out: c.OutOrStdout(),
+				Command: c,
+			}
+			if !command.NoSettings {
+				ctx.DB = conf.DB
+			}
+			if ctx.Context == nil {
+				ctx.Context = context.Background()
+			}
+			return command.Run(ctx)
+		},
+	}
+	cobraCmd.SilenceUsage = true
+	if command.NoSettings {
+		cobraCmd.Annotations = map[string]string{noSettingsAnnotation: "true"}
+	}
+	if command.Flags != nil {
+		command.Flags(cobraCmd.Flags())
+	}
+	for _, registered := range rootCmd.Commands() {
+		if registered.Name() == cobraCmd.Name() {
+			rootCmd.RemoveCommand(registered)
+		}
+	}
+	rootCmd.AddCommand(cobraCmd)
+	return cobraCmd
+}
+
+// needsSettings reports whether settings are loaded before c runs.
+func needsSettings(c *cobra.Command) bool {
+	for command := c; command != nil; command = command.Parent() {
+		if command.Annotations[noSettingsAnnotation] == "true" {
+			return false
+		}
+		switch command.Name() {
+		case "help", "completion", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+			return false
+		}
+	}
+	return true
+}
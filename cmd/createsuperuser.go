@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/rimba47prayoga/gorim.git/conf"
+	"github.com/rimba47prayoga/gorim.git/interfaces"
+	"github.com/rimba47prayoga/gorim.git/models"
+	"github.com/rimba47prayoga/gorim.git/utils"
+	"github.com/spf13/pflag"
+	"gorm.io/gorm"
+)
+
+// adminColumns are set to true on created superuser when the user model has them.
+var adminColumns = []string{"is_admin", "is_superuser", "is_staff"}
+
+func init() {
+	Register(Command{
+		Use: "createsuperuser",
+		Short: "Create admin user of conf.USER_MODEL.",
+		Long: `Create admin user of conf.USER_MODEL.
+
+Values not given with flags are prompted, with --noinput they're read from
+GORIM_SUPERUSER_USERNAME, GORIM_SUPERUSER_EMAIL and GORIM_SUPERUSER_PASSWORD.`,
+		Flags: func(flags *pflag.FlagSet) {
+			flags.String("username", "", "Value of conf.USERNAME_FIELD")
+			flags.String("email", "", "Email, when it isn't the username field")
+			flags.Bool("noinput", false, "Don't prompt, the password is read from GORIM_SUPERUSER_PASSWORD")
+		},
+		Run: func(ctx *CommandContext) error {
+			noinput, _ := ctx.Flags.GetBool("noinput")
+			prompter := &prompter{reader: bufio.NewReader(os.Stdin), out: ctx.Out, noinput: noinput}
+			user := models.NewUser()
+			username := prompter.value(ctx.Flags, "username", "GORIM_SUPERUSER_USERNAME", conf.USERNAME_FIELD)
+			if username == "" {
+				return fmt.Errorf("%s cannot be blank", conf.USERNAME_FIELD)
+			}
+			if _, err := models.GetUserByUsername(ctx.DB, username); err == nil {
+				return fmt.Errorf("user with this %s already exists", conf.USERNAME_FIELD)
+			}
+			if err := utils.SetFieldFromString(user, conf.USERNAME_FIELD, username); err != nil {
+				return err
+			}
+			if conf.USERNAME_FIELD != "email" && hasColumn(user, "email") {
+				email := prompter.value(ctx.Flags, "email", "GORIM_SUPERUSER_EMAIL", "email")
+				if err := utils.SetFieldFromString(user, "email", email); err != nil {
+					return err
+				}
+			}
+			password, err := prompter.password()
+			if err != nil {
+				return err
+			}
+			if err := setPassword(user, password); err != nil {
+				return err
+			}
+			for _, column := range adminColumns {
+				if hasColumn(user, column) {
+					if err := utils.SetFieldFromString(user, column, "true"); err != nil {
+						return err
+					}
+				}
+			}
+			if err := ctx.DB.Session(&gorm.Session{}).Create(user).Error; err != nil {
+				return err
+			}
+			fmt.Fprintln(ctx.Out, "Superuser created successfully.")
+			return nil
+		},
+	})
+}
+
+// prompter reads values of createsuperuser from flags, environment or stdin.
+type prompter struct {
+	reader		*bufio.Reader
+	out			io.Writer
+	noinput		bool
+}
+
+func (p *prompter) value(flags *pflag.FlagSet, flag string, env string, label string) string {
+	if value, _ := flags.GetString(flag); value != "" {
+		return value
+	}
+	if p.noinput {
+		return os.Getenv(env)
+	}
+	fmt.Fprintf(p.out, "%s: ", label)
+	line, _ := p.reader.ReadString('\n')
+	return strings.TrimSpace(line)
+}
+
+func (p *prompter) password() (string, error) {
+	if p.noinput {
+		password := os.Getenv("GORIM_SUPERUSER_PASSWORD")
+		if password == "" {
+			return "", fmt.Errorf("GORIM_SUPERUSER_PASSWORD must be set with --noinput")
+		}
+		return password, nil
+	}
+	for {
+		password := p.secret("Password: ")
+		again := p.secret("Password (again): ")
+		switch {
+		case password == "":
+			fmt.Fprintln(p.out, "Error: Blank passwords aren't allowed.")
+		case password != again:
+			fmt.Fprintln(p.out, "Error: Your passwords didn't match.")
+		default:
+			return password, nil
+		}
+	}
+}
+
+// secret reads line without echo when stty is available, such as in unix terminals.
+func (p *prompter) secret(label string) string {
+	fmt.Fprint(p.out, label)
+	if stty("-echo") == nil {
+		defer func() {
+			stty("echo")
+			fmt.Fprintln(p.out)
+		}()
+	}
+	line, _ := p.reader.ReadString('\n')
+	return strings.TrimRight(line, "\r\n")
+}
+
+func stty(arg string) error {
+	command := exec.Command("stty", arg)
+	command.Stdin = os.Stdin
+	return command.Run()
+}
+
+func setPassword(user interface{}, password string) error {
+	if setter, ok := user.(interfaces.IUser); ok {
+		setter.SetPassword(password)
+		return nil
+	}
+	hashed, err := utils.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	return utils.SetFieldFromString(user, "password", hashed)
+}
+
+func hasColumn(model interface{}, column string) bool {
+	_, err := utils.LookUpField(model, column)
+	return err == nil
+}
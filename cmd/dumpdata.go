@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/rimba47prayoga/gorim.git/conf"
+	"github.com/rimba47prayoga/gorim.git/fixtures"
+	"github.com/spf13/cobra"
+)
+
+// dumpdataCmd represents the dumpdata command
+var dumpdataCmd = &cobra.Command{
+	Use:   "dumpdata [table...]",
+	Short: "Outputs rows of models as fixture, every model when no table is given.",
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		format, _ := cmd.Flags().GetString("format")
+		models := migrationInstance().AllModels()
+		if output != "" {
+			count, err := fixtures.DumpFile(conf.DB, models, output, args...)
+			if err != nil {
+				log.Fatal(err)
+			}
+			fmt.Printf("Dumped %d object(s) into %s\n", count, output)
+			return
+		}
+		dumped, err := fixtures.Dump(conf.DB, models, args...)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := fixtures.Encode(os.Stdout, format, dumped); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(dumpdataCmd)
+	dumpdataCmd.Flags().StringP("output", "o", "", "File to write, format is guessed from its extension")
+	dumpdataCmd.Flags().String("format", "json", "Format of standard output, json or yaml")
+}
@@ -0,0 +1,14 @@
+// Command gorim creates projects and apps, install it with:
+//
+//	go install github.com/rimba47prayoga/gorim.git/cmd/gorim@latest
+//
+// Commands needing settings such as runserver or migrate are run by main of the project.
+package main
+
+import (
+	"github.com/rimba47prayoga/gorim.git/cmd"
+)
+
+func main() {
+	cmd.Execute()
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/rimba47prayoga/gorim.git/conf"
+	"github.com/rimba47prayoga/gorim.git/fixtures"
+	"github.com/spf13/cobra"
+)
+
+// loaddataCmd represents the loaddata command
+var loaddataCmd = &cobra.Command{
+	Use:   "loaddata fixture...",
+	Short: "Creates or updates rows from JSON or YAML fixture files.",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		count, err := fixtures.LoadFiles(conf.DB, migrationInstance().AllModels(), args...)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Installed %d object(s) from %d fixture(s)\n", count, len(args))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(loaddataCmd)
+}
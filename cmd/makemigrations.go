@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/rimba47prayoga/gorim.git/conf"
+	"github.com/rimba47prayoga/gorim.git/migrations"
+	"github.com/spf13/cobra"
+)
+
+// makemigrationsCmd represents the makemigrations command
+var makemigrationsCmd = &cobra.Command{
+	Use:   "makemigrations",
+	Short: "Creates migration files from changes of models.",
+	Run: func(cmd *cobra.Command, args []string) {
+		name, _ := cmd.Flags().GetString("name")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		instance := migrationInstance()
+		migration, err := instance.MakeMigrations(name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if migration == nil {
+			fmt.Println("No changes detected.")
+			return
+		}
+		if dryRun {
+			fmt.Printf("-- %s up\n%s\n-- %s down\n%s", migration.Name, migration.Up, migration.Name, migration.Down)
+			return
+		}
+		dir, err := instance.WriteFile(*migration)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Migration %s created in %s\n", migration.Name, dir)
+	},
+}
+
+// migrationInstance returns conf.MigrationInstance, makemigrations and rollback need *migrations.Migrations.
+func migrationInstance() *migrations.Migrations {
+	instance, ok := conf.MigrationInstance.(*migrations.Migrations)
+	if !ok {
+		log.Fatal("conf.MigrationInstance must be *migrations.Migrations")
+	}
+	return instance
+}
+
+func init() {
+	rootCmd.AddCommand(makemigrationsCmd)
+	makemigrationsCmd.Flags().String("name", "", "Name of the migration")
+	makemigrationsCmd.Flags().Bool("dry-run", false, "Print the migration instead of writing it")
+}
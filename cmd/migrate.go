@@ -4,6 +4,7 @@ Copyright © 2024 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"fmt"
 	"log"
 	"os"
 	"time"
@@ -34,13 +35,39 @@ to quickly create a Cobra application.`,
 			},
 		)
 		conf.DB.Logger = migrationLogger
-		conf.MigrationInstance.Run()
+		rollback, _ := cmd.Flags().GetInt("rollback")
+		list, _ := cmd.Flags().GetBool("list")
+		switch {
+		case list:
+			instance := migrationInstance()
+			files, err := instance.Files()
+			if err != nil {
+				log.Fatal(err)
+			}
+			applied := instance.Applied()
+			for _, file := range files {
+				mark := " "
+				if applied[file.Name] {
+					mark = "X"
+				}
+				fmt.Printf("[%s] %s\n", mark, file.Name)
+			}
+		case rollback > 0:
+			if err := migrationInstance().Rollback(rollback); err != nil {
+				log.Fatal(err)
+			}
+		default:
+			conf.MigrationInstance.Run()
+		}
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(migrateCmd)
 
+	migrateCmd.Flags().Int("rollback", 0, "Revert the given number of applied migration files")
+	migrateCmd.Flags().Bool("list", false, "List migration files and whether they are applied")
+
 	// Here you will define your flags and configuration settings.
 
 	// Cobra supports Persistent Flags which will work for this command
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/rimba47prayoga/gorim.git/schema"
+	"github.com/spf13/cobra"
+)
+
+// openapiCmd represents the openapi command
+var openapiCmd = &cobra.Command{
+	Use:   "openapi",
+	Short: "Export OpenAPI 3 schema of registered routes.",
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		title, _ := cmd.Flags().GetString("title")
+		version, _ := cmd.Flags().GetString("version")
+		prefix, _ := cmd.Flags().GetString("prefix")
+		apps, _ := cmd.Flags().GetStringSlice("app")
+		generator := &schema.Generator{
+			Info: schema.Info{Title: title, Version: version},
+			Prefix: prefix,
+			Apps: apps,
+		}
+		data, err := json.MarshalIndent(generator.Generate(), "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+		if output == "" {
+			fmt.Println(string(data))
+			return
+		}
+		if err := os.WriteFile(output, data, 0644); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Schema written to %s\n", output)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(openapiCmd)
+	openapiCmd.Flags().StringP("output", "o", "", "Write schema to the file instead of stdout")
+	openapiCmd.Flags().String("title", "API", "Title of the API")
+	openapiCmd.Flags().String("version", "1.0.0", "Version of the API")
+	openapiCmd.Flags().String("prefix", "", "Only include routes starting with the prefix")
+	openapiCmd.Flags().StringSlice("app", nil, "Only include routes of the apps")
+}
@@ -14,14 +14,17 @@ import (
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
-	Use:   "gorim.git",
-	Short: "A brief description of your application",
-	Long: `A longer description that spans multiple lines and likely contains
-examples and usage of using your application. For example:
-
-Cobra is a CLI library for Go that empowers applications.
-This application is a tool to generate the needed files
-to quickly create a Cobra application.`,
+	Use:   "gorim",
+	Short: "Manage gorim projects.",
+	Long: `Manage gorim projects.
+
+Create a project with startproject and its apps with startapp, other commands
+such as runserver and migrate are run by main of the project: go run . runserver`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if needsSettings(cmd) {
+			LoadSettings()
+		}
+	},
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
 	// Run: func(cmd *cobra.Command, args []string) { },
@@ -4,12 +4,19 @@ Copyright © 2024 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/autoreload"
 	"github.com/rimba47prayoga/gorim.git/conf"
+	"github.com/rimba47prayoga/gorim.git/database"
+	"github.com/rimba47prayoga/gorim.git/middlewares"
 	"github.com/spf13/cobra"
 )
 
@@ -17,26 +24,68 @@ import (
 var runserverCmd = &cobra.Command{
 	Use:   "runserver",
 	Short: "Start a lightweight Web server for development.",
-	Long: `A longer description that spans multiple lines and likely contains examples
-and usage of using your command. For example:
+	Long: `Start a lightweight Web server for development.
 
-Cobra is a CLI library for Go that empowers applications.
-This application is a tool to generate the needed files
-to quickly create a Cobra application.`,
+The project is rebuilt and the server restarted when source files change, the
+server runs as child process of the command. Use --noreload to serve directly.
+
+On SIGINT or SIGTERM the server stops accepting connections, waits for in-flight
+requests up to the shutdown timeout, runs OnShutdown hooks of the server and
+closes the databases.`,
+	// settings are loaded by the server, not by the process reloading it.
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if !reloading(cmd) {
+			LoadSettings()
+		}
+	},
 	Run: func(cmd *cobra.Command, args []string) {
+		if reloading(cmd) {
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			fmt.Println("Watching for file changes with polling, restart is automatic.")
+			reloader := &autoreload.Reloader{Args: os.Args[1:]}
+			if err := reloader.Run(ctx); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
 		server := conf.GorimServer.(*gorim.Server)
 		address := fmt.Sprintf("%s:%d", conf.HOST, conf.PORT)
 		versionNumber := "v1.1.0"
 		printBanner(versionNumber, conf.ENV_PATH, conf.HOST)
-		err := server.Start(address)
+		server.Echo.Server.ReadHeaderTimeout = conf.READ_HEADER_TIMEOUT
+		server.Echo.Server.ReadTimeout = conf.READ_TIMEOUT
+		server.Echo.Server.WriteTimeout = conf.WRITE_TIMEOUT
+		server.Echo.Server.IdleTimeout = conf.IDLE_TIMEOUT
+		if verbose, _ := cmd.Flags().GetBool("verbose"); verbose {
+			server.Use(middlewares.VerboseLoggerMiddleware)
+		}
+		if len(conf.ALLOWED_HOSTS) > 0 {
+			server.Use(middlewares.AllowedHosts(conf.ALLOWED_HOSTS))
+		}
+		if conf.MAX_BODY_SIZE > 0 {
+			server.Use(middlewares.BodyLimit(conf.MAX_BODY_SIZE))
+		}
+		server.ShutdownTimeout = conf.SHUTDOWN_TIMEOUT
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		conf.WatchReplicas(ctx, conf.REPLICA_HEALTH_CHECK_INTERVAL)
+		// requests are drained and hooks of the project run before the databases are closed.
+		err := server.RunContext(ctx, address)
+		if closeErr := database.Close(); err == nil {
+			err = closeErr
+		}
 		if err != nil {
 			log.Fatal(err)
 		}
+		fmt.Println("Server stopped.")
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(runserverCmd)
+	runserverCmd.Flags().Bool("noreload", false, "Don't rebuild and restart the server when source files change")
+	runserverCmd.Flags().BoolP("verbose", "v", false, "Echo headers and body of every request")
 
 	// Here you will define your flags and configuration settings.
 
@@ -49,6 +98,12 @@ func init() {
 	// runserverCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 }
 
+// reloading reports whether the process watches files and restarts the server.
+func reloading(cmd *cobra.Command) bool {
+	noreload, _ := cmd.Flags().GetBool("noreload")
+	return !noreload && !autoreload.IsChild()
+}
+
 func printBanner(version string, env string, host string) {
     // Current timestamp
     now := time.Now().Format("January 02, 2006 - 15:04:05")
@@ -56,7 +111,10 @@ func printBanner(version string, env string, host string) {
     // Banner content
     fmt.Println("System check identified no issues (0 silenced).")
     fmt.Println(now)
-	fmt.Printf("Gorim version %s using env '%s'\n", version, env)
+	fmt.Printf("Gorim version %s using env '%s' and profile '%s'\n", version, env, conf.PROFILE)
+	if conf.DEBUG {
+		fmt.Println("Debug is enabled, error responses include stack traces.")
+	}
     fmt.Printf("Starting development server at %s\n", host)
     fmt.Println("Quit the server with CONTROL-C.")
 }
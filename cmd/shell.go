@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rimba47prayoga/gorim.git/conf"
+	"github.com/spf13/pflag"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func init() {
+	Register(Command{
+		Use: "shell",
+		Short: "Inspect the database with SQL and registered models, interactively or with --command.",
+		Aliases: []string{"dbshell"},
+		Flags: func(flags *pflag.FlagSet) {
+			flags.StringP("command", "c", "", "Run the statement and exit")
+			flags.String("database", "default", "Name in conf.DATABASES to connect to")
+		},
+		Run: func(ctx *CommandContext) error {
+			command, _ := ctx.Flags.GetString("command")
+			database, _ := ctx.Flags.GetString("database")
+			db := ctx.DB
+			if database != "default" {
+				var ok bool
+				if db, ok = conf.DATABASES[database]; !ok {
+					return fmt.Errorf("database %s isn't configured in conf.DATABASES", database)
+				}
+			}
+			// errors are printed by the shell, one connection keeps session state such as SET.
+			db = db.WithContext(ctx).Session(&gorm.Session{Logger: db.Logger.LogMode(logger.Silent)})
+			return db.Connection(func(conn *gorm.DB) error {
+				if strings.HasPrefix(command, `\`) {
+					_, err := runMeta(conn, ctx.Out, command)
+					return err
+				}
+				if command != "" {
+					return runStatements(conn, ctx.Out, command)
+				}
+				fmt.Fprintln(ctx.Out, `Type \? for help, \q to quit.`)
+				return runShell(conn, os.Stdin, ctx.Out)
+			})
+		},
+	})
+}
+
+// runShell reads statements ending with semicolon and backslash commands until EOF or \q,
+// errors are printed and the shell keeps running.
+func runShell(db *gorm.DB, in io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+	statement := ""
+	fmt.Fprint(out, "gorim> ")
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if statement == "" && strings.HasPrefix(line, `\`) {
+			quit, err := runMeta(db, out, line)
+			if quit {
+				return nil
+			}
+			if err != nil {
+				fmt.Fprintln(out, "ERROR:", err)
+			}
+			fmt.Fprint(out, "gorim> ")
+			continue
+		}
+		if statement == "" && line == "exit" {
+			return nil
+		}
+		statement += line + "\n"
+		if strings.HasSuffix(line, ";") {
+			if err := runStatements(db, out, statement); err != nil {
+				fmt.Fprintln(out, "ERROR:", err)
+			}
+			statement = ""
+		}
+		if statement == "" {
+			fmt.Fprint(out, "gorim> ")
+		} else {
+			fmt.Fprint(out, "   ... ")
+		}
+	}
+	fmt.Fprintln(out)
+	return scanner.Err()
+}
+
+// queryPrefixes are first words of statements returning rows, others are executed.
+var queryPrefixes = []string{"select", "with", "show", "explain", "pragma", "values", "table", "describe"}
+
+func returnsRows(statement string) bool {
+	statement = strings.ToLower(statement)
+	first := strings.Fields(statement)
+	if len(first) == 0 {
+		return false
+	}
+	for _, prefix := range queryPrefixes {
+		if first[0] == prefix {
+			return true
+		}
+	}
+	return strings.Contains(statement, "returning")
+}
+
+// runStatements runs statement and prints returned rows as table.
+func runStatements(db *gorm.DB, out io.Writer, statement string) error {
+	statement = strings.TrimSpace(statement)
+	db = db.Session(&gorm.Session{})
+	if !returnsRows(statement) {
+		result := db.Exec(statement)
+		if result.Error != nil {
+			return result.Error
+		}
+		fmt.Fprintf(out, "OK, %d row(s) affected\n", result.RowsAffected)
+		return nil
+	}
+	rows, err := db.Raw(statement).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, strings.Join(columns, "\t"))
+	count := 0
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return err
+		}
+		cells := make([]string, len(values))
+		for i, value := range values {
+			switch value := value.(type) {
+			case nil:
+				cells[i] = "NULL"
+			case []byte:
+				cells[i] = string(value)
+			default:
+				cells[i] = fmt.Sprint(value)
+			}
+		}
+		fmt.Fprintln(writer, strings.Join(cells, "\t"))
+		count++
+	}
+	writer.Flush()
+	fmt.Fprintf(out, "(%d row(s))\n", count)
+	return rows.Err()
+}
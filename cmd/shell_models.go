@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rimba47prayoga/gorim.git/apps"
+	"github.com/rimba47prayoga/gorim.git/conf"
+	"github.com/rimba47prayoga/gorim.git/migrations"
+	"github.com/rimba47prayoga/gorim.git/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// shellListLimit is the number of objects printed by \list.
+const shellListLimit = 20
+
+const shellHelp = `Statements ending with ; are run on the database, other commands are:
+  \models                  list models of conf.MigrationInstance
+  \d <model>               describe columns of the model
+  \list <model> [where]    print objects as JSON, at most 20
+  \get <model> <pk>        print object as JSON
+  \count <model> [where]   count objects
+  \q                       quit
+<model> is the struct or table name, [where] is SQL such as: email like '%@example.com'`
+
+// shellModel is a model the shell can query, found by struct or table name.
+type shellModel struct {
+	Name		string
+	Schema		*schema.Schema
+}
+
+// shellModels returns models of conf.MigrationInstance and registered apps.
+func shellModels(db *gorm.DB) []shellModel {
+	registered := apps.Models()
+	if instance, ok := conf.MigrationInstance.(*migrations.Migrations); ok {
+		registered = instance.AllModels()
+	}
+	models := []shellModel{}
+	for _, model := range registered {
+		statement := &gorm.Statement{DB: db}
+		if err := statement.Parse(model); err != nil {
+			continue
+		}
+		models = append(models, shellModel{Name: utils.GetStructName(model), Schema: statement.Schema})
+	}
+	return models
+}
+
+func findShellModel(models []shellModel, name string) (*shellModel, error) {
+	for i, model := range models {
+		if strings.EqualFold(model.Name, name) || model.Schema.Table == name {
+			return &models[i], nil
+		}
+	}
+	return nil, fmt.Errorf("model %s not found, see \\models", name)
+}
+
+// newSlice returns pointer to empty slice of the model.
+func (m *shellModel) newSlice() interface{} {
+	return reflect.New(reflect.SliceOf(m.Schema.ModelType)).Interface()
+}
+
+// runMeta runs backslash command of the shell, quit is true for \q.
+func runMeta(db *gorm.DB, out io.Writer, line string) (quit bool, err error) {
+	fields := strings.Fields(line)
+	command := fields[0]
+	args := fields[1:]
+	switch command {
+	case `\q`:
+		return true, nil
+	case `\?`, `\help`:
+		fmt.Fprintln(out, shellHelp)
+		return false, nil
+	case `\models`:
+		writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "MODEL\tTABLE")
+		for _, model := range shellModels(db) {
+			fmt.Fprintf(writer, "%s\t%s\n", model.Name, model.Schema.Table)
+		}
+		return false, writer.Flush()
+	}
+	if len(args) == 0 {
+		return false, fmt.Errorf("unknown command %s, see \\?", command)
+	}
+	model, err := findShellModel(shellModels(db), args[0])
+	if err != nil {
+		return false, err
+	}
+	query := db.Session(&gorm.Session{}).Model(reflect.New(model.Schema.ModelType).Interface())
+	// where is the text after the model name.
+	where := strings.TrimSpace(strings.TrimPrefix(line, command))
+	where = strings.TrimSuffix(strings.TrimSpace(strings.TrimPrefix(where, args[0])), ";")
+	if where != "" && command != `\get` {
+		query = query.Where(where)
+	}
+	switch command {
+	case `\d`:
+		writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "COLUMN\tTYPE\tFIELD\tPRIMARY KEY")
+		for _, field := range model.Schema.Fields {
+			if field.DBName == "" {
+				continue
+			}
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%t\n", field.DBName, field.FieldType, field.Name, field.PrimaryKey)
+		}
+		return false, writer.Flush()
+	case `\count`:
+		var count int64
+		if err := query.Count(&count).Error; err != nil {
+			return false, err
+		}
+		fmt.Fprintln(out, count)
+		return false, nil
+	case `\list`:
+		objects := model.newSlice()
+		if model.Schema.PrioritizedPrimaryField != nil {
+			query = query.Order(model.Schema.PrioritizedPrimaryField.DBName)
+		}
+		if err := query.Limit(shellListLimit).Find(objects).Error; err != nil {
+			return false, err
+		}
+		return false, printJSON(out, objects)
+	case `\get`:
+		if len(args) < 2 || model.Schema.PrioritizedPrimaryField == nil {
+			return false, fmt.Errorf("usage: \\get <model> <pk>")
+		}
+		object := reflect.New(model.Schema.ModelType).Interface()
+		pk, err := utils.ParseFieldString(object, model.Schema.PrioritizedPrimaryField.Name, args[1])
+		if err != nil {
+			return false, err
+		}
+		if err := query.Where(model.Schema.PrioritizedPrimaryField.DBName + " = ?", pk).First(object).Error; err != nil {
+			return false, err
+		}
+		return false, printJSON(out, object)
+	}
+	return false, fmt.Errorf("unknown command %s, see \\?", command)
+}
+
+func printJSON(out io.Writer, value interface{}) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out, string(data))
+	return err
+}
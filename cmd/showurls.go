@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/conf"
+	"github.com/rimba47prayoga/gorim.git/routers"
+	"github.com/spf13/cobra"
+)
+
+// showurlsCmd represents the show-urls command
+var showurlsCmd = &cobra.Command{
+	Use:   "show-urls",
+	Aliases: []string{"routes"},
+	Short: "Print registered routes with their viewset, action and permissions.",
+	Run: func(cmd *cobra.Command, args []string) {
+		prefix, _ := cmd.Flags().GetString("prefix")
+		server := conf.GorimServer.(*gorim.Server)
+		echoRoutes := server.Routes()
+		sort.SliceStable(echoRoutes, func(i, j int) bool {
+			if echoRoutes[i].Path != echoRoutes[j].Path {
+				return echoRoutes[i].Path < echoRoutes[j].Path
+			}
+			return echoRoutes[i].Method < echoRoutes[j].Method
+		})
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "METHOD\tPATH\tNAME\tVIEWSET\tACTION\tPERMISSIONS")
+		for _, route := range echoRoutes {
+			if !strings.HasPrefix(route.Path, prefix) {
+				continue
+			}
+			info, ok := routers.LookupRoute(route.Method, route.Path)
+			if !ok {
+				info = routers.RouteInfo{}
+			}
+			fmt.Fprintf(
+				writer, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				route.Method, route.Path, info.Name, info.ViewSet, info.Action,
+				strings.Join(info.Permissions, ", "),
+			)
+		}
+		writer.Flush()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(showurlsCmd)
+	showurlsCmd.Flags().String("prefix", "", "Only show routes starting with the prefix")
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/rimba47prayoga/gorim.git/scaffold"
+	"github.com/spf13/cobra"
+)
+
+// startappCmd represents the startapp command
+var startappCmd = &cobra.Command{
+	Use:   "startapp [name]",
+	Short: "Create app with model, serializer, filter, viewset and routes.",
+	Args:  cobra.ExactArgs(1),
+	Annotations: map[string]string{noSettingsAnnotation: "true"},
+	Run: func(cmd *cobra.Command, args []string) {
+		directory, _ := cmd.Flags().GetString("directory")
+		app, err := scaffold.App(directory, args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("App %s created, install it by importing it in main.go:\n", app.Package)
+		fmt.Printf("  _ %q\n", app.ImportPath)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(startappCmd)
+	startappCmd.Flags().StringP("directory", "d", ".", "Directory to create the app in")
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/rimba47prayoga/gorim.git/scaffold"
+	"github.com/spf13/cobra"
+)
+
+// startprojectCmd represents the startproject command
+var startprojectCmd = &cobra.Command{
+	Use:   "startproject [name]",
+	Short: "Create project with settings, routes, migrations and main.",
+	Args:  cobra.ExactArgs(1),
+	Annotations: map[string]string{noSettingsAnnotation: "true"},
+	Run: func(cmd *cobra.Command, args []string) {
+		directory, _ := cmd.Flags().GetString("directory")
+		module, _ := cmd.Flags().GetString("module")
+		target, err := scaffold.Project(directory, args[0], module)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Project created in %s, next steps:\n", target)
+		fmt.Printf("  cd %s\n", target)
+		fmt.Println("  go mod tidy")
+		fmt.Println("  go run . migrate")
+		fmt.Println("  go run . runserver")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(startprojectCmd)
+	startprojectCmd.Flags().StringP("directory", "d", ".", "Directory to create the project in")
+	startprojectCmd.Flags().StringP("module", "m", "", "Module path of go.mod, default is the name")
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/rimba47prayoga/gorim.git/database"
+	"github.com/rimba47prayoga/gorim.git/outbox"
+	"github.com/rimba47prayoga/gorim.git/tasks"
+	"github.com/spf13/cobra"
+)
+
+var workerCmd = &cobra.Command{
+	Use:   "worker",
+	Short: "Run enqueued background tasks.",
+	Long: `Run enqueued background tasks.
+
+Messages of the queues are taken from tasks.Default, set it in settings of the
+project with tasks.FromSettings. Queues and concurrency default to the tasks
+section of the settings. Schedules added by tasks.Periodic are run too, the
+workers elect one of them to enqueue them through locks.Default. Events of
+the outbox are published the same way when outbox.DefaultRelay has a publisher.
+
+On SIGINT or SIGTERM the worker stops taking messages and waits for running
+tasks, then closes the databases.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		worker := tasks.WorkerFromSettings()
+		if cmd.Flags().Changed("queues") {
+			worker.Queues, _ = cmd.Flags().GetStringSlice("queues")
+		}
+		if cmd.Flags().Changed("concurrency") {
+			worker.Concurrency, _ = cmd.Flags().GetInt("concurrency")
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		registered := tasks.Registered()
+		sort.Strings(registered)
+		fmt.Printf("Registered tasks: %s\n", strings.Join(registered, ", "))
+		var err error
+		if burst, _ := cmd.Flags().GetBool("burst"); burst {
+			err = worker.Drain(ctx)
+		} else {
+			fmt.Printf(
+				"Worker running queues %s with concurrency %d, quit with CONTROL-C.\n",
+				strings.Join(worker.GetQueues(), ", "), worker.GetConcurrency(),
+			)
+			var wg sync.WaitGroup
+			if noSchedule, _ := cmd.Flags().GetBool("no-schedule"); !noSchedule && len(tasks.DefaultScheduler.Entries()) > 0 {
+				fmt.Printf("Scheduler running %d schedules.\n", len(tasks.DefaultScheduler.Entries()))
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					tasks.DefaultScheduler.Run(ctx)
+				}()
+			}
+			if noRelay, _ := cmd.Flags().GetBool("no-relay"); !noRelay && outbox.DefaultRelay.Publisher != nil {
+				fmt.Println("Outbox relay running.")
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					outbox.DefaultRelay.Run(ctx)
+				}()
+			}
+			err = worker.Run(ctx)
+			wg.Wait()
+		}
+		if closeErr := database.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("Worker stopped.")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(workerCmd)
+	workerCmd.Flags().StringSliceP("queues", "q", nil, "Queues to run, earlier queues first")
+	workerCmd.Flags().IntP("concurrency", "c", 0, "Number of tasks run at once")
+	workerCmd.Flags().Bool("burst", false, "Run due tasks one by one and exit when none is left")
+	workerCmd.Flags().Bool("no-schedule", false, "Don't run schedules of tasks.Periodic")
+	workerCmd.Flags().Bool("no-relay", false, "Don't publish events of the outbox")
+}
@@ -0,0 +1,81 @@
+package conf
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// dbContextKey carries the DB set by WithDB.
+type dbContextKey struct{}
+// usingContextKey carries the name in DATABASES set by Using.
+type usingContextKey struct{}
+// primaryContextKey marks ctx whose reads must not use replicas.
+type primaryContextKey struct{}
+
+// WithDB returns ctx whose queries run in db instead of DB,
+// used by tests to run each test in its own transaction.
+func WithDB(ctx context.Context, db *gorm.DB) context.Context {
+	return context.WithValue(ctx, dbContextKey{}, db)
+}
+
+// Using returns ctx whose queries, reads and writes, run in DATABASES[name].
+func Using(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, usingContextKey{}, name)
+}
+
+// UsePrimary returns ctx whose reads run in DB instead of replicas,
+// example: reading data written by the same request.
+func UsePrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryContextKey{}, true)
+}
+
+// BindDB binds queryset of writes to ctx, the queries run in the DB set by WithDB
+// or DATABASES selected by Using when ctx has one.
+func BindDB(queryset *gorm.DB, ctx context.Context) *gorm.DB {
+	return bindDB(queryset, ctx, false)
+}
+
+// BindReadDB is BindDB for reads, which run in a healthy replica unless ctx is UsePrimary.
+func BindReadDB(queryset *gorm.DB, ctx context.Context) *gorm.DB {
+	return bindDB(queryset, ctx, true)
+}
+
+func bindDB(queryset *gorm.DB, ctx context.Context, read bool) *gorm.DB {
+	queryset = queryset.WithContext(ctx)
+	db := selectDB(ctx, read)
+	if override, ok := ctx.Value(dbContextKey{}).(*gorm.DB); ok {
+		db = override
+	}
+	if db != nil {
+		// WithContext clones the statement, so the connection is replaced only for this queryset.
+		queryset.Statement.ConnPool = db.Statement.ConnPool
+	}
+	return queryset
+}
+
+// selectDB returns nil when queryset should keep its own connection.
+func selectDB(ctx context.Context, read bool) *gorm.DB {
+	if name, ok := ctx.Value(usingContextKey{}).(string); ok {
+		db, ok := DATABASES[name]
+		if !ok {
+			panic(fmt.Sprintf("database %s isn't configured in conf.DATABASES", name))
+		}
+		return db
+	}
+	if read && ctx.Value(primaryContextKey{}) == nil {
+		return ReplicaDB()
+	}
+	return nil
+}
+
+// GetDB returns DB bound to ctx.
+func GetDB(ctx context.Context) *gorm.DB {
+	return BindDB(DB, ctx)
+}
+
+// GetReadDB returns DB bound to ctx for reads.
+func GetReadDB(ctx context.Context) *gorm.DB {
+	return BindReadDB(DB, ctx)
+}
@@ -0,0 +1,94 @@
+package conf
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DATABASES are named connections besides DB, selected with Using
+// or the Using param of viewsets, example: conf.DATABASES["replica"] = replicaDB
+var DATABASES = map[string]*gorm.DB{}
+// REPLICAS are names in DATABASES serving reads of safe requests, picked round robin.
+// Replicas failing health check are skipped, reads fall back to DB when none is healthy.
+var REPLICAS []string
+// REPLICA_HEALTH_CHECK_INTERVAL is how often runserver pings replicas, zero disables.
+var REPLICA_HEALTH_CHECK_INTERVAL = 10 * time.Second
+
+var (
+	replicasDown	sync.Map
+	replicaCounter	atomic.Uint64
+)
+
+// ReplicaDB returns the next healthy replica, nil when there's none.
+func ReplicaDB() *gorm.DB {
+	healthy := []*gorm.DB{}
+	for _, name := range REPLICAS {
+		db, ok := DATABASES[name]
+		if !ok || IsReplicaDown(name) {
+			continue
+		}
+		healthy = append(healthy, db)
+	}
+	if len(healthy) == 0 {
+		return nil
+	}
+	return healthy[replicaCounter.Add(1) % uint64(len(healthy))]
+}
+
+func IsReplicaDown(name string) bool {
+	_, down := replicasDown.Load(name)
+	return down
+}
+
+// MarkReplicaDown skips the replica until a later CheckReplicas succeeds.
+func MarkReplicaDown(name string) {
+	replicasDown.Store(name, true)
+}
+
+// CheckReplicas pings every replica and updates their health.
+func CheckReplicas(ctx context.Context) {
+	for _, name := range REPLICAS {
+		db, ok := DATABASES[name]
+		if !ok {
+			continue
+		}
+		if err := pingDB(ctx, db); err != nil {
+			MarkReplicaDown(name)
+		} else {
+			replicasDown.Delete(name)
+		}
+	}
+}
+
+func pingDB(ctx context.Context, db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2 * time.Second)
+	defer cancel()
+	return sqlDB.PingContext(ctx)
+}
+
+// WatchReplicas checks replicas every interval until ctx is done.
+func WatchReplicas(ctx context.Context, interval time.Duration) {
+	if len(REPLICAS) == 0 || interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			CheckReplicas(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
@@ -1,10 +1,14 @@
 package conf
 
 import (
+	"log/slog"
 	"os"
+	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/rimba47prayoga/gorim.git/errors"
 	"github.com/rimba47prayoga/gorim.git/interfaces"
+	"github.com/rimba47prayoga/gorim.git/settings"
 	"gorm.io/gorm"
 )
 
@@ -20,13 +24,95 @@ type Database struct {
 }
 
 var ENV_PATH = ".env"
+// PROFILE of the loaded settings, see settings.Profile.
+var PROFILE = ""
+var DEBUG = false
+// ALLOWED_HOSTS are checked by runserver when not empty, see middlewares.AllowedHosts.
+var ALLOWED_HOSTS []string
+// SECRET_KEY is used for signing, keep it secret in production.
+var SECRET_KEY = ""
 var HOST = "http://localhost:8000/"
 var PORT uint = 8000
 
+// STATIC_URL and MEDIA_URL are URL prefixes served from STATIC_ROOT and MEDIA_ROOT.
+var STATIC_URL = "/static/"
+var STATIC_ROOT = "static"
+var MEDIA_URL = "/media/"
+var MEDIA_ROOT = "media"
+
+// MAX_BODY_SIZE limits request body of every route, zero disables, see middlewares.BodyLimit.
+var MAX_BODY_SIZE int64 = 10 << 20
+// Server timeouts protecting against slow clients, zero disables.
+var READ_HEADER_TIMEOUT = 10 * time.Second
+var READ_TIMEOUT = 60 * time.Second
+var WRITE_TIMEOUT time.Duration = 0
+var IDLE_TIMEOUT = 120 * time.Second
+// SHUTDOWN_TIMEOUT is how long runserver drains in-flight requests on SIGTERM.
+var SHUTDOWN_TIMEOUT = 30 * time.Second
+
+// USER_MODEL is the user model loaded by authentication and created by createsuperuser,
+// nil is models.User, example: conf.USER_MODEL = &Account{}
+var USER_MODEL interface{}
+// USERNAME_FIELD is the column identifying users at login.
+var USERNAME_FIELD = "email"
+
 var MigrationInstance interfaces.IMigrations
+// MIGRATIONS_DIR holds migration files written by makemigrations.
+var MIGRATIONS_DIR = "migrations"
+
+// TENANT_COLUMN enables scoping models having it to the tenant resolved by
+// tenancy.Middleware, empty disables, example: conf.TENANT_COLUMN = "tenant_id".
+var TENANT_COLUMN = ""
+// LOCK_VERSION_COLUMN enables optimistic locking of models having it, an integer
+// incremented by each update or time such as updated_at, empty disables, example:
+// conf.LOCK_VERSION_COLUMN = "version".
+var LOCK_VERSION_COLUMN = ""
 
 var Configure func()
 
+func init() {
+	settings.OnLoad(apply)
+}
+
+// apply syncs variables with settings loaded by settings.Load, so code reading them
+// gets the loaded values. Empty strings and zero ports aren't applied, so values set
+// in code, example: SECRET_KEY read by UseEnv, aren't cleared by settings lacking them.
+// Zero timeouts and sizes disable their limit and are applied.
+func apply(loaded *settings.Settings) {
+	PROFILE = loaded.Profile
+	DEBUG = loaded.Debug
+	errors.Debug = loaded.Debug
+	ALLOWED_HOSTS = loaded.AllowedHosts
+	if level, ok := logLevels[loaded.LogLevel]; ok {
+		slog.SetLogLoggerLevel(level)
+	}
+	if loaded.SecretKey != "" {
+		SECRET_KEY = loaded.SecretKey
+	}
+	if loaded.Server.Host != "" {
+		HOST = loaded.Server.Host
+	}
+	if loaded.Server.Port != 0 {
+		PORT = loaded.Server.Port
+	}
+	READ_HEADER_TIMEOUT = loaded.Server.ReadHeaderTimeout
+	READ_TIMEOUT = loaded.Server.ReadTimeout
+	WRITE_TIMEOUT = loaded.Server.WriteTimeout
+	IDLE_TIMEOUT = loaded.Server.IdleTimeout
+	MAX_BODY_SIZE = loaded.Server.MaxBodySize
+	SHUTDOWN_TIMEOUT = loaded.Server.ShutdownTimeout
+	if loaded.Auth.UsernameField != "" {
+		USERNAME_FIELD = loaded.Auth.UsernameField
+	}
+}
+
+var logLevels = map[string]slog.Level{
+	"debug": slog.LevelDebug,
+	"info": slog.LevelInfo,
+	"warn": slog.LevelWarn,
+	"error": slog.LevelError,
+}
+
 func UseEnv(path string) {
 	err := godotenv.Load(path)
 	if err != nil {
@@ -0,0 +1,179 @@
+package contenttypes
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git/policies"
+	"github.com/rimba47prayoga/gorim.git/tenancy"
+	"github.com/rimba47prayoga/gorim.git/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// GenericRelation points at object of any registered model, embed it in models shared
+// by many models such as comments or attachments:
+//
+//	type Comment struct {
+//		ID		uint
+//		Body	string
+//		contenttypes.GenericRelation
+//	}
+//
+// Object is the target loaded by Prefetch, serializers of the model render it.
+type GenericRelation struct {
+	ObjectType		string			`gorm:"type:varchar(100);index:,composite:generic_object" json:"object_type"`
+	ObjectID		string			`gorm:"type:varchar(64);index:,composite:generic_object" json:"object_id"`
+	Object			interface{}		`gorm:"-" json:"object,omitempty"`
+}
+
+var (
+	ErrUnknownType		= fmt.Errorf("Unknown object type.")
+	ErrTargetNotFound	= fmt.Errorf("Object does not exist.")
+)
+
+var (
+	registryMu	sync.RWMutex
+	types		= map[string]reflect.Type{}
+	names		= map[reflect.Type]string{}
+	schemaCache	= &sync.Map{}
+)
+
+// Register allows models as targets, named by their table name, example: contenttypes.Register(&Post{}, &Photo{})
+func Register(models ...interface{}) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	for _, model := range models {
+		parsed, err := parse(model)
+		if err != nil {
+			panic(err)
+		}
+		types[parsed.Table] = parsed.ModelType
+		names[parsed.ModelType] = parsed.Table
+	}
+}
+
+// TypeName returns name of registered model, empty when it isn't registered.
+func TypeName(model interface{}) string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return names[indirect(reflect.TypeOf(model))]
+}
+
+// Lookup returns model type registered under name.
+func Lookup(name string) (reflect.Type, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	typ, ok := types[name]
+	return typ, ok
+}
+
+// SetTarget points the relation at instance of registered model.
+func (r *GenericRelation) SetTarget(instance interface{}) error {
+	name := TypeName(instance)
+	if name == "" {
+		return ErrUnknownType
+	}
+	parsed, err := parse(instance)
+	if err != nil {
+		return err
+	}
+	r.ObjectType = name
+	r.ObjectID = primaryKey(parsed, reflect.ValueOf(instance))
+	r.Object = instance
+	return nil
+}
+
+// Target loads the object the relation points at, it's not found when the request c
+// may not read it, see Scope.
+func (r *GenericRelation) Target(c echo.Context, db *gorm.DB) (interface{}, error) {
+	typ, ok := Lookup(r.ObjectType)
+	if !ok {
+		return nil, ErrUnknownType
+	}
+	target := reflect.New(typ).Interface()
+	parsed, err := parse(target)
+	if err != nil {
+		return nil, err
+	}
+	if parsed.PrioritizedPrimaryField == nil {
+		return nil, fmt.Errorf("%s has no primary key", r.ObjectType)
+	}
+	id, err := utils.ParseFieldString(target, parsed.PrioritizedPrimaryField.Name, r.ObjectID)
+	if err != nil {
+		return nil, ErrTargetNotFound
+	}
+	err = Scope(c, db, target).Where(parsed.PrioritizedPrimaryField.DBName + " = ?", id).First(target).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, ErrTargetNotFound
+	}
+	return target, err
+}
+
+// Exists returns ErrUnknownType or ErrTargetNotFound when the target is invalid, use it
+// in serializer validation:
+//
+//	func (s *CommentSerializer) ValidateObjectID() {
+//		if err := contenttypes.Exists(s.GetContext(), s.DB(), s.ObjectType, s.ObjectID); err != nil {
+//			s.AddError("object_id", err.Error())
+//		}
+//	}
+func Exists(c echo.Context, db *gorm.DB, objectType string, objectID string) error {
+	relation := GenericRelation{ObjectType: objectType, ObjectID: objectID}
+	_, err := relation.Target(c, db)
+	return err
+}
+
+// Scope limits queryset of target model to rows the request c may read, like querysets
+// of viewsets: rows of its tenant, see tenancy.Scope, filtered by policies of the
+// viewset serving it. Policies filtering by columns the model doesn't have match nothing.
+func Scope(c echo.Context, queryset *gorm.DB, model interface{}) *gorm.DB {
+	queryset = tenancy.Scope(c, queryset, model)
+	return policies.Filter(c, queryset, model)
+}
+
+// For scopes queryset to rows pointing at instance, example: db.Scopes(contenttypes.For(post)).Find(&comments)
+func For(instance interface{}) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		parsed, err := parse(instance)
+		name := TypeName(instance)
+		if err != nil || name == "" {
+			return db.Where("1 = 0")
+		}
+		return db.Where("object_type = ? AND object_id = ?", name, primaryKey(parsed, reflect.ValueOf(instance)))
+	}
+}
+
+// OfType scopes queryset to rows pointing at the given models.
+func OfType(models ...interface{}) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		typeNames := []string{}
+		for _, model := range models {
+			typeNames = append(typeNames, TypeName(model))
+		}
+		return db.Where("object_type IN ?", typeNames)
+	}
+}
+
+func parse(model interface{}) (*schema.Schema, error) {
+	return schema.Parse(model, schemaCache, schema.NamingStrategy{})
+}
+
+func primaryKey(parsed *schema.Schema, value reflect.Value) string {
+	for value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+	if parsed.PrioritizedPrimaryField == nil {
+		return ""
+	}
+	return fmt.Sprint(value.FieldByIndex(parsed.PrioritizedPrimaryField.StructField.Index).Interface())
+}
+
+func indirect(typ reflect.Type) reflect.Type {
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ
+}
@@ -0,0 +1,102 @@
+package contenttypes
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git/utils"
+	"gorm.io/gorm"
+)
+
+var relationType = reflect.TypeOf(GenericRelation{})
+
+// HasRelation reports whether model embeds GenericRelation.
+func HasRelation(model interface{}) bool {
+	typ := indirect(reflect.TypeOf(model))
+	for typ != nil && (typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array) {
+		typ = indirect(typ.Elem())
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return false
+	}
+	field, ok := typ.FieldByName("GenericRelation")
+	return ok && field.Type == relationType
+}
+
+// Prefetch loads Object of the relations in data, an instance or slice of instances,
+// with one query per object type. Objects the request c may not read are left nil, see Scope.
+func Prefetch(c echo.Context, db *gorm.DB, data interface{}) error {
+	if !HasRelation(data) {
+		return nil
+	}
+	relations := collect(reflect.ValueOf(data))
+	byType := map[string][]*GenericRelation{}
+	for _, relation := range relations {
+		byType[relation.ObjectType] = append(byType[relation.ObjectType], relation)
+	}
+	for objectType, group := range byType {
+		typ, ok := Lookup(objectType)
+		if !ok {
+			continue
+		}
+		parsed, err := parse(reflect.New(typ).Interface())
+		if err != nil {
+			return err
+		}
+		if parsed.PrioritizedPrimaryField == nil {
+			continue
+		}
+		ids := []interface{}{}
+		for _, relation := range group {
+			id, err := utils.ParseFieldString(reflect.New(typ).Interface(), parsed.PrioritizedPrimaryField.Name, relation.ObjectID)
+			if err == nil {
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) == 0 {
+			continue
+		}
+		targets := reflect.New(reflect.SliceOf(reflect.PointerTo(typ)))
+		queryset := Scope(c, db, reflect.New(typ).Interface())
+		if err := queryset.Where(parsed.PrioritizedPrimaryField.DBName + " IN ?", ids).Find(targets.Interface()).Error; err != nil {
+			return err
+		}
+		byID := map[string]interface{}{}
+		for i := 0; i < targets.Elem().Len(); i++ {
+			target := targets.Elem().Index(i)
+			byID[primaryKey(parsed, target)] = target.Interface()
+		}
+		for _, relation := range group {
+			relation.Object = byID[relation.ObjectID]
+		}
+	}
+	return nil
+}
+
+// collect returns addressable relations of value, values which can't be set are skipped.
+func collect(value reflect.Value) []*GenericRelation {
+	relations := []*GenericRelation{}
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return relations
+		}
+		value = value.Elem()
+	}
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			relations = append(relations, collect(value.Index(i))...)
+		}
+	case reflect.Struct:
+		field := value.FieldByName("GenericRelation")
+		if field.IsValid() && field.CanAddr() && field.Type() == relationType {
+			relations = append(relations, field.Addr().Interface().(*GenericRelation))
+		}
+	}
+	return relations
+}
+
+func (r GenericRelation) String() string {
+	return fmt.Sprintf("%s:%s", r.ObjectType, r.ObjectID)
+}
@@ -2,18 +2,34 @@ package gorim
 
 import (
 	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git/flags"
+	"github.com/rimba47prayoga/gorim.git/i18n"
+	"github.com/rimba47prayoga/gorim.git/parsers"
+	"github.com/rimba47prayoga/gorim.git/renderers"
+	"github.com/rimba47prayoga/gorim.git/sse"
+)
+
+// Keys used to store request state on echo's context,
+// so middlewares can populate them without depending on gorim.
+const (
+	UserContextKey				= "user"
+	ActionContextKey			= "action"
+	ParentLookupsContextKey		= "parent_lookups"
+	VersionContextKey			= "version"
+	TenantContextKey			= "tenant"
 )
 
 // Context is a custom context that extends Echo's Context
 type Context struct {
     echo.Context
-	User	interface{}
+	user	interface{}
 }
 
 // NewContext creates a new Gorim context
 func NewContext(echoContext echo.Context) Context {
     return Context{
         Context: echoContext,
+        user: echoContext.Get(UserContextKey),
     }
 }
 
@@ -25,3 +41,89 @@ func (c *Context) GetBool(key string) bool {
 	}
 	return false
 }
+
+// User returns the authenticated user, nil for anonymous request.
+func (c *Context) User() interface{} {
+	return c.user
+}
+
+func (c *Context) IsAuthenticated() bool {
+	return c.user != nil
+}
+
+// SetUser marks the request as authenticated by the given user.
+func (c *Context) SetUser(user interface{}) {
+	c.user = user
+	c.Set(UserContextKey, user)
+}
+
+// GetAction returns the viewset action name handling the request.
+func (c *Context) GetAction() string {
+	action, _ := c.Get(ActionContextKey).(string)
+	return action
+}
+
+// GetParentLookups returns field => value of parent viewsets for nested routes,
+// example: {"project_id": "1"} for /projects/1/tasks.
+func (c *Context) GetParentLookups() map[string]string {
+	if c.Context == nil {
+		return nil
+	}
+	lookups, _ := c.Get(ParentLookupsContextKey).(map[string]string)
+	return lookups
+}
+
+// Version returns the API version resolved by versioning middleware.
+func (c *Context) Version() string {
+	version, _ := c.Get(VersionContextKey).(string)
+	return version
+}
+
+// Tenant returns the tenant resolved by tenancy middleware, empty when there's none.
+func (c *Context) Tenant() string {
+	if c.Context == nil {
+		return ""
+	}
+	tenant, _ := c.Get(TenantContextKey).(string)
+	return tenant
+}
+
+// Language returns the language of the request, see i18n.Language.
+func (c *Context) Language() string {
+	return i18n.Language(c.Context)
+}
+
+// T translates message id to the language of the request.
+func (c *Context) T(id string, args ...any) string {
+	return i18n.Translate(c.Language(), id, args...)
+}
+
+// FeatureEnabled reports whether feature flag name is on for the user of the request,
+// see flags.Flag, the answer doesn't change during the request.
+func (c *Context) FeatureEnabled(name string) bool {
+	return flags.EnabledInRequest(c.Context, name, c.user)
+}
+
+// Respond writes data with renderer negotiated from Accept header,
+// use it instead of JSON so views can emit other formats, see renderers.Register.
+func (c *Context) Respond(status int, data any) error {
+	return renderers.Render(c.Context, status, data)
+}
+
+// RawBody returns the request body, safe to call many times and before Bind.
+func (c *Context) RawBody() ([]byte, error) {
+	return parsers.RawBody(c.Context)
+}
+
+// EventStream serves server-sent events written by handler, see sse.Serve, example:
+//
+//	return c.EventStream(func(stream *sse.Stream) error {
+//		return stream.Send(sse.Event{Event: "ping", Data: "ok"})
+//	})
+func (c *Context) EventStream(handler func(*sse.Stream) error, options ...sse.Options) error {
+	var streamOptions sse.Options
+	if len(options) > 0 {
+		streamOptions = options[0]
+	}
+	return sse.Serve(c.Response(), c.Request(), streamOptions, handler)
+}
@@ -0,0 +1,243 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rimba47prayoga/gorim.git/conf"
+	"github.com/rimba47prayoga/gorim.git/health"
+	"github.com/rimba47prayoga/gorim.git/settings"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// DefaultAlias is the alias stored in conf.DB, other aliases are stored in conf.DATABASES.
+const DefaultAlias = "default"
+
+// Config of a connection, example:
+//
+//	database.Config{Dialector: postgres.Open, DSN: conf.GetEnv("DATABASE_URL", ""), MaxOpenConns: 50}
+type Config struct {
+	// Dialector opens DSN, example: postgres.Open or mysql.Open.
+	Dialector			func(dsn string) gorm.Dialector
+	DSN					string
+	MaxOpenConns		int
+	MaxIdleConns		int
+	ConnMaxLifetime		time.Duration
+	ConnMaxIdleTime		time.Duration
+	// ConnectTimeout limits the ping verifying the connection on open, default 5 seconds.
+	ConnectTimeout		time.Duration
+	LogLevel			logger.LogLevel
+	SlowThreshold		time.Duration
+	// Replica adds the alias to conf.REPLICAS, so it serves reads of safe requests.
+	Replica				bool
+	// GormConfig is used as is when set, LogLevel and SlowThreshold are ignored.
+	GormConfig			*gorm.Config
+}
+
+// FromSettings returns Config of settings.Get().Database opened by dialector, DSN of the
+// settings is used as is, otherwise it's built from the other fields in key=value form
+// understood by postgres, example:
+//
+//	database.MustSetup(map[string]database.Config{database.DefaultAlias: database.FromSettings(postgres.Open)})
+func FromSettings(dialector func(dsn string) gorm.Dialector) Config {
+	return configOf(settings.Get().Database, dialector)
+}
+
+// AllFromSettings returns FromSettings as DefaultAlias with Databases of the settings
+// by alias, example: database.MustSetup(database.AllFromSettings(postgres.Open))
+func AllFromSettings(dialector func(dsn string) gorm.Dialector) map[string]Config {
+	configs := map[string]Config{DefaultAlias: FromSettings(dialector)}
+	for alias, config := range settings.Get().Databases {
+		configs[alias] = configOf(config, dialector)
+	}
+	return configs
+}
+
+func configOf(config settings.Database, dialector func(dsn string) gorm.Dialector) Config {
+	dsn := config.DSN
+	if dsn == "" {
+		parts := []string{}
+		for _, pair := range [][2]string{
+			{"host", config.Host},
+			{"port", strconv.Itoa(config.Port)},
+			{"user", config.User},
+			{"password", config.Password},
+			{"dbname", config.Name},
+		} {
+			if pair[1] != "" && pair[1] != "0" {
+				parts = append(parts, pair[0] + "=" + pair[1])
+			}
+		}
+		dsn = strings.Join(append(parts, "sslmode=disable"), " ")
+	}
+	return Config{
+		Dialector: dialector,
+		DSN: dsn,
+		MaxOpenConns: config.MaxOpenConns,
+		MaxIdleConns: config.MaxIdleConns,
+		ConnMaxLifetime: config.ConnMaxLifetime,
+		LogLevel: logLevels[settings.Get().LogLevel],
+		Replica: config.Replica,
+	}
+}
+
+// logLevels maps log level of the settings to gorm, debug logs every query.
+var logLevels = map[string]logger.LogLevel{
+	"debug": logger.Info,
+	"info": logger.Warn,
+	"warn": logger.Warn,
+	"error": logger.Error,
+}
+
+func (config Config) getConnectTimeout() time.Duration {
+	if config.ConnectTimeout == 0 {
+		return 5 * time.Second
+	}
+	return config.ConnectTimeout
+}
+
+func (config Config) gormConfig() *gorm.Config {
+	if config.GormConfig != nil {
+		return config.GormConfig
+	}
+	level := config.LogLevel
+	if level == 0 {
+		level = logger.Warn
+	}
+	slowThreshold := config.SlowThreshold
+	if slowThreshold == 0 {
+		slowThreshold = 200 * time.Millisecond
+	}
+	return &gorm.Config{
+		Logger: logger.New(log.New(os.Stdout, "\r\n", log.LstdFlags), logger.Config{
+			SlowThreshold: slowThreshold,
+			LogLevel: level,
+			IgnoreRecordNotFoundError: true,
+		}),
+	}
+}
+
+// Open connects and configures the pool, the connection is verified with ping.
+func Open(config Config) (*gorm.DB, error) {
+	if config.Dialector == nil {
+		return nil, fmt.Errorf("database config requires Dialector")
+	}
+	db, err := gorm.Open(config.Dialector(config.DSN), config.gormConfig())
+	if err != nil {
+		return nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	if config.MaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(config.MaxOpenConns)
+	}
+	if config.MaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(config.MaxIdleConns)
+	}
+	if config.ConnMaxLifetime > 0 {
+		sqlDB.SetConnMaxLifetime(config.ConnMaxLifetime)
+	}
+	if config.ConnMaxIdleTime > 0 {
+		sqlDB.SetConnMaxIdleTime(config.ConnMaxIdleTime)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), config.getConnectTimeout())
+	defer cancel()
+	if err := sqlDB.PingContext(ctx); err != nil {
+		sqlDB.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// Setup opens connections of configs keyed by alias, DefaultAlias is required and stored in conf.DB,
+// others in conf.DATABASES. Each connection is registered as health check "database:<alias>".
+// Connections opened before an error are closed.
+func Setup(configs map[string]Config) error {
+	if _, ok := configs[DefaultAlias]; !ok {
+		return fmt.Errorf("database config %s is required", DefaultAlias)
+	}
+	aliases := make([]string, 0, len(configs))
+	for alias := range configs {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	opened := map[string]*gorm.DB{}
+	for _, alias := range aliases {
+		db, err := Open(configs[alias])
+		if err != nil {
+			closeAll(opened)
+			return fmt.Errorf("database %s: %w", alias, err)
+		}
+		opened[alias] = db
+	}
+	for _, alias := range aliases {
+		db := opened[alias]
+		if alias == DefaultAlias {
+			conf.DB = db
+		} else {
+			conf.DATABASES[alias] = db
+		}
+		if configs[alias].Replica {
+			conf.REPLICAS = append(conf.REPLICAS, alias)
+		}
+		health.Register("database:" + alias, Checker(db))
+	}
+	return nil
+}
+
+// MustSetup is Setup panicking on error, for settings.
+func MustSetup(configs map[string]Config) {
+	if err := Setup(configs); err != nil {
+		panic(err)
+	}
+}
+
+// Close closes conf.DB and conf.DATABASES.
+func Close() error {
+	all := map[string]*gorm.DB{}
+	for alias, db := range conf.DATABASES {
+		all[alias] = db
+	}
+	if conf.DB != nil {
+		all[DefaultAlias] = conf.DB
+	}
+	return closeAll(all)
+}
+
+func closeAll(dbs map[string]*gorm.DB) error {
+	var result error
+	for alias, db := range dbs {
+		sqlDB, err := db.DB()
+		if err == nil {
+			err = sqlDB.Close()
+		}
+		if err != nil && result == nil {
+			result = fmt.Errorf("database %s: %w", alias, err)
+		}
+	}
+	return result
+}
+
+// Checker pings db, reporting open connections when it fails.
+func Checker(db *gorm.DB) health.Checker {
+	return health.CheckerFunc(func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		if err := sqlDB.PingContext(ctx); err != nil {
+			stats := sqlDB.Stats()
+			return fmt.Errorf("%w (open %d, in use %d)", err, stats.OpenConnections, stats.InUse)
+		}
+		return nil
+	})
+}
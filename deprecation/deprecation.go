@@ -0,0 +1,205 @@
+package deprecation
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/errors"
+	"github.com/rimba47prayoga/gorim.git/metrics"
+	"github.com/rimba47prayoga/gorim.git/routers"
+)
+
+var requestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gorim",
+	Name: "deprecated_requests_total",
+	Help: "Total requests of deprecated endpoints by route, method and version.",
+}, []string{"route", "method", "version"})
+
+func init() {
+	metrics.Registry.MustRegister(requestsTotal)
+}
+
+const stateContextKey = "gorim:deprecation"
+
+// Policy of a deprecated endpoint, responses carry the Deprecation header of RFC 9745,
+// Sunset of RFC 8594 and Link to the documentation and successor, example:
+//
+//	Deprecation: @1735689600
+//	Sunset: Tue, 30 Jun 2026 00:00:00 GMT
+//	Link: <https://docs.example.com/v2-migration>; rel="deprecation"; type="text/html"
+type Policy struct {
+	// Date the endpoint was deprecated, zero sends "Deprecation: true" of earlier drafts.
+	Date		time.Time
+	// Sunset is when the endpoint stops responding, zero omits the header.
+	Sunset		time.Time
+	// Link to documentation of the deprecation.
+	Link		string
+	// Successor links the endpoint replacing this one.
+	Successor	string
+	// Gone responds 410 Gone once Sunset has passed.
+	Gone		bool
+}
+
+func (p *Policy) isGone() bool {
+	return p.Gone && !p.Sunset.IsZero() && !time.Now().Before(p.Sunset)
+}
+
+// IDeprecatedView is implemented by viewsets deprecating actions,
+// return nil for actions that aren't deprecated.
+type IDeprecatedView interface {
+	Deprecation(action string) *Policy
+}
+
+type Config struct {
+	// Versions deprecates API versions resolved by versioning.Middleware.
+	Versions	map[string]*Policy
+}
+
+// state holds the policies found for the request, the most specific one is sent:
+// the action, then the route, then the version.
+type state struct {
+	action		*Policy
+	route		*Policy
+	version		*Policy
+}
+
+func (s *state) policy() *Policy {
+	if s.action != nil {
+		return s.action
+	}
+	if s.route != nil {
+		return s.route
+	}
+	return s.version
+}
+
+// Middleware sends headers of deprecated actions and versions, and of routes marked by
+// Deprecate, and counts their requests in metrics, example:
+//
+//	server.Use(deprecation.Middleware(deprecation.Config{
+//		Versions: map[string]*deprecation.Policy{"v1": {Sunset: sunset, Successor: "/api/v2"}},
+//	}))
+//
+// Use it after versioning.Middleware for Versions to apply.
+func Middleware(config Config) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			s := stateOf(c)
+			s.action = actionPolicy(c.Request().Method, c.Path())
+			if version, ok := c.Get(gorim.VersionContextKey).(string); ok {
+				s.version = config.Versions[version]
+			}
+			if policy := s.policy(); policy != nil && policy.isGone() {
+				return gone(c)
+			}
+			return next(c)
+		}
+	}
+}
+
+// Deprecate marks routes of a group or a single route as deprecated, example:
+//
+//	v1 := server.Group("/api/v1", deprecation.Deprecate(deprecation.Policy{Sunset: sunset}))
+func Deprecate(policy Policy) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			s := stateOf(c)
+			s.route = &policy
+			if s.policy().isGone() {
+				return gone(c)
+			}
+			return next(c)
+		}
+	}
+}
+
+// stateOf returns state of the request, headers are written by the first call just
+// before the response, so middlewares running later can still add their policy.
+func stateOf(c echo.Context) *state {
+	if s, ok := c.Get(stateContextKey).(*state); ok {
+		return s
+	}
+	s := &state{}
+	c.Set(stateContextKey, s)
+	c.Response().Before(func() {
+		policy := s.policy()
+		if policy == nil {
+			return
+		}
+		writeHeaders(c.Response().Header(), policy)
+		version, _ := c.Get(gorim.VersionContextKey).(string)
+		requestsTotal.WithLabelValues(c.Path(), c.Request().Method, version).Inc()
+	})
+	return s
+}
+
+func writeHeaders(header http.Header, policy *Policy) {
+	if policy.Date.IsZero() {
+		header.Set("Deprecation", "true")
+	} else {
+		header.Set("Deprecation", "@" + strconv.FormatInt(policy.Date.Unix(), 10))
+	}
+	if !policy.Sunset.IsZero() {
+		header.Set("Sunset", policy.Sunset.UTC().Format(http.TimeFormat))
+	}
+	if policy.Link != "" {
+		header.Add("Link", "<" + policy.Link + ">; rel=\"deprecation\"; type=\"text/html\"")
+	}
+	if policy.Successor != "" {
+		header.Add("Link", "<" + policy.Successor + ">; rel=\"successor-version\"")
+	}
+}
+
+func gone(c echo.Context) error {
+	return errors.Handle(&errors.APIError{
+		Status: http.StatusGone,
+		Message: "This endpoint has been removed.",
+		Code: "gone",
+	}, c)
+}
+
+var (
+	actionsMu	sync.RWMutex
+	// actions caches policies of viewset routes by method and path, nil when not deprecated.
+	actions		= map[string]*Policy{}
+)
+
+// actionPolicy returns policy of the viewset action of route.
+func actionPolicy(method string, path string) *Policy {
+	key := method + " " + path
+	actionsMu.RLock()
+	policy, ok := actions[key]
+	actionsMu.RUnlock()
+	if ok {
+		return policy
+	}
+	if route, found := routers.LookupRoute(method, path); found {
+		policy = Of(route)
+	}
+	actionsMu.Lock()
+	actions[key] = policy
+	actionsMu.Unlock()
+	return policy
+}
+
+// Of returns policy of the viewset action of route, nil when it isn't deprecated,
+// used by schema generation.
+func Of(route routers.RouteInfo) (policy *Policy) {
+	if route.HandlerFunc == nil {
+		return nil
+	}
+	defer func() {
+		if recover() != nil {
+			policy = nil
+		}
+	}()
+	if view, ok := route.HandlerFunc().(IDeprecatedView); ok {
+		return view.Deprecation(route.Action)
+	}
+	return nil
+}
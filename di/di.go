@@ -0,0 +1,363 @@
+package di
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Lifetime of values created by providers.
+type Lifetime int
+
+const (
+	// Singleton values are created once by the container.
+	Singleton Lifetime = iota
+	// Scoped values are created once per request, closed at its end when they're io.Closer.
+	Scoped
+	// Transient values are created every time they're resolved.
+	Transient
+)
+
+func (l Lifetime) String() string {
+	switch l {
+	case Singleton:
+		return "singleton"
+	case Scoped:
+		return "scoped"
+	}
+	return "transient"
+}
+
+type provider struct {
+	lifetime	Lifetime
+	factory		func(*Scope) (any, error)
+	once		sync.Once
+	value		any
+	err			error
+}
+
+// Container holds providers of dependencies by type, example:
+//
+//	di.Value[*slog.Logger](di.Default, logger)
+//	di.Provide(di.Default, di.Singleton, func(s *di.Scope) (Mailer, error) {
+//		return NewSMTPMailer(settings.Get().Email), nil
+//	})
+//	di.Provide(di.Default, di.Scoped, func(s *di.Scope) (*InvoiceRepository, error) {
+//		return &InvoiceRepository{DB: conf.GetDB(s.Context())}, nil
+//	})
+//
+// Viewsets declare dependencies as exported fields tagged `inject:""`, routers check
+// them and create singletons when viewsets are registered, then set the fields of
+// every viewset they create for a request:
+//
+//	type InvoiceViewSet struct {
+//		views.ModelViewSet[Invoice]
+//		Invoices	*InvoiceRepository	`inject:""`
+//		Mailer		Mailer				`inject:""`
+//	}
+type Container struct {
+	mu			sync.RWMutex
+	providers	map[reflect.Type]*provider
+}
+
+// Default is the container of routers without Container.
+var Default = New()
+
+func New() *Container {
+	return &Container{providers: map[reflect.Type]*provider{}}
+}
+
+// singletons returns scope creating singletons, they can't depend on scoped values.
+func (c *Container) singletons() *Scope {
+	return &Scope{container: c, ctx: context.Background(), singleton: true}
+}
+
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// Provide registers factory of T, replacing the provider T had.
+func Provide[T any](c *Container, lifetime Lifetime, factory func(*Scope) (T, error)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.providers[typeOf[T]()] = &provider{
+		lifetime: lifetime,
+		factory: func(s *Scope) (any, error) {
+			return factory(s)
+		},
+	}
+}
+
+// Value registers value as the singleton of T.
+func Value[T any](c *Container, value T) {
+	Provide(c, Singleton, func(*Scope) (T, error) {
+		return value, nil
+	})
+}
+
+// Has reports whether T has a provider.
+func Has[T any](c *Container) bool {
+	return c.provider(typeOf[T]()) != nil
+}
+
+func (c *Container) provider(typ reflect.Type) *provider {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.providers[typ]
+}
+
+// Check verifies every dependency of target has a provider and creates singletons, so
+// missing providers and failing singletons are found at startup, used by routers when
+// viewsets are registered.
+func (c *Container) Check(target any) error {
+	fields, err := injectedFields(reflect.TypeOf(target))
+	if err != nil {
+		return err
+	}
+	for _, field := range fields {
+		p := c.provider(field.Type)
+		if p == nil {
+			return fmt.Errorf("di: no provider of %s for %s.%s", field.Type, indirect(reflect.TypeOf(target)), field.Name)
+		}
+		if p.lifetime == Singleton {
+			if _, err := c.singletons().resolve(field.Type); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+const scopeContextKey = "gorim:di"
+
+// Begin returns the scope of request c, created by the first call, end closes it and
+// is a no-op for calls finding the scope created.
+func (c *Container) Begin(ctx echo.Context) (*Scope, func()) {
+	scopes, _ := ctx.Get(scopeContextKey).(map[*Container]*Scope)
+	if scope, ok := scopes[c]; ok {
+		return scope, func() {}
+	}
+	if scopes == nil {
+		scopes = map[*Container]*Scope{}
+		ctx.Set(scopeContextKey, scopes)
+	}
+	scope := &Scope{container: c, ctx: ctx.Request().Context(), request: ctx}
+	scopes[c] = scope
+	return scope, func() {
+		delete(scopes, c)
+		scope.Close()
+	}
+}
+
+// Middleware begins the scope of requests for handlers outside routers, example:
+// server.Use(di.Default.Middleware)
+func (c *Container) Middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		_, end := c.Begin(ctx)
+		defer end()
+		return next(ctx)
+	}
+}
+
+// Scope resolves dependencies of a request, scoped values are created once.
+type Scope struct {
+	container	*Container
+	ctx			context.Context
+	request		echo.Context
+	singleton	bool
+	mu			sync.Mutex
+	values		map[reflect.Type]any
+	// created keeps scoped values in order, closed in reverse order.
+	created		[]any
+	resolving	[]reflect.Type
+}
+
+// Context returns context of the request, background for singletons.
+func (s *Scope) Context() context.Context {
+	return s.ctx
+}
+
+// Request returns the request of the scope, nil for singletons.
+func (s *Scope) Request() echo.Context {
+	return s.request
+}
+
+// Resolve returns value of T, example: invoices, err := di.Resolve[*InvoiceRepository](scope)
+func Resolve[T any](s *Scope) (T, error) {
+	var zero T
+	value, err := s.resolve(typeOf[T]())
+	if err != nil || value == nil {
+		return zero, err
+	}
+	return value.(T), nil
+}
+
+// From resolves T in the scope of request c of Default container.
+func From[T any](c echo.Context) (T, error) {
+	scope, _ := Default.Begin(c)
+	return Resolve[T](scope)
+}
+
+func (s *Scope) resolve(typ reflect.Type) (any, error) {
+	p := s.container.provider(typ)
+	if p == nil {
+		return nil, fmt.Errorf("di: no provider of %s", typ)
+	}
+	switch p.lifetime {
+	case Singleton:
+		if !s.singleton {
+			return s.container.singletons().resolve(typ)
+		}
+		s.mu.Lock()
+		if err := s.enter(typ); err != nil {
+			s.mu.Unlock()
+			return nil, err
+		}
+		s.mu.Unlock()
+		p.once.Do(func() {
+			p.value, p.err = p.factory(s)
+		})
+		s.leave()
+		return p.value, p.err
+	case Scoped:
+		if s.singleton {
+			return nil, fmt.Errorf("di: %s is scoped, singletons can't depend on it", typ)
+		}
+		s.mu.Lock()
+		if value, ok := s.values[typ]; ok {
+			s.mu.Unlock()
+			return value, nil
+		}
+		err := s.enter(typ)
+		s.mu.Unlock()
+		if err != nil {
+			return nil, err
+		}
+		value, err := p.factory(s)
+		s.leave()
+		if err != nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.values == nil {
+			s.values = map[reflect.Type]any{}
+		}
+		s.values[typ] = value
+		s.created = append(s.created, value)
+		return value, nil
+	}
+	s.mu.Lock()
+	err := s.enter(typ)
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	defer s.leave()
+	return p.factory(s)
+}
+
+// enter tracks types being created to report dependency cycles.
+func (s *Scope) enter(typ reflect.Type) error {
+	for i, resolving := range s.resolving {
+		if resolving == typ {
+			names := []string{}
+			for _, cycle := range append(slices.Clone(s.resolving[i:]), typ) {
+				names = append(names, cycle.String())
+			}
+			return fmt.Errorf("di: dependency cycle %s", strings.Join(names, " -> "))
+		}
+	}
+	s.resolving = append(s.resolving, typ)
+	return nil
+}
+
+func (s *Scope) leave() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.resolving = s.resolving[:len(s.resolving) - 1]
+}
+
+// Inject sets fields of target tagged `inject:""`, target is a pointer to struct.
+func (s *Scope) Inject(target any) error {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return fmt.Errorf("di: Inject needs a pointer to struct, got %T", target)
+	}
+	fields, err := injectedFields(value.Type())
+	if err != nil {
+		return err
+	}
+	value = value.Elem()
+	for _, field := range fields {
+		dependency, err := s.resolve(field.Type)
+		if err != nil {
+			return err
+		}
+		if dependency != nil {
+			value.FieldByIndex(field.Index).Set(reflect.ValueOf(dependency))
+		}
+	}
+	return nil
+}
+
+// Close closes scoped values implementing io.Closer in reverse order of creation.
+func (s *Scope) Close() error {
+	s.mu.Lock()
+	created := s.created
+	s.created, s.values = nil, nil
+	s.mu.Unlock()
+	var errs []error
+	for i := len(created) - 1; i >= 0; i-- {
+		if closer, ok := created[i].(io.Closer); ok {
+			errs = append(errs, closer.Close())
+		}
+	}
+	return stderrors.Join(errs...)
+}
+
+var (
+	fieldsMu	sync.RWMutex
+	fieldsCache	= map[reflect.Type][]reflect.StructField{}
+)
+
+// injectedFields returns fields tagged `inject:""`, of embedded structs too.
+func injectedFields(typ reflect.Type) ([]reflect.StructField, error) {
+	typ = indirect(typ)
+	fieldsMu.RLock()
+	fields, ok := fieldsCache[typ]
+	fieldsMu.RUnlock()
+	if ok {
+		return fields, nil
+	}
+	fields = []reflect.StructField{}
+	if typ.Kind() == reflect.Struct {
+		for _, field := range reflect.VisibleFields(typ) {
+			if _, ok := field.Tag.Lookup("inject"); !ok {
+				continue
+			}
+			if !field.IsExported() {
+				return nil, fmt.Errorf("di: %s.%s must be exported to be injected", typ, field.Name)
+			}
+			fields = append(fields, field)
+		}
+	}
+	fieldsMu.Lock()
+	fieldsCache[typ] = fields
+	fieldsMu.Unlock()
+	return fields, nil
+}
+
+func indirect(typ reflect.Type) reflect.Type {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ
+}
@@ -1,5 +1,19 @@
 package errors
 
+import (
+    "fmt"
+    "math"
+    "net/http"
+    "time"
+)
+
+// APIException is an error rendered by ExceptionHandler with its status code and body.
+type APIException interface {
+    error
+    StatusCode() int
+    Body() map[string]any
+}
+
 type InternalServerError struct {
     Message string
 }
@@ -7,3 +21,123 @@ type InternalServerError struct {
 func (e *InternalServerError) Error() string {
     return e.Message
 }
+
+func (e *InternalServerError) StatusCode() int {
+    return http.StatusInternalServerError
+}
+
+func (e *InternalServerError) Body() map[string]any {
+    return map[string]any{"error": e.Message, "code": CodeServerError}
+}
+
+type PermissionDeniedError struct {
+    Message string
+    Code    string
+}
+
+func (e *PermissionDeniedError) Error() string {
+    return e.Message
+}
+
+func (e *PermissionDeniedError) StatusCode() int {
+    return http.StatusForbidden
+}
+
+func (e *PermissionDeniedError) Body() map[string]any {
+    return map[string]any{"error": e.Message, "code": codeOr(e.Code, CodePermissionDenied)}
+}
+
+// NotAuthenticatedError is PermissionDeniedError for anonymous requests.
+type NotAuthenticatedError struct {
+    Message string
+    Code    string
+}
+
+func (e *NotAuthenticatedError) Error() string {
+    return e.Message
+}
+
+func (e *NotAuthenticatedError) StatusCode() int {
+    return http.StatusUnauthorized
+}
+
+func (e *NotAuthenticatedError) Body() map[string]any {
+    return map[string]any{"error": e.Message, "code": codeOr(e.Code, CodeNotAuthenticated)}
+}
+
+// AuthenticationFailedError is raised for invalid credentials.
+type AuthenticationFailedError struct {
+    Message string
+}
+
+func (e *AuthenticationFailedError) Error() string {
+    return e.Message
+}
+
+func (e *AuthenticationFailedError) StatusCode() int {
+    return http.StatusUnauthorized
+}
+
+func (e *AuthenticationFailedError) Body() map[string]any {
+    return map[string]any{"error": e.Message, "code": CodeAuthenticationFailed}
+}
+
+// ThrottledError is rendered as 429 with Retry-After header.
+type ThrottledError struct {
+    Wait    time.Duration
+}
+
+func (e *ThrottledError) Seconds() int {
+    return int(math.Ceil(e.Wait.Seconds()))
+}
+
+func (e *ThrottledError) Error() string {
+    format, args := e.MessageFormat()
+    return fmt.Sprintf(format, args...)
+}
+
+func (e *ThrottledError) MessageFormat() (string, []any) {
+    return "Request was throttled. Expected available in %d seconds.", []any{e.Seconds()}
+}
+
+func (e *ThrottledError) StatusCode() int {
+    return http.StatusTooManyRequests
+}
+
+func (e *ThrottledError) Body() map[string]any {
+    return map[string]any{"error": e.Error(), "code": CodeThrottled}
+}
+
+func (e *ThrottledError) Headers() map[string]string {
+    return map[string]string{"Retry-After": fmt.Sprint(e.Seconds())}
+}
+
+// APIError is an error with arbitrary status, Code defaults to the code of the status,
+// example: &errors.APIError{Status: http.StatusConflict, Message: "Already exists."}
+type APIError struct {
+    Status  int
+    Message string
+    Code    string
+}
+
+func (e *APIError) Error() string {
+    return e.Message
+}
+
+func (e *APIError) StatusCode() int {
+    return e.Status
+}
+
+func (e *APIError) Body() map[string]any {
+    return map[string]any{"error": e.Message, "code": codeOr(e.Code, StatusCode(e.Status))}
+}
+
+// PanicError wraps a recovered value which isn't an error raised by errors.Raise.
+type PanicError struct {
+    Value   any
+    Stack   []byte
+}
+
+func (e *PanicError) Error() string {
+    return fmt.Sprint(e.Value)
+}
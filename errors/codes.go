@@ -0,0 +1,93 @@
+package errors
+
+import (
+    "net/http"
+    "time"
+)
+
+// Stable codes included in error responses, clients should branch on them instead of messages.
+const (
+    CodeInvalid                 = "invalid"
+    CodeParseError              = "parse_error"
+    CodeNotAuthenticated        = "not_authenticated"
+    CodeAuthenticationFailed    = "authentication_failed"
+    CodePermissionDenied        = "permission_denied"
+    CodeNotFound                = "not_found"
+    CodeMethodNotAllowed        = "method_not_allowed"
+    CodeNotAcceptable           = "not_acceptable"
+    CodeConflict                = "conflict"
+    CodeRequestTooLarge         = "request_too_large"
+    CodeUnsupportedMediaType    = "unsupported_media_type"
+    CodeThrottled               = "throttled"
+    CodeServerError             = "server_error"
+    CodeServiceUnavailable      = "service_unavailable"
+)
+
+var statusCodes = map[int]string{
+    http.StatusBadRequest:              CodeInvalid,
+    http.StatusUnauthorized:            CodeNotAuthenticated,
+    http.StatusForbidden:               CodePermissionDenied,
+    http.StatusNotFound:                CodeNotFound,
+    http.StatusMethodNotAllowed:        CodeMethodNotAllowed,
+    http.StatusNotAcceptable:           CodeNotAcceptable,
+    http.StatusConflict:                CodeConflict,
+    http.StatusRequestEntityTooLarge:   CodeRequestTooLarge,
+    http.StatusUnsupportedMediaType:    CodeUnsupportedMediaType,
+    http.StatusTooManyRequests:         CodeThrottled,
+    http.StatusInternalServerError:     CodeServerError,
+    http.StatusServiceUnavailable:      CodeServiceUnavailable,
+}
+
+// StatusCode returns the default code of HTTP status, "error" for unknown status.
+func StatusCode(status int) string {
+    if code, ok := statusCodes[status]; ok {
+        return code
+    }
+    return "error"
+}
+
+func codeOr(code string, defaultCode string) string {
+    if code == "" {
+        return defaultCode
+    }
+    return code
+}
+
+func NotFound(message string) *ObjectNotFoundError {
+    return &ObjectNotFoundError{Message: message}
+}
+
+func PermissionDenied(message string) *PermissionDeniedError {
+    return &PermissionDeniedError{Message: message, Code: CodePermissionDenied}
+}
+
+func NotAuthenticated(message string) *NotAuthenticatedError {
+    return &NotAuthenticatedError{Message: message, Code: CodeNotAuthenticated}
+}
+
+func AuthenticationFailed(message string) *AuthenticationFailedError {
+    return &AuthenticationFailedError{Message: message}
+}
+
+// Invalid returns validation error of field, empty field is non_field_errors.
+func Invalid(field string, message string) ValidationErrors {
+    validationErrors := ValidationErrors{}
+    validationErrors.Add(field, message)
+    return validationErrors
+}
+
+func BadRequest(message string) *APIError {
+    return &APIError{Status: http.StatusBadRequest, Message: message, Code: CodeInvalid}
+}
+
+func Conflict(message string) *APIError {
+    return &APIError{Status: http.StatusConflict, Message: message, Code: CodeConflict}
+}
+
+func Throttled(wait time.Duration) *ThrottledError {
+    return &ThrottledError{Wait: wait}
+}
+
+func ServerError(message string) *InternalServerError {
+    return &InternalServerError{Message: message}
+}
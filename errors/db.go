@@ -1,5 +1,7 @@
 package errors
 
+import "net/http"
+
 // ObjectNotFoundError represents a custom error type for not found objects
 type ObjectNotFoundError struct {
     Message string
@@ -8,3 +10,11 @@ type ObjectNotFoundError struct {
 func (e *ObjectNotFoundError) Error() string {
     return e.Message
 }
+
+func (e *ObjectNotFoundError) StatusCode() int {
+    return http.StatusNotFound
+}
+
+func (e *ObjectNotFoundError) Body() map[string]any {
+    return map[string]any{"error": e.Message, "code": CodeNotFound}
+}
@@ -0,0 +1,130 @@
+package errors
+
+import (
+    "fmt"
+    "io"
+    "log/slog"
+    "net/http"
+    "strings"
+
+    "github.com/labstack/echo/v4"
+    "github.com/rimba47prayoga/gorim.git/i18n"
+)
+
+// ExceptionHandlerFunc renders err as the response.
+type ExceptionHandlerFunc func(err error, c echo.Context) error
+
+// Debug adds type, message and stack trace of unexpected errors to 500 responses,
+// synced with debug of the settings. Never enable it in production.
+var Debug bool
+
+// ExceptionHandler renders every error of viewsets, middlewares and panics,
+// override it to customize error responses.
+var ExceptionHandler ExceptionHandlerFunc = DefaultExceptionHandler
+
+// Handle renders err with ExceptionHandler.
+func Handle(err error, c echo.Context) error {
+    if err == nil {
+        return nil
+    }
+    return ExceptionHandler(err, c)
+}
+
+// Resolve maps err to status code and body, unexpected errors are 500
+// without details since they may carry internal information.
+func Resolve(err error) (int, map[string]any) {
+    switch e := err.(type) {
+    case APIException:
+        return e.StatusCode(), e.Body()
+    case *echo.HTTPError:
+        return e.Code, map[string]any{"error": fmt.Sprint(e.Message), "code": StatusCode(e.Code)}
+    }
+    return http.StatusInternalServerError, map[string]any{"error": "Internal server error.", "code": CodeServerError}
+}
+
+// DefaultExceptionHandler writes the error as JSON unless response is already written.
+func DefaultExceptionHandler(err error, c echo.Context) error {
+    if c.Response().Committed {
+        return nil
+    }
+    status, body := Resolve(err)
+    if status >= http.StatusInternalServerError {
+        logInternalError(err, c)
+        Report(err, c)
+    }
+    setErrorHeaders(err, c)
+    if c.Request().Method == http.MethodHead {
+        return c.NoContent(status)
+    }
+    body = Localize(err, body, c)
+    if Debug && status >= http.StatusInternalServerError {
+        body["debug"] = debugInfo(err)
+    }
+    // same key as renderers.RendererContextKey, set when the renderer is negotiated.
+    if renderer, ok := c.Get("renderer").(bodyRenderer); ok {
+        c.Response().Header().Set(echo.HeaderContentType, renderer.MediaType())
+        c.Response().WriteHeader(status)
+        return renderer.Render(c.Response(), body)
+    }
+    return c.JSON(status, body)
+}
+
+// bodyRenderer is implemented by renderers.Renderer.
+type bodyRenderer interface {
+    MediaType() string
+    Render(w io.Writer, data any) error
+}
+
+// Localize translates messages of body to the language of the request,
+// errors with formatted message implement MessageFormat so the format is translated.
+func Localize(err error, body map[string]any, c echo.Context) map[string]any {
+    language := i18n.Language(c)
+    localized := make(map[string]any, len(body))
+    for key, value := range body {
+        switch value := value.(type) {
+        case string:
+            localized[key] = i18n.Translate(language, value)
+        case []string:
+            messages := make([]string, len(value))
+            for i, message := range value {
+                messages[i] = i18n.Translate(language, message)
+            }
+            localized[key] = messages
+        default:
+            localized[key] = value
+        }
+    }
+    if formatter, ok := err.(interface{ MessageFormat() (string, []any) }); ok {
+        format, args := formatter.MessageFormat()
+        localized["error"] = i18n.Translate(language, format, args...)
+    }
+    return localized
+}
+
+// debugInfo describes err for Debug responses, panics include the stack trace.
+func debugInfo(err error) map[string]any {
+    info := map[string]any{"type": fmt.Sprintf("%T", err), "message": err.Error()}
+    if panicErr, ok := err.(*PanicError); ok {
+        info["type"] = fmt.Sprintf("%T", panicErr.Value)
+        if len(panicErr.Stack) > 0 {
+            info["stack"] = strings.Split(strings.TrimSpace(string(panicErr.Stack)), "\n")
+        }
+    }
+    return info
+}
+
+// logInternalError logs unexpected errors, panics are logged with stack trace by RecoverMiddleware.
+func logInternalError(err error, c echo.Context) {
+    if _, ok := err.(*PanicError); ok {
+        return
+    }
+    slog.Error("internal server error", "method", c.Request().Method, "path", c.Request().URL.Path, "error", err.Error())
+}
+
+func setErrorHeaders(err error, c echo.Context) {
+    if headerer, ok := err.(interface{ Headers() map[string]string }); ok {
+        for name, value := range headerer.Headers() {
+            c.Response().Header().Set(name, value)
+        }
+    }
+}
@@ -0,0 +1,63 @@
+package errors
+
+import (
+    "net/http"
+
+    "github.com/labstack/echo/v4"
+    "github.com/rimba47prayoga/gorim.git/i18n"
+)
+
+const ProblemContentType = "application/problem+json"
+
+// ProblemTypeBaseURL prefixes the error code to build problem type URI,
+// example: "https://example.com/problems/" + "permission_denied", empty uses "about:blank".
+var ProblemTypeBaseURL = ""
+
+// Problem returns RFC 7807 Problem Details of err, the body of Resolve is kept as extensions,
+// "error" becomes detail and field errors are under "errors".
+func Problem(err error, c echo.Context) map[string]any {
+    status, body := Resolve(err)
+    body = Localize(err, body, c)
+    problem := map[string]any{
+        "type": "about:blank",
+        "title": i18n.T(c, http.StatusText(status)),
+        "status": status,
+        "instance": c.Request().URL.Path,
+    }
+    if _, ok := err.(ValidationErrors); ok {
+        body = map[string]any{"error": i18n.T(c, "Invalid input."), "code": CodeInvalid, "errors": body}
+    } else if _, ok := err.(*ValidationError); ok {
+        body = map[string]any{"error": i18n.T(c, "Invalid input."), "code": CodeInvalid, "errors": body}
+    }
+    for key, value := range body {
+        if key == "error" {
+            problem["detail"] = value
+        } else {
+            problem[key] = value
+        }
+    }
+    if code, ok := body["code"].(string); ok && code != "" && ProblemTypeBaseURL != "" {
+        problem["type"] = ProblemTypeBaseURL + code
+    }
+    return problem
+}
+
+// ProblemDetailsHandler renders errors as application/problem+json,
+// select it globally with errors.ExceptionHandler = errors.ProblemDetailsHandler.
+func ProblemDetailsHandler(err error, c echo.Context) error {
+    if c.Response().Committed {
+        return nil
+    }
+    problem := Problem(err, c)
+    status := problem["status"].(int)
+    if status >= http.StatusInternalServerError {
+        logInternalError(err, c)
+        Report(err, c)
+    }
+    setErrorHeaders(err, c)
+    if c.Request().Method == http.MethodHead {
+        return c.NoContent(status)
+    }
+    c.Response().Header().Set(echo.HeaderContentType, ProblemContentType)
+    return c.JSON(status, problem)
+}
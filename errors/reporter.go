@@ -0,0 +1,83 @@
+package errors
+
+import (
+    "log/slog"
+    "net/http"
+    "sync"
+
+    "github.com/labstack/echo/v4"
+)
+
+// ErrorEvent describes unhandled error or panic of a request.
+type ErrorEvent struct {
+    Err         error
+    // Stack is set for panics.
+    Stack       []byte
+    Request     *http.Request
+    // Route is the registered path, example: "/users/:pk".
+    Route       string
+    User        any
+    RequestID   string
+}
+
+// ErrorReporter sends unhandled errors to an error tracking service,
+// Report is called synchronously so it should not block.
+type ErrorReporter interface {
+    Report(event *ErrorEvent)
+}
+
+// ErrorReporterFunc is an adapter to use ordinary function as ErrorReporter.
+type ErrorReporterFunc func(event *ErrorEvent)
+
+func (f ErrorReporterFunc) Report(event *ErrorEvent) {
+    f(event)
+}
+
+var (
+    reportersMu sync.RWMutex
+    reporters   []ErrorReporter
+)
+
+// AddReporter registers reporter called for every error resulting in 5xx response.
+func AddReporter(reporter ErrorReporter) {
+    reportersMu.Lock()
+    defer reportersMu.Unlock()
+    reporters = append(reporters, reporter)
+}
+
+// Report sends err of the request to registered reporters,
+// it is called by the builtin exception handlers so custom handlers should call it too.
+func Report(err error, c echo.Context) {
+    reportersMu.RLock()
+    defer reportersMu.RUnlock()
+    if len(reporters) == 0 {
+        return
+    }
+    event := &ErrorEvent{
+        Err: err,
+        Request: c.Request(),
+        Route: c.Path(),
+        User: c.Get("user"),
+    }
+    if panicErr, ok := err.(*PanicError); ok {
+        event.Stack = panicErr.Stack
+    }
+    // same keys as gorim.UserContextKey and middlewares.RequestIDContextKey.
+    event.RequestID, _ = c.Get("request_id").(string)
+    if event.RequestID == "" {
+        event.RequestID = c.Request().Header.Get("X-Request-ID")
+    }
+    for _, reporter := range reporters {
+        reportSafely(reporter, event)
+    }
+}
+
+// reportSafely keeps panic of reporter from replacing the error response.
+func reportSafely(reporter ErrorReporter, event *ErrorEvent) {
+    defer func() {
+        if r := recover(); r != nil {
+            slog.Error("error reporter panicked", "panic", r)
+        }
+    }()
+    reporter.Report(event)
+}
@@ -1,5 +1,14 @@
 package errors
 
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// NonFieldErrors is the key of errors not belonging to a field.
+const NonFieldErrors = "non_field_errors"
+
 // ValidationError struct for custom validation errors
 type ValidationError struct {
 	Field   string `json:"field"`
@@ -9,3 +18,63 @@ type ValidationError struct {
 func (e *ValidationError) Error() string {
 	return e.Message
 }
+
+func (e *ValidationError) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+func (e *ValidationError) Body() map[string]any {
+	field := e.Field
+	if field == "" {
+		field = NonFieldErrors
+	}
+	return map[string]any{field: []string{e.Message}}
+}
+
+// ValidationErrors maps field path to its messages, nested fields are joined by dot,
+// example: {"email": ["This field is required."], "address.city": [...], "non_field_errors": [...]}
+type ValidationErrors map[string][]string
+
+func (e ValidationErrors) Add(field string, message string) {
+	if field == "" {
+		field = NonFieldErrors
+	}
+	e[field] = append(e[field], message)
+}
+
+// Merge adds errors of nested serializer under prefix.
+func (e ValidationErrors) Merge(prefix string, other ValidationErrors) {
+	for field, messages := range other {
+		if prefix != "" && field != NonFieldErrors {
+			field = prefix + "." + field
+		} else if prefix != "" {
+			field = prefix
+		}
+		e[field] = append(e[field], messages...)
+	}
+}
+
+func (e ValidationErrors) Error() string {
+	fields := make([]string, 0, len(e))
+	for field := range e {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	parts := make([]string, 0, len(fields))
+	for _, field := range fields {
+		parts = append(parts, field + ": " + strings.Join(e[field], " "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+func (e ValidationErrors) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+func (e ValidationErrors) Body() map[string]any {
+	body := make(map[string]any, len(e))
+	for field, messages := range e {
+		body[field] = messages
+	}
+	return body
+}
@@ -7,9 +7,10 @@ import (
 
 	"github.com/rimba47prayoga/gorim.git"
 	"github.com/rimba47prayoga/gorim.git/conf"
+	"github.com/rimba47prayoga/gorim.git/database"
+	"github.com/rimba47prayoga/gorim.git/health"
 	"github.com/rimba47prayoga/gorim.git/middlewares"
 	"gorm.io/driver/postgres"
-	"gorm.io/gorm"
 )
 
 // its just for flag to check if settings was configured.
@@ -24,26 +25,21 @@ var Server *gorim.Server
 var HOST string
 var PORT uint
 
-func SetupDatabase() *gorm.DB {
+func SetupDatabase() {
 	config := DATABASE
 	dsn := fmt.Sprintf(
 		"host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
 		config.Host, config.Port, config.User, config.Password, config.Name,
 	)
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
-	if err != nil {
-		panic(err)
-	}
-
-	sqlDB, err := db.DB()
-	if err != nil {
-		panic(err)
-	}
-
-	sqlDB.SetMaxIdleConns(10)
-	sqlDB.SetMaxOpenConns(50)
-	sqlDB.SetConnMaxLifetime(time.Hour)
-	return db
+	database.MustSetup(map[string]database.Config{
+		database.DefaultAlias: {
+			Dialector: postgres.Open,
+			DSN: dsn,
+			MaxIdleConns: 10,
+			MaxOpenConns: 50,
+			ConnMaxLifetime: time.Hour,
+		},
+	})
 }
 
 func SetupMiddlewares() {
@@ -64,11 +60,11 @@ func Configure() {
 	HOST = "localhost"
 	PORT = 8000
 	Server = gorim.New()
-	db := SetupDatabase()
+	SetupDatabase()
 	SetupMiddlewares()
+	Server.GET("/health", health.View)
 
 	// its for gorim settings.
-	conf.DB = db
 	conf.GorimServer = Server
 	conf.HOST = HOST
 	conf.PORT = PORT
@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/errors"
 	"github.com/rimba47prayoga/gorim.git/interfaces"
 	"github.com/rimba47prayoga/gorim.git/models"
 	"github.com/rimba47prayoga/gorim.git/permissions"
@@ -24,7 +25,6 @@ func NewUserViewSet() *UserViewSet {
 	viewset := UserViewSet{}
 	params := views.ModelViewSetParams[models.User]{
 		Serializer: &serializer,
-		PKField: "uuid",
 		Child: &viewset,
 	}
 	modelViewSet := views.NewModelViewSet(params)
@@ -66,9 +66,7 @@ func (h *UserViewSet) UpdateProfile(ctx gorim.Context) error {
 	profile := h.GetObject()
 	serializer := *h.GetSerializer()
 	if !serializer.IsValid() {
-		return ctx.JSON(http.StatusBadRequest, gorim.Response{
-			"error": serializer.GetErrors(),
-		})
+		return errors.Handle(serializer.GetErrors(), ctx)
 	}
 	data := serializer.Update(profile)
 	return ctx.JSON(http.StatusOK, data)
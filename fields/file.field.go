@@ -0,0 +1,83 @@
+package fields
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"github.com/rimba47prayoga/gorim.git/storages"
+)
+
+// File is the name of a file qualified by its storage, see storages.Qualify, stored as
+// string and rendered as its URL, serializers save uploaded files of fields tagged
+// upload_to into it, example:
+//
+//	type Product struct {
+//		Image	fields.File	`json:"image"`
+//	}
+//	type ProductSerializer struct {
+//		serializers.ModelSerializer[Product]
+//		Image	*parsers.UploadedFile	`json:"image" upload_to:"products"`
+//	}
+type File string
+
+// Name returns name of the file in its storage, example: "private://products/1.png" => "products/1.png".
+func (f File) Name() string {
+	_, name := storages.Resolve(string(f))
+	return name
+}
+
+// Storage returns storage of the file, the one of storage tag of the serializer field
+// it's uploaded by, nil when it's not registered anymore.
+func (f File) Storage() storages.Storage {
+	storage, _ := storages.Resolve(string(f))
+	return storage
+}
+
+// URL returns URL of the file in its storage, empty without file.
+func (f File) URL() string {
+	storage, name := storages.Resolve(string(f))
+	if f == "" || storage == nil {
+		return ""
+	}
+	return storage.URL(name)
+}
+
+// MarshalJSON renders URL of the file, null without file.
+func (f File) MarshalJSON() ([]byte, error) {
+	if f == "" {
+		return []byte("null"), nil
+	}
+	return json.Marshal(f.URL())
+}
+
+// UnmarshalJSON reads name of the file, example: fixtures.
+func (f *File) UnmarshalJSON(data []byte) error {
+	var name *string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+	*f = ""
+	if name != nil {
+		*f = File(*name)
+	}
+	return nil
+}
+
+func (f File) Value() (driver.Value, error) {
+	return string(f), nil
+}
+
+func (f *File) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*f = ""
+	case string:
+		*f = File(v)
+	case []byte:
+		*f = File(v)
+	default:
+		return fmt.Errorf("cannot convert %v to File", value)
+	}
+	return nil
+}
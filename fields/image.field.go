@@ -0,0 +1,61 @@
+package fields
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+
+	"github.com/rimba47prayoga/gorim.git/images"
+	"github.com/rimba47prayoga/gorim.git/storages"
+)
+
+// Image is File of an image, rendered with URLs of its variants registered for the
+// directory it's uploaded to by images.Register, example:
+// {"url": "/media/products/1.png", "variants": {"thumb": "/media/products/variants/thumb/1.jpg"}}
+type Image string
+
+func (i Image) Name() string {
+	return File(i).Name()
+}
+
+func (i Image) Storage() storages.Storage {
+	return File(i).Storage()
+}
+
+func (i Image) URL() string {
+	return File(i).URL()
+}
+
+// VariantURL returns URL of variant, empty when it's not registered.
+func (i Image) VariantURL(variant string) string {
+	if i == "" {
+		return ""
+	}
+	if v := images.Lookup(i.Name(), variant); v != nil {
+		return images.URL(i.Name(), v)
+	}
+	return ""
+}
+
+// MarshalJSON renders URLs of the image and its variants, null without image.
+func (i Image) MarshalJSON() ([]byte, error) {
+	if i == "" {
+		return []byte("null"), nil
+	}
+	return json.Marshal(map[string]any{
+		"url": i.URL(),
+		"variants": images.URLs(i.Name()),
+	})
+}
+
+// UnmarshalJSON reads name of the image, example: fixtures.
+func (i *Image) UnmarshalJSON(data []byte) error {
+	return (*File)(i).UnmarshalJSON(data)
+}
+
+func (i Image) Value() (driver.Value, error) {
+	return string(i), nil
+}
+
+func (i *Image) Scan(value interface{}) error {
+	return (*File)(i).Scan(value)
+}
@@ -101,6 +101,8 @@ func (fs FilterSet) ApplyFilters(filter interface{}, ctx echo.Context, db *gorm.
 			query = query.Where(dbName+" LIKE ?", "%"+fieldVal.String()+"%")
 		case "ilike":
 			query = query.Where(dbName+" ILIKE ?", "%"+fieldVal.String()+"%")
+		case "search":
+			query = applySearch(ctx, query, fieldType, fieldVal.String())
 		}
 	}
 
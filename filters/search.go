@@ -0,0 +1,59 @@
+package filters
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git/errors"
+	"github.com/rimba47prayoga/gorim.git/search"
+	"gorm.io/gorm"
+)
+
+// applySearch filters query by text of a field with operator "search", every word has
+// to match one of the columns of the db tag. The index tag delegates the search to the
+// index registered by search.Register, ordering rows by relevance, the columns are the
+// fallback when the backend fails, example:
+//
+//	Search	string	`query:"search" operator:"search" db:"name,description" index:"products"`
+func applySearch(ctx echo.Context, query *gorm.DB, field reflect.StructField, text string) *gorm.DB {
+	columns := []string{}
+	for _, column := range strings.Split(field.Tag.Get("db"), ",") {
+		if column = strings.TrimSpace(column); column != "" {
+			columns = append(columns, column)
+		}
+	}
+	if name := field.Tag.Get("index"); name != "" {
+		index := search.Lookup(name)
+		if index == nil {
+			errors.Raise(&errors.InternalServerError{
+				Message: fmt.Sprintf("search index %q is not registered", name),
+			})
+		}
+		requestContext := context.Background()
+		if ctx != nil {
+			requestContext = ctx.Request().Context()
+		}
+		filtered, err := index.Filter(requestContext, query, text)
+		if err == nil {
+			return filtered
+		}
+		if len(columns) == 0 {
+			errors.Raise(&errors.InternalServerError{Message: err.Error()})
+		}
+		slog.Warn("search backend failed, searching columns", "index", name, "error", err)
+	}
+	for _, term := range strings.Fields(strings.ToLower(text)) {
+		conditions := make([]string, 0, len(columns))
+		args := make([]interface{}, 0, len(columns))
+		for _, column := range columns {
+			conditions = append(conditions, "LOWER(" + column + ") LIKE ?")
+			args = append(args, "%" + term + "%")
+		}
+		query = query.Where(strings.Join(conditions, " OR "), args...)
+	}
+	return query
+}
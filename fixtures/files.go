@@ -0,0 +1,87 @@
+package fixtures
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+)
+
+// Format returns "yaml" for .yaml and .yml paths, "json" otherwise.
+func Format(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return "yaml"
+	}
+	return "json"
+}
+
+// Encode writes fixtures as "json" or "yaml".
+func Encode(w io.Writer, format string, fixtures []Fixture) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(fixtures)
+	case "yaml":
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		defer encoder.Close()
+		return encoder.Encode(fixtures)
+	}
+	return fmt.Errorf("unknown fixture format %s", format)
+}
+
+// Decode reads fixtures written by Encode.
+func Decode(r io.Reader, format string) ([]Fixture, error) {
+	fixtures := []Fixture{}
+	switch format {
+	case "json":
+		err := json.NewDecoder(r).Decode(&fixtures)
+		return fixtures, err
+	case "yaml":
+		err := yaml.NewDecoder(r).Decode(&fixtures)
+		if err == io.EOF {
+			err = nil
+		}
+		return fixtures, err
+	}
+	return nil, fmt.Errorf("unknown fixture format %s", format)
+}
+
+// LoadFiles loads fixture files in one transaction, format is guessed from the extension.
+func LoadFiles(db *gorm.DB, models []interface{}, paths ...string) (int, error) {
+	fixtures := []Fixture{}
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			return 0, err
+		}
+		decoded, err := Decode(file, Format(path))
+		file.Close()
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", path, err)
+		}
+		fixtures = append(fixtures, decoded...)
+	}
+	return len(fixtures), Load(db, models, fixtures)
+}
+
+// DumpFile writes rows of models into path, format is guessed from the extension.
+func DumpFile(db *gorm.DB, models []interface{}, path string, tables ...string) (int, error) {
+	fixtures, err := Dump(db, models, tables...)
+	if err != nil {
+		return 0, err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	return len(fixtures), Encode(file, Format(path), fixtures)
+}
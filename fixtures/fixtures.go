@@ -0,0 +1,195 @@
+package fixtures
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Fixture is a row of model keyed by table name, Fields are keyed by column names.
+type Fixture struct {
+	Model		string					`json:"model" yaml:"model"`
+	PK			interface{}				`json:"pk" yaml:"pk"`
+	Fields		map[string]interface{}	`json:"fields" yaml:"fields"`
+}
+
+var schemaCache = &sync.Map{}
+
+// parseModels returns schemas of models ordered so tables come after the ones they reference.
+func parseModels(db *gorm.DB, models []interface{}) ([]*schema.Schema, error) {
+	schemas := []*schema.Schema{}
+	byTable := map[string]*schema.Schema{}
+	for _, model := range models {
+		parsed, err := schema.Parse(model, schemaCache, db.NamingStrategy)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := byTable[parsed.Table]; ok {
+			continue
+		}
+		byTable[parsed.Table] = parsed
+		schemas = append(schemas, parsed)
+	}
+	ordered := []*schema.Schema{}
+	visited := map[string]bool{}
+	var visit func(parsed *schema.Schema)
+	visit = func(parsed *schema.Schema) {
+		if visited[parsed.Table] {
+			return
+		}
+		visited[parsed.Table] = true
+		for _, relation := range parsed.Relationships.BelongsTo {
+			if dependency, ok := byTable[relation.FieldSchema.Table]; ok {
+				visit(dependency)
+			}
+		}
+		ordered = append(ordered, parsed)
+	}
+	for _, parsed := range schemas {
+		visit(parsed)
+	}
+	return ordered, nil
+}
+
+// Dump returns rows of models as fixtures, tables limits them to the given table names.
+func Dump(db *gorm.DB, models []interface{}, tables ...string) ([]Fixture, error) {
+	schemas, err := parseModels(db, models)
+	if err != nil {
+		return nil, err
+	}
+	fixtures := []Fixture{}
+	for _, parsed := range schemas {
+		if len(tables) > 0 && !contains(tables, parsed.Table) {
+			continue
+		}
+		rows := reflect.New(reflect.SliceOf(parsed.ModelType))
+		queryset := db.Model(reflect.New(parsed.ModelType).Interface())
+		if parsed.PrioritizedPrimaryField != nil {
+			queryset = queryset.Order(parsed.PrioritizedPrimaryField.DBName)
+		}
+		if err := queryset.Find(rows.Interface()).Error; err != nil {
+			return nil, err
+		}
+		for i := 0; i < rows.Elem().Len(); i++ {
+			fixtures = append(fixtures, toFixture(db.Statement.Context, parsed, rows.Elem().Index(i)))
+		}
+	}
+	return fixtures, nil
+}
+
+func toFixture(ctx context.Context, parsed *schema.Schema, row reflect.Value) Fixture {
+	fixture := Fixture{Model: parsed.Table, Fields: map[string]interface{}{}}
+	for _, field := range parsed.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		value, zero := field.ValueOf(ctx, row)
+		if field == parsed.PrioritizedPrimaryField {
+			fixture.PK = value
+			continue
+		}
+		if zero && field.FieldType.Kind() == reflect.Ptr {
+			value = nil
+		}
+		// types such as gorm.DeletedAt are dumped as stored, so every format reads them back.
+		if valuer, ok := value.(driver.Valuer); ok && value != nil {
+			if stored, err := valuer.Value(); err == nil {
+				value = stored
+			}
+		}
+		fixture.Fields[field.DBName] = value
+	}
+	return fixture
+}
+
+// Load creates or updates rows of fixtures in a transaction, tables referenced by
+// foreign keys are loaded first. Hooks are skipped so rows are stored as dumped.
+func Load(db *gorm.DB, models []interface{}, fixtures []Fixture) error {
+	schemas, err := parseModels(db, models)
+	if err != nil {
+		return err
+	}
+	grouped := map[string][]Fixture{}
+	for _, fixture := range fixtures {
+		if !hasTable(schemas, fixture.Model) {
+			return fmt.Errorf("fixture model %s isn't registered", fixture.Model)
+		}
+		grouped[fixture.Model] = append(grouped[fixture.Model], fixture)
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		tx = tx.Session(&gorm.Session{SkipHooks: true})
+		for _, parsed := range schemas {
+			if len(grouped[parsed.Table]) == 0 {
+				continue
+			}
+			for i, fixture := range grouped[parsed.Table] {
+				instance, err := toInstance(tx.Statement.Context, parsed, fixture)
+				if err != nil {
+					return fmt.Errorf("%s #%d: %w", parsed.Table, i + 1, err)
+				}
+				if err := tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(instance).Error; err != nil {
+					return fmt.Errorf("%s #%d: %w", parsed.Table, i + 1, err)
+				}
+			}
+			if err := resetSequence(tx, parsed); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func toInstance(ctx context.Context, parsed *schema.Schema, fixture Fixture) (interface{}, error) {
+	instance := reflect.New(parsed.ModelType)
+	for column, value := range fixture.Fields {
+		field := parsed.LookUpField(column)
+		if field == nil || field.DBName == "" {
+			return nil, fmt.Errorf("unknown field %s", column)
+		}
+		if err := field.Set(ctx, instance.Elem(), value); err != nil {
+			return nil, fmt.Errorf("field %s: %w", column, err)
+		}
+	}
+	if fixture.PK != nil && parsed.PrioritizedPrimaryField != nil {
+		if err := parsed.PrioritizedPrimaryField.Set(ctx, instance.Elem(), fixture.PK); err != nil {
+			return nil, fmt.Errorf("pk: %w", err)
+		}
+	}
+	return instance.Interface(), nil
+}
+
+// resetSequence moves postgres sequence past the loaded keys, so later inserts don't collide.
+func resetSequence(db *gorm.DB, parsed *schema.Schema) error {
+	field := parsed.PrioritizedPrimaryField
+	if db.Dialector.Name() != "postgres" || field == nil || !field.AutoIncrement {
+		return nil
+	}
+	return db.Exec(
+		"SELECT setval(pg_get_serial_sequence(?, ?), COALESCE(MAX(" + db.Statement.Quote(field.DBName) + "), 1)) FROM " + db.Statement.Quote(parsed.Table),
+		parsed.Table, field.DBName,
+	).Error
+}
+
+func hasTable(schemas []*schema.Schema, table string) bool {
+	for _, parsed := range schemas {
+		if parsed.Table == table {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, item := range values {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
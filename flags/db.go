@@ -0,0 +1,68 @@
+package flags
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type GorimFeatureFlag struct {
+	Name		string		`gorm:"type:varchar(100);primarykey"`
+	Enabled		bool
+	Percentage	int
+	Users		[]uint		`gorm:"serializer:json;type:text"`
+}
+
+func (m GorimFeatureFlag) TableName() string {
+	return "gorim_feature_flags"
+}
+
+// DBBackend keeps flags in gorim_feature_flags table, add &flags.GorimFeatureFlag{} to
+// migration models.
+type DBBackend struct {
+	DB		*gorm.DB
+}
+
+func NewDBBackend(db *gorm.DB) *DBBackend {
+	return &DBBackend{DB: db}
+}
+
+func (b *DBBackend) Get(ctx context.Context, name string) (*Flag, error) {
+	var records []GorimFeatureFlag
+	if err := b.DB.WithContext(ctx).Where("name = ?", name).Limit(1).Find(&records).Error; err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	return records[0].flag(), nil
+}
+
+func (b *DBBackend) Set(ctx context.Context, flag *Flag) error {
+	return b.DB.WithContext(ctx).Save(&GorimFeatureFlag{
+		Name: flag.Name,
+		Enabled: flag.Enabled,
+		Percentage: flag.Percentage,
+		Users: flag.Users,
+	}).Error
+}
+
+func (b *DBBackend) Delete(ctx context.Context, name string) error {
+	return b.DB.WithContext(ctx).Delete(&GorimFeatureFlag{Name: name}).Error
+}
+
+func (b *DBBackend) List(ctx context.Context) ([]*Flag, error) {
+	var records []GorimFeatureFlag
+	if err := b.DB.WithContext(ctx).Order("name").Find(&records).Error; err != nil {
+		return nil, err
+	}
+	flags := make([]*Flag, 0, len(records))
+	for _, record := range records {
+		flags = append(flags, record.flag())
+	}
+	return flags, nil
+}
+
+func (m GorimFeatureFlag) flag() *Flag {
+	return &Flag{Name: m.Name, Enabled: m.Enabled, Percentage: m.Percentage, Users: m.Users}
+}
@@ -0,0 +1,74 @@
+package flags
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// EnvBackend reads flags from environment variables named by Prefix and the flag name
+// in upper case, values are comma separated: on, off, a percentage or user IDs,
+// example: FLAG_NEW_CHECKOUT=25%,1,42 is on for 25% of users and users 1 and 42.
+type EnvBackend struct {
+	// Prefix of variables, default "FLAG_".
+	Prefix	string
+}
+
+func (b *EnvBackend) GetPrefix() string {
+	if b.Prefix != "" {
+		return b.Prefix
+	}
+	return "FLAG_"
+}
+
+func (b *EnvBackend) Get(ctx context.Context, name string) (*Flag, error) {
+	value, ok := os.LookupEnv(b.GetPrefix() + envName(name))
+	if !ok {
+		return nil, nil
+	}
+	return ParseFlag(name, value)
+}
+
+// ParseFlag parses flag of EnvBackend format.
+func ParseFlag(name string, value string) (*Flag, error) {
+	flag := &Flag{Name: name}
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		switch strings.ToLower(part) {
+		case "", "0", "false", "off", "no":
+			continue
+		case "1", "true", "on", "yes":
+			flag.Enabled = true
+			continue
+		}
+		if percentage, ok := strings.CutSuffix(part, "%"); ok {
+			number, err := strconv.Atoi(percentage)
+			if err != nil || number < 0 || number > 100 {
+				return nil, fmt.Errorf("flags: invalid percentage %q of %s", part, name)
+			}
+			flag.Percentage = number
+			continue
+		}
+		userID, err := strconv.ParseUint(part, 10, 0)
+		if err != nil {
+			return nil, fmt.Errorf("flags: invalid value %q of %s", part, name)
+		}
+		flag.Users = append(flag.Users, uint(userID))
+	}
+	return flag, nil
+}
+
+// envName converts name to variable name, example: "new-checkout" is NEW_CHECKOUT.
+func envName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, name)
+}
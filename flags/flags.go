@@ -0,0 +1,120 @@
+package flags
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git/utils"
+)
+
+// Flag of a feature, off unless Enabled, listed in Users or in the Percentage of users
+// rolled out, example: {Name: "new_checkout", Percentage: 10, Users: []uint{1, 2}}.
+type Flag struct {
+	Name		string	`json:"name"`
+	// Enabled turns the feature on for everyone, anonymous requests included.
+	Enabled		bool	`json:"enabled"`
+	// Percentage of authenticated users the feature is on for, users keep their bucket
+	// as it grows, so rollouts only add users.
+	Percentage	int		`json:"percentage"`
+	// Users the feature is on for by user ID, example: testers.
+	Users		[]uint	`json:"users"`
+}
+
+// EnabledFor reports whether the feature is on for user, nil for anonymous requests.
+func (f *Flag) EnabledFor(user any) bool {
+	if f.Enabled {
+		return true
+	}
+	if user == nil {
+		return false
+	}
+	userID, ok := utils.GetUserID(user)
+	if !ok {
+		return false
+	}
+	if slices.Contains(f.Users, userID) {
+		return true
+	}
+	return f.Percentage > 0 && bucket(f.Name, userID) < f.Percentage
+}
+
+// bucket returns 0-99 of user for flag, flags bucket users independently.
+func bucket(name string, userID uint) int {
+	hash := fnv.New32a()
+	hash.Write([]byte(name + ":" + strconv.FormatUint(uint64(userID), 10)))
+	return int(hash.Sum32() % 100)
+}
+
+// Backend looks up flags, see EnvBackend, DBBackend and RedisBackend.
+type Backend interface {
+	// Get returns flag by name, nil when it isn't defined.
+	Get(ctx context.Context, name string) (*Flag, error)
+}
+
+// ErrReadOnly is returned by Store methods of cached backends which can't be changed.
+var ErrReadOnly = errors.New("flags: backend is read only")
+
+// Store is a backend whose flags are changed at runtime.
+type Store interface {
+	Backend
+	Set(ctx context.Context, flag *Flag) error
+	Delete(ctx context.Context, name string) error
+	List(ctx context.Context) ([]*Flag, error)
+}
+
+var (
+	mu		sync.RWMutex
+	backend	Backend = &EnvBackend{}
+)
+
+// SetBackend replaces the backend of Evaluate, default EnvBackend, example:
+// flags.SetBackend(flags.NewCachedBackend(flags.NewDBBackend(conf.DB), time.Minute))
+func SetBackend(b Backend) {
+	mu.Lock()
+	defer mu.Unlock()
+	backend = b
+}
+
+func GetBackend() Backend {
+	mu.RLock()
+	defer mu.RUnlock()
+	return backend
+}
+
+// Evaluate reports whether flag name is on for user, flags not defined are off.
+func Evaluate(ctx context.Context, name string, user any) (bool, error) {
+	flag, err := GetBackend().Get(ctx, name)
+	if err != nil || flag == nil {
+		return false, err
+	}
+	return flag.EnabledFor(user), nil
+}
+
+// Enabled is Evaluate treating errors of the backend as off.
+func Enabled(ctx context.Context, name string, user any) bool {
+	enabled, _ := Evaluate(ctx, name, user)
+	return enabled
+}
+
+const requestContextKey = "gorim:flags"
+
+// EnabledInRequest is Enabled remembered for the request, so a feature doesn't change
+// while it's served, used by gorim.Context.FeatureEnabled.
+func EnabledInRequest(c echo.Context, name string, user any) bool {
+	evaluated, ok := c.Get(requestContextKey).(map[string]bool)
+	if !ok {
+		evaluated = map[string]bool{}
+		c.Set(requestContextKey, evaluated)
+	}
+	if enabled, ok := evaluated[name]; ok {
+		return enabled
+	}
+	enabled := Enabled(c.Request().Context(), name, user)
+	evaluated[name] = enabled
+	return enabled
+}
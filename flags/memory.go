@@ -0,0 +1,136 @@
+package flags
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryBackend keeps flags in process memory, example: flags of tests.
+type MemoryBackend struct {
+	mu		sync.RWMutex
+	flags	map[string]Flag
+}
+
+func NewMemoryBackend(flags ...*Flag) *MemoryBackend {
+	b := &MemoryBackend{flags: map[string]Flag{}}
+	for _, flag := range flags {
+		b.flags[flag.Name] = *flag
+	}
+	return b
+}
+
+func (b *MemoryBackend) Get(ctx context.Context, name string) (*Flag, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	flag, ok := b.flags[name]
+	if !ok {
+		return nil, nil
+	}
+	return &flag, nil
+}
+
+func (b *MemoryBackend) Set(ctx context.Context, flag *Flag) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.flags[flag.Name] = *flag
+	return nil
+}
+
+func (b *MemoryBackend) Delete(ctx context.Context, name string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.flags, name)
+	return nil
+}
+
+func (b *MemoryBackend) List(ctx context.Context) ([]*Flag, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	flags := make([]*Flag, 0, len(b.flags))
+	for _, flag := range b.flags {
+		flag := flag
+		flags = append(flags, &flag)
+	}
+	sort.Slice(flags, func(i, j int) bool {
+		return flags[i].Name < flags[j].Name
+	})
+	return flags, nil
+}
+
+type cachedFlag struct {
+	flag		*Flag
+	expiresAt	time.Time
+}
+
+// CachedBackend keeps flags of Backend for TTL, so requests don't query the database or
+// redis for every flag. Changes through Set and Delete are seen at once by this process,
+// other processes see them after TTL.
+type CachedBackend struct {
+	Backend	Backend
+	TTL		time.Duration
+	mu		sync.Mutex
+	flags	map[string]cachedFlag
+}
+
+func NewCachedBackend(backend Backend, ttl time.Duration) *CachedBackend {
+	return &CachedBackend{Backend: backend, TTL: ttl, flags: map[string]cachedFlag{}}
+}
+
+func (b *CachedBackend) Get(ctx context.Context, name string) (*Flag, error) {
+	now := time.Now()
+	b.mu.Lock()
+	cached, ok := b.flags[name]
+	b.mu.Unlock()
+	if ok && now.Before(cached.expiresAt) {
+		return cached.flag, nil
+	}
+	flag, err := b.Backend.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	b.mu.Lock()
+	b.flags[name] = cachedFlag{flag: flag, expiresAt: now.Add(b.TTL)}
+	b.mu.Unlock()
+	return flag, nil
+}
+
+// Invalidate forgets cached flags, all of them without names.
+func (b *CachedBackend) Invalidate(names ...string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(names) == 0 {
+		b.flags = map[string]cachedFlag{}
+		return
+	}
+	for _, name := range names {
+		delete(b.flags, name)
+	}
+}
+
+func (b *CachedBackend) Set(ctx context.Context, flag *Flag) error {
+	store, ok := b.Backend.(Store)
+	if !ok {
+		return ErrReadOnly
+	}
+	defer b.Invalidate(flag.Name)
+	return store.Set(ctx, flag)
+}
+
+func (b *CachedBackend) Delete(ctx context.Context, name string) error {
+	store, ok := b.Backend.(Store)
+	if !ok {
+		return ErrReadOnly
+	}
+	defer b.Invalidate(name)
+	return store.Delete(ctx, name)
+}
+
+func (b *CachedBackend) List(ctx context.Context) ([]*Flag, error) {
+	store, ok := b.Backend.(Store)
+	if !ok {
+		return nil, ErrReadOnly
+	}
+	return store.List(ctx)
+}
@@ -0,0 +1,79 @@
+package flags
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisBackend keeps flags as JSON in a hash shared by processes.
+type RedisBackend struct {
+	Client	redis.UniversalClient
+	// Key of the hash, default "gorim:flags".
+	Key		string
+}
+
+func NewRedisBackend(client redis.UniversalClient) *RedisBackend {
+	return &RedisBackend{Client: client}
+}
+
+func (b *RedisBackend) GetKey() string {
+	if b.Key != "" {
+		return b.Key
+	}
+	return "gorim:flags"
+}
+
+func (b *RedisBackend) Get(ctx context.Context, name string) (*Flag, error) {
+	data, err := b.Client.HGet(ctx, b.GetKey(), name).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	flag := &Flag{}
+	if err := json.Unmarshal(data, flag); err != nil {
+		return nil, err
+	}
+	flag.Name = name
+	return flag, nil
+}
+
+func (b *RedisBackend) Set(ctx context.Context, flag *Flag) error {
+	data, err := json.Marshal(flag)
+	if err != nil {
+		return err
+	}
+	return b.Client.HSet(ctx, b.GetKey(), flag.Name, data).Err()
+}
+
+func (b *RedisBackend) Delete(ctx context.Context, name string) error {
+	return b.Client.HDel(ctx, b.GetKey(), name).Err()
+}
+
+func (b *RedisBackend) List(ctx context.Context) ([]*Flag, error) {
+	values, err := b.Client.HGetAll(ctx, b.GetKey()).Result()
+	if err != nil {
+		return nil, err
+	}
+	flags := make([]*Flag, 0, len(values))
+	for name, data := range values {
+		flag := &Flag{}
+		if err := json.Unmarshal([]byte(data), flag); err != nil {
+			return nil, err
+		}
+		flag.Name = name
+		flags = append(flags, flag)
+	}
+	sort.Slice(flags, func(i, j int) bool {
+		return flags[i].Name < flags[j].Name
+	})
+	return flags, nil
+}
+
+func (b *RedisBackend) Close() error {
+	return b.Client.Close()
+}
@@ -0,0 +1,107 @@
+package gorimtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// NewResponse wraps recorder of RequestFactory.Context to use the assertions.
+func NewResponse(recorder *httptest.ResponseRecorder) *Response {
+	return &Response{ResponseRecorder: recorder}
+}
+
+func (r *Response) describe() string {
+	if r.Request == nil {
+		return "response"
+	}
+	return r.Request.Method + " " + r.Request.URL.String()
+}
+
+// Select returns value at dot separated path of the json body, list items are selected
+// by index, example: "results.0.name". Empty path selects the whole body.
+func (r *Response) Select(path string) (any, error) {
+	var value any
+	if err := json.Unmarshal(r.Body.Bytes(), &value); err != nil {
+		return nil, fmt.Errorf("body is not json: %w", err)
+	}
+	if path == "" {
+		return value, nil
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch current := value.(type) {
+		case map[string]any:
+			item, ok := current[key]
+			if !ok {
+				return nil, fmt.Errorf("%s: key %q not found", path, key)
+			}
+			value = item
+		case []any:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(current) {
+				return nil, fmt.Errorf("%s: index %q out of range of %d items", path, key, len(current))
+			}
+			value = current[index]
+		default:
+			return nil, fmt.Errorf("%s: can't select %q of %T", path, key, value)
+		}
+	}
+	return value, nil
+}
+
+// AssertJSONEqual fails the test when value at path differs from expected,
+// expected is compared after json round trip so structs, maps and numbers of any type work.
+func (r *Response) AssertJSONEqual(t testing.TB, path string, expected any) {
+	t.Helper()
+	actual, err := r.Select(path)
+	if err != nil {
+		t.Fatalf("%s: %s: %s", r.describe(), err, r.Body.String())
+	}
+	data, err := json.Marshal(expected)
+	if err != nil {
+		t.Fatalf("%s: can't encode expected value: %s", r.describe(), err)
+	}
+	var normalized any
+	json.Unmarshal(data, &normalized)
+	if !reflect.DeepEqual(actual, normalized) {
+		actualData, _ := json.Marshal(actual)
+		t.Fatalf("%s: %q expected %s, got %s", r.describe(), path, data, actualData)
+	}
+}
+
+// AssertPaginated fails the test when the body isn't a page of pagination.Pagination
+// or total_rows differs from total.
+func (r *Response) AssertPaginated(t testing.TB, total int) {
+	t.Helper()
+	data := r.Data()
+	for _, key := range []string{"page", "page_size", "total_rows", "total_pages", "results"} {
+		if _, ok := data[key]; !ok {
+			t.Fatalf("%s: expected paginated response, %q is missing: %s", r.describe(), key, r.Body.String())
+		}
+	}
+	if _, ok := data["results"].([]any); !ok {
+		t.Fatalf("%s: expected results to be a list: %s", r.describe(), r.Body.String())
+	}
+	r.AssertJSONEqual(t, "total_rows", total)
+}
+
+// AssertError fails the test when status or "code" of the error body differs.
+func (r *Response) AssertError(t testing.TB, status int, code string) {
+	t.Helper()
+	r.AssertStatus(t, status)
+	r.AssertJSONEqual(t, "code", code)
+}
+
+// AssertFieldError fails the test when the validation error body has no messages for field,
+// nested fields are keyed by dot separated path like serializers key them.
+func (r *Response) AssertFieldError(t testing.TB, field string) {
+	t.Helper()
+	messages, ok := r.Data()[field].([]any)
+	if !ok || len(messages) == 0 {
+		t.Fatalf("%s: expected errors of %q: %s", r.describe(), field, r.Body.String())
+	}
+}
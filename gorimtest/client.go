@@ -0,0 +1,192 @@
+package gorimtest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/conf"
+	"gorm.io/gorm"
+)
+
+// forcedUserKey is request context key of the user set by ForceAuthenticate.
+type forcedUserKey struct{}
+
+var (
+	installedMu	sync.Mutex
+	installed	= map[*echo.Echo]bool{}
+)
+
+// forceAuthentication sets the forced user before routing, authentication.Chain
+// only replaces the user when credentials are present so forced requests stay authenticated.
+func forceAuthentication(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if user := c.Request().Context().Value(forcedUserKey{}); user != nil {
+			c.Set(gorim.UserContextKey, user)
+		}
+		return next(c)
+	}
+}
+
+// APIClient performs requests against the server in-process, example:
+//
+//	client := gorimtest.NewAPIClient(server)
+//	client.ForceAuthenticate(&user)
+//	response := client.Post("/api/items", map[string]any{"name": "Lamp"})
+//	response.AssertStatus(t, http.StatusCreated)
+type APIClient struct {
+	Server		*gorim.Server
+	// Headers are sent with every request.
+	Headers		http.Header
+	user		any
+	db			*gorm.DB
+}
+
+func NewAPIClient(server *gorim.Server) *APIClient {
+	installedMu.Lock()
+	if !installed[server.Echo] {
+		server.Echo.Pre(forceAuthentication)
+		installed[server.Echo] = true
+	}
+	installedMu.Unlock()
+	return &APIClient{Server: server, Headers: http.Header{}}
+}
+
+// ForceAuthenticate makes following requests authenticated as user without credentials, nil clears it.
+func (c *APIClient) ForceAuthenticate(user any) {
+	c.user = user
+}
+
+// UseDB makes requests run in db, usually the transaction returned by DBHarness.Begin.
+func (c *APIClient) UseDB(db *gorm.DB) *APIClient {
+	c.db = db
+	return c
+}
+
+// Credentials sets header sent with every request, example: client.Credentials("Authorization", "Bearer " + token)
+func (c *APIClient) Credentials(header string, value string) {
+	c.Headers.Set(header, value)
+}
+
+// Logout clears forced user and headers.
+func (c *APIClient) Logout() {
+	c.user = nil
+	c.Headers = http.Header{}
+}
+
+func (c *APIClient) Get(path string) *Response {
+	return c.Do(http.MethodGet, path, nil)
+}
+
+func (c *APIClient) Post(path string, body any) *Response {
+	return c.Do(http.MethodPost, path, body)
+}
+
+func (c *APIClient) Put(path string, body any) *Response {
+	return c.Do(http.MethodPut, path, body)
+}
+
+func (c *APIClient) Patch(path string, body any) *Response {
+	return c.Do(http.MethodPatch, path, body)
+}
+
+func (c *APIClient) Delete(path string) *Response {
+	return c.Do(http.MethodDelete, path, nil)
+}
+
+func (c *APIClient) Options(path string) *Response {
+	return c.Do(http.MethodOptions, path, nil)
+}
+
+// Do performs the request, body is sent as is when it's string, []byte or io.Reader,
+// as form when it's url.Values and as json otherwise.
+func (c *APIClient) Do(method string, path string, body any) *Response {
+	request := httptest.NewRequest(method, path, nil)
+	setBody(request, body)
+	for key, values := range c.Headers {
+		for _, value := range values {
+			request.Header.Add(key, value)
+		}
+	}
+	return c.Send(request)
+}
+
+// Send performs request built by the caller, forced user and headers still apply.
+func (c *APIClient) Send(request *http.Request) *Response {
+	if c.user != nil {
+		request = request.WithContext(context.WithValue(request.Context(), forcedUserKey{}, c.user))
+	}
+	if c.db != nil {
+		request = request.WithContext(conf.WithDB(request.Context(), c.db))
+	}
+	recorder := httptest.NewRecorder()
+	c.Server.Echo.ServeHTTP(recorder, request)
+	return &Response{ResponseRecorder: recorder, Request: request}
+}
+
+// setBody encodes body as described by APIClient.Do.
+func setBody(request *http.Request, body any) {
+	var reader io.Reader
+	switch body := body.(type) {
+	case nil:
+		return
+	case string:
+		reader = strings.NewReader(body)
+	case []byte:
+		reader = bytes.NewReader(body)
+	case io.Reader:
+		reader = body
+	case url.Values:
+		reader = strings.NewReader(body.Encode())
+		request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	default:
+		data, err := json.Marshal(body)
+		if err != nil {
+			panic(err)
+		}
+		reader = bytes.NewReader(data)
+		request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	}
+	request.Body = io.NopCloser(reader)
+	request.ContentLength = -1
+	if sized, ok := reader.(interface{ Len() int }); ok {
+		request.ContentLength = int64(sized.Len())
+	}
+}
+
+// Response is the recorded response with helpers to parse json.
+type Response struct {
+	*httptest.ResponseRecorder
+	Request		*http.Request
+}
+
+// JSON decodes the body into v.
+func (r *Response) JSON(v any) error {
+	return json.Unmarshal(r.Body.Bytes(), v)
+}
+
+// Data returns the decoded json object, nil when the body isn't an object.
+func (r *Response) Data() map[string]any {
+	data := map[string]any{}
+	if err := r.JSON(&data); err != nil {
+		return nil
+	}
+	return data
+}
+
+// AssertStatus fails the test with the body when status differs.
+func (r *Response) AssertStatus(t testing.TB, status int) {
+	t.Helper()
+	if r.Code != status {
+		t.Fatalf("%s: expected status %d, got %d: %s", r.describe(), status, r.Code, r.Body.String())
+	}
+}
@@ -0,0 +1,70 @@
+package gorimtest
+
+import (
+	"testing"
+
+	"github.com/rimba47prayoga/gorim.git/conf"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// DBHarness opens the connection once and hands every test its own transaction,
+// rolled back when the test ends, example:
+//
+//	var harness *gorimtest.DBHarness
+//
+//	func TestMain(m *testing.M) {
+//		harness = gorimtest.MustDBHarness(postgres.Open(dsn), &User{}, &Item{})
+//		os.Exit(m.Run())
+//	}
+//
+//	func TestCreateItem(t *testing.T) {
+//		t.Parallel()
+//		db := harness.Begin(t)
+//		client := gorimtest.NewAPIClient(server).UseDB(db)
+//		...
+//	}
+//
+// Parallel tests need a database allowing concurrent transactions, sqlite ":memory:"
+// opens a new database per connection so use a shared cache file there.
+type DBHarness struct {
+	DB		*gorm.DB
+}
+
+// NewDBHarness opens the connection, sets it as conf.DB and migrates models.
+func NewDBHarness(dialector gorm.Dialector, models ...any) (*DBHarness, error) {
+	db, err := gorm.Open(dialector, &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		return nil, err
+	}
+	if len(models) > 0 {
+		if err := db.AutoMigrate(models...); err != nil {
+			return nil, err
+		}
+	}
+	conf.DB = db
+	return &DBHarness{DB: db}, nil
+}
+
+// MustDBHarness is NewDBHarness panicking on error, for TestMain.
+func MustDBHarness(dialector gorm.Dialector, models ...any) *DBHarness {
+	harness, err := NewDBHarness(dialector, models...)
+	if err != nil {
+		panic(err)
+	}
+	return harness
+}
+
+// Begin starts transaction of the test, rolled back by t.Cleanup.
+// Requests of APIClient.UseDB and RequestFactory.DB run in it.
+func (h *DBHarness) Begin(t testing.TB) *gorm.DB {
+	t.Helper()
+	tx := h.DB.Begin()
+	if tx.Error != nil {
+		t.Fatalf("begin transaction: %s", tx.Error)
+	}
+	t.Cleanup(func() {
+		tx.Rollback()
+	})
+	return tx
+}
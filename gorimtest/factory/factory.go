@@ -0,0 +1,172 @@
+package factory
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var sequence atomic.Int64
+
+// Next returns the next number of the sequence shared by generated values.
+func Next() int64 {
+	return sequence.Add(1)
+}
+
+// Option overrides a field of the built instance.
+type Option func(instance reflect.Value, n int64)
+
+// With sets field to value, the value is converted to the field type when possible,
+// example: factory.Create[User](db, factory.With("Email", "a@b.c"))
+func With(field string, value any) Option {
+	return func(instance reflect.Value, n int64) {
+		setField(instance, field, reflect.ValueOf(value))
+	}
+}
+
+// Sequence sets field to value returned for the sequence number,
+// example: factory.Sequence("Username", func(n int64) any { return fmt.Sprintf("user%d", n) })
+func Sequence(field string, value func(n int64) any) Option {
+	return func(instance reflect.Value, n int64) {
+		setField(instance, field, reflect.ValueOf(value(n)))
+	}
+}
+
+// Build returns instance with generated values for empty fields, it's not saved.
+func Build[T any](options ...Option) *T {
+	instance := new(T)
+	value := reflect.ValueOf(instance).Elem()
+	n := Next()
+	fill(value, n)
+	for _, option := range options {
+		option(value, n)
+	}
+	return instance
+}
+
+// BuildBatch returns count instances built by Build.
+func BuildBatch[T any](count int, options ...Option) []*T {
+	instances := make([]*T, count)
+	for i := range instances {
+		instances[i] = Build[T](options...)
+	}
+	return instances
+}
+
+// Create builds the instance and saves it, panics when it can't be saved.
+func Create[T any](db *gorm.DB, options ...Option) *T {
+	instance := Build[T](options...)
+	if err := db.Create(instance).Error; err != nil {
+		panic(fmt.Sprintf("factory: create %T: %s", instance, err))
+	}
+	return instance
+}
+
+// CreateBatch returns count instances saved by Create.
+func CreateBatch[T any](db *gorm.DB, count int, options ...Option) []*T {
+	instances := make([]*T, count)
+	for i := range instances {
+		instances[i] = Create[T](db, options...)
+	}
+	return instances
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// fill generates values of exported scalar fields, primary key, gorm managed
+// and relation fields are left for gorm.
+func fill(value reflect.Value, n int64) {
+	typ := value.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		fieldValue := value.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			fill(fieldValue, n)
+			continue
+		}
+		if !field.IsExported() || !fieldValue.IsZero() || skipField(field) {
+			continue
+		}
+		switch field.Type.Kind() {
+		case reflect.String:
+			fieldValue.SetString(generateString(field.Name, n))
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			fieldValue.SetInt(n % 100 + 1)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			fieldValue.SetUint(uint64(n % 100 + 1))
+		case reflect.Float32, reflect.Float64:
+			fieldValue.SetFloat(float64(n % 100 + 1))
+		case reflect.Struct:
+			if field.Type == timeType {
+				fieldValue.Set(reflect.ValueOf(time.Now()))
+			}
+		}
+	}
+}
+
+func skipField(field reflect.StructField) bool {
+	tag := strings.ToLower(field.Tag.Get("gorm"))
+	if tag == "-" || strings.Contains(tag, "primarykey") || strings.Contains(tag, "default:") ||
+		strings.Contains(tag, "foreignkey") || strings.Contains(tag, "autocreatetime") || strings.Contains(tag, "autoupdatetime") {
+		return true
+	}
+	switch field.Name {
+	case "ID", "CreatedAt", "UpdatedAt", "DeletedAt":
+		return true
+	}
+	// foreign keys, example: UserID.
+	return strings.HasSuffix(field.Name, "ID") && field.Type.Kind() != reflect.String
+}
+
+// generateString returns value fitting field name, example: Email => "user12@example.com".
+func generateString(name string, n int64) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.Contains(lower, "email"):
+		return fmt.Sprintf("user%d@example.com", n)
+	case strings.Contains(lower, "url") || strings.Contains(lower, "website"):
+		return fmt.Sprintf("https://example.com/%d", n)
+	case strings.Contains(lower, "phone"):
+		return fmt.Sprintf("+1555%07d", n)
+	}
+	return fmt.Sprintf("%s %d", name, n)
+}
+
+func setField(instance reflect.Value, name string, value reflect.Value) {
+	field := instance.FieldByName(name)
+	if !field.IsValid() || !field.CanSet() {
+		panic(fmt.Sprintf("factory: %s has no field %s", instance.Type(), name))
+	}
+	if !value.IsValid() {
+		field.Set(reflect.Zero(field.Type()))
+		return
+	}
+	if value.Type().AssignableTo(field.Type()) {
+		field.Set(value)
+		return
+	}
+	// pointer fields accept the value they point to.
+	if field.Kind() == reflect.Ptr && convertible(value.Type(), field.Type().Elem()) {
+		pointer := reflect.New(field.Type().Elem())
+		pointer.Elem().Set(value.Convert(field.Type().Elem()))
+		field.Set(pointer)
+		return
+	}
+	if convertible(value.Type(), field.Type()) {
+		field.Set(value.Convert(field.Type()))
+		return
+	}
+	panic(fmt.Sprintf("factory: can't set %s.%s of %s to %s", instance.Type(), name, field.Type(), value.Type()))
+}
+
+// convertible is reflect ConvertibleTo without integer to string conversion, which yields a rune.
+func convertible(from reflect.Type, to reflect.Type) bool {
+	if to.Kind() == reflect.String && from.Kind() != reflect.String {
+		return false
+	}
+	return from.ConvertibleTo(to)
+}
@@ -0,0 +1,130 @@
+package gorimtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+const maskedValue = "<masked>"
+
+// UpdateSnapshots rewrites golden files instead of comparing them,
+// example: GORIM_UPDATE_SNAPSHOTS=1 go test ./...
+var UpdateSnapshots = os.Getenv("GORIM_UPDATE_SNAPSHOTS") != ""
+
+// SnapshotDir is where golden files are stored, relative to the package of the test.
+var SnapshotDir = filepath.Join("testdata", "snapshots")
+
+// MaskedFields are masked wherever they appear in the body, string values parsed as
+// RFC 3339 timestamps are masked too so snapshots don't change between runs.
+var MaskedFields = []string{"created_at", "updated_at", "deleted_at", "CreatedAt", "UpdatedAt", "DeletedAt"}
+
+var unsafeFileName = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// AssertSnapshot compares status and body with golden file named after the test and name,
+// the file is written when missing or UpdateSnapshots is set. Json bodies are stored
+// indented with sorted keys, masks are fields masked in addition to MaskedFields.
+func (r *Response) AssertSnapshot(t testing.TB, name string, masks ...string) {
+	t.Helper()
+	fileName := t.Name()
+	if name != "" {
+		fileName += "_" + name
+	}
+	path := filepath.Join(SnapshotDir, unsafeFileName.ReplaceAllString(fileName, "_") + ".snap")
+	actual := r.snapshot(append(append([]string{}, MaskedFields...), masks...))
+
+	expected, err := os.ReadFile(path)
+	if os.IsNotExist(err) || UpdateSnapshots {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("snapshot %s: %s", path, err)
+		}
+		if err := os.WriteFile(path, actual, 0644); err != nil {
+			t.Fatalf("snapshot %s: %s", path, err)
+		}
+		t.Logf("snapshot %s written", path)
+		return
+	}
+	if err != nil {
+		t.Fatalf("snapshot %s: %s", path, err)
+	}
+	if !bytes.Equal(expected, actual) {
+		t.Fatalf("%s: snapshot %s differs, rerun with GORIM_UPDATE_SNAPSHOTS=1 if it's intended:\n%s",
+			r.describe(), path, diffLines(string(expected), string(actual)))
+	}
+}
+
+// snapshot returns status line followed by normalized body.
+func (r *Response) snapshot(masks []string) []byte {
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, "%d\n", r.Code)
+	var body any
+	if err := json.Unmarshal(r.Body.Bytes(), &body); err != nil {
+		buffer.Write(r.Body.Bytes())
+		return buffer.Bytes()
+	}
+	// encoding/json sorts map keys, so the order is stable.
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(mask(body, masks))
+	return buffer.Bytes()
+}
+
+func mask(value any, masks []string) any {
+	switch value := value.(type) {
+	case map[string]any:
+		for key, item := range value {
+			if containsString(masks, key) && item != nil {
+				value[key] = maskedValue
+				continue
+			}
+			value[key] = mask(item, masks)
+		}
+	case []any:
+		for i, item := range value {
+			value[i] = mask(item, masks)
+		}
+	case string:
+		if _, err := time.Parse(time.RFC3339Nano, value); err == nil {
+			return maskedValue
+		}
+	}
+	return value
+}
+
+func containsString(values []string, value string) bool {
+	for _, item := range values {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// diffLines returns expected and actual lines around the first difference.
+func diffLines(expected string, actual string) string {
+	expectedLines := strings.Split(expected, "\n")
+	actualLines := strings.Split(actual, "\n")
+	first := 0
+	for first < len(expectedLines) && first < len(actualLines) && expectedLines[first] == actualLines[first] {
+		first++
+	}
+	var builder strings.Builder
+	start := max(first - 3, 0)
+	for i := start; i < first; i++ {
+		fmt.Fprintf(&builder, "  %s\n", expectedLines[i])
+	}
+	for i := first; i < min(first + 5, len(expectedLines)); i++ {
+		fmt.Fprintf(&builder, "- %s\n", expectedLines[i])
+	}
+	for i := first; i < min(first + 5, len(actualLines)); i++ {
+		fmt.Fprintf(&builder, "+ %s\n", actualLines[i])
+	}
+	return builder.String()
+}
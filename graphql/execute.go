@@ -0,0 +1,479 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/rimba47prayoga/gorim.git/errors"
+)
+
+// Error is an error of the response, Extensions of errors returned by routes hold
+// their status and code, and errors of the fields for validation errors.
+type Error struct {
+	Message		string			`json:"message"`
+	Locations	[]Location		`json:"locations,omitempty"`
+	Path		[]any			`json:"path,omitempty"`
+	Extensions	map[string]any	`json:"extensions,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Response is the body of GraphQL responses, Data is absent when the request failed
+// before execution.
+type Response struct {
+	Data	any			`json:"data,omitempty"`
+	Errors	[]*Error	`json:"errors,omitempty"`
+}
+
+// thunk is a value evaluated when it's selected.
+type thunk func() any
+
+// resolver is a field value computed from the arguments of the field.
+type resolver func(args map[string]any) (any, error)
+
+// dispatcher resolves fields having routes.
+type dispatcher func(field *fieldDef, args map[string]any) (any, error)
+
+type executor struct {
+	ts			*typeSystem
+	doc			*document
+	variables	map[string]any
+	maxDepth	int
+	maxFields	int
+	// fields counts fields selected by the operation once fragments are spread.
+	fields		int
+	dispatch	dispatcher
+	errors		[]*Error
+}
+
+// request is what execute needs of GraphQL requests.
+type request struct {
+	query			string
+	operationName	string
+	variables		map[string]any
+	// readOnly refuses mutations, GET requests are read only.
+	readOnly		bool
+}
+
+func execute(ts *typeSystem, req request, maxDepth int, maxFields int, dispatch dispatcher) *Response {
+	doc, err := parse(req.query)
+	if err != nil {
+		syntax := err.(*syntaxError)
+		return &Response{Errors: []*Error{{Message: syntax.Error(), Locations: []Location{syntax.location}}}}
+	}
+	e := &executor{ts: ts, doc: doc, maxDepth: maxDepth, maxFields: maxFields, dispatch: dispatch}
+	op, root := e.operation(req)
+	if op == nil {
+		return &Response{Errors: e.errors}
+	}
+	e.validateOperation(op, root)
+	if len(e.errors) > 0 {
+		return &Response{Errors: e.errors}
+	}
+	if !e.coerceVariables(op, req.variables) {
+		return &Response{Errors: e.errors}
+	}
+	data := e.executeSelections(root, map[string]any{}, op.selections, nil)
+	return &Response{Data: data, Errors: e.errors}
+}
+
+// operation returns the operation to execute and its root type.
+func (e *executor) operation(req request) (*operation, *typeDef) {
+	var op *operation
+	for _, candidate := range e.doc.operations {
+		if req.operationName == "" || candidate.name == req.operationName {
+			if op != nil {
+				e.fail("Must provide operation name if query contains multiple operations.", nil, nil)
+				return nil, nil
+			}
+			op = candidate
+		}
+	}
+	if op == nil {
+		e.fail(fmt.Sprintf("Unknown operation named %q.", req.operationName), nil, nil)
+		return nil, nil
+	}
+	location := []Location{op.location}
+	switch op.kind {
+	case "subscription":
+		e.fail("Subscriptions aren't supported, stream changes with the watch action of viewsets.", location, nil)
+	case "mutation":
+		if e.ts.mutation == nil {
+			e.fail("Schema is not configured for mutations.", location, nil)
+		} else if req.readOnly {
+			e.fail("Can only perform a mutation operation from a POST request.", location, map[string]any{"status": http.StatusMethodNotAllowed, "code": errors.CodeMethodNotAllowed})
+		} else {
+			return op, e.ts.mutation
+		}
+	default:
+		return op, e.ts.query
+	}
+	return nil, nil
+}
+
+func (e *executor) fail(message string, locations []Location, extensions map[string]any) {
+	e.errors = append(e.errors, &Error{Message: message, Locations: locations, Extensions: extensions})
+}
+
+func (e *executor) validateOperation(op *operation, root *typeDef) {
+	defined := map[string]bool{}
+	for _, definition := range op.variables {
+		if defined[definition.name] {
+			e.fail(fmt.Sprintf("There can be only one variable named \"$%s\".", definition.name), []Location{op.location}, nil)
+		}
+		defined[definition.name] = true
+		if def, ok := e.ts.types[definition.typ.namedType()]; !ok || (def.kind != "SCALAR" && def.kind != "ENUM" && def.kind != "INPUT_OBJECT") {
+			e.fail(fmt.Sprintf("Variable \"$%s\" cannot be non-input type %q.", definition.name, definition.typ), []Location{op.location}, nil)
+		}
+	}
+	e.validateSelections(root, op.selections, defined, 1, map[string]bool{})
+}
+
+// validateSelections checks fields, arguments, variables and fragments against the
+// types, fragments are checked where they're spread so their fields count for each spread.
+func (e *executor) validateSelections(def *typeDef, selections []*selection, defined map[string]bool, depth int, spreading map[string]bool) {
+	for _, field := range selections {
+		if e.fields > e.maxFields {
+			return
+		}
+		location := []Location{field.location}
+		for name, args := range field.directives {
+			if name != "skip" && name != "include" {
+				e.fail(fmt.Sprintf("Unknown directive \"@%s\".", name), location, nil)
+			}
+			e.validateVariables(args, defined, location)
+		}
+		if field.spread != "" || field.inline {
+			typeCondition, subselections := field.typeCondition, field.selections
+			if field.spread != "" {
+				definition, ok := e.doc.fragments[field.spread]
+				if !ok {
+					e.fail(fmt.Sprintf("Unknown fragment %q.", field.spread), location, nil)
+					continue
+				}
+				if spreading[field.spread] {
+					e.fail(fmt.Sprintf("Cannot spread fragment %q within itself.", field.spread), location, nil)
+					continue
+				}
+				typeCondition, subselections = definition.typeCondition, definition.selections
+			}
+			if typeCondition != "" && typeCondition != def.name {
+				if _, ok := e.ts.types[typeCondition]; !ok {
+					e.fail(fmt.Sprintf("Unknown type %q.", typeCondition), location, nil)
+				} else {
+					e.fail(fmt.Sprintf("Fragment cannot be spread here as objects of type %q can never be of type %q.", def.name, typeCondition), location, nil)
+				}
+				continue
+			}
+			if field.spread != "" {
+				spreading[field.spread] = true
+				e.validateSelections(def, subselections, defined, depth, spreading)
+				delete(spreading, field.spread)
+			} else {
+				e.validateSelections(def, subselections, defined, depth, spreading)
+			}
+			continue
+		}
+		if depth > e.maxDepth {
+			e.fail(fmt.Sprintf("Query is nested deeper than %d levels.", e.maxDepth), location, nil)
+			return
+		}
+		e.fields++
+		if e.fields > e.maxFields {
+			e.fail(fmt.Sprintf("Query selects more than %d fields.", e.maxFields), location, nil)
+			return
+		}
+		fieldDef := e.fieldDef(def, field.name)
+		if fieldDef == nil {
+			e.fail(fmt.Sprintf("Cannot query field %q on type %q.", field.name, def.name), location, nil)
+			continue
+		}
+		for name, value := range field.arguments {
+			if fieldDef.arg(name) == nil {
+				e.fail(fmt.Sprintf("Unknown argument %q on field %q.", name, def.name + "." + field.name), location, nil)
+			}
+			e.validateVariables(value, defined, location)
+		}
+		for _, arg := range fieldDef.args {
+			if _, ok := field.arguments[arg.name]; !ok && arg.typ.kind == "NON_NULL" && arg.defaultValue == "" {
+				e.fail(fmt.Sprintf("Field %q argument %q of type %q is required, but it was not provided.", field.name, arg.name, arg.typ), location, nil)
+			}
+		}
+		fieldType := e.ts.types[fieldDef.typ.namedType()]
+		if fieldType.kind == "OBJECT" {
+			if len(field.selections) == 0 {
+				e.fail(fmt.Sprintf("Field %q of type %q must have a selection of subfields.", field.name, fieldDef.typ), location, nil)
+				continue
+			}
+			e.validateSelections(fieldType, field.selections, defined, depth + 1, spreading)
+		} else if len(field.selections) > 0 {
+			e.fail(fmt.Sprintf("Field %q must not have a selection since type %q has no subfields.", field.name, fieldDef.typ), location, nil)
+		}
+	}
+}
+
+func (e *executor) validateVariables(value any, defined map[string]bool, location []Location) {
+	switch value := value.(type) {
+	case variable:
+		if !defined[string(value)] {
+			e.fail(fmt.Sprintf("Variable \"$%s\" is not defined.", value), location, nil)
+		}
+	case []any:
+		for _, item := range value {
+			e.validateVariables(item, defined, location)
+		}
+	case map[string]any:
+		for _, item := range value {
+			e.validateVariables(item, defined, location)
+		}
+	}
+}
+
+// fieldDef returns field of def, including meta fields.
+func (e *executor) fieldDef(def *typeDef, name string) *fieldDef {
+	switch {
+	case name == typenameField.name:
+		return typenameField
+	case def == e.ts.query && name == schemaField.name:
+		return schemaField
+	case def == e.ts.query && name == typeField.name:
+		return typeField
+	}
+	return def.field(name)
+}
+
+// coerceVariables applies defaults of variables and checks required ones are provided.
+func (e *executor) coerceVariables(op *operation, provided map[string]any) bool {
+	e.variables = map[string]any{}
+	for _, definition := range op.variables {
+		value, ok := provided[definition.name]
+		if !ok && definition.hasDefault {
+			value, ok = e.resolve(definition.defaultValue), true
+		}
+		if definition.typ.kind == "NON_NULL" && value == nil {
+			e.fail(fmt.Sprintf("Variable \"$%s\" of required type %q was not provided.", definition.name, definition.typ), []Location{op.location}, nil)
+			continue
+		}
+		if ok {
+			e.variables[definition.name] = value
+		}
+	}
+	return len(e.errors) == 0
+}
+
+// resolve replaces variables and enum values of value.
+func (e *executor) resolve(value any) any {
+	switch value := value.(type) {
+	case variable:
+		return e.variables[string(value)]
+	case enumValue:
+		return string(value)
+	case []any:
+		list := make([]any, len(value))
+		for i, item := range value {
+			list[i] = e.resolve(item)
+		}
+		return list
+	case map[string]any:
+		object := make(map[string]any, len(value))
+		for name, item := range value {
+			object[name] = e.resolve(item)
+		}
+		return object
+	}
+	return value
+}
+
+// arguments returns arguments of field, absent ones take their default.
+func (e *executor) arguments(def *fieldDef, field *selection) (map[string]any, error) {
+	args := map[string]any{}
+	for _, arg := range def.args {
+		value, ok := field.arguments[arg.name]
+		if name, isVariable := value.(variable); isVariable {
+			_, ok = e.variables[string(name)]
+		}
+		if ok {
+			args[arg.name] = e.resolve(value)
+		} else if arg.defaultValue != "" {
+			args[arg.name] = e.resolve(newParser(arg.defaultValue).value(true))
+		}
+		if arg.typ.kind == "NON_NULL" && args[arg.name] == nil {
+			return nil, fmt.Errorf("Argument %q of required type %q was not provided.", arg.name, arg.typ)
+		}
+	}
+	return args, nil
+}
+
+func (e *executor) included(directives map[string]map[string]any) bool {
+	if args, ok := directives["skip"]; ok && e.resolve(args["if"]) == true {
+		return false
+	}
+	if args, ok := directives["include"]; ok && e.resolve(args["if"]) != true {
+		return false
+	}
+	return true
+}
+
+// collectFields returns fields of selections by response key in order, fragments are
+// flattened and fields of the same key merged.
+func (e *executor) collectFields(selections []*selection, keys []string, fields map[string][]*selection) ([]string, map[string][]*selection) {
+	for _, field := range selections {
+		if !e.included(field.directives) {
+			continue
+		}
+		switch {
+		case field.spread != "":
+			keys, fields = e.collectFields(e.doc.fragments[field.spread].selections, keys, fields)
+		case field.inline:
+			keys, fields = e.collectFields(field.selections, keys, fields)
+		default:
+			key := field.responseKey()
+			if _, ok := fields[key]; !ok {
+				keys = append(keys, key)
+			}
+			fields[key] = append(fields[key], field)
+		}
+	}
+	return keys, fields
+}
+
+func (e *executor) executeSelections(def *typeDef, object map[string]any, selections []*selection, path []any) *orderedMap {
+	keys, fields := e.collectFields(selections, nil, map[string][]*selection{})
+	result := &orderedMap{values: map[string]any{}}
+	for _, key := range keys {
+		field := fields[key][0]
+		fieldPath := append(append([]any{}, path...), key)
+		fieldDef := e.fieldDef(def, field.name)
+		var subselections []*selection
+		for _, merged := range fields[key] {
+			subselections = append(subselections, merged.selections...)
+		}
+		value, err := e.resolveField(def, object, fieldDef, field)
+		if err != nil {
+			e.fieldError(err, field, fieldPath)
+			result.set(key, nil)
+			continue
+		}
+		result.set(key, e.complete(fieldDef.typ, value, subselections, field, fieldPath))
+	}
+	return result
+}
+
+func (e *executor) resolveField(def *typeDef, object map[string]any, fieldDef *fieldDef, field *selection) (any, error) {
+	args, err := e.arguments(fieldDef, field)
+	if err != nil {
+		return nil, err
+	}
+	switch fieldDef {
+	case typenameField:
+		return def.name, nil
+	case schemaField:
+		return e.ts.schemaValue(), nil
+	case typeField:
+		if _, ok := e.ts.types[fmt.Sprint(args["name"])]; !ok {
+			return nil, nil
+		}
+		return e.ts.typeValue(named(fmt.Sprint(args["name"]))), nil
+	}
+	if fieldDef.route != nil {
+		return e.dispatch(fieldDef, args)
+	}
+	value := object[field.name]
+	if resolve, ok := value.(resolver); ok {
+		return resolve(args)
+	}
+	return value, nil
+}
+
+func (e *executor) fieldError(err error, field *selection, path []any) {
+	graphqlError, ok := err.(*Error)
+	if !ok {
+		graphqlError = &Error{Message: err.Error()}
+	}
+	located := *graphqlError
+	located.Locations = []Location{field.location}
+	located.Path = path
+	e.errors = append(e.errors, &located)
+}
+
+// complete shapes value as typ, objects keep the selected fields only.
+func (e *executor) complete(typ *typeRef, value any, selections []*selection, field *selection, path []any) any {
+	if evaluate, ok := value.(thunk); ok {
+		value = evaluate()
+	}
+	switch typ.kind {
+	case "NON_NULL":
+		completed := e.complete(typ.of, value, selections, field, path)
+		if completed == nil {
+			e.fieldError(fmt.Errorf("Cannot return null for non-nullable field %q.", field.name), field, path)
+		}
+		return completed
+	case "LIST":
+		if value == nil {
+			return nil
+		}
+		items, ok := value.([]any)
+		if !ok {
+			items = []any{value}
+		}
+		list := make([]any, len(items))
+		for i, item := range items {
+			list[i] = e.complete(typ.of, item, selections, field, append(append([]any{}, path...), i))
+		}
+		return list
+	}
+	if value == nil {
+		return nil
+	}
+	def := e.ts.types[typ.name]
+	if def.kind != "OBJECT" {
+		return value
+	}
+	if items, ok := value.([]any); ok {
+		// routes answering lists where objects are documented.
+		return e.complete(listOf(typ), items, selections, field, path)
+	}
+	object, ok := value.(map[string]any)
+	if !ok {
+		e.fieldError(fmt.Errorf("Expected object of type %q.", def.name), field, path)
+		return nil
+	}
+	return e.executeSelections(def, object, selections, path)
+}
+
+// orderedMap renders fields in the order they're selected.
+type orderedMap struct {
+	keys	[]string
+	values	map[string]any
+}
+
+func (m *orderedMap) set(key string, value any) {
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		encodedKey, _ := json.Marshal(key)
+		buffer.Write(encodedKey)
+		buffer.WriteByte(':')
+		encoded, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buffer.Write(encoded)
+	}
+	buffer.WriteByte('}')
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,265 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/errors"
+	"github.com/rimba47prayoga/gorim.git/interfaces"
+	"github.com/rimba47prayoga/gorim.git/permissions"
+	"github.com/rimba47prayoga/gorim.git/routers"
+	"github.com/rimba47prayoga/gorim.git/schema"
+)
+
+// Endpoint serves GraphQL generated from the viewsets registered by routers, example:
+//
+//	endpoint := &graphql.Endpoint{Prefix: "/api/v1"}
+//	endpoint.Register(api, "/graphql")
+//
+// Types are the models and serializers of the OpenAPI document of schema.Generator,
+// List and Retrieve actions are queries and Create, Update, PartialUpdate and Destroy
+// are mutations, example for InvoiceViewSet registered as "invoices":
+//
+//	query { invoices(status: "paid", page: 2, sort: "-created_at") { total_rows results { id total } } }
+//	query { invoice(pk: "1") { id total } }
+//	mutation { createInvoice(input: {total: 10}) { id } }
+//	mutation { partialUpdateInvoice(pk: "1", input: {total: 20}) { total } }
+//	mutation { deleteInvoice(pk: "1") }
+//
+// Filters of the filterset are arguments of list queries and nested routes take the
+// params of their parents. Every field is dispatched as a request to its route with
+// the headers of the GraphQL request, so authentication, permissions, throttles and
+// serializers are those of the REST API, errors of routes are errors of their fields.
+type Endpoint struct {
+	// Permissions and Throttles of the endpoint, checked before fields are dispatched.
+	Permissions	[]interfaces.IPermission
+	Throttles	[]interfaces.IThrottle
+	// Prefix limits the schema to routes starting with it.
+	Prefix		string
+	// Apps limits the schema to routes mounted by these apps.
+	Apps		[]string
+	// MaxDepth of selections, default 10.
+	MaxDepth	int
+	// MaxFields limits fields selected by queries, counted once fragments are spread,
+	// so small queries can't repeat fragments into huge ones, default 500.
+	MaxFields	int
+	once		sync.Once
+	types		*typeSystem
+}
+
+func (g *Endpoint) GetMaxDepth() int {
+	if g.MaxDepth > 0 {
+		return g.MaxDepth
+	}
+	return 10
+}
+
+func (g *Endpoint) GetMaxFields() int {
+	if g.MaxFields > 0 {
+		return g.MaxFields
+	}
+	return 500
+}
+
+// typeSystem is generated on first use, once routes are registered.
+func (g *Endpoint) typeSystem() *typeSystem {
+	g.once.Do(func() {
+		generator := &schema.Generator{Prefix: g.Prefix, Apps: g.Apps}
+		g.types = build(generator.Generate(), routers.Routes())
+	})
+	return g.types
+}
+
+// SDL returns the schema in GraphQL schema definition language.
+func (g *Endpoint) SDL() string {
+	return g.typeSystem().sdl()
+}
+
+// Register adds GET and POST routes of the endpoint to group, GET can't run mutations.
+func (g *Endpoint) Register(group *gorim.Group, path string) {
+	group.Add(http.MethodGet, path, g.Serve)
+	group.Add(http.MethodPost, path, g.Serve)
+}
+
+// Serve checks permissions and throttles and executes the GraphQL request.
+func (g *Endpoint) Serve(c gorim.Context) error {
+	for _, permission := range g.Permissions {
+		if !permission.HasPermission(c) {
+			return permissions.Deny(c, permission)
+		}
+	}
+	for _, throttle := range g.Throttles {
+		if allowed, wait := throttle.AllowRequest(c); !allowed {
+			return errors.Handle(&errors.ThrottledError{Wait: wait}, c)
+		}
+	}
+	req, err := readRequest(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &Response{Errors: []*Error{{Message: err.Error()}}})
+	}
+	response := execute(g.typeSystem(), req, g.GetMaxDepth(), g.GetMaxFields(), func(field *fieldDef, args map[string]any) (any, error) {
+		return dispatch(c, field, args)
+	})
+	status := http.StatusOK
+	if response.Data == nil && len(response.Errors) > 0 {
+		if errorStatus, ok := response.Errors[0].Extensions["status"].(int); ok {
+			status = errorStatus
+		}
+	}
+	return c.JSON(status, response)
+}
+
+// readRequest reads query, operationName and variables of GET params or POST body,
+// bodies of application/graphql are the query.
+func readRequest(c gorim.Context) (request, error) {
+	req := request{readOnly: c.Request().Method == http.MethodGet}
+	var rawVariables json.RawMessage
+	if req.readOnly {
+		req.query = c.QueryParam("query")
+		req.operationName = c.QueryParam("operationName")
+		if variables := c.QueryParam("variables"); variables != "" {
+			rawVariables = json.RawMessage(variables)
+		}
+	} else if strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), "application/graphql") {
+		body, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return req, err
+		}
+		req.query = string(body)
+	} else {
+		var body struct {
+			Query			string			`json:"query"`
+			OperationName	string			`json:"operationName"`
+			Variables		json.RawMessage	`json:"variables"`
+		}
+		if err := json.NewDecoder(c.Request().Body).Decode(&body); err != nil {
+			return req, fmt.Errorf("Body must be a JSON object of query, operationName and variables.")
+		}
+		req.query, req.operationName, rawVariables = body.Query, body.OperationName, body.Variables
+	}
+	if strings.TrimSpace(req.query) == "" {
+		return req, fmt.Errorf("Must provide query string.")
+	}
+	if len(rawVariables) > 0 && string(rawVariables) != "null" {
+		decoder := json.NewDecoder(bytes.NewReader(rawVariables))
+		decoder.UseNumber()
+		if err := decoder.Decode(&req.variables); err != nil {
+			return req, fmt.Errorf("Variables must be a JSON object.")
+		}
+	}
+	return req, nil
+}
+
+// dispatch requests the route of field and returns its decoded body.
+func dispatch(c gorim.Context, field *fieldDef, args map[string]any) (any, error) {
+	params := map[string]string{}
+	query := url.Values{}
+	var body []byte
+	for _, arg := range field.args {
+		value, ok := args[arg.name]
+		if !ok || value == nil {
+			continue
+		}
+		switch arg.in {
+		case "path":
+			params[arg.name] = fmt.Sprint(value)
+		case "query":
+			query.Set(arg.name, queryValue(value))
+		case "body":
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return nil, err
+			}
+			body = encoded
+		}
+	}
+	recorder, err := routers.Dispatch(c, *field.route, params, query, body)
+	if err != nil {
+		return nil, err
+	}
+	if recorder.Code >= http.StatusBadRequest {
+		return nil, routeError(recorder)
+	}
+	if field.typ.namedType() == "Boolean" {
+		return true, nil
+	}
+	if recorder.Body.Len() == 0 {
+		return nil, nil
+	}
+	var value any
+	decoder := json.NewDecoder(recorder.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// queryValue formats argument as query param, lists are comma separated.
+func queryValue(value any) string {
+	switch value := value.(type) {
+	case []any:
+		items := make([]string, 0, len(value))
+		for _, item := range value {
+			items = append(items, queryValue(item))
+		}
+		return strings.Join(items, ",")
+	case map[string]any:
+		encoded, _ := json.Marshal(value)
+		return string(encoded)
+	case bool:
+		return strconv.FormatBool(value)
+	}
+	return fmt.Sprint(value)
+}
+
+// routeError converts error response of route, validation errors are kept in the
+// errors extension.
+func routeError(recorder *httptest.ResponseRecorder) *Error {
+	graphqlError := &Error{
+		Message: http.StatusText(recorder.Code),
+		Extensions: map[string]any{"status": recorder.Code, "code": errors.StatusCode(recorder.Code)},
+	}
+	var body map[string]any
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		return graphqlError
+	}
+	if code, ok := body["code"].(string); ok {
+		graphqlError.Extensions["code"] = code
+	}
+	if message, ok := body["error"].(string); ok {
+		graphqlError.Message = message
+		return graphqlError
+	}
+	fields := make([]string, 0, len(body))
+	for name := range body {
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+	parts := make([]string, 0, len(fields))
+	for _, name := range fields {
+		if messages, ok := body[name].([]any); ok {
+			texts := make([]string, 0, len(messages))
+			for _, message := range messages {
+				texts = append(texts, fmt.Sprint(message))
+			}
+			parts = append(parts, name + ": " + strings.Join(texts, " "))
+		}
+	}
+	if len(parts) > 0 {
+		graphqlError.Message = strings.Join(parts, "; ")
+		graphqlError.Extensions["errors"] = body
+	}
+	return graphqlError
+}
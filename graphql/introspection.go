@@ -0,0 +1,293 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+var typeKinds = []string{"SCALAR", "OBJECT", "INTERFACE", "UNION", "ENUM", "INPUT_OBJECT", "LIST", "NON_NULL"}
+
+var directiveLocations = []string{
+	"QUERY", "MUTATION", "SUBSCRIPTION", "FIELD", "FRAGMENT_DEFINITION", "FRAGMENT_SPREAD",
+	"INLINE_FRAGMENT", "VARIABLE_DEFINITION", "SCHEMA", "SCALAR", "OBJECT", "FIELD_DEFINITION",
+	"ARGUMENT_DEFINITION", "INTERFACE", "UNION", "ENUM", "ENUM_VALUE", "INPUT_OBJECT",
+	"INPUT_FIELD_DEFINITION",
+}
+
+// meta fields are queried on any type, __schema and __type on Query only.
+var (
+	typenameField	= parseField("__typename: String!")
+	schemaField		= parseField("__schema: __Schema!")
+	typeField		= parseField("__type(name: String!): __Type")
+)
+
+type directiveDef struct {
+	name		string
+	description	string
+	locations	[]string
+	args		[]*inputValue
+}
+
+var directives = []*directiveDef{
+	{
+		name: "include",
+		description: "Includes this field or fragment only when the if argument is true.",
+		locations: []string{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"},
+		args: []*inputValue{{name: "if", typ: parseType("Boolean!")}},
+	},
+	{
+		name: "skip",
+		description: "Skips this field or fragment when the if argument is true.",
+		locations: []string{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"},
+		args: []*inputValue{{name: "if", typ: parseType("Boolean!")}},
+	},
+	{
+		name: "deprecated",
+		description: "Marks an element of the schema as no longer supported.",
+		locations: []string{"FIELD_DEFINITION", "ARGUMENT_DEFINITION", "INPUT_FIELD_DEFINITION", "ENUM_VALUE"},
+		args: []*inputValue{{name: "reason", typ: parseType("String"), defaultValue: `"No longer supported"`}},
+	},
+}
+
+// addIntrospection adds the types queried by __schema and __type.
+func addIntrospection(ts *typeSystem) {
+	ts.add(&typeDef{kind: "ENUM", name: "__TypeKind", enumValues: typeKinds})
+	ts.add(&typeDef{kind: "ENUM", name: "__DirectiveLocation", enumValues: directiveLocations})
+	ts.add(object("__Schema",
+		"description: String",
+		"types: [__Type!]!",
+		"queryType: __Type!",
+		"mutationType: __Type",
+		"subscriptionType: __Type",
+		"directives: [__Directive!]!",
+	))
+	ts.add(object("__Type",
+		"kind: __TypeKind!",
+		"name: String",
+		"description: String",
+		"specifiedByURL: String",
+		"fields(includeDeprecated: Boolean = false): [__Field!]",
+		"interfaces: [__Type!]",
+		"possibleTypes: [__Type!]",
+		"enumValues(includeDeprecated: Boolean = false): [__EnumValue!]",
+		"inputFields(includeDeprecated: Boolean = false): [__InputValue!]",
+		"ofType: __Type",
+		"isOneOf: Boolean",
+	))
+	ts.add(object("__Field",
+		"name: String!",
+		"description: String",
+		"args(includeDeprecated: Boolean = false): [__InputValue!]!",
+		"type: __Type!",
+		"isDeprecated: Boolean!",
+		"deprecationReason: String",
+	))
+	ts.add(object("__InputValue",
+		"name: String!",
+		"description: String",
+		"type: __Type!",
+		"defaultValue: String",
+		"isDeprecated: Boolean!",
+		"deprecationReason: String",
+	))
+	ts.add(object("__EnumValue",
+		"name: String!",
+		"description: String",
+		"isDeprecated: Boolean!",
+		"deprecationReason: String",
+	))
+	ts.add(object("__Directive",
+		"name: String!",
+		"description: String",
+		"locations: [__DirectiveLocation!]!",
+		"args(includeDeprecated: Boolean = false): [__InputValue!]!",
+		"isRepeatable: Boolean!",
+	))
+}
+
+func object(name string, fields ...string) *typeDef {
+	def := &typeDef{kind: "OBJECT", name: name}
+	for _, field := range fields {
+		def.fields = append(def.fields, parseField(field))
+	}
+	return def
+}
+
+// parseField parses field definition, example: "fields(includeDeprecated: Boolean = false): [__Field!]".
+func parseField(source string) *fieldDef {
+	p := newParser(source)
+	field := &fieldDef{name: p.name()}
+	if p.skip("(") {
+		for !p.skip(")") {
+			arg := &inputValue{name: p.name()}
+			p.expect(":")
+			arg.typ = p.typeRef()
+			if p.skip("=") {
+				arg.defaultValue = literal(p.value(true))
+			}
+			field.args = append(field.args, arg)
+		}
+	}
+	p.expect(":")
+	field.typ = p.typeRef()
+	return field
+}
+
+// literal formats value in the syntax of documents.
+func literal(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		quoted, _ := json.Marshal(value)
+		return string(quoted)
+	case []any:
+		items := make([]string, 0, len(value))
+		for _, item := range value {
+			items = append(items, literal(item))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]any:
+		fields := make([]string, 0, len(value))
+		for name, item := range value {
+			fields = append(fields, name + ": " + literal(item))
+		}
+		return "{" + strings.Join(fields, ", ") + "}"
+	}
+	return fmt.Sprint(value)
+}
+
+func (ts *typeSystem) schemaValue() map[string]any {
+	types := make([]any, 0, len(ts.names))
+	for _, name := range ts.names {
+		types = append(types, ts.typeValue(named(name)))
+	}
+	directiveValues := make([]any, 0, len(directives))
+	for _, directive := range directives {
+		directiveValues = append(directiveValues, ts.directiveValue(directive))
+	}
+	value := map[string]any{
+		"description": nil,
+		"types": types,
+		"queryType": ts.typeValue(named(ts.query.name)),
+		"mutationType": nil,
+		"subscriptionType": nil,
+		"directives": directiveValues,
+	}
+	if ts.mutation != nil {
+		value["mutationType"] = ts.typeValue(named(ts.mutation.name))
+	}
+	return value
+}
+
+// typeValue is evaluated when selected, types reference each other.
+func (ts *typeSystem) typeValue(ref *typeRef) thunk {
+	return func() any {
+		value := map[string]any{
+			"kind": ref.kind,
+			"name": nil,
+			"description": nil,
+			"specifiedByURL": nil,
+			"fields": nil,
+			"interfaces": nil,
+			"possibleTypes": nil,
+			"enumValues": nil,
+			"inputFields": nil,
+			"ofType": nil,
+			"isOneOf": nil,
+		}
+		if ref.kind != "" {
+			value["ofType"] = ts.typeValue(ref.of)
+			return value
+		}
+		def := ts.types[ref.name]
+		value["kind"] = def.kind
+		value["name"] = def.name
+		value["description"] = optional(def.description)
+		switch def.kind {
+		case "OBJECT":
+			value["interfaces"] = []any{}
+			value["fields"] = resolver(func(args map[string]any) (any, error) {
+				fields := []any{}
+				for _, field := range def.fields {
+					if field.deprecationReason == "" || args["includeDeprecated"] == true {
+						fields = append(fields, ts.fieldValue(field))
+					}
+				}
+				return fields, nil
+			})
+		case "INPUT_OBJECT":
+			value["isOneOf"] = false
+			value["inputFields"] = resolver(func(args map[string]any) (any, error) {
+				return ts.inputValues(def.inputFields), nil
+			})
+		case "ENUM":
+			value["enumValues"] = resolver(func(args map[string]any) (any, error) {
+				values := make([]any, 0, len(def.enumValues))
+				for _, name := range def.enumValues {
+					values = append(values, map[string]any{
+						"name": name,
+						"description": nil,
+						"isDeprecated": false,
+						"deprecationReason": nil,
+					})
+				}
+				return values, nil
+			})
+		}
+		return value
+	}
+}
+
+func (ts *typeSystem) fieldValue(field *fieldDef) map[string]any {
+	return map[string]any{
+		"name": field.name,
+		"description": optional(field.description),
+		"args": resolver(func(args map[string]any) (any, error) {
+			return ts.inputValues(field.args), nil
+		}),
+		"type": ts.typeValue(field.typ),
+		"isDeprecated": field.deprecationReason != "",
+		"deprecationReason": optional(field.deprecationReason),
+	}
+}
+
+func (ts *typeSystem) inputValues(values []*inputValue) []any {
+	result := make([]any, 0, len(values))
+	for _, value := range values {
+		result = append(result, map[string]any{
+			"name": value.name,
+			"description": optional(value.description),
+			"type": ts.typeValue(value.typ),
+			"defaultValue": optional(value.defaultValue),
+			"isDeprecated": false,
+			"deprecationReason": nil,
+		})
+	}
+	return result
+}
+
+func (ts *typeSystem) directiveValue(directive *directiveDef) map[string]any {
+	locations := make([]any, 0, len(directive.locations))
+	for _, location := range directive.locations {
+		locations = append(locations, location)
+	}
+	return map[string]any{
+		"name": directive.name,
+		"description": optional(directive.description),
+		"locations": locations,
+		"args": resolver(func(args map[string]any) (any, error) {
+			return ts.inputValues(directive.args), nil
+		}),
+		"isRepeatable": false,
+	}
+}
+
+// optional returns nil for empty string, rendered as null.
+func optional(value string) any {
+	if value == "" {
+		return nil
+	}
+	return value
+}
@@ -0,0 +1,617 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// document is a parsed executable document, type system definitions aren't supported.
+type document struct {
+	operations	[]*operation
+	fragments	map[string]*fragment
+}
+
+type operation struct {
+	// kind is "query", "mutation" or "subscription".
+	kind		string
+	name		string
+	variables	[]*variableDefinition
+	selections	[]*selection
+	location	Location
+}
+
+type variableDefinition struct {
+	name			string
+	typ				*typeRef
+	defaultValue	any
+	hasDefault		bool
+}
+
+type fragment struct {
+	name			string
+	typeCondition	string
+	selections		[]*selection
+}
+
+// selection is a field, a fragment spread when spread is set, or an inline fragment
+// when inline is set.
+type selection struct {
+	alias			string
+	name			string
+	arguments		map[string]any
+	directives		map[string]map[string]any
+	selections		[]*selection
+	spread			string
+	inline			bool
+	typeCondition	string
+	location		Location
+}
+
+// responseKey is the alias of the field, or its name.
+func (s *selection) responseKey() string {
+	if s.alias != "" {
+		return s.alias
+	}
+	return s.name
+}
+
+// typeRef is a named type, or a list or non null wrapping of.
+type typeRef struct {
+	// kind is "LIST", "NON_NULL" or empty for named types.
+	kind	string
+	name	string
+	of		*typeRef
+}
+
+func named(name string) *typeRef {
+	return &typeRef{name: name}
+}
+
+func listOf(of *typeRef) *typeRef {
+	return &typeRef{kind: "LIST", of: of}
+}
+
+func nonNull(of *typeRef) *typeRef {
+	if of.kind == "NON_NULL" {
+		return of
+	}
+	return &typeRef{kind: "NON_NULL", of: of}
+}
+
+func (t *typeRef) String() string {
+	switch t.kind {
+	case "LIST":
+		return "[" + t.of.String() + "]"
+	case "NON_NULL":
+		return t.of.String() + "!"
+	}
+	return t.name
+}
+
+// namedType returns the name of the type wrapped by lists and non nulls.
+func (t *typeRef) namedType() string {
+	for t.of != nil {
+		t = t.of
+	}
+	return t.name
+}
+
+// variable and enumValue are values of the document resolved at execution.
+type variable string
+type enumValue string
+
+// Location is line and column of the document, starting at 1.
+type Location struct {
+	Line	int	`json:"line"`
+	Column	int	`json:"column"`
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunctuator
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind		tokenKind
+	value		string
+	location	Location
+}
+
+type syntaxError struct {
+	message		string
+	location	Location
+}
+
+func (e *syntaxError) Error() string {
+	return fmt.Sprintf("Syntax Error: %s (%d:%d)", e.message, e.location.Line, e.location.Column)
+}
+
+type lexer struct {
+	source		string
+	position	int
+	line		int
+	lineStart	int
+}
+
+func (l *lexer) location() Location {
+	return Location{Line: l.line, Column: l.position - l.lineStart + 1}
+}
+
+func (l *lexer) fail(message string) {
+	panic(&syntaxError{message: message, location: l.location()})
+}
+
+// skip skips whitespace, commas and comments.
+func (l *lexer) skip() {
+	for l.position < len(l.source) {
+		switch char := l.source[l.position]; char {
+		case ' ', '\t', ',':
+			l.position++
+		case '\n', '\r':
+			l.position++
+			if char == '\r' && l.position < len(l.source) && l.source[l.position] == '\n' {
+				l.position++
+			}
+			l.line++
+			l.lineStart = l.position
+		case '#':
+			for l.position < len(l.source) && l.source[l.position] != '\n' && l.source[l.position] != '\r' {
+				l.position++
+			}
+		default:
+			if strings.HasPrefix(l.source[l.position:], "\ufeff") {
+				l.position += len("\ufeff")
+				continue
+			}
+			return
+		}
+	}
+}
+
+func (l *lexer) next() token {
+	l.skip()
+	location := l.location()
+	if l.position >= len(l.source) {
+		return token{kind: tokenEOF, location: location}
+	}
+	char := l.source[l.position]
+	switch {
+	case strings.HasPrefix(l.source[l.position:], "..."):
+		l.position += 3
+		return token{kind: tokenPunctuator, value: "...", location: location}
+	case strings.IndexByte("!$&():=@[]{}|", char) >= 0:
+		l.position++
+		return token{kind: tokenPunctuator, value: string(char), location: location}
+	case char == '_' || isLetter(char):
+		start := l.position
+		for l.position < len(l.source) && (l.source[l.position] == '_' || isLetter(l.source[l.position]) || isDigit(l.source[l.position])) {
+			l.position++
+		}
+		return token{kind: tokenName, value: l.source[start:l.position], location: location}
+	case char == '-' || isDigit(char):
+		return l.number(location)
+	case strings.HasPrefix(l.source[l.position:], `"""`):
+		return token{kind: tokenString, value: l.blockString(), location: location}
+	case char == '"':
+		return token{kind: tokenString, value: l.string(), location: location}
+	}
+	l.fail(fmt.Sprintf("Unexpected character %q.", char))
+	return token{}
+}
+
+func (l *lexer) number(location Location) token {
+	start := l.position
+	kind := tokenInt
+	if l.source[l.position] == '-' {
+		l.position++
+	}
+	digits := func() {
+		if l.position >= len(l.source) || !isDigit(l.source[l.position]) {
+			l.fail("Invalid number, expected digit.")
+		}
+		for l.position < len(l.source) && isDigit(l.source[l.position]) {
+			l.position++
+		}
+	}
+	digits()
+	if l.position < len(l.source) && l.source[l.position] == '.' {
+		kind = tokenFloat
+		l.position++
+		digits()
+	}
+	if l.position < len(l.source) && (l.source[l.position] == 'e' || l.source[l.position] == 'E') {
+		kind = tokenFloat
+		l.position++
+		if l.position < len(l.source) && (l.source[l.position] == '+' || l.source[l.position] == '-') {
+			l.position++
+		}
+		digits()
+	}
+	return token{kind: kind, value: l.source[start:l.position], location: location}
+}
+
+func (l *lexer) string() string {
+	l.position++
+	var value strings.Builder
+	for l.position < len(l.source) {
+		char := l.source[l.position]
+		switch {
+		case char == '"':
+			l.position++
+			return value.String()
+		case char == '\n' || char == '\r':
+			l.fail("Unterminated string.")
+		case char == '\\':
+			l.position++
+			if l.position >= len(l.source) {
+				l.fail("Unterminated string.")
+			}
+			escaped := l.source[l.position]
+			l.position++
+			switch escaped {
+			case '"', '\\', '/':
+				value.WriteByte(escaped)
+			case 'b':
+				value.WriteByte('\b')
+			case 'f':
+				value.WriteByte('\f')
+			case 'n':
+				value.WriteByte('\n')
+			case 'r':
+				value.WriteByte('\r')
+			case 't':
+				value.WriteByte('\t')
+			case 'u':
+				if l.position + 4 > len(l.source) {
+					l.fail("Invalid unicode escape sequence.")
+				}
+				code, err := strconv.ParseUint(l.source[l.position:l.position + 4], 16, 32)
+				if err != nil {
+					l.fail("Invalid unicode escape sequence.")
+				}
+				l.position += 4
+				value.WriteRune(rune(code))
+			default:
+				l.fail(fmt.Sprintf("Invalid character escape sequence \\%c.", escaped))
+			}
+		default:
+			r, size := utf8.DecodeRuneInString(l.source[l.position:])
+			value.WriteRune(r)
+			l.position += size
+		}
+	}
+	l.fail("Unterminated string.")
+	return ""
+}
+
+// blockString reads """ string, removing the common indentation of its lines.
+func (l *lexer) blockString() string {
+	l.position += 3
+	start := l.position
+	for l.position < len(l.source) {
+		if strings.HasPrefix(l.source[l.position:], `\"""`) {
+			l.position += 4
+			continue
+		}
+		if strings.HasPrefix(l.source[l.position:], `"""`) {
+			raw := strings.ReplaceAll(l.source[start:l.position], `\"""`, `"""`)
+			if last := strings.LastIndex(l.source[start:l.position], "\n"); last >= 0 {
+				l.line += strings.Count(l.source[start:l.position], "\n")
+				l.lineStart = start + last + 1
+			}
+			l.position += 3
+			return dedent(raw)
+		}
+		l.position++
+	}
+	l.fail("Unterminated string.")
+	return ""
+}
+
+func dedent(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n")
+	indent := -1
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if width := len(line) - len(trimmed); indent < 0 || width < indent {
+			indent = width
+		}
+	}
+	for i := 1; i < len(lines) && indent > 0; i++ {
+		if len(lines[i]) >= indent {
+			lines[i] = lines[i][indent:]
+		}
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines) - 1]) == "" {
+		lines = lines[:len(lines) - 1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func isLetter(char byte) bool {
+	return (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z')
+}
+
+func isDigit(char byte) bool {
+	return char >= '0' && char <= '9'
+}
+
+type parser struct {
+	lexer	*lexer
+	token	token
+}
+
+func newParser(source string) *parser {
+	p := &parser{lexer: &lexer{source: source, line: 1}}
+	p.token = p.lexer.next()
+	return p
+}
+
+// parse parses executable document, syntax errors are returned as *syntaxError.
+func parse(source string) (doc *document, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			syntax, ok := recovered.(*syntaxError)
+			if !ok {
+				panic(recovered)
+			}
+			err = syntax
+		}
+	}()
+	p := newParser(source)
+	doc = &document{fragments: map[string]*fragment{}}
+	if p.token.kind == tokenEOF {
+		p.fail("Unexpected <EOF>.")
+	}
+	for p.token.kind != tokenEOF {
+		switch {
+		case p.peek("{"):
+			doc.operations = append(doc.operations, &operation{kind: "query", location: p.token.location, selections: p.selectionSet()})
+		case p.token.kind == tokenName && (p.token.value == "query" || p.token.value == "mutation" || p.token.value == "subscription"):
+			doc.operations = append(doc.operations, p.operation())
+		case p.token.kind == tokenName && p.token.value == "fragment":
+			definition := p.fragment()
+			if _, ok := doc.fragments[definition.name]; ok {
+				p.fail(fmt.Sprintf("There can be only one fragment named %q.", definition.name))
+			}
+			doc.fragments[definition.name] = definition
+		default:
+			p.unexpected()
+		}
+	}
+	return doc, nil
+}
+
+// parseType parses type reference, example: "[String!]!".
+func parseType(source string) *typeRef {
+	return newParser(source).typeRef()
+}
+
+func (p *parser) fail(message string) {
+	panic(&syntaxError{message: message, location: p.token.location})
+}
+
+func (p *parser) unexpected() {
+	if p.token.kind == tokenEOF {
+		p.fail("Unexpected <EOF>.")
+	}
+	p.fail(fmt.Sprintf("Unexpected %q.", p.token.value))
+}
+
+func (p *parser) advance() token {
+	current := p.token
+	p.token = p.lexer.next()
+	return current
+}
+
+func (p *parser) peek(punctuator string) bool {
+	return p.token.kind == tokenPunctuator && p.token.value == punctuator
+}
+
+func (p *parser) skip(punctuator string) bool {
+	if p.peek(punctuator) {
+		p.advance()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(punctuator string) {
+	if !p.skip(punctuator) {
+		p.fail(fmt.Sprintf("Expected %q, found %s.", punctuator, p.describe()))
+	}
+}
+
+func (p *parser) name() string {
+	if p.token.kind != tokenName {
+		p.fail(fmt.Sprintf("Expected Name, found %s.", p.describe()))
+	}
+	return p.advance().value
+}
+
+func (p *parser) describe() string {
+	if p.token.kind == tokenEOF {
+		return "<EOF>"
+	}
+	return strconv.Quote(p.token.value)
+}
+
+func (p *parser) operation() *operation {
+	location := p.token.location
+	definition := &operation{kind: p.advance().value, location: location}
+	if p.token.kind == tokenName {
+		definition.name = p.name()
+	}
+	if p.skip("(") {
+		for !p.skip(")") {
+			p.expect("$")
+			variable := &variableDefinition{name: p.name()}
+			p.expect(":")
+			variable.typ = p.typeRef()
+			if p.skip("=") {
+				variable.defaultValue = p.value(true)
+				variable.hasDefault = true
+			}
+			p.directives()
+			definition.variables = append(definition.variables, variable)
+		}
+	}
+	p.directives()
+	definition.selections = p.selectionSet()
+	return definition
+}
+
+func (p *parser) fragment() *fragment {
+	p.advance()
+	definition := &fragment{name: p.name()}
+	if definition.name == "on" {
+		p.fail("Unexpected Name \"on\".")
+	}
+	if p.token.kind != tokenName || p.token.value != "on" {
+		p.fail(fmt.Sprintf("Expected \"on\", found %s.", p.describe()))
+	}
+	p.advance()
+	definition.typeCondition = p.name()
+	p.directives()
+	definition.selections = p.selectionSet()
+	return definition
+}
+
+func (p *parser) selectionSet() []*selection {
+	p.expect("{")
+	selections := []*selection{}
+	for !p.skip("}") {
+		selections = append(selections, p.selection())
+	}
+	return selections
+}
+
+func (p *parser) selection() *selection {
+	location := p.token.location
+	if p.skip("...") {
+		if p.token.kind == tokenName && p.token.value != "on" {
+			return &selection{spread: p.name(), directives: p.directives(), location: location}
+		}
+		inline := &selection{inline: true, location: location}
+		if p.token.kind == tokenName {
+			p.advance()
+			inline.typeCondition = p.name()
+		}
+		inline.directives = p.directives()
+		inline.selections = p.selectionSet()
+		return inline
+	}
+	field := &selection{name: p.name(), location: location}
+	if p.skip(":") {
+		field.alias, field.name = field.name, p.name()
+	}
+	field.arguments = p.arguments(false)
+	field.directives = p.directives()
+	if p.peek("{") {
+		field.selections = p.selectionSet()
+	}
+	return field
+}
+
+func (p *parser) arguments(constant bool) map[string]any {
+	arguments := map[string]any{}
+	if !p.skip("(") {
+		return arguments
+	}
+	for !p.skip(")") {
+		name := p.name()
+		if _, ok := arguments[name]; ok {
+			p.fail(fmt.Sprintf("There can be only one argument named %q.", name))
+		}
+		p.expect(":")
+		arguments[name] = p.value(constant)
+	}
+	return arguments
+}
+
+func (p *parser) directives() map[string]map[string]any {
+	directives := map[string]map[string]any{}
+	for p.skip("@") {
+		directives[p.name()] = p.arguments(false)
+	}
+	return directives
+}
+
+func (p *parser) typeRef() *typeRef {
+	var typ *typeRef
+	if p.skip("[") {
+		typ = listOf(p.typeRef())
+		p.expect("]")
+	} else {
+		typ = named(p.name())
+	}
+	if p.skip("!") {
+		typ = nonNull(typ)
+	}
+	return typ
+}
+
+// value parses value literal, constant values can't have variables.
+func (p *parser) value(constant bool) any {
+	current := p.token
+	switch current.kind {
+	case tokenInt:
+		p.advance()
+		return json.Number(current.value)
+	case tokenFloat:
+		p.advance()
+		return json.Number(current.value)
+	case tokenString:
+		p.advance()
+		return current.value
+	case tokenName:
+		p.advance()
+		switch current.value {
+		case "true":
+			return true
+		case "false":
+			return false
+		case "null":
+			return nil
+		}
+		return enumValue(current.value)
+	}
+	switch {
+	case p.skip("$"):
+		if constant {
+			p.fail("Unexpected variable in constant value.")
+		}
+		return variable(p.name())
+	case p.skip("["):
+		list := []any{}
+		for !p.skip("]") {
+			list = append(list, p.value(constant))
+		}
+		return list
+	case p.skip("{"):
+		object := map[string]any{}
+		for !p.skip("}") {
+			name := p.name()
+			p.expect(":")
+			object[name] = p.value(constant)
+		}
+		return object
+	}
+	p.unexpected()
+	return nil
+}
@@ -0,0 +1,441 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git/routers"
+	"github.com/rimba47prayoga/gorim.git/schema"
+)
+
+// typeDef is a named type of the schema, kind is "SCALAR", "OBJECT", "INPUT_OBJECT" or "ENUM".
+type typeDef struct {
+	kind		string
+	name		string
+	description	string
+	fields		[]*fieldDef
+	inputFields	[]*inputValue
+	enumValues	[]string
+}
+
+func (t *typeDef) field(name string) *fieldDef {
+	for _, field := range t.fields {
+		if field.name == name {
+			return field
+		}
+	}
+	return nil
+}
+
+type fieldDef struct {
+	name				string
+	description			string
+	args				[]*inputValue
+	typ					*typeRef
+	deprecationReason	string
+	// route resolves fields of Query and Mutation.
+	route				*routers.RouteInfo
+}
+
+func (f *fieldDef) arg(name string) *inputValue {
+	for _, arg := range f.args {
+		if arg.name == name {
+			return arg
+		}
+	}
+	return nil
+}
+
+type inputValue struct {
+	name			string
+	description		string
+	typ				*typeRef
+	// defaultValue is a literal of the document, example: "false".
+	defaultValue	string
+	// in is "path", "query" or "body" for arguments of routes.
+	in				string
+}
+
+// typeSystem is the schema generated from the OpenAPI document of the routes.
+type typeSystem struct {
+	types		map[string]*typeDef
+	// names keeps the order types are defined in.
+	names		[]string
+	query		*typeDef
+	mutation	*typeDef
+	components	map[string]*schema.Schema
+}
+
+var (
+	pathParam	= regexp.MustCompile(`:([^/]+)`)
+	validName	= regexp.MustCompile(`^[_A-Za-z][_0-9A-Za-z]*$`)
+)
+
+var builtinScalars = []string{"String", "Int", "Float", "Boolean", "ID"}
+
+// build generates Query and Mutation fields of the CRUD actions of the routes found in doc.
+func build(doc *schema.Document, routes []routers.RouteInfo) *typeSystem {
+	ts := &typeSystem{types: map[string]*typeDef{}, components: doc.Components.Schemas}
+	for _, name := range builtinScalars {
+		ts.add(&typeDef{kind: "SCALAR", name: name})
+	}
+	ts.add(&typeDef{kind: "SCALAR", name: "JSON", description: "Any JSON value."})
+	ts.query = ts.add(&typeDef{kind: "OBJECT", name: "Query"})
+	mutation := &typeDef{kind: "OBJECT", name: "Mutation"}
+	models := map[string]string{}
+	for _, route := range routes {
+		if operation := lookupOperation(doc, route); operation != nil {
+			collection := collectionOf(route.Path)
+			if model := ts.modelOf(route.Action, operation); model != "" && models[collection] == "" {
+				models[collection] = model
+			}
+		}
+	}
+	for i := range routes {
+		route := &routes[i]
+		operation := lookupOperation(doc, *route)
+		if operation == nil {
+			continue
+		}
+		collection := collectionOf(route.Path)
+		model := models[collection]
+		if model == "" {
+			model = strings.TrimSuffix(route.ViewSet, "ViewSet")
+		}
+		ts.addRoute(route, operation, collection, model, mutation)
+	}
+	if len(mutation.fields) > 0 {
+		ts.mutation = ts.add(mutation)
+	}
+	addIntrospection(ts)
+	return ts
+}
+
+func (ts *typeSystem) add(def *typeDef) *typeDef {
+	if _, ok := ts.types[def.name]; !ok {
+		ts.names = append(ts.names, def.name)
+	}
+	ts.types[def.name] = def
+	return def
+}
+
+// collectionOf returns path of the collection of the route, example: "/users/:pk/" => "/users".
+func collectionOf(path string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(path, "/"), "/:pk")
+}
+
+func lookupOperation(doc *schema.Document, route routers.RouteInfo) *schema.Operation {
+	item, ok := doc.Paths[pathParam.ReplaceAllString(route.Path, "{$1}")]
+	if !ok {
+		return nil
+	}
+	return item[strings.ToLower(route.Method)]
+}
+
+// modelOf returns component name of the object served by the action.
+func (ts *typeSystem) modelOf(action string, operation *schema.Operation) string {
+	switch action {
+	case "List":
+		if page := ts.component(responseSchema(operation, "200")); page != nil {
+			if results, ok := page.Properties["results"]; ok && results.Items != nil {
+				return refName(results.Items)
+			}
+		}
+	case "Retrieve", "Update", "PartialUpdate":
+		return refName(responseSchema(operation, "200"))
+	case "Create":
+		return refName(responseSchema(operation, "201"))
+	}
+	return ""
+}
+
+// addRoute defines field of CRUD action, nested routes are prefixed by their parents,
+// example: GET /projects/:project_id/tasks is projectsTasks.
+func (ts *typeSystem) addRoute(route *routers.RouteInfo, operation *schema.Operation, collection string, model string, mutation *typeDef) {
+	segments := strings.Split(strings.Trim(collection, "/"), "/")
+	prefix := ""
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, ":") {
+			continue
+		}
+		for _, parent := range segments[max(i - 1, 0):len(segments) - 1] {
+			if !strings.HasPrefix(parent, ":") {
+				prefix += upperFirst(camelCase(parent))
+			}
+		}
+		break
+	}
+	singular := prefix + upperFirst(camelCase(model))
+	field := &fieldDef{description: operation.Description, route: route}
+	if operation.Deprecated {
+		field.deprecationReason = "No longer supported"
+	}
+	parent := mutation
+	switch route.Action {
+	case "List":
+		parent = ts.query
+		field.name = lowerFirst(prefix + upperFirst(camelCase(segments[len(segments) - 1])))
+		field.typ = ts.output(responseSchema(operation, "200"))
+	case "Retrieve":
+		parent = ts.query
+		field.name = lowerFirst(singular)
+		field.typ = ts.output(responseSchema(operation, "200"))
+	case "Create":
+		field.name = "create" + singular
+		field.typ = ts.output(responseSchema(operation, "201"))
+	case "Update":
+		field.name = "update" + singular
+		field.typ = ts.output(responseSchema(operation, "200"))
+	case "PartialUpdate":
+		field.name = "partialUpdate" + singular
+		field.typ = ts.output(responseSchema(operation, "200"))
+	case "Destroy", "Delete":
+		field.name = "delete" + singular
+		field.typ = named("Boolean")
+	default:
+		return
+	}
+	if !validName.MatchString(field.name) {
+		return
+	}
+	for _, match := range pathParam.FindAllStringSubmatch(route.Path, -1) {
+		if validName.MatchString(match[1]) {
+			field.args = append(field.args, &inputValue{name: match[1], typ: nonNull(named("ID")), in: "path"})
+		}
+	}
+	for _, parameter := range operation.Parameters {
+		if parameter.In == "query" && validName.MatchString(parameter.Name) && field.arg(parameter.Name) == nil {
+			field.args = append(field.args, &inputValue{
+				name: parameter.Name,
+				description: parameter.Description,
+				typ: ts.input(parameter.Schema),
+				in: "query",
+			})
+		}
+	}
+	if operation.RequestBody != nil {
+		if content, ok := operation.RequestBody.Content[echo.MIMEApplicationJSON]; ok {
+			field.args = append(field.args, &inputValue{name: "input", typ: nonNull(ts.input(content.Schema)), in: "body"})
+		}
+	}
+	if defined := parent.field(field.name); defined != nil {
+		panic(fmt.Sprintf("graphql: %s.%s is generated by %s %s and %s %s, limit routes with Prefix or Apps",
+			parent.name, field.name, defined.route.Method, defined.route.Path, route.Method, route.Path))
+	}
+	parent.fields = append(parent.fields, field)
+}
+
+func responseSchema(operation *schema.Operation, status string) *schema.Schema {
+	response, ok := operation.Responses[status]
+	if !ok {
+		return nil
+	}
+	if content, ok := response.Content[echo.MIMEApplicationJSON]; ok {
+		return content.Schema
+	}
+	return nil
+}
+
+func refName(s *schema.Schema) string {
+	if s == nil {
+		return ""
+	}
+	return strings.TrimPrefix(s.Ref, "#/components/schemas/")
+}
+
+// component returns the component referenced by s, nil unless it's an object with properties.
+func (ts *typeSystem) component(s *schema.Schema) *schema.Schema {
+	if s == nil || s.Ref == "" {
+		return nil
+	}
+	component, ok := ts.components[refName(s)]
+	if !ok || len(component.Properties) == 0 {
+		return nil
+	}
+	return component
+}
+
+// output converts schema to output type, components are objects and other objects JSON.
+func (ts *typeSystem) output(s *schema.Schema) *typeRef {
+	if s == nil {
+		return named("JSON")
+	}
+	if s.Ref != "" {
+		component := ts.component(s)
+		if component == nil {
+			return named("JSON")
+		}
+		name := refName(s)
+		if _, ok := ts.types[name]; !ok {
+			def := ts.add(&typeDef{kind: "OBJECT", name: name, description: component.Description})
+			for _, property := range sortedProperties(component) {
+				value := component.Properties[property]
+				if !validName.MatchString(property) || value.WriteOnly {
+					continue
+				}
+				field := &fieldDef{name: property, description: value.Description, typ: ts.output(value)}
+				if value.Deprecated {
+					field.deprecationReason = "No longer supported"
+				}
+				def.fields = append(def.fields, field)
+			}
+		}
+		return named(name)
+	}
+	if s.Type == "array" {
+		return listOf(ts.output(s.Items))
+	}
+	return named(scalarOf(s))
+}
+
+// input converts schema to input type, components are input objects without read only
+// fields named like "UserInput".
+func (ts *typeSystem) input(s *schema.Schema) *typeRef {
+	if s == nil {
+		return named("JSON")
+	}
+	if s.Ref != "" {
+		component := ts.component(s)
+		if component == nil {
+			return named("JSON")
+		}
+		name := refName(s) + "Input"
+		if _, ok := ts.types[name]; !ok {
+			def := ts.add(&typeDef{kind: "INPUT_OBJECT", name: name, description: component.Description})
+			for _, property := range sortedProperties(component) {
+				value := component.Properties[property]
+				if !validName.MatchString(property) || value.ReadOnly {
+					continue
+				}
+				typ := ts.input(value)
+				if contains(component.Required, property) && !value.Nullable {
+					typ = nonNull(typ)
+				}
+				def.inputFields = append(def.inputFields, &inputValue{name: property, description: value.Description, typ: typ})
+			}
+		}
+		return named(name)
+	}
+	if s.Type == "array" {
+		return listOf(ts.input(s.Items))
+	}
+	return named(scalarOf(s))
+}
+
+func scalarOf(s *schema.Schema) string {
+	switch s.Type {
+	case "string":
+		return "String"
+	case "integer":
+		return "Int"
+	case "number":
+		return "Float"
+	case "boolean":
+		return "Boolean"
+	}
+	return "JSON"
+}
+
+func sortedProperties(s *schema.Schema) []string {
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sdl returns the schema in GraphQL schema definition language.
+func (ts *typeSystem) sdl() string {
+	var sdl strings.Builder
+	for _, name := range ts.names {
+		def := ts.types[name]
+		if strings.HasPrefix(name, "__") || contains(builtinScalars, name) {
+			continue
+		}
+		if sdl.Len() > 0 {
+			sdl.WriteString("\n")
+		}
+		writeDescription(&sdl, "", def.description)
+		switch def.kind {
+		case "SCALAR":
+			fmt.Fprintf(&sdl, "scalar %s\n", name)
+		case "ENUM":
+			fmt.Fprintf(&sdl, "enum %s {\n  %s\n}\n", name, strings.Join(def.enumValues, "\n  "))
+		case "INPUT_OBJECT":
+			fmt.Fprintf(&sdl, "input %s {\n", name)
+			for _, field := range def.inputFields {
+				writeDescription(&sdl, "  ", field.description)
+				fmt.Fprintf(&sdl, "  %s: %s\n", field.name, field.typ)
+			}
+			sdl.WriteString("}\n")
+		case "OBJECT":
+			fmt.Fprintf(&sdl, "type %s {\n", name)
+			for _, field := range def.fields {
+				writeDescription(&sdl, "  ", field.description)
+				sdl.WriteString("  " + field.name)
+				if len(field.args) > 0 {
+					args := make([]string, 0, len(field.args))
+					for _, arg := range field.args {
+						args = append(args, arg.name + ": " + arg.typ.String())
+					}
+					sdl.WriteString("(" + strings.Join(args, ", ") + ")")
+				}
+				sdl.WriteString(": " + field.typ.String())
+				if field.deprecationReason != "" {
+					reason, _ := json.Marshal(field.deprecationReason)
+					fmt.Fprintf(&sdl, " @deprecated(reason: %s)", reason)
+				}
+				sdl.WriteString("\n")
+			}
+			sdl.WriteString("}\n")
+		}
+	}
+	return sdl.String()
+}
+
+func writeDescription(sdl *strings.Builder, indent string, description string) {
+	if description == "" {
+		return
+	}
+	quoted, _ := json.Marshal(description)
+	sdl.WriteString(indent + string(quoted) + "\n")
+}
+
+// camelCase converts path segment to camel case, example: "user-groups" => "userGroups".
+func camelCase(segment string) string {
+	words := strings.FieldsFunc(segment, func(r rune) bool {
+		return !(r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9'))
+	})
+	for i := 1; i < len(words); i++ {
+		words[i] = upperFirst(words[i])
+	}
+	return strings.Join(words, "")
+}
+
+func upperFirst(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+func lowerFirst(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+func contains(values []string, value string) bool {
+	for _, item := range values {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -26,5 +26,5 @@ func (g *Group) Add(method string, path string, handler HandlerFunc, middleware
 	return g.EchoGroup.Add(method, path, func(c echo.Context) error {
 		ctx := NewContext(c)
 		return handler(ctx)
-	})
+	}, append([]echo.MiddlewareFunc{}, middleware...)...)
 }
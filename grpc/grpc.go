@@ -0,0 +1,384 @@
+package grpc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/interfaces"
+	"github.com/rimba47prayoga/gorim.git/permissions"
+	"github.com/rimba47prayoga/gorim.git/routers"
+)
+
+// Status codes of gRPC.
+const (
+	OK					= 0
+	Unknown				= 2
+	InvalidArgument		= 3
+	DeadlineExceeded	= 4
+	NotFound			= 5
+	PermissionDenied	= 7
+	ResourceExhausted	= 8
+	FailedPrecondition	= 9
+	Aborted				= 10
+	Unimplemented		= 12
+	Internal			= 13
+	Unavailable			= 14
+	Unauthenticated		= 16
+)
+
+// Services serves gRPC services generated from the viewsets registered by routers,
+// example:
+//
+//	services := &grpc.Services{Package: "shop.v1", Prefix: "/api/v1"}
+//	services.Register(server)
+//	server.H2C = true
+//
+// Every viewset serving CRUD actions is a service, example for InvoiceViewSet:
+//
+//	service InvoiceService {
+//	  rpc List(InvoiceListRequest) returns (InvoiceListResponse);
+//	  rpc Retrieve(InvoiceRetrieveRequest) returns (Invoice);
+//	  rpc Create(InvoiceCreateRequest) returns (Invoice);
+//	  rpc Update(InvoiceUpdateRequest) returns (Invoice);
+//	  rpc PartialUpdate(InvoicePartialUpdateRequest) returns (Invoice);
+//	  rpc Destroy(InvoiceDestroyRequest) returns (google.protobuf.Empty);
+//	}
+//
+// Messages are generated from models and serializers with fields named by json tags,
+// Proto returns the .proto file for clients. Calls are dispatched as requests to their
+// routes with the metadata of the call as headers, so authentication, permissions,
+// throttles and serializers are those of the REST API, error responses are mapped to
+// gRPC status codes. gRPC needs HTTP/2, enable Server.H2C or serve with TLS.
+type Services struct {
+	// Permissions and Throttles of the services, checked before calls are dispatched.
+	Permissions		[]interfaces.IPermission
+	Throttles		[]interfaces.IThrottle
+	// Package of the services, default "gorim".
+	Package			string
+	// Prefix limits the services to routes starting with it.
+	Prefix			string
+	// Apps limits the services to routes mounted by these apps.
+	Apps			[]string
+	// MaxMessageSize of requests in bytes, default 4MB.
+	MaxMessageSize	int
+	once			sync.Once
+	registry		*registry
+}
+
+func (s *Services) GetPackage() string {
+	if s.Package != "" {
+		return s.Package
+	}
+	return "gorim"
+}
+
+func (s *Services) GetMaxMessageSize() int {
+	if s.MaxMessageSize > 0 {
+		return s.MaxMessageSize
+	}
+	return 4 << 20
+}
+
+// services are generated on first use, once routes are registered.
+func (s *Services) services() *registry {
+	s.once.Do(func() {
+		s.registry = build(s.GetPackage(), s.Prefix, s.Apps, routers.Routes())
+	})
+	return s.registry
+}
+
+// Proto returns the services and messages in proto3 syntax.
+func (s *Services) Proto() string {
+	return s.services().proto()
+}
+
+// Register adds the route of every method of the package to server.
+func (s *Services) Register(server *gorim.Server) {
+	server.POST("/" + s.GetPackage() + ".*", s.Serve)
+}
+
+// Serve checks permissions and throttles and dispatches the call to its route.
+func (s *Services) Serve(c gorim.Context) error {
+	request := c.Request()
+	if !strings.HasPrefix(request.Header.Get(echo.HeaderContentType), "application/grpc") {
+		return echo.NewHTTPError(http.StatusUnsupportedMediaType)
+	}
+	m, ok := s.services().methods[request.URL.Path]
+	if !ok {
+		return writeStatus(c, Unimplemented, "unknown method " + request.URL.Path)
+	}
+	for _, permission := range s.Permissions {
+		if !permission.HasPermission(c) {
+			return s.deny(c, permission)
+		}
+	}
+	for _, throttle := range s.Throttles {
+		if allowed, wait := throttle.AllowRequest(c); !allowed {
+			return writeStatus(c, ResourceExhausted, fmt.Sprintf("Request was throttled. Expected available in %d seconds.", int(wait.Seconds())))
+		}
+	}
+	if timeout, ok := parseTimeout(request.Header.Get("Grpc-Timeout")); ok {
+		ctx, cancel := context.WithTimeout(request.Context(), timeout)
+		defer cancel()
+		c.SetRequest(request.WithContext(ctx))
+	}
+	payload, code, err := s.readMessage(c)
+	if err != nil {
+		return writeStatus(c, code, err.Error())
+	}
+	args, err := decode(m.request, payload)
+	if err != nil {
+		return writeStatus(c, InvalidArgument, err.Error())
+	}
+	params := map[string]string{}
+	query := url.Values{}
+	var body []byte
+	for name, value := range args {
+		switch m.in[name] {
+		case "path":
+			params[name] = fmt.Sprint(value)
+		case "query":
+			query.Set(name, queryValue(value))
+		case "body":
+			if body, err = json.Marshal(value); err != nil {
+				return writeStatus(c, Internal, err.Error())
+			}
+		}
+	}
+	if body == nil && m.in["input"] == "body" {
+		body = []byte("{}")
+	}
+	recorder, err := routers.Dispatch(c, *m.route, params, query, body)
+	if err != nil {
+		return writeStatus(c, Internal, err.Error())
+	}
+	if c.Request().Context().Err() == context.DeadlineExceeded {
+		return writeStatus(c, DeadlineExceeded, "deadline exceeded")
+	}
+	if recorder.Code >= http.StatusBadRequest {
+		return writeStatus(c, codeOf(recorder.Code), errorMessage(recorder.Code, recorder.Body.Bytes()))
+	}
+	var response []byte
+	if m.response != nil && recorder.Body.Len() > 0 {
+		var value map[string]any
+		decoder := json.NewDecoder(recorder.Body)
+		decoder.UseNumber()
+		if err := decoder.Decode(&value); err != nil {
+			return writeStatus(c, Internal, "response of " + m.route.Path + " isn't a JSON object")
+		}
+		if response, err = encode(m.response, value); err != nil {
+			return writeStatus(c, Internal, err.Error())
+		}
+	}
+	return writeMessage(c, response)
+}
+
+// deny fires the denial event of permission and ends the call.
+func (s *Services) deny(c gorim.Context, permission interfaces.IPermission) error {
+	message, code := permissions.GetDenial(c, permission)
+	status, grpcCode := http.StatusForbidden, PermissionDenied
+	if !c.IsAuthenticated() {
+		status, grpcCode = http.StatusUnauthorized, Unauthenticated
+	}
+	permissions.FireDenied(permissions.DenialEvent{
+		Request: c.Request(),
+		User: c.User(),
+		Permission: permission,
+		Action: c.GetAction(),
+		Status: status,
+		Code: code,
+		Reason: message,
+	})
+	if message == "" {
+		message = http.StatusText(status)
+	}
+	return writeStatus(c, grpcCode, message)
+}
+
+// readMessage reads the length prefixed message of the request, compressed messages
+// must be gzip.
+func (s *Services) readMessage(c gorim.Context) ([]byte, int, error) {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(c.Request().Body, header); err != nil {
+		if err == io.EOF {
+			return nil, OK, nil
+		}
+		return nil, Internal, fmt.Errorf("reading message: %w", err)
+	}
+	size := binary.BigEndian.Uint32(header[1:])
+	if int64(size) > int64(s.GetMaxMessageSize()) {
+		return nil, ResourceExhausted, fmt.Errorf("message larger than max (%d vs. %d)", size, s.GetMaxMessageSize())
+	}
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(c.Request().Body, payload); err != nil {
+		return nil, Internal, fmt.Errorf("reading message: %w", err)
+	}
+	if header[0] == 0 {
+		return payload, OK, nil
+	}
+	if encoding := c.Request().Header.Get("Grpc-Encoding"); encoding != "gzip" {
+		return nil, Unimplemented, fmt.Errorf("grpc-encoding %q isn't supported", encoding)
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(payload))
+	if err != nil {
+		return nil, Internal, err
+	}
+	payload, err = io.ReadAll(io.LimitReader(reader, int64(s.GetMaxMessageSize()) + 1))
+	if err != nil {
+		return nil, Internal, err
+	}
+	if len(payload) > s.GetMaxMessageSize() {
+		return nil, ResourceExhausted, fmt.Errorf("decompressed message larger than max %d", s.GetMaxMessageSize())
+	}
+	return payload, OK, nil
+}
+
+// writeMessage writes the length prefixed response message and the OK status.
+func writeMessage(c gorim.Context, payload []byte) error {
+	response := c.Response()
+	response.Header().Set(echo.HeaderContentType, "application/grpc+proto")
+	response.WriteHeader(http.StatusOK)
+	header := make([]byte, 5)
+	binary.BigEndian.PutUint32(header[1:], uint32(len(payload)))
+	if _, err := response.Write(append(header, payload...)); err != nil {
+		return err
+	}
+	return writeStatus(c, OK, "")
+}
+
+// writeStatus ends the call with status in trailers, calls without response message
+// send them with the headers.
+func writeStatus(c gorim.Context, code int, message string) error {
+	response := c.Response()
+	prefix := http.TrailerPrefix
+	if !response.Committed {
+		prefix = ""
+		response.Header().Set(echo.HeaderContentType, "application/grpc+proto")
+	}
+	response.Header().Set(prefix + "Grpc-Status", strconv.Itoa(code))
+	if message != "" {
+		response.Header().Set(prefix + "Grpc-Message", encodeMessage(message))
+	}
+	if !response.Committed {
+		response.WriteHeader(http.StatusOK)
+	}
+	return nil
+}
+
+// encodeMessage percent encodes status message like gRPC does.
+func encodeMessage(message string) string {
+	encoded := &strings.Builder{}
+	for i := 0; i < len(message); i++ {
+		b := message[i]
+		if b < 0x20 || b > 0x7e || b == '%' {
+			fmt.Fprintf(encoded, "%%%02X", b)
+		} else {
+			encoded.WriteByte(b)
+		}
+	}
+	return encoded.String()
+}
+
+// parseTimeout parses grpc-timeout header, example: "100m" is 100 milliseconds.
+func parseTimeout(value string) (time.Duration, bool) {
+	if len(value) < 2 {
+		return 0, false
+	}
+	amount, err := strconv.ParseInt(value[:len(value) - 1], 10, 64)
+	if err != nil || amount < 0 {
+		return 0, false
+	}
+	units := map[byte]time.Duration{
+		'H': time.Hour, 'M': time.Minute, 'S': time.Second,
+		'm': time.Millisecond, 'u': time.Microsecond, 'n': time.Nanosecond,
+	}
+	unit, ok := units[value[len(value) - 1]]
+	return time.Duration(amount) * unit, ok
+}
+
+// codeOf maps HTTP status of route to gRPC status code.
+func codeOf(status int) int {
+	switch status {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return InvalidArgument
+	case http.StatusUnauthorized:
+		return Unauthenticated
+	case http.StatusForbidden:
+		return PermissionDenied
+	case http.StatusNotFound:
+		return NotFound
+	case http.StatusConflict:
+		return Aborted
+	case http.StatusPreconditionFailed:
+		return FailedPrecondition
+	case http.StatusTooManyRequests:
+		return ResourceExhausted
+	case http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return Unimplemented
+	case http.StatusServiceUnavailable:
+		return Unavailable
+	case http.StatusGatewayTimeout:
+		return DeadlineExceeded
+	}
+	if status >= http.StatusInternalServerError {
+		return Internal
+	}
+	return Unknown
+}
+
+// errorMessage returns the error of the response body, validation errors are
+// joined by field.
+func errorMessage(status int, data []byte) string {
+	var body map[string]any
+	if err := json.Unmarshal(data, &body); err != nil {
+		return http.StatusText(status)
+	}
+	if message, ok := body["error"].(string); ok {
+		return message
+	}
+	fields := make([]string, 0, len(body))
+	for name := range body {
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+	parts := make([]string, 0, len(fields))
+	for _, name := range fields {
+		if messages, ok := body[name].([]any); ok {
+			texts := make([]string, 0, len(messages))
+			for _, message := range messages {
+				texts = append(texts, fmt.Sprint(message))
+			}
+			parts = append(parts, name + ": " + strings.Join(texts, " "))
+		}
+	}
+	if len(parts) == 0 {
+		return http.StatusText(status)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// queryValue formats field as query param, lists are comma separated.
+func queryValue(value any) string {
+	if items, ok := value.([]any); ok {
+		values := make([]string, 0, len(items))
+		for _, item := range items {
+			values = append(values, fmt.Sprint(item))
+		}
+		return strings.Join(values, ",")
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,282 @@
+package grpc
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	timeType			= reflect.TypeOf(time.Time{})
+	deletedAtType		= reflect.TypeOf(gorm.DeletedAt{})
+	textMarshalerType	= reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	jsonMarshalerType	= reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	validName			= regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	genericArgs			= regexp.MustCompile(`\[.*\]$`)
+)
+
+// message is a protobuf message of the generated file.
+type message struct {
+	name	string
+	fields	[]*field
+}
+
+// field of message, kind is a scalar type of protobuf, "message", or "json" for values
+// without protobuf type, sent as string of JSON.
+type field struct {
+	name		string
+	number		int
+	kind		string
+	message		*message
+	repeated	bool
+	// optional tracks presence, null values and fields not sent by clients are absent.
+	optional	bool
+}
+
+func (m *message) field(number int) *field {
+	for _, field := range m.fields {
+		if field.number == number {
+			return field
+		}
+	}
+	return nil
+}
+
+// typeName returns the type of field in the proto file.
+func (f *field) typeName() string {
+	switch f.kind {
+	case "message":
+		return f.message.name
+	case "json":
+		return "string"
+	}
+	return f.kind
+}
+
+// messages converts go types to messages, messages are named by their types.
+type messages struct {
+	byName	map[string]*message
+	byType	map[typeKey]*message
+	// names keeps the order messages are defined in.
+	names	[]string
+	types	map[string]reflect.Type
+}
+
+// typeKey caches messages of go types, inputs are distinct messages.
+type typeKey struct {
+	typ		reflect.Type
+	input	bool
+}
+
+func newMessages() *messages {
+	return &messages{
+		byName: map[string]*message{},
+		byType: map[typeKey]*message{},
+		types: map[string]reflect.Type{},
+	}
+}
+
+// add defines message, typ is the go type it's generated from, nil for request messages.
+func (m *messages) add(msg *message, typ reflect.Type) *message {
+	if defined, ok := m.types[msg.name]; ok {
+		panic(fmt.Sprintf("grpc: message %s is generated from %v and %v, rename one of the types", msg.name, defined, typ))
+	}
+	m.byName[msg.name] = msg
+	m.names = append(m.names, msg.name)
+	m.types[msg.name] = typ
+	return msg
+}
+
+// ofStruct returns message of struct fields named by json tags, input messages skip
+// fields managed by gorm and track presence of every singular field, so partial
+// updates only send the fields set by clients. Fields are numbered in order, set
+// `proto:"N"` tag to keep numbers of fields when they're reordered or removed.
+func (m *messages) ofStruct(typ reflect.Type, name string, input bool) *message {
+	if msg, ok := m.byType[typeKey{typ, input}]; ok {
+		return msg
+	}
+	msg := m.add(&message{name: name}, typ)
+	m.byType[typeKey{typ, input}] = msg
+	used := map[int]bool{}
+	var pending []*field
+	for _, structField := range flattenFields(typ) {
+		jsonName, _, _ := strings.Cut(structField.Tag.Get("json"), ",")
+		if jsonName == "" {
+			jsonName = structField.Name
+		}
+		if !validName.MatchString(jsonName) || (input && isReadOnlyField(structField)) {
+			continue
+		}
+		f := m.fieldOf(structField.Type, name + upperFirst(structField.Name), input)
+		f.name = jsonName
+		if input {
+			f.optional = !f.repeated
+		}
+		if tag := structField.Tag.Get("proto"); tag != "" {
+			number, err := strconv.Atoi(tag)
+			if err != nil || number < 1 || used[number] {
+				panic(fmt.Sprintf("grpc: invalid or duplicate proto tag %q of %s.%s", tag, typ, structField.Name))
+			}
+			f.number = number
+			used[number] = true
+		} else {
+			pending = append(pending, f)
+		}
+		msg.fields = append(msg.fields, f)
+	}
+	next := 1
+	for _, f := range pending {
+		for used[next] {
+			next++
+		}
+		f.number = next
+		used[next] = true
+	}
+	sort.SliceStable(msg.fields, func(i, j int) bool {
+		return msg.fields[i].number < msg.fields[j].number
+	})
+	return msg
+}
+
+// flattenFields returns exported fields of struct, embedded structs without json name
+// are flattened like encoding/json does.
+func flattenFields(typ reflect.Type) []reflect.StructField {
+	fields := []reflect.StructField{}
+	for i := 0; i < typ.NumField(); i++ {
+		structField := typ.Field(i)
+		jsonName, _, _ := strings.Cut(structField.Tag.Get("json"), ",")
+		if jsonName == "-" {
+			continue
+		}
+		if structField.Anonymous && jsonName == "" {
+			embedded := indirect(structField.Type)
+			if embedded.Kind() == reflect.Struct {
+				fields = append(fields, flattenFields(embedded)...)
+				continue
+			}
+		}
+		if structField.IsExported() {
+			fields = append(fields, structField)
+		}
+	}
+	return fields
+}
+
+// fieldOf returns field of go type, name names messages of anonymous structs.
+func (m *messages) fieldOf(typ reflect.Type, name string, input bool) *field {
+	nullable := typ.Kind() == reflect.Ptr
+	typ = indirect(typ)
+	f := &field{optional: nullable}
+	switch {
+	case typ == timeType || typ == deletedAtType:
+		f.kind = "string"
+		f.optional = true
+		return f
+	case reflect.PointerTo(typ).Implements(textMarshalerType):
+		f.kind = "string"
+		return f
+	case reflect.PointerTo(typ).Implements(jsonMarshalerType):
+		f.kind = "json"
+		f.optional = true
+		return f
+	}
+	switch typ.Kind() {
+	case reflect.Bool:
+		f.kind = "bool"
+	case reflect.Int, reflect.Int64:
+		f.kind = "int64"
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		f.kind = "int32"
+	case reflect.Uint, reflect.Uint64:
+		f.kind = "uint64"
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		f.kind = "uint32"
+	case reflect.Float32:
+		f.kind = "float"
+	case reflect.Float64:
+		f.kind = "double"
+	case reflect.String:
+		f.kind = "string"
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			f.kind = "bytes"
+			return f
+		}
+		item := m.fieldOf(typ.Elem(), name, input)
+		if item.repeated {
+			// lists of lists have no protobuf type.
+			return &field{kind: "json", optional: true}
+		}
+		item.repeated, item.optional = true, false
+		return item
+	case reflect.Struct:
+		f.kind = "message"
+		typeName := genericArgs.ReplaceAllString(typ.Name(), "")
+		if typeName == "" {
+			typeName = name
+		}
+		if input {
+			typeName += "Input"
+		}
+		f.message = m.ofStruct(typ, typeName, input)
+	default:
+		// maps and interfaces.
+		f.kind = "json"
+		f.optional = true
+	}
+	return f
+}
+
+// isReadOnlyField reports fields managed by gorm, primary key and timestamps.
+func isReadOnlyField(field reflect.StructField) bool {
+	gormTag := strings.ToLower(field.Tag.Get("gorm"))
+	if strings.Contains(gormTag, "primarykey") || strings.Contains(gormTag, "autocreatetime") || strings.Contains(gormTag, "autoupdatetime") {
+		return true
+	}
+	switch field.Name {
+	case "ID", "CreatedAt", "UpdatedAt", "DeletedAt":
+		return true
+	}
+	return false
+}
+
+func indirect(typ reflect.Type) reflect.Type {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ
+}
+
+func upperFirst(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// writeDefinition writes message definition in proto3 syntax.
+func writeDefinition(proto *strings.Builder, msg *message) {
+	fmt.Fprintf(proto, "message %s {\n", msg.name)
+	for _, f := range msg.fields {
+		label := ""
+		if f.repeated {
+			label = "repeated "
+		} else if f.optional {
+			label = "optional "
+		}
+		comment := ""
+		if f.kind == "json" {
+			comment = " // JSON"
+		}
+		fmt.Fprintf(proto, "  %s%s %s = %d;%s\n", label, f.typeName(), f.name, f.number, comment)
+	}
+	proto.WriteString("}\n")
+}
@@ -0,0 +1,231 @@
+package grpc
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/rimba47prayoga/gorim.git/apps"
+	"github.com/rimba47prayoga/gorim.git/routers"
+	"github.com/rimba47prayoga/gorim.git/schema"
+)
+
+var pathParam = regexp.MustCompile(`:([^/]+)`)
+
+// service of a viewset, methods are its CRUD actions.
+type service struct {
+	name	string
+	methods	[]*method
+}
+
+// method dispatches request to route, fields of request are path params, query params
+// or the JSON body of route, response is nil for google.protobuf.Empty.
+type method struct {
+	name		string
+	route		*routers.RouteInfo
+	request		*message
+	response	*message
+	in			map[string]string
+}
+
+// registry holds messages and services generated from routes.
+type registry struct {
+	pkg			string
+	messages	*messages
+	services	[]*service
+	// methods by their gRPC path, example: "/shop.InvoiceService/List".
+	methods		map[string]*method
+}
+
+// build generates a service of every viewset serving CRUD actions.
+func build(pkg string, prefix string, appNames []string, routes []routers.RouteInfo) *registry {
+	r := &registry{pkg: pkg, messages: newMessages(), methods: map[string]*method{}}
+	services := map[string]*service{}
+	collections := map[string]string{}
+	for i := range routes {
+		route := &routes[i]
+		if !strings.HasPrefix(route.Path, prefix) || route.ViewSet == "" {
+			continue
+		}
+		if len(appNames) > 0 && !slices.Contains(appNames, apps.AppOf(*route)) {
+			continue
+		}
+		methodName := route.Action
+		if methodName == "Delete" {
+			methodName = "Destroy"
+		}
+		if !slices.Contains([]string{"List", "Retrieve", "Create", "Update", "PartialUpdate", "Destroy"}, methodName) {
+			continue
+		}
+		model, serializer, filter := schema.ViewTypes(*route)
+		if model == nil || model.Kind() != reflect.Struct || !validName.MatchString(typeName(model)) {
+			continue
+		}
+		if serializer == nil || serializer.Kind() != reflect.Struct || !validName.MatchString(typeName(serializer)) {
+			serializer = model
+		}
+		collection := strings.TrimSuffix(strings.TrimSuffix(route.Path, "/"), "/:pk")
+		base := parentsOf(collection) + strings.TrimSuffix(route.ViewSet, "ViewSet")
+		if !validName.MatchString(base) {
+			continue
+		}
+		name := base + "Service"
+		svc, ok := services[name]
+		if !ok {
+			svc = &service{name: name}
+			services[name] = svc
+			collections[name] = collection
+			r.services = append(r.services, svc)
+		} else if collections[name] != collection {
+			panic(fmt.Sprintf("grpc: service %s is generated by %s and %s, limit routes with Prefix or Apps",
+				name, collections[name], collection))
+		}
+		m := r.method(route, methodName, base, model, serializer, filter)
+		path := "/" + pkg + "." + name + "/" + methodName
+		if _, ok := r.methods[path]; ok {
+			panic(fmt.Sprintf("grpc: method %s is generated twice by %s %s, limit routes with Prefix or Apps", path, route.Method, route.Path))
+		}
+		r.methods[path] = m
+		svc.methods = append(svc.methods, m)
+	}
+	return r
+}
+
+// parentsOf returns prefix of nested routes named by their parents, example:
+// "/api/projects/:project_id/tasks" is "Projects".
+func parentsOf(collection string) string {
+	segments := strings.Split(strings.Trim(collection, "/"), "/")
+	prefix := ""
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, ":") {
+			continue
+		}
+		for _, parent := range segments[max(i - 1, 0):len(segments) - 1] {
+			if !strings.HasPrefix(parent, ":") {
+				prefix += camelCase(parent)
+			}
+		}
+		break
+	}
+	return prefix
+}
+
+func camelCase(segment string) string {
+	parts := strings.FieldsFunc(segment, func(r rune) bool {
+		return r == '-' || r == '_' || r == '.'
+	})
+	for i, part := range parts {
+		parts[i] = upperFirst(part)
+	}
+	return strings.Join(parts, "")
+}
+
+// method generates messages of action, pk is the first field of detail requests,
+// followed by input for actions with body, then params of parent routes.
+func (r *registry) method(route *routers.RouteInfo, name string, base string, model reflect.Type, serializer reflect.Type, filter reflect.Type) *method {
+	m := &method{name: name, route: route, in: map[string]string{}}
+	request := &message{name: base + name + "Request"}
+	modelMessage := r.messages.ofStruct(model, typeName(model), false)
+	m.response = modelMessage
+	addField := func(f *field, in string) {
+		f.number = len(request.fields) + 1
+		request.fields = append(request.fields, f)
+		m.in[f.name] = in
+	}
+	params := pathParam.FindAllStringSubmatch(route.Path, -1)
+	if slices.ContainsFunc(params, func(match []string) bool { return match[1] == "pk" }) {
+		addField(&field{name: "pk", kind: "string"}, "path")
+	}
+	switch name {
+	case "List":
+		addField(&field{name: "page", kind: "int64", optional: true}, "query")
+		addField(&field{name: "page_size", kind: "int64", optional: true}, "query")
+		addField(&field{name: "sort", kind: "string", optional: true}, "query")
+		m.response = r.messages.add(&message{name: base + "ListResponse", fields: []*field{
+			{name: "results", number: 1, kind: "message", message: modelMessage, repeated: true},
+			{name: "total_rows", number: 2, kind: "int64"},
+			{name: "total_pages", number: 3, kind: "int64"},
+			{name: "page", number: 4, kind: "int64"},
+			{name: "page_size", number: 5, kind: "int64"},
+			{name: "sort", number: 6, kind: "string"},
+		}}, nil)
+	case "Create", "Update", "PartialUpdate":
+		input := r.messages.ofStruct(serializer, typeName(serializer) + "Input", true)
+		addField(&field{name: "input", kind: "message", message: input}, "body")
+	case "Destroy":
+		m.response = nil
+	}
+	for _, match := range params {
+		if match[1] != "pk" && validName.MatchString(match[1]) {
+			addField(&field{name: match[1], kind: "string"}, "path")
+		}
+	}
+	if name == "List" {
+		for _, f := range r.filterFields(filter, base) {
+			if _, ok := m.in[f.name]; !ok {
+				addField(f, "query")
+			}
+		}
+	}
+	m.request = r.messages.add(request, nil)
+	return m
+}
+
+// filterFields returns fields of filterset params, named like echo binds them.
+func (r *registry) filterFields(filter reflect.Type, base string) []*field {
+	if filter == nil || filter.Kind() != reflect.Struct {
+		return nil
+	}
+	fields := []*field{}
+	for i := 0; i < filter.NumField(); i++ {
+		structField := filter.Field(i)
+		if structField.Type.Name() == "FilterSet" || !structField.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(structField.Tag.Get("query"), ",")
+		if name == "" {
+			name = structField.Name
+		}
+		typ := indirect(structField.Type)
+		if !validName.MatchString(name) || (typ.Kind() == reflect.Struct && typ != timeType) {
+			continue
+		}
+		f := r.messages.fieldOf(structField.Type, base + "Filter" + structField.Name, false)
+		f.name = name
+		f.optional = !f.repeated
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+func typeName(typ reflect.Type) string {
+	return genericArgs.ReplaceAllString(typ.Name(), "")
+}
+
+// proto returns the definitions in proto3 syntax.
+func (r *registry) proto() string {
+	proto := &strings.Builder{}
+	proto.WriteString("// Code generated by gorim from viewsets. DO NOT EDIT.\n\n")
+	proto.WriteString("syntax = \"proto3\";\n\n")
+	fmt.Fprintf(proto, "package %s;\n\n", r.pkg)
+	proto.WriteString("import \"google/protobuf/empty.proto\";\n")
+	for _, svc := range r.services {
+		fmt.Fprintf(proto, "\nservice %s {\n", svc.name)
+		for _, m := range svc.methods {
+			response := "google.protobuf.Empty"
+			if m.response != nil {
+				response = m.response.name
+			}
+			fmt.Fprintf(proto, "  // %s %s\n", m.route.Method, m.route.Path)
+			fmt.Fprintf(proto, "  rpc %s(%s) returns (%s);\n", m.name, m.request.name, response)
+		}
+		proto.WriteString("}\n")
+	}
+	for _, name := range r.messages.names {
+		proto.WriteString("\n")
+		writeDefinition(proto, r.messages.byName[name])
+	}
+	return proto.String()
+}
@@ -0,0 +1,301 @@
+package grpc
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// decode reads protobuf message into JSON values of its fields, unknown fields are skipped.
+func decode(msg *message, data []byte) (map[string]any, error) {
+	values := map[string]any{}
+	for len(data) > 0 {
+		number, wireType, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		data = data[n:]
+		f := msg.field(int(number))
+		if f == nil {
+			n = protowire.ConsumeFieldValue(number, wireType, data)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			data = data[n:]
+			continue
+		}
+		if f.repeated && wireType == protowire.BytesType && isPackable(f.kind) {
+			packed, n := protowire.ConsumeBytes(data)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			data = data[n:]
+			items, _ := values[f.name].([]any)
+			for len(packed) > 0 {
+				item, n, err := decodeValue(f, wireTypeOf(f.kind), packed)
+				if err != nil {
+					return nil, err
+				}
+				packed = packed[n:]
+				items = append(items, item)
+			}
+			values[f.name] = items
+			continue
+		}
+		value, n, err := decodeValue(f, wireType, data)
+		if err != nil {
+			return nil, err
+		}
+		data = data[n:]
+		if f.repeated {
+			items, _ := values[f.name].([]any)
+			values[f.name] = append(items, value)
+		} else {
+			values[f.name] = value
+		}
+	}
+	return values, nil
+}
+
+// decodeValue reads a single value of field and returns the number of bytes read.
+func decodeValue(f *field, wireType protowire.Type, data []byte) (any, int, error) {
+	if wireType != wireTypeOf(f.kind) {
+		return nil, 0, fmt.Errorf("field %s has wire type %d, expected %d", f.name, wireType, wireTypeOf(f.kind))
+	}
+	switch wireType {
+	case protowire.VarintType:
+		v, n := protowire.ConsumeVarint(data)
+		if n < 0 {
+			return nil, 0, protowire.ParseError(n)
+		}
+		switch f.kind {
+		case "bool":
+			return v != 0, n, nil
+		case "int32":
+			return json.Number(strconv.FormatInt(int64(int32(v)), 10)), n, nil
+		case "int64":
+			return json.Number(strconv.FormatInt(int64(v), 10)), n, nil
+		case "uint32":
+			return json.Number(strconv.FormatUint(uint64(uint32(v)), 10)), n, nil
+		}
+		return json.Number(strconv.FormatUint(v, 10)), n, nil
+	case protowire.Fixed32Type:
+		v, n := protowire.ConsumeFixed32(data)
+		if n < 0 {
+			return nil, 0, protowire.ParseError(n)
+		}
+		return json.Number(strconv.FormatFloat(float64(math.Float32frombits(v)), 'g', -1, 32)), n, nil
+	case protowire.Fixed64Type:
+		v, n := protowire.ConsumeFixed64(data)
+		if n < 0 {
+			return nil, 0, protowire.ParseError(n)
+		}
+		return json.Number(strconv.FormatFloat(math.Float64frombits(v), 'g', -1, 64)), n, nil
+	}
+	v, n := protowire.ConsumeBytes(data)
+	if n < 0 {
+		return nil, 0, protowire.ParseError(n)
+	}
+	switch f.kind {
+	case "bytes":
+		return base64.StdEncoding.EncodeToString(v), n, nil
+	case "json":
+		var value any
+		if err := json.Unmarshal(v, &value); err != nil {
+			return nil, 0, fmt.Errorf("field %s must be a string of JSON", f.name)
+		}
+		return value, n, nil
+	case "message":
+		value, err := decode(f.message, v)
+		return value, n, err
+	}
+	return string(v), n, nil
+}
+
+// encode writes JSON values as protobuf message, keys without field and nulls are
+// skipped, and so are zero values of fields without presence.
+func encode(msg *message, values map[string]any) ([]byte, error) {
+	var data []byte
+	for _, f := range msg.fields {
+		value, ok := values[f.name]
+		if !ok || value == nil {
+			continue
+		}
+		if !f.repeated {
+			encoded, err := encodeField(data, f, value)
+			if err != nil {
+				return nil, err
+			}
+			data = encoded
+			continue
+		}
+		items, ok := value.([]any)
+		if !ok {
+			return nil, fmt.Errorf("field %s must be a list", f.name)
+		}
+		if len(items) == 0 {
+			continue
+		}
+		if isPackable(f.kind) {
+			var packed []byte
+			for _, item := range items {
+				encoded, err := appendValue(packed, f, item)
+				if err != nil {
+					return nil, err
+				}
+				packed = encoded
+			}
+			data = protowire.AppendTag(data, protowire.Number(f.number), protowire.BytesType)
+			data = protowire.AppendBytes(data, packed)
+			continue
+		}
+		for _, item := range items {
+			if item == nil {
+				continue
+			}
+			encoded, err := encodeField(data, f, item)
+			if err != nil {
+				return nil, err
+			}
+			data = encoded
+		}
+	}
+	return data, nil
+}
+
+func encodeField(data []byte, f *field, value any) ([]byte, error) {
+	if !f.optional && !f.repeated && isZero(f, value) {
+		return data, nil
+	}
+	data = protowire.AppendTag(data, protowire.Number(f.number), wireTypeOf(f.kind))
+	return appendValue(data, f, value)
+}
+
+// appendValue writes value of field without tag.
+func appendValue(data []byte, f *field, value any) ([]byte, error) {
+	switch f.kind {
+	case "bool":
+		b, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("field %s must be a boolean", f.name)
+		}
+		return protowire.AppendVarint(data, protowire.EncodeBool(b)), nil
+	case "int32", "int64":
+		v, err := toInt(value)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", f.name, err)
+		}
+		return protowire.AppendVarint(data, uint64(v)), nil
+	case "uint32", "uint64":
+		v, err := toInt(value)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", f.name, err)
+		}
+		return protowire.AppendVarint(data, uint64(v)), nil
+	case "float":
+		v, err := toFloat(value)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", f.name, err)
+		}
+		return protowire.AppendFixed32(data, math.Float32bits(float32(v))), nil
+	case "double":
+		v, err := toFloat(value)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", f.name, err)
+		}
+		return protowire.AppendFixed64(data, math.Float64bits(v)), nil
+	case "bytes":
+		s, _ := value.(string)
+		v, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("field %s must be base64: %w", f.name, err)
+		}
+		return protowire.AppendBytes(data, v), nil
+	case "json":
+		v, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		return protowire.AppendBytes(data, v), nil
+	case "message":
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("field %s must be an object", f.name)
+		}
+		v, err := encode(f.message, object)
+		if err != nil {
+			return nil, err
+		}
+		return protowire.AppendBytes(data, v), nil
+	}
+	if s, ok := value.(string); ok {
+		return protowire.AppendString(data, s), nil
+	}
+	return protowire.AppendString(data, fmt.Sprint(value)), nil
+}
+
+func isZero(f *field, value any) bool {
+	switch value := value.(type) {
+	case bool:
+		return !value
+	case string:
+		return value == "" && f.kind != "json"
+	case json.Number:
+		v, err := value.Float64()
+		return err == nil && v == 0 && f.kind != "string" && f.kind != "json"
+	}
+	return false
+}
+
+func toInt(value any) (int64, error) {
+	switch value := value.(type) {
+	case json.Number:
+		if v, err := value.Int64(); err == nil {
+			return v, nil
+		}
+		if v, err := strconv.ParseUint(string(value), 10, 64); err == nil {
+			return int64(v), nil
+		}
+		v, err := value.Float64()
+		return int64(v), err
+	case string:
+		return strconv.ParseInt(value, 10, 64)
+	case bool:
+		if value {
+			return 1, nil
+		}
+		return 0, nil
+	}
+	return 0, fmt.Errorf("%v is not an integer", value)
+}
+
+func toFloat(value any) (float64, error) {
+	switch value := value.(type) {
+	case json.Number:
+		return value.Float64()
+	case string:
+		return strconv.ParseFloat(value, 64)
+	}
+	return 0, fmt.Errorf("%v is not a number", value)
+}
+
+func wireTypeOf(kind string) protowire.Type {
+	switch kind {
+	case "bool", "int32", "int64", "uint32", "uint64":
+		return protowire.VarintType
+	case "float":
+		return protowire.Fixed32Type
+	case "double":
+		return protowire.Fixed64Type
+	}
+	return protowire.BytesType
+}
+
+// isPackable reports scalars written as packed repeated fields, the proto3 default.
+func isPackable(kind string) bool {
+	return wireTypeOf(kind) != protowire.BytesType
+}
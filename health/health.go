@@ -0,0 +1,111 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rimba47prayoga/gorim.git"
+)
+
+// Checker reports health of a dependency, nil error when it's healthy.
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc is an adapter to use ordinary function as Checker.
+type CheckerFunc func(ctx context.Context) error
+
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Timeout limits each check run by View.
+var Timeout = 5 * time.Second
+
+var (
+	checkersMu	sync.RWMutex
+	checkers	= map[string]Checker{}
+)
+
+// Register adds checker under name, registering the same name replaces it.
+func Register(name string, checker Checker) {
+	checkersMu.Lock()
+	checkers[name] = checker
+	checkersMu.Unlock()
+}
+
+func Unregister(name string) {
+	checkersMu.Lock()
+	delete(checkers, name)
+	checkersMu.Unlock()
+}
+
+type Check struct {
+	Status		string		`json:"status"`
+	Error		string		`json:"error,omitempty"`
+	Duration	string		`json:"duration"`
+}
+
+type Report struct {
+	Status		string				`json:"status"`
+	Checks		map[string]Check	`json:"checks"`
+}
+
+func (r Report) Healthy() bool {
+	return r.Status == "ok"
+}
+
+// Run runs registered checkers concurrently.
+func Run(ctx context.Context) Report {
+	checkersMu.RLock()
+	names := make([]string, 0, len(checkers))
+	for name := range checkers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	registered := map[string]Checker{}
+	for _, name := range names {
+		registered[name] = checkers[name]
+	}
+	checkersMu.RUnlock()
+
+	report := Report{Status: "ok", Checks: map[string]Check{}}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, checker := range registered {
+		wg.Add(1)
+		go func(name string, checker Checker) {
+			defer wg.Done()
+			start := time.Now()
+			err := checker.Check(ctx)
+			check := Check{Status: "ok", Duration: time.Since(start).String()}
+			if err != nil {
+				check.Status = "error"
+				check.Error = err.Error()
+			}
+			mu.Lock()
+			report.Checks[name] = check
+			if err != nil {
+				report.Status = "error"
+			}
+			mu.Unlock()
+		}(name, checker)
+	}
+	wg.Wait()
+	return report
+}
+
+// View responds the report, 503 when a check fails, example: server.GET("/health", health.View)
+func View(c gorim.Context) error {
+	ctx, cancel := context.WithTimeout(c.Request().Context(), Timeout)
+	defer cancel()
+	report := Run(ctx)
+	status := http.StatusOK
+	if !report.Healthy() {
+		status = http.StatusServiceUnavailable
+	}
+	return c.Respond(status, report)
+}
@@ -0,0 +1,163 @@
+package hooks
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Event of the request lifecycle, sent by the server, routers and RecoverMiddleware.
+type Event int
+
+const (
+	// RequestStarted is sent before routing, Context has no route yet.
+	RequestStarted Event = iota
+	// Routed is sent after the route of the request is found, see Context.Path().
+	Routed
+	// PreAction is sent before routers call a viewset action, permissions and throttles passed.
+	PreAction
+	// PostAction is sent after the viewset action returned, Err is its error.
+	PostAction
+	// ResponseWritten is sent after the response is written, errors of handlers included.
+	ResponseWritten
+	// Panic is sent when RecoverMiddleware recovers a panic rendered as 500.
+	Panic
+)
+
+func (e Event) String() string {
+	switch e {
+	case RequestStarted:
+		return "request_started"
+	case Routed:
+		return "routed"
+	case PreAction:
+		return "pre_action"
+	case PostAction:
+		return "post_action"
+	case ResponseWritten:
+		return "response_written"
+	case Panic:
+		return "panic"
+	}
+	return "unknown"
+}
+
+// Info describes the request an event is sent for.
+type Info struct {
+	Event		Event
+	Context		echo.Context
+	// Started is when the request started, Duration is the time since.
+	Started		time.Time
+	Duration	time.Duration
+	// View and Action of PreAction and PostAction, example: "ProductViewSet", "List".
+	View		string
+	Action		string
+	// Err is the error of PostAction and ResponseWritten, the recovered error of Panic.
+	Err			error
+	// Stack of Panic.
+	Stack		[]byte
+}
+
+// Handler of an event, handlers run in the request, so they should be quick.
+type Handler func(*Info)
+
+type subscription struct {
+	handler	Handler
+}
+
+var (
+	mu			sync.Mutex
+	// handlers are replaced on changes, so Emit reads them without locks.
+	handlers	[Panic + 1]atomic.Pointer[[]*subscription]
+)
+
+// Subscribe adds handler of event, handlers run in the order they're added,
+// unsubscribe removes it, example:
+//
+//	hooks.Subscribe(hooks.ResponseWritten, func(info *hooks.Info) {
+//		latency.WithLabelValues(info.Context.Path()).Observe(info.Duration.Seconds())
+//	})
+func Subscribe(event Event, handler Handler) (unsubscribe func()) {
+	sub := &subscription{handler: handler}
+	mu.Lock()
+	defer mu.Unlock()
+	current := handlers[event].Load()
+	subs := []*subscription{}
+	if current != nil {
+		subs = append(subs, *current...)
+	}
+	subs = append(subs, sub)
+	handlers[event].Store(&subs)
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		current := handlers[event].Load()
+		if current == nil {
+			return
+		}
+		subs := []*subscription{}
+		for _, other := range *current {
+			if other != sub {
+				subs = append(subs, other)
+			}
+		}
+		handlers[event].Store(&subs)
+	}
+}
+
+// Has reports whether event has handlers, so senders skip building Info.
+func Has(event Event) bool {
+	subs := handlers[event].Load()
+	return subs != nil && len(*subs) > 0
+}
+
+const startedContextKey = "gorim:hooks_started"
+
+// Emit sends event of request c to handlers, info may be nil, used by the framework.
+func Emit(event Event, c echo.Context, info *Info) {
+	subs := handlers[event].Load()
+	if subs == nil || len(*subs) == 0 {
+		return
+	}
+	if info == nil {
+		info = &Info{}
+	}
+	info.Event = event
+	info.Context = c
+	if started, ok := c.Get(startedContextKey).(time.Time); ok {
+		info.Started = started
+		info.Duration = time.Since(started)
+	}
+	for _, sub := range *subs {
+		sub.handler(info)
+	}
+}
+
+// Middleware sends RequestStarted and ResponseWritten, added by gorim.New as a Pre
+// middleware so it wraps routing and every other middleware. Errors of handlers are
+// rendered here, so ResponseWritten sees the status they're written with.
+func Middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		c.Set(startedContextKey, time.Now())
+		Emit(RequestStarted, c, nil)
+		err := next(c)
+		if !Has(ResponseWritten) {
+			return err
+		}
+		if err != nil {
+			c.Error(err)
+		}
+		Emit(ResponseWritten, c, &Info{Err: err})
+		return nil
+	}
+}
+
+// RoutedMiddleware sends Routed, added by gorim.New before other middleware.
+func RoutedMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		Emit(Routed, c, nil)
+		return next(c)
+	}
+}
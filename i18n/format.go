@@ -0,0 +1,140 @@
+package i18n
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format of numbers and dates of a language, layouts are those of time.Format.
+type Format struct {
+	Decimal		string
+	// Group separates thousands, empty doesn't group.
+	Group		string
+	Date		string
+	Time		string
+	DateTime	string
+}
+
+var formats = map[string]Format{
+	"en":		{Decimal: ".", Group: ",", Date: "01/02/2006", Time: "3:04 PM", DateTime: "01/02/2006 3:04 PM"},
+	"en-gb":	{Decimal: ".", Group: ",", Date: "02/01/2006", Time: "15:04", DateTime: "02/01/2006 15:04"},
+	"id":		{Decimal: ",", Group: ".", Date: "02/01/2006", Time: "15.04", DateTime: "02/01/2006 15.04"},
+	"de":		{Decimal: ",", Group: ".", Date: "02.01.2006", Time: "15:04", DateTime: "02.01.2006 15:04"},
+	"fr":		{Decimal: ",", Group: " ", Date: "02/01/2006", Time: "15:04", DateTime: "02/01/2006 15:04"},
+	"es":		{Decimal: ",", Group: ".", Date: "02/01/2006", Time: "15:04", DateTime: "02/01/2006 15:04"},
+	"it":		{Decimal: ",", Group: ".", Date: "02/01/2006", Time: "15:04", DateTime: "02/01/2006 15:04"},
+	"pt":		{Decimal: ",", Group: ".", Date: "02/01/2006", Time: "15:04", DateTime: "02/01/2006 15:04"},
+	"nl":		{Decimal: ",", Group: ".", Date: "02-01-2006", Time: "15:04", DateTime: "02-01-2006 15:04"},
+	"ja":		{Decimal: ".", Group: ",", Date: "2006/01/02", Time: "15:04", DateTime: "2006/01/02 15:04"},
+	"zh":		{Decimal: ".", Group: ",", Date: "2006/01/02", Time: "15:04", DateTime: "2006/01/02 15:04"},
+}
+
+// RegisterFormat sets format of language, replacing the builtin one.
+func RegisterFormat(language string, format Format) {
+	mu.Lock()
+	defer mu.Unlock()
+	formats[normalize(language)] = format
+}
+
+// GetFormat returns format of language, "pt-br" falls back to "pt", then DefaultLanguage, then "en".
+func GetFormat(language string) Format {
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, candidate := range append(candidates(normalize(language)), candidates(normalize(DefaultLanguage))...) {
+		if format, ok := formats[candidate]; ok {
+			return format
+		}
+	}
+	return formats["en"]
+}
+
+// FormatNumber formats integers and floats with separators of language, floats are
+// rounded to decimals, negative decimals keep the digits needed, example:
+// i18n.FormatNumber("id", 1234.5, 2) => "1.234,50".
+func FormatNumber(language string, value any, decimals int) string {
+	var digits string
+	switch value := value.(type) {
+	case int:
+		digits = strconv.FormatInt(int64(value), 10)
+	case int8:
+		digits = strconv.FormatInt(int64(value), 10)
+	case int16:
+		digits = strconv.FormatInt(int64(value), 10)
+	case int32:
+		digits = strconv.FormatInt(int64(value), 10)
+	case int64:
+		digits = strconv.FormatInt(value, 10)
+	case uint:
+		digits = strconv.FormatUint(uint64(value), 10)
+	case uint8:
+		digits = strconv.FormatUint(uint64(value), 10)
+	case uint16:
+		digits = strconv.FormatUint(uint64(value), 10)
+	case uint32:
+		digits = strconv.FormatUint(uint64(value), 10)
+	case uint64:
+		digits = strconv.FormatUint(value, 10)
+	case float32:
+		digits = formatFloat(float64(value), decimals, 32)
+	case float64:
+		digits = formatFloat(value, decimals, 64)
+	case fmt.Stringer:
+		// example: json.Number and decimal types.
+		digits = value.String()
+	default:
+		return fmt.Sprint(value)
+	}
+	return localizeDigits(GetFormat(language), digits)
+}
+
+func formatFloat(value float64, decimals int, bitSize int) string {
+	if math.IsInf(value, 0) || math.IsNaN(value) {
+		return strconv.FormatFloat(value, 'f', -1, bitSize)
+	}
+	return strconv.FormatFloat(value, 'f', decimals, bitSize)
+}
+
+// localizeDigits replaces separators of a number formatted by strconv.
+func localizeDigits(format Format, digits string) string {
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+	integer, fraction, hasFraction := strings.Cut(digits, ".")
+	if _, err := strconv.ParseUint(integer, 10, 64); err != nil {
+		// not a plain number, example: NaN or exponent.
+		return sign + digits
+	}
+	if format.Group != "" && len(integer) > 3 {
+		var grouped strings.Builder
+		for i, digit := range integer {
+			if i > 0 && (len(integer) - i) % 3 == 0 {
+				grouped.WriteString(format.Group)
+			}
+			grouped.WriteRune(digit)
+		}
+		integer = grouped.String()
+	}
+	if hasFraction {
+		return sign + integer + format.Decimal + fraction
+	}
+	return sign + integer
+}
+
+// FormatDate formats date of t in language.
+func FormatDate(language string, t time.Time) string {
+	return t.Format(GetFormat(language).Date)
+}
+
+// FormatTime formats time of day of t in language.
+func FormatTime(language string, t time.Time) string {
+	return t.Format(GetFormat(language).Time)
+}
+
+// FormatDateTime formats date and time of t in language.
+func FormatDateTime(language string, t time.Time) string {
+	return t.Format(GetFormat(language).DateTime)
+}
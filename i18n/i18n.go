@@ -0,0 +1,274 @@
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+)
+
+// LanguageContextKey stores the language resolved for the request.
+const LanguageContextKey = "language"
+
+// userContextKey is the same as gorim.UserContextKey.
+const userContextKey = "user"
+
+// DefaultLanguage is used when neither the user nor Accept-Language selects a registered language.
+var DefaultLanguage = "en"
+
+// LanguageParam is the query param activating a registered language, example: ?lang=id,
+// empty disables it. It takes precedence over LanguageCookie, the user and Accept-Language.
+var LanguageParam = ""
+
+// LanguageCookie is the cookie activating a registered language, empty disables it.
+var LanguageCookie = ""
+
+// LanguageUser is implemented by user models with a preferred language,
+// it takes precedence over Accept-Language.
+type LanguageUser interface {
+	GetLanguage() string
+}
+
+var (
+	mu			sync.RWMutex
+	catalogs	= map[string]map[string]string{}
+)
+
+// Register adds translations of message ids to language, message id is the english message,
+// example: i18n.Register("id", map[string]string{"This field is required.": "Bidang ini wajib diisi."}).
+func Register(language string, messages map[string]string) {
+	language = normalize(language)
+	mu.Lock()
+	defer mu.Unlock()
+	catalog, ok := catalogs[language]
+	if !ok {
+		catalog = map[string]string{}
+		catalogs[language] = catalog
+	}
+	for id, message := range messages {
+		catalog[id] = message
+	}
+}
+
+// LoadFile registers translations from json object of message id => translation.
+func LoadFile(language string, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	messages := map[string]string{}
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return fmt.Errorf("i18n: %s: %w", path, err)
+	}
+	Register(language, messages)
+	return nil
+}
+
+// LoadFS registers catalogs of json files in the root of fsys named by language,
+// example: i18n.LoadFS(os.DirFS("locale")) loads locale/id.json and locale/pt-br.json.
+func LoadFS(fsys fs.FS) error {
+	paths, err := fs.Glob(fsys, "*.json")
+	if err != nil {
+		return err
+	}
+	for _, name := range paths {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		messages := map[string]string{}
+		if err := json.Unmarshal(data, &messages); err != nil {
+			return fmt.Errorf("i18n: %s: %w", name, err)
+		}
+		Register(strings.TrimSuffix(path.Base(name), ".json"), messages)
+	}
+	return nil
+}
+
+// Languages returns registered languages.
+func Languages() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	languages := make([]string, 0, len(catalogs))
+	for language := range catalogs {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// Lookup returns translation of id, "pt-br" falls back to "pt".
+func Lookup(language string, id string) (string, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, candidate := range candidates(normalize(language)) {
+		if message, ok := catalogs[candidate][id]; ok {
+			return message, true
+		}
+	}
+	return "", false
+}
+
+// Translate returns translation of id formatted with args, id itself when it has no translation.
+func Translate(language string, id string, args ...any) string {
+	message, ok := Lookup(language, id)
+	if !ok {
+		message = id
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+	return message
+}
+
+// Plural translates singular when n is 1 and plural otherwise, formatted with args,
+// example: i18n.Plural(language, "%d item", "%d items", n, n).
+// Catalogs translate both ids, languages without plural forms translate them alike.
+func Plural(language string, singular string, plural string, n int, args ...any) string {
+	if n == 1 {
+		return Translate(language, singular, args...)
+	}
+	return Translate(language, plural, args...)
+}
+
+// T translates id to the language of the request.
+func T(c echo.Context, id string, args ...any) string {
+	return Translate(Language(c), id, args...)
+}
+
+// Activate sets the language of the request, it takes precedence over every other
+// source, example: from the path of localized routes.
+func Activate(c echo.Context, language string) {
+	c.Set(LanguageContextKey, normalize(language))
+}
+
+// Language resolves language of the request from LanguageParam, LanguageCookie,
+// LanguageUser, then Accept-Language, the result is cached on the context.
+func Language(c echo.Context) string {
+	if c == nil {
+		return DefaultLanguage
+	}
+	if language, ok := c.Get(LanguageContextKey).(string); ok && language != "" {
+		return language
+	}
+	language := ""
+	if LanguageParam != "" {
+		language = supported(c.QueryParam(LanguageParam))
+	}
+	if language == "" && LanguageCookie != "" {
+		if cookie, err := c.Cookie(LanguageCookie); err == nil {
+			language = supported(cookie.Value)
+		}
+	}
+	activated := language != ""
+	if user, ok := c.Get(userContextKey).(LanguageUser); ok && language == "" {
+		language = normalize(user.GetLanguage())
+	}
+	if language == "" {
+		language = Match(c.Request().Header.Get("Accept-Language"))
+	}
+	// anonymous result isn't cached since the user may be authenticated later.
+	if activated || c.Get(userContextKey) != nil {
+		c.Set(LanguageContextKey, language)
+	}
+	return language
+}
+
+// Match returns the registered language preferred by Accept-Language header, DefaultLanguage if none.
+func Match(acceptLanguage string) string {
+	for _, tag := range parseAcceptLanguage(acceptLanguage) {
+		if language := supported(tag); language != "" {
+			return language
+		}
+	}
+	return normalize(DefaultLanguage)
+}
+
+// supported returns the registered language of tag or its base language, empty if none.
+func supported(tag string) string {
+	tag = normalize(tag)
+	if tag == "" {
+		return ""
+	}
+	for _, candidate := range candidates(tag) {
+		if candidate == normalize(DefaultLanguage) || hasCatalog(candidate) {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// Middleware sets Content-Language of the response, translations are resolved lazily by Language.
+func Middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		c.Response().Header().Add(echo.HeaderVary, "Accept-Language")
+		if LanguageCookie != "" {
+			c.Response().Header().Add(echo.HeaderVary, "Cookie")
+		}
+		c.Response().Before(func() {
+			c.Response().Header().Set("Content-Language", Language(c))
+		})
+		return next(c)
+	}
+}
+
+func hasCatalog(language string) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	_, ok := catalogs[language]
+	return ok
+}
+
+// parseAcceptLanguage returns language tags ordered by quality, example:
+// "da, en-GB;q=0.8, en;q=0.7" => ["da", "en-gb", "en"].
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag		string
+		quality	float64
+	}
+	tags := []weighted{}
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = normalize(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if q, err := strconv.ParseFloat(value, 64); err == nil {
+				quality = q
+			}
+		}
+		if quality <= 0 {
+			continue
+		}
+		tags = append(tags, weighted{tag, quality})
+	}
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].quality > tags[j].quality
+	})
+	result := make([]string, len(tags))
+	for i, tag := range tags {
+		result[i] = tag.tag
+	}
+	return result
+}
+
+// candidates returns the language followed by its base language.
+func candidates(language string) []string {
+	if base, _, ok := strings.Cut(language, "-"); ok {
+		return []string{language, base}
+	}
+	return []string{language}
+}
+
+func normalize(language string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(language), "_", "-"))
+}
@@ -0,0 +1,25 @@
+package i18n
+
+import (
+	"os"
+
+	"github.com/rimba47prayoga/gorim.git/settings"
+)
+
+// FromSettings configures languages from settings.Get().I18n and loads catalogs of
+// its locale directory when it exists.
+func FromSettings() error {
+	config := settings.Get().I18n
+	if config.Language != "" {
+		DefaultLanguage = config.Language
+	}
+	LanguageParam = config.Param
+	LanguageCookie = config.Cookie
+	if config.LocaleDir == "" {
+		return nil
+	}
+	if _, err := os.Stat(config.LocaleDir); os.IsNotExist(err) {
+		return nil
+	}
+	return LoadFS(os.DirFS(config.LocaleDir))
+}
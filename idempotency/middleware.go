@@ -0,0 +1,157 @@
+package idempotency
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/errors"
+	"github.com/rimba47prayoga/gorim.git/permissions"
+	"github.com/rimba47prayoga/gorim.git/utils"
+)
+
+const ReplayedHeader = "Idempotent-Replayed"
+
+// Config of Middleware.
+type Config struct {
+	Store		Store
+	// Header carrying the key, default "Idempotency-Key".
+	Header		string
+	// TTL of stored responses, default 24 hours.
+	TTL			time.Duration
+	// Scope separates keys of different clients, default user ID or client IP.
+	Scope		func(gorim.Context) string
+}
+
+var DefaultStore Store = NewMemoryStore()
+
+// Middleware stores the response of unsafe requests sent with Idempotency-Key header
+// and replays it on retries with the same key, so a retried Create doesn't create twice.
+// Server errors aren't stored so the request can be retried, it must run after authentication.
+func Middleware(config Config) echo.MiddlewareFunc {
+	if config.Store == nil {
+		config.Store = DefaultStore
+	}
+	if config.Header == "" {
+		config.Header = "Idempotency-Key"
+	}
+	if config.TTL == 0 {
+		config.TTL = 24 * time.Hour
+	}
+	if config.Scope == nil {
+		config.Scope = defaultScope
+	}
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			request := c.Request()
+			idempotencyKey := request.Header.Get(config.Header)
+			if idempotencyKey == "" || utils.Contains(permissions.SafeMethods, request.Method) {
+				return next(c)
+			}
+			if len(idempotencyKey) > 255 {
+				return errors.Handle(&errors.APIError{Status: http.StatusBadRequest, Message: fmt.Sprintf("%s must be at most 255 characters.", config.Header)}, c)
+			}
+			body, err := io.ReadAll(request.Body)
+			if err != nil {
+				return err
+			}
+			request.Body = io.NopCloser(bytes.NewReader(body))
+			fingerprint := fingerprint(request, body)
+			key := config.Scope(gorim.NewContext(c)) + ":" + idempotencyKey
+
+			locked, err := config.Store.Lock(key, &Record{Fingerprint: fingerprint}, config.TTL)
+			if err != nil {
+				return err
+			}
+			if !locked {
+				record, err := config.Store.Get(key)
+				if err != nil {
+					return err
+				}
+				return replay(c, record, fingerprint)
+			}
+
+			recorder := &responseRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = recorder
+			defer func() {
+				c.Response().Writer = recorder.ResponseWriter
+				if r := recover(); r != nil {
+					config.Store.Delete(key)
+					panic(r)
+				}
+			}()
+			err = next(c)
+			status := c.Response().Status
+			if err != nil || !c.Response().Committed || status >= http.StatusInternalServerError {
+				config.Store.Delete(key)
+				return err
+			}
+			record := &Record{
+				Fingerprint: fingerprint,
+				Completed: true,
+				Status: status,
+				Header: c.Response().Header().Clone(),
+				Body: recorder.body.Bytes(),
+			}
+			if err := config.Store.Save(key, record, config.TTL); err != nil {
+				c.Logger().Error(err)
+			}
+			return nil
+		}
+	}
+}
+
+func replay(c echo.Context, record *Record, fingerprint string) error {
+	if record == nil {
+		// expired between Lock and Get.
+		return errors.Handle(&errors.APIError{Status: http.StatusConflict, Message: "A request with this Idempotency-Key is in progress."}, c)
+	}
+	if record.Fingerprint != fingerprint {
+		return errors.Handle(&errors.APIError{Status: http.StatusUnprocessableEntity, Message: "Idempotency-Key was used with a different request."}, c)
+	}
+	if !record.Completed {
+		return errors.Handle(&errors.APIError{Status: http.StatusConflict, Message: "A request with this Idempotency-Key is in progress."}, c)
+	}
+	header := c.Response().Header()
+	for name, values := range record.Header {
+		header[name] = values
+	}
+	header.Set(ReplayedHeader, "true")
+	c.Response().WriteHeader(record.Status)
+	_, err := c.Response().Write(record.Body)
+	return err
+}
+
+func fingerprint(request *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(request.Method + " " + request.URL.RequestURI() + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func defaultScope(ctx gorim.Context) string {
+	if userID, ok := utils.GetUserID(ctx.User()); ok {
+		return fmt.Sprintf("user_%d", userID)
+	}
+	return "ip_" + ctx.RealIP()
+}
+
+type responseRecorder struct {
+	http.ResponseWriter
+	body		bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,169 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Record is the response stored for an idempotency key.
+type Record struct {
+	// Fingerprint of the request, a key reused for a different request is rejected.
+	Fingerprint		string		`json:"fingerprint"`
+	// Completed is false while the first request is in progress.
+	Completed		bool		`json:"completed"`
+	Status			int			`json:"status"`
+	Header			http.Header	`json:"header"`
+	Body			[]byte		`json:"body"`
+}
+
+// Store keeps records per key until ttl.
+type Store interface {
+	Get(key string) (*Record, error)
+	// Lock saves in progress record if the key is absent, returns false otherwise.
+	Lock(key string, record *Record, ttl time.Duration) (bool, error)
+	Save(key string, record *Record, ttl time.Duration) error
+	Delete(key string) error
+}
+
+type memoryEntry struct {
+	record		Record
+	expiresAt	time.Time
+}
+
+// MemoryStore keeps records in process memory, use RedisStore with several processes.
+// Expired records are removed when read and by sweeps every sweepInterval writes.
+type MemoryStore struct {
+	mu			sync.Mutex
+	entries		map[string]memoryEntry
+	writes		int
+}
+
+// sweepInterval is the number of writes between sweeps of expired records.
+const sweepInterval = 1000
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: map[string]memoryEntry{},
+	}
+}
+
+func (s *MemoryStore) get(key string) (*Record, bool) {
+	entry, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(s.entries, key)
+		return nil, false
+	}
+	record := entry.record
+	return &record, true
+}
+
+func (s *MemoryStore) Get(key string) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, _ := s.get(key)
+	return record, nil
+}
+
+func (s *MemoryStore) Lock(key string, record *Record, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.get(key); ok {
+		return false, nil
+	}
+	s.set(key, record, ttl)
+	return true, nil
+}
+
+func (s *MemoryStore) Save(key string, record *Record, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.set(key, record, ttl)
+	return nil
+}
+
+func (s *MemoryStore) set(key string, record *Record, ttl time.Duration) {
+	now := time.Now()
+	s.entries[key] = memoryEntry{record: *record, expiresAt: now.Add(ttl)}
+	s.writes++
+	if s.writes % sweepInterval == 0 {
+		s.sweep(now)
+	}
+}
+
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, entry := range s.entries {
+		if now.After(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+func (s *MemoryStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+	return nil
+}
+
+// RedisStore keeps records as json in redis.
+type RedisStore struct {
+	Client		redis.UniversalClient
+	// Prefix of redis keys, default "gorim:idempotency:".
+	Prefix		string
+}
+
+func NewRedisStore(client redis.UniversalClient) *RedisStore {
+	return &RedisStore{
+		Client: client,
+	}
+}
+
+func (s *RedisStore) key(key string) string {
+	if s.Prefix == "" {
+		return "gorim:idempotency:" + key
+	}
+	return s.Prefix + key
+}
+
+func (s *RedisStore) Get(key string) (*Record, error) {
+	data, err := s.Client.Get(context.Background(), s.key(key)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var record Record
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (s *RedisStore) Lock(key string, record *Record, ttl time.Duration) (bool, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return false, err
+	}
+	return s.Client.SetNX(context.Background(), s.key(key), data, ttl).Result()
+}
+
+func (s *RedisStore) Save(key string, record *Record, ttl time.Duration) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return s.Client.Set(context.Background(), s.key(key), data, ttl).Err()
+}
+
+func (s *RedisStore) Delete(key string) error {
+	return s.Client.Del(context.Background(), s.key(key)).Err()
+}
@@ -0,0 +1,104 @@
+package images
+
+import (
+	stderrors "errors"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/errors"
+	"github.com/rimba47prayoga/gorim.git/storages"
+)
+
+// generation serializes generation of a variant by concurrent requests, it's removed
+// when its last request is done so variants don't stay in memory.
+type generation struct {
+	mu			sync.Mutex
+	requests	int
+}
+
+var (
+	generatingMu	sync.Mutex
+	generating		= map[string]*generation{}
+)
+
+// lockVariant waits for other requests generating name, the returned func unlocks it.
+func lockVariant(name string) func() {
+	generatingMu.Lock()
+	g, ok := generating[name]
+	if !ok {
+		g = &generation{}
+		generating[name] = g
+	}
+	g.requests++
+	generatingMu.Unlock()
+	g.mu.Lock()
+	return func() {
+		g.mu.Unlock()
+		generatingMu.Lock()
+		g.requests--
+		if g.requests == 0 {
+			delete(generating, name)
+		}
+		generatingMu.Unlock()
+	}
+}
+
+// Handler serves variants of images in storages.Default() by ":variant" and "*" params,
+// generating them on their first request, example: /images/large/products/1.png.
+func Handler(c gorim.Context) error {
+	name := storages.CleanName(c.Param("*"))
+	variant := Lookup(name, c.Param("variant"))
+	if variant == nil {
+		return notFound(c)
+	}
+	storage := storages.Default()
+	variantName := Name(name, variant)
+	unlock := lockVariant(variantName)
+	err := Generate(c.Request().Context(), storage, name, variant)
+	unlock()
+	if stderrors.Is(err, fs.ErrNotExist) || stderrors.Is(err, ErrInvalidImage) {
+		return notFound(c)
+	}
+	if err != nil {
+		return errors.Handle(err, c)
+	}
+	if _, ok := storage.(*storages.FileSystemStorage); !ok {
+		// variants don't change, remote storages serve them.
+		c.Response().Header().Set("Cache-Control", "public, max-age=86400")
+		return c.Redirect(http.StatusFound, storage.URL(variantName))
+	}
+	file, err := storage.Open(c.Request().Context(), variantName)
+	if err != nil {
+		return notFound(c)
+	}
+	defer file.Close()
+	c.Response().Header().Set("Cache-Control", "public, max-age=86400")
+	if seeker, ok := file.(io.ReadSeeker); ok {
+		http.ServeContent(c.Response(), c.Request(), path.Base(variantName), time.Time{}, seeker)
+		return nil
+	}
+	return c.Stream(http.StatusOK, mime.TypeByExtension(path.Ext(variantName)), file)
+}
+
+// Serve registers Handler of lazy variants at prefix, URLs of lazy variants point to it,
+// example: images.Serve(server, "/images/")
+func Serve(server *gorim.Server, prefix string, middleware ...echo.MiddlewareFunc) {
+	mu.Lock()
+	servedAt = prefix
+	mu.Unlock()
+	pattern := strings.TrimSuffix(prefix, "/") + "/:variant/*"
+	server.GET(pattern, Handler, middleware...)
+	server.AddRoute(http.MethodHead, pattern, Handler, middleware...)
+}
+
+func notFound(c gorim.Context) error {
+	return errors.Handle(&errors.ObjectNotFoundError{Message: "Not found."}, c)
+}
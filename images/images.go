@@ -0,0 +1,151 @@
+package images
+
+import (
+	"bytes"
+	"context"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/rimba47prayoga/gorim.git/storages"
+)
+
+// Variant of uploaded images, example: {Name: "thumb", Width: 150, Height: 150, Crop: true}.
+type Variant struct {
+	Name	string
+	// Width and Height of the box the image is resized to, zero follows the aspect ratio.
+	Width	int
+	Height	int
+	// Crop fills the box cutting the overflow, otherwise the image fits in the box.
+	Crop	bool
+	// Format of the variant, "jpeg", "png" or "gif", empty keeps the format of the image.
+	Format	string
+	// Quality of JPEG, default 85.
+	Quality	int
+	// Lazy variants are generated on their first request served by Serve instead of on upload.
+	Lazy	bool
+}
+
+func (v *Variant) GetFormat(sourceFormat string) string {
+	if v.Format != "" {
+		return v.Format
+	}
+	return sourceFormat
+}
+
+func (v *Variant) GetQuality() int {
+	if v.Quality > 0 {
+		return v.Quality
+	}
+	return 85
+}
+
+var (
+	mu			sync.RWMutex
+	variants	= map[string][]*Variant{}
+	// servedAt is the prefix of lazy variants set by Serve.
+	servedAt	= "/images/"
+)
+
+// Register sets variants of images uploaded to dir, the upload_to of serializer fields,
+// example:
+//
+//	images.Register("products",
+//		&images.Variant{Name: "thumb", Width: 150, Height: 150, Crop: true, Format: "jpeg"},
+//		&images.Variant{Name: "large", Width: 1200, Lazy: true},
+//	)
+func Register(dir string, imageVariants ...*Variant) {
+	mu.Lock()
+	defer mu.Unlock()
+	variants[storages.CleanName(dir)] = imageVariants
+}
+
+// Variants returns variants of image name by its directory.
+func Variants(name string) []*Variant {
+	mu.RLock()
+	defer mu.RUnlock()
+	return variants[path.Dir(storages.CleanName(name))]
+}
+
+// Lookup returns variant of image name, nil when its directory has no such variant.
+func Lookup(name string, variant string) *Variant {
+	for _, v := range Variants(name) {
+		if v.Name == variant {
+			return v
+		}
+	}
+	return nil
+}
+
+var extensions = map[string]string{"jpeg": ".jpg", "png": ".png", "gif": ".gif"}
+
+// Name returns name of variant of image name in storage,
+// example: "products/1.png" => "products/variants/thumb/1.jpg".
+func Name(name string, variant *Variant) string {
+	name = storages.CleanName(name)
+	ext := path.Ext(name)
+	if extension, ok := extensions[variant.Format]; ok {
+		ext = extension
+	}
+	base := strings.TrimSuffix(path.Base(name), path.Ext(name))
+	return path.Join(path.Dir(name), "variants", variant.Name, base + ext)
+}
+
+// URL returns URL of variant of image name, lazy variants are served by Serve.
+func URL(name string, variant *Variant) string {
+	if variant.Lazy {
+		mu.RLock()
+		prefix := servedAt
+		mu.RUnlock()
+		segments := strings.Split(storages.CleanName(name), "/")
+		for i, segment := range segments {
+			segments[i] = url.PathEscape(segment)
+		}
+		return strings.TrimSuffix(prefix, "/") + "/" + variant.Name + "/" + strings.Join(segments, "/")
+	}
+	return storages.Default().URL(Name(name, variant))
+}
+
+// URLs returns URLs of variants of image name by variant name.
+func URLs(name string) map[string]string {
+	urls := map[string]string{}
+	for _, variant := range Variants(name) {
+		urls[variant.Name] = URL(name, variant)
+	}
+	return urls
+}
+
+// Generate stores variant of image name, it's a no-op when the variant exists.
+func Generate(ctx context.Context, storage storages.Storage, name string, variant *Variant) error {
+	variantName := Name(name, variant)
+	exists, err := storage.Exists(ctx, variantName)
+	if err != nil || exists {
+		return err
+	}
+	source, err := storage.Open(ctx, name)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+	data, _, err := Process(source, variant)
+	if err != nil {
+		return err
+	}
+	_, err = storage.Save(ctx, variantName, bytes.NewReader(data))
+	return err
+}
+
+// OnUpload generates variants of image name which aren't lazy, used by serializers
+// after saving uploads, ErrInvalidImage is returned when the upload isn't an image.
+func OnUpload(ctx context.Context, storage storages.Storage, name string) error {
+	for _, variant := range Variants(name) {
+		if variant.Lazy {
+			continue
+		}
+		if err := Generate(ctx, storage, name, variant); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package images
+
+import (
+	"bytes"
+	stderrors "errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+)
+
+// ErrInvalidImage is returned for content which isn't a JPEG, PNG or GIF image.
+var ErrInvalidImage = stderrors.New("images: invalid image")
+
+// MaxPixels limits width times height of decoded images, so small files of huge
+// dimensions don't exhaust memory.
+var MaxPixels = 50_000_000
+
+// Process decodes src, resizes it by variant and encodes it, format is the format
+// of the result: "jpeg", "png" or "gif".
+func Process(src io.Reader, variant *Variant) ([]byte, string, error) {
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return nil, "", err
+	}
+	config, sourceFormat, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if config.Width * config.Height > MaxPixels {
+		return nil, "", fmt.Errorf("%w: %dx%d exceeds %d pixels", ErrInvalidImage, config.Width, config.Height, MaxPixels)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	img = Resize(img, variant.Width, variant.Height, variant.Crop)
+	format := variant.GetFormat(sourceFormat)
+	buffer := &bytes.Buffer{}
+	switch format {
+	case "jpeg":
+		err = jpeg.Encode(buffer, flatten(img), &jpeg.Options{Quality: variant.GetQuality()})
+	case "png":
+		err = png.Encode(buffer, img)
+	case "gif":
+		err = gif.Encode(buffer, img, nil)
+	default:
+		err = fmt.Errorf("images: can't encode %s", format)
+	}
+	return buffer.Bytes(), format, err
+}
+
+// Resize scales img to fit in width and height keeping its aspect ratio, zero width or
+// height is computed from the other. crop fills the box, cutting the center of the
+// overflowing side. Images aren't enlarged unless cropped.
+func Resize(img image.Image, width int, height int, crop bool) image.Image {
+	bounds := img.Bounds()
+	sourceWidth, sourceHeight := bounds.Dx(), bounds.Dy()
+	if sourceWidth == 0 || sourceHeight == 0 || width <= 0 && height <= 0 {
+		return img
+	}
+	if width <= 0 {
+		width = max(1, sourceWidth * height / sourceHeight)
+	}
+	if height <= 0 {
+		height = max(1, sourceHeight * width / sourceWidth)
+	}
+	if crop {
+		// cut the source to the aspect ratio of the box, then scale it to the box.
+		cropWidth, cropHeight := sourceWidth, sourceWidth * height / width
+		if cropHeight > sourceHeight {
+			cropWidth, cropHeight = sourceHeight * width / height, sourceHeight
+		}
+		x := bounds.Min.X + (sourceWidth - cropWidth) / 2
+		y := bounds.Min.Y + (sourceHeight - cropHeight) / 2
+		return scale(img, image.Rect(x, y, x + cropWidth, y + cropHeight), width, height)
+	}
+	ratio := min(float64(width) / float64(sourceWidth), float64(height) / float64(sourceHeight))
+	if ratio >= 1 {
+		return img
+	}
+	width = max(1, int(float64(sourceWidth) * ratio + 0.5))
+	height = max(1, int(float64(sourceHeight) * ratio + 0.5))
+	return scale(img, bounds, width, height)
+}
+
+// scale resamples area of img to width and height, averaging the source pixels covered
+// by each pixel, which keeps thumbnails smooth.
+func scale(img image.Image, area image.Rectangle, width int, height int) *image.RGBA {
+	source := image.NewRGBA(image.Rect(0, 0, area.Dx(), area.Dy()))
+	draw.Draw(source, source.Bounds(), img, area.Min, draw.Src)
+	result := image.NewRGBA(image.Rect(0, 0, width, height))
+	sourceWidth, sourceHeight := area.Dx(), area.Dy()
+	for y := 0; y < height; y++ {
+		y0 := y * sourceHeight / height
+		y1 := max(y0 + 1, (y + 1) * sourceHeight / height)
+		for x := 0; x < width; x++ {
+			x0 := x * sourceWidth / width
+			x1 := max(x0 + 1, (x + 1) * sourceWidth / width)
+			var r, g, b, a, count uint64
+			for sy := y0; sy < y1; sy++ {
+				offset := source.PixOffset(x0, sy)
+				for sx := x0; sx < x1; sx++ {
+					r += uint64(source.Pix[offset])
+					g += uint64(source.Pix[offset + 1])
+					b += uint64(source.Pix[offset + 2])
+					a += uint64(source.Pix[offset + 3])
+					offset += 4
+					count++
+				}
+			}
+			offset := result.PixOffset(x, y)
+			result.Pix[offset] = uint8(r / count)
+			result.Pix[offset + 1] = uint8(g / count)
+			result.Pix[offset + 2] = uint8(b / count)
+			result.Pix[offset + 3] = uint8(a / count)
+		}
+	}
+	return result
+}
+
+// flatten draws img over white, JPEG has no transparency.
+func flatten(img image.Image) image.Image {
+	result := image.NewRGBA(img.Bounds())
+	draw.Draw(result, result.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(result, result.Bounds(), img, img.Bounds().Min, draw.Over)
+	return result
+}
@@ -5,3 +5,15 @@ import "github.com/rimba47prayoga/gorim.git"
 type IPermission interface {
 	HasPermission(gorim.Context) bool
 }
+
+// IObjectPermission is checked against the object returned by GetObject.
+type IObjectPermission interface {
+	HasObjectPermission(gorim.Context, interface{}) bool
+}
+
+// IPermissionMessage lets permissions explain why access was denied,
+// code is machine-readable, example: "subscription_required".
+type IPermissionMessage interface {
+	GetMessage() string
+	GetCode() string
+}
@@ -0,0 +1,12 @@
+package interfaces
+
+import (
+	"time"
+
+	"github.com/rimba47prayoga/gorim.git"
+)
+
+// IThrottle returns false with the duration to wait when the request should be throttled.
+type IThrottle interface {
+	AllowRequest(gorim.Context) (bool, time.Duration)
+}
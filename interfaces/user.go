@@ -0,0 +1,12 @@
+package interfaces
+
+// IAdminUser is implemented by user models that can be granted admin access.
+type IAdminUser interface {
+	IsAdminUser() bool
+}
+
+// IUser is implemented by user models, see models.User and conf.USER_MODEL.
+type IUser interface {
+	SetPassword(string)
+	CheckPassword(string) bool
+}
@@ -1,9 +1,44 @@
 package interfaces
 
-import "github.com/rimba47prayoga/gorim.git"
+import (
+	"time"
+
+	"github.com/rimba47prayoga/gorim.git"
+)
 
 type IBaseView interface {
 	SetAction(string)
 	SetContext(gorim.Context)
 	HasPermission(gorim.Context) bool
 }
+
+// IThrottledView is implemented by views having throttles, checked by routers before the action runs.
+type IThrottledView interface {
+	CheckThrottles(gorim.Context) (bool, time.Duration)
+}
+
+// IPermissionCheckedView returns the first permission denying the request, nil when allowed.
+type IPermissionCheckedView interface {
+	CheckPermissions(gorim.Context) IPermission
+}
+
+// ISoftDeleteView is implemented by views with soft delete, routers add ListDeleted and Restore when enabled.
+type ISoftDeleteView interface {
+	SoftDeleteEnabled() bool
+}
+
+// IAggregateView is implemented by views with aggregations, routers add Aggregate when it has any.
+type IAggregateView interface {
+	HasAggregations() bool
+}
+
+// IWatchView is implemented by views publishing changes, routers add Watch when enabled.
+type IWatchView interface {
+	WatchEnabled() bool
+}
+
+// ICachedView is implemented by views caching responses of actions, routers run the
+// action through ServeCached after permissions and throttles are checked.
+type ICachedView interface {
+	ServeCached(c gorim.Context, action string, run func() error) error
+}
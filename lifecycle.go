@@ -0,0 +1,97 @@
+package gorim
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// DefaultShutdownTimeout is used when Server.ShutdownTimeout is zero.
+const DefaultShutdownTimeout = 30 * time.Second
+
+// Hook runs on startup or shutdown of the server, such as tracing shutdown
+// or closing a redis client: server.OnShutdown(func(ctx context.Context) error { return client.Close() })
+type Hook func(ctx context.Context) error
+
+// OnStartup adds hooks run in order before the server listens, an error stops Run.
+func (s *Server) OnStartup(hooks ...Hook) {
+	s.startup = append(s.startup, hooks...)
+}
+
+// OnShutdown adds hooks run after in-flight requests are drained, in reverse order so
+// resources are released after the ones depending on them.
+func (s *Server) OnShutdown(hooks ...Hook) {
+	s.shutdown = append(s.shutdown, hooks...)
+}
+
+// ShuttingDown reports whether Run is draining requests, readiness checks can fail
+// with it so load balancers stop routing to the server.
+func (s *Server) ShuttingDown() bool {
+	return s.closing.Load()
+}
+
+// Run starts the server until SIGINT or SIGTERM, see RunContext.
+func (s *Server) Run(address string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return s.RunContext(ctx, address)
+}
+
+// RunContext runs startup hooks and serves on address until ctx is done, then stops
+// accepting connections, waits for in-flight requests up to ShutdownTimeout, closes
+// remaining connections and runs shutdown hooks. Shutdown hooks also run when startup
+// fails, so resources opened by earlier hooks are released.
+func (s *Server) RunContext(ctx context.Context, address string) error {
+	s.closing.Store(false)
+	for _, hook := range s.startup {
+		if err := hook(ctx); err != nil {
+			return stderrors.Join(err, s.runShutdown())
+		}
+	}
+	served := make(chan error, 1)
+	go func() {
+		served <- s.Start(address)
+	}()
+	var err error
+	select {
+	case err = <-served:
+	case <-ctx.Done():
+	}
+	if stderrors.Is(err, http.ErrServerClosed) {
+		err = nil
+	}
+	return stderrors.Join(err, s.drain(), s.runShutdown())
+}
+
+func (s *Server) drain() error {
+	s.closing.Store(true)
+	ctx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout())
+	defer cancel()
+	err := s.Shutdown(ctx)
+	if stderrors.Is(err, context.DeadlineExceeded) {
+		s.Echo.Close()
+		return stderrors.New("shutdown timeout exceeded, remaining connections were closed")
+	}
+	return err
+}
+
+func (s *Server) runShutdown() error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout())
+	defer cancel()
+	var errs []error
+	for i := len(s.shutdown) - 1; i >= 0; i-- {
+		errs = append(errs, s.shutdown[i](ctx))
+	}
+	return stderrors.Join(errs...)
+}
+
+func (s *Server) shutdownTimeout() time.Duration {
+	if s.ShutdownTimeout == 0 {
+		return DefaultShutdownTimeout
+	}
+	return s.ShutdownTimeout
+}
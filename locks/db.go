@@ -0,0 +1,82 @@
+package locks
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GorimLock is a lease of a lock name, rows are kept after release so Fence keeps
+// increasing.
+type GorimLock struct {
+	Name		string			`gorm:"type:varchar(255);primarykey"`
+	Token		string			`gorm:"type:varchar(64)"`
+	Fence		int64
+	ExpiresAt	time.Time		`gorm:"index"`
+}
+
+func (m GorimLock) TableName() string {
+	return "gorim_locks"
+}
+
+// DBBackend locks across replicas sharing a database with leases in gorim_locks table,
+// add &locks.GorimLock{} to migration models. Expiry uses clocks of the replicas, keep
+// them in sync.
+type DBBackend struct {
+	DB		*gorm.DB
+}
+
+func NewDBBackend(db *gorm.DB) *DBBackend {
+	return &DBBackend{DB: db}
+}
+
+func (b *DBBackend) Acquire(ctx context.Context, name string, token string, ttl time.Duration) (int64, bool, error) {
+	db := b.DB.WithContext(ctx)
+	now := time.Now()
+	result := db.Model(&GorimLock{}).
+		Where("name = ? AND expires_at <= ?", name, now).
+		Updates(map[string]interface{}{
+			"token": token,
+			"fence": gorm.Expr("fence + 1"),
+			"expires_at": now.Add(ttl),
+		})
+	if result.Error != nil {
+		return 0, false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		record := GorimLock{Name: name, Token: token, Fence: 1, ExpiresAt: now.Add(ttl)}
+		result = db.Clauses(clause.OnConflict{DoNothing: true}).Create(&record)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return 0, false, result.Error
+		}
+		return 1, true, nil
+	}
+	var record GorimLock
+	if err := db.Where("name = ? AND token = ?", name, token).Take(&record).Error; err != nil {
+		return 0, false, err
+	}
+	return record.Fence, true, nil
+}
+
+func (b *DBBackend) Extend(ctx context.Context, name string, token string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	result := b.DB.WithContext(ctx).Model(&GorimLock{}).
+		Where("name = ? AND token = ? AND expires_at > ?", name, token, now).
+		Update("expires_at", now.Add(ttl))
+	return result.RowsAffected == 1, result.Error
+}
+
+func (b *DBBackend) Release(ctx context.Context, name string, token string) (bool, error) {
+	now := time.Now()
+	result := b.DB.WithContext(ctx).Model(&GorimLock{}).
+		Where("name = ? AND token = ? AND expires_at > ?", name, token, now).
+		Updates(map[string]interface{}{"token": "", "expires_at": now})
+	return result.RowsAffected == 1, result.Error
+}
+
+// ClearExpired removes leases expired before, their fences restart from 1.
+func (b *DBBackend) ClearExpired(before time.Time) error {
+	return b.DB.Where("expires_at <= ?", before).Delete(&GorimLock{}).Error
+}
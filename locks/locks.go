@@ -0,0 +1,219 @@
+package locks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	stderrors "errors"
+	"net/http"
+	"time"
+
+	"github.com/rimba47prayoga/gorim.git/errors"
+)
+
+// ErrNotAcquired is returned when the lock is held by another owner, it's rendered
+// as 409 when returned from an action.
+var ErrNotAcquired = &errors.APIError{
+	Status: http.StatusConflict,
+	Message: "The resource is locked by another operation, try again later.",
+	Code: "locked",
+}
+
+// ErrLost is returned by Extend and Release when the lock expired and may be held by
+// another owner.
+var ErrLost = stderrors.New("lock expired before it was released")
+
+// Backend stores locks shared by replicas, names are already prefixed by Locker.
+type Backend interface {
+	// Acquire takes name for token unless held by another token and returns the fencing
+	// token, which increases with every acquisition of name.
+	Acquire(ctx context.Context, name string, token string, ttl time.Duration) (int64, bool, error)
+	// Extend resets ttl of name held by token, false when token doesn't hold it.
+	Extend(ctx context.Context, name string, token string, ttl time.Duration) (bool, error)
+	// Release frees name held by token, false when token doesn't hold it.
+	Release(ctx context.Context, name string, token string) (bool, error)
+}
+
+// Lock is an acquired lock, Fence orders owners of the same name so storage receiving
+// writes can reject writes of an owner whose lock expired, example:
+//
+//	db.Where("id = ? AND fence < ?", id, lock.Fence).Updates(map[string]any{"fence": lock.Fence, ...})
+type Lock struct {
+	Name		string
+	Token		string
+	Fence		int64
+	TTL			time.Duration
+	backend		Backend
+}
+
+// Extend resets the ttl, ErrLost when the lock expired.
+func (l *Lock) Extend(ctx context.Context) error {
+	ok, err := l.backend.Extend(ctx, l.Name, l.Token, l.TTL)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrLost
+	}
+	return nil
+}
+
+// Release frees the lock, ErrLost when the lock expired.
+func (l *Lock) Release(ctx context.Context) error {
+	ok, err := l.backend.Release(ctx, l.Name, l.Token)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrLost
+	}
+	return nil
+}
+
+// Locker acquires locks of Backend.
+type Locker struct {
+	Backend			Backend
+	// Prefix of lock names, default "gorim:lock:".
+	Prefix			string
+	// TTL of acquired locks, default 30 seconds. Do extends it while running.
+	TTL				time.Duration
+	// RetryInterval between attempts of Acquire, default 100 milliseconds.
+	RetryInterval	time.Duration
+}
+
+// Default is used by the package functions, replace it with a redis or database locker
+// when the app runs on many replicas.
+var Default = New(NewMemoryBackend())
+
+func New(backend Backend) *Locker {
+	return &Locker{Backend: backend}
+}
+
+func (l *Locker) GetPrefix() string {
+	if l.Prefix == "" {
+		return "gorim:lock:"
+	}
+	return l.Prefix
+}
+
+func (l *Locker) GetTTL() time.Duration {
+	if l.TTL <= 0 {
+		return 30 * time.Second
+	}
+	return l.TTL
+}
+
+func (l *Locker) GetRetryInterval() time.Duration {
+	if l.RetryInterval <= 0 {
+		return 100 * time.Millisecond
+	}
+	return l.RetryInterval
+}
+
+// TryAcquire takes the lock once, ErrNotAcquired when it's held.
+func (l *Locker) TryAcquire(ctx context.Context, name string) (*Lock, error) {
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+	lock := &Lock{Name: l.GetPrefix() + name, Token: token, TTL: l.GetTTL(), backend: l.Backend}
+	fence, ok, err := l.Backend.Acquire(ctx, lock.Name, token, lock.TTL)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrNotAcquired
+	}
+	lock.Fence = fence
+	return lock, nil
+}
+
+// Acquire waits for the lock until ctx is done, use context.WithTimeout to bound
+// the wait, ErrNotAcquired when ctx is done first.
+func (l *Locker) Acquire(ctx context.Context, name string) (*Lock, error) {
+	ticker := time.NewTicker(l.GetRetryInterval())
+	defer ticker.Stop()
+	for {
+		lock, err := l.TryAcquire(ctx, name)
+		if err != ErrNotAcquired {
+			return lock, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ErrNotAcquired
+		case <-ticker.C:
+		}
+	}
+}
+
+// Do runs fn holding the lock and releases it after, the lock is extended every third
+// of the ttl while fn runs and the context of fn is cancelled when it's lost. Do doesn't
+// wait for a held lock unless ctx has a deadline, example in an action:
+//
+//	return locks.Do(ctx.Request().Context(), fmt.Sprintf("invoice:%d:pay", id), func(ctx context.Context, lock *locks.Lock) error {
+//		return pay(ctx, id)
+//	})
+func (l *Locker) Do(ctx context.Context, name string, fn func(ctx context.Context, lock *Lock) error) error {
+	var lock *Lock
+	var err error
+	if _, ok := ctx.Deadline(); ok {
+		lock, err = l.Acquire(ctx, name)
+	} else {
+		lock, err = l.TryAcquire(ctx, name)
+	}
+	if err != nil {
+		return err
+	}
+	runCtx, cancel := context.WithCancelCause(ctx)
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(lock.TTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := lock.Extend(context.WithoutCancel(runCtx)); err == ErrLost {
+					cancel(ErrLost)
+					return
+				}
+			}
+		}
+	}()
+	err = fn(runCtx, lock)
+	close(done)
+	lost := context.Cause(runCtx) == ErrLost
+	cancel(nil)
+	releaseErr := lock.Release(context.WithoutCancel(ctx))
+	if err != nil {
+		return err
+	}
+	if lost {
+		return ErrLost
+	}
+	return releaseErr
+}
+
+// TryAcquire takes lock of Default once.
+func TryAcquire(ctx context.Context, name string) (*Lock, error) {
+	return Default.TryAcquire(ctx, name)
+}
+
+// Acquire waits for lock of Default until ctx is done.
+func Acquire(ctx context.Context, name string) (*Lock, error) {
+	return Default.Acquire(ctx, name)
+}
+
+// Do runs fn holding lock of Default.
+func Do(ctx context.Context, name string, fn func(ctx context.Context, lock *Lock) error) error {
+	return Default.Do(ctx, name, fn)
+}
+
+func newToken() (string, error) {
+	data := make([]byte, 16)
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(data), nil
+}
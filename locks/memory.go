@@ -0,0 +1,62 @@
+package locks
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryLock struct {
+	token		string
+	expires		time.Time
+}
+
+// MemoryBackend locks within the process, for tests and single instance apps.
+type MemoryBackend struct {
+	mu			sync.Mutex
+	locks		map[string]memoryLock
+	fences		map[string]int64
+}
+
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{
+		locks: map[string]memoryLock{},
+		fences: map[string]int64{},
+	}
+}
+
+func (b *MemoryBackend) Acquire(ctx context.Context, name string, token string, ttl time.Duration) (int64, bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	if current, ok := b.locks[name]; ok && now.Before(current.expires) {
+		return 0, false, nil
+	}
+	b.locks[name] = memoryLock{token: token, expires: now.Add(ttl)}
+	b.fences[name]++
+	return b.fences[name], true, nil
+}
+
+func (b *MemoryBackend) Extend(ctx context.Context, name string, token string, ttl time.Duration) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	current, ok := b.locks[name]
+	if !ok || current.token != token || !now.Before(current.expires) {
+		return false, nil
+	}
+	current.expires = now.Add(ttl)
+	b.locks[name] = current
+	return true, nil
+}
+
+func (b *MemoryBackend) Release(ctx context.Context, name string, token string) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	current, ok := b.locks[name]
+	if !ok || current.token != token {
+		return false, nil
+	}
+	delete(b.locks, name)
+	return time.Now().Before(current.expires), nil
+}
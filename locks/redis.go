@@ -0,0 +1,65 @@
+package locks
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// acquireScript sets KEYS[1] to token ARGV[1] with ttl ARGV[2] (ms) unless it exists and
+// increments fence counter KEYS[2]. Returns the fence, 0 when the lock is held.
+var acquireScript = redis.NewScript(`
+if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+	return redis.call("INCR", KEYS[2])
+end
+return 0
+`)
+
+// extendScript resets ttl ARGV[2] (ms) of KEYS[1] held by token ARGV[1].
+var extendScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseScript deletes KEYS[1] held by token ARGV[1].
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// RedisBackend locks across replicas sharing a redis server. The fence counter is kept
+// in "{name}:fence", the braces keep both keys in one slot of a cluster.
+type RedisBackend struct {
+	Client		redis.UniversalClient
+}
+
+func NewRedisBackend(client redis.UniversalClient) *RedisBackend {
+	return &RedisBackend{Client: client}
+}
+
+func (b *RedisBackend) keys(name string) []string {
+	return []string{"{" + name + "}", "{" + name + "}:fence"}
+}
+
+func (b *RedisBackend) Acquire(ctx context.Context, name string, token string, ttl time.Duration) (int64, bool, error) {
+	fence, err := acquireScript.Run(ctx, b.Client, b.keys(name), token, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return 0, false, err
+	}
+	return fence, fence > 0, nil
+}
+
+func (b *RedisBackend) Extend(ctx context.Context, name string, token string, ttl time.Duration) (bool, error) {
+	extended, err := extendScript.Run(ctx, b.Client, b.keys(name)[:1], token, ttl.Milliseconds()).Int64()
+	return extended == 1, err
+}
+
+func (b *RedisBackend) Release(ctx context.Context, name string, token string) (bool, error) {
+	released, err := releaseScript.Run(ctx, b.Client, b.keys(name)[:1], token).Int64()
+	return released == 1, err
+}
@@ -0,0 +1,25 @@
+package mail
+
+import (
+	"context"
+
+	"github.com/rimba47prayoga/gorim.git/tasks"
+)
+
+// sendTask sends a message with Default in the worker.
+var sendTask = tasks.Register("gorim.mail.send", func(ctx context.Context, message Message) error {
+	return Default.Send(ctx, &message)
+}, tasks.Options{MaxRetries: 5})
+
+// SendAsync validates message and enqueues it to tasks.Default, the worker sends it and
+// retries it on errors of the server. Attachments are part of the payload, keep them small.
+func SendAsync(ctx context.Context, message *Message, options ...tasks.EnqueueOption) error {
+	if message.From == "" {
+		message.From = DefaultFrom
+	}
+	if err := message.Validate(); err != nil {
+		return err
+	}
+	_, err := sendTask.Enqueue(ctx, *message, options...)
+	return err
+}
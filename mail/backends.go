@@ -0,0 +1,218 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/smtp"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ConsoleBackend writes messages to Writer instead of sending them, for development.
+type ConsoleBackend struct {
+	// Writer defaults to os.Stdout.
+	Writer	io.Writer
+	mu		sync.Mutex
+}
+
+func NewConsoleBackend(writer io.Writer) *ConsoleBackend {
+	return &ConsoleBackend{Writer: writer}
+}
+
+func (b *ConsoleBackend) Send(ctx context.Context, messages ...*Message) error {
+	writer := b.Writer
+	if writer == nil {
+		writer = os.Stdout
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, message := range messages {
+		data, err := message.Bytes()
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(writer, "%s\r\n%s\r\n", data, "----------------------------------------"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MemoryBackend keeps sent messages, for tests.
+type MemoryBackend struct {
+	mu			sync.Mutex
+	messages	[]*Message
+}
+
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{}
+}
+
+func (b *MemoryBackend) Send(ctx context.Context, messages ...*Message) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, message := range messages {
+		if _, err := message.Bytes(); err != nil {
+			return err
+		}
+		b.messages = append(b.messages, message)
+	}
+	return nil
+}
+
+// Outbox returns sent messages in order.
+func (b *MemoryBackend) Outbox() []*Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]*Message{}, b.messages...)
+}
+
+// Reset empties the outbox.
+func (b *MemoryBackend) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.messages = nil
+}
+
+// SMTPBackend sends messages through an SMTP server, every Send opens one connection.
+type SMTPBackend struct {
+	Host		string
+	// Port defaults to 587, or 465 with TLS.
+	Port		int
+	// Username and Password authenticate with PLAIN auth when Username is set.
+	Username	string
+	Password	string
+	// TLS connects with implicit TLS, otherwise STARTTLS is used when supported.
+	TLS			bool
+	// TLSConfig defaults to verifying Host.
+	TLSConfig	*tls.Config
+	// Timeout of the connection, default 10 seconds.
+	Timeout		time.Duration
+}
+
+func NewSMTPBackend(host string, port int, username string, password string) *SMTPBackend {
+	return &SMTPBackend{
+		Host: host,
+		Port: port,
+		Username: username,
+		Password: password,
+	}
+}
+
+func (b *SMTPBackend) GetPort() int {
+	if b.Port != 0 {
+		return b.Port
+	}
+	if b.TLS {
+		return 465
+	}
+	return 587
+}
+
+func (b *SMTPBackend) GetTimeout() time.Duration {
+	if b.Timeout > 0 {
+		return b.Timeout
+	}
+	return 10 * time.Second
+}
+
+func (b *SMTPBackend) getTLSConfig() *tls.Config {
+	if b.TLSConfig != nil {
+		return b.TLSConfig
+	}
+	return &tls.Config{ServerName: b.Host}
+}
+
+func (b *SMTPBackend) Send(ctx context.Context, messages ...*Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	client, err := b.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	for _, message := range messages {
+		if err := b.send(client, message); err != nil {
+			return err
+		}
+	}
+	return client.Quit()
+}
+
+func (b *SMTPBackend) dial(ctx context.Context) (*smtp.Client, error) {
+	address := net.JoinHostPort(b.Host, strconv.Itoa(b.GetPort()))
+	ctx, cancel := context.WithTimeout(ctx, b.GetTimeout())
+	defer cancel()
+	var conn net.Conn
+	var err error
+	if b.TLS {
+		dialer := &tls.Dialer{Config: b.getTLSConfig()}
+		conn, err = dialer.DialContext(ctx, "tcp", address)
+	} else {
+		dialer := &net.Dialer{}
+		conn, err = dialer.DialContext(ctx, "tcp", address)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// the deadline covers the whole session, net/smtp doesn't take a context.
+	conn.SetDeadline(time.Now().Add(b.GetTimeout()))
+	client, err := smtp.NewClient(conn, b.Host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if !b.TLS {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(b.getTLSConfig()); err != nil {
+				client.Close()
+				return nil, err
+			}
+		}
+	}
+	if b.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", b.Username, b.Password, b.Host)); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+	return client, nil
+}
+
+func (b *SMTPBackend) send(client *smtp.Client, message *Message) error {
+	data, err := message.Bytes()
+	if err != nil {
+		return err
+	}
+	from, err := parseAddress(message.From)
+	if err != nil {
+		return err
+	}
+	recipients, err := message.Recipients()
+	if err != nil {
+		return err
+	}
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	for _, recipient := range recipients {
+		if err := client.Rcpt(recipient); err != nil {
+			return err
+		}
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(data); err != nil {
+		writer.Close()
+		return err
+	}
+	return writer.Close()
+}
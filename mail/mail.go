@@ -0,0 +1,304 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Attachment is a file sent with the message, inline attachments are referenced by
+// HTML as cid:<Filename>.
+type Attachment struct {
+	Filename	string		`json:"filename"`
+	// ContentType defaults to the type of the file extension.
+	ContentType	string		`json:"content_type"`
+	Data		[]byte		`json:"data"`
+	Inline		bool		`json:"inline"`
+}
+
+// Message is an email with text and HTML alternatives, From defaults to DefaultFrom.
+type Message struct {
+	From		string			`json:"from"`
+	To			[]string		`json:"to"`
+	Cc			[]string		`json:"cc"`
+	Bcc			[]string		`json:"bcc"`
+	ReplyTo		string			`json:"reply_to"`
+	Subject		string			`json:"subject"`
+	Text		string			`json:"text"`
+	HTML		string			`json:"html"`
+	Headers		map[string]string	`json:"headers"`
+	Attachments	[]Attachment	`json:"attachments"`
+}
+
+// Attach adds attachment of data.
+func (m *Message) Attach(filename string, data []byte) {
+	m.Attachments = append(m.Attachments, Attachment{Filename: filename, Data: data})
+}
+
+// AttachFile adds file at path as attachment.
+func (m *Message) AttachFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	m.Attach(filepath.Base(path), data)
+	return nil
+}
+
+// Recipients returns addresses of To, Cc and Bcc.
+func (m *Message) Recipients() ([]string, error) {
+	recipients := []string{}
+	for _, list := range [][]string{m.To, m.Cc, m.Bcc} {
+		for _, value := range list {
+			address, err := parseAddress(value)
+			if err != nil {
+				return nil, err
+			}
+			recipients = append(recipients, address)
+		}
+	}
+	return recipients, nil
+}
+
+// Validate checks addresses and headers of the message and that it has recipients and a body.
+func (m *Message) Validate() error {
+	if m.From == "" {
+		return errors.New("mail: message has no sender")
+	}
+	if _, err := parseAddress(m.From); err != nil {
+		return err
+	}
+	if m.ReplyTo != "" {
+		if _, err := parseAddress(m.ReplyTo); err != nil {
+			return err
+		}
+	}
+	for key, value := range m.Headers {
+		if strings.ContainsAny(key + value, "\r\n") {
+			return fmt.Errorf("mail: invalid header %q", key)
+		}
+	}
+	recipients, err := m.Recipients()
+	if err != nil {
+		return err
+	}
+	if len(recipients) == 0 {
+		return errors.New("mail: message has no recipients")
+	}
+	if m.Text == "" && m.HTML == "" {
+		return errors.New("mail: message has no body")
+	}
+	return nil
+}
+
+// Bytes validates the message and returns it in MIME format, Bcc isn't included.
+func (m *Message) Bytes() ([]byte, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	headers := map[string]string{
+		"MIME-Version": "1.0",
+		"Date": time.Now().Format(time.RFC1123Z),
+		"Message-ID": messageID(m.From),
+		"From": formatAddresses(m.From),
+		"Subject": mime.QEncoding.Encode("utf-8", m.Subject),
+	}
+	if len(m.To) > 0 {
+		headers["To"] = formatAddresses(m.To...)
+	}
+	if len(m.Cc) > 0 {
+		headers["Cc"] = formatAddresses(m.Cc...)
+	}
+	if m.ReplyTo != "" {
+		headers["Reply-To"] = formatAddresses(m.ReplyTo)
+	}
+	for key, value := range m.Headers {
+		headers[textproto.CanonicalMIMEHeaderKey(key)] = value
+	}
+	header, body, err := m.body()
+	if err != nil {
+		return nil, err
+	}
+	if len(m.Attachments) > 0 {
+		var mixed bytes.Buffer
+		writer := multipart.NewWriter(&mixed)
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, err
+		}
+		part.Write(body)
+		for _, attachment := range m.Attachments {
+			if err := writeAttachment(writer, attachment); err != nil {
+				return nil, err
+			}
+		}
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+		header, body = textproto.MIMEHeader{"Content-Type": {"multipart/mixed; boundary=" + writer.Boundary()}}, mixed.Bytes()
+	}
+	for key := range header {
+		headers[key] = header.Get(key)
+	}
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var buffer bytes.Buffer
+	for _, key := range keys {
+		fmt.Fprintf(&buffer, "%s: %s\r\n", key, headers[key])
+	}
+	buffer.WriteString("\r\n")
+	buffer.Write(body)
+	return buffer.Bytes(), nil
+}
+
+// body returns header and encoded body of the text, the HTML or both as alternatives.
+func (m *Message) body() (textproto.MIMEHeader, []byte, error) {
+	if m.Text == "" || m.HTML == "" {
+		contentType, content := "text/plain", m.Text
+		if m.HTML != "" {
+			contentType, content = "text/html", m.HTML
+		}
+		var buffer bytes.Buffer
+		if err := writeQuotedPrintable(&buffer, content); err != nil {
+			return nil, nil, err
+		}
+		header := textproto.MIMEHeader{
+			"Content-Type": {contentType + "; charset=utf-8"},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		}
+		return header, buffer.Bytes(), nil
+	}
+	var buffer bytes.Buffer
+	writer := multipart.NewWriter(&buffer)
+	for _, alternative := range []struct{ contentType, content string }{{"text/plain", m.Text}, {"text/html", m.HTML}} {
+		part, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type": {alternative.contentType + "; charset=utf-8"},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := writeQuotedPrintable(part, alternative.content); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, nil, err
+	}
+	header := textproto.MIMEHeader{"Content-Type": {"multipart/alternative; boundary=" + writer.Boundary()}}
+	return header, buffer.Bytes(), nil
+}
+
+func writeQuotedPrintable(w io.Writer, content string) error {
+	writer := quotedprintable.NewWriter(w)
+	if _, err := writer.Write([]byte(content)); err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+func writeAttachment(writer *multipart.Writer, attachment Attachment) error {
+	contentType := attachment.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(attachment.Filename))
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	disposition := "attachment"
+	header := textproto.MIMEHeader{
+		"Content-Type": {contentType},
+		"Content-Transfer-Encoding": {"base64"},
+	}
+	if attachment.Inline {
+		disposition = "inline"
+		header.Set("Content-ID", "<" + attachment.Filename + ">")
+	}
+	header.Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": attachment.Filename}))
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	encoded := base64.StdEncoding.EncodeToString(attachment.Data)
+	// lines of base64 are limited to 76 characters.
+	for len(encoded) > 76 {
+		if _, err := part.Write([]byte(encoded[:76] + "\r\n")); err != nil {
+			return err
+		}
+		encoded = encoded[76:]
+	}
+	_, err = part.Write([]byte(encoded + "\r\n"))
+	return err
+}
+
+// parseAddress returns the address of value such as "Name <name@example.com>".
+func parseAddress(value string) (string, error) {
+	address, err := mail.ParseAddress(value)
+	if err != nil {
+		return "", fmt.Errorf("mail: invalid address %q: %w", value, err)
+	}
+	return address.Address, nil
+}
+
+// formatAddresses returns header value of valid addresses, names are encoded.
+func formatAddresses(values ...string) string {
+	formatted := make([]string, len(values))
+	for i, value := range values {
+		address, _ := mail.ParseAddress(value)
+		formatted[i] = address.String()
+	}
+	return strings.Join(formatted, ", ")
+}
+
+func messageID(from string) string {
+	domain := "localhost"
+	if address, err := parseAddress(from); err == nil {
+		if _, host, ok := strings.Cut(address, "@"); ok {
+			domain = host
+		}
+	}
+	random := make([]byte, 12)
+	rand.Read(random)
+	return fmt.Sprintf("<%d.%s@%s>", time.Now().UnixNano(), hex.EncodeToString(random), domain)
+}
+
+// Backend delivers messages.
+type Backend interface {
+	Send(ctx context.Context, messages ...*Message) error
+}
+
+var (
+	// Default sends messages of Send, it prints them until configured by FromSettings.
+	Default		Backend	= &ConsoleBackend{}
+	// DefaultFrom is the sender of messages without From.
+	DefaultFrom	= "webmaster@localhost"
+)
+
+// Send sends message with Default.
+func Send(ctx context.Context, message *Message) error {
+	if message.From == "" {
+		message.From = DefaultFrom
+	}
+	if err := message.Validate(); err != nil {
+		return err
+	}
+	return Default.Send(ctx, message)
+}
@@ -0,0 +1,32 @@
+package mail
+
+import (
+	"fmt"
+
+	"github.com/rimba47prayoga/gorim.git/settings"
+)
+
+// FromSettings returns Backend of settings.Get().Email and sets DefaultFrom,
+// example: mail.Default, err = mail.FromSettings()
+func FromSettings() (Backend, error) {
+	config := settings.Get().Email
+	if config.From != "" {
+		DefaultFrom = config.From
+	}
+	switch config.Backend {
+	case "", "console":
+		return NewConsoleBackend(nil), nil
+	case "memory":
+		return NewMemoryBackend(), nil
+	case "smtp":
+		return &SMTPBackend{
+			Host: config.Host,
+			Port: config.Port,
+			Username: config.Username,
+			Password: config.Password,
+			TLS: config.TLS,
+			Timeout: config.Timeout,
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown email backend %q", config.Backend)
+}
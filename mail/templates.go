@@ -0,0 +1,86 @@
+package mail
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Templates holds templates of messages rendered by Render, set it in settings of the
+// project, example: mail.Templates = os.DirFS("templates/mail")
+var Templates fs.FS
+
+// Render returns message of template name with data, its subject is "<name>.subject.txt",
+// its text is "<name>.txt" and its HTML is "<name>.html" escaped by html/template. Either
+// body may be missing, example:
+//
+//	message, err := mail.Render("password_reset", map[string]any{"Link": link})
+//	message.To = []string{user.Email}
+//	mail.SendAsync(ctx, message)
+func Render(name string, data any) (*Message, error) {
+	if Templates == nil {
+		return nil, errors.New("mail: Templates isn't set")
+	}
+	message := &Message{}
+	subject, err := renderText(name + ".subject.txt", data)
+	if err != nil {
+		return nil, err
+	}
+	// subjects are one line.
+	message.Subject = strings.Join(strings.Fields(subject), " ")
+	if message.Text, err = renderText(name + ".txt", data); err != nil {
+		return nil, err
+	}
+	if message.HTML, err = renderHTML(name + ".html", data); err != nil {
+		return nil, err
+	}
+	if message.Text == "" && message.HTML == "" {
+		return nil, fmt.Errorf("mail: template %q has neither %s.txt nor %s.html", name, name, name)
+	}
+	return message, nil
+}
+
+// readTemplate returns content of file, empty when it doesn't exist.
+func readTemplate(file string) (string, error) {
+	content, err := fs.ReadFile(Templates, file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	return string(content), err
+}
+
+func renderText(file string, data any) (string, error) {
+	content, err := readTemplate(file)
+	if content == "" || err != nil {
+		return "", err
+	}
+	tmpl, err := texttemplate.New(file).Parse(content)
+	if err != nil {
+		return "", err
+	}
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, data); err != nil {
+		return "", err
+	}
+	return buffer.String(), nil
+}
+
+func renderHTML(file string, data any) (string, error) {
+	content, err := readTemplate(file)
+	if content == "" || err != nil {
+		return "", err
+	}
+	tmpl, err := htmltemplate.New(file).Parse(content)
+	if err != nil {
+		return "", err
+	}
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, data); err != nil {
+		return "", err
+	}
+	return buffer.String(), nil
+}
@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+)
+
+var (
+	queriesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "gorim",
+		Name: "db_queries_total",
+		Help: "Total database queries by operation, table and status.",
+	}, []string{"operation", "table", "status"})
+	queryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "gorim",
+		Name: "db_query_duration_seconds",
+		Help: "Database query latency by operation and table.",
+		Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+)
+
+const startTimeKey = "gorim:metrics_start"
+
+// GormPlugin records query metrics, example: conf.DB.Use(metrics.GormPlugin{})
+type GormPlugin struct{}
+
+func (p GormPlugin) Name() string {
+	return "gorim:metrics"
+}
+
+func (p GormPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	return errors.Join(
+		callback.Create().Before("gorm:create").Register("gorim:metrics_before_create", beforeQuery),
+		callback.Create().After("gorm:create").Register("gorim:metrics_after_create", afterQuery("create")),
+		callback.Query().Before("gorm:query").Register("gorim:metrics_before_query", beforeQuery),
+		callback.Query().After("gorm:query").Register("gorim:metrics_after_query", afterQuery("query")),
+		callback.Update().Before("gorm:update").Register("gorim:metrics_before_update", beforeQuery),
+		callback.Update().After("gorm:update").Register("gorim:metrics_after_update", afterQuery("update")),
+		callback.Delete().Before("gorm:delete").Register("gorim:metrics_before_delete", beforeQuery),
+		callback.Delete().After("gorm:delete").Register("gorim:metrics_after_delete", afterQuery("delete")),
+		callback.Row().Before("gorm:row").Register("gorim:metrics_before_row", beforeQuery),
+		callback.Row().After("gorm:row").Register("gorim:metrics_after_row", afterQuery("row")),
+		callback.Raw().Before("gorm:raw").Register("gorim:metrics_before_raw", beforeQuery),
+		callback.Raw().After("gorm:raw").Register("gorim:metrics_after_raw", afterQuery("raw")),
+	)
+}
+
+func beforeQuery(db *gorm.DB) {
+	db.InstanceSet(startTimeKey, time.Now())
+}
+
+func afterQuery(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(startTimeKey)
+		if !ok {
+			return
+		}
+		start, _ := value.(time.Time)
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+		status := "ok"
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			status = "error"
+		}
+		queriesTotal.WithLabelValues(operation, table, status).Inc()
+		queryDuration.WithLabelValues(operation, table).Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/rimba47prayoga/gorim.git"
+)
+
+// Registry collects gorim metrics with go and process collectors,
+// register application metrics here to expose them on the same handler.
+var Registry = prometheus.NewRegistry()
+
+var (
+	requestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "gorim",
+		Name: "http_requests_total",
+		Help: "Total HTTP requests by route, method and status.",
+	}, []string{"route", "method", "status"})
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "gorim",
+		Name: "http_request_duration_seconds",
+		Help: "HTTP request latency by route, method and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+	responseSize = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "gorim",
+		Name: "http_response_size_bytes",
+		Help: "HTTP response size by route, method and status.",
+		Buckets: prometheus.ExponentialBuckets(128, 4, 8),
+	}, []string{"route", "method", "status"})
+	requestsInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "gorim",
+		Name: "http_requests_in_flight",
+		Help: "HTTP requests currently being served.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		requestsTotal,
+		requestDuration,
+		responseSize,
+		requestsInFlight,
+		queriesTotal,
+		queryDuration,
+	)
+}
+
+// Middleware records request metrics labeled by route pattern,
+// example: "/api/users/:pk", unmatched requests are labeled "unmatched".
+func Middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		start := time.Now()
+		requestsInFlight.Inc()
+		defer requestsInFlight.Dec()
+		err := next(c)
+
+		status := c.Response().Status
+		if err != nil && !c.Response().Committed {
+			status = http.StatusInternalServerError
+			if httpErr, ok := err.(*echo.HTTPError); ok {
+				status = httpErr.Code
+			}
+		}
+		route := c.Path()
+		if route == "" {
+			route = "unmatched"
+		}
+		labels := prometheus.Labels{
+			"route": route,
+			"method": c.Request().Method,
+			"status": strconv.Itoa(status),
+		}
+		requestsTotal.With(labels).Inc()
+		requestDuration.With(labels).Observe(time.Since(start).Seconds())
+		responseSize.With(labels).Observe(float64(c.Response().Size))
+		return err
+	}
+}
+
+// Handler serves the Registry in prometheus text format,
+// example: server.GET("/metrics", metrics.Handler(), basicAuth)
+func Handler() gorim.HandlerFunc {
+	handler := promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+	return func(c gorim.Context) error {
+		handler.ServeHTTP(c.Response(), c.Request())
+		return nil
+	}
+}
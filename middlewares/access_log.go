@@ -0,0 +1,150 @@
+package middlewares
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git/utils"
+)
+
+const RequestIDHeader = "X-Request-ID"
+const RequestIDContextKey = "request_id"
+
+const redacted = "REDACTED"
+
+// AccessLogConfig configures AccessLogMiddleware.
+type AccessLogConfig struct {
+	// Logger defaults to JSON records on stdout, pass any slog.Logger to plug into existing loggers.
+	Logger			*slog.Logger
+	// RedactQuery lists query params whose values are redacted from the logged path.
+	RedactQuery		[]string
+	// Headers lists request headers to log, RedactHeaders values are redacted.
+	Headers			[]string
+	RedactHeaders	[]string
+	// Attrs adds extra attributes to the record.
+	Attrs			func(c echo.Context) []slog.Attr
+	Skipper			func(c echo.Context) bool
+}
+
+var DefaultRedactQuery = []string{"password", "token", "secret", "api_key", "access_token", "refresh_token"}
+var DefaultRedactHeaders = []string{"Authorization", "Cookie", "X-API-Key"}
+
+// AccessLogMiddleware logs a structured record per request with method, path, status,
+// latency, user id and request id. Server errors are logged at error level,
+// client errors at warn level.
+func AccessLogMiddleware(config AccessLogConfig) echo.MiddlewareFunc {
+	logger := config.Logger
+	if logger == nil {
+		logger = slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	}
+	if config.RedactQuery == nil {
+		config.RedactQuery = DefaultRedactQuery
+	}
+	if config.RedactHeaders == nil {
+		config.RedactHeaders = DefaultRedactHeaders
+	}
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper != nil && config.Skipper(c) {
+				return next(c)
+			}
+			start := time.Now()
+			requestID := GetRequestID(c)
+			err := next(c)
+			latency := time.Since(start)
+
+			request := c.Request()
+			status := c.Response().Status
+			if err != nil && !c.Response().Committed {
+				status = http.StatusInternalServerError
+				if httpErr, ok := err.(*echo.HTTPError); ok {
+					status = httpErr.Code
+				}
+			}
+			attrs := []slog.Attr{
+				slog.String("method", request.Method),
+				slog.String("path", redactURI(request.URL, config.RedactQuery)),
+				slog.Int("status", status),
+				slog.Float64("latency_ms", float64(latency.Microseconds()) / 1000),
+				slog.Int64("size", c.Response().Size),
+				slog.String("remote_ip", c.RealIP()),
+				slog.String("request_id", requestID),
+			}
+			// "user" is gorim.UserContextKey, set by authentication.
+			if userID, ok := utils.GetUserID(c.Get("user")); ok {
+				attrs = append(attrs, slog.Uint64("user_id", uint64(userID)))
+			}
+			for _, name := range config.Headers {
+				value := request.Header.Get(name)
+				if value != "" && containsFold(config.RedactHeaders, name) {
+					value = redacted
+				}
+				attrs = append(attrs, slog.String(strings.ToLower(name), value))
+			}
+			if err != nil {
+				attrs = append(attrs, slog.String("error", err.Error()))
+			}
+			if config.Attrs != nil {
+				attrs = append(attrs, config.Attrs(c)...)
+			}
+			level := slog.LevelInfo
+			if status >= 500 {
+				level = slog.LevelError
+			} else if status >= 400 {
+				level = slog.LevelWarn
+			}
+			logger.LogAttrs(context.Background(), level, "request", attrs...)
+			return err
+		}
+	}
+}
+
+// GetRequestID returns the request id from X-Request-ID header, generated when missing,
+// the id is set to the response header and context.
+func GetRequestID(c echo.Context) string {
+	if requestID, ok := c.Get(RequestIDContextKey).(string); ok {
+		return requestID
+	}
+	requestID := c.Request().Header.Get(RequestIDHeader)
+	if requestID == "" {
+		requestID = c.Response().Header().Get(RequestIDHeader)
+	}
+	if requestID == "" {
+		bytes := make([]byte, 16)
+		rand.Read(bytes)
+		requestID = hex.EncodeToString(bytes)
+	}
+	c.Response().Header().Set(RequestIDHeader, requestID)
+	c.Set(RequestIDContextKey, requestID)
+	return requestID
+}
+
+func redactURI(u *url.URL, keys []string) string {
+	if u.RawQuery == "" {
+		return u.Path
+	}
+	query := u.Query()
+	for key := range query {
+		if containsFold(keys, key) {
+			query.Set(key, redacted)
+		}
+	}
+	return u.Path + "?" + query.Encode()
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package middlewares
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git/errors"
+)
+
+// AllowedHosts refuses requests whose Host header isn't in hosts with 400, protecting
+// links built from the host. "*" allows any host and leading dot allows the domain
+// and its subdomains, example: server.Use(middlewares.AllowedHosts([]string{".example.com"}))
+func AllowedHosts(hosts []string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !HostAllowed(c.Request().Host, hosts) {
+				return errors.Handle(echo.NewHTTPError(http.StatusBadRequest, "Invalid Host header."), c)
+			}
+			return next(c)
+		}
+	}
+}
+
+// HostAllowed reports whether host, with or without port, matches hosts.
+func HostAllowed(host string, hosts []string) bool {
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		host = name
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, pattern := range hosts {
+		pattern = strings.ToLower(pattern)
+		switch {
+		case pattern == "*":
+			return true
+		case strings.HasPrefix(pattern, "."):
+			if host == pattern[1:] || strings.HasSuffix(host, pattern) {
+				return true
+			}
+		case host == pattern:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package middlewares
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git/errors"
+)
+
+// limitedBody fails reads past limit, the limit can be replaced by route middleware
+// until the body is read, so a route can allow more than the global limit.
+type limitedBody struct {
+	io.ReadCloser
+	contentLength	int64
+	limit		int64
+	read		int64
+	exceeded	bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.contentLength > b.limit {
+		b.exceeded = true
+	}
+	if b.exceeded {
+		return 0, echo.ErrStatusRequestEntityTooLarge
+	}
+	// read one byte past limit to tell exceeding body from body of exactly limit.
+	if remaining := b.limit - b.read + 1; int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	if b.read > b.limit {
+		b.exceeded = true
+		return n - int(b.read - b.limit), echo.ErrStatusRequestEntityTooLarge
+	}
+	return n, err
+}
+
+// ParseSize parses "<number>[B|KB|MB|GB]", example: "2MB".
+func ParseSize(size string) (int64, error) {
+	size = strings.ToUpper(strings.TrimSpace(size))
+	multipliers := []struct {
+		suffix		string
+		multiplier	int64
+	}{
+		{"GB", 1 << 30},
+		{"MB", 1 << 20},
+		{"KB", 1 << 10},
+		{"B", 1},
+	}
+	for _, m := range multipliers {
+		if number, found := strings.CutSuffix(size, m.suffix); found {
+			value, err := strconv.ParseInt(strings.TrimSpace(number), 10, 64)
+			return value * m.multiplier, err
+		}
+	}
+	return strconv.ParseInt(size, 10, 64)
+}
+
+// BodyLimit responds 413 when the request body exceeds limit bytes,
+// checked when the body is read so Content-Length exceeding it is rejected before reading.
+// Use it globally with server.Use and per route to override the global limit,
+// example: server.POST("/upload", handler, middlewares.BodyLimit(50 << 20))
+func BodyLimit(limit int64) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			request := c.Request()
+			body, ok := request.Body.(*limitedBody)
+			if ok {
+				body.limit = limit
+			} else {
+				body = &limitedBody{
+					ReadCloser: request.Body,
+					contentLength: request.ContentLength,
+					limit: limit,
+				}
+				request.Body = body
+			}
+			// bind errors are raised as 500 or returned as 400, replace them with 413.
+			defer func() {
+				if r := recover(); r != nil {
+					if !body.exceeded || c.Response().Committed {
+						panic(r)
+					}
+					err = tooLarge(c)
+				}
+			}()
+			err = next(c)
+			if body.exceeded && !c.Response().Committed {
+				return tooLarge(c)
+			}
+			return err
+		}
+	}
+}
+
+func tooLarge(c echo.Context) error {
+	return errors.Handle(&errors.APIError{Status: http.StatusRequestEntityTooLarge, Message: "Request body too large."}, c)
+}
@@ -0,0 +1,247 @@
+package middlewares
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/labstack/echo/v4"
+)
+
+// CompressConfig configures CompressMiddleware.
+type CompressConfig struct {
+	// Encodings in order of preference when the client accepts several, default br then gzip.
+	Encodings		[]string
+	// MinLength skips responses smaller than it, default 1024 bytes.
+	MinLength		int
+	// ContentTypes allowlist, entries ending with "/" match the whole type, example: "text/".
+	ContentTypes	[]string
+	Level			int
+	Skipper			func(c echo.Context) bool
+}
+
+var DefaultCompressContentTypes = []string{
+	"text/",
+	"application/json",
+	"application/problem+json",
+	"application/javascript",
+	"application/xml",
+	"image/svg+xml",
+}
+
+// CompressMiddleware compresses responses with gzip or brotli negotiated from Accept-Encoding.
+// The response is buffered until MinLength to decide, flushed responses (streams)
+// are compressed from the first flush and each flush is passed through the encoder.
+func CompressMiddleware(config CompressConfig) echo.MiddlewareFunc {
+	if config.Encodings == nil {
+		config.Encodings = []string{"br", "gzip"}
+	}
+	if config.MinLength == 0 {
+		config.MinLength = 1024
+	}
+	if config.ContentTypes == nil {
+		config.ContentTypes = DefaultCompressContentTypes
+	}
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper != nil && config.Skipper(c) {
+				return next(c)
+			}
+			c.Response().Header().Add(echo.HeaderVary, echo.HeaderAcceptEncoding)
+			encoding := negotiateEncoding(c.Request().Header.Get(echo.HeaderAcceptEncoding), config.Encodings)
+			if encoding == "" || c.Request().Method == http.MethodHead {
+				return next(c)
+			}
+			response := c.Response()
+			writer := &compressWriter{
+				ResponseWriter: response.Writer,
+				config: config,
+				encoding: encoding,
+				status: http.StatusOK,
+			}
+			response.Writer = writer
+			defer func() {
+				writer.Close()
+				response.Writer = writer.ResponseWriter
+			}()
+			return next(c)
+		}
+	}
+}
+
+// negotiateEncoding returns the preferred encoding with the highest q value accepted by client.
+func negotiateEncoding(header string, encodings []string) string {
+	accepted := map[string]float64{}
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if value, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+		accepted[strings.ToLower(name)] = q
+	}
+	best, bestQ := "", 0.0
+	for _, encoding := range encodings {
+		q, ok := accepted[encoding]
+		if !ok {
+			q, ok = accepted["*"]
+		}
+		if ok && q > bestQ {
+			best, bestQ = encoding, q
+		}
+	}
+	return best
+}
+
+type compressWriter struct {
+	http.ResponseWriter
+	config		CompressConfig
+	encoding	string
+	status		int
+	buffer		bytes.Buffer
+	wrote		bool
+	decided		bool
+	encoder		io.WriteCloser
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+	w.status = code
+	w.wrote = true
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	w.wrote = true
+	if !w.decided {
+		w.buffer.Write(b)
+		if w.buffer.Len() >= w.config.MinLength {
+			if err := w.decide(false); err != nil {
+				return 0, err
+			}
+		}
+		return len(b), nil
+	}
+	if w.encoder != nil {
+		return w.encoder.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// decide writes the header with or without compression then the buffered body,
+// streaming skips MinLength since the final size is unknown.
+func (w *compressWriter) decide(streaming bool) error {
+	w.decided = true
+	header := w.Header()
+	if w.shouldCompress(streaming) {
+		header.Del(echo.HeaderContentLength)
+		header.Set(echo.HeaderContentEncoding, w.encoding)
+		w.encoder = w.newEncoder()
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.buffer.Len() == 0 {
+		return nil
+	}
+	var err error
+	if w.encoder != nil {
+		_, err = w.encoder.Write(w.buffer.Bytes())
+	} else {
+		_, err = w.ResponseWriter.Write(w.buffer.Bytes())
+	}
+	w.buffer.Reset()
+	return err
+}
+
+func (w *compressWriter) shouldCompress(streaming bool) bool {
+	if w.status < http.StatusOK || w.status == http.StatusNoContent || w.status == http.StatusNotModified {
+		return false
+	}
+	header := w.Header()
+	if header.Get(echo.HeaderContentEncoding) != "" {
+		return false
+	}
+	if !streaming && w.buffer.Len() < w.config.MinLength {
+		return false
+	}
+	contentType := header.Get(echo.HeaderContentType)
+	if contentType == "" {
+		contentType = http.DetectContentType(w.buffer.Bytes())
+	}
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	for _, allowed := range w.config.ContentTypes {
+		if strings.HasSuffix(allowed, "/") && strings.HasPrefix(mediaType, allowed) {
+			return true
+		}
+		if mediaType == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *compressWriter) newEncoder() io.WriteCloser {
+	if w.encoding == "br" {
+		level := w.config.Level
+		if level == 0 {
+			level = brotli.DefaultCompression
+		}
+		return brotli.NewWriterLevel(w.ResponseWriter, level)
+	}
+	level := w.config.Level
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	encoder, err := gzip.NewWriterLevel(w.ResponseWriter, level)
+	if err != nil {
+		encoder = gzip.NewWriter(w.ResponseWriter)
+	}
+	return encoder
+}
+
+type flusher interface {
+	Flush() error
+}
+
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.decide(true)
+	}
+	if encoder, ok := w.encoder.(flusher); ok {
+		encoder.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Close flushes the encoder, nothing is written when the handler didn't respond
+// so echo's error handler can still respond with the original writer.
+func (w *compressWriter) Close() error {
+	if !w.wrote {
+		return nil
+	}
+	if !w.decided {
+		if err := w.decide(false); err != nil {
+			return err
+		}
+	}
+	if w.encoder != nil {
+		return w.encoder.Close()
+	}
+	return nil
+}
+
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -1,35 +1,66 @@
 package middlewares
 
 import (
+	"fmt"
+	"log/slog"
 	"net/http"
+	"runtime/debug"
 
 	"github.com/labstack/echo/v4"
 	"github.com/rimba47prayoga/gorim.git/errors"
+	"github.com/rimba47prayoga/gorim.git/hooks"
 )
 
 type Response map[string]any
 
-// RecoverMiddleware is the middleware that recovers from panics
+// RecoverLogger logs stack traces of panics resulting in 500, defaults to slog.Default().
+var RecoverLogger *slog.Logger
+
+// RecoverMiddleware is the middleware that recovers from panics,
+// errors raised by errors.Raise are rendered by errors.ExceptionHandler,
+// other panics are logged with the stack trace and rendered as 500.
 func RecoverMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
     return func(c echo.Context) (err error) {
         defer func() {
-            if r := recover(); r != nil {
-                // Check if the panic is of type ObjectNotFoundError
-                if notFoundErr, ok := r.(*errors.ObjectNotFoundError); ok {
-                    // Return 404 for ObjectNotFoundError
-                    c.JSON(http.StatusNotFound, Response{
-                        "error": notFoundErr.Error(),
-                    })
-                } else if internalServerErr, ok := r.(*errors.InternalServerError); ok {
-                    c.JSON(http.StatusInternalServerError, Response{
-                        "error": internalServerErr.Error(),
-                    })
-                } else {
-                    // For other panics, return a generic 500 error
-                    panic(r)
+            r := recover()
+            if r == nil {
+                return
+            }
+            if r == http.ErrAbortHandler {
+                panic(r)
+            }
+            panicErr := PanicError(r)
+            stack := debug.Stack()
+            if e, ok := panicErr.(*errors.PanicError); ok {
+                e.Stack = stack
+            }
+            if status, _ := errors.Resolve(panicErr); status >= http.StatusInternalServerError {
+                logger := RecoverLogger
+                if logger == nil {
+                    logger = slog.Default()
                 }
+                logger.Error(
+                    "panic recovered",
+                    "method", c.Request().Method,
+                    "path", c.Request().URL.Path,
+                    "panic", fmt.Sprint(r),
+                    "stack", string(stack),
+                )
+                hooks.Emit(hooks.Panic, c, &hooks.Info{Err: panicErr, Stack: stack})
             }
+            err = errors.Handle(panicErr, c)
         }()
         return next(c)
     }
-}
\ No newline at end of file
+}
+
+// PanicError converts recovered value to error, values which aren't errors are wrapped in errors.PanicError.
+func PanicError(r interface{}) error {
+    if err, ok := r.(errors.APIException); ok {
+        return err
+    }
+    if err, ok := r.(*echo.HTTPError); ok {
+        return err
+    }
+    return &errors.PanicError{Value: r}
+}
@@ -0,0 +1,66 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git/errors"
+)
+
+// TimeoutConfig configures TimeoutMiddleware.
+type TimeoutConfig struct {
+	Timeout		time.Duration
+	// StatusCode when deadline is exceeded, default 503, use 504 behind a gateway.
+	StatusCode	int
+	Message		string
+	Skipper		func(c echo.Context) bool
+}
+
+// Timeout is TimeoutMiddleware with default config,
+// example: server.GET("/reports", handler, middlewares.Timeout(5 * time.Second))
+func Timeout(timeout time.Duration) echo.MiddlewareFunc {
+	return TimeoutMiddleware(TimeoutConfig{Timeout: timeout})
+}
+
+// TimeoutMiddleware cancels the request context after Timeout. Viewset and serializer
+// queries are bound to the request context, so they are aborted, and the client gets
+// a JSON error if the handler hasn't responded. Handlers doing other long work
+// should watch c.Request().Context().Done().
+func TimeoutMiddleware(config TimeoutConfig) echo.MiddlewareFunc {
+	if config.StatusCode == 0 {
+		config.StatusCode = http.StatusServiceUnavailable
+	}
+	if config.Message == "" {
+		config.Message = "Request timed out."
+	}
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			if config.Timeout <= 0 || (config.Skipper != nil && config.Skipper(c)) {
+				return next(c)
+			}
+			ctx, cancel := context.WithTimeout(c.Request().Context(), config.Timeout)
+			defer cancel()
+			c.SetRequest(c.Request().WithContext(ctx))
+
+			timedOut := func() bool {
+				return ctx.Err() == context.DeadlineExceeded && !c.Response().Committed
+			}
+			// aborted queries panic with InternalServerError, replace it with the timeout response.
+			defer func() {
+				if r := recover(); r != nil {
+					if !timedOut() {
+						panic(r)
+					}
+					err = errors.Handle(&errors.APIError{Status: config.StatusCode, Message: config.Message}, c)
+				}
+			}()
+			err = next(c)
+			if timedOut() {
+				return errors.Handle(&errors.APIError{Status: config.StatusCode, Message: config.Message}, c)
+			}
+			return err
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package middlewares
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// VerboseBodyLimit is the number of body bytes echoed by VerboseLoggerMiddleware.
+var VerboseBodyLimit = 2048
+
+// VerboseLoggerMiddleware echoes request line, headers and body then status of the response,
+// for development only, values of DefaultRedactQuery and DefaultRedactHeaders are redacted.
+func VerboseLoggerMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		start := time.Now()
+		request := c.Request()
+		var body []byte
+		if request.Body != nil {
+			body, _ = io.ReadAll(request.Body)
+			request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		var buffer strings.Builder
+		fmt.Fprintf(&buffer, "[%s] %s %s %s\n", start.Format("02/Jan/2006 15:04:05"), request.Method, redactURI(request.URL, DefaultRedactQuery), request.Proto)
+		names := make([]string, 0, len(request.Header))
+		for name := range request.Header {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			value := strings.Join(request.Header.Values(name), ", ")
+			if containsFold(DefaultRedactHeaders, name) {
+				value = redacted
+			}
+			fmt.Fprintf(&buffer, "  %s: %s\n", name, value)
+		}
+		if len(body) > 0 {
+			echoed := body
+			if len(echoed) > VerboseBodyLimit {
+				echoed = echoed[:VerboseBodyLimit]
+			}
+			fmt.Fprintf(&buffer, "  \n  %s", echoed)
+			if len(body) > VerboseBodyLimit {
+				fmt.Fprintf(&buffer, "... (%d bytes)", len(body))
+			}
+			buffer.WriteString("\n")
+		}
+
+		err := next(c)
+		status := c.Response().Status
+		if err != nil && !c.Response().Committed {
+			if httpErr, ok := err.(*echo.HTTPError); ok {
+				status = httpErr.Code
+			}
+		}
+		fmt.Fprintf(&buffer, "  -> %d %d bytes (Duration: %.2f ms)\n", status, c.Response().Size, float64(time.Since(start).Microseconds()) / 1000)
+		if err != nil {
+			fmt.Fprintf(&buffer, "  error: %s\n", err)
+		}
+		fmt.Fprint(os.Stdout, buffer.String())
+		return err
+	}
+}
@@ -0,0 +1,253 @@
+package migrations
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rimba47prayoga/gorim.git/conf"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// recorder is gorm logger collecting sql of dry run session.
+type recorder struct {
+	logger.Interface
+	statements	[]string
+}
+
+func (r *recorder) LogMode(logger.LogLevel) logger.Interface {
+	return r
+}
+
+func (r *recorder) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	sql, _ := fc()
+	keyword, _, _ := strings.Cut(strings.TrimSpace(sql), " ")
+	switch strings.ToUpper(keyword) {
+	case "SELECT", "PRAGMA", "SHOW", "WITH":
+		return
+	}
+	r.statements = append(r.statements, sql)
+}
+
+// dryRun returns sql gorm migrator would execute in run. Migrators recreating
+// tables read the database, they can't run dry and the panic is returned as error.
+func dryRun(db *gorm.DB, run func(migrator gorm.Migrator) error) (sql string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%s migrator can't generate the sql: %v", db.Dialector.Name(), r)
+		}
+	}()
+	record := &recorder{Interface: logger.Discard}
+	session := db.Session(&gorm.Session{DryRun: true, Logger: record})
+	if err := run(session.Migrator()); err != nil {
+		return "", err
+	}
+	var builder strings.Builder
+	for _, statement := range record.statements {
+		builder.WriteString(strings.TrimSuffix(strings.TrimSpace(statement), ";"))
+		builder.WriteString(";\n")
+	}
+	return builder.String(), nil
+}
+
+// changes collects up sql and down sql, down is reversed when the migration is built.
+type changes struct {
+	up		[]string
+	down	[]string
+}
+
+func (c *changes) add(comment string, up string, down string) {
+	c.up = append(c.up, "-- " + comment + "\n" + up)
+	c.down = append(c.down, "-- revert " + comment + "\n" + down)
+}
+
+// errDiffed rolls back pending migrations applied to diff models with them.
+var errDiffed = stderrors.New("migrations: diffed")
+
+// MakeMigrations diffs AllModels with the database and returns migration creating missing
+// tables, columns and indexes, altering changed columns and dropping columns removed
+// from models. Returns nil when nothing changed, the migration isn't written.
+// Pending migration files are applied in a transaction rolled back after the diff, so
+// their changes aren't made again, databases without transactional DDL such as mysql
+// must be migrated first.
+func (m *Migrations) MakeMigrations(name string) (*FileMigration, error) {
+	files, err := m.Files()
+	if err != nil {
+		return nil, err
+	}
+	applied := m.Applied()
+	pending := []FileMigration{}
+	for _, file := range files {
+		if !applied[file.Name] {
+			pending = append(pending, file)
+		}
+	}
+	result := &changes{}
+	if len(pending) == 0 {
+		err = m.diff(conf.DB, result)
+	} else if conf.DB.Dialector.Name() == "mysql" {
+		return nil, fmt.Errorf("migration %s is not applied, run migrate first", pending[0].Name)
+	} else {
+		err = conf.DB.Transaction(func(tx *gorm.DB) error {
+			for _, file := range pending {
+				if err := execute(tx, file.Up); err != nil {
+					return fmt.Errorf("migration %s: %w", file.Name, err)
+				}
+			}
+			if err := m.diff(tx, result); err != nil {
+				return err
+			}
+			return errDiffed
+		})
+		if err == errDiffed {
+			err = nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(result.up) == 0 {
+		return nil, nil
+	}
+	for i, j := 0, len(result.down) - 1; i < j; i, j = i + 1, j - 1 {
+		result.down[i], result.down[j] = result.down[j], result.down[i]
+	}
+	return &FileMigration{
+		Name: nextName(files, name),
+		Up: strings.Join(result.up, "\n"),
+		Down: strings.Join(result.down, "\n"),
+	}, nil
+}
+
+func (m *Migrations) diff(db *gorm.DB, result *changes) error {
+	for _, model := range m.AllModels() {
+		if err := m.diffModel(db, model, result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Migrations) diffModel(db *gorm.DB, model interface{}, result *changes) error {
+	statement := &gorm.Statement{DB: db}
+	if err := statement.Parse(model); err != nil {
+		return err
+	}
+	modelSchema := statement.Schema
+	migrator := db.Migrator()
+	if !migrator.HasTable(model) {
+		up, err := dryRun(db, func(migrator gorm.Migrator) error {
+			return migrator.CreateTable(model)
+		})
+		if err != nil {
+			return err
+		}
+		down, err := dryRun(db, func(migrator gorm.Migrator) error {
+			return migrator.DropTable(model)
+		})
+		if err != nil {
+			return err
+		}
+		result.add("create table " + modelSchema.Table, up, down)
+		return nil
+	}
+
+	columnTypes, err := migrator.ColumnTypes(model)
+	if err != nil {
+		return err
+	}
+	columns := map[string]gorm.ColumnType{}
+	for _, columnType := range columnTypes {
+		columns[columnType.Name()] = columnType
+	}
+	for _, dbName := range modelSchema.DBNames {
+		field := modelSchema.FieldsByDBName[dbName]
+		if field.IgnoreMigration {
+			continue
+		}
+		columnType, exists := columns[dbName]
+		delete(columns, dbName)
+		table := modelSchema.Table + "." + dbName
+		if !exists {
+			if err := m.addColumn(db, model, modelSchema.Table, dbName, result); err != nil {
+				return err
+			}
+			continue
+		}
+		up, err := dryRun(db, func(migrator gorm.Migrator) error {
+			return migrator.MigrateColumn(model, field, columnType)
+		})
+		if err != nil {
+			up = fmt.Sprintf("-- %s, change %s to %s manually.\n", err, table, db.Dialector.DataTypeOf(field))
+		}
+		if up != "" {
+			result.add("alter column " + table, up, "-- column was " + describeColumn(columnType) + ", revert it manually.\n")
+		}
+	}
+	for dbName, columnType := range columns {
+		up := dropColumn(modelSchema.Table, dbName)
+		down := fmt.Sprintf(
+			"ALTER TABLE %s ADD %s %s;\n",
+			quote(modelSchema.Table), quote(dbName), describeColumn(columnType),
+		)
+		result.add("drop column " + modelSchema.Table + "." + dbName, up, down)
+	}
+	for _, index := range modelSchema.ParseIndexes() {
+		if migrator.HasIndex(model, index.Name) {
+			continue
+		}
+		up, err := dryRun(db, func(migrator gorm.Migrator) error {
+			return migrator.CreateIndex(model, index.Name)
+		})
+		if err != nil {
+			return err
+		}
+		down, err := dryRun(db, func(migrator gorm.Migrator) error {
+			return migrator.DropIndex(model, index.Name)
+		})
+		if err != nil {
+			return err
+		}
+		result.add("create index " + index.Name, up, down)
+	}
+	return nil
+}
+
+func (m *Migrations) addColumn(db *gorm.DB, model interface{}, table string, dbName string, result *changes) error {
+	up, err := dryRun(db, func(migrator gorm.Migrator) error {
+		return migrator.AddColumn(model, dbName)
+	})
+	if err != nil {
+		return err
+	}
+	result.add("add column " + table + "." + dbName, up, dropColumn(table, dbName))
+	return nil
+}
+
+// dropColumn returns sql supported by postgres, mysql and sqlite 3.35+,
+// migrators of sqlite recreate the table instead and can't run dry.
+func dropColumn(table string, column string) string {
+	return fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;\n", quote(table), quote(column))
+}
+
+// describeColumn returns column definition of the database, example: "varchar(255) NOT NULL".
+func describeColumn(columnType gorm.ColumnType) string {
+	definition, ok := columnType.ColumnType()
+	if !ok || definition == "" {
+		definition = columnType.DatabaseTypeName()
+	}
+	if nullable, ok := columnType.Nullable(); ok && !nullable {
+		definition += " NOT NULL"
+	}
+	return definition
+}
+
+func quote(name string) string {
+	var builder strings.Builder
+	conf.DB.Dialector.QuoteTo(&builder, name)
+	return builder.String()
+}
+
@@ -0,0 +1,224 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rimba47prayoga/gorim.git/conf"
+	"gorm.io/gorm"
+)
+
+// FileMigration is a versioned migration written by makemigrations,
+// stored as "<number>_<name>.up.sql" and "<number>_<name>.down.sql" in Dir.
+type FileMigration struct {
+	Name	string
+	Up		string
+	Down	string
+}
+
+var migrationFile = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// LoadFiles returns migrations in dir ordered by number.
+func LoadFiles(dir string) ([]FileMigration, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	byName := map[string]*FileMigration{}
+	for _, entry := range entries {
+		match := migrationFile.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		name := match[1] + "_" + match[2]
+		migration, ok := byName[name]
+		if !ok {
+			migration = &FileMigration{Name: name}
+			byName[name] = migration
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+	migrations := make([]FileMigration, 0, len(byName))
+	for _, migration := range byName {
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Name < migrations[j].Name
+	})
+	return migrations, nil
+}
+
+// Statements splits sql into statements ending with ";" at the end of a line,
+// lines starting with "--" are comments.
+func Statements(sql string) []string {
+	statements := []string{}
+	var current strings.Builder
+	for _, line := range strings.Split(sql, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSpace(current.String()))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
+
+func execute(tx *gorm.DB, sql string) error {
+	for _, statement := range Statements(sql) {
+		if err := tx.Exec(statement).Error; err != nil {
+			return fmt.Errorf("%w\n%s", err, statement)
+		}
+	}
+	return nil
+}
+
+// Applied returns names of applied migrations.
+func (m *Migrations) Applied() map[string]bool {
+	m.CreateMigrationTable()
+	var records []GorimMigrations
+	conf.DB.Find(&records)
+	applied := map[string]bool{}
+	for _, record := range records {
+		applied[record.Name] = true
+	}
+	return applied
+}
+
+// ApplyFiles applies pending migrations of Dir in order, each in its own transaction.
+func (m *Migrations) ApplyFiles() error {
+	files, err := m.Files()
+	if err != nil {
+		return err
+	}
+	applied := m.Applied()
+	for _, file := range files {
+		if applied[file.Name] {
+			continue
+		}
+		err := conf.DB.Transaction(func(tx *gorm.DB) error {
+			if err := execute(tx, file.Up); err != nil {
+				return err
+			}
+			return tx.Create(&GorimMigrations{Name: file.Name, CreatedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("migration %s: %w", file.Name, err)
+		}
+		m.hasChanges = true
+		fmt.Printf("%s migration applied\n", file.Name)
+	}
+	return nil
+}
+
+// RunFiles wraps ApplyFiles as operation, example:
+//
+//	MigrationInstance.AddOperation(migrations.Operation{Name: "files", Func: MigrationInstance.RunFiles()})
+func (m *Migrations) RunFiles() func(string) error {
+	return func(name string) error {
+		err := m.ApplyFiles()
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		return nil
+	}
+}
+
+// Rollback reverts the last steps applied migrations of Dir with their down sql.
+func (m *Migrations) Rollback(steps int) error {
+	files, err := m.Files()
+	if err != nil {
+		return err
+	}
+	applied := m.Applied()
+	for i := len(files) - 1; i >= 0 && steps > 0; i-- {
+		file := files[i]
+		if !applied[file.Name] {
+			continue
+		}
+		err := conf.DB.Transaction(func(tx *gorm.DB) error {
+			if err := execute(tx, file.Down); err != nil {
+				return err
+			}
+			return tx.Where("name = ?", file.Name).Delete(&GorimMigrations{}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("rollback %s: %w", file.Name, err)
+		}
+		fmt.Printf("%s migration reverted\n", file.Name)
+		steps--
+	}
+	return nil
+}
+
+func (m *Migrations) dir() string {
+	if m.Dir != "" {
+		return m.Dir
+	}
+	return conf.MIGRATIONS_DIR
+}
+
+// nextName returns name of the next migration, example: "0003_add_slug".
+func nextName(files []FileMigration, name string) string {
+	number := 0
+	for _, file := range files {
+		prefix, _, _ := strings.Cut(file.Name, "_")
+		if n, err := strconv.Atoi(prefix); err == nil && n > number {
+			number = n
+		}
+	}
+	if name == "" {
+		name = "auto_" + time.Now().Format("20060102_1504")
+		if number == 0 {
+			name = "initial"
+		}
+	}
+	name = regexp.MustCompile(`\W+`).ReplaceAllString(strings.ToLower(name), "_")
+	return fmt.Sprintf("%04d_%s", number + 1, name)
+}
+
+// Files returns migrations of Dir ordered by number.
+func (m *Migrations) Files() ([]FileMigration, error) {
+	return LoadFiles(m.dir())
+}
+
+// WriteFile writes up and down sql of the migration to Dir, returns the directory.
+func (m *Migrations) WriteFile(migration FileMigration) (string, error) {
+	dir := m.dir()
+	return dir, writeFile(dir, migration)
+}
+
+func writeFile(dir string, migration FileMigration) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, migration.Name + ".up.sql"), []byte(migration.Up), 0644); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, migration.Name + ".down.sql"), []byte(migration.Down), 0644)
+}
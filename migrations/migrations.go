@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/rimba47prayoga/gorim.git/apps"
 	"github.com/rimba47prayoga/gorim.git/conf"
 )
 
@@ -20,6 +21,8 @@ type Operation struct {
 type Migrations struct {
 	Models		[]interface{}
 	Operations	[]Operation
+	// Dir holds migration files of makemigrations, defaults to conf.MIGRATIONS_DIR.
+	Dir			string
 	hasChanges	bool
 }
 
@@ -34,6 +37,21 @@ func (m *Migrations) CreateMigrationTable() {
 	}
 }
 
+// AllModels returns Models followed by models of registered apps, each model once.
+func (m *Migrations) AllModels() []interface{} {
+	models := []interface{}{}
+	seen := map[reflect.Type]bool{}
+	for _, model := range append(append([]interface{}{}, m.Models...), apps.Models()...) {
+		modelType := reflect.TypeOf(model)
+		if seen[modelType] {
+			continue
+		}
+		seen[modelType] = true
+		models = append(models, model)
+	}
+	return models
+}
+
 // Serialize a model's structure into a consistent string representation, excluding non-database fields
 func (m *Migrations) SerializeModel(model interface{}) string {
     t := reflect.TypeOf(model).Elem() // Get the type of the model
@@ -60,7 +78,7 @@ func (m *Migrations) SerializeModel(model interface{}) string {
 // Generate a hash from the serialized model structures
 func (m *Migrations) GenerateHash() string {
     var combinedString string
-    for _, model := range m.Models {
+    for _, model := range m.AllModels() {
         combinedString += m.SerializeModel(model)
     }
 
@@ -75,20 +93,30 @@ func (m *Migrations) AddOperation(operation Operation) {
 
 func (m *Migrations) MigrateModels() {
 	err := conf.DB.AutoMigrate(
-		m.Models...
+		m.AllModels()...
 	)
 	if err != nil {
 		panic(err)
 	}
 }
 
+// RunMigrationModels auto migrates AllModels when they change, until makemigrations
+// writes files: models are then migrated by the files only, so their changes aren't
+// made twice.
 func (m *Migrations) RunMigrationModels() func(string) error {
 
 	// wrap to function, cause it called from commandline
 	return func(name string) error {
+		files, err := m.Files()
+		if err != nil {
+			return err
+		}
+		if len(files) > 0 {
+			return nil
+		}
 		hashVersion := m.GenerateHash()
 		var migration GorimMigrations
-		err := conf.DB.Where("name = ?", name).First(&migration).Error
+		err = conf.DB.Where("name = ?", name).First(&migration).Error
 		if err != nil {
 			m.MigrateModels()
 			migration := GorimMigrations{
@@ -147,6 +175,10 @@ func (m *Migrations) Run() {
 	for _, operation := range m.Operations {
 		operation.Func(operation.Name)
 	}
+	// files not applied by RunFiles operation are applied after the operations.
+	if err := m.ApplyFiles(); err != nil {
+		log.Fatal(err.Error())
+	}
 	if !m.hasChanges {
 		fmt.Println("No changes detected.")
 	}
@@ -0,0 +1,66 @@
+package gorim
+
+import (
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Model is the base of models:
+//
+//	type Product struct {
+//		gorim.Model
+//		Name	string	`json:"name"`
+//	}
+//
+// CreatedAt is set on create and UpdatedAt on every save, deletes only set DeletedAt and
+// deleted rows are left out of queries. Other time fields are set like them with gorm tags
+// autoCreateTime (auto now add) or autoUpdateTime (auto now).
+// audit.Register doesn't record UpdatedAt, so saves changing nothing else aren't recorded.
+type Model struct {
+	ID			uint			`gorm:"primarykey" json:"id"`
+	CreatedAt	time.Time		`gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt	time.Time		`gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt	gorm.DeletedAt	`gorm:"index" json:"-"`
+}
+
+var modelType = reflect.TypeOf(Model{})
+
+// GetID returns primary key of the model.
+func (m *Model) GetID() uint {
+	return m.ID
+}
+
+// IsNew reports whether the model hasn't been created yet.
+func (m *Model) IsNew() bool {
+	return m.ID == 0
+}
+
+// IsDeleted reports whether the model was soft deleted, only loaded with Unscoped.
+func (m *Model) IsDeleted() bool {
+	return m.DeletedAt.Valid
+}
+
+// Touch sets UpdatedAt, and CreatedAt of created model, to now. Serializers touch models
+// before sending signals.PreSave, so handlers see the timestamps being saved.
+func (m *Model) Touch(created bool) {
+	now := time.Now()
+	if created && m.CreatedAt.IsZero() {
+		m.CreatedAt = now
+	}
+	m.UpdatedAt = now
+}
+
+// IsModel reports whether model embeds Model.
+func IsModel(model interface{}) bool {
+	typ := reflect.TypeOf(model)
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return false
+	}
+	field, ok := typ.FieldByName("Model")
+	return ok && field.Anonymous && field.Type == modelType
+}
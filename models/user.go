@@ -1,8 +1,12 @@
 package models
 
 import (
+	"reflect"
+
+	"github.com/rimba47prayoga/gorim.git/conf"
 	"github.com/rimba47prayoga/gorim.git/errors"
 	"github.com/rimba47prayoga/gorim.git/utils"
+	"gorm.io/gorm"
 )
 
 
@@ -11,12 +15,36 @@ type User struct {
 	Username	string			`gorm:"type:varchar(255)" json:"username"`
 	Email		string			`gorm:"type:varchar(255)" json:"email"`
 	Password	string			`gorm:"type:varchar(255)" json:"password"`
+	IsAdmin		bool			`gorm:"default:false" json:"is_admin"`
+}
+
+func (m *User) IsAdminUser() bool {
+	return m.IsAdmin
+}
+
+func (m *User) SetPassword(passwd string) {
+	hashedPassword, err := utils.HashPassword(passwd)
+	if err != nil {
+		errors.Raise(&errors.InternalServerError{
+			Message: err.Error(),
+		})
+	}
+	m.Password = hashedPassword
+}
+
+func (m *User) CheckPassword(passwd string) bool {
+	return utils.VerifyPassword(passwd, m.Password)
 }
 
 type AbstractUser struct {
 	BaseModel
 	Email		string			`gorm:"type:varchar(255)" json:"email"`
 	Password	string			`gorm:"type:varchar(255)" json:"password"`
+	IsAdmin		bool			`gorm:"default:false" json:"is_admin"`
+}
+
+func (m *AbstractUser) IsAdminUser() bool {
+	return m.IsAdmin
 }
 
 func (m *AbstractUser) SetPassword(passwd string) {
@@ -32,3 +60,33 @@ func (m *AbstractUser) SetPassword(passwd string) {
 func (m *AbstractUser) CheckPassword(passwd string) bool {
 	return utils.VerifyPassword(passwd, m.Password)
 }
+
+// NewUser returns pointer to new instance of conf.USER_MODEL, models.User when it isn't set.
+func NewUser() interface{} {
+	if conf.USER_MODEL == nil {
+		return &User{}
+	}
+	typ := reflect.TypeOf(conf.USER_MODEL)
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return reflect.New(typ).Interface()
+}
+
+// GetUserByID loads user of conf.USER_MODEL by primary key.
+func GetUserByID(db *gorm.DB, userID interface{}) (interface{}, error) {
+	user := NewUser()
+	if err := db.First(user, "id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// GetUserByUsername loads user of conf.USER_MODEL by conf.USERNAME_FIELD.
+func GetUserByUsername(db *gorm.DB, username string) (interface{}, error) {
+	user := NewUser()
+	if err := db.Where(conf.USERNAME_FIELD + " = ?", username).First(user).Error; err != nil {
+		return nil, err
+	}
+	return user, nil
+}
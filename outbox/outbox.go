@@ -0,0 +1,161 @@
+package outbox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GorimEvent is an event waiting in gorim_outbox until the relay publishes it, add
+// &outbox.GorimEvent{} to migration models.
+type GorimEvent struct {
+	ID			uint		`gorm:"primarykey"`
+	Topic		string		`gorm:"type:varchar(255)"`
+	// Type of the event, example: order.created
+	Type		string		`gorm:"type:varchar(255)"`
+	// Key is the primary key of the instance, brokers partition by it.
+	Key			string		`gorm:"type:varchar(255)"`
+	Payload		string		`gorm:"type:text"`
+	Attempts	int
+	LastError	string		`gorm:"type:text"`
+	CreatedAt	time.Time
+	PublishedAt	*time.Time	`gorm:"index"`
+}
+
+func (m GorimEvent) TableName() string {
+	return "gorim_outbox"
+}
+
+type registration struct {
+	topic	string
+	name	string
+}
+
+var (
+	registryMu	sync.RWMutex
+	registry	= map[reflect.Type]registration{}
+)
+
+// Register records "<name>.created", "<name>.updated" and "<name>.deleted" events of
+// model T to topic in the transaction changing it, the instance as json is the payload.
+// Changes are recorded by Plugin, example: outbox.Register[Order]("shop.orders", "order")
+func Register[T any](topic string, name string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[reflect.TypeOf((*T)(nil)).Elem()] = registration{topic: topic, name: name}
+}
+
+// Publish records event of type eventType to topic in db, which should be the transaction
+// of the changes it describes, example:
+//
+//	conf.DB.Transaction(func(tx *gorm.DB) error {
+//		if err := tx.Model(&Order{}).Where("paid = ?", false).Update("status", "expired").Error; err != nil {
+//			return err
+//		}
+//		return outbox.Publish(tx, "shop.orders", "orders.expired", "", nil)
+//	})
+func Publish(db *gorm.DB, topic string, eventType string, key string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return db.Create(&GorimEvent{Topic: topic, Type: eventType, Key: key, Payload: string(payload)}).Error
+}
+
+// Plugin records events of models of Register after they're created, updated or deleted
+// through gorm, the events are inserted before the transaction of the statement commits,
+// so they're written together or not at all, example: conf.DB.Use(outbox.Plugin{})
+//
+// Serializers and viewsets save instances in transactions of gorm unless
+// SkipDefaultTransaction is set. Statements changing many rows without instances, such
+// as bulk updates, aren't recorded, use Publish in their transaction.
+type Plugin struct{}
+
+func (p Plugin) Name() string {
+	return "gorim:outbox"
+}
+
+func (p Plugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	return errors.Join(
+		callback.Create().After("gorm:create").Before("gorm:commit_or_rollback_transaction").
+			Register("gorim:outbox_after_create", record("created")),
+		callback.Update().After("gorm:update").Before("gorm:commit_or_rollback_transaction").
+			Register("gorim:outbox_after_update", record("updated")),
+		callback.Delete().After("gorm:delete").Before("gorm:commit_or_rollback_transaction").
+			Register("gorim:outbox_after_delete", record("deleted")),
+	)
+}
+
+func record(action string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		if db.Error != nil || db.RowsAffected == 0 || db.Statement.Schema == nil {
+			return
+		}
+		registryMu.RLock()
+		registered, ok := registry[db.Statement.Schema.ModelType]
+		registryMu.RUnlock()
+		if !ok {
+			return
+		}
+		events := []GorimEvent{}
+		for _, instance := range changedInstances(db) {
+			key, zero := db.Statement.Schema.PrioritizedPrimaryField.ValueOf(db.Statement.Context, instance)
+			if zero {
+				continue
+			}
+			payload, err := json.Marshal(instance.Addr().Interface())
+			if err != nil {
+				db.AddError(err)
+				return
+			}
+			events = append(events, GorimEvent{
+				Topic: registered.topic,
+				Type: registered.name + "." + action,
+				Key: fmt.Sprint(key),
+				Payload: string(payload),
+			})
+		}
+		if len(events) == 0 {
+			return
+		}
+		// NewDB keeps the connection of the statement, which is its transaction.
+		tx := db.Session(&gorm.Session{NewDB: true, SkipHooks: true})
+		if err := tx.Create(&events).Error; err != nil {
+			db.AddError(fmt.Errorf("outbox: %w", err))
+		}
+	}
+}
+
+// changedInstances returns addressable instances of the statement, none for statements
+// without primary key.
+func changedInstances(db *gorm.DB) []reflect.Value {
+	if db.Statement.Schema.PrioritizedPrimaryField == nil {
+		return nil
+	}
+	value := reflect.Indirect(db.Statement.ReflectValue)
+	instances := []reflect.Value{}
+	switch value.Kind() {
+	case reflect.Struct:
+		if value.CanAddr() {
+			instances = append(instances, value)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if element := reflect.Indirect(value.Index(i)); element.Kind() == reflect.Struct && element.CanAddr() {
+				instances = append(instances, element)
+			}
+		}
+	}
+	return instances
+}
+
+// ClearPublished deletes events published before time, example: old events of a day.
+func ClearPublished(db *gorm.DB, before time.Time) error {
+	return db.Where("published_at < ?", before).Delete(&GorimEvent{}).Error
+}
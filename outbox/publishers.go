@@ -0,0 +1,89 @@
+package outbox
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Publisher sends events to a broker, the relay retries events it fails, so consumers
+// receive them at least once and dedupe by ID.
+type Publisher interface {
+	Publish(ctx context.Context, event *GorimEvent) error
+}
+
+// PublisherFunc adapts a function to Publisher, example with a client of Kafka:
+//
+//	outbox.DefaultRelay.Publisher = outbox.PublisherFunc(func(ctx context.Context, event *outbox.GorimEvent) error {
+//		return writer.WriteMessages(ctx, kafka.Message{
+//			Topic: event.Topic,
+//			Key: []byte(event.Key),
+//			Value: []byte(event.Payload),
+//			Headers: []kafka.Header{{Key: "type", Value: []byte(event.Type)}},
+//		})
+//	})
+//
+// or NATS JetStream, whose Nats-Msg-Id header dedupes redeliveries:
+//
+//	outbox.DefaultRelay.Publisher = outbox.PublisherFunc(func(ctx context.Context, event *outbox.GorimEvent) error {
+//		_, err := js.Publish(ctx, event.Topic, []byte(event.Payload), jetstream.WithMsgID(strconv.Itoa(int(event.ID))))
+//		return err
+//	})
+type PublisherFunc func(ctx context.Context, event *GorimEvent) error
+
+func (f PublisherFunc) Publish(ctx context.Context, event *GorimEvent) error {
+	return f(ctx, event)
+}
+
+// RedisStreamPublisher adds events to redis streams named Prefix + topic with fields
+// id, type, key and payload.
+type RedisStreamPublisher struct {
+	Client	redis.UniversalClient
+	Prefix	string
+	// MaxLen trims streams approximately to this length, zero doesn't trim.
+	MaxLen	int64
+}
+
+func NewRedisStreamPublisher(client redis.UniversalClient) *RedisStreamPublisher {
+	return &RedisStreamPublisher{Client: client}
+}
+
+func (p *RedisStreamPublisher) Publish(ctx context.Context, event *GorimEvent) error {
+	return p.Client.XAdd(ctx, &redis.XAddArgs{
+		Stream: p.Prefix + event.Topic,
+		MaxLen: p.MaxLen,
+		Approx: p.MaxLen > 0,
+		Values: map[string]interface{}{
+			"id": strconv.FormatUint(uint64(event.ID), 10),
+			"type": event.Type,
+			"key": event.Key,
+			"payload": event.Payload,
+		},
+	}).Err()
+}
+
+// MemoryPublisher keeps published events, for tests.
+type MemoryPublisher struct {
+	mu		sync.Mutex
+	events	[]GorimEvent
+}
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+func (p *MemoryPublisher) Publish(ctx context.Context, event *GorimEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, *event)
+	return nil
+}
+
+// Events returns published events in order.
+func (p *MemoryPublisher) Events() []GorimEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]GorimEvent{}, p.events...)
+}
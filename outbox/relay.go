@@ -0,0 +1,143 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/rimba47prayoga/gorim.git/conf"
+	"github.com/rimba47prayoga/gorim.git/locks"
+	"gorm.io/gorm"
+)
+
+// Relay publishes recorded events in order of ID. Every replica can run it, only the
+// replica holding the lock of LockName publishes, so the order is kept. A failing event
+// is retried after Interval and holds back later events.
+type Relay struct {
+	Publisher	Publisher
+	// DB default conf.DB.
+	DB			*gorm.DB
+	// Locker elects the leader, default locks.Default, which is shared by replicas only
+	// when it has a redis or database backend.
+	Locker		*locks.Locker
+	// LockName default "outbox".
+	LockName	string
+	// BatchSize is how many events are loaded at once, default 100.
+	BatchSize	int
+	// Interval between checks of new events, default 1 second.
+	Interval	time.Duration
+}
+
+// DefaultRelay is run by the worker command when its Publisher is set.
+var DefaultRelay = &Relay{}
+
+func (r *Relay) GetDB() *gorm.DB {
+	if r.DB != nil {
+		return r.DB
+	}
+	return conf.DB
+}
+
+func (r *Relay) GetLocker() *locks.Locker {
+	if r.Locker != nil {
+		return r.Locker
+	}
+	return locks.Default
+}
+
+func (r *Relay) GetLockName() string {
+	if r.LockName != "" {
+		return r.LockName
+	}
+	return "outbox"
+}
+
+func (r *Relay) GetBatchSize() int {
+	if r.BatchSize > 0 {
+		return r.BatchSize
+	}
+	return 100
+}
+
+func (r *Relay) GetInterval() time.Duration {
+	if r.Interval > 0 {
+		return r.Interval
+	}
+	return time.Second
+}
+
+// Run publishes events while leading until ctx is done.
+func (r *Relay) Run(ctx context.Context) error {
+	if r.Publisher == nil {
+		return errors.New("outbox: relay has no publisher")
+	}
+	var lock *locks.Lock
+	var extended time.Time
+	defer func() {
+		if lock != nil {
+			lock.Release(context.WithoutCancel(ctx))
+		}
+	}()
+	for {
+		now := time.Now()
+		if lock == nil {
+			var err error
+			lock, err = r.GetLocker().TryAcquire(ctx, r.GetLockName())
+			if err == nil {
+				extended = now
+				slog.Info("outbox: relay is leading", "lock", r.GetLockName(), "fence", lock.Fence)
+			} else if err != locks.ErrNotAcquired && ctx.Err() == nil {
+				slog.Error("outbox: relay election failed", "error", err)
+			}
+		} else if now.Sub(extended) >= lock.TTL / 3 {
+			if err := lock.Extend(ctx); err != nil {
+				slog.Warn("outbox: relay lost leadership", "error", err)
+				lock = nil
+			} else {
+				extended = now
+			}
+		}
+		wait := r.GetInterval()
+		if lock != nil {
+			published, err := r.Flush(ctx)
+			if err != nil && ctx.Err() == nil {
+				slog.Error("outbox: publishing failed", "error", err)
+			} else if published == r.GetBatchSize() {
+				// more events are waiting.
+				wait = 0
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(wait):
+		}
+	}
+}
+
+// Flush publishes one batch of events and returns how many were published, it stops at
+// the first event failing.
+func (r *Relay) Flush(ctx context.Context) (int, error) {
+	db := r.GetDB().WithContext(ctx)
+	var events []GorimEvent
+	err := db.Where("published_at IS NULL").Order("id").Limit(r.GetBatchSize()).Find(&events).Error
+	if err != nil {
+		return 0, err
+	}
+	for i := range events {
+		event := &events[i]
+		if err := r.Publisher.Publish(ctx, event); err != nil {
+			updateErr := db.Model(event).Updates(map[string]interface{}{
+				"attempts": gorm.Expr("attempts + 1"),
+				"last_error": err.Error(),
+			}).Error
+			return i, errors.Join(err, updateErr)
+		}
+		now := time.Now()
+		if err := db.Model(event).Update("published_at", &now).Error; err != nil {
+			return i, err
+		}
+	}
+	return len(events), nil
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/mcuadros/go-defaults"
 	"github.com/rimba47prayoga/gorim.git/errors"
 	"github.com/rimba47prayoga/gorim.git/models"
+	"github.com/rimba47prayoga/gorim.git/settings"
 	"github.com/rimba47prayoga/gorim.git/utils"
 	"gorm.io/gorm"
 )
@@ -31,7 +32,7 @@ func (p *Pagination) GetOffset() int {
 
 func (p *Pagination) GetLimit() int {   
     if p.PageSize == 0 {   
-        p.PageSize = 10    
+        p.PageSize = settings.Get().Pagination.PageSize
     }   
     return p.PageSize  
 }
@@ -70,6 +71,8 @@ func InitPagination(ctx echo.Context, db *gorm.DB) *Pagination {
         QuerySet: db,
     }
 	defaults.SetDefaults(&pagination)
+	config := settings.Get().Pagination
+	pagination.PageSize = config.PageSize
 
 	page, _ := strconv.Atoi(ctx.QueryParam("page"))
     pageSize, _ := strconv.Atoi(ctx.QueryParam("page_size"))
@@ -81,6 +84,9 @@ func InitPagination(ctx echo.Context, db *gorm.DB) *Pagination {
     if pageSize > 0 {
         pagination.PageSize = pageSize
     }
+    if config.MaxPageSize > 0 && pagination.PageSize > config.MaxPageSize {
+        pagination.PageSize = config.MaxPageSize
+    }
     if sort != "" {
         pagination.Sort = sort
     }
@@ -0,0 +1,141 @@
+package gorim
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git/errors"
+)
+
+// Typed accessors of path and query params, parse failures are validation errors of
+// the param, so handlers return them as 400 responses, example:
+//
+//	pk, err := c.ParamInt("pk")
+//	if err != nil {
+//		return err
+//	}
+//	limit, err := c.QueryInt("limit", 20)
+
+// ParamInt returns path param name as int.
+func (c *Context) ParamInt(name string) (int, error) {
+	value, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		return 0, errors.Invalid(name, "A valid integer is required.")
+	}
+	return value, nil
+}
+
+// ParamUint returns path param name as uint, example: primary keys of gorm.Model.
+func (c *Context) ParamUint(name string) (uint, error) {
+	value, err := strconv.ParseUint(c.Param(name), 10, 0)
+	if err != nil {
+		return 0, errors.Invalid(name, "A valid integer is required.")
+	}
+	return uint(value), nil
+}
+
+// ParamUUID returns path param name as UUID.
+func (c *Context) ParamUUID(name string) (uuid.UUID, error) {
+	value, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		return uuid.Nil, errors.Invalid(name, "Must be a valid UUID.")
+	}
+	return value, nil
+}
+
+// QueryInt returns query param name as int, defaultValue when it's missing or empty.
+func (c *Context) QueryInt(name string, defaultValue int) (int, error) {
+	raw := c.QueryParam(name)
+	if raw == "" {
+		return defaultValue, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, errors.Invalid(name, "A valid integer is required.")
+	}
+	return value, nil
+}
+
+// QueryFloat returns query param name as float64, defaultValue when it's missing or empty.
+func (c *Context) QueryFloat(name string, defaultValue float64) (float64, error) {
+	raw := c.QueryParam(name)
+	if raw == "" {
+		return defaultValue, nil
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0, errors.Invalid(name, "A valid number is required.")
+	}
+	return value, nil
+}
+
+// QueryBool returns query param name as bool, defaultValue when it's missing or empty,
+// true, 1, yes and on are true, false, 0, no and off are false.
+func (c *Context) QueryBool(name string, defaultValue bool) (bool, error) {
+	switch strings.ToLower(c.QueryParam(name)) {
+	case "":
+		return defaultValue, nil
+	case "true", "1", "yes", "on":
+		return true, nil
+	case "false", "0", "no", "off":
+		return false, nil
+	}
+	return false, errors.Invalid(name, "Must be a valid boolean.")
+}
+
+// QueryTime returns query param name as time of RFC 3339 or date, example: 2024-01-31,
+// defaultValue when it's missing or empty.
+func (c *Context) QueryTime(name string, defaultValue time.Time) (time.Time, error) {
+	raw := c.QueryParam(name)
+	if raw == "" {
+		return defaultValue, nil
+	}
+	for _, layout := range []string{time.RFC3339Nano, time.DateOnly} {
+		if value, err := time.Parse(layout, raw); err == nil {
+			return value, nil
+		}
+	}
+	return time.Time{}, errors.Invalid(name, "Enter a valid date or date and time of RFC 3339.")
+}
+
+// QuerySlice returns values of query param name, repeated or comma separated,
+// example: ?tag=a&tag=b,c is [a b c].
+func (c *Context) QuerySlice(name string) []string {
+	values := []string{}
+	for _, raw := range c.QueryParams()[name] {
+		for _, value := range strings.Split(raw, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+// QueryIntSlice is QuerySlice of integers.
+func (c *Context) QueryIntSlice(name string) ([]int, error) {
+	values := []int{}
+	for _, raw := range c.QuerySlice(name) {
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, errors.Invalid(name, "A valid integer is required.")
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// Get returns value of key set on the context as T, false when it's missing or of
+// another type, example: cart, ok := gorim.Get[*Cart](c, "cart")
+func Get[T any](c echo.Context, key string) (T, bool) {
+	value, ok := c.Get(key).(T)
+	return value, ok
+}
+
+// Set stores value of key, the typed counterpart of Get.
+func Set[T any](c echo.Context, key string, value T) {
+	c.Set(key, value)
+}
@@ -0,0 +1,181 @@
+package parsers
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// bindValues binds form values and uploaded files by form tag, falling back to json tag,
+// nested struct fields are addressed by dotted keys, example: "address.city".
+func bindValues(v any, values url.Values, files map[string][]*UploadedFile) error {
+	root := reflect.ValueOf(target(v))
+	if root.Kind() != reflect.Ptr || root.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("form: cannot bind into %T", v)
+	}
+	for key, items := range values {
+		field, ok := lookupField(root.Elem(), key)
+		if !ok {
+			continue
+		}
+		if err := setValues(field, key, items); err != nil {
+			return err
+		}
+	}
+	for key, uploads := range files {
+		field, ok := lookupField(root.Elem(), key)
+		if !ok {
+			continue
+		}
+		switch field.Type() {
+		case uploadedFileType:
+			field.Set(reflect.ValueOf(uploads[0]))
+		case reflect.SliceOf(uploadedFileType):
+			field.Set(reflect.ValueOf(uploads))
+		default:
+			return fmt.Errorf("%s: expected %s, got file", key, field.Type())
+		}
+	}
+	return nil
+}
+
+var uploadedFileType = reflect.TypeOf(&UploadedFile{})
+
+// lookupField returns field of struct v at dotted key, nil pointers on the way are allocated.
+func lookupField(v reflect.Value, key string) (reflect.Value, bool) {
+	for _, name := range strings.Split(key, ".") {
+		for v.Kind() == reflect.Ptr && v.Type() != uploadedFileType {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, false
+		}
+		index, ok := fieldsByTag(v.Type(), "form", "json")[name]
+		if !ok {
+			// same as echo, untagged fields match case insensitively.
+			field, ok := v.Type().FieldByNameFunc(func(fieldName string) bool {
+				return strings.EqualFold(fieldName, name)
+			})
+			if !ok || !field.IsExported() {
+				return reflect.Value{}, false
+			}
+			index = field.Index
+		}
+		v = v.FieldByIndex(index)
+	}
+	return v, true
+}
+
+// setValues sets form values to the field, slices get every value and other kinds the first.
+func setValues(v reflect.Value, name string, values []string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setValues(v.Elem(), name, values)
+	}
+	if unmarshaler, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return unmarshaler.UnmarshalText([]byte(values[0]))
+	}
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 {
+		slice := reflect.MakeSlice(v.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setValues(slice.Index(i), name, []string{value}); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+		return nil
+	}
+	if v.Kind() == reflect.Slice {
+		v.SetBytes([]byte(values[0]))
+		return nil
+	}
+	return setScalar(v, name, values[0])
+}
+
+// setScalar parses text into string, bool and number kinds, empty text leaves zero value.
+func setScalar(v reflect.Value, name string, text string) error {
+	if v.Kind() == reflect.String {
+		v.SetString(text)
+		return nil
+	}
+	if text == "" {
+		return nil
+	}
+	var err error
+	switch v.Kind() {
+	case reflect.Bool:
+		var value bool
+		value, err = strconv.ParseBool(text)
+		v.SetBool(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var value int64
+		value, err = strconv.ParseInt(text, 10, v.Type().Bits())
+		v.SetInt(value)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var value uint64
+		value, err = strconv.ParseUint(text, 10, v.Type().Bits())
+		v.SetUint(value)
+	case reflect.Float32, reflect.Float64:
+		var value float64
+		value, err = strconv.ParseFloat(text, v.Type().Bits())
+		v.SetFloat(value)
+	default:
+		return fmt.Errorf("%s: unsupported type %s", name, v.Type())
+	}
+	if err != nil {
+		return fmt.Errorf("%s: invalid value %q for %s", name, text, v.Type())
+	}
+	return nil
+}
+
+// fieldsByTag maps names to field index, the name is taken from the first tag set,
+// embedded structs are flattened like json.
+func fieldsByTag(typ reflect.Type, keys ...string) map[string][]int {
+	fields := map[string][]int{}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name := tagName(field, keys...)
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && name == field.Name {
+			for embeddedName, index := range fieldsByTag(field.Type, keys...) {
+				if _, ok := fields[embeddedName]; !ok {
+					fields[embeddedName] = append([]int{i}, index...)
+				}
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		fields[name] = []int{i}
+	}
+	return fields
+}
+
+// tagName returns name of the first tag set on the field, field name otherwise.
+func tagName(field reflect.StructField, keys ...string) string {
+	for _, key := range keys {
+		tag, ok := field.Tag.Lookup(key)
+		if !ok {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
@@ -0,0 +1,221 @@
+package parsers
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"os"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git/errors"
+)
+
+// RequestParser is implemented by parsers which need the request,
+// example: multipart boundary is in Content-Type and files are cleaned up after the response.
+type RequestParser interface {
+	ParseRequest(c echo.Context, v any) error
+}
+
+// FormParser binds application/x-www-form-urlencoded bodies by form tag, falling back to json tag.
+type FormParser struct{}
+
+func (p *FormParser) MediaType() string {
+	return echo.MIMEApplicationForm
+}
+
+func (p *FormParser) Parse(r io.Reader, v any) error {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return err
+	}
+	return bindValues(v, values, nil)
+}
+
+const DefaultMaxFieldSize int64 = 1 << 20
+
+// MultipartParser streams multipart/form-data, files are written to temp files instead of memory
+// and bound to *parsers.UploadedFile or []*parsers.UploadedFile fields.
+// Temp files are removed after the response is written, use UploadedFile.Save to keep them.
+type MultipartParser struct {
+	// TempDir of uploaded files, default os.TempDir().
+	TempDir			string
+	// MaxFileSize limits each file, zero is unlimited, the request body is still limited by MAX_BODY_SIZE.
+	MaxFileSize		int64
+	// MaxFieldSize limits each non file field, default DefaultMaxFieldSize.
+	MaxFieldSize	int64
+	// FieldLimits overrides MaxFileSize and MaxFieldSize by field name, example: {"avatar": 2 << 20}.
+	FieldLimits		map[string]int64
+}
+
+func (p *MultipartParser) MediaType() string {
+	return echo.MIMEMultipartForm
+}
+
+func (p *MultipartParser) Parse(r io.Reader, v any) error {
+	return fmt.Errorf("multipart: boundary is required, use ParseRequest")
+}
+
+func (p *MultipartParser) ParseRequest(c echo.Context, v any) error {
+	reader, err := c.Request().MultipartReader()
+	if err != nil {
+		return err
+	}
+	values := url.Values{}
+	files := map[string][]*UploadedFile{}
+	uploads := []*UploadedFile{}
+	c.Response().After(func() {
+		for _, upload := range uploads {
+			upload.Remove()
+		}
+	})
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		name := part.FormName()
+		if name == "" {
+			part.Close()
+			continue
+		}
+		if part.FileName() == "" {
+			value, err := readField(part, name, p.limit(name, p.maxFieldSize()))
+			part.Close()
+			if err != nil {
+				return err
+			}
+			values.Add(name, value)
+			continue
+		}
+		upload, err := p.saveFile(part, name)
+		part.Close()
+		if upload != nil {
+			uploads = append(uploads, upload)
+		}
+		if err != nil {
+			return err
+		}
+		files[name] = append(files[name], upload)
+	}
+	return bindValues(v, values, files)
+}
+
+func (p *MultipartParser) maxFieldSize() int64 {
+	if p.MaxFieldSize == 0 {
+		return DefaultMaxFieldSize
+	}
+	return p.MaxFieldSize
+}
+
+func (p *MultipartParser) limit(name string, defaultLimit int64) int64 {
+	if limit, ok := p.FieldLimits[name]; ok {
+		return limit
+	}
+	return defaultLimit
+}
+
+// saveFile copies the part to temp file, the file is returned with error too so it's removed.
+func (p *MultipartParser) saveFile(part *multipart.Part, name string) (*UploadedFile, error) {
+	file, err := os.CreateTemp(p.TempDir, "gorim-upload-*")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	upload := &UploadedFile{
+		Filename: part.FileName(),
+		ContentType: part.Header.Get(echo.HeaderContentType),
+		Header: part.Header,
+		Path: file.Name(),
+	}
+	limit := p.limit(name, p.MaxFileSize)
+	var reader io.Reader = part
+	if limit > 0 {
+		reader = io.LimitReader(part, limit + 1)
+	}
+	size, err := io.Copy(file, reader)
+	upload.Size = size
+	if err != nil {
+		return upload, err
+	}
+	if limit > 0 && size > limit {
+		return upload, tooLarge(name, limit)
+	}
+	return upload, nil
+}
+
+func readField(part io.Reader, name string, limit int64) (string, error) {
+	value, err := io.ReadAll(io.LimitReader(part, limit + 1))
+	if err != nil {
+		return "", err
+	}
+	if int64(len(value)) > limit {
+		return "", tooLarge(name, limit)
+	}
+	return string(value), nil
+}
+
+func tooLarge(name string, limit int64) error {
+	return &errors.APIError{
+		Status: http.StatusRequestEntityTooLarge,
+		Message: fmt.Sprintf("%s exceeds the limit of %d bytes.", name, limit),
+		Code: errors.CodeRequestTooLarge,
+	}
+}
+
+// UploadedFile is a file of multipart request stored in a temp file.
+type UploadedFile struct {
+	Filename		string
+	ContentType		string
+	Size			int64
+	Header			textproto.MIMEHeader
+	// Path of the temp file.
+	Path			string
+	removeOnce		sync.Once
+}
+
+func (f *UploadedFile) Open() (*os.File, error) {
+	return os.Open(f.Path)
+}
+
+// Save moves the temp file to path, falls back to copy across file systems.
+func (f *UploadedFile) Save(path string) error {
+	if err := os.Rename(f.Path, path); err == nil {
+		return nil
+	}
+	src, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
+
+// Remove deletes the temp file, it's called after the response is written.
+func (f *UploadedFile) Remove() error {
+	var err error
+	f.removeOnce.Do(func() {
+		if removeErr := os.Remove(f.Path); removeErr != nil && !os.IsNotExist(removeErr) {
+			err = removeErr
+		}
+	})
+	return err
+}
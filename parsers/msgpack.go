@@ -0,0 +1,34 @@
+package parsers
+
+import (
+	"io"
+	"reflect"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+const MIMEApplicationMsgpack = "application/msgpack"
+
+// MsgpackParser decodes application/msgpack bodies, fields are named by json tag,
+// opt in with parsers.Register(&parsers.MsgpackParser{}).
+type MsgpackParser struct{}
+
+func (p *MsgpackParser) MediaType() string {
+	return MIMEApplicationMsgpack
+}
+
+func (p *MsgpackParser) Parse(r io.Reader, v any) error {
+	decoder := msgpack.NewDecoder(r)
+	decoder.SetCustomStructTag("json")
+	return decoder.Decode(target(v))
+}
+
+// target unwraps pointer to interface holding a pointer, example: &serializer of IModelSerializer,
+// so decoders fill the underlying struct instead of replacing the interface value.
+func target(v any) any {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Ptr && value.Elem().Kind() == reflect.Interface && !value.Elem().IsNil() && value.Elem().Elem().Kind() == reflect.Ptr {
+		value = value.Elem().Elem()
+	}
+	return value.Interface()
+}
@@ -0,0 +1,159 @@
+package parsers
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git/errors"
+)
+
+const (
+	// ParsersContextKey stores parsers of the viewset handling the request.
+	ParsersContextKey	= "parsers"
+	RawBodyContextKey	= "raw_body"
+)
+
+// Parser decodes request body of its media type into the bound value,
+// example of custom content type:
+//
+//	func (p *ProtobufParser) MediaType() string { return "application/x-protobuf" }
+//	func (p *ProtobufParser) Parse(r io.Reader, v any) error {
+//		body, err := io.ReadAll(r)
+//		...
+//		return proto.Unmarshal(body, v.(proto.Message))
+//	}
+type Parser interface {
+	MediaType() string
+	Parse(r io.Reader, v any) error
+}
+
+var (
+	mu			sync.RWMutex
+	registry	= map[string]Parser{
+		echo.MIMEApplicationForm:	&FormParser{},
+		echo.MIMEMultipartForm:		&MultipartParser{},
+	}
+)
+
+// aliases maps media types to the registered media type parsing them.
+var aliases = map[string]string{
+	"text/xml":				echo.MIMEApplicationXML,
+	"application/x-msgpack":	MIMEApplicationMsgpack,
+	"application/x-yaml":		MIMEApplicationYAML,
+	"text/yaml":				MIMEApplicationYAML,
+}
+
+// Register adds parser used by Binder for its media type, json bodies
+// are parsed by echo unless a parser is registered for it.
+func Register(parser Parser) {
+	mu.Lock()
+	defer mu.Unlock()
+	registry[parser.MediaType()] = parser
+}
+
+// Lookup returns parser of Content-Type, "application/vnd.api+xml" falls back to "application/xml".
+func Lookup(contentType string) Parser {
+	mu.RLock()
+	defer mu.RUnlock()
+	return find(registry, contentType)
+}
+
+// LookupFor returns parser of the request Content-Type, parsers of the viewset take precedence.
+func LookupFor(c echo.Context) Parser {
+	contentType := c.Request().Header.Get(echo.HeaderContentType)
+	if parsers, ok := c.Get(ParsersContextKey).([]Parser); ok && len(parsers) > 0 {
+		byMediaType := make(map[string]Parser, len(parsers))
+		for _, parser := range parsers {
+			byMediaType[parser.MediaType()] = parser
+		}
+		if parser := find(byMediaType, contentType); parser != nil {
+			return parser
+		}
+	}
+	return Lookup(contentType)
+}
+
+func find(parsers map[string]Parser, contentType string) Parser {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil
+	}
+	if parser, ok := parsers[mediaType]; ok {
+		return parser
+	}
+	if alias, ok := aliases[mediaType]; ok {
+		return parsers[alias]
+	}
+	if _, suffix, ok := strings.Cut(mediaType, "+"); ok {
+		return parsers["application/" + suffix]
+	}
+	return nil
+}
+
+// RawBody returns the request body, it can be called many times and the body
+// is still bound by c.Bind after it. The body is buffered in memory.
+func RawBody(c echo.Context) ([]byte, error) {
+	if body, ok := c.Get(RawBodyContextKey).([]byte); ok {
+		return body, nil
+	}
+	request := c.Request()
+	if request.Body == nil || request.Body == http.NoBody {
+		return []byte{}, nil
+	}
+	body, err := io.ReadAll(request.Body)
+	request.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	c.Set(RawBodyContextKey, body)
+	request.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// Binder binds body with registered parsers and falls back to echo.DefaultBinder,
+// gorim.New sets it as the binder of the server.
+type Binder struct {
+	echo.DefaultBinder
+}
+
+func (b *Binder) Bind(i interface{}, c echo.Context) error {
+	request := c.Request()
+	if body, ok := c.Get(RawBodyContextKey).([]byte); ok {
+		// body already read by RawBody.
+		request.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	parser := LookupFor(c)
+	if parser == nil {
+		return b.DefaultBinder.Bind(i, c)
+	}
+	if err := b.BindPathParams(c, i); err != nil {
+		return err
+	}
+	method := request.Method
+	if method == http.MethodGet || method == http.MethodDelete || method == http.MethodHead {
+		if err := b.BindQueryParams(c, i); err != nil {
+			return err
+		}
+	}
+	if request.ContentLength == 0 {
+		return nil
+	}
+	var err error
+	if requestParser, ok := parser.(RequestParser); ok {
+		err = requestParser.ParseRequest(c, i)
+	} else {
+		err = parser.Parse(request.Body, i)
+	}
+	switch err.(type) {
+	case nil:
+		return nil
+	case *echo.HTTPError, errors.APIException:
+		return err
+	}
+	return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+}
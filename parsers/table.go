@@ -0,0 +1,274 @@
+package parsers
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	MIMETextCSV	= "text/csv"
+	MIMEXLSX	= "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+// maxXLSXPart limits decompressed parts of XLSX files, so small zip bombs don't exhaust memory.
+const maxXLSXPart = 100 << 20
+
+// BindValues binds text values to fields by form tag, falling back to json tag, like
+// form requests, example: cells of imported rows keyed by their column.
+func BindValues(v any, values url.Values) error {
+	return bindValues(v, values, nil)
+}
+
+// ErrTooManyRows is returned by readers of tables having more rows than their limit.
+var ErrTooManyRows = stderrors.New("table: too many rows")
+
+const (
+	// xlsxMaxRows and xlsxMaxColumns are the limits of Excel worksheets.
+	xlsxMaxRows		= 1048576
+	xlsxMaxColumns	= 16384
+)
+
+// ReadTable reads rows of CSV or XLSX upload by its extension or content type, cells of
+// missing columns aren't padded. Reading stops with ErrTooManyRows after maxRows rows,
+// the header included, zero is unlimited.
+func ReadTable(upload *UploadedFile, maxRows int) ([][]string, error) {
+	file, err := upload.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	extension := strings.ToLower(path.Ext(upload.Filename))
+	switch {
+	case extension == ".xlsx" || strings.HasPrefix(upload.ContentType, MIMEXLSX):
+		info, err := file.Stat()
+		if err != nil {
+			return nil, err
+		}
+		return ReadXLSX(file, info.Size(), maxRows)
+	case extension == ".csv" || strings.HasPrefix(upload.ContentType, MIMETextCSV):
+		return ReadCSV(file, maxRows)
+	}
+	return nil, fmt.Errorf("table: unsupported file %s", upload.Filename)
+}
+
+// ReadCSV reads rows of CSV with ',' or ';' delimiter, whichever the header has more of,
+// as written by renderers.CSVRenderer. Byte order mark of Excel is skipped.
+func ReadCSV(r io.Reader, maxRows int) ([][]string, error) {
+	buffered := bufio.NewReader(r)
+	if bom, err := buffered.Peek(3); err == nil && bytes.Equal(bom, []byte("\ufeff")) {
+		buffered.Discard(3)
+	}
+	header, _ := buffered.Peek(buffered.Size())
+	if index := bytes.IndexByte(header, '\n'); index >= 0 {
+		header = header[:index]
+	}
+	reader := csv.NewReader(buffered)
+	reader.FieldsPerRecord = -1
+	if bytes.Count(header, []byte(";")) > bytes.Count(header, []byte(",")) {
+		reader.Comma = ';'
+	}
+	rows := [][]string{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if maxRows > 0 && len(rows) >= maxRows {
+			return nil, ErrTooManyRows
+		}
+		rows = append(rows, record)
+	}
+}
+
+// xlsxRow is a row of worksheet, decoded one by one so large sheets stop at the limit.
+type xlsxRow struct {
+	Number	int	`xml:"r,attr"`
+	Cells	[]struct {
+		Ref		string		`xml:"r,attr"`
+		Type	string		`xml:"t,attr"`
+		Value	string		`xml:"v"`
+		Inline	xlsxText	`xml:"is"`
+	}	`xml:"c"`
+}
+
+// ReadXLSX reads rows of the first worksheet of XLSX, cells are read as text: numbers
+// as written by Excel, booleans as 1 or 0 and dates as serial numbers. Rows are
+// numbered like the sheet, so rows skipped by the file are empty. Rows and columns
+// beyond the limits of Excel are invalid.
+func ReadXLSX(r io.ReaderAt, size int64, maxRows int) ([][]string, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("xlsx: %w", err)
+	}
+	parts := map[string]*zip.File{}
+	for _, file := range archive.File {
+		parts[strings.TrimPrefix(file.Name, "/")] = file
+	}
+	var sharedStrings struct {
+		Items	[]xlsxText	`xml:"si"`
+	}
+	if part, ok := parts["xl/sharedStrings.xml"]; ok {
+		if err := decodeXLSXPart(part, &sharedStrings); err != nil {
+			return nil, err
+		}
+	}
+	part, ok := parts[firstWorksheet(parts)]
+	if !ok {
+		return nil, fmt.Errorf("xlsx: worksheet not found")
+	}
+	if maxRows <= 0 || maxRows > xlsxMaxRows {
+		maxRows = xlsxMaxRows
+	}
+	reader, err := part.Open()
+	if err != nil {
+		return nil, fmt.Errorf("xlsx: %w", err)
+	}
+	defer reader.Close()
+	decoder := xml.NewDecoder(io.LimitReader(reader, maxXLSXPart))
+	rows := [][]string{}
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("xlsx: %s: %w", part.Name, err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "row" {
+			continue
+		}
+		var row xlsxRow
+		if err := decoder.DecodeElement(&row, &start); err != nil {
+			return nil, fmt.Errorf("xlsx: %s: %w", part.Name, err)
+		}
+		if row.Number > xlsxMaxRows {
+			return nil, fmt.Errorf("xlsx: invalid row %d", row.Number)
+		}
+		if max(row.Number, len(rows) + 1) > maxRows {
+			return nil, ErrTooManyRows
+		}
+		for row.Number > len(rows) + 1 {
+			rows = append(rows, []string{})
+		}
+		cells := []string{}
+		for _, cell := range row.Cells {
+			value := cell.Value
+			switch cell.Type {
+			case "s":
+				index, err := strconv.Atoi(value)
+				if err != nil || index < 0 || index >= len(sharedStrings.Items) {
+					return nil, fmt.Errorf("xlsx: invalid shared string %q of %s", value, cell.Ref)
+				}
+				value = sharedStrings.Items[index].String()
+			case "inlineStr":
+				value = cell.Inline.String()
+			}
+			column := columnIndex(cell.Ref)
+			if column < 0 {
+				column = len(cells)
+			}
+			if column >= xlsxMaxColumns {
+				return nil, fmt.Errorf("xlsx: invalid cell %s", cell.Ref)
+			}
+			for column > len(cells) {
+				cells = append(cells, "")
+			}
+			if column == len(cells) {
+				cells = append(cells, value)
+			} else {
+				cells[column] = value
+			}
+		}
+		rows = append(rows, cells)
+	}
+}
+
+// xlsxText is text of a shared or inline string, rich text is split in runs.
+type xlsxText struct {
+	Text	string	`xml:"t"`
+	Runs	[]struct {
+		Text	string	`xml:"t"`
+	}	`xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	text := t.Text
+	for _, run := range t.Runs {
+		text += run.Text
+	}
+	return text
+}
+
+// firstWorksheet returns part name of the first sheet of the workbook by its relationship.
+func firstWorksheet(parts map[string]*zip.File) string {
+	fallback := "xl/worksheets/sheet1.xml"
+	var workbook struct {
+		Sheets	[]struct {
+			ID	string	`xml:"id,attr"`
+		}	`xml:"sheets>sheet"`
+	}
+	var relationships struct {
+		Items	[]struct {
+			ID		string	`xml:"Id,attr"`
+			Target	string	`xml:"Target,attr"`
+		}	`xml:"Relationship"`
+	}
+	workbookPart, ok := parts["xl/workbook.xml"]
+	relationshipsPart, hasRelationships := parts["xl/_rels/workbook.xml.rels"]
+	if !ok || !hasRelationships || decodeXLSXPart(workbookPart, &workbook) != nil ||
+		decodeXLSXPart(relationshipsPart, &relationships) != nil || len(workbook.Sheets) == 0 {
+		return fallback
+	}
+	for _, relationship := range relationships.Items {
+		if relationship.ID != workbook.Sheets[0].ID {
+			continue
+		}
+		if strings.HasPrefix(relationship.Target, "/") {
+			return strings.TrimPrefix(relationship.Target, "/")
+		}
+		return path.Join("xl", relationship.Target)
+	}
+	return fallback
+}
+
+func decodeXLSXPart(part *zip.File, v any) error {
+	reader, err := part.Open()
+	if err != nil {
+		return fmt.Errorf("xlsx: %w", err)
+	}
+	defer reader.Close()
+	if err := xml.NewDecoder(io.LimitReader(reader, maxXLSXPart)).Decode(v); err != nil {
+		return fmt.Errorf("xlsx: %s: %w", part.Name, err)
+	}
+	return nil
+}
+
+// columnIndex returns zero based column of cell reference, example: "B3" => 1, -1 without
+// letters. References beyond xlsxMaxColumns return xlsxMaxColumns, so they can't overflow.
+func columnIndex(ref string) int {
+	column := 0
+	for _, char := range strings.ToUpper(ref) {
+		if char < 'A' || char > 'Z' {
+			break
+		}
+		column = column * 26 + int(char - 'A' + 1)
+		if column > xlsxMaxColumns {
+			return xlsxMaxColumns
+		}
+	}
+	return column - 1
+}
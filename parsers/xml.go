@@ -0,0 +1,163 @@
+package parsers
+
+import (
+	"encoding"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// XMLParser binds elements by xml tag, falling back to json tag, so serializers
+// declared for json accept xml too. Lists are <list-item> elements or repeated elements,
+// example: <root><email>a@b.c</email><tags><list-item>x</list-item></tags></root>.
+type XMLParser struct{}
+
+func (p *XMLParser) MediaType() string {
+	return echo.MIMEApplicationXML
+}
+
+func (p *XMLParser) Parse(r io.Reader, v any) error {
+	root, err := decodeXML(r)
+	if err != nil {
+		return err
+	}
+	return assignXML(reflect.ValueOf(v), root)
+}
+
+type xmlNode struct {
+	name		string
+	text		string
+	children	[]*xmlNode
+}
+
+// items returns <list-item> children, all children when there's none.
+func (n *xmlNode) items() []*xmlNode {
+	items := []*xmlNode{}
+	for _, child := range n.children {
+		if child.name == "list-item" {
+			items = append(items, child)
+		}
+	}
+	if len(items) == 0 {
+		return n.children
+	}
+	return items
+}
+
+// generic converts node into string, []any or map[string]any.
+func (n *xmlNode) generic() any {
+	if len(n.children) == 0 {
+		return n.text
+	}
+	if n.children[0].name == "list-item" {
+		values := []any{}
+		for _, item := range n.items() {
+			values = append(values, item.generic())
+		}
+		return values
+	}
+	values := map[string]any{}
+	for _, child := range n.children {
+		values[child.name] = child.generic()
+	}
+	return values
+}
+
+func decodeXML(r io.Reader) (*xmlNode, error) {
+	decoder := xml.NewDecoder(r)
+	stack := []*xmlNode{}
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil, fmt.Errorf("xml: missing root element")
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{name: token.Name.Local}
+			if len(stack) > 0 {
+				parent := stack[len(stack) - 1]
+				parent.children = append(parent.children, node)
+			}
+			stack = append(stack, node)
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack) - 1].text += string(token)
+			}
+		case xml.EndElement:
+			node := stack[len(stack) - 1]
+			node.text = strings.TrimSpace(node.text)
+			stack = stack[:len(stack) - 1]
+			if len(stack) == 0 {
+				return node, nil
+			}
+		}
+	}
+}
+
+func assignXML(v reflect.Value, node *xmlNode) error {
+	if v.Kind() == reflect.Interface && !v.IsNil() && v.Elem().Kind() == reflect.Ptr {
+		// value bound through interface, example: &serializer of IModelSerializer.
+		return assignXML(v.Elem(), node)
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return assignXML(v.Elem(), node)
+	}
+	if v.CanAddr() {
+		if unmarshaler, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			if node.text == "" {
+				return nil
+			}
+			return unmarshaler.UnmarshalText([]byte(node.text))
+		}
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		fields := fieldsByTag(v.Type(), "xml", "json")
+		for _, child := range node.children {
+			index, ok := fields[child.name]
+			if !ok {
+				continue
+			}
+			if err := assignXML(v.FieldByIndex(index), child); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice:
+		items := node.items()
+		slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := assignXML(slice.Index(i), item); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("xml: %s: unsupported map key", node.name)
+		}
+		values := reflect.MakeMap(v.Type())
+		for _, child := range node.children {
+			value := reflect.New(v.Type().Elem()).Elem()
+			if err := assignXML(value, child); err != nil {
+				return err
+			}
+			values.SetMapIndex(reflect.ValueOf(child.name).Convert(v.Type().Key()), value)
+		}
+		v.Set(values)
+	case reflect.Interface:
+		v.Set(reflect.ValueOf(node.generic()))
+	default:
+		return setScalar(v, node.name, node.text)
+	}
+	return nil
+}
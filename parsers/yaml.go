@@ -0,0 +1,71 @@
+package parsers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+const MIMEApplicationYAML = "application/yaml"
+
+// YAMLParser decodes application/yaml bodies through json, so fields are named by json tag
+// and bound values are validated by serializers the same way as json,
+// opt in with parsers.Register(&parsers.YAMLParser{}).
+type YAMLParser struct{}
+
+func (p *YAMLParser) MediaType() string {
+	return MIMEApplicationYAML
+}
+
+func (p *YAMLParser) Parse(r io.Reader, v any) error {
+	var value any
+	if err := yaml.NewDecoder(r).Decode(&value); err != nil && err != io.EOF {
+		return err
+	}
+	value, err := jsonCompatible(value)
+	if err != nil {
+		return err
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, target(v))
+}
+
+// jsonCompatible converts map keys decoded from yaml to string.
+func jsonCompatible(value any) (any, error) {
+	switch value := value.(type) {
+	case map[string]any:
+		for key, item := range value {
+			converted, err := jsonCompatible(item)
+			if err != nil {
+				return nil, err
+			}
+			value[key] = converted
+		}
+		return value, nil
+	case map[any]any:
+		converted := make(map[string]any, len(value))
+		for key, item := range value {
+			item, err := jsonCompatible(item)
+			if err != nil {
+				return nil, err
+			}
+			converted[fmt.Sprint(key)] = item
+		}
+		return converted, nil
+	case []any:
+		for i, item := range value {
+			converted, err := jsonCompatible(item)
+			if err != nil {
+				return nil, err
+			}
+			value[i] = converted
+		}
+		return value, nil
+	}
+	return value, nil
+}
@@ -0,0 +1,84 @@
+package permissions
+
+import (
+	"net/http"
+
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/errors"
+	"github.com/rimba47prayoga/gorim.git/interfaces"
+)
+
+const (
+	DefaultDeniedMessage		= "You are not authorized to access this resource"
+	DefaultDeniedCode			= errors.CodePermissionDenied
+	NotAuthenticatedMessage		= "Authentication credentials were not provided."
+	NotAuthenticatedCode		= errors.CodeNotAuthenticated
+)
+
+// BasePermission can be embedded by permissions to supply denial message and code,
+// example: IsSubscribed{BasePermission: permissions.BasePermission{Code: "subscription_required"}}
+type BasePermission struct {
+	Message		string
+	Code		string
+}
+
+func (p *BasePermission) GetMessage() string {
+	return p.Message
+}
+
+func (p *BasePermission) GetCode() string {
+	return p.Code
+}
+
+// GetDenial returns message and code of the denying permission, falls back to defaults.
+func GetDenial(ctx gorim.Context, permission interfaces.IPermission) (string, string) {
+	message, code := "", ""
+	if permissionMessage, ok := permission.(interfaces.IPermissionMessage); ok {
+		message = permissionMessage.GetMessage()
+		code = permissionMessage.GetCode()
+	}
+	if !ctx.IsAuthenticated() {
+		if message == "" {
+			message = NotAuthenticatedMessage
+		}
+		if code == "" {
+			code = NotAuthenticatedCode
+		}
+	}
+	if message == "" {
+		message = DefaultDeniedMessage
+	}
+	if code == "" {
+		code = DefaultDeniedCode
+	}
+	return message, code
+}
+
+// Deny fires DenialEvent then renders the response with DeniedHandler.
+func Deny(ctx gorim.Context, permission interfaces.IPermission) error {
+	message, code := GetDenial(ctx, permission)
+	status := http.StatusForbidden
+	if !ctx.IsAuthenticated() {
+		status = http.StatusUnauthorized
+	}
+	FireDenied(DenialEvent{
+		Request: ctx.Request(),
+		User: ctx.User(),
+		Permission: permission,
+		Action: ctx.GetAction(),
+		Status: status,
+		Code: code,
+		Reason: message,
+	})
+	return DeniedHandler(ctx, permission)
+}
+
+// DeniedHandler renders the response when a permission denies the request,
+// override it to customize the response body.
+var DeniedHandler = func(ctx gorim.Context, permission interfaces.IPermission) error {
+	message, code := GetDenial(ctx, permission)
+	if !ctx.IsAuthenticated() {
+		return errors.Handle(&errors.NotAuthenticatedError{Message: message, Code: code}, ctx)
+	}
+	return errors.Handle(&errors.PermissionDeniedError{Message: message, Code: code}, ctx)
+}
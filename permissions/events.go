@@ -0,0 +1,68 @@
+package permissions
+
+import (
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/rimba47prayoga/gorim.git/interfaces"
+)
+
+// DenialEvent describes an authentication (401) or permission (403) failure.
+type DenialEvent struct {
+	Request			*http.Request
+	User			interface{}
+	// Permission is nil for authentication failures.
+	Permission		interfaces.IPermission
+	PermissionName	string
+	Action			string
+	Status			int
+	Code			string
+	Reason			string
+	Time			time.Time
+}
+
+var (
+	denialHandlersMu	sync.RWMutex
+	denialHandlers		[]func(DenialEvent)
+)
+
+// OnDenied registers handler called on every 401/403 decision,
+// example: ship the event to SIEM.
+func OnDenied(handler func(DenialEvent)) {
+	denialHandlersMu.Lock()
+	defer denialHandlersMu.Unlock()
+	denialHandlers = append(denialHandlers, handler)
+}
+
+// FireDenied calls registered handlers, a panicking handler doesn't break the response.
+func FireDenied(event DenialEvent) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	if event.Permission != nil && event.PermissionName == "" {
+		event.PermissionName = PermissionName(event.Permission)
+	}
+	denialHandlersMu.RLock()
+	handlers := denialHandlers
+	denialHandlersMu.RUnlock()
+	for _, handler := range handlers {
+		func() {
+			defer func() { recover() }()
+			handler(event)
+		}()
+	}
+}
+
+// PermissionName returns the struct name of permission, example: "IsAuthenticated".
+func PermissionName(permission interfaces.IPermission) string {
+	typ := reflect.TypeOf(permission)
+	if typ == nil {
+		return ""
+	}
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ.Name()
+}
@@ -0,0 +1,29 @@
+package permissions
+
+import (
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/utils"
+)
+
+// FeatureFlag allows requests only when feature flag Name is on for the user,
+// example: &permissions.FeatureFlag{Name: "new_checkout", Actions: []string{"Create"}}
+type FeatureFlag struct {
+	Name		string
+	// Actions gated by the flag, empty gates every action of the viewset.
+	Actions		[]string
+}
+
+func (p *FeatureFlag) HasPermission(ctx gorim.Context) bool {
+	if len(p.Actions) > 0 && !utils.Contains(p.Actions, ctx.GetAction()) {
+		return true
+	}
+	return ctx.FeatureEnabled(p.Name)
+}
+
+func (p *FeatureFlag) GetMessage() string {
+	return "This feature is not enabled."
+}
+
+func (p *FeatureFlag) GetCode() string {
+	return "feature_disabled"
+}
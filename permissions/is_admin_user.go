@@ -0,0 +1,18 @@
+package permissions
+
+import (
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/interfaces"
+)
+
+// IsAdminUser allows access only to users implementing interfaces.IAdminUser
+// that are flagged as admin.
+type IsAdminUser struct {}
+
+func (p *IsAdminUser) HasPermission(ctx gorim.Context) bool {
+	user, ok := ctx.User().(interfaces.IAdminUser)
+	if !ok {
+		return false
+	}
+	return user.IsAdminUser()
+}
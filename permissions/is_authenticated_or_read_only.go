@@ -0,0 +1,26 @@
+package permissions
+
+import (
+	"net/http"
+
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/utils"
+)
+
+var SafeMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodOptions,
+}
+
+// IsAuthenticatedOrReadOnly allows safe methods for anyone,
+// unsafe methods only for authenticated users.
+type IsAuthenticatedOrReadOnly struct {}
+
+func (p *IsAuthenticatedOrReadOnly) HasPermission(ctx gorim.Context) bool {
+	if utils.Contains(SafeMethods, ctx.Request().Method) {
+		return true
+	}
+	isAuthenticated := IsAuthenticated{}
+	return isAuthenticated.HasPermission(ctx)
+}
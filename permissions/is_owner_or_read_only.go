@@ -0,0 +1,56 @@
+package permissions
+
+import (
+	"fmt"
+
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/utils"
+)
+
+// IsOwnerOrReadOnly allows safe methods for anyone,
+// unsafe methods only when OwnerField of the object equals the authenticated user ID.
+// It's an object permission, checked by GetObject on Retrieve/Update/Destroy.
+type IsOwnerOrReadOnly struct {
+	// OwnerField is column or struct field name of the owner, default "user_id".
+	OwnerField		string
+}
+
+func (p *IsOwnerOrReadOnly) HasPermission(ctx gorim.Context) bool {
+	return true
+}
+
+func (p *IsOwnerOrReadOnly) HasObjectPermission(ctx gorim.Context, obj interface{}) bool {
+	if utils.Contains(SafeMethods, ctx.Request().Method) {
+		return true
+	}
+	isOwner := IsOwner{OwnerField: p.OwnerField}
+	return isOwner.HasObjectPermission(ctx, obj)
+}
+
+// IsOwner allows access only when OwnerField of the object equals the authenticated user ID.
+type IsOwner struct {
+	OwnerField		string
+}
+
+func (p *IsOwner) HasPermission(ctx gorim.Context) bool {
+	return ctx.IsAuthenticated()
+}
+
+func (p *IsOwner) HasObjectPermission(ctx gorim.Context, obj interface{}) bool {
+	if !ctx.IsAuthenticated() {
+		return false
+	}
+	userID, ok := utils.GetUserID(ctx.User())
+	if !ok {
+		return false
+	}
+	ownerField := p.OwnerField
+	if ownerField == "" {
+		ownerField = "user_id"
+	}
+	ownerID, err := utils.GetFieldValue(obj, ownerField)
+	if err != nil || ownerID == nil {
+		return false
+	}
+	return fmt.Sprint(ownerID) == fmt.Sprint(userID)
+}
@@ -0,0 +1,91 @@
+package permissions
+
+import (
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/interfaces"
+)
+
+// AndPermission grants access when every wrapped permission does.
+type AndPermission struct {
+	Permissions		[]interfaces.IPermission
+}
+
+func (p *AndPermission) HasPermission(ctx gorim.Context) bool {
+	for _, permission := range p.Permissions {
+		if !permission.HasPermission(ctx) {
+			return false
+		}
+	}
+	return true
+}
+
+// HasObjectPermission grants access to obj when every wrapped permission does.
+func (p *AndPermission) HasObjectPermission(ctx gorim.Context, obj interface{}) bool {
+	for _, permission := range p.Permissions {
+		if !hasObjectPermission(permission, ctx, obj) {
+			return false
+		}
+	}
+	return true
+}
+
+// OrPermission grants access when at least one wrapped permission does.
+type OrPermission struct {
+	Permissions		[]interfaces.IPermission
+}
+
+func (p *OrPermission) HasPermission(ctx gorim.Context) bool {
+	for _, permission := range p.Permissions {
+		if permission.HasPermission(ctx) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasObjectPermission grants access to obj when at least one wrapped permission does,
+// example: Or(&IsAdminUser{}, &IsOwner{}) grants admins and the owner of obj.
+func (p *OrPermission) HasObjectPermission(ctx gorim.Context, obj interface{}) bool {
+	for _, permission := range p.Permissions {
+		if hasObjectPermission(permission, ctx, obj) {
+			return true
+		}
+	}
+	return false
+}
+
+// NotPermission inverts the wrapped permission.
+type NotPermission struct {
+	Permission		interfaces.IPermission
+}
+
+func (p *NotPermission) HasPermission(ctx gorim.Context) bool {
+	return !p.Permission.HasPermission(ctx)
+}
+
+func (p *NotPermission) HasObjectPermission(ctx gorim.Context, obj interface{}) bool {
+	return !hasObjectPermission(p.Permission, ctx, obj)
+}
+
+// hasObjectPermission checks obj against permission, permissions without object check
+// grant by HasPermission.
+func hasObjectPermission(permission interfaces.IPermission, ctx gorim.Context, obj interface{}) bool {
+	if objectPermission, ok := permission.(interfaces.IObjectPermission); ok {
+		return objectPermission.HasObjectPermission(ctx, obj)
+	}
+	return permission.HasPermission(ctx)
+}
+
+// And combines permissions, example: And(&IsAuthenticated{}, Not(&IsBanned{}))
+func And(permissions ...interfaces.IPermission) interfaces.IPermission {
+	return &AndPermission{Permissions: permissions}
+}
+
+// Or combines permissions, example: Or(&IsAdminUser{}, &IsOwner{})
+func Or(permissions ...interfaces.IPermission) interfaces.IPermission {
+	return &OrPermission{Permissions: permissions}
+}
+
+func Not(permission interfaces.IPermission) interfaces.IPermission {
+	return &NotPermission{Permission: permission}
+}
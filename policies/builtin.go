@@ -0,0 +1,87 @@
+package policies
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/conf"
+	"github.com/rimba47prayoga/gorim.git/errors"
+	"github.com/rimba47prayoga/gorim.git/tenancy"
+	"github.com/rimba47prayoga/gorim.git/utils"
+	"gorm.io/gorm"
+)
+
+// OwnedByUser limits rows to the ones whose Column is ID of the request user and sets it on writes,
+// anonymous requests see nothing. Bypass returning true lets the request see every row,
+// example: &policies.OwnedByUser{Column: "author_id", Bypass: isAdmin}
+type OwnedByUser struct {
+	// Column referencing the user, default "user_id".
+	Column		string
+	Bypass		func(c echo.Context) bool
+}
+
+func (p *OwnedByUser) GetColumn() string {
+	if p.Column == "" {
+		return "user_id"
+	}
+	return p.Column
+}
+
+func (p *OwnedByUser) Filter(c echo.Context, queryset *gorm.DB, model interface{}) *gorm.DB {
+	if p.Bypass != nil && p.Bypass(c) {
+		return queryset
+	}
+	userID, ok := utils.GetUserID(c.Get(gorim.UserContextKey))
+	if !ok {
+		return queryset.Where("1 = 0")
+	}
+	return matchColumn(queryset, model, p.GetColumn(), fmt.Sprint(userID))
+}
+
+func (p *OwnedByUser) Assign(c echo.Context, instance interface{}) error {
+	userID, ok := utils.GetUserID(c.Get(gorim.UserContextKey))
+	if !ok || (p.Bypass != nil && p.Bypass(c)) {
+		return nil
+	}
+	return utils.SetFieldFromString(instance, p.GetColumn(), fmt.Sprint(userID))
+}
+
+// TenantScoped limits rows to the tenant resolved by tenancy middleware and sets it on writes,
+// requests without tenant see nothing. Unlike the automatic scoping by conf.TENANT_COLUMN,
+// Column may differ per viewset and the tenant is required.
+type TenantScoped struct {
+	// Column of the tenant, default conf.TENANT_COLUMN or "tenant_id" when it's empty.
+	Column		string
+}
+
+func (p *TenantScoped) GetColumn() string {
+	if p.Column != "" {
+		return p.Column
+	}
+	if conf.TENANT_COLUMN != "" {
+		return conf.TENANT_COLUMN
+	}
+	return "tenant_id"
+}
+
+func (p *TenantScoped) Filter(c echo.Context, queryset *gorm.DB, model interface{}) *gorm.DB {
+	tenant := tenancy.GetTenant(c)
+	if tenant == "" {
+		return queryset.Where("1 = 0")
+	}
+	return matchColumn(queryset, model, p.GetColumn(), tenant)
+}
+
+func (p *TenantScoped) Assign(c echo.Context, instance interface{}) error {
+	tenant := tenancy.GetTenant(c)
+	if tenant == "" {
+		return &errors.APIError{
+			Status: http.StatusBadRequest,
+			Message: "Tenant is required.",
+			Code: "tenant_required",
+		}
+	}
+	return utils.SetFieldFromString(instance, p.GetColumn(), tenant)
+}
@@ -0,0 +1,78 @@
+package policies
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git/utils"
+	"gorm.io/gorm"
+)
+
+// PoliciesContextKey stores policies of the viewset handling the request, read by serializers.
+const PoliciesContextKey = "policies"
+
+// Policy limits rows a request may read and fills fields of rows it writes,
+// attached to viewsets with the Policies param so every action is scoped the same way.
+type Policy interface {
+	// Filter appends conditions to queryset of model.
+	Filter(c echo.Context, queryset *gorm.DB, model interface{}) *gorm.DB
+	// Assign sets fields of instance before it's created or updated.
+	Assign(c echo.Context, instance interface{}) error
+}
+
+// FromContext returns policies stored by the viewset.
+func FromContext(c echo.Context) []Policy {
+	if c == nil {
+		return nil
+	}
+	policies, _ := c.Get(PoliciesContextKey).([]Policy)
+	return policies
+}
+
+// Filter applies policies of the request to queryset.
+func Filter(c echo.Context, queryset *gorm.DB, model interface{}) *gorm.DB {
+	for _, policy := range FromContext(c) {
+		queryset = policy.Filter(c, queryset, model)
+	}
+	return queryset
+}
+
+// Assign applies policies of the request to instance.
+func Assign(c echo.Context, instance interface{}) error {
+	for _, policy := range FromContext(c) {
+		if err := policy.Assign(c, instance); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PolicyFunc builds Policy from functions, nil AssignFunc assigns nothing,
+// example: policies.PolicyFunc{FilterFunc: func(c echo.Context, db *gorm.DB, model interface{}) *gorm.DB {
+// return db.Where("published = ?", true) }}
+type PolicyFunc struct {
+	FilterFunc	func(c echo.Context, queryset *gorm.DB, model interface{}) *gorm.DB
+	AssignFunc	func(c echo.Context, instance interface{}) error
+}
+
+func (p PolicyFunc) Filter(c echo.Context, queryset *gorm.DB, model interface{}) *gorm.DB {
+	if p.FilterFunc == nil {
+		return queryset
+	}
+	return p.FilterFunc(c, queryset, model)
+}
+
+func (p PolicyFunc) Assign(c echo.Context, instance interface{}) error {
+	if p.AssignFunc == nil {
+		return nil
+	}
+	return p.AssignFunc(c, instance)
+}
+
+// matchColumn compares column with value parsed into the type of the model field,
+// value which isn't valid for the column matches nothing.
+func matchColumn(queryset *gorm.DB, model interface{}, column string, value string) *gorm.DB {
+	parsed, err := utils.ParseFieldString(model, column, value)
+	if err != nil {
+		return queryset.Where("1 = 0")
+	}
+	return queryset.Where(column + " = ?", parsed)
+}
@@ -0,0 +1,46 @@
+package rbac
+
+import (
+	"github.com/rimba47prayoga/gorim.git/models"
+)
+
+type Permission struct {
+	models.BaseModel
+	Name		string			`gorm:"type:varchar(255)" json:"name"`
+	Codename	string			`gorm:"type:varchar(255);uniqueIndex;not null" json:"codename"`
+}
+
+func (m Permission) TableName() string {
+	return "gorim_permissions"
+}
+
+type Role struct {
+	models.BaseModel
+	Name		string			`gorm:"type:varchar(255);uniqueIndex;not null" json:"name"`
+	Permissions	[]Permission	`gorm:"many2many:gorim_role_permissions" json:"permissions"`
+}
+
+func (m Role) TableName() string {
+	return "gorim_roles"
+}
+
+// UserRole assigns a role to a user.
+type UserRole struct {
+	models.BaseModel
+	UserID		uint			`gorm:"index;not null" json:"user_id"`
+	RoleID		uint			`gorm:"index;not null" json:"role_id"`
+	Role		Role			`json:"role"`
+}
+
+func (m UserRole) TableName() string {
+	return "gorim_user_roles"
+}
+
+// Models returns rbac models, append them to your migration models.
+func Models() []interface{} {
+	return []interface{}{
+		&Permission{},
+		&Role{},
+		&UserRole{},
+	}
+}
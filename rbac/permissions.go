@@ -0,0 +1,90 @@
+package rbac
+
+import (
+	"net/http"
+
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/interfaces"
+)
+
+// DefaultActionPerms maps default viewset actions to permission action.
+var DefaultActionPerms = map[string]string{
+	"List":				"view",
+	"Retrieve":			"view",
+	"Create":			"add",
+	"Update":			"change",
+	"PartialUpdate":	"change",
+	"Delete":			"delete",
+	"Destroy":			"delete",
+}
+
+// DefaultMethodPerms is used for custom actions not listed in DefaultActionPerms.
+var DefaultMethodPerms = map[string]string{
+	http.MethodGet:		"view",
+	http.MethodHead:	"view",
+	http.MethodPost:	"add",
+	http.MethodPut:		"change",
+	http.MethodPatch:	"change",
+	http.MethodDelete:	"delete",
+}
+
+// ModelPermissions requires the user to have app.<action>_<model> permission
+// for the current viewset action, example: user.add_user on Create.
+type ModelPermissions struct {
+	App			string
+	Model		string
+	// Actions maps custom actions to required codenames,
+	// example: {"UpdateProfile": {"user.change_profile"}}
+	Actions		map[string][]string
+}
+
+func NewModelPermissions[T any](app string) *ModelPermissions {
+	return &ModelPermissions{
+		App: app,
+		Model: ModelName[T](),
+	}
+}
+
+// GetRequiredPermissions returns codenames required by the action.
+func (p *ModelPermissions) GetRequiredPermissions(action string, method string) []string {
+	if codenames, ok := p.Actions[action]; ok {
+		return codenames
+	}
+	if perm, ok := DefaultActionPerms[action]; ok {
+		return []string{Codename(p.App, perm, p.Model)}
+	}
+	if perm, ok := DefaultMethodPerms[method]; ok {
+		return []string{Codename(p.App, perm, p.Model)}
+	}
+	return []string{}
+}
+
+func (p *ModelPermissions) HasPermission(ctx gorim.Context) bool {
+	if !ctx.IsAuthenticated() {
+		return false
+	}
+	if admin, ok := ctx.User().(interfaces.IAdminUser); ok && admin.IsAdminUser() {
+		return true
+	}
+	codenames := p.GetRequiredPermissions(ctx.GetAction(), ctx.Request().Method)
+	if len(codenames) == 0 {
+		return true
+	}
+	return HasPerms(ctx.User(), codenames...)
+}
+
+// HasPermsPermission checks the user has specific codenames regardless the action.
+type HasPermsPermission struct {
+	Codenames	[]string
+}
+
+func (p *HasPermsPermission) HasPermission(ctx gorim.Context) bool {
+	if !ctx.IsAuthenticated() {
+		return false
+	}
+	return HasPerms(ctx.User(), p.Codenames...)
+}
+
+func RequirePerms(codenames ...string) interfaces.IPermission {
+	return &HasPermsPermission{Codenames: codenames}
+}
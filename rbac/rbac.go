@@ -0,0 +1,108 @@
+package rbac
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/rimba47prayoga/gorim.git/conf"
+	"github.com/rimba47prayoga/gorim.git/utils"
+	"gorm.io/gorm"
+)
+
+// Codename builds permission codename, example: Codename("user", "add", "user") => "user.add_user"
+func Codename(app string, action string, model string) string {
+	return fmt.Sprintf("%s.%s_%s", app, action, model)
+}
+
+// GetUserPermissions returns all permission codenames granted to the user through roles.
+func GetUserPermissions(db *gorm.DB, userID uint) []string {
+	var codenames []string
+	db.Model(&Permission{}).
+		Distinct("gorim_permissions.codename").
+		Joins("JOIN gorim_role_permissions ON gorim_role_permissions.permission_id = gorim_permissions.id").
+		Joins("JOIN gorim_user_roles ON gorim_user_roles.role_id = gorim_role_permissions.role_id").
+		Where("gorim_user_roles.user_id = ? AND gorim_user_roles.deleted_at IS NULL", userID).
+		Pluck("gorim_permissions.codename", &codenames)
+	return codenames
+}
+
+// HasPerms checks that the user is granted every codename.
+func HasPerms(user interface{}, codenames ...string) bool {
+	userID, ok := utils.GetUserID(user)
+	if !ok {
+		return false
+	}
+	granted := GetUserPermissions(conf.DB, userID)
+	for _, codename := range codenames {
+		if !utils.Contains(granted, codename) {
+			return false
+		}
+	}
+	return true
+}
+
+// GetUserRoles returns names of roles assigned to the user.
+func GetUserRoles(db *gorm.DB, userID uint) []string {
+	var names []string
+	db.Model(&Role{}).
+		Joins("JOIN gorim_user_roles ON gorim_user_roles.role_id = gorim_roles.id").
+		Where("gorim_user_roles.user_id = ? AND gorim_user_roles.deleted_at IS NULL", userID).
+		Pluck("gorim_roles.name", &names)
+	return names
+}
+
+// HasRoles checks that the user is assigned every role.
+func HasRoles(user interface{}, names ...string) bool {
+	userID, ok := utils.GetUserID(user)
+	if !ok {
+		return false
+	}
+	assigned := GetUserRoles(conf.DB, userID)
+	for _, name := range names {
+		if !utils.Contains(assigned, name) {
+			return false
+		}
+	}
+	return true
+}
+
+// AssignRole assigns role to the user, does nothing if already assigned.
+func AssignRole(db *gorm.DB, userID uint, role *Role) error {
+	userRole := UserRole{
+		UserID: userID,
+		RoleID: role.ID,
+	}
+	return db.Where(&userRole).FirstOrCreate(&userRole).Error
+}
+
+// RemoveRole removes role assignment from the user.
+func RemoveRole(db *gorm.DB, userID uint, role *Role) error {
+	return db.Where("user_id = ? AND role_id = ?", userID, role.ID).Delete(&UserRole{}).Error
+}
+
+// GetOrCreatePermission creates permission by codename if not exists.
+func GetOrCreatePermission(db *gorm.DB, codename string, name string) (*Permission, error) {
+	permission := Permission{Codename: codename}
+	err := db.Where(&permission).Attrs(Permission{Name: name}).FirstOrCreate(&permission).Error
+	return &permission, err
+}
+
+// ModelName returns lowercase struct name of model T, used for codenames.
+func ModelName[T any]() string {
+	var model T
+	return toSnakeCase(reflect.TypeOf(model).Name())
+}
+
+func toSnakeCase(name string) string {
+	var result []rune
+	for i, r := range name {
+		if r >= 'A' && r <= 'Z' {
+			if i > 0 {
+				result = append(result, '_')
+			}
+			r = r + ('a' - 'A')
+		}
+		result = append(result, r)
+	}
+	return string(result)
+}
@@ -0,0 +1,216 @@
+package renderers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rimba47prayoga/gorim.git/i18n"
+)
+
+const MIMETextCSV = "text/csv"
+
+// LocalizedRenderer is implemented by renderers formatting values for people, Write
+// renders with the renderer returned for the language of the request.
+type LocalizedRenderer interface {
+	Localized(language string) Renderer
+}
+
+// CSVRenderer renders lists, or results of paginated responses, as rows with a header
+// of json field names, nested values are written as json. Numbers and RFC 3339 times
+// are formatted for the language of the request, so spreadsheets read them, opt in with
+// renderers.Register(&renderers.CSVRenderer{}).
+type CSVRenderer struct {
+	// Delimiter default ',', or ';' for languages with decimal comma.
+	Delimiter	rune
+	// BOM starts the file with UTF-8 byte order mark for Excel.
+	BOM			bool
+	language	string
+}
+
+func (r *CSVRenderer) MediaType() string {
+	return MIMETextCSV
+}
+
+func (r *CSVRenderer) Format() string {
+	return "csv"
+}
+
+func (r *CSVRenderer) Localized(language string) Renderer {
+	localized := *r
+	localized.language = language
+	return &localized
+}
+
+func (r *CSVRenderer) Render(w io.Writer, data any) error {
+	// encode through json so json tags, omitempty and custom marshalers apply.
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	rows, err := csvRows(raw)
+	if err != nil {
+		return err
+	}
+	format := i18n.GetFormat(r.language)
+	if r.language == "" {
+		format = i18n.Format{Decimal: "."}
+	}
+	writer := csv.NewWriter(w)
+	writer.Comma = r.Delimiter
+	if writer.Comma == 0 {
+		writer.Comma = ','
+		if format.Decimal == "," {
+			writer.Comma = ';'
+		}
+	}
+	if r.BOM {
+		if _, err := io.WriteString(w, "\ufeff"); err != nil {
+			return err
+		}
+	}
+	columns := []string{}
+	seen := map[string]bool{}
+	for _, row := range rows {
+		for _, key := range row.keys {
+			if !seen[key] {
+				seen[key] = true
+				columns = append(columns, key)
+			}
+		}
+	}
+	if err := writer.Write(columns); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		record := make([]string, len(columns))
+		for i, column := range columns {
+			record[i] = r.cell(format, row.values[column])
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func (r *CSVRenderer) cell(format i18n.Format, value any) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		if r.language != "" && len(value) >= 20 {
+			if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+				return t.Format(format.DateTime)
+			}
+		}
+		return value
+	case json.Number:
+		// numbers aren't grouped, spreadsheets read grouped numbers as text.
+		return strings.Replace(value.String(), ".", format.Decimal, 1)
+	case bool:
+		if value {
+			return "true"
+		}
+		return "false"
+	}
+	encoded, _ := json.Marshal(value)
+	return string(encoded)
+}
+
+type csvRow struct {
+	keys	[]string
+	values	map[string]any
+}
+
+// csvRows decodes objects of json list, or results of object having it, keeping the
+// order of their keys.
+func csvRows(raw []byte) ([]csvRow, error) {
+	var value any
+	if err := decodeNumbers(raw, &value); err != nil {
+		return nil, err
+	}
+	if object, ok := value.(map[string]any); ok {
+		if _, paginated := object["results"].([]any); !paginated {
+			row, err := csvObject(raw)
+			if err != nil {
+				return nil, err
+			}
+			return []csvRow{row}, nil
+		}
+		var wrapper struct {
+			Results	json.RawMessage	`json:"results"`
+		}
+		if err := json.Unmarshal(raw, &wrapper); err != nil {
+			return nil, err
+		}
+		return csvRows(wrapper.Results)
+	}
+	var items []json.RawMessage
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return nil, err
+	}
+	rows := make([]csvRow, 0, len(items))
+	for _, item := range items {
+		row, err := csvObject(item)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// csvObject decodes object keeping the order of keys, other values are a "value" column.
+func csvObject(raw []byte) (csvRow, error) {
+	var value any
+	if err := decodeNumbers(raw, &value); err != nil {
+		return csvRow{}, err
+	}
+	object, ok := value.(map[string]any)
+	if !ok {
+		return csvRow{keys: []string{"value"}, values: map[string]any{"value": value}}, nil
+	}
+	keys, err := objectKeys(raw)
+	if err != nil {
+		// keys are sorted when their order can't be read.
+		keys = make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+	}
+	return csvRow{keys: keys, values: object}, nil
+}
+
+// objectKeys returns keys of json object in order.
+func objectKeys(raw []byte) ([]string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	keys := []string{}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, token.(string))
+		var skip json.RawMessage
+		if err := decoder.Decode(&skip); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+func decodeNumbers(raw []byte, value *any) error {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	return decoder.Decode(value)
+}
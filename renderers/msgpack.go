@@ -0,0 +1,27 @@
+package renderers
+
+import (
+	"io"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+const MIMEApplicationMsgpack = "application/msgpack"
+
+// MsgpackRenderer renders application/msgpack, fields are named by json tag,
+// opt in with renderers.Register(&renderers.MsgpackRenderer{}).
+type MsgpackRenderer struct{}
+
+func (r *MsgpackRenderer) MediaType() string {
+	return MIMEApplicationMsgpack
+}
+
+func (r *MsgpackRenderer) Format() string {
+	return "msgpack"
+}
+
+func (r *MsgpackRenderer) Render(w io.Writer, data any) error {
+	encoder := msgpack.NewEncoder(w)
+	encoder.SetCustomStructTag("json")
+	return encoder.Encode(data)
+}
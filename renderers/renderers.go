@@ -0,0 +1,212 @@
+package renderers
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git/errors"
+	"github.com/rimba47prayoga/gorim.git/i18n"
+)
+
+const (
+	// RendererContextKey stores the negotiated renderer, errors are rendered with it too.
+	RendererContextKey	= "renderer"
+	// RenderersContextKey stores renderers of the viewset handling the request.
+	RenderersContextKey	= "renderers"
+)
+
+// FormatParam is the query param overriding Accept header, example: ?format=json.
+var FormatParam = "format"
+
+// Renderer encodes response data to its media type.
+type Renderer interface {
+	MediaType() string
+	// Format is the short name used by FormatParam, example: "json".
+	Format() string
+	Render(w io.Writer, data any) error
+}
+
+type JSONRenderer struct {
+	Indent		string
+}
+
+func (r *JSONRenderer) MediaType() string {
+	return echo.MIMEApplicationJSON
+}
+
+func (r *JSONRenderer) Format() string {
+	return "json"
+}
+
+func (r *JSONRenderer) Render(w io.Writer, data any) error {
+	encoder := json.NewEncoder(w)
+	if r.Indent != "" {
+		encoder.SetIndent("", r.Indent)
+	}
+	return encoder.Encode(data)
+}
+
+var (
+	mu			sync.RWMutex
+	defaults	= []Renderer{&JSONRenderer{}}
+)
+
+// Register adds renderer to the default renderers, the first registered is used
+// when Accept header is missing or */*.
+func Register(renderer Renderer) {
+	mu.Lock()
+	defer mu.Unlock()
+	for i, registered := range defaults {
+		if registered.MediaType() == renderer.MediaType() {
+			defaults[i] = renderer
+			return
+		}
+	}
+	defaults = append(defaults, renderer)
+}
+
+// Defaults returns renderers used by views which don't declare their own.
+func Defaults() []Renderer {
+	mu.RLock()
+	defer mu.RUnlock()
+	return append([]Renderer{}, defaults...)
+}
+
+// Available returns renderers of the viewset handling the request, Defaults otherwise.
+func Available(c echo.Context) []Renderer {
+	if renderers, ok := c.Get(RenderersContextKey).([]Renderer); ok && len(renderers) > 0 {
+		return renderers
+	}
+	return Defaults()
+}
+
+// Negotiate selects renderer from FormatParam then Accept header,
+// responds 406 when none of the available renderers is acceptable.
+func Negotiate(c echo.Context) (Renderer, error) {
+	available := Available(c)
+	if format := c.QueryParam(FormatParam); format != "" {
+		for _, renderer := range available {
+			if renderer.Format() == format {
+				return renderer, nil
+			}
+		}
+		return nil, notAcceptable()
+	}
+	accept := c.Request().Header.Get(echo.HeaderAccept)
+	if strings.TrimSpace(accept) == "" {
+		return available[0], nil
+	}
+	for _, mediaRange := range parseAccept(accept) {
+		for _, renderer := range available {
+			if matches(mediaRange, renderer.MediaType()) {
+				return renderer, nil
+			}
+		}
+	}
+	return nil, notAcceptable()
+}
+
+// Render writes data with negotiated renderer.
+func Render(c echo.Context, status int, data any) error {
+	renderer, err := Negotiate(c)
+	if err != nil {
+		return errors.Handle(err, c)
+	}
+	c.Set(RendererContextKey, renderer)
+	return Write(c, renderer, status, data)
+}
+
+// Write writes data with renderer regardless of Accept header, LocalizedRenderer
+// renders for the language of the request.
+func Write(c echo.Context, renderer Renderer, status int, data any) error {
+	if localized, ok := renderer.(LocalizedRenderer); ok {
+		renderer = localized.Localized(i18n.Language(c))
+	}
+	response := c.Response()
+	response.Header().Add(echo.HeaderVary, echo.HeaderAccept)
+	response.Header().Set(echo.HeaderContentType, renderer.MediaType())
+	response.WriteHeader(status)
+	if c.Request().Method == http.MethodHead {
+		return nil
+	}
+	return renderer.Render(response, data)
+}
+
+// Middleware negotiates the renderer before the handler, so errors raised
+// by authentication and permissions are rendered in the requested format too.
+// Requests preferring streams are left to the handler, which streams or responds 406
+// when rendering, their errors are rendered by the first available renderer.
+func Middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		renderer, err := Negotiate(c)
+		if err != nil && WantsStream(c) {
+			renderer, err = Available(c)[0], nil
+		}
+		if err != nil {
+			return errors.Handle(err, c)
+		}
+		c.Set(RendererContextKey, renderer)
+		return next(c)
+	}
+}
+
+func notAcceptable() error {
+	return &errors.APIError{
+		Status: http.StatusNotAcceptable,
+		Message: "Could not satisfy the request Accept header.",
+		Code: errors.CodeNotAcceptable,
+	}
+}
+
+// parseAccept returns media ranges ordered by quality then specificity.
+func parseAccept(accept string) []string {
+	type weighted struct {
+		mediaType	string
+		quality		float64
+	}
+	ranges := []weighted{}
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if value, err := strconv.ParseFloat(q, 64); err == nil {
+				quality = value
+			}
+		}
+		if quality <= 0 {
+			continue
+		}
+		ranges = append(ranges, weighted{mediaType, quality})
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		if ranges[i].quality != ranges[j].quality {
+			return ranges[i].quality > ranges[j].quality
+		}
+		return strings.Count(ranges[i].mediaType, "*") < strings.Count(ranges[j].mediaType, "*")
+	})
+	result := make([]string, len(ranges))
+	for i, r := range ranges {
+		result[i] = r.mediaType
+	}
+	return result
+}
+
+// matches reports whether media range like "application/*" accepts mediaType.
+func matches(mediaRange string, mediaType string) bool {
+	if mediaRange == "*/*" || mediaRange == mediaType {
+		return true
+	}
+	rangeType, rangeSubtype, _ := strings.Cut(mediaRange, "/")
+	typ, _, _ := strings.Cut(mediaType, "/")
+	return rangeSubtype == "*" && rangeType == typ
+}
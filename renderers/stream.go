@@ -0,0 +1,133 @@
+package renderers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git/errors"
+)
+
+const (
+	MIMEApplicationNDJSON	= "application/x-ndjson"
+	MIMETextEventStream		= "text/event-stream"
+)
+
+// StreamFlushEvery is the number of items written before flushing to the client.
+var StreamFlushEvery = 100
+
+// WantsStream reports whether Accept header prefers NDJSON or server-sent events to the
+// available renderers, example: "application/json, application/x-ndjson;q=0.5" doesn't.
+func WantsStream(c echo.Context) bool {
+	return streamType(c) != ""
+}
+
+// streamType returns the stream media type preferred by Accept header, empty when
+// FormatParam is sent or a renderer is preferred.
+func streamType(c echo.Context) string {
+	if c.QueryParam(FormatParam) != "" {
+		return ""
+	}
+	available := Available(c)
+	for _, mediaRange := range parseAccept(c.Request().Header.Get(echo.HeaderAccept)) {
+		if mediaRange == MIMEApplicationNDJSON || mediaRange == MIMETextEventStream {
+			return mediaRange
+		}
+		for _, renderer := range available {
+			if matches(mediaRange, renderer.MediaType()) {
+				return ""
+			}
+		}
+	}
+	return ""
+}
+
+// StreamWriter writes items one by one as NDJSON, or server-sent events when
+// Accept is text/event-stream, so large results aren't held in memory.
+type StreamWriter struct {
+	context		echo.Context
+	events		bool
+	encoder		*json.Encoder
+	count		int
+}
+
+// NewStreamWriter writes the response header, errors after it can't change the status
+// so they are written as the last item, see Error.
+func NewStreamWriter(c echo.Context, status int) *StreamWriter {
+	response := c.Response()
+	events := streamType(c) == MIMETextEventStream
+	response.Header().Add(echo.HeaderVary, echo.HeaderAccept)
+	if events {
+		response.Header().Set(echo.HeaderContentType, MIMETextEventStream)
+		response.Header().Set(echo.HeaderCacheControl, "no-cache")
+	} else {
+		response.Header().Set(echo.HeaderContentType, MIMEApplicationNDJSON)
+	}
+	// disables proxy buffering, example: nginx.
+	response.Header().Set("X-Accel-Buffering", "no")
+	response.WriteHeader(status)
+	return &StreamWriter{
+		context: c,
+		events: events,
+		encoder: json.NewEncoder(response),
+	}
+}
+
+func (w *StreamWriter) Write(item any) error {
+	return w.write("", item)
+}
+
+// Error writes err as the last item, {"error": ..., "code": ...} line or "error" event.
+func (w *StreamWriter) Error(err error) error {
+	_, body := errors.Resolve(err)
+	body = errors.Localize(err, body, w.context)
+	if writeErr := w.write("error", body); writeErr != nil {
+		return writeErr
+	}
+	w.Flush()
+	return nil
+}
+
+// Close flushes remaining items, server-sent events stream ends with "end" event.
+func (w *StreamWriter) Close() error {
+	if w.events {
+		if _, err := w.context.Response().Write([]byte("event: end\ndata: {}\n\n")); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return nil
+}
+
+func (w *StreamWriter) Flush() {
+	if flusher, ok := w.context.Response().Writer.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *StreamWriter) write(event string, item any) error {
+	response := w.context.Response()
+	if w.events {
+		prefix := "data: "
+		if event != "" {
+			prefix = "event: " + event + "\n" + prefix
+		}
+		if _, err := response.Write([]byte(prefix)); err != nil {
+			return err
+		}
+	}
+	// json.Encoder ends each item with a newline.
+	if err := w.encoder.Encode(item); err != nil {
+		return err
+	}
+	if w.events {
+		if _, err := response.Write([]byte("\n")); err != nil {
+			return err
+		}
+	}
+	w.count++
+	if event != "" || w.count % StreamFlushEvery == 0 {
+		w.Flush()
+	}
+	return nil
+}
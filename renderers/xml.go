@@ -0,0 +1,90 @@
+package renderers
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/labstack/echo/v4"
+)
+
+// XMLRenderer renders data as it would be rendered to json, fields are named by json tag,
+// list items are <list-item> elements, example: <root><id>1</id><tags><list-item>x</list-item></tags></root>.
+type XMLRenderer struct {
+	// RootName defaults to "root".
+	RootName	string
+}
+
+func (r *XMLRenderer) MediaType() string {
+	return echo.MIMEApplicationXML
+}
+
+func (r *XMLRenderer) Format() string {
+	return "xml"
+}
+
+func (r *XMLRenderer) Render(w io.Writer, data any) error {
+	// encode through json so json tags, omitempty and custom marshalers apply.
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	rootName := r.RootName
+	if rootName == "" {
+		rootName = "root"
+	}
+	encoder := xml.NewEncoder(w)
+	if err := encodeXML(encoder, rootName, value); err != nil {
+		return err
+	}
+	return encoder.Flush()
+}
+
+func encodeXML(encoder *xml.Encoder, name string, value any) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	if err := encoder.EncodeToken(start); err != nil {
+		return err
+	}
+	switch value := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if err := encodeXML(encoder, key, value[key]); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, item := range value {
+			if err := encodeXML(encoder, "list-item", item); err != nil {
+				return err
+			}
+		}
+	case nil:
+	case string:
+		if err := encoder.EncodeToken(xml.CharData(value)); err != nil {
+			return err
+		}
+	default:
+		// json.Number and bool.
+		if err := encoder.EncodeToken(xml.CharData(fmt.Sprint(value))); err != nil {
+			return err
+		}
+	}
+	return encoder.EncodeToken(start.End())
+}
@@ -0,0 +1,40 @@
+package renderers
+
+import (
+	"encoding/json"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+const MIMEApplicationYAML = "application/yaml"
+
+// YAMLRenderer renders application/yaml, fields are named by json tag,
+// opt in with renderers.Register(&renderers.YAMLRenderer{}).
+type YAMLRenderer struct{}
+
+func (r *YAMLRenderer) MediaType() string {
+	return MIMEApplicationYAML
+}
+
+func (r *YAMLRenderer) Format() string {
+	return "yaml"
+}
+
+func (r *YAMLRenderer) Render(w io.Writer, data any) error {
+	// encode through json so json tags, omitempty and custom marshalers apply.
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	var value any
+	if err := yaml.Unmarshal(raw, &value); err != nil {
+		return err
+	}
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(value); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
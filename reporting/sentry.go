@@ -0,0 +1,65 @@
+package reporting
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/rimba47prayoga/gorim.git/errors"
+	"github.com/rimba47prayoga/gorim.git/utils"
+)
+
+// SentryReporter sends unhandled errors and panics to Sentry,
+// example: errors.AddReporter(reporting.NewSentryReporter(sentry.CurrentHub())).
+type SentryReporter struct {
+	Hub		*sentry.Hub
+}
+
+func NewSentryReporter(hub *sentry.Hub) *SentryReporter {
+	return &SentryReporter{Hub: hub}
+}
+
+// SetupSentry initializes sentry client and registers its reporter.
+func SetupSentry(options sentry.ClientOptions) (*SentryReporter, error) {
+	if err := sentry.Init(options); err != nil {
+		return nil, err
+	}
+	reporter := NewSentryReporter(sentry.CurrentHub())
+	errors.AddReporter(reporter)
+	return reporter, nil
+}
+
+func (r *SentryReporter) Report(event *errors.ErrorEvent) {
+	hub := r.Hub
+	if hub == nil {
+		hub = sentry.CurrentHub()
+	}
+	hub.WithScope(func(scope *sentry.Scope) {
+		if event.Request != nil {
+			scope.SetRequest(event.Request)
+		}
+		if event.Route != "" {
+			scope.SetTag("route", event.Route)
+		}
+		if event.RequestID != "" {
+			scope.SetTag("request_id", event.RequestID)
+		}
+		if userID, ok := utils.GetUserID(event.User); ok {
+			scope.SetUser(sentry.User{ID: fmt.Sprint(userID)})
+		}
+		if event.Stack != nil {
+			scope.SetLevel(sentry.LevelFatal)
+			scope.SetContext("panic", sentry.Context{"stack": string(event.Stack)})
+		}
+		hub.CaptureException(event.Err)
+	})
+}
+
+// Flush waits until buffered events are sent, call it before the process exits.
+func (r *SentryReporter) Flush(timeout time.Duration) bool {
+	hub := r.Hub
+	if hub == nil {
+		hub = sentry.CurrentHub()
+	}
+	return hub.Flush(timeout)
+}
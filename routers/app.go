@@ -0,0 +1,101 @@
+package routers
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git/interfaces"
+)
+
+// AppRegistration records a viewset registered on AppRouter, replayed on Mount.
+type AppRegistration struct {
+	Prefix			string
+	HandlerFunc		interface{}
+	Options			[]RegisterOption
+	Children		[]*AppRegistration
+}
+
+// Register records nested viewset, see DefaultRouter.Register.
+func (a *AppRegistration) Register(prefix string, handlerFunc interface{}, options ...RegisterOption) *AppRegistration {
+	child := &AppRegistration{
+		Prefix: prefix,
+		HandlerFunc: handlerFunc,
+		Options: options,
+	}
+	a.Children = append(a.Children, child)
+	return child
+}
+
+func (a *AppRegistration) registerChildren(router *DefaultRouter[interfaces.IBaseView]) {
+	for _, child := range a.Children {
+		childRouter := router.Register(child.Prefix, child.HandlerFunc, child.Options...)
+		child.registerChildren(childRouter)
+	}
+}
+
+type appMount struct {
+	prefix		string
+	app			*AppRouter
+}
+
+// AppRouter is built by an app package without knowing where it's mounted,
+// like Django's include():
+//
+//	func Router() *routers.AppRouter {
+//		app := routers.NewAppRouter("billing")
+//		app.Register("invoices", NewInvoiceViewSet)
+//		return app
+//	}
+//
+//	router.Mount("/billing", billing.Router())
+type AppRouter struct {
+	Namespace		string
+	Middleware		[]echo.MiddlewareFunc
+	Registrations	[]*AppRegistration
+	mounts			[]appMount
+}
+
+func NewAppRouter(namespace string, middleware ...echo.MiddlewareFunc) *AppRouter {
+	return &AppRouter{
+		Namespace: namespace,
+		Middleware: middleware,
+	}
+}
+
+func (a *AppRouter) Use(middleware ...echo.MiddlewareFunc) {
+	a.Middleware = append(a.Middleware, middleware...)
+}
+
+func (a *AppRouter) Register(prefix string, handlerFunc interface{}, options ...RegisterOption) *AppRegistration {
+	registration := &AppRegistration{
+		Prefix: prefix,
+		HandlerFunc: handlerFunc,
+		Options: options,
+	}
+	a.Registrations = append(a.Registrations, registration)
+	return registration
+}
+
+// Mount includes another app under prefix of this app.
+func (a *AppRouter) Mount(prefix string, app *AppRouter) {
+	a.mounts = append(a.mounts, appMount{prefix: prefix, app: app})
+}
+
+// Mount registers the app viewsets under prefix with the app middleware,
+// route names are namespaced by the app, example: "billing:invoices-list".
+func (r *Router) Mount(prefix string, app *AppRouter) *Router {
+	sub := r.Group(prefix, app.Middleware...)
+	if app.Namespace != "" {
+		if sub.Namespace != "" {
+			sub.Namespace = sub.Namespace + ":" + app.Namespace
+		} else {
+			sub.Namespace = app.Namespace
+		}
+	}
+	for _, registration := range app.Registrations {
+		router := sub.Register(registration.Prefix, registration.HandlerFunc, registration.Options...)
+		registration.registerChildren(router)
+	}
+	for _, mount := range app.mounts {
+		sub.Mount(mount.prefix, mount.app)
+	}
+	return sub
+}
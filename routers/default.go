@@ -4,16 +4,29 @@ import (
 	"fmt"
 	"net/http"
 	"reflect"
+	"strings"
 
 	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/di"
+	"github.com/rimba47prayoga/gorim.git/errors"
+	"github.com/rimba47prayoga/gorim.git/hooks"
 	"github.com/rimba47prayoga/gorim.git/interfaces"
+	"github.com/rimba47prayoga/gorim.git/middlewares"
+	"github.com/rimba47prayoga/gorim.git/permissions"
+	"github.com/rimba47prayoga/gorim.git/tracing"
 	"github.com/rimba47prayoga/gorim.git/utils"
 )
 
 
 type DefaultRouter[T interfaces.IBaseView] struct {
-	RouteGroup	*gorim.Group
-	HandlerFunc func() T
+	RouteGroup		*gorim.Group
+	HandlerFunc 	func() T
+	ParentLookups	[]ParentLookup
+	TrailingSlash	*TrailingSlashConfig
+	// Basename names the routes, example: "users" names List route "users-list".
+	Basename		string
+	// Container injects fields of viewsets tagged `inject:""`, nil uses di.Default.
+	Container		*di.Container
 }
 
 func NewDefaultRouter[T interfaces.IBaseView](group *gorim.Group, handlerFunc func() T) *DefaultRouter[T] {
@@ -28,46 +41,122 @@ func NewDefaultRouter[T interfaces.IBaseView](group *gorim.Group, handlerFunc fu
 func(r *DefaultRouter[T]) SetupHandler(action string, c gorim.Context) T {
 	// Helper function to create and configure a handler
 	handler := r.HandlerFunc()
+	c.Set(gorim.ActionContextKey, action)
+	if len(r.ParentLookups) > 0 {
+		lookups := map[string]string{}
+		for _, lookup := range r.ParentLookups {
+			lookups[lookup.GetField()] = c.Param(lookup.Param)
+		}
+		c.Set(gorim.ParentLookupsContextKey, lookups)
+	}
 	handler.SetAction(action)
 	handler.SetContext(c)
 	return handler
 }
 
+// GetContainer returns Container, di.Default when it's nil.
+func(r *DefaultRouter[T]) GetContainer() *di.Container {
+	if r.Container != nil {
+		return r.Container
+	}
+	return di.Default
+}
+
 func(r *DefaultRouter[T]) RegisterFunc(name string, httpMethod string, path string) {
 	r.HandleRoute(httpMethod, path, name)
 }
 
 // Helper function to handle common route logic
 func(r *DefaultRouter[T]) HandleRoute(method, path, action string) {
+	handler := r.ActionHandler(action)
+	if r.TrailingSlash == nil {
+		route := r.RouteGroup.Add(method, path, handler)
+		route.Name = r.RouteName(action)
+		r.recordRoute(route, action)
+		return
+	}
+	canonical, alternate := r.TrailingSlash.Paths(path)
+	route := r.RouteGroup.Add(method, canonical, handler)
+	route.Name = r.RouteName(action)
+	r.recordRoute(route, action)
+	if r.TrailingSlash.RedirectCode != 0 {
+		r.RouteGroup.Add(method, alternate, r.TrailingSlash.Redirect)
+	} else {
+		r.RouteGroup.Add(method, alternate, handler)
+	}
+}
+
+// RouteName returns name of the action route, example: "users-partial-update".
+func(r *DefaultRouter[T]) RouteName(action string) string {
+	if r.Basename == "" {
+		return ""
+	}
+	return r.Basename + "-" + toKebabCase(action)
+}
 
-	r.RouteGroup.Add(method, path, func(c gorim.Context) error {
+// ActionHandler checks permissions and throttles then calls the viewset action.
+func(r *DefaultRouter[T]) ActionHandler(action string) gorim.HandlerFunc {
+	return func(c gorim.Context) error {
 		handler := r.SetupHandler(action, c)
+		scope, end := r.GetContainer().Begin(c)
+		defer end()
+		if err := scope.Inject(handler); err != nil {
+			return errors.Handle(err, c)
+		}
 		if !utils.HasAttr(handler, action) {
 			msg := fmt.Sprintf("%s has no attribute or method %s", utils.GetStructName(handler), action)
 			panic(msg)
 		}
 		// Get the method by name using reflection
 		methodVal := reflect.ValueOf(handler).MethodByName(action)
-		if !handler.HasPermission(c) {
-			return c.JSON(http.StatusForbidden, gorim.Response{
-				"error": "You are not authorized to access this resource",
-			})
+		if checkedView, ok := any(handler).(interfaces.IPermissionCheckedView); ok {
+			if permission := checkedView.CheckPermissions(c); permission != nil {
+				return permissions.Deny(c, permission)
+			}
+		} else if !handler.HasPermission(c) {
+			return permissions.Deny(c, nil)
 		}
-		// Call the method with gorim.Context argument and capture return values
-		result := methodVal.Call([]reflect.Value{reflect.ValueOf(c)})
-
-		// Assuming the method returns an error as the last return value
-		if len(result) > 0 {
-			// Convert the last return value to error
-			if errInterface := result[len(result)-1].Interface(); errInterface != nil {
-				if err, ok := errInterface.(error); ok {
-					// Return the error if present
-					return err
-				}
+		if throttledView, ok := any(handler).(interfaces.IThrottledView); ok {
+			if allowed, wait := throttledView.CheckThrottles(c); !allowed {
+				return errors.Handle(&errors.ThrottledError{Wait: wait}, c)
 			}
 		}
-		return nil
-	})
+		if cachedView, ok := any(handler).(interfaces.ICachedView); ok {
+			return cachedView.ServeCached(c, action, func() error {
+				return r.callAction(handler, action, methodVal, c)
+			})
+		}
+		return r.callAction(handler, action, methodVal, c)
+	}
+}
+
+// callAction calls the viewset action with gorim.Context and renders the returned error.
+func(r *DefaultRouter[T]) callAction(handler T, action string, methodVal reflect.Value, c gorim.Context) error {
+	view := utils.GetStructName(handler)
+	span := tracing.StartSpan(c, view + "." + action)
+	defer span.End()
+	hooks.Emit(hooks.PreAction, c, &hooks.Info{View: view, Action: action})
+	defer func() {
+		// errors raised by errors.Raise end actions too, RecoverMiddleware renders them.
+		if r := recover(); r != nil {
+			hooks.Emit(hooks.PostAction, c, &hooks.Info{View: view, Action: action, Err: middlewares.PanicError(r)})
+			panic(r)
+		}
+	}()
+	// Call the method with gorim.Context argument and capture return values
+	result := methodVal.Call([]reflect.Value{reflect.ValueOf(c)})
+
+	// Assuming the method returns an error as the last return value
+	var err error
+	if len(result) > 0 {
+		err, _ = result[len(result)-1].Interface().(error)
+	}
+	hooks.Emit(hooks.PostAction, c, &hooks.Info{View: view, Action: action, Err: err})
+	if err != nil {
+		tracing.SetError(span, err)
+		return errors.Handle(err, c)
+	}
+	return nil
 }
 
 func (r *DefaultRouter[T]) AutoDiscover() {
@@ -84,7 +173,69 @@ func (r *DefaultRouter[T]) AutoDiscover() {
     if utils.HasAttr(handler, "Update") {
         r.HandleRoute(http.MethodPut, "/:pk", "Update")
     }
-    if utils.HasAttr(handler, "Delete") {
+    if utils.HasAttr(handler, "PartialUpdate") {
+        r.HandleRoute(http.MethodPatch, "/:pk", "PartialUpdate")
+    }
+    if utils.HasAttr(handler, "Destroy") {
+        r.HandleRoute(http.MethodDelete, "/:pk", "Destroy")
+    } else if utils.HasAttr(handler, "Delete") {
         r.HandleRoute(http.MethodDelete, "/:pk", "Delete")
     }
+	actions := DiscoverActions(handler)
+	if view, ok := any(handler).(interfaces.ISoftDeleteView); ok && view.SoftDeleteEnabled() {
+		r.registerUndeclared(actions,
+			Action("ListDeleted", http.MethodGet, "/deleted"),
+			DetailAction("Restore", http.MethodPost, "/restore"),
+		)
+	}
+	if view, ok := any(handler).(interfaces.IAggregateView); ok && view.HasAggregations() {
+		r.registerUndeclared(actions, Action("Aggregate", http.MethodGet, "/aggregate/:aggregation"))
+	}
+	if view, ok := any(handler).(interfaces.IWatchView); ok && view.WatchEnabled() {
+		r.registerUndeclared(actions, Action("Watch", http.MethodGet, "/watch"))
+	}
+	for _, action := range actions {
+		r.RegisterFunc(action.Name, action.Method, action.RoutePath())
+	}
+}
+
+// registerUndeclared routes default actions of the viewset unless declared by ActionTag.
+func (r *DefaultRouter[T]) registerUndeclared(declared []ExtraAction, defaults ...ExtraAction) {
+	for _, action := range defaults {
+		if isDeclared(declared, action.Name) {
+			continue
+		}
+		r.RegisterFunc(action.Name, action.Method, action.RoutePath())
+	}
+}
+
+func isDeclared(actions []ExtraAction, name string) bool {
+	for _, action := range actions {
+		if action.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Register nests viewset under this router, requires Parent option,
+// example: router.Register("projects", NewProjectViewSet).Register("tasks", NewTaskViewSet, routers.Parent("project_id"))
+// generates /projects/:project_id/tasks and filters tasks by project_id.
+func (r *DefaultRouter[T]) Register(prefix string, handlerFunc interface{}, options ...RegisterOption) *DefaultRouter[interfaces.IBaseView] {
+	registration := newRegistration(options)
+	if registration.parent == nil {
+		panic(fmt.Sprintf("nested route %s requires routers.Parent option", prefix))
+	}
+	path := fmt.Sprintf("/:%s/%s", registration.parent.Param, strings.Trim(prefix, "/"))
+	group := r.RouteGroup.Group(path)
+	router := &DefaultRouter[interfaces.IBaseView]{
+		RouteGroup: group,
+		HandlerFunc: ToHandlerFunc(handlerFunc),
+		ParentLookups: append(append([]ParentLookup{}, r.ParentLookups...), *registration.parent),
+		TrailingSlash: r.TrailingSlash,
+		Basename: r.Basename + "-" + toBasename(prefix),
+	}
+	router.AutoDiscover()
+	registration.registerActions(router)
+	return router
 }
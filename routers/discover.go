@@ -0,0 +1,82 @@
+package routers
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/rimba47prayoga/gorim.git"
+)
+
+// ActionTag marks a viewset method as routed extra action, declared as blank field:
+//
+//	type UserViewSet struct {
+//		views.ModelViewSet[models.User]
+//		_ routers.ActionTag `action:"Activate" method:"POST" detail:"true"`
+//	}
+//
+//	func (h *UserViewSet) Activate(c gorim.Context) error
+//
+// method defaults to GET, path defaults to kebab-case of the action, example: /activate.
+type ActionTag struct{}
+
+var actionTagType = reflect.TypeOf(ActionTag{})
+var actionSignature = reflect.TypeOf(func(gorim.Context) error { return nil })
+
+// DiscoverActions returns extra actions declared by ActionTag fields of the viewset.
+func DiscoverActions(handler interface{}) []ExtraAction {
+	actions := []ExtraAction{}
+	typ := reflect.TypeOf(handler)
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return actions
+	}
+	handlerVal := reflect.ValueOf(handler)
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.Type != actionTagType {
+			continue
+		}
+		name := field.Tag.Get("action")
+		if name == "" {
+			panic(fmt.Sprintf("%s: ActionTag field requires action tag", typ.Name()))
+		}
+		method := handlerVal.MethodByName(name)
+		if !method.IsValid() || method.Type() != actionSignature {
+			panic(fmt.Sprintf("%s.%s must be func(gorim.Context) error", typ.Name(), name))
+		}
+		httpMethod := strings.ToUpper(field.Tag.Get("method"))
+		if httpMethod == "" {
+			httpMethod = http.MethodGet
+		}
+		path := field.Tag.Get("path")
+		if path == "" {
+			path = "/" + toKebabCase(name)
+		}
+		actions = append(actions, ExtraAction{
+			Name: name,
+			Method: httpMethod,
+			Path: path,
+			Detail: field.Tag.Get("detail") == "true",
+		})
+	}
+	return actions
+}
+
+func toKebabCase(name string) string {
+	var result []rune
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				result = append(result, '-')
+			}
+			r = unicode.ToLower(r)
+		}
+		result = append(result, r)
+	}
+	return string(result)
+}
@@ -0,0 +1,78 @@
+package routers
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git"
+)
+
+// dispatchSkippedHeaders aren't passed to dispatched requests, they're answered in JSON
+// without conditions.
+var dispatchSkippedHeaders = []string{
+	"Accept", "Accept-Encoding", "Connection", "Content-Length", "Content-Type", "Te", "Trailer", "Upgrade",
+	"If-Match", "If-None-Match", "If-Modified-Since", "If-Unmodified-Since", "Range", "Idempotency-Key",
+}
+
+var routeParam = regexp.MustCompile(`:([^/]+)`)
+
+// Dispatch serves a request of route through the server of c, so middlewares,
+// authentication, permissions, throttles and serializers apply like for clients of the
+// route, used by gateways of other protocols. Params fill the path, body is sent as
+// JSON when not nil, headers of c are passed except those of content negotiation,
+// conditional requests and idempotency.
+func Dispatch(c gorim.Context, route RouteInfo, params map[string]string, query url.Values, body []byte) (*httptest.ResponseRecorder, error) {
+	path := routeParam.ReplaceAllStringFunc(route.Path, func(param string) string {
+		return url.PathEscape(params[param[1:]])
+	})
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	return DispatchPath(c, route.Method, path, nil, body)
+}
+
+// DispatchPath is Dispatch of a request to target, path and query, header is set after
+// the headers of c, so it can send the skipped ones.
+func DispatchPath(c gorim.Context, method string, target string, header http.Header, body []byte) (*httptest.ResponseRecorder, error) {
+	var reader io.Reader = http.NoBody
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	outer := c.Request()
+	request, err := http.NewRequestWithContext(outer.Context(), method, target, reader)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range outer.Header {
+		if !isSkippedHeader(name) {
+			request.Header[name] = values
+		}
+	}
+	request.Header.Set(echo.HeaderAccept, echo.MIMEApplicationJSON)
+	if body != nil {
+		request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	}
+	for name, values := range header {
+		request.Header[http.CanonicalHeaderKey(name)] = values
+	}
+	request.RemoteAddr = outer.RemoteAddr
+	request.Host = outer.Host
+	recorder := httptest.NewRecorder()
+	c.Echo().ServeHTTP(recorder, request)
+	return recorder, nil
+}
+
+func isSkippedHeader(name string) bool {
+	name = http.CanonicalHeaderKey(name)
+	for _, skipped := range dispatchSkippedHeaders {
+		if name == skipped {
+			return true
+		}
+	}
+	return false
+}
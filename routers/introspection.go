@@ -0,0 +1,130 @@
+package routers
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/interfaces"
+	"github.com/rimba47prayoga/gorim.git/permissions"
+	"github.com/rimba47prayoga/gorim.git/utils"
+)
+
+// RouteInfo describes a viewset route registered by routers.
+type RouteInfo struct {
+	Method			string		`json:"method"`
+	Path			string		`json:"path"`
+	Name			string		`json:"name"`
+	// Basename is shared by routes of the viewset, example: "users".
+	Basename		string		`json:"basename"`
+	ViewSet			string		`json:"viewset"`
+	Action			string		`json:"action"`
+	Permissions		[]string	`json:"permissions"`
+	// HandlerFunc creates the viewset, used to introspect serializers for schema generation.
+	HandlerFunc		func() interfaces.IBaseView	`json:"-"`
+}
+
+type permissionsView interface {
+	GetPermissions(gorim.Context) []interfaces.IPermission
+}
+
+// routeEntry is a recorded route, ViewSet and Permissions are described on first
+// introspection so registering routes doesn't construct viewsets.
+type routeEntry struct {
+	info		RouteInfo
+	once		sync.Once
+	describe	func(*RouteInfo)
+}
+
+func (e *routeEntry) get() RouteInfo {
+	e.once.Do(func() {
+		e.describe(&e.info)
+	})
+	return e.info
+}
+
+var (
+	routesMu	sync.RWMutex
+	routes		[]*routeEntry
+)
+
+// Routes returns viewset routes registered so far, sorted by path and method.
+func Routes() []RouteInfo {
+	routesMu.RLock()
+	result := make([]RouteInfo, 0, len(routes))
+	for _, entry := range routes {
+		result = append(result, entry.get())
+	}
+	routesMu.RUnlock()
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Path != result[j].Path {
+			return result[i].Path < result[j].Path
+		}
+		return result[i].Method < result[j].Method
+	})
+	return result
+}
+
+// LookupRoute returns viewset route by method and path as registered in echo.
+func LookupRoute(method string, path string) (RouteInfo, bool) {
+	routesMu.RLock()
+	defer routesMu.RUnlock()
+	for _, entry := range routes {
+		if entry.info.Method == method && entry.info.Path == path {
+			return entry.get(), true
+		}
+	}
+	return RouteInfo{}, false
+}
+
+func (r *DefaultRouter[T]) recordRoute(route *echo.Route, action string) {
+	entry := &routeEntry{
+		info: RouteInfo{
+			Method: route.Method,
+			Path: route.Path,
+			Name: route.Name,
+			Basename: r.Basename,
+			Action: action,
+			HandlerFunc: func() interfaces.IBaseView {
+				return r.HandlerFunc()
+			},
+		},
+		describe: func(info *RouteInfo) {
+			handler := r.HandlerFunc()
+			info.ViewSet = utils.GetStructName(handler)
+			info.Permissions = actionPermissions(handler, info.Method, info.Path, info.Action)
+		},
+	}
+	routesMu.Lock()
+	routes = append(routes, entry)
+	routesMu.Unlock()
+}
+
+// actionPermissions returns permission names of the action,
+// GetPermissions is called with a blank request since there is no real one.
+func actionPermissions(handler interfaces.IBaseView, method string, path string, action string) (names []string) {
+	view, ok := any(handler).(permissionsView)
+	if !ok {
+		return nil
+	}
+	defer func() {
+		if recover() != nil {
+			names = nil
+		}
+	}()
+	request, err := http.NewRequest(method, path, nil)
+	if err != nil {
+		return nil
+	}
+	echoContext := echo.New().NewContext(request, nil)
+	echoContext.Set(gorim.ActionContextKey, action)
+	c := gorim.NewContext(echoContext)
+	handler.SetAction(action)
+	handler.SetContext(c)
+	for _, permission := range view.GetPermissions(c) {
+		names = append(names, permissions.PermissionName(permission))
+	}
+	return names
+}
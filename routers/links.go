@@ -0,0 +1,173 @@
+package routers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/interfaces"
+)
+
+// linkerContextKey caches the Linker of the request, so links of every object share the
+// permission checks of the viewset.
+const linkerContextKey = "gorim:linker"
+
+// Link of an action the requester may perform, rendered in "_links" of objects.
+type Link struct {
+	Href	string	`json:"href"`
+	Method	string	`json:"method"`
+}
+
+// linkNames names links of the standard actions, other actions are named in snake case,
+// example: "Activate" => "activate".
+var linkNames = map[string]string{
+	"Retrieve": "self",
+	"Update": "update",
+	"PartialUpdate": "partial_update",
+	"Destroy": "delete",
+}
+
+// LinkName returns name of the link of action, example: "Destroy" => "delete".
+func LinkName(action string) string {
+	if name, ok := linkNames[action]; ok {
+		return name
+	}
+	return strings.ReplaceAll(toKebabCase(action), "-", "_")
+}
+
+// Reverse returns path of the route named name, path params are filled from params,
+// example: Reverse("users-retrieve", map[string]string{"pk": "1"}) => "/api/v1/users/1".
+func Reverse(name string, params map[string]string) (string, error) {
+	routesMu.RLock()
+	defer routesMu.RUnlock()
+	for _, entry := range routes {
+		if entry.info.Name == name && name != "" {
+			return buildPath(entry.info.Path, params)
+		}
+	}
+	return "", fmt.Errorf("routers: no route named %q", name)
+}
+
+// buildPath replaces ":param" segments of path with escaped params.
+func buildPath(path string, params map[string]string) (string, error) {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, ":") {
+			continue
+		}
+		value, ok := params[segment[1:]]
+		if !ok || value == "" {
+			return "", fmt.Errorf("routers: missing param %s of %s", segment[1:], path)
+		}
+		segments[i] = url.PathEscape(value)
+	}
+	return strings.Join(segments, "/"), nil
+}
+
+// linkRoute is a detail route of the viewset the requester has permission for,
+// object permissions are checked by object with request of the route.
+type linkRoute struct {
+	info		RouteInfo
+	request		*http.Request
+	permissions	[]interfaces.IPermission
+}
+
+// Linker builds links of objects served by the viewset of a request to its detail
+// routes: Retrieve, Update, PartialUpdate, Destroy and detail actions. Routes whose
+// permissions deny the requester are left out, as are routes whose object permissions
+// deny the object.
+type Linker struct {
+	context		gorim.Context
+	routes		[]linkRoute
+}
+
+// GetLinker returns Linker of the viewset route serving c, created on the first call
+// of the request.
+func GetLinker(c gorim.Context) *Linker {
+	if linker, ok := c.Get(linkerContextKey).(*Linker); ok {
+		return linker
+	}
+	linker := &Linker{context: c}
+	if current, ok := LookupRoute(c.Request().Method, c.Path()); ok && current.Basename != "" {
+		linker.routes = allowedRoutes(c, current.Basename)
+	}
+	c.Set(linkerContextKey, linker)
+	return linker
+}
+
+// allowedRoutes checks permissions of detail routes of basename as if they served c,
+// with a clone of its request having the method of the route, example: permissions
+// allowing safe methods only deny Destroy.
+func allowedRoutes(c gorim.Context, basename string) []linkRoute {
+	// handlers of the routes are set up with their action, the action and request of c are restored.
+	action := c.GetAction()
+	request := c.Request()
+	defer c.Set(gorim.ActionContextKey, action)
+	defer c.SetRequest(request)
+	allowed := []linkRoute{}
+	for _, info := range Routes() {
+		if info.Basename != basename || info.HandlerFunc == nil || !strings.Contains(info.Path, "/:pk") {
+			continue
+		}
+		routeRequest := request.Clone(request.Context())
+		routeRequest.Method = info.Method
+		c.SetRequest(routeRequest)
+		c.Set(gorim.ActionContextKey, info.Action)
+		handler := info.HandlerFunc()
+		handler.SetAction(info.Action)
+		handler.SetContext(c)
+		if checkedView, ok := any(handler).(interfaces.IPermissionCheckedView); ok {
+			if checkedView.CheckPermissions(c) != nil {
+				continue
+			}
+		} else if !handler.HasPermission(c) {
+			continue
+		}
+		route := linkRoute{info: info, request: routeRequest}
+		if view, ok := any(handler).(permissionsView); ok {
+			route.permissions = view.GetPermissions(c)
+		}
+		allowed = append(allowed, route)
+	}
+	return allowed
+}
+
+// Links returns links of instance, pk is the value of its ":pk" param, other params
+// are taken from the request, example: parent of nested routes.
+func (l *Linker) Links(instance interface{}, pk string) map[string]Link {
+	links := map[string]Link{}
+	params := map[string]string{}
+	for _, name := range l.context.ParamNames() {
+		params[name] = l.context.Param(name)
+	}
+	params["pk"] = pk
+	for _, route := range l.routes {
+		if !hasObjectPermissions(l.context, route, instance) {
+			continue
+		}
+		href, err := buildPath(route.info.Path, params)
+		if err != nil {
+			continue
+		}
+		links[LinkName(route.info.Action)] = Link{Href: href, Method: route.info.Method}
+	}
+	return links
+}
+
+func hasObjectPermissions(c gorim.Context, route linkRoute, instance interface{}) bool {
+	action := c.GetAction()
+	request := c.Request()
+	defer c.Set(gorim.ActionContextKey, action)
+	defer c.SetRequest(request)
+	c.Set(gorim.ActionContextKey, route.info.Action)
+	c.SetRequest(route.request)
+	for _, permission := range route.permissions {
+		objectPermission, ok := permission.(interfaces.IObjectPermission)
+		if ok && !objectPermission.HasObjectPermission(c, instance) {
+			return false
+		}
+	}
+	return true
+}
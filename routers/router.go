@@ -0,0 +1,183 @@
+package routers
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/di"
+	"github.com/rimba47prayoga/gorim.git/interfaces"
+)
+
+// RegisterOption configures viewset registration, see Action, DetailAction and Parent.
+type RegisterOption interface {
+	apply(*registration)
+}
+
+type registration struct {
+	actions		[]ExtraAction
+	parent		*ParentLookup
+}
+
+func newRegistration(options []RegisterOption) *registration {
+	reg := registration{}
+	for _, option := range options {
+		option.apply(&reg)
+	}
+	return &reg
+}
+
+func (reg *registration) registerActions(router *DefaultRouter[interfaces.IBaseView]) {
+	for _, action := range reg.actions {
+		router.RegisterFunc(action.Name, action.Method, action.RoutePath())
+	}
+}
+
+// ExtraAction is a custom viewset method exposed as route,
+// Detail action is routed under /:pk.
+type ExtraAction struct {
+	Name		string
+	Method		string
+	Path		string
+	Detail		bool
+}
+
+// RoutePath returns path relative to the viewset prefix.
+func (a ExtraAction) RoutePath() string {
+	path := "/" + strings.TrimPrefix(a.Path, "/")
+	if a.Detail {
+		path = "/:pk" + path
+	}
+	return path
+}
+
+func (a ExtraAction) apply(reg *registration) {
+	reg.actions = append(reg.actions, a)
+}
+
+// Action declares extra action on the collection, example: Action("Export", http.MethodGet, "/export")
+func Action(name string, method string, path string) ExtraAction {
+	return ExtraAction{Name: name, Method: method, Path: path}
+}
+
+// DetailAction declares extra action on single object, example: DetailAction("Activate", http.MethodPost, "/activate")
+func DetailAction(name string, method string, path string) ExtraAction {
+	return ExtraAction{Name: name, Method: method, Path: path, Detail: true}
+}
+
+// ParentLookup is the path param of the parent object in nested routes,
+// Field is the column of the child referencing the parent, default same as Param.
+type ParentLookup struct {
+	Param		string
+	Field		string
+}
+
+func (p ParentLookup) GetField() string {
+	if p.Field == "" {
+		return p.Param
+	}
+	return p.Field
+}
+
+func (p ParentLookup) apply(reg *registration) {
+	reg.parent = &p
+}
+
+// Parent declares the parent lookup of nested viewset, example: Parent("project_id")
+func Parent(param string) ParentLookup {
+	return ParentLookup{Param: param}
+}
+
+// Router registers viewsets under a group, generating the standard routes.
+//
+//	router := routers.NewRouter(api)
+//	router.Register("users", user.NewUserViewSet, routers.Action("UpdateProfile", http.MethodPost, "/profile"))
+type Router struct {
+	RouteGroup		*gorim.Group
+	Prefix			string
+	// TrailingSlash applies to viewsets registered after it's set, nil registers paths as is.
+	TrailingSlash	*TrailingSlashConfig
+	// Namespace prefixes route names, example: "billing:invoices-list".
+	Namespace		string
+	// Registry is shared with sub routers created by Group, keyed by full prefix.
+	Registry		map[string]*DefaultRouter[interfaces.IBaseView]
+	// Container provides dependencies of viewsets registered after it's set, nil uses di.Default.
+	Container		*di.Container
+}
+
+func NewRouter(group *gorim.Group, middleware ...echo.MiddlewareFunc) *Router {
+	if len(middleware) > 0 {
+		group.Use(middleware...)
+	}
+	return &Router{
+		RouteGroup: group,
+		Registry: map[string]*DefaultRouter[interfaces.IBaseView]{},
+	}
+}
+
+// Group returns sub router under prefix, the middleware applies to every viewset registered on it,
+// example: router.Group("/admin", authentication.Chain(jwtAuth), tenancy.Middleware)
+func (r *Router) Group(prefix string, middleware ...echo.MiddlewareFunc) *Router {
+	prefix = "/" + strings.Trim(prefix, "/")
+	return &Router{
+		RouteGroup: r.RouteGroup.Group(prefix, middleware...),
+		Prefix: r.Prefix + prefix,
+		TrailingSlash: r.TrailingSlash,
+		Namespace: r.Namespace,
+		Registry: r.Registry,
+		Container: r.Container,
+	}
+}
+
+// Use adds middleware to viewsets registered after the call.
+func (r *Router) Use(middleware ...echo.MiddlewareFunc) {
+	r.RouteGroup.Use(middleware...)
+}
+
+// ToHandlerFunc converts `func() *XViewSet` into func returning interfaces.IBaseView.
+func ToHandlerFunc(handlerFunc interface{}) func() interfaces.IBaseView {
+	if fn, ok := handlerFunc.(func() interfaces.IBaseView); ok {
+		return fn
+	}
+	fnVal := reflect.ValueOf(handlerFunc)
+	fnType := fnVal.Type()
+	viewType := reflect.TypeOf((*interfaces.IBaseView)(nil)).Elem()
+	if fnType.Kind() != reflect.Func || fnType.NumIn() != 0 || fnType.NumOut() != 1 || !fnType.Out(0).Implements(viewType) {
+		panic(fmt.Sprintf("handlerFunc must be func() returning interfaces.IBaseView, got %s", fnType))
+	}
+	return func() interfaces.IBaseView {
+		return fnVal.Call(nil)[0].Interface().(interfaces.IBaseView)
+	}
+}
+
+// Register generates List, Create, Retrieve, Update, PartialUpdate and Destroy routes
+// for the methods the viewset has, plus the extra actions.
+func (r *Router) Register(prefix string, handlerFunc interface{}, options ...RegisterOption) *DefaultRouter[interfaces.IBaseView] {
+	prefix = "/" + strings.Trim(prefix, "/")
+	group := r.RouteGroup.Group(prefix)
+	basename := toBasename(prefix)
+	if r.Namespace != "" {
+		basename = r.Namespace + ":" + basename
+	}
+	router := &DefaultRouter[interfaces.IBaseView]{
+		RouteGroup: group,
+		HandlerFunc: ToHandlerFunc(handlerFunc),
+		TrailingSlash: r.TrailingSlash,
+		Basename: basename,
+		Container: r.Container,
+	}
+	if err := router.GetContainer().Check(router.HandlerFunc()); err != nil {
+		panic(err.Error())
+	}
+	router.AutoDiscover()
+	newRegistration(options).registerActions(router)
+	r.Registry[r.Prefix + prefix] = router
+	return router
+}
+
+// toBasename converts prefix to route basename, example: "/user-groups" => "user-groups".
+func toBasename(prefix string) string {
+	return strings.ReplaceAll(strings.Trim(prefix, "/"), "/", "-")
+}
@@ -0,0 +1,53 @@
+package routers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/rimba47prayoga/gorim.git"
+)
+
+// TrailingSlashConfig registers every route with and without trailing slash,
+// so clients migrating from Django-style URLs don't get 404.
+type TrailingSlashConfig struct {
+	// Append makes "/users/" canonical, otherwise "/users".
+	Append			bool
+	// RedirectCode redirects the alternate path to the canonical one, example: http.StatusPermanentRedirect.
+	// Zero serves both paths.
+	RedirectCode	int
+}
+
+// AppendSlash redirects "/users" to "/users/".
+func AppendSlash(redirectCode int) *TrailingSlashConfig {
+	return &TrailingSlashConfig{Append: true, RedirectCode: redirectCode}
+}
+
+// StripSlash redirects "/users/" to "/users".
+func StripSlash(redirectCode int) *TrailingSlashConfig {
+	return &TrailingSlashConfig{RedirectCode: redirectCode}
+}
+
+// Paths returns canonical and alternate path relative to the group.
+func (t *TrailingSlashConfig) Paths(path string) (string, string) {
+	withoutSlash := strings.TrimSuffix(path, "/")
+	withSlash := withoutSlash + "/"
+	if t.Append {
+		return withSlash, withoutSlash
+	}
+	return withoutSlash, withSlash
+}
+
+// Redirect responds redirect to the canonical path, keeping the query string.
+func (t *TrailingSlashConfig) Redirect(c gorim.Context) error {
+	url := *c.Request().URL
+	if t.Append {
+		url.Path = url.Path + "/"
+	} else {
+		url.Path = strings.TrimSuffix(url.Path, "/")
+	}
+	code := t.RedirectCode
+	if code == 0 {
+		code = http.StatusPermanentRedirect
+	}
+	return c.Redirect(code, url.RequestURI())
+}
@@ -0,0 +1,179 @@
+package scaffold
+
+import (
+	"bytes"
+	"crypto/rand"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"go/format"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+//go:embed all:templates
+var templates embed.FS
+
+// renamed maps templates to the files they create, names starting with dot can't be embedded.
+var renamed = map[string]string{
+	"env": ".env",
+	"gitignore": ".gitignore",
+}
+
+var identifier = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+var moduleLine = regexp.MustCompile(`(?m)^module\s+(\S+)`)
+
+// ProjectData is passed to templates of startproject.
+type ProjectData struct {
+	Name		string
+	Module		string
+	SecretKey	string
+}
+
+// AppData is passed to templates of startapp, ImportPath is import path of the app.
+type AppData struct {
+	ImportPath	string
+	Package		string
+	Model		string
+	Route		string
+}
+
+// Project creates project layout in dir/name, module defaults to name.
+func Project(dir string, name string, module string) (string, error) {
+	if !identifier.MatchString(name) {
+		return "", fmt.Errorf("%q is not a valid project name, use lowercase letters, digits and underscores", name)
+	}
+	if module == "" {
+		module = name
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	target := filepath.Join(dir, name)
+	data := ProjectData{Name: name, Module: module, SecretKey: hex.EncodeToString(secret)}
+	return target, render("templates/project", target, data)
+}
+
+// App creates app package name in dir, dir must be inside the project so the app
+// is imported by module of its go.mod.
+func App(dir string, name string) (*AppData, error) {
+	if !identifier.MatchString(name) {
+		return nil, fmt.Errorf("%q is not a valid app name, use lowercase letters, digits and underscores", name)
+	}
+	target, err := filepath.Abs(filepath.Join(dir, name))
+	if err != nil {
+		return nil, err
+	}
+	root, module, err := findModule(filepath.Dir(target))
+	if err != nil {
+		return nil, err
+	}
+	relative, err := filepath.Rel(root, target)
+	if err != nil {
+		return nil, err
+	}
+	data := &AppData{
+		ImportPath: module + "/" + filepath.ToSlash(relative),
+		Package: name,
+		Model: ModelName(name),
+		Route: strings.ReplaceAll(name, "_", "-"),
+	}
+	return data, render("templates/app", target, data)
+}
+
+// ModelName returns model of app name, example: ModelName("blog_posts") returns "BlogPost".
+func ModelName(name string) string {
+	if !strings.HasSuffix(name, "ss") {
+		name = strings.TrimSuffix(name, "s")
+	}
+	result := []rune{}
+	upper := true
+	for _, char := range name {
+		if char == '_' {
+			upper = true
+			continue
+		}
+		if upper {
+			char = unicode.ToUpper(char)
+			upper = false
+		}
+		result = append(result, char)
+	}
+	return string(result)
+}
+
+// render executes templates of root into target, it fails without writing anything
+// when target already exists.
+func render(root string, target string, data interface{}) error {
+	if _, err := os.Stat(target); err == nil {
+		return fmt.Errorf("%s already exists", target)
+	}
+	files := map[string][]byte{}
+	err := fs.WalkDir(templates, root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		relative := strings.TrimSuffix(strings.TrimPrefix(path, root + "/"), ".tmpl")
+		if name, ok := renamed[filepath.Base(relative)]; ok {
+			relative = filepath.Join(filepath.Dir(relative), name)
+		}
+		content, err := execute(path, data)
+		if err != nil {
+			return err
+		}
+		files[relative] = content
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for relative, content := range files {
+		path := filepath.Join(target, relative)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func execute(path string, data interface{}) ([]byte, error) {
+	tmpl, err := template.ParseFS(templates, path)
+	if err != nil {
+		return nil, err
+	}
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, data); err != nil {
+		return nil, err
+	}
+	if strings.HasSuffix(path, ".go.tmpl") {
+		return format.Source(buffer.Bytes())
+	}
+	return buffer.Bytes(), nil
+}
+
+// findModule returns directory and module of the nearest go.mod of dir.
+func findModule(dir string) (string, string, error) {
+	for {
+		data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+		if err == nil {
+			if match := moduleLine.FindSubmatch(data); match != nil {
+				return dir, strings.Trim(string(match[1]), `"`), nil
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", "", fmt.Errorf("go.mod not found, run startapp inside the project")
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,138 @@
+package schema
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Override customizes generated operation of an action, zero fields keep what's generated.
+type Override struct {
+	Summary				string
+	Description			string
+	Deprecated			bool
+	Tags				[]string
+	OperationID			string
+	// RequestExample and ResponseExample are shown as json examples of the body.
+	RequestExample		any
+	ResponseExample		any
+	// Responses are added to generated responses, replacing those with the same status.
+	Responses			map[int]ExtraResponse
+}
+
+// ExtraResponse documents a response the generator can't find out, example:
+//
+//	Responses: map[int]schema.ExtraResponse{
+//		http.StatusConflict: {Description: "Email already registered.", Body: schema.RefTo("Error")},
+//		http.StatusAccepted: {Description: "Import queued.", Body: ImportJob{}},
+//	}
+type ExtraResponse struct {
+	Description		string
+	// Body is a value of the response type or *Schema, nil means the response has no content.
+	Body			any
+	Example			any
+}
+
+// IAnnotatedView is implemented by viewsets customizing their schema,
+// return nil for actions that keep the generated operation.
+type IAnnotatedView interface {
+	SchemaOverride(action string) *Override
+}
+
+// applyOverride applies per action override to the operation.
+func applyOverride(registry *registry, operation *Operation, override *Override) {
+	if override == nil {
+		return
+	}
+	if override.Summary != "" {
+		operation.Summary = override.Summary
+	}
+	if override.Description != "" {
+		operation.Description = override.Description
+	}
+	if override.Deprecated {
+		operation.Deprecated = true
+	}
+	if len(override.Tags) > 0 {
+		operation.Tags = override.Tags
+	}
+	if override.OperationID != "" {
+		operation.OperationID = override.OperationID
+	}
+	if override.RequestExample != nil && operation.RequestBody != nil {
+		for _, media := range operation.RequestBody.Content {
+			media.Example = override.RequestExample
+		}
+	}
+	if override.ResponseExample != nil {
+		if response := successResponse(operation); response != nil {
+			for _, media := range response.Content {
+				media.Example = override.ResponseExample
+			}
+		}
+	}
+	for status, extra := range override.Responses {
+		response := &Response{Description: extra.Description}
+		if extra.Body != nil {
+			body, ok := extra.Body.(*Schema)
+			if !ok {
+				body = registry.SchemaOf(reflect.TypeOf(extra.Body))
+			}
+			response.Content = map[string]*MediaType{
+				echo.MIMEApplicationJSON: {Schema: body, Example: extra.Example},
+			}
+		}
+		operation.Responses[strconv.Itoa(status)] = response
+	}
+}
+
+// successResponse returns 2xx response with the lowest status.
+func successResponse(operation *Operation) *Response {
+	statuses := []string{}
+	for status := range operation.Responses {
+		if strings.HasPrefix(status, "2") {
+			statuses = append(statuses, status)
+		}
+	}
+	if len(statuses) == 0 {
+		return nil
+	}
+	sort.Strings(statuses)
+	return operation.Responses[statuses[0]]
+}
+
+// applyFieldTags applies description, example and deprecated struct tags to the field schema,
+// example: `json:"email" description:"Login email." example:"jane@example.com"`.
+func applyFieldTags(schema *Schema, field reflect.StructField) {
+	if schema.Ref != "" {
+		return
+	}
+	if description := field.Tag.Get("description"); description != "" {
+		schema.Description = description
+	}
+	if example, ok := field.Tag.Lookup("example"); ok {
+		schema.Example = exampleValue(schema.Type, example)
+	}
+	if deprecated, _ := strconv.ParseBool(field.Tag.Get("deprecated")); deprecated {
+		schema.Deprecated = true
+	}
+}
+
+func exampleValue(typ string, value string) any {
+	switch typ {
+	case "boolean":
+		if boolean, err := strconv.ParseBool(value); err == nil {
+			return boolean
+		}
+	case "array":
+		items := []any{}
+		for _, item := range strings.Split(value, ",") {
+			items = append(items, strings.TrimSpace(item))
+		}
+		return items
+	}
+	return enumValue(typ, value)
+}
@@ -0,0 +1,127 @@
+package schema
+
+import (
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/interfaces"
+	"github.com/rimba47prayoga/gorim.git/permissions"
+)
+
+const (
+	DefaultSwaggerUIURL		= "https://cdn.jsdelivr.net/npm/swagger-ui-dist@5"
+	DefaultReDocURL			= "https://cdn.jsdelivr.net/npm/redoc@2/bundles/redoc.standalone.js"
+)
+
+// DocsConfig configures Swagger UI and ReDoc pages.
+type DocsConfig struct {
+	Title			string
+	// SchemaURL is where the pages load the document from, default "openapi.json" relative to the page.
+	SchemaURL		string
+	// Permissions must all allow the request, including the schema view mounted by Mount.
+	Permissions		[]interfaces.IPermission
+	// SwaggerUIURL is base url of swagger-ui-dist assets, set it to serve them from static files.
+	SwaggerUIURL	string
+	// ReDocURL is url of redoc standalone script.
+	ReDocURL		string
+}
+
+func (config DocsConfig) withDefaults() DocsConfig {
+	if config.Title == "" {
+		config.Title = "API"
+	}
+	if config.SchemaURL == "" {
+		config.SchemaURL = "openapi.json"
+	}
+	if config.SwaggerUIURL == "" {
+		config.SwaggerUIURL = DefaultSwaggerUIURL
+	}
+	config.SwaggerUIURL = strings.TrimSuffix(config.SwaggerUIURL, "/")
+	if config.ReDocURL == "" {
+		config.ReDocURL = DefaultReDocURL
+	}
+	return config
+}
+
+var swaggerUITemplate = template.Must(template.New("swagger-ui").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<link rel="stylesheet" href="{{.SwaggerUIURL}}/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="{{.SwaggerUIURL}}/swagger-ui-bundle.js"></script>
+<script>
+window.ui = SwaggerUIBundle({url: {{.SchemaURL}}, dom_id: "#swagger-ui", deepLinking: true});
+</script>
+</body>
+</html>
+`))
+
+var reDocTemplate = template.Must(template.New("redoc").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>body { margin: 0; padding: 0; }</style>
+</head>
+<body>
+<redoc spec-url="{{.SchemaURL}}"></redoc>
+<script src="{{.ReDocURL}}"></script>
+</body>
+</html>
+`))
+
+// SwaggerUI returns handler serving Swagger UI of the document at config.SchemaURL.
+func SwaggerUI(config DocsConfig) gorim.HandlerFunc {
+	return docsHandler(swaggerUITemplate, config.withDefaults())
+}
+
+// ReDoc returns handler serving ReDoc of the document at config.SchemaURL.
+func ReDoc(config DocsConfig) gorim.HandlerFunc {
+	return docsHandler(reDocTemplate, config.withDefaults())
+}
+
+func docsHandler(page *template.Template, config DocsConfig) gorim.HandlerFunc {
+	return Protect(config.Permissions, func(c gorim.Context) error {
+		var builder strings.Builder
+		if err := page.Execute(&builder, config); err != nil {
+			return err
+		}
+		return c.HTML(http.StatusOK, builder.String())
+	})
+}
+
+// Protect returns handler that calls next only when all permissions allow the request.
+func Protect(permissionClasses []interfaces.IPermission, next gorim.HandlerFunc) gorim.HandlerFunc {
+	return func(c gorim.Context) error {
+		for _, permission := range permissionClasses {
+			if !permission.HasPermission(c) {
+				return permissions.Deny(c, permission)
+			}
+		}
+		return next(c)
+	}
+}
+
+// Mount registers the schema at "/openapi.json", Swagger UI at "/docs" and ReDoc at "/redoc"
+// of the group, example:
+//
+//	generator := &schema.Generator{Info: schema.Info{Title: "Shop"}}
+//	generator.Mount(server.Group("/api"), schema.DocsConfig{
+//		Permissions: []interfaces.IPermission{&permissions.IsAdminUser{}},
+//	})
+func (g *Generator) Mount(group *gorim.Group, config DocsConfig) {
+	if config.Title == "" {
+		config.Title = g.Info.Title
+	}
+	group.Add(http.MethodGet, "/openapi.json", Protect(config.Permissions, g.View))
+	group.Add(http.MethodGet, "/docs", SwaggerUI(config))
+	group.Add(http.MethodGet, "/redoc", ReDoc(config))
+}
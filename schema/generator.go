@@ -0,0 +1,387 @@
+package schema
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/apps"
+	"github.com/rimba47prayoga/gorim.git/audit"
+	"github.com/rimba47prayoga/gorim.git/deprecation"
+	"github.com/rimba47prayoga/gorim.git/renderers"
+	"github.com/rimba47prayoga/gorim.git/routers"
+	"github.com/rimba47prayoga/gorim.git/serializers"
+	"github.com/rimba47prayoga/gorim.git/views/mixins"
+)
+
+// Generator builds OpenAPI document from the routes registered by routers.
+type Generator struct {
+	Info		Info
+	Servers		[]Server
+	// Prefix limits the document to routes starting with it.
+	Prefix		string
+	// Apps limits the document to routes mounted by these apps.
+	Apps		[]string
+	once		sync.Once
+	document	*Document
+	// patterns caches compiled Pattern of schemas of document by ValidateRequests.
+	patterns	sync.Map
+}
+
+// viewInfo is what the generator could find out about the viewset of a route.
+type viewInfo struct {
+	model		reflect.Type
+	serializer	reflect.Type
+	filter		reflect.Type
+	override	*Override
+}
+
+var pathParam = regexp.MustCompile(`:([^/]+)`)
+
+// Generate returns OpenAPI document of the registered viewsets.
+func (g *Generator) Generate() *Document {
+	info := g.Info
+	if info.Title == "" {
+		info.Title = "API"
+	}
+	if info.Version == "" {
+		info.Version = "1.0.0"
+	}
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info: info,
+		Servers: g.Servers,
+		Paths: map[string]PathItem{},
+		Components: Components{Schemas: map[string]*Schema{}},
+	}
+	registry := newRegistry(doc.Components.Schemas)
+	for _, route := range routers.Routes() {
+		if !strings.HasPrefix(route.Path, g.Prefix) {
+			continue
+		}
+		if len(g.Apps) > 0 && !slices.Contains(g.Apps, apps.AppOf(route)) {
+			continue
+		}
+		path := pathParam.ReplaceAllString(route.Path, "{$1}")
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = PathItem{}
+			doc.Paths[path] = item
+		}
+		item[strings.ToLower(route.Method)] = g.operation(registry, route)
+	}
+	return doc
+}
+
+func (g *Generator) operation(registry *registry, route routers.RouteInfo) *Operation {
+	view := introspect(route)
+	tag := strings.TrimSuffix(route.ViewSet, "ViewSet")
+	operation := &Operation{
+		OperationID: route.Name,
+		Summary: route.Action,
+		Responses: map[string]*Response{},
+	}
+	if tag != "" {
+		operation.Tags = []string{tag}
+	}
+	if operation.OperationID == "" && tag != "" {
+		operation.OperationID = strings.ToLower(tag[:1]) + tag[1:] + route.Action
+	}
+	if operation.OperationID == "" {
+		operation.OperationID = strings.ToLower(route.Method) + pathParam.ReplaceAllString(strings.ReplaceAll(route.Path, "/", "_"), "$1")
+	}
+	for _, match := range pathParam.FindAllStringSubmatch(route.Path, -1) {
+		operation.Parameters = append(operation.Parameters, &Parameter{
+			Name: match[1],
+			In: "path",
+			Required: true,
+			Schema: &Schema{Type: "string"},
+		})
+	}
+
+	var model *Schema
+	if view.model != nil {
+		model = registry.SchemaOf(view.model)
+	}
+	hasBody := route.Method == http.MethodPost || route.Method == http.MethodPut || route.Method == http.MethodPatch
+	if route.Action == "Restore" {
+		hasBody = false
+	}
+	switch route.Action {
+	case "List", "ListDeleted":
+		operation.Parameters = append(operation.Parameters, filterParameters(registry, view.filter)...)
+		operation.Parameters = append(operation.Parameters, paginationParameters()...)
+		operation.Responses["200"] = jsonResponse("OK", paginated(registry, view.model, model))
+	case "Export":
+		operation.Parameters = append(operation.Parameters, filterParameters(registry, view.filter)...)
+		operation.Responses["200"] = &Response{
+			Description: "Newline delimited JSON of every object.",
+			Content: map[string]*MediaType{renderers.MIMEApplicationNDJSON: {Schema: model}},
+		}
+	case "Import":
+		hasBody = false
+		operation.RequestBody = &RequestBody{
+			Required: true,
+			Content: map[string]*MediaType{echo.MIMEMultipartForm: {Schema: &Schema{
+				Type: "object",
+				Properties: map[string]*Schema{"file": {Type: "string", Format: "binary", Description: "CSV or XLSX file, the header row names the fields."}},
+				Required: []string{"file"},
+			}}},
+		}
+		result := reflect.TypeOf(mixins.ImportResult{})
+		operation.Responses["200"] = jsonResponse("Number of imported rows and errors of invalid rows.", registry.SchemaOf(result))
+		operation.Responses["400"] = jsonResponse("Bad Request", validationComponent(registry))
+	case "Aggregate":
+		operation.Parameters = append(operation.Parameters, filterParameters(registry, view.filter)...)
+		operation.Responses["200"] = jsonResponse("Aggregated rows, an array when grouped.", &Schema{})
+	case "History":
+		entry := reflect.TypeOf(audit.LogEntry{})
+		operation.Parameters = append(operation.Parameters, paginationParameters()...)
+		operation.Responses["200"] = jsonResponse("OK", paginated(registry, entry, registry.SchemaOf(entry)))
+	case "Create":
+		operation.Responses["201"] = jsonResponse("Created", model)
+	case "Retrieve", "Update", "PartialUpdate", "Restore":
+		operation.Responses["200"] = jsonResponse("OK", model)
+	case "Destroy", "Delete":
+		operation.Responses["204"] = &Response{Description: "No Content"}
+	default:
+		operation.Responses["200"] = &Response{Description: "OK"}
+	}
+	if hasBody && view.serializer != nil {
+		body := registry.SchemaOf(view.serializer)
+		registry.writeOnly(view.serializer, view.model)
+		if route.Action == "PartialUpdate" {
+			body = registry.patched(view.serializer)
+		}
+		operation.RequestBody = &RequestBody{
+			Required: true,
+			Content: map[string]*MediaType{echo.MIMEApplicationJSON: {Schema: body}},
+		}
+	}
+
+	errorSchema := errorComponent(registry)
+	if hasBody || route.Action == "List" || route.Action == "ListDeleted" {
+		operation.Responses["400"] = jsonResponse("Bad Request", validationComponent(registry))
+	}
+	if requiresAuth(route.Permissions) {
+		operation.Responses["401"] = jsonResponse("Unauthorized", errorSchema)
+		operation.Responses["403"] = jsonResponse("Forbidden", errorSchema)
+	}
+	if (route.Action == "Update" || route.Action == "PartialUpdate") && view.model != nil &&
+		serializers.IsLockedModel(reflect.New(view.model).Interface()) {
+		operation.Responses["409"] = jsonResponse("Conflict", errorSchema)
+	}
+	if strings.Contains(route.Path, ":") {
+		operation.Responses["404"] = jsonResponse("Not Found", errorSchema)
+	}
+	if deprecation.Of(route) != nil {
+		operation.Deprecated = true
+	}
+	applyOverride(registry, operation, view.override)
+	return operation
+}
+
+// introspect creates the viewset of the route with a blank request, then looks up
+// model, serializer and filter types. Failures only leave the types empty.
+func introspect(route routers.RouteInfo) (view viewInfo) {
+	if route.HandlerFunc == nil {
+		return view
+	}
+	defer func() {
+		recover()
+	}()
+	handler := route.HandlerFunc()
+	request, err := http.NewRequest(route.Method, route.Path, nil)
+	if err != nil {
+		return view
+	}
+	echoContext := echo.New().NewContext(request, nil)
+	echoContext.Set(gorim.ActionContextKey, route.Action)
+	handler.SetAction(route.Action)
+	handler.SetContext(gorim.NewContext(echoContext))
+
+	if annotated, ok := handler.(IAnnotatedView); ok {
+		view.override = annotated.SchemaOverride(route.Action)
+	}
+	value := reflect.ValueOf(handler)
+	if filter := structField(value, "Filter"); filter.IsValid() && !filter.IsNil() {
+		view.filter = indirectType(filter.Elem().Type())
+	}
+	if method := value.MethodByName("GetModelSlice"); isGetter(method) {
+		if slice, ok := method.Call(nil)[0].Interface().(reflect.Value); ok && slice.IsValid() {
+			view.model = indirectType(indirectType(slice.Type()).Elem())
+		}
+	}
+	if method := value.MethodByName("GetSerializerStruct"); isGetter(method) {
+		if serializer := method.Call(nil)[0]; !serializer.IsNil() {
+			view.serializer = indirectType(serializer.Elem().Type())
+		}
+	}
+	return view
+}
+
+// ViewTypes returns model, serializer and filter types of the viewset of route, nil
+// when they can't be found, used by generators of other schemas.
+func ViewTypes(route routers.RouteInfo) (model reflect.Type, serializer reflect.Type, filter reflect.Type) {
+	view := introspect(route)
+	return view.model, view.serializer, view.filter
+}
+
+func structField(value reflect.Value, name string) reflect.Value {
+	value = reflect.Indirect(value)
+	if value.Kind() != reflect.Struct {
+		return reflect.Value{}
+	}
+	return value.FieldByName(name)
+}
+
+func isGetter(method reflect.Value) bool {
+	return method.IsValid() && method.Type().NumIn() == 0 && method.Type().NumOut() == 1
+}
+
+func indirectType(typ reflect.Type) reflect.Type {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ
+}
+
+// requiresAuth reports whether any permission other than AllowAny applies.
+func requiresAuth(permissions []string) bool {
+	for _, name := range permissions {
+		if name != "AllowAny" {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonResponse(description string, schema *Schema) *Response {
+	response := &Response{Description: description}
+	if schema != nil {
+		response.Content = map[string]*MediaType{echo.MIMEApplicationJSON: {Schema: schema}}
+	}
+	return response
+}
+
+// filterParameters returns query parameters of filterset fields, named like echo binds them.
+func filterParameters(registry *registry, filter reflect.Type) []*Parameter {
+	if filter == nil || filter.Kind() != reflect.Struct {
+		return nil
+	}
+	parameters := []*Parameter{}
+	for i := 0; i < filter.NumField(); i++ {
+		field := filter.Field(i)
+		if field.Type.Name() == "FilterSet" || !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("query"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema := registry.SchemaOf(field.Type)
+		description := ""
+		if operator := field.Tag.Get("operator"); operator != "" {
+			description = "Filter " + field.Tag.Get("db") + " by " + operator + "."
+		}
+		parameters = append(parameters, &Parameter{
+			Name: name,
+			In: "query",
+			Description: description,
+			Schema: schema,
+		})
+	}
+	return parameters
+}
+
+func paginationParameters() []*Parameter {
+	minimum := 1.0
+	return []*Parameter{
+		{Name: "page", In: "query", Description: "Page number.", Schema: &Schema{Type: "integer", Minimum: &minimum}},
+		{Name: "page_size", In: "query", Description: "Number of results per page.", Schema: &Schema{Type: "integer", Minimum: &minimum}},
+		{Name: "sort", In: "query", Description: "Comma separated fields, prefix with - for descending order.", Schema: &Schema{Type: "string"}},
+	}
+}
+
+// paginated returns schema of pagination.Pagination with results of the model.
+func paginated(registry *registry, modelType reflect.Type, model *Schema) *Schema {
+	results := &Schema{Type: "array", Items: model}
+	if model == nil {
+		results.Items = &Schema{}
+	}
+	name := "Paginated"
+	if modelType != nil {
+		name += registry.component(modelType)
+	}
+	if _, ok := registry.components[name]; !ok {
+		registry.components[name] = &Schema{
+			Type: "object",
+			Properties: map[string]*Schema{
+				"page": {Type: "integer"},
+				"page_size": {Type: "integer"},
+				"sort": {Type: "string"},
+				"total_rows": {Type: "integer", Format: "int64"},
+				"total_pages": {Type: "integer"},
+				"results": results,
+			},
+		}
+	}
+	return RefTo(name)
+}
+
+// writeOnly marks serializer fields absent from the model as write only.
+func (r *registry) writeOnly(serializer reflect.Type, model reflect.Type) {
+	if model == nil || model.Kind() != reflect.Struct {
+		return
+	}
+	serializerSchema := r.components[r.component(serializer)]
+	modelSchema := r.components[r.component(model)]
+	for name, property := range serializerSchema.Properties {
+		if _, ok := modelSchema.Properties[name]; !ok && property.Ref == "" {
+			property.WriteOnly = true
+		}
+	}
+}
+
+// patched returns serializer component without required fields, used by partial update.
+func (r *registry) patched(typ reflect.Type) *Schema {
+	name := "Patched" + r.component(typ)
+	if _, ok := r.components[name]; !ok {
+		schema := *r.components[r.names[typ]]
+		schema.Required = nil
+		r.components[name] = &schema
+	}
+	return RefTo(name)
+}
+
+func errorComponent(registry *registry) *Schema {
+	if _, ok := registry.components["Error"]; !ok {
+		registry.components["Error"] = &Schema{
+			Type: "object",
+			Properties: map[string]*Schema{
+				"error": {Type: "string"},
+				"code": {Type: "string"},
+			},
+		}
+	}
+	return RefTo("Error")
+}
+
+func validationComponent(registry *registry) *Schema {
+	if _, ok := registry.components["ValidationError"]; !ok {
+		registry.components["ValidationError"] = &Schema{
+			Type: "object",
+			Description: "Messages keyed by field, non_field_errors holds messages of the whole input.",
+			AdditionalProperties: &Schema{Type: "array", Items: &Schema{Type: "string"}},
+		}
+	}
+	return RefTo("ValidationError")
+}
@@ -0,0 +1,95 @@
+package schema
+
+// Document is OpenAPI 3 document, only the parts generated by Generator are modeled.
+type Document struct {
+	OpenAPI			string					`json:"openapi"`
+	Info			Info					`json:"info"`
+	Servers			[]Server				`json:"servers,omitempty"`
+	Paths			map[string]PathItem		`json:"paths"`
+	Components		Components				`json:"components"`
+}
+
+type Info struct {
+	Title			string		`json:"title"`
+	Version			string		`json:"version"`
+	Description		string		`json:"description,omitempty"`
+}
+
+type Server struct {
+	URL				string		`json:"url"`
+	Description		string		`json:"description,omitempty"`
+}
+
+// PathItem maps lower case http method to operation.
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID		string					`json:"operationId,omitempty"`
+	Summary			string					`json:"summary,omitempty"`
+	Description		string					`json:"description,omitempty"`
+	Tags			[]string				`json:"tags,omitempty"`
+	Deprecated		bool					`json:"deprecated,omitempty"`
+	Parameters		[]*Parameter			`json:"parameters,omitempty"`
+	RequestBody		*RequestBody			`json:"requestBody,omitempty"`
+	Responses		map[string]*Response	`json:"responses"`
+}
+
+type Parameter struct {
+	Name			string		`json:"name"`
+	// In is "path", "query" or "header".
+	In				string		`json:"in"`
+	Description		string		`json:"description,omitempty"`
+	Required		bool		`json:"required,omitempty"`
+	Schema			*Schema		`json:"schema,omitempty"`
+}
+
+type RequestBody struct {
+	Description		string					`json:"description,omitempty"`
+	Required		bool					`json:"required,omitempty"`
+	Content			map[string]*MediaType	`json:"content"`
+}
+
+type MediaType struct {
+	Schema			*Schema		`json:"schema,omitempty"`
+	Example			any			`json:"example,omitempty"`
+}
+
+type Response struct {
+	Description		string					`json:"description"`
+	Content			map[string]*MediaType	`json:"content,omitempty"`
+}
+
+type Components struct {
+	Schemas			map[string]*Schema		`json:"schemas,omitempty"`
+}
+
+type Schema struct {
+	Ref						string				`json:"$ref,omitempty"`
+	Type					string				`json:"type,omitempty"`
+	Format					string				`json:"format,omitempty"`
+	Description				string				`json:"description,omitempty"`
+	Nullable				bool				`json:"nullable,omitempty"`
+	ReadOnly				bool				`json:"readOnly,omitempty"`
+	WriteOnly				bool				`json:"writeOnly,omitempty"`
+	Deprecated				bool				`json:"deprecated,omitempty"`
+	Properties				map[string]*Schema	`json:"properties,omitempty"`
+	Required				[]string			`json:"required,omitempty"`
+	Items					*Schema				`json:"items,omitempty"`
+	AdditionalProperties	*Schema				`json:"additionalProperties,omitempty"`
+	Enum					[]any				`json:"enum,omitempty"`
+	MinLength				*int64				`json:"minLength,omitempty"`
+	MaxLength				*int64				`json:"maxLength,omitempty"`
+	MinItems				*int64				`json:"minItems,omitempty"`
+	MaxItems				*int64				`json:"maxItems,omitempty"`
+	Minimum					*float64			`json:"minimum,omitempty"`
+	Maximum					*float64			`json:"maximum,omitempty"`
+	ExclusiveMinimum		bool				`json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum		bool				`json:"exclusiveMaximum,omitempty"`
+	Pattern					string				`json:"pattern,omitempty"`
+	Example					any					`json:"example,omitempty"`
+}
+
+// RefTo returns schema referencing component.
+func RefTo(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
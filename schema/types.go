@@ -0,0 +1,270 @@
+package schema
+
+import (
+	"encoding"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rimba47prayoga/gorim.git/parsers"
+	"gorm.io/gorm"
+)
+
+var (
+	timeType			= reflect.TypeOf(time.Time{})
+	deletedAtType		= reflect.TypeOf(gorm.DeletedAt{})
+	uploadedFileType	= reflect.TypeOf(parsers.UploadedFile{})
+	textMarshalerType	= reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// registry converts go types to schemas, named structs are added to components and referenced.
+type registry struct {
+	components	map[string]*Schema
+	names		map[reflect.Type]string
+}
+
+func newRegistry(components map[string]*Schema) *registry {
+	return &registry{components: components, names: map[reflect.Type]string{}}
+}
+
+// SchemaOf returns schema of go type, fields are named by json tag and
+// constrained by validate tag, example: `json:"email" validate:"required,email"`.
+func (r *registry) SchemaOf(typ reflect.Type) *Schema {
+	nullable := false
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+		nullable = true
+	}
+	schema := r.baseSchema(typ)
+	if nullable && schema.Ref == "" {
+		schema.Nullable = true
+	}
+	return schema
+}
+
+func (r *registry) baseSchema(typ reflect.Type) *Schema {
+	switch typ {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case deletedAtType:
+		return &Schema{Type: "string", Format: "date-time", Nullable: true}
+	case uploadedFileType:
+		return &Schema{Type: "string", Format: "binary"}
+	}
+	if typ.Kind() == reflect.Struct && reflect.PointerTo(typ).Implements(textMarshalerType) {
+		return &Schema{Type: "string"}
+	}
+	switch typ.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		minimum := 0.0
+		return &Schema{Type: "integer", Minimum: &minimum}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: r.SchemaOf(typ.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: r.SchemaOf(typ.Elem())}
+	case reflect.Struct:
+		if typ.Name() == "" {
+			return r.objectSchema(typ)
+		}
+		return RefTo(r.component(typ))
+	}
+	// interface and other kinds accept any value.
+	return &Schema{}
+}
+
+// component adds named struct to components, the name is reserved before
+// the fields are converted so recursive types terminate.
+func (r *registry) component(typ reflect.Type) string {
+	if name, ok := r.names[typ]; ok {
+		return name
+	}
+	name := componentName(typ)
+	if _, taken := r.components[name]; taken {
+		parts := strings.Split(typ.PkgPath(), "/")
+		name = exportedName(parts[len(parts) - 1]) + name
+	}
+	r.names[typ] = name
+	schema := &Schema{}
+	r.components[name] = schema
+	*schema = *r.objectSchema(typ)
+	return name
+}
+
+func (r *registry) objectSchema(typ reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	r.addFields(schema, typ)
+	return schema
+}
+
+// addFields adds properties of struct fields, embedded structs without json name are flattened.
+func (r *registry) addFields(schema *Schema, typ reflect.Type) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				r.addFields(schema, embedded)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		property := r.SchemaOf(field.Type)
+		if property.Ref == "" && isReadOnlyField(field) {
+			property.ReadOnly = true
+		}
+		applyFieldTags(property, field)
+		if applyValidate(property, field.Tag.Get("validate")) && !contains(schema.Required, name) {
+			schema.Required = append(schema.Required, name)
+		}
+		schema.Properties[name] = property
+	}
+}
+
+// isReadOnlyField reports fields managed by gorm, primary key and timestamps.
+func isReadOnlyField(field reflect.StructField) bool {
+	gormTag := strings.ToLower(field.Tag.Get("gorm"))
+	if strings.Contains(gormTag, "primarykey") || strings.Contains(gormTag, "autocreatetime") || strings.Contains(gormTag, "autoupdatetime") {
+		return true
+	}
+	switch field.Name {
+	case "ID", "CreatedAt", "UpdatedAt", "DeletedAt":
+		return true
+	}
+	return false
+}
+
+// applyValidate maps validator rules to schema constraints, returns true when the field is required.
+func applyValidate(schema *Schema, tag string) bool {
+	required := false
+	if tag == "" || schema.Ref != "" {
+		return strings.Contains(tag, "required")
+	}
+	for _, rule := range strings.Split(tag, ",") {
+		key, param, _ := strings.Cut(rule, "=")
+		switch key {
+		case "dive":
+			// following rules apply to items.
+			return required
+		case "required":
+			required = true
+		case "email":
+			schema.Format = "email"
+		case "url", "uri":
+			schema.Format = "uri"
+		case "uuid", "uuid4":
+			schema.Format = "uuid"
+		case "alphanum":
+			schema.Pattern = "^[a-zA-Z0-9]*$"
+		case "numeric":
+			schema.Pattern = "^[-+]?[0-9]*\\.?[0-9]+$"
+		case "oneof":
+			for _, value := range strings.Fields(param) {
+				schema.Enum = append(schema.Enum, enumValue(schema.Type, value))
+			}
+		case "len", "min", "max", "gte", "lte", "gt", "lt":
+			applyBound(schema, key, param)
+		}
+	}
+	return required
+}
+
+func applyBound(schema *Schema, key string, param string) {
+	value, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+	count := int64(value)
+	lower := key == "min" || key == "gte" || key == "gt" || key == "len"
+	upper := key == "max" || key == "lte" || key == "lt" || key == "len"
+	switch schema.Type {
+	case "string":
+		if lower {
+			schema.MinLength = &count
+		}
+		if upper {
+			schema.MaxLength = &count
+		}
+	case "array":
+		if lower {
+			schema.MinItems = &count
+		}
+		if upper {
+			schema.MaxItems = &count
+		}
+	case "integer", "number":
+		if lower {
+			schema.Minimum = &value
+			schema.ExclusiveMinimum = key == "gt"
+		}
+		if upper {
+			schema.Maximum = &value
+			schema.ExclusiveMaximum = key == "lt"
+		}
+	}
+}
+
+func enumValue(typ string, value string) any {
+	switch typ {
+	case "integer":
+		if number, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return number
+		}
+	case "number":
+		if number, err := strconv.ParseFloat(value, 64); err == nil {
+			return number
+		}
+	}
+	return value
+}
+
+var genericArgs = regexp.MustCompile(`\[.*\]$`)
+
+// componentName returns type name without type arguments, example: "Paginated[...User]" => "Paginated".
+func componentName(typ reflect.Type) string {
+	return genericArgs.ReplaceAllString(typ.Name(), "")
+}
+
+func exportedName(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+func contains(values []string, value string) bool {
+	for _, item := range values {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
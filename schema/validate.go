@@ -0,0 +1,346 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git/errors"
+	"github.com/rimba47prayoga/gorim.git/i18n"
+	"github.com/rimba47prayoga/gorim.git/parsers"
+)
+
+// ValidateRequests returns middleware validating path and query parameters and json body
+// against the document before the handler runs, invalid requests are responded 400
+// with messages keyed by field path. Routes missing from the document are not validated,
+// example: server.Use(generator.ValidateRequests())
+func (g *Generator) ValidateRequests() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			doc := g.Document()
+			item, ok := doc.Paths[pathParam.ReplaceAllString(c.Path(), "{$1}")]
+			if !ok {
+				return next(c)
+			}
+			operation, ok := item[strings.ToLower(c.Request().Method)]
+			if !ok {
+				return next(c)
+			}
+			v := &requestValidator{
+				components: doc.Components.Schemas,
+				patterns: &g.patterns,
+				language: i18n.Language(c),
+				errors: errors.ValidationErrors{},
+			}
+			v.parameters(operation, c)
+			if err := v.body(operation, c); err != nil {
+				return errors.Handle(err, c)
+			}
+			if len(v.errors) > 0 {
+				return errors.Handle(v.errors, c)
+			}
+			return next(c)
+		}
+	}
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+type requestValidator struct {
+	components	map[string]*Schema
+	patterns	*sync.Map
+	language	string
+	errors		errors.ValidationErrors
+}
+
+// pattern returns Pattern of schema compiled once, nil when it isn't valid.
+func (v *requestValidator) pattern(schema *Schema) *regexp.Regexp {
+	if cached, ok := v.patterns.Load(schema); ok {
+		return cached.(*regexp.Regexp)
+	}
+	pattern, err := regexp.Compile(schema.Pattern)
+	if err != nil {
+		pattern = nil
+	}
+	v.patterns.Store(schema, pattern)
+	return pattern
+}
+
+func (v *requestValidator) add(path string, message string, args ...any) {
+	v.errors.Add(path, i18n.Translate(v.language, message, args...))
+}
+
+func (v *requestValidator) parameters(operation *Operation, c echo.Context) {
+	query := c.QueryParams()
+	for _, parameter := range operation.Parameters {
+		var values []string
+		switch parameter.In {
+		case "path":
+			values = []string{c.Param(parameter.Name)}
+		case "query":
+			values = query[parameter.Name]
+		default:
+			continue
+		}
+		if len(values) == 0 || values[0] == "" {
+			if parameter.Required {
+				v.add(parameter.Name, "This field is required.")
+			}
+			continue
+		}
+		schema := v.resolve(parameter.Schema)
+		if schema == nil {
+			continue
+		}
+		if schema.Type == "array" {
+			items := make([]any, len(values))
+			for i, value := range values {
+				items[i] = coerce(v.resolve(schema.Items), value)
+			}
+			v.validate(schema, items, parameter.Name)
+			continue
+		}
+		v.validate(schema, coerce(schema, values[0]), parameter.Name)
+	}
+}
+
+// body validates json body, other content types are left to the parsers.
+func (v *requestValidator) body(operation *Operation, c echo.Context) error {
+	if operation.RequestBody == nil {
+		return nil
+	}
+	media, ok := operation.RequestBody.Content[echo.MIMEApplicationJSON]
+	if !ok {
+		return nil
+	}
+	contentType := c.Request().Header.Get(echo.HeaderContentType)
+	if contentType != "" {
+		mediaType, _, _ := mime.ParseMediaType(contentType)
+		if mediaType != echo.MIMEApplicationJSON && !strings.HasSuffix(mediaType, "+json") {
+			return nil
+		}
+	}
+	raw, err := parsers.RawBody(c)
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(raw)) == 0 {
+		if operation.RequestBody.Required {
+			v.add("", "This field is required.")
+		}
+		return nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		// malformed json is reported by the binder.
+		return nil
+	}
+	v.validate(media.Schema, value, "")
+	return nil
+}
+
+func (v *requestValidator) resolve(schema *Schema) *Schema {
+	for depth := 0; schema != nil && schema.Ref != "" && depth < 32; depth++ {
+		schema = v.components[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+	}
+	return schema
+}
+
+func joinPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func (v *requestValidator) validate(schema *Schema, value any, path string) {
+	schema = v.resolve(schema)
+	if schema == nil {
+		return
+	}
+	if value == nil {
+		if !schema.Nullable && schema.Type != "" {
+			v.add(path, "This field may not be null.")
+		}
+		return
+	}
+	switch schema.Type {
+	case "object":
+		v.object(schema, value, path)
+	case "array":
+		v.array(schema, value, path)
+	case "string":
+		v.string(schema, value, path)
+	case "integer", "number":
+		v.number(schema, value, path)
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			v.add(path, "Must be a valid boolean.")
+		}
+	}
+	if len(schema.Enum) > 0 && !inEnum(schema.Enum, value) {
+		choices := make([]string, len(schema.Enum))
+		for i, choice := range schema.Enum {
+			choices[i] = fmt.Sprint(choice)
+		}
+		v.add(path, "Must be one of: %s.", strings.Join(choices, " "))
+	}
+}
+
+func (v *requestValidator) object(schema *Schema, value any, path string) {
+	object, ok := value.(map[string]any)
+	if !ok {
+		v.add(path, "Expected an object.")
+		return
+	}
+	for _, name := range schema.Required {
+		if _, ok := object[name]; !ok {
+			v.add(joinPath(path, name), "This field is required.")
+		}
+	}
+	for name, item := range object {
+		property := v.resolve(schema.Properties[name])
+		if property == nil {
+			property = schema.AdditionalProperties
+		}
+		// read only fields are ignored like serializers ignore them.
+		if property == nil || property.ReadOnly {
+			continue
+		}
+		v.validate(property, item, joinPath(path, name))
+	}
+}
+
+func (v *requestValidator) array(schema *Schema, value any, path string) {
+	items, ok := value.([]any)
+	if !ok {
+		v.add(path, "Expected a list of items.")
+		return
+	}
+	count := int64(len(items))
+	if schema.MinItems != nil && count < *schema.MinItems {
+		v.add(path, "Ensure this field has at least %s items.", strconv.FormatInt(*schema.MinItems, 10))
+	}
+	if schema.MaxItems != nil && count > *schema.MaxItems {
+		v.add(path, "Ensure this field has no more than %s items.", strconv.FormatInt(*schema.MaxItems, 10))
+	}
+	for i, item := range items {
+		v.validate(schema.Items, item, joinPath(path, strconv.Itoa(i)))
+	}
+}
+
+func (v *requestValidator) string(schema *Schema, value any, path string) {
+	text, ok := value.(string)
+	if !ok {
+		v.add(path, "Not a valid string.")
+		return
+	}
+	length := int64(utf8.RuneCountInString(text))
+	if schema.MinLength != nil && length < *schema.MinLength {
+		v.add(path, "Ensure this field has at least %s characters.", strconv.FormatInt(*schema.MinLength, 10))
+	}
+	if schema.MaxLength != nil && length > *schema.MaxLength {
+		v.add(path, "Ensure this field has no more than %s characters.", strconv.FormatInt(*schema.MaxLength, 10))
+	}
+	if schema.Pattern != "" {
+		if pattern := v.pattern(schema); pattern != nil && !pattern.MatchString(text) {
+			v.add(path, "This value does not match the required pattern.")
+		}
+	}
+	if text == "" {
+		return
+	}
+	switch schema.Format {
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, text); err != nil {
+			v.add(path, "Datetime has wrong format.")
+		}
+	case "email":
+		if _, err := mail.ParseAddress(text); err != nil {
+			v.add(path, "Enter a valid email address.")
+		}
+	case "uri":
+		if parsed, err := url.Parse(text); err != nil || parsed.Scheme == "" {
+			v.add(path, "Enter a valid URL.")
+		}
+	case "uuid":
+		if !uuidPattern.MatchString(text) {
+			v.add(path, "Must be a valid UUID.")
+		}
+	}
+}
+
+func (v *requestValidator) number(schema *Schema, value any, path string) {
+	message := "A valid number is required."
+	if schema.Type == "integer" {
+		message = "A valid integer is required."
+	}
+	number, ok := value.(json.Number)
+	if !ok {
+		v.add(path, message)
+		return
+	}
+	float, err := number.Float64()
+	if err == nil && schema.Type == "integer" {
+		_, err = number.Int64()
+	}
+	if err != nil {
+		v.add(path, message)
+		return
+	}
+	if schema.Minimum != nil {
+		bound := strconv.FormatFloat(*schema.Minimum, 'f', -1, 64)
+		if schema.ExclusiveMinimum && float <= *schema.Minimum {
+			v.add(path, "Ensure this value is greater than %s.", bound)
+		} else if float < *schema.Minimum {
+			v.add(path, "Ensure this value is greater than or equal to %s.", bound)
+		}
+	}
+	if schema.Maximum != nil {
+		bound := strconv.FormatFloat(*schema.Maximum, 'f', -1, 64)
+		if schema.ExclusiveMaximum && float >= *schema.Maximum {
+			v.add(path, "Ensure this value is less than %s.", bound)
+		} else if float > *schema.Maximum {
+			v.add(path, "Ensure this value is less than or equal to %s.", bound)
+		}
+	}
+}
+
+// coerce converts parameter string to the value json would decode for the schema.
+func coerce(schema *Schema, value string) any {
+	if schema == nil {
+		return value
+	}
+	switch schema.Type {
+	case "integer", "number":
+		return json.Number(value)
+	case "boolean":
+		if boolean, err := strconv.ParseBool(value); err == nil {
+			return boolean
+		}
+	}
+	return value
+}
+
+func inEnum(enum []any, value any) bool {
+	text := fmt.Sprint(value)
+	for _, choice := range enum {
+		if fmt.Sprint(choice) == text {
+			return true
+		}
+	}
+	return false
+}
+
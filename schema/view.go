@@ -0,0 +1,23 @@
+package schema
+
+import (
+	"net/http"
+
+	"github.com/rimba47prayoga/gorim.git"
+)
+
+// Document returns generated document, it's generated once since routes are registered on startup.
+func (g *Generator) Document() *Document {
+	g.once.Do(func() {
+		g.document = g.Generate()
+	})
+	return g.document
+}
+
+// View serves the document, example:
+//
+//	generator := &schema.Generator{Info: schema.Info{Title: "Shop"}}
+//	server.GET("/openapi.json", generator.View)
+func (g *Generator) View(c gorim.Context) error {
+	return c.JSON(http.StatusOK, g.Document())
+}
@@ -0,0 +1,134 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ElasticsearchBackend indexes documents in Elasticsearch or OpenSearch through the
+// REST API, queries are multi_match of every term over the fields, ranked by the engine.
+type ElasticsearchBackend struct {
+	// URL of the cluster, example: "http://localhost:9200".
+	URL			string
+	// Username and Password of basic auth, or APIKey sent as "ApiKey <key>".
+	Username	string
+	Password	string
+	APIKey		string
+	// Prefix of index names, example: "shop_" stores index products as shop_products.
+	Prefix		string
+	// Refresh makes changes searchable at once, slower, example: tests.
+	Refresh		bool
+	HTTPClient	*http.Client
+}
+
+func (b *ElasticsearchBackend) getHTTPClient() *http.Client {
+	if b.HTTPClient == nil {
+		return &http.Client{Timeout: 10 * time.Second}
+	}
+	return b.HTTPClient
+}
+
+func (b *ElasticsearchBackend) do(ctx context.Context, method string, path string, body any, result any) (int, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	request, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(b.URL, "/") + path, reader)
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if b.APIKey != "" {
+		request.Header.Set("Authorization", "ApiKey " + b.APIKey)
+	} else if b.Username != "" {
+		request.SetBasicAuth(b.Username, b.Password)
+	}
+	response, err := b.getHTTPClient().Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode >= http.StatusBadRequest {
+		if response.StatusCode == http.StatusNotFound {
+			return response.StatusCode, nil
+		}
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return response.StatusCode, fmt.Errorf("search: %s %s: %s: %s", method, path, response.Status, message)
+	}
+	if result != nil {
+		return response.StatusCode, json.NewDecoder(response.Body).Decode(result)
+	}
+	return response.StatusCode, nil
+}
+
+func (b *ElasticsearchBackend) documentPath(index string, id string) string {
+	path := "/" + url.PathEscape(b.Prefix + index) + "/_doc/" + url.PathEscape(id)
+	if b.Refresh {
+		path += "?refresh=true"
+	}
+	return path
+}
+
+func (b *ElasticsearchBackend) Index(ctx context.Context, index string, id string, document map[string]any) error {
+	_, err := b.do(ctx, http.MethodPut, b.documentPath(index, id), document, nil)
+	return err
+}
+
+func (b *ElasticsearchBackend) Delete(ctx context.Context, index string, id string) error {
+	_, err := b.do(ctx, http.MethodDelete, b.documentPath(index, id), nil, nil)
+	return err
+}
+
+// Clear deletes the index, it's created again by the next document.
+func (b *ElasticsearchBackend) Clear(ctx context.Context, index string) error {
+	_, err := b.do(ctx, http.MethodDelete, "/" + url.PathEscape(b.Prefix + index), nil, nil)
+	return err
+}
+
+func (b *ElasticsearchBackend) Search(ctx context.Context, index string, query Query) ([]Hit, error) {
+	body := map[string]any{
+		"_source": false,
+		"query": map[string]any{
+			"multi_match": map[string]any{
+				"query": query.Text,
+				"fields": query.Fields,
+				"operator": "and",
+			},
+		},
+	}
+	if query.Limit > 0 {
+		body["size"] = query.Limit
+	}
+	var result struct {
+		Hits struct {
+			Hits []struct {
+				ID		string	`json:"_id"`
+				Score	float64	`json:"_score"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	status, err := b.do(ctx, http.MethodPost, "/" + url.PathEscape(b.Prefix + index) + "/_search", body, &result)
+	if err != nil {
+		return nil, err
+	}
+	hits := []Hit{}
+	if status == http.StatusNotFound {
+		// nothing is indexed yet.
+		return hits, nil
+	}
+	for _, hit := range result.Hits.Hits {
+		hits = append(hits, Hit{ID: hit.ID, Score: hit.Score})
+	}
+	return hits, nil
+}
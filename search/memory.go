@@ -0,0 +1,107 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// MemoryBackend keeps indexes in process memory, ranking documents by tf-idf of the
+// terms, every term has to match. It suits tests and small datasets of one process.
+type MemoryBackend struct {
+	mu		sync.RWMutex
+	indexes	map[string]map[string]map[string]int
+}
+
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{indexes: map[string]map[string]map[string]int{}}
+}
+
+// Tokenize splits text into lower case words, used by MemoryBackend.
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func (b *MemoryBackend) Index(ctx context.Context, index string, id string, document map[string]any) error {
+	terms := map[string]int{}
+	for _, value := range document {
+		if value == nil {
+			continue
+		}
+		for _, term := range Tokenize(fmt.Sprint(value)) {
+			terms[term]++
+		}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.indexes[index] == nil {
+		b.indexes[index] = map[string]map[string]int{}
+	}
+	b.indexes[index][id] = terms
+	return nil
+}
+
+func (b *MemoryBackend) Delete(ctx context.Context, index string, id string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.indexes[index], id)
+	return nil
+}
+
+func (b *MemoryBackend) Clear(ctx context.Context, index string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.indexes, index)
+	return nil
+}
+
+func (b *MemoryBackend) Search(ctx context.Context, index string, query Query) ([]Hit, error) {
+	terms := Tokenize(query.Text)
+	if len(terms) == 0 {
+		return []Hit{}, nil
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	documents := b.indexes[index]
+	idf := map[string]float64{}
+	for _, term := range terms {
+		matched := 0
+		for _, document := range documents {
+			if document[term] > 0 {
+				matched++
+			}
+		}
+		idf[term] = math.Log(1 + float64(len(documents)) / float64(matched + 1))
+	}
+	hits := []Hit{}
+	for id, document := range documents {
+		score := 0.0
+		for _, term := range terms {
+			count := document[term]
+			if count == 0 {
+				score = 0
+				break
+			}
+			score += float64(count) * idf[term]
+		}
+		if score > 0 {
+			hits = append(hits, Hit{ID: id, Score: score})
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	if query.Limit > 0 && len(hits) > query.Limit {
+		hits = hits[:query.Limit]
+	}
+	return hits, nil
+}
@@ -0,0 +1,237 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/rimba47prayoga/gorim.git/signals"
+	"github.com/rimba47prayoga/gorim.git/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Query of a search, Text is matched against Fields of documents.
+type Query struct {
+	Text	string
+	Fields	[]string
+	// Limit is the number of hits returned, best first.
+	Limit	int
+}
+
+// Hit is a document matching a query, ID is the primary key of the row.
+type Hit struct {
+	ID		string
+	Score	float64
+}
+
+// Backend is a search engine keeping documents of indexes, see MemoryBackend and
+// ElasticsearchBackend, engines such as Bleve are plugged by implementing it.
+type Backend interface {
+	// Index adds or replaces the document of id.
+	Index(ctx context.Context, index string, id string, document map[string]any) error
+	// Delete removes the document of id, missing documents aren't errors.
+	Delete(ctx context.Context, index string, id string) error
+	Search(ctx context.Context, index string, query Query) ([]Hit, error)
+}
+
+// Clearer is a backend removing every document of an index, used by Reindex.
+type Clearer interface {
+	Clear(ctx context.Context, index string) error
+}
+
+// Index keeps rows of a model searchable by Backend, documents are indexed on PostSave
+// and removed on PostDelete, rows changed in bulk are indexed again by Reindex.
+type Index struct {
+	// Name of the index, used by filters, example: `index:"products"`.
+	Name		string
+	Backend		Backend
+	// Fields indexed by column or field name, example: []string{"name", "description"}.
+	Fields		[]string
+	// PKField is the column ids of hits are matched with, default "id".
+	PKField		string
+	// MaxResults is the number of hits joined back to the queryset, default 1000.
+	MaxResults	int
+	model		reflect.Type
+}
+
+func (i *Index) GetPKField() string {
+	if i.PKField != "" {
+		return i.PKField
+	}
+	return "id"
+}
+
+func (i *Index) GetMaxResults() int {
+	if i.MaxResults > 0 {
+		return i.MaxResults
+	}
+	return 1000
+}
+
+var (
+	mu		sync.RWMutex
+	indexes	= map[string]*Index{}
+)
+
+// Register adds index of model T, connecting signals maintaining it, example:
+//
+//	search.Register[Product](&search.Index{
+//		Name: "products",
+//		Backend: &search.ElasticsearchBackend{URL: "http://localhost:9200"},
+//		Fields: []string{"name", "description"},
+//	})
+func Register[T any](index *Index) *Index {
+	index.model = reflect.TypeOf((*T)(nil)).Elem()
+	mu.Lock()
+	indexes[index.Name] = index
+	mu.Unlock()
+	signals.Connect(signals.PostSave, func(event signals.Event[T]) {
+		if err := index.Save(eventContext(event), event.Instance); err != nil {
+			slog.Error("search: index failed", "index", index.Name, "error", err)
+		}
+	})
+	signals.Connect(signals.PostDelete, func(event signals.Event[T]) {
+		if err := index.Remove(eventContext(event), event.Instance); err != nil {
+			slog.Error("search: delete failed", "index", index.Name, "error", err)
+		}
+	})
+	return index
+}
+
+// Lookup returns index registered by name, nil when there's none.
+func Lookup(name string) *Index {
+	mu.RLock()
+	defer mu.RUnlock()
+	return indexes[name]
+}
+
+func eventContext[T any](event signals.Event[T]) context.Context {
+	if event.Context != nil {
+		return event.Context.Request().Context()
+	}
+	return context.Background()
+}
+
+// Document returns id and fields of instance indexed by the backend.
+func (i *Index) Document(instance any) (string, map[string]any, error) {
+	pk, err := utils.GetFieldValue(instance, i.GetPKField())
+	if err != nil {
+		return "", nil, err
+	}
+	document := map[string]any{}
+	for _, field := range i.Fields {
+		value, err := utils.GetFieldValue(instance, field)
+		if err != nil {
+			return "", nil, err
+		}
+		document[field] = value
+	}
+	return fmt.Sprint(pk), document, nil
+}
+
+// Save indexes instance, a pointer to the model.
+func (i *Index) Save(ctx context.Context, instance any) error {
+	if instance == nil || reflect.ValueOf(instance).IsNil() {
+		return nil
+	}
+	id, document, err := i.Document(instance)
+	if err != nil {
+		return err
+	}
+	return i.Backend.Index(ctx, i.Name, id, document)
+}
+
+// Remove deletes document of instance.
+func (i *Index) Remove(ctx context.Context, instance any) error {
+	if instance == nil || reflect.ValueOf(instance).IsNil() {
+		return nil
+	}
+	pk, err := utils.GetFieldValue(instance, i.GetPKField())
+	if err != nil {
+		return err
+	}
+	return i.Backend.Delete(ctx, i.Name, fmt.Sprint(pk))
+}
+
+// Reindex clears the index when the backend is a Clearer and indexes rows of db in
+// batches, run it after bulk changes or when Fields change, example:
+// search.Lookup("products").Reindex(ctx, conf.DB)
+func (i *Index) Reindex(ctx context.Context, db *gorm.DB) error {
+	if clearer, ok := i.Backend.(Clearer); ok {
+		if err := clearer.Clear(ctx, i.Name); err != nil {
+			return err
+		}
+	}
+	rows := reflect.New(reflect.SliceOf(i.model))
+	var indexErr error
+	result := db.WithContext(ctx).Model(reflect.New(i.model).Interface()).FindInBatches(rows.Interface(), 500, func(tx *gorm.DB, batch int) error {
+		slice := rows.Elem()
+		for n := 0; n < slice.Len(); n++ {
+			if indexErr = i.Save(ctx, slice.Index(n).Addr().Interface()); indexErr != nil {
+				return indexErr
+			}
+		}
+		return nil
+	})
+	if indexErr != nil {
+		return indexErr
+	}
+	return result.Error
+}
+
+// Search returns hits of text, best first.
+func (i *Index) Search(ctx context.Context, text string) ([]Hit, error) {
+	return i.Backend.Search(ctx, i.Name, Query{Text: text, Fields: i.Fields, Limit: i.GetMaxResults()})
+}
+
+// Filter narrows db to rows matching text, ordered by relevance, other orders of the
+// queryset such as pagination sort apply to rows of equal rank.
+func (i *Index) Filter(ctx context.Context, db *gorm.DB, text string) (*gorm.DB, error) {
+	hits, err := i.Search(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	if len(hits) == 0 {
+		return db.Where("1 = 0"), nil
+	}
+	column := i.GetPKField()
+	ids := make([]string, 0, len(hits))
+	rank := strings.Builder{}
+	for n, hit := range hits {
+		ids = append(ids, hit.ID)
+		// the rank is a column, gorm drops order expressions with vars when pagination
+		// adds its sort, so only ids safe as literals are ranked, others rank last.
+		if literal, ok := idLiteral(hit.ID); ok {
+			fmt.Fprintf(&rank, " WHEN %s THEN %d", literal, n)
+		}
+	}
+	db = db.Where(column + " IN ?", ids)
+	if rank.Len() == 0 {
+		return db, nil
+	}
+	return db.Order(clause.OrderByColumn{
+		Column: clause.Column{Name: "CASE " + column + rank.String() + fmt.Sprintf(" ELSE %d END", len(hits)), Raw: true},
+	}), nil
+}
+
+// idLiteral returns id as SQL literal, integers as numbers and ids of letters, digits,
+// dashes and underscores quoted, example: UUIDs.
+func idLiteral(id string) (string, bool) {
+	if id == "" {
+		return "", false
+	}
+	if _, err := strconv.ParseInt(id, 10, 64); err == nil {
+		return id, true
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return "", false
+		}
+	}
+	return "'" + id + "'", true
+}
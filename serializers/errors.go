@@ -0,0 +1,123 @@
+package serializers
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/rimba47prayoga/gorim.git/errors"
+	"github.com/rimba47prayoga/gorim.git/i18n"
+)
+
+// ValidationMessages renders validator tags, %s is replaced by the tag param.
+// Messages are also the message ids translated by i18n.
+var ValidationMessages = map[string]string{
+	"required":		"This field is required.",
+	"email":		"Enter a valid email address.",
+	"url":			"Enter a valid URL.",
+	"uuid":			"Must be a valid UUID.",
+	"numeric":		"A valid number is required.",
+	"alphanum":		"Only letters and numbers are allowed.",
+	"oneof":		"Must be one of: %s.",
+	"len":			"Ensure this field has exactly %s.",
+	"min":			"Ensure this value is greater than or equal to %s.",
+	"max":			"Ensure this value is less than or equal to %s.",
+	"gte":			"Ensure this value is greater than or equal to %s.",
+	"lte":			"Ensure this value is less than or equal to %s.",
+	"gt":			"Ensure this value is greater than %s.",
+	"lt":			"Ensure this value is less than %s.",
+}
+
+// lengthMessages are used for min, max and len of strings and slices.
+var lengthMessages = map[string]map[reflect.Kind]string{
+	"min":	{
+		reflect.String:	"Ensure this field has at least %s characters.",
+		reflect.Slice:	"Ensure this field has at least %s items.",
+	},
+	"max":	{
+		reflect.String:	"Ensure this field has no more than %s characters.",
+		reflect.Slice:	"Ensure this field has no more than %s items.",
+	},
+	"len":	{
+		reflect.String:	"Ensure this field has exactly %s characters.",
+		reflect.Slice:	"Ensure this field has exactly %s items.",
+	},
+}
+
+// ValidationMessage returns the message of the failed validator tag translated to language.
+func ValidationMessage(e validator.FieldError, language string) string {
+	kind := e.Kind()
+	if kind == reflect.Array || kind == reflect.Map {
+		kind = reflect.Slice
+	}
+	format, ok := lengthMessages[e.Tag()][kind]
+	if !ok {
+		format, ok = ValidationMessages[e.Tag()]
+	}
+	if !ok {
+		return i18n.Translate(language, "Failed on the '%s' validation.", e.Tag())
+	}
+	if strings.Contains(format, "%s") {
+		return i18n.Translate(language, format, e.Param())
+	}
+	return i18n.Translate(language, format)
+}
+
+// FieldPath converts validator struct namespace into json path of the root type,
+// example: "UserSerializer.Addresses[0].ZipCode" => "addresses.0.zip_code".
+func FieldPath(rootType reflect.Type, namespace string) string {
+	parts := strings.Split(namespace, ".")
+	if len(parts) > 1 {
+		// first part is the root type name.
+		parts = parts[1:]
+	}
+	structType := rootType
+	path := []string{}
+	for _, part := range parts {
+		name, index, _ := strings.Cut(part, "[")
+		for structType != nil && (structType.Kind() == reflect.Ptr || structType.Kind() == reflect.Slice || structType.Kind() == reflect.Array || structType.Kind() == reflect.Map) {
+			structType = structType.Elem()
+		}
+		jsonName := name
+		if structType != nil && structType.Kind() == reflect.Struct {
+			if field, ok := structType.FieldByName(name); ok {
+				jsonName = tagName(field)
+				structType = field.Type
+			} else {
+				structType = nil
+			}
+		}
+		path = append(path, jsonName)
+		if index != "" {
+			path = append(path, strings.TrimSuffix(index, "]"))
+		}
+	}
+	return strings.Join(path, ".")
+}
+
+// tagName returns json or form tag name of the field, field name if neither is set.
+func tagName(field reflect.StructField) string {
+	for _, key := range []string{"json", "form"} {
+		tag := field.Tag.Get(key)
+		if tag != "" && tag != "-" {
+			if name := strings.Split(tag, ",")[0]; name != "" {
+				return name
+			}
+		}
+	}
+	return field.Name
+}
+
+// ToValidationErrors converts error returned by validator.Struct of rootType value,
+// messages are translated to language.
+func ToValidationErrors(rootType reflect.Type, err error, language string) errors.ValidationErrors {
+	validationErrors := errors.ValidationErrors{}
+	if errs, ok := err.(validator.ValidationErrors); ok {
+		for _, e := range errs {
+			validationErrors.Add(FieldPath(rootType, e.StructNamespace()), ValidationMessage(e, language))
+		}
+		return validationErrors
+	}
+	validationErrors.Add(errors.NonFieldErrors, err.Error())
+	return validationErrors
+}
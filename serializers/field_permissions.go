@@ -0,0 +1,168 @@
+package serializers
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/conf"
+	"github.com/rimba47prayoga/gorim.git/interfaces"
+	"github.com/rimba47prayoga/gorim.git/rbac"
+	"github.com/rimba47prayoga/gorim.git/utils"
+	"gorm.io/gorm"
+)
+
+const grantsContextKey = "gorim:field_grants"
+
+// CanAccessField evaluates `permissions` and `roles` tags of serializer field against the user,
+// example: Salary int `json:"salary" permissions:"hr.view_salary" roles:"hr"`
+func CanAccessField(field reflect.StructField, user interface{}) bool {
+	return canAccessField(field, user, &grants{})
+}
+
+func canAccessField(field reflect.StructField, user interface{}, granted *grants) bool {
+	permissionsTag := field.Tag.Get("permissions")
+	rolesTag := field.Tag.Get("roles")
+	if permissionsTag == "" && rolesTag == "" {
+		return true
+	}
+	if user == nil {
+		return false
+	}
+	if admin, ok := user.(interfaces.IAdminUser); ok && admin.IsAdminUser() {
+		return true
+	}
+	userID, ok := utils.GetUserID(user)
+	if !ok {
+		return false
+	}
+	if permissionsTag != "" && !containsAll(granted.permissionsOf(userID), strings.Split(permissionsTag, ",")) {
+		return false
+	}
+	if rolesTag != "" && !containsAll(granted.rolesOf(userID), strings.Split(rolesTag, ",")) {
+		return false
+	}
+	return true
+}
+
+// grants are permissions and roles of the request user, loaded once by the first field
+// needing them since serializers check fields several times a request.
+type grants struct {
+	db			*gorm.DB
+	userID		uint
+	permissions	[]string
+	roles		[]string
+	loaded		map[string]bool
+}
+
+// grantsOf returns grants stored in the context, nil context gets grants of one call.
+func grantsOf(c echo.Context) *grants {
+	if c == nil {
+		return &grants{}
+	}
+	if granted, ok := c.Get(grantsContextKey).(*grants); ok {
+		return granted
+	}
+	granted := &grants{db: conf.GetDB(c.Request().Context())}
+	c.Set(grantsContextKey, granted)
+	return granted
+}
+
+func (g *grants) permissionsOf(userID uint) []string {
+	if g.load("permissions", userID) {
+		g.permissions = rbac.GetUserPermissions(g.getDB(), userID)
+	}
+	return g.permissions
+}
+
+func (g *grants) rolesOf(userID uint) []string {
+	if g.load("roles", userID) {
+		g.roles = rbac.GetUserRoles(g.getDB(), userID)
+	}
+	return g.roles
+}
+
+// load reports whether name has to be loaded, grants of another user are dropped.
+func (g *grants) load(name string, userID uint) bool {
+	if g.loaded == nil || g.userID != userID {
+		g.loaded = map[string]bool{}
+		g.userID = userID
+	}
+	if g.loaded[name] {
+		return false
+	}
+	g.loaded[name] = true
+	return true
+}
+
+func (g *grants) getDB() *gorm.DB {
+	if g.db == nil {
+		return conf.DB
+	}
+	return g.db
+}
+
+func containsAll(granted []string, required []string) bool {
+	for _, item := range required {
+		if !utils.Contains(granted, item) {
+			return false
+		}
+	}
+	return true
+}
+
+// GetDeniedFields returns struct field names of the serializer the context user can't access.
+func GetDeniedFields(serializer interface{}, c echo.Context) []string {
+	denied := []string{}
+	typ := reflect.TypeOf(serializer)
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return denied
+	}
+	var user interface{}
+	if c != nil {
+		user = c.Get(gorim.UserContextKey)
+	}
+	granted := grantsOf(c)
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !canAccessField(field, user, granted) {
+			denied = append(denied, field.Name)
+		}
+	}
+	return denied
+}
+
+// OmitFields removes json keys from data, data is an object or slice of objects.
+func OmitFields(data interface{}, keys []string) interface{} {
+	if len(keys) == 0 {
+		return data
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return data
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return data
+	}
+	omit := func(item interface{}) {
+		if object, ok := item.(map[string]interface{}); ok {
+			for _, key := range keys {
+				delete(object, key)
+			}
+		}
+	}
+	if items, ok := decoded.([]interface{}); ok {
+		for _, item := range items {
+			omit(item)
+		}
+	} else {
+		omit(decoded)
+	}
+	return decoded
+}
@@ -0,0 +1,57 @@
+package serializers
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"reflect"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git/images"
+	"github.com/rimba47prayoga/gorim.git/parsers"
+	"github.com/rimba47prayoga/gorim.git/storages"
+)
+
+var uploadedFileType = reflect.TypeOf(&parsers.UploadedFile{})
+
+// saveUploadedFile stores upload of serializer field in the storage of its storage tag,
+// default storages.Default(), under the directory of upload_to tag and returns the name
+// it's stored as qualified by the storage, example: `json:"image" upload_to:"products" storage:"private"`
+// => "private://products/1.png".
+func saveUploadedFile(c echo.Context, field reflect.StructField, upload *parsers.UploadedFile) (string, error) {
+	storageName := field.Tag.Get("storage")
+	storage := storages.Get(storageName)
+	if storage == nil {
+		return "", fmt.Errorf("storage %q of %s is not registered", storageName, field.Name)
+	}
+	file, err := upload.Open()
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	ctx := context.Background()
+	if c != nil {
+		ctx = c.Request().Context()
+	}
+	name := path.Join(field.Tag.Get("upload_to"), path.Base(storages.CleanName(upload.Filename)))
+	name, err = storage.Save(ctx, name, file)
+	if err != nil {
+		return "", err
+	}
+	// variants of images which aren't lazy are generated on upload, see images.Register.
+	if err := images.OnUpload(ctx, storage, name); err != nil {
+		storage.Delete(ctx, name)
+		return "", err
+	}
+	return storages.Qualify(storageName, name), nil
+}
+
+// setFileName sets name of stored file to string field of model, example: fields.File and fields.Image.
+func setFileName(model interface{}, field string, name string) error {
+	value := reflect.ValueOf(model).Elem().FieldByName(field)
+	if !value.IsValid() || value.Kind() != reflect.String {
+		return fmt.Errorf("field %s of %T must be fields.File, fields.Image or string to store uploaded files", field, model)
+	}
+	value.SetString(name)
+	return nil
+}
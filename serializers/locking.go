@@ -0,0 +1,112 @@
+package serializers
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/rimba47prayoga/gorim.git/conf"
+	"github.com/rimba47prayoga/gorim.git/errors"
+	"github.com/rimba47prayoga/gorim.git/utils"
+	"gorm.io/gorm"
+)
+
+const conflictMessage = "The object was modified by another request, reload it and try again."
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// IsLockedModel reports whether model has conf.LOCK_VERSION_COLUMN of integer or time,
+// columns of other types such as version strings of the domain aren't versions of rows.
+func IsLockedModel(model interface{}) bool {
+	if conf.LOCK_VERSION_COLUMN == "" {
+		return false
+	}
+	name, err := utils.LookUpFieldName(model, conf.LOCK_VERSION_COLUMN)
+	if err != nil {
+		return false
+	}
+	typ := reflect.TypeOf(model)
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	field, ok := typ.FieldByName(name)
+	if !ok {
+		return false
+	}
+	switch field.Type.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return field.Type == timeType
+}
+
+// lockedUpdate saves instance only when its version column still has current value,
+// expected is the version the client edited, from the serializer field or If-Match header.
+func (s *ModelSerializer[T]) lockedUpdate(db *gorm.DB, instance *T, current interface{}) error {
+	expected, err := utils.GetFieldValue(instance, conf.LOCK_VERSION_COLUMN)
+	if err != nil {
+		return err
+	}
+	if s.context != nil {
+		if header := s.context.Request().Header.Get("If-Match"); header != "" {
+			expected = strings.Trim(strings.TrimPrefix(header, "W/"), `"`)
+		}
+	}
+	if !sameVersion(current, expected) {
+		errors.Raise(errors.Conflict(conflictMessage))
+	}
+	if err := utils.SetStructValue(instance, fieldName(instance), nextVersion(current)); err != nil {
+		return err
+	}
+	result := db.Model(instance).
+		Where(conf.LOCK_VERSION_COLUMN + " = ?", current).
+		Select("*").
+		Updates(instance)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		errors.Raise(errors.Conflict(conflictMessage))
+	}
+	return nil
+}
+
+func fieldName(instance interface{}) string {
+	name, _ := utils.LookUpFieldName(instance, conf.LOCK_VERSION_COLUMN)
+	return name
+}
+
+// sameVersion compares integer versions or times, expected may be string from If-Match header.
+func sameVersion(current interface{}, expected interface{}) bool {
+	if currentTime, ok := current.(time.Time); ok {
+		switch expected := expected.(type) {
+		case time.Time:
+			return currentTime.Equal(expected)
+		case string:
+			parsed, err := time.Parse(time.RFC3339Nano, expected)
+			return err == nil && currentTime.Equal(parsed)
+		}
+		return false
+	}
+	return fmt.Sprint(current) == fmt.Sprint(expected)
+}
+
+// nextVersion increments integer versions, time versions are set to now.
+func nextVersion(current interface{}) interface{} {
+	if _, ok := current.(time.Time); ok {
+		return time.Now()
+	}
+	value := reflect.ValueOf(current)
+	next := reflect.New(value.Type()).Elem()
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		next.SetInt(value.Int() + 1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		next.SetUint(value.Uint() + 1)
+	default:
+		return current
+	}
+	return next.Interface()
+}
@@ -1,14 +1,24 @@
 package serializers
 
 import (
+	stderrors "errors"
 	"fmt"
 	"reflect"
 	"strings"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git"
 	"github.com/rimba47prayoga/gorim.git/conf"
+	"github.com/rimba47prayoga/gorim.git/contenttypes"
 	"github.com/rimba47prayoga/gorim.git/errors"
+	"github.com/rimba47prayoga/gorim.git/i18n"
+	"github.com/rimba47prayoga/gorim.git/images"
+	"github.com/rimba47prayoga/gorim.git/parsers"
+	"github.com/rimba47prayoga/gorim.git/policies"
+	"github.com/rimba47prayoga/gorim.git/signals"
+	"github.com/rimba47prayoga/gorim.git/tenancy"
+	"github.com/rimba47prayoga/gorim.git/tracing"
 	"github.com/rimba47prayoga/gorim.git/utils"
 	"gorm.io/gorm"
 )
@@ -17,7 +27,7 @@ import (
 type IModelSerializer[T any] interface {
 	Validate()
 	IsValid() bool
-	GetErrors() []errors.ValidationError
+	GetErrors() errors.ValidationErrors
 	GetContext() echo.Context
 	SetContext(echo.Context)
 	SetChild(IModelSerializer[T])
@@ -26,11 +36,12 @@ type IModelSerializer[T any] interface {
 	DB() *gorm.DB
 	Create() *T
 	Update(*T) *T
+	ToRepresentation(interface{}) interface{}
 }
 
 
 type ModelSerializer[T any] struct {
-	errors			[]errors.ValidationError
+	errors			errors.ValidationErrors
 	context			echo.Context
 	child			IModelSerializer[T]
 }
@@ -45,8 +56,12 @@ func (s *ModelSerializer[T]) Fields() []string {
 	return s.GetFields()
 }
 
+// DB is bound to the request context, so queries are aborted when the request is canceled.
 func (s *ModelSerializer[T]) DB() *gorm.DB {
-	return conf.DB
+	if s.context == nil {
+		return conf.DB
+	}
+	return conf.GetDB(s.context.Request().Context())
 }
 // ------ END ------
 
@@ -55,7 +70,7 @@ func (s *ModelSerializer[T]) GetContext() echo.Context {
 	return s.context
 }
 
-func (s *ModelSerializer[T]) GetErrors() []errors.ValidationError {
+func (s *ModelSerializer[T]) GetErrors() errors.ValidationErrors {
 	return s.errors
 }
 
@@ -114,7 +129,11 @@ func (s *ModelSerializer[T]) SetChild(child IModelSerializer[T]) {
 
 func (s *ModelSerializer[T]) SetModelAttr(model *T) {
 	serializer := s.child
+	deniedFields := GetDeniedFields(serializer, s.context)
 	for _, field := range serializer.Fields() {
+		if utils.Contains(deniedFields, field) {
+			continue
+		}
 
 		value, err := utils.GetStructValue(serializer, field)
 		if err != nil {
@@ -122,6 +141,13 @@ func (s *ModelSerializer[T]) SetModelAttr(model *T) {
 				Message: err.Error(),
 			})
 		}
+		if structField, _ := reflect.TypeOf(serializer).Elem().FieldByName(field); structField.Type == uploadedFileType {
+			// files missing from the request keep the current file.
+			if upload, ok := value.(*parsers.UploadedFile); ok && upload != nil {
+				s.setUploadedFile(model, structField, upload)
+			}
+			continue
+		}
 		err = utils.SetStructValue(model, field, value)
 		if err != nil {
 			errors.Raise(&errors.InternalServerError{
@@ -130,34 +156,39 @@ func (s *ModelSerializer[T]) SetModelAttr(model *T) {
 		}
 	}
 }
+
+// setUploadedFile stores upload in storage and sets its name to the model field.
+func (s *ModelSerializer[T]) setUploadedFile(model *T, field reflect.StructField, upload *parsers.UploadedFile) {
+	name, err := saveUploadedFile(s.context, field, upload)
+	if stderrors.Is(err, images.ErrInvalidImage) {
+		errors.Raise(errors.Invalid(s.GetFieldName(field.Name), "Upload a valid image. The file you uploaded was either not an image or a corrupted image."))
+	}
+	if err == nil {
+		err = setFileName(model, field.Name, name)
+	}
+	if err != nil {
+		errors.Raise(&errors.InternalServerError{
+			Message: err.Error(),
+		})
+	}
+}
 // ------ END ------
 
 // ------ Error Handlers ------
+// AddError adds message to field, empty field adds to non_field_errors.
 func (s *ModelSerializer[T]) AddError(field string, message string) {
-	s.errors = append(s.errors, errors.ValidationError{
-		Field: field,
-		Message: message,
-	})
+	if s.errors == nil {
+		s.errors = errors.ValidationErrors{}
+	}
+	s.errors.Add(field, message)
 }
 
-// HandleError processes and formats validation errors.
+// HandleError processes and formats validation errors, nested fields are keyed by path.
 func (s *ModelSerializer[T]) HandleError(err error) {
-	if errs, ok := err.(validator.ValidationErrors); ok {
-		var validationErrors []errors.ValidationError
-		for _, e := range errs {
-			fieldName := s.GetFieldName(e.StructField())
-			validationErrors = append(validationErrors, errors.ValidationError{
-				Field:   fieldName,
-				Message: fmt.Sprintf("%s is %s", fieldName, e.Tag()),
-			})
-		}
-		s.errors = validationErrors
-	} else {
-		s.errors = append(s.errors, errors.ValidationError{
-			Field:   "non_field_errors",
-			Message: err.Error(),
-		})
+	if s.errors == nil {
+		s.errors = errors.ValidationErrors{}
 	}
+	s.errors.Merge("", ToValidationErrors(reflect.TypeOf(s.child).Elem(), err, i18n.Language(s.context)))
 }
 // ------ END ------
 
@@ -169,6 +200,7 @@ func (s *ModelSerializer[T]) Validate() {
 		s.HandleError(err)
 		return
 	}
+	s.ValidateFieldPermissions()
 	serializerVal := reflect.ValueOf(serializer)
 	for _, field := range serializer.Fields() {
 		methodName := fmt.Sprintf("Validate%s", field)
@@ -179,25 +211,143 @@ func (s *ModelSerializer[T]) Validate() {
 	}
 }
 
+// ValidateFieldPermissions rejects values sent for fields the context user can't access.
+func (s *ModelSerializer[T]) ValidateFieldPermissions() {
+	serializer := s.child
+	for _, field := range GetDeniedFields(serializer, s.context) {
+		value := reflect.ValueOf(serializer).Elem().FieldByName(field)
+		if value.IsValid() && !value.IsZero() {
+			s.AddError(s.GetFieldName(field), "You do not have permission to set this field.")
+		}
+	}
+}
+
 // IsValid validates the serializer and handles errors.
 func (s *ModelSerializer[T]) IsValid() bool {
+	if s.context != nil {
+		span := tracing.StartSpan(s.context, utils.GetStructName(s.child) + ".Validate")
+		defer span.End()
+	}
 	s.child.Validate()
 	isValid := len(s.errors) == 0
 	return isValid
 }
 // ------ END ------
 
+// SetParentLookups assigns the parent of nested route to the model.
+func (s *ModelSerializer[T]) SetParentLookups(model *T) {
+	if s.context == nil {
+		return
+	}
+	lookups, _ := s.context.Get(gorim.ParentLookupsContextKey).(map[string]string)
+	for field, value := range lookups {
+		if err := utils.SetFieldFromString(model, field, value); err != nil {
+			errors.Raise(&errors.InternalServerError{
+				Message: err.Error(),
+			})
+		}
+	}
+}
+
+// SetTenant assigns tenant of the request to model having conf.TENANT_COLUMN,
+// on update too so the object can't be moved to another tenant.
+func (s *ModelSerializer[T]) SetTenant(model *T) {
+	if err := tenancy.Assign(s.context, model); err != nil {
+		if apiErr, ok := err.(errors.APIException); ok {
+			errors.Raise(apiErr)
+		}
+		errors.Raise(&errors.InternalServerError{
+			Message: err.Error(),
+		})
+	}
+}
+
+// SetPolicies fills fields of model by policies of the viewset, see policies.Policy.
+func (s *ModelSerializer[T]) SetPolicies(model *T) {
+	if err := policies.Assign(s.context, model); err != nil {
+		if apiErr, ok := err.(errors.APIException); ok {
+			errors.Raise(apiErr)
+		}
+		errors.Raise(&errors.InternalServerError{
+			Message: err.Error(),
+		})
+	}
+}
+
+// Create saves new model, errors of the database are raised so the row isn't reported
+// as created, example: imports counting created rows.
 func (s *ModelSerializer[T]) Create() *T {
 	serializer := s.child
-	model := serializer.Model()
+	model := s.Build()
+	signals.Send(signals.PreSave, s.context, model, true)
+	if err := serializer.DB().Create(model).Error; err != nil {
+		errors.Raise(err)
+	}
+	signals.Send(signals.PostSave, s.context, model, true)
+	return model
+}
+
+// Build returns new model filled like Create without saving it, example: imports
+// inserting rows in batches.
+func (s *ModelSerializer[T]) Build() *T {
+	model := s.child.Model()
 	s.SetModelAttr(model)
-	serializer.DB().Create(model)
+	s.SetParentLookups(model)
+	s.SetTenant(model)
+	s.SetPolicies(model)
+	touch(model, true)
 	return model
 }
 
+// ToRepresentation omits fields the context user can't access from the instance or slice of instances,
+// targets of contenttypes.GenericRelation are loaded to be rendered as object.
+func (s *ModelSerializer[T]) ToRepresentation(data interface{}) interface{} {
+	serializer := s.child
+	if contenttypes.HasRelation(data) {
+		if err := contenttypes.Prefetch(s.context, serializer.DB(), data); err != nil {
+			errors.Raise(&errors.InternalServerError{
+				Message: err.Error(),
+			})
+		}
+	}
+	keys := []string{}
+	for _, field := range GetDeniedFields(serializer, s.context) {
+		keys = append(keys, s.GetFieldName(field))
+	}
+	return OmitFields(data, keys)
+}
+
+// Update saves instance, models having conf.LOCK_VERSION_COLUMN are updated only when
+// the version sent by the client is still current, otherwise 409 is raised. Errors of the
+// database are raised too.
 func (s *ModelSerializer[T]) Update(instance *T) *T {
 	serializer := s.child
+	locked := IsLockedModel(instance)
+	var current interface{}
+	if locked {
+		current, _ = utils.GetFieldValue(instance, conf.LOCK_VERSION_COLUMN)
+	}
 	s.SetModelAttr(instance)
-	serializer.DB().Save(instance)
+	s.SetTenant(instance)
+	s.SetPolicies(instance)
+	touch(instance, false)
+	signals.Send(signals.PreSave, s.context, instance, false)
+	var err error
+	if locked {
+		err = s.lockedUpdate(serializer.DB(), instance, current)
+	} else {
+		err = serializer.DB().Save(instance).Error
+	}
+	if err != nil {
+		errors.Raise(err)
+	}
+	signals.Send(signals.PostSave, s.context, instance, false)
 	return instance
 }
+
+// touch sets timestamps of models embedding gorim.Model before pre save signal.
+func touch(model interface{}, created bool) {
+	if touchable, ok := model.(interface{ Touch(bool) }); ok {
+		touchable.Touch(created)
+	}
+}
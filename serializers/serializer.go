@@ -1,25 +1,25 @@
 package serializers
 
 import (
-	"fmt"
 	"reflect"
 	"strings"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
 	"github.com/rimba47prayoga/gorim.git/errors"
+	"github.com/rimba47prayoga/gorim.git/i18n"
 )
 
 type ISerializer interface {
 	IsValid() bool
-	GetErrors() []errors.ValidationError
+	GetErrors() errors.ValidationErrors
 	GetContext() echo.Context
 	SetContext(echo.Context)
 }
 
 // Serializer struct with embedded error handling
 type Serializer struct {
-	errors			[]errors.ValidationError
+	errors			errors.ValidationErrors
 	structType		reflect.Type
 	context			echo.Context
 }
@@ -32,15 +32,16 @@ func (s *Serializer) SetContext(c echo.Context) {
 	s.context = c
 }
 
-func (s *Serializer) GetErrors() []errors.ValidationError {
+func (s *Serializer) GetErrors() errors.ValidationErrors {
 	return s.errors
 }
 
+// AddError adds message to field, empty field adds to non_field_errors.
 func (s *Serializer) AddError(field string, message string) {
-	s.errors = append(s.errors, errors.ValidationError{
-		Field: field,
-		Message: message,
-	})
+	if s.errors == nil {
+		s.errors = errors.ValidationErrors{}
+	}
+	s.errors.Add(field, message)
 }
 
 // Function to extract the JSON or form tag name from the struct field.
@@ -61,22 +62,10 @@ func (s *Serializer) getFieldName(structType reflect.Type, fieldName string) str
 
 // HandleError processes and formats validation errors.
 func (s *Serializer) HandleError(err error) {
-	if errs, ok := err.(validator.ValidationErrors); ok {
-		var validationErrors []errors.ValidationError
-		for _, e := range errs {
-			fieldName := s.getFieldName(s.structType, e.StructField())
-			validationErrors = append(validationErrors, errors.ValidationError{
-				Field:   fieldName,
-				Message: fmt.Sprintf("%s is %s", fieldName, e.Tag()),
-			})
-		}
-		s.errors = validationErrors
-	} else {
-		s.errors = append(s.errors, errors.ValidationError{
-			Field:   reflect.TypeOf(err).String(),
-			Message: err.Error(),
-		})
+	if s.errors == nil {
+		s.errors = errors.ValidationErrors{}
 	}
+	s.errors.Merge("", ToValidationErrors(s.structType, err, i18n.Language(s.context)))
 }
 
 
@@ -2,17 +2,34 @@ package gorim
 
 import (
 	"context"
+	"sync/atomic"
+	"time"
 
 	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git/errors"
+	"github.com/rimba47prayoga/gorim.git/hooks"
 	"github.com/rimba47prayoga/gorim.git/middlewares"
+	"github.com/rimba47prayoga/gorim.git/parsers"
+	"github.com/rimba47prayoga/gorim.git/sse"
+	"golang.org/x/net/http2"
 )
 
 // Server represents the Gorim server
 type Server struct {
     Echo *echo.Echo
+    // ShutdownTimeout is how long Run waits for in-flight requests, zero waits 30 seconds.
+    ShutdownTimeout time.Duration
+    // H2C serves HTTP/2 without TLS besides HTTP/1, needed by gRPC clients of grpc.Services.
+    H2C bool
+    startup []Hook
+    shutdown []Hook
+    closing atomic.Bool
 }
 
 func (s *Server) Start(address string) error {
+	if s.H2C {
+		return s.Echo.StartH2CServer(address, &http2.Server{})
+	}
 	return s.Echo.Start(address)
 }
 
@@ -37,36 +54,36 @@ func (s *Server) Routes() []*echo.Route {
 }
 
 // AddRoute registers a new route with the specified method, path, and handler
-func (s *Server) AddRoute(method string, path string, handler HandlerFunc) {
+func (s *Server) AddRoute(method string, path string, handler HandlerFunc, middleware ...echo.MiddlewareFunc) {
     s.Echo.Add(method, path, func(c echo.Context) error {
         ctx := NewContext(c) // Convert to your custom context
         return handler(ctx)   // Call the handler with your custom context
-    })
+    }, middleware...)
 }
 
 // Override HTTP methods
-func (s *Server) GET(path string, handler HandlerFunc) {
-    s.AddRoute(echo.GET, path, handler)
+func (s *Server) GET(path string, handler HandlerFunc, middleware ...echo.MiddlewareFunc) {
+    s.AddRoute(echo.GET, path, handler, middleware...)
 }
 
-func (s *Server) POST(path string, handler HandlerFunc) {
-    s.AddRoute(echo.POST, path, handler)
+func (s *Server) POST(path string, handler HandlerFunc, middleware ...echo.MiddlewareFunc) {
+    s.AddRoute(echo.POST, path, handler, middleware...)
 }
 
-func (s *Server) PUT(path string, handler HandlerFunc) {
-    s.AddRoute(echo.PUT, path, handler)
+func (s *Server) PUT(path string, handler HandlerFunc, middleware ...echo.MiddlewareFunc) {
+    s.AddRoute(echo.PUT, path, handler, middleware...)
 }
 
-func (s *Server) DELETE(path string, handler HandlerFunc) {
-    s.AddRoute(echo.DELETE, path, handler)
+func (s *Server) DELETE(path string, handler HandlerFunc, middleware ...echo.MiddlewareFunc) {
+    s.AddRoute(echo.DELETE, path, handler, middleware...)
 }
 
-func (s *Server) PATCH(path string, handler HandlerFunc) {
-    s.AddRoute(echo.PATCH, path, handler)
+func (s *Server) PATCH(path string, handler HandlerFunc, middleware ...echo.MiddlewareFunc) {
+    s.AddRoute(echo.PATCH, path, handler, middleware...)
 }
 
-func (s *Server) OPTIONS(path string, handler HandlerFunc) {
-    s.AddRoute(echo.OPTIONS, path, handler)
+func (s *Server) OPTIONS(path string, handler HandlerFunc, middleware ...echo.MiddlewareFunc) {
+    s.AddRoute(echo.OPTIONS, path, handler, middleware...)
 }
 
 func (s *Server) Use(middleware ...echo.MiddlewareFunc) {
@@ -77,10 +94,19 @@ func New() *Server {
 	e := echo.New()
 	e.HideBanner = true
 	e.HidePort = true
+	e.Binder = &parsers.Binder{}
 
 	server := Server{
 		Echo: e,
 	}
-	e.Use(middlewares.RecoverMiddleware)
+	e.HTTPErrorHandler = func(err error, c echo.Context) {
+		errors.Handle(err, c)
+	}
+	// hooks.Middleware wraps routing, so RequestStarted and ResponseWritten are sent for
+	// requests of any route, not found included.
+	e.Pre(hooks.Middleware)
+	e.Use(middlewares.RecoverMiddleware, hooks.RoutedMiddleware)
+	// event streams don't end by themselves, they're closed so shutdown can drain requests.
+	e.Server.RegisterOnShutdown(sse.CloseAll)
 	return &server
 }
@@ -0,0 +1,52 @@
+package sessions
+
+import (
+	"context"
+	"time"
+
+	"github.com/rimba47prayoga/gorim.git/cache"
+)
+
+// CacheStore keeps sessions in a cache until they expire, use a cache with redis
+// backend to share sessions between processes.
+type CacheStore struct {
+	// Cache defaults to cache.Default namespaced by "sessions".
+	Cache		*cache.Cache
+}
+
+func NewCacheStore(c *cache.Cache) *CacheStore {
+	return &CacheStore{
+		Cache: c,
+	}
+}
+
+func (s *CacheStore) GetCache() *cache.Cache {
+	if s.Cache == nil {
+		return cache.Default.Namespace("sessions")
+	}
+	return s.Cache
+}
+
+func (s *CacheStore) Load(id string) (*Session, error) {
+	var session Session
+	ok, err := s.GetCache().Get(context.Background(), id, &session)
+	if err != nil || !ok {
+		return nil, err
+	}
+	if session.IsExpired() {
+		return nil, s.Delete(id)
+	}
+	return &session, nil
+}
+
+func (s *CacheStore) Save(session *Session) error {
+	ttl := time.Until(session.ExpiresAt)
+	if ttl <= 0 {
+		return s.Delete(session.ID)
+	}
+	return s.GetCache().Set(context.Background(), session.ID, session, ttl)
+}
+
+func (s *CacheStore) Delete(id string) error {
+	return s.GetCache().Delete(context.Background(), id)
+}
@@ -0,0 +1,75 @@
+package sessions
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type GorimSession struct {
+	Key			string			`gorm:"type:varchar(64);primarykey"`
+	Data		string			`gorm:"type:text"`
+	ExpireDate	time.Time		`gorm:"type:timestamp;index"`
+}
+
+func (m GorimSession) TableName() string {
+	return "gorim_sessions"
+}
+
+// DBStore keeps sessions in gorim_sessions table, add &sessions.GorimSession{} to migration models.
+type DBStore struct {
+	DB		*gorm.DB
+}
+
+func NewDBStore(db *gorm.DB) *DBStore {
+	return &DBStore{DB: db}
+}
+
+// Load returns the session unless it's expired, the key condition is built by gorm so
+// the key column, a reserved word of MySQL, is quoted. Empty id is checked first since
+// gorm drops zero conditions.
+func (s *DBStore) Load(id string) (*Session, error) {
+	if id == "" {
+		return nil, nil
+	}
+	var record GorimSession
+	err := s.DB.Where(&GorimSession{Key: id}).Where("expire_date > ?", time.Now()).Limit(1).Find(&record).Error
+	if err != nil {
+		return nil, err
+	}
+	if record.Key == "" {
+		return nil, nil
+	}
+	values := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(record.Data), &values); err != nil {
+		return nil, err
+	}
+	return &Session{
+		ID: record.Key,
+		Values: values,
+		ExpiresAt: record.ExpireDate,
+	}, nil
+}
+
+func (s *DBStore) Save(session *Session) error {
+	data, err := json.Marshal(session.Values)
+	if err != nil {
+		return err
+	}
+	record := GorimSession{
+		Key: session.ID,
+		Data: string(data),
+		ExpireDate: session.ExpiresAt,
+	}
+	return s.DB.Save(&record).Error
+}
+
+func (s *DBStore) Delete(id string) error {
+	return s.DB.Delete(&GorimSession{Key: id}).Error
+}
+
+// ClearExpired removes expired sessions.
+func (s *DBStore) ClearExpired() error {
+	return s.DB.Where("expire_date <= ?", time.Now()).Delete(&GorimSession{}).Error
+}
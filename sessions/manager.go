@@ -0,0 +1,82 @@
+package sessions
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Manager reads and writes the session cookie.
+type Manager struct {
+	Store		Store
+	CookieName	string
+	MaxAge		time.Duration
+	Path		string
+	Domain		string
+	Secure		bool
+	SameSite	http.SameSite
+}
+
+func NewManager(store Store) *Manager {
+	return &Manager{
+		Store: store,
+		CookieName: "sessionid",
+		MaxAge: 14 * 24 * time.Hour,
+		Path: "/",
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// Get returns the current session, nil if the request has no valid session.
+func (m *Manager) Get(c echo.Context) (*Session, error) {
+	cookie, err := c.Cookie(m.CookieName)
+	if err != nil || cookie.Value == "" {
+		return nil, nil
+	}
+	return m.Store.Load(cookie.Value)
+}
+
+// Create starts a new session with the values and sets the cookie.
+func (m *Manager) Create(c echo.Context, values map[string]interface{}) (*Session, error) {
+	id, err := NewSessionID()
+	if err != nil {
+		return nil, err
+	}
+	session := Session{
+		ID: id,
+		Values: values,
+		ExpiresAt: time.Now().Add(m.MaxAge),
+	}
+	if err := m.Store.Save(&session); err != nil {
+		return nil, err
+	}
+	m.setCookie(c, id, session.ExpiresAt, int(m.MaxAge.Seconds()))
+	return &session, nil
+}
+
+// Destroy deletes the current session and expires the cookie.
+func (m *Manager) Destroy(c echo.Context) error {
+	cookie, err := c.Cookie(m.CookieName)
+	if err == nil && cookie.Value != "" {
+		if err := m.Store.Delete(cookie.Value); err != nil {
+			return err
+		}
+	}
+	m.setCookie(c, "", time.Unix(0, 0), -1)
+	return nil
+}
+
+func (m *Manager) setCookie(c echo.Context, value string, expires time.Time, maxAge int) {
+	c.SetCookie(&http.Cookie{
+		Name: m.CookieName,
+		Value: value,
+		Path: m.Path,
+		Domain: m.Domain,
+		Expires: expires,
+		MaxAge: maxAge,
+		Secure: m.Secure,
+		HttpOnly: true,
+		SameSite: m.SameSite,
+	})
+}
@@ -0,0 +1,82 @@
+package sessions
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type Session struct {
+	ID			string
+	Values		map[string]interface{}
+	ExpiresAt	time.Time
+}
+
+func (s *Session) IsExpired() bool {
+	return time.Now().After(s.ExpiresAt)
+}
+
+// Store is the server-side session storage.
+type Store interface {
+	// Load returns nil session when not found or expired.
+	Load(id string) (*Session, error)
+	Save(session *Session) error
+	Delete(id string) error
+}
+
+// NewSessionID returns a random 64 characters hex id.
+func NewSessionID() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("failed to generate session id: %w", err)
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+// MemoryStore keeps sessions in process memory, suitable for development
+// and single instance deployment.
+type MemoryStore struct {
+	mu			sync.RWMutex
+	sessions	map[string]Session
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		sessions: map[string]Session{},
+	}
+}
+
+func (s *MemoryStore) Load(id string) (*Session, error) {
+	s.mu.RLock()
+	session, ok := s.sessions[id]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, nil
+	}
+	if session.IsExpired() {
+		s.Delete(id)
+		return nil, nil
+	}
+	values := make(map[string]interface{}, len(session.Values))
+	for key, value := range session.Values {
+		values[key] = value
+	}
+	session.Values = values
+	return &session, nil
+}
+
+func (s *MemoryStore) Save(session *Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[session.ID] = *session
+	return nil
+}
+
+func (s *MemoryStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, id)
+	return nil
+}
@@ -0,0 +1,10 @@
+package gorim
+
+import (
+	"github.com/rimba47prayoga/gorim.git/settings"
+)
+
+// Settings returns current settings, load them with settings.Load.
+func Settings() *settings.Settings {
+	return settings.Get()
+}
@@ -0,0 +1,126 @@
+package settings
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// applyEnv sets fields having env tag from lookup, names of nested sections are joined by underscore.
+func applyEnv(settings *Settings, lookup func(string) (string, bool)) error {
+	return applyEnvValue(reflect.ValueOf(settings).Elem(), "", lookup)
+}
+
+func applyEnvValue(value reflect.Value, prefix string, lookup func(string) (string, bool)) error {
+	typ := value.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name := field.Tag.Get("env")
+		if prefix != "" && name != "" {
+			name = prefix + "_" + name
+		} else if name == "" {
+			name = prefix
+		}
+		if field.Type.Kind() == reflect.Struct {
+			if err := applyEnvValue(value.Field(i), name, lookup); err != nil {
+				return err
+			}
+			continue
+		}
+		if field.Tag.Get("env") == "" {
+			continue
+		}
+		raw, ok := lookup(name)
+		if !ok {
+			continue
+		}
+		if err := setString(value.Field(i), raw); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// setString parses raw into field, maps are written as key=value pairs separated by comma
+// and merged into the current map.
+func setString(field reflect.Value, raw string) error {
+	if field.Type() == durationType {
+		duration, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(duration))
+		return nil
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Bool:
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		field.SetBool(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value, err := strconv.ParseInt(raw, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(value)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value, err := strconv.ParseUint(raw, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(value)
+	case reflect.Float32, reflect.Float64:
+		value, err := strconv.ParseFloat(raw, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(value)
+	case reflect.Slice:
+		items := splitList(raw)
+		slice := reflect.MakeSlice(field.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setString(slice.Index(i), item); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+	case reflect.Map:
+		result := field
+		if result.IsNil() {
+			result = reflect.MakeMap(field.Type())
+		}
+		for _, pair := range splitList(raw) {
+			key, item, ok := strings.Cut(pair, "=")
+			if !ok {
+				return fmt.Errorf("expected key=value, got %q", pair)
+			}
+			element := reflect.New(field.Type().Elem()).Elem()
+			if err := setString(element, strings.TrimSpace(item)); err != nil {
+				return err
+			}
+			result.SetMapIndex(reflect.ValueOf(strings.TrimSpace(key)).Convert(field.Type().Key()), element)
+		}
+		field.Set(result)
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
+
+func splitList(raw string) []string {
+	items := []string{}
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
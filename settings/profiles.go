@@ -0,0 +1,76 @@
+package settings
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ProfileEnv names environment variable selecting the profile, example: GORIM_PROFILE=prod
+const ProfileEnv = "GORIM_PROFILE"
+
+const (
+	Dev		= "dev"
+	Staging	= "staging"
+	Prod	= "prod"
+)
+
+// Profile returns profile selected by ProfileEnv, empty when it isn't set, so servers
+// deployed without it never run with debug and any host of Dev.
+func Profile() string {
+	return strings.ToLower(strings.TrimSpace(os.Getenv(ProfileEnv)))
+}
+
+// ProfileDefaults returns Default adjusted for profile: dev enables debug and logs queries,
+// staging logs info, prod logs warnings and is checked by Validate. Custom and empty
+// profiles get Default, which has debug disabled and no allowed hosts.
+func ProfileDefaults(profile string) *Settings {
+	settings := Default()
+	settings.Profile = profile
+	switch profile {
+	case Dev:
+		settings.Debug = true
+		settings.LogLevel = "debug"
+		settings.AllowedHosts = []string{"*"}
+	case Staging:
+		settings.LogLevel = "info"
+	case Prod:
+		settings.LogLevel = "warn"
+	}
+	return settings
+}
+
+// IsProd reports whether settings are of the prod profile.
+func (s *Settings) IsProd() bool {
+	return s.Profile == Prod
+}
+
+// profileFile returns override file of profile for file, example: settings.prod.yaml for settings.yaml.
+func profileFile(file string, profile string) string {
+	ext := filepath.Ext(file)
+	return strings.TrimSuffix(file, ext) + "." + profile + ext
+}
+
+// validateProd refuses settings unsafe in production.
+func validateProd(settings *Settings) error {
+	messages := []string{}
+	if settings.Debug {
+		messages = append(messages, "Debug must be false")
+	}
+	if len(settings.SecretKey) < 32 {
+		messages = append(messages, "SecretKey must have at least 32 characters")
+	}
+	if len(settings.AllowedHosts) == 0 {
+		messages = append(messages, "AllowedHosts is required")
+	}
+	for _, host := range settings.AllowedHosts {
+		if host == "*" {
+			messages = append(messages, "AllowedHosts must not allow every host")
+		}
+	}
+	if len(messages) > 0 {
+		return fmt.Errorf("invalid settings for %s profile: %s", Prod, strings.Join(messages, ", "))
+	}
+	return nil
+}
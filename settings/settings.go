@@ -0,0 +1,358 @@
+package settings
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"gopkg.in/yaml.v3"
+)
+
+// Settings of the framework, loaded by Load from code defaults, then files, then environment.
+// Env tags name environment variables, nested sections prefix names of their fields,
+// example: Database.Host is DB_HOST.
+type Settings struct {
+	// Profile is selected by ProfileEnv, it picks defaults and override files.
+	Profile			string				`yaml:"-"`
+	// Debug adds details of unexpected errors to responses, never enable it in production.
+	Debug			bool				`yaml:"debug" env:"DEBUG"`
+	SecretKey		string				`yaml:"secret_key" env:"SECRET_KEY"`
+	// AllowedHosts are values of Host header served, "*" allows any host and leading dot
+	// allows subdomains, example: [".example.com"]. Empty doesn't check the host.
+	AllowedHosts	[]string			`yaml:"allowed_hosts" env:"ALLOWED_HOSTS"`
+	LogLevel		string				`yaml:"log_level" env:"LOG_LEVEL" validate:"oneof=debug info warn error"`
+	Server			Server				`yaml:"server"`
+	Database		Database			`yaml:"database" env:"DB"`
+	// Databases are connections other than default by alias, see database.AllFromSettings.
+	Databases		map[string]Database	`yaml:"databases"`
+	Auth			Auth				`yaml:"auth" env:"AUTH"`
+	Pagination		Pagination			`yaml:"pagination" env:"PAGINATION"`
+	Throttling		Throttling			`yaml:"throttling" env:"THROTTLE"`
+	Cache			Cache				`yaml:"cache" env:"CACHE"`
+	Tasks			Tasks				`yaml:"tasks" env:"TASKS"`
+	Email			Email				`yaml:"email" env:"EMAIL"`
+	Channels		Channels			`yaml:"channels" env:"CHANNELS"`
+	I18n			I18n				`yaml:"i18n" env:"I18N"`
+}
+
+type Server struct {
+	Host				string			`yaml:"host" env:"HOST" validate:"required"`
+	Port				uint			`yaml:"port" env:"PORT" validate:"required,max=65535"`
+	ReadHeaderTimeout	time.Duration	`yaml:"read_header_timeout" env:"READ_HEADER_TIMEOUT"`
+	ReadTimeout			time.Duration	`yaml:"read_timeout" env:"READ_TIMEOUT"`
+	WriteTimeout		time.Duration	`yaml:"write_timeout" env:"WRITE_TIMEOUT"`
+	IdleTimeout			time.Duration	`yaml:"idle_timeout" env:"IDLE_TIMEOUT"`
+	// MaxBodySize limits request body in bytes, zero disables.
+	MaxBodySize			int64			`yaml:"max_body_size" env:"MAX_BODY_SIZE" validate:"min=0"`
+	// ShutdownTimeout is how long in-flight requests are drained on shutdown.
+	ShutdownTimeout		time.Duration	`yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+}
+
+// Database is used by database.FromSettings, DSN is passed to the dialector as is.
+type Database struct {
+	DSN					string			`yaml:"dsn" env:"DSN"`
+	Name				string			`yaml:"name" env:"NAME"`
+	Host				string			`yaml:"host" env:"HOST"`
+	Port				int				`yaml:"port" env:"PORT"`
+	User				string			`yaml:"user" env:"USER"`
+	Password			string			`yaml:"password" env:"PASSWORD"`
+	MaxOpenConns		int				`yaml:"max_open_conns" env:"MAX_OPEN_CONNS" validate:"min=0"`
+	MaxIdleConns		int				`yaml:"max_idle_conns" env:"MAX_IDLE_CONNS" validate:"min=0"`
+	ConnMaxLifetime		time.Duration	`yaml:"conn_max_lifetime" env:"CONN_MAX_LIFETIME"`
+	// Replica serves reads of safe requests, see database.Config.
+	Replica				bool			`yaml:"replica" env:"REPLICA"`
+}
+
+type Auth struct {
+	// UsernameField is the column identifying users at login.
+	UsernameField			string			`yaml:"username_field" env:"USERNAME_FIELD" validate:"required"`
+	AccessTokenLifetime		time.Duration	`yaml:"access_token_lifetime" env:"ACCESS_TOKEN_LIFETIME" validate:"gt=0"`
+	RefreshTokenLifetime	time.Duration	`yaml:"refresh_token_lifetime" env:"REFRESH_TOKEN_LIFETIME" validate:"gt=0"`
+}
+
+type Pagination struct {
+	PageSize		int		`yaml:"page_size" env:"PAGE_SIZE" validate:"min=1"`
+	// MaxPageSize limits page_size query param, zero doesn't limit.
+	MaxPageSize		int		`yaml:"max_page_size" env:"MAX_PAGE_SIZE" validate:"min=0"`
+}
+
+type Throttling struct {
+	// Rates by scope such as "anon" or "user", example: 100/h
+	Rates		map[string]string	`yaml:"rates" env:"RATES"`
+}
+
+// Cache is used by cache.FromSettings.
+type Cache struct {
+	// URL of redis, example: redis://localhost:6379/0, empty keeps values in memory.
+	URL			string			`yaml:"url" env:"URL"`
+	Prefix		string			`yaml:"prefix" env:"PREFIX"`
+	Version		int				`yaml:"version" env:"VERSION" validate:"min=0"`
+	// TTL of values stored without ttl, zero doesn't expire.
+	TTL			time.Duration	`yaml:"ttl" env:"TTL"`
+}
+
+// Tasks is used by tasks.FromSettings and the worker command.
+type Tasks struct {
+	// Backend of enqueued tasks, "database" and "redis" are shared by processes.
+	Backend		string			`yaml:"backend" env:"BACKEND" validate:"oneof=memory database redis"`
+	// URL of redis for the redis backend.
+	URL			string			`yaml:"url" env:"URL" validate:"required_if=Backend redis"`
+	// Queues run by the worker, earlier queues first, empty runs the default queue.
+	Queues		[]string		`yaml:"queues" env:"QUEUES"`
+	Concurrency	int				`yaml:"concurrency" env:"CONCURRENCY" validate:"min=0"`
+}
+
+// Channels is used by channels.FromSettings.
+type Channels struct {
+	// URL of redis, example: redis://localhost:6379/0, empty fans out within the process.
+	URL			string			`yaml:"url" env:"URL"`
+	Prefix		string			`yaml:"prefix" env:"PREFIX"`
+}
+
+// I18n is used by i18n.FromSettings.
+type I18n struct {
+	// Language of requests selecting no registered language.
+	Language	string		`yaml:"language" env:"LANGUAGE" validate:"required"`
+	// Param is the query param activating a language, example: ?lang=id, empty disables.
+	Param		string		`yaml:"param" env:"PARAM"`
+	// Cookie activating a language, empty disables.
+	Cookie		string		`yaml:"cookie" env:"COOKIE"`
+	// LocaleDir holds catalogs named by language, example: locale/id.json.
+	LocaleDir	string		`yaml:"locale_dir" env:"LOCALE_DIR"`
+}
+
+// Email is used by mail.FromSettings.
+type Email struct {
+	// Backend sending messages, "console" prints them and "memory" keeps them for tests.
+	Backend		string			`yaml:"backend" env:"BACKEND" validate:"oneof=console smtp memory"`
+	Host		string			`yaml:"host" env:"HOST" validate:"required_if=Backend smtp"`
+	Port		int				`yaml:"port" env:"PORT" validate:"min=0,max=65535"`
+	Username	string			`yaml:"username" env:"USERNAME"`
+	Password	string			`yaml:"password" env:"PASSWORD"`
+	// TLS connects with implicit TLS such as port 465, otherwise STARTTLS is used when
+	// the server supports it.
+	TLS			bool			`yaml:"tls" env:"TLS"`
+	Timeout		time.Duration	`yaml:"timeout" env:"TIMEOUT"`
+	// From is the sender of messages without one.
+	From		string			`yaml:"from" env:"FROM"`
+}
+
+// Default returns code defaults of the settings.
+func Default() *Settings {
+	return &Settings{
+		LogLevel: "info",
+		Server: Server{
+			Host: "localhost",
+			Port: 8000,
+			ReadHeaderTimeout: 10 * time.Second,
+			ReadTimeout: 60 * time.Second,
+			IdleTimeout: 120 * time.Second,
+			MaxBodySize: 10 << 20,
+			ShutdownTimeout: 30 * time.Second,
+		},
+		Auth: Auth{
+			UsernameField: "email",
+			AccessTokenLifetime: 5 * time.Minute,
+			RefreshTokenLifetime: 24 * time.Hour,
+		},
+		Pagination: Pagination{
+			PageSize: 10,
+		},
+		Throttling: Throttling{
+			Rates: map[string]string{},
+		},
+		Cache: Cache{
+			Prefix: "gorim",
+			Version: 1,
+		},
+		Tasks: Tasks{
+			Backend: "memory",
+			Concurrency: 4,
+		},
+		Email: Email{
+			Backend: "console",
+			Port: 587,
+			Timeout: 10 * time.Second,
+			From: "webmaster@localhost",
+		},
+		I18n: I18n{
+			Language: "en",
+			LocaleDir: "locale",
+		},
+	}
+}
+
+var (
+	mu			sync.RWMutex
+	current		= Default()
+	sections	= map[string][]yaml.Node{}
+	hooks		[]func(*Settings)
+)
+
+// Get returns current settings, Default until Load or Set.
+func Get() *Settings {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// Set makes settings current and calls OnLoad hooks.
+func Set(settings *Settings) {
+	mu.Lock()
+	current = settings
+	callbacks := append([]func(*Settings){}, hooks...)
+	mu.Unlock()
+	for _, hook := range callbacks {
+		hook(settings)
+	}
+}
+
+// OnLoad registers hook called with settings made current, conf uses it to sync its variables.
+func OnLoad(hook func(*Settings)) {
+	mu.Lock()
+	defer mu.Unlock()
+	hooks = append(hooks, hook)
+}
+
+// Load reads settings from ProfileDefaults of Profile, then YAML or TOML files in order,
+// each followed by override file of the profile when it exists, then environment,
+// validates and makes them current, example: settings.Load("settings.yaml") with
+// GORIM_PROFILE=prod reads settings.yaml then settings.prod.yaml.
+func Load(files ...string) (*Settings, error) {
+	return LoadWith(ProfileDefaults(Profile()), files...)
+}
+
+// LoadWith is Load starting from the given defaults instead of ProfileDefaults,
+// Profile of defaults selects override files.
+func LoadWith(defaults *Settings, files ...string) (*Settings, error) {
+	settings := *defaults
+	settings.AllowedHosts = append([]string{}, defaults.AllowedHosts...)
+	settings.Throttling.Rates = map[string]string{}
+	for scope, rate := range defaults.Throttling.Rates {
+		settings.Throttling.Rates[scope] = rate
+	}
+	settings.Databases = map[string]Database{}
+	for alias, database := range defaults.Databases {
+		settings.Databases[alias] = database
+	}
+	loaded := map[string][]yaml.Node{}
+	for _, file := range files {
+		paths := []string{file}
+		if override := profileFile(file, settings.Profile); settings.Profile != "" && fileExists(override) {
+			paths = append(paths, override)
+		}
+		for _, path := range paths {
+			node, err := readFile(path)
+			if err != nil {
+				return nil, err
+			}
+			if err := node.Decode(&settings); err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			collectSections(node, loaded)
+		}
+	}
+	if err := applyEnv(&settings, os.LookupEnv); err != nil {
+		return nil, err
+	}
+	if err := Validate(&settings); err != nil {
+		return nil, err
+	}
+	mu.Lock()
+	sections = loaded
+	mu.Unlock()
+	Set(&settings)
+	return &settings, nil
+}
+
+// MustLoad is Load panicking on error.
+func MustLoad(files ...string) *Settings {
+	settings, err := Load(files...)
+	if err != nil {
+		panic(err)
+	}
+	return settings
+}
+
+// Validate checks validate tags of the settings, settings of prod profile must also
+// disable debug, have a strong SecretKey and list AllowedHosts.
+func Validate(settings *Settings) error {
+	if err := validator.New().Struct(settings); err != nil {
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			messages := []string{}
+			for _, fieldErr := range errs {
+				messages = append(messages, fmt.Sprintf("%s failed on %s", strings.TrimPrefix(fieldErr.Namespace(), "Settings."), fieldErr.Tag()))
+			}
+			return fmt.Errorf("invalid settings: %s", strings.Join(messages, ", "))
+		}
+		return err
+	}
+	if settings.IsProd() {
+		return validateProd(settings)
+	}
+	return nil
+}
+
+// Section decodes top level section of the loaded files into target in order of the files,
+// so components of the project keep their settings with the framework's, example:
+//
+//	var payments PaymentSettings
+//	settings.Section("payments", &payments)
+func Section(name string, target interface{}) error {
+	mu.RLock()
+	nodes := sections[name]
+	mu.RUnlock()
+	for _, node := range nodes {
+		if err := node.Decode(target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func fileExists(file string) bool {
+	info, err := os.Stat(file)
+	return err == nil && !info.IsDir()
+}
+
+func readFile(file string) (*yaml.Node, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	node := &yaml.Node{}
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, node)
+	case ".toml":
+		var values map[string]interface{}
+		values, err = parseTOML(string(data))
+		if err == nil {
+			err = node.Encode(values)
+		}
+	default:
+		return nil, fmt.Errorf("%s: settings files must be .yaml, .yml or .toml", file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return node, nil
+}
+
+// collectSections appends top level sections of node by key.
+func collectSections(node *yaml.Node, loaded map[string][]yaml.Node) {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i + 1 < len(node.Content); i += 2 {
+		key := node.Content[i].Value
+		loaded[key] = append(loaded[key], *node.Content[i + 1])
+	}
+}
@@ -0,0 +1,128 @@
+package settings
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseTOML parses the subset of TOML used by settings files: [tables], [dotted.tables],
+// key = value pairs of strings, integers, floats, booleans and single line arrays.
+// Durations are strings such as "5m".
+func parseTOML(data string) (map[string]interface{}, error) {
+	root := map[string]interface{}{}
+	table := root
+	for number, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(stripComment(line))
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") || strings.HasPrefix(line, "[[") {
+				return nil, fmt.Errorf("line %d: unsupported table %s", number + 1, line)
+			}
+			table = root
+			for _, name := range strings.Split(strings.Trim(line, "[]"), ".") {
+				name = unquoteKey(name)
+				child, ok := table[name].(map[string]interface{})
+				if !ok {
+					child = map[string]interface{}{}
+					table[name] = child
+				}
+				table = child
+			}
+			continue
+		}
+		key, raw, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value", number + 1)
+		}
+		value, err := parseTOMLValue(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", number + 1, err)
+		}
+		table[unquoteKey(key)] = value
+	}
+	return root, nil
+}
+
+func parseTOMLValue(raw string) (interface{}, error) {
+	switch {
+	case raw == "true" || raw == "false":
+		return raw == "true", nil
+	case strings.HasPrefix(raw, `"`):
+		return strconv.Unquote(raw)
+	case strings.HasPrefix(raw, "'"):
+		if len(raw) < 2 || !strings.HasSuffix(raw, "'") {
+			return nil, fmt.Errorf("unterminated string %s", raw)
+		}
+		return raw[1:len(raw) - 1], nil
+	case strings.HasPrefix(raw, "["):
+		if !strings.HasSuffix(raw, "]") {
+			return nil, fmt.Errorf("arrays must be on one line")
+		}
+		items := []interface{}{}
+		for _, item := range splitArray(strings.TrimSpace(raw[1:len(raw) - 1])) {
+			value, err := parseTOMLValue(item)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+		}
+		return items, nil
+	}
+	number := strings.ReplaceAll(raw, "_", "")
+	if value, err := strconv.ParseInt(number, 0, 64); err == nil {
+		return value, nil
+	}
+	if value, err := strconv.ParseFloat(number, 64); err == nil {
+		return value, nil
+	}
+	return nil, fmt.Errorf("unsupported value %s", raw)
+}
+
+// splitArray splits items of array by comma outside of strings.
+func splitArray(raw string) []string {
+	items := []string{}
+	quote := rune(0)
+	start := 0
+	for i, char := range raw {
+		switch {
+		case quote != 0 && char == quote:
+			quote = 0
+		case quote == 0 && (char == '"' || char == '\''):
+			quote = char
+		case quote == 0 && char == ',':
+			items = append(items, strings.TrimSpace(raw[start:i]))
+			start = i + 1
+		}
+	}
+	if last := strings.TrimSpace(raw[start:]); last != "" {
+		items = append(items, last)
+	}
+	return items
+}
+
+// stripComment removes # comment outside of strings.
+func stripComment(line string) string {
+	quote := rune(0)
+	for i, char := range line {
+		switch {
+		case quote != 0 && char == quote:
+			quote = 0
+		case quote == 0 && (char == '"' || char == '\''):
+			quote = char
+		case quote == 0 && char == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func unquoteKey(key string) string {
+	key = strings.TrimSpace(key)
+	if unquoted, err := strconv.Unquote(key); err == nil {
+		return unquoted
+	}
+	return strings.Trim(key, "'")
+}
@@ -0,0 +1,65 @@
+package signals
+
+import (
+	"errors"
+	"reflect"
+
+	"gorm.io/gorm"
+)
+
+// changeEvent is sent by GormPlugin, which knows the model only at runtime.
+type changeEvent struct {
+	signal		Signal
+	instance	any
+}
+
+// GormPlugin sends PostChange after successful creates, updates and deletes of models
+// having receivers, example: conf.DB.Use(signals.GormPlugin{})
+type GormPlugin struct{}
+
+func (p GormPlugin) Name() string {
+	return "gorim:signals"
+}
+
+func (p GormPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	return errors.Join(
+		callback.Create().After("gorm:create").Register("gorim:signals_after_create", sendChange),
+		callback.Update().After("gorm:update").Register("gorim:signals_after_update", sendChange),
+		callback.Delete().After("gorm:delete").Register("gorim:signals_after_delete", sendChange),
+	)
+}
+
+func sendChange(db *gorm.DB) {
+	if db.Error != nil || db.RowsAffected == 0 || db.Statement.Schema == nil {
+		return
+	}
+	key := receiverKey{signal: PostChange, model: db.Statement.Schema.ModelType}
+	receiversMu.RLock()
+	handlers := receivers[key]
+	receiversMu.RUnlock()
+	if len(handlers) == 0 {
+		return
+	}
+	event := changeEvent{signal: PostChange, instance: changedInstance(db)}
+	for _, handler := range handlers {
+		handler(event)
+	}
+}
+
+// changedInstance returns pointer to the instance of the statement when it changed one
+// row identified by primary key, nil for bulk statements.
+func changedInstance(db *gorm.DB) any {
+	value := db.Statement.ReflectValue
+	if value.Kind() != reflect.Struct || !value.CanAddr() || db.RowsAffected != 1 {
+		return nil
+	}
+	field := db.Statement.Schema.PrioritizedPrimaryField
+	if field == nil {
+		return nil
+	}
+	if _, zero := field.ValueOf(db.Statement.Context, value); zero {
+		return nil
+	}
+	return value.Addr().Interface()
+}
@@ -0,0 +1,99 @@
+package signals
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+)
+
+type Signal int
+
+const (
+	PreSave Signal = iota
+	PostSave
+	PreDelete
+	PostDelete
+	// PostChange is sent by GormPlugin after rows are created, updated or deleted through
+	// gorm, including bulk updates and deletes which don't send the other signals.
+	PostChange
+)
+
+func (s Signal) String() string {
+	switch s {
+	case PreSave:
+		return "pre_save"
+	case PostSave:
+		return "post_save"
+	case PreDelete:
+		return "pre_delete"
+	case PostDelete:
+		return "post_delete"
+	case PostChange:
+		return "post_change"
+	}
+	return "unknown"
+}
+
+// Event is sent to handlers of model T, Context is nil outside of requests.
+// Instance is nil for PostChange of statements changing many rows.
+type Event[T any] struct {
+	Signal		Signal
+	Instance	*T
+	// Created is true for save signals of newly created instance.
+	Created		bool
+	Context		echo.Context
+}
+
+type receiverKey struct {
+	signal		Signal
+	model		reflect.Type
+}
+
+var (
+	receiversMu	sync.RWMutex
+	receivers	= map[receiverKey][]func(any){}
+)
+
+// Connect registers handler of signal for model T, handlers run in the request and in the
+// order they're connected, pre signal handlers can abort with errors.Raise, example:
+//
+//	signals.Connect(signals.PostSave, func(event signals.Event[Product]) {
+//		cache.Delete("product:" + fmt.Sprint(event.Instance.ID))
+//	})
+func Connect[T any](signal Signal, handler func(Event[T])) {
+	key := receiverKey{signal: signal, model: reflect.TypeOf((*T)(nil)).Elem()}
+	receiversMu.Lock()
+	defer receiversMu.Unlock()
+	receivers[key] = append(receivers[key], func(event any) {
+		if change, ok := event.(changeEvent); ok {
+			instance, _ := change.instance.(*T)
+			handler(Event[T]{Signal: change.signal, Instance: instance})
+			return
+		}
+		handler(event.(Event[T]))
+	})
+}
+
+// Disconnect removes all handlers of signal for model T.
+func Disconnect[T any](signal Signal) {
+	key := receiverKey{signal: signal, model: reflect.TypeOf((*T)(nil)).Elem()}
+	receiversMu.Lock()
+	defer receiversMu.Unlock()
+	delete(receivers, key)
+}
+
+// Send calls handlers of signal for model of instance.
+func Send[T any](signal Signal, c echo.Context, instance *T, created bool) {
+	key := receiverKey{signal: signal, model: reflect.TypeOf((*T)(nil)).Elem()}
+	receiversMu.RLock()
+	handlers := receivers[key]
+	receiversMu.RUnlock()
+	if len(handlers) == 0 {
+		return
+	}
+	event := Event[T]{Signal: signal, Instance: instance, Created: created, Context: c}
+	for _, handler := range handlers {
+		handler(event)
+	}
+}
@@ -0,0 +1,51 @@
+package signing
+
+import (
+	"github.com/rimba47prayoga/gorim.git"
+)
+
+// HasSignedToken allows requests carrying a valid token minted by MakeToken,
+// read from `token` query param or `X-Signed-Token` header.
+type HasSignedToken struct {
+	// Scope defaults to the route path, example: /api/v1/users/:pk/confirm
+	Scope			string
+	// ObjectParam is the path param compared to token object, example: "pk".
+	ObjectParam		string
+	QueryParam		string
+}
+
+func (p *HasSignedToken) GetToken(ctx gorim.Context) string {
+	queryParam := p.QueryParam
+	if queryParam == "" {
+		queryParam = "token"
+	}
+	if token := ctx.QueryParam(queryParam); token != "" {
+		return token
+	}
+	return ctx.Request().Header.Get("X-Signed-Token")
+}
+
+func (p *HasSignedToken) HasPermission(ctx gorim.Context) bool {
+	token := p.GetToken(ctx)
+	if token == "" {
+		return false
+	}
+	scope := p.Scope
+	if scope == "" {
+		scope = ctx.Path()
+	}
+	object := ""
+	if p.ObjectParam != "" {
+		object = ctx.Param(p.ObjectParam)
+	}
+	_, err := VerifyToken(token, scope, object)
+	return err == nil
+}
+
+func (p *HasSignedToken) GetMessage() string {
+	return "Invalid or expired token."
+}
+
+func (p *HasSignedToken) GetCode() string {
+	return "invalid_signed_token"
+}
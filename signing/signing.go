@@ -0,0 +1,108 @@
+package signing
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rimba47prayoga/gorim.git/conf"
+)
+
+var ErrBadSignature = fmt.Errorf("bad signature")
+var ErrExpired = fmt.Errorf("signature expired")
+
+// Signer signs values with HMAC-SHA256, Salt separates different usages of the same key.
+type Signer struct {
+	Key		[]byte
+	Salt	string
+}
+
+// NewSigner uses conf.SECRET_KEY.
+func NewSigner(salt string) *Signer {
+	return &Signer{
+		Key: []byte(conf.SECRET_KEY),
+		Salt: salt,
+	}
+}
+
+func (s *Signer) signature(value string) string {
+	if len(s.Key) == 0 {
+		panic("signing: empty key, set conf.SECRET_KEY")
+	}
+	mac := hmac.New(sha256.New, s.Key)
+	mac.Write([]byte(s.Salt + ":" + value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Sign returns "<value>.<signature>", value must not contain "." when used with Unsign.
+func (s *Signer) Sign(value string) string {
+	return value + "." + s.signature(value)
+}
+
+func (s *Signer) Unsign(signed string) (string, error) {
+	index := strings.LastIndex(signed, ".")
+	if index < 0 {
+		return "", ErrBadSignature
+	}
+	value, signature := signed[:index], signed[index+1:]
+	if !hmac.Equal([]byte(signature), []byte(s.signature(value))) {
+		return "", ErrBadSignature
+	}
+	return value, nil
+}
+
+// AccessToken grants access to Scope (usually a route) and optional Object.
+type AccessToken struct {
+	Scope		string		`json:"s"`
+	Object		string		`json:"o,omitempty"`
+	ExpiresAt	int64		`json:"e"`
+}
+
+// MakeToken mints url-safe token valid for ttl,
+// example: MakeToken("confirm-email", fmt.Sprint(user.ID), 24*time.Hour)
+func (s *Signer) MakeToken(scope string, object string, ttl time.Duration) string {
+	payload, _ := json.Marshal(AccessToken{
+		Scope: scope,
+		Object: object,
+		ExpiresAt: time.Now().Add(ttl).Unix(),
+	})
+	return s.Sign(base64.RawURLEncoding.EncodeToString(payload))
+}
+
+// VerifyToken checks signature, expiry, scope and object of the token.
+func (s *Signer) VerifyToken(token string, scope string, object string) (*AccessToken, error) {
+	value, err := s.Unsign(token)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrBadSignature
+	}
+	var accessToken AccessToken
+	if err := json.Unmarshal(payload, &accessToken); err != nil {
+		return nil, ErrBadSignature
+	}
+	if time.Now().Unix() > accessToken.ExpiresAt {
+		return nil, ErrExpired
+	}
+	if accessToken.Scope != scope || accessToken.Object != object {
+		return nil, ErrBadSignature
+	}
+	return &accessToken, nil
+}
+
+const accessTokenSalt = "gorim.signing.access_token"
+
+// MakeToken mints access token signed by conf.SECRET_KEY.
+func MakeToken(scope string, object string, ttl time.Duration) string {
+	return NewSigner(accessTokenSalt).MakeToken(scope, object, ttl)
+}
+
+func VerifyToken(token string, scope string, object string) (*AccessToken, error) {
+	return NewSigner(accessTokenSalt).VerifyToken(token, scope, object)
+}
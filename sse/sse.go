@@ -0,0 +1,293 @@
+package sse
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rimba47prayoga/gorim.git/errors"
+	"github.com/rimba47prayoga/gorim.git/renderers"
+)
+
+// ErrClosed is the cause of streams closed by CloseAll.
+var ErrClosed = stderrors.New("sse: stream closed by server")
+
+// Event is a server-sent event, Data is written as is when it's string or []byte,
+// otherwise as json. Clients reconnect with the ID of the last event they received.
+type Event struct {
+	ID		string
+	Event	string
+	Data	any
+	// Retry tells clients how long to wait before reconnecting.
+	Retry	time.Duration
+}
+
+// Bytes returns the event in text/event-stream format.
+func (e Event) Bytes() ([]byte, error) {
+	var buffer bytes.Buffer
+	if e.ID != "" {
+		buffer.WriteString("id: " + singleLine(e.ID) + "\n")
+	}
+	if e.Event != "" {
+		buffer.WriteString("event: " + singleLine(e.Event) + "\n")
+	}
+	if e.Retry > 0 {
+		buffer.WriteString("retry: " + strconv.FormatInt(e.Retry.Milliseconds(), 10) + "\n")
+	}
+	var data string
+	switch value := e.Data.(type) {
+	case nil:
+	case string:
+		data = value
+	case []byte:
+		data = string(value)
+	default:
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		data = string(encoded)
+	}
+	if e.Data != nil {
+		for _, line := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+			buffer.WriteString("data: " + line + "\n")
+		}
+	}
+	buffer.WriteString("\n")
+	return buffer.Bytes(), nil
+}
+
+// singleLine drops line breaks which would end fields of the event.
+func singleLine(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
+
+type Options struct {
+	// KeepAlive is the interval of comments sent while no event is, so proxies don't
+	// close idle streams, default 15 seconds.
+	KeepAlive		time.Duration
+	// Retry is sent before the first event, zero keeps the reconnect delay of clients.
+	Retry			time.Duration
+	// BufferSize is how many events wait for a slow client, default 16.
+	BufferSize		int
+	// WriteTimeout of an event, clients not reading in time are disconnected, default
+	// 10 seconds.
+	WriteTimeout	time.Duration
+}
+
+func (o Options) GetKeepAlive() time.Duration {
+	if o.KeepAlive > 0 {
+		return o.KeepAlive
+	}
+	return 15 * time.Second
+}
+
+func (o Options) GetBufferSize() int {
+	if o.BufferSize > 0 {
+		return o.BufferSize
+	}
+	return 16
+}
+
+func (o Options) GetWriteTimeout() time.Duration {
+	if o.WriteTimeout > 0 {
+		return o.WriteTimeout
+	}
+	return 10 * time.Second
+}
+
+// Stream queues events written to the client by its own goroutine, so producers wait
+// for slow clients only as long as the buffer is full.
+type Stream struct {
+	// LastEventID is sent by reconnecting clients, resume after it.
+	LastEventID	string
+	queue		chan Event
+	ctx			context.Context
+	cancel		context.CancelCauseFunc
+	dropped		atomic.Int64
+}
+
+// Context is done when the client disconnects, a write fails or the server shuts down.
+func (s *Stream) Context() context.Context {
+	return s.ctx
+}
+
+// Send queues event, it waits while the buffer is full and fails when the stream ended.
+func (s *Stream) Send(event Event) error {
+	select {
+	case <-s.ctx.Done():
+		return context.Cause(s.ctx)
+	default:
+	}
+	select {
+	case s.queue <- event:
+		return nil
+	case <-s.ctx.Done():
+		return context.Cause(s.ctx)
+	}
+}
+
+// TrySend queues event unless the buffer is full, for broadcasts which mustn't wait
+// for one client. Events not queued are counted by Dropped.
+func (s *Stream) TrySend(event Event) bool {
+	if s.ctx.Err() != nil {
+		return false
+	}
+	select {
+	case s.queue <- event:
+		return true
+	default:
+		s.dropped.Add(1)
+		return false
+	}
+}
+
+// Dropped returns how many events TrySend didn't queue.
+func (s *Stream) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+var (
+	streamsMu	sync.Mutex
+	streams		= map[*Stream]struct{}{}
+)
+
+// CloseAll ends open streams with ErrClosed, gorim.Server calls it when shutdown starts
+// so streams don't hold back draining of requests.
+func CloseAll() {
+	streamsMu.Lock()
+	defer streamsMu.Unlock()
+	for stream := range streams {
+		stream.cancel(ErrClosed)
+	}
+}
+
+// Serve writes the header of an event stream and calls handler, queued events are
+// written until handler returns and the queue is empty or the stream ended. Errors of
+// handler are written as "error" event since the status is already sent, example:
+//
+//	return sse.Serve(w, r, sse.Options{}, func(stream *sse.Stream) error {
+//		for update := range updates(stream.Context(), stream.LastEventID) {
+//			if err := stream.Send(sse.Event{ID: update.ID, Event: "update", Data: update}); err != nil {
+//				return err
+//			}
+//		}
+//		return nil
+//	})
+func Serve(w http.ResponseWriter, r *http.Request, options Options, handler func(*Stream) error) error {
+	controller := http.NewResponseController(w)
+	header := w.Header()
+	header.Set("Content-Type", renderers.MIMETextEventStream)
+	header.Set("Cache-Control", "no-cache")
+	// disables proxy buffering, example: nginx.
+	header.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if options.Retry > 0 {
+		io.WriteString(w, "retry: " + strconv.FormatInt(options.Retry.Milliseconds(), 10) + "\n\n")
+	}
+	if err := controller.Flush(); err != nil {
+		return err
+	}
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		// EventSource polyfills send it as query param.
+		lastEventID = r.URL.Query().Get("lastEventId")
+	}
+	ctx, cancel := context.WithCancelCause(r.Context())
+	defer cancel(nil)
+	stream := &Stream{
+		LastEventID: lastEventID,
+		queue: make(chan Event, options.GetBufferSize()),
+		ctx: ctx,
+		cancel: cancel,
+	}
+	streamsMu.Lock()
+	streams[stream] = struct{}{}
+	streamsMu.Unlock()
+	defer func() {
+		streamsMu.Lock()
+		delete(streams, stream)
+		streamsMu.Unlock()
+	}()
+
+	finished := make(chan struct{})
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		write(w, controller, stream, options, finished)
+	}()
+	err := handler(stream)
+	if err != nil && ctx.Err() == nil {
+		status, body := errors.Resolve(err)
+		if status >= http.StatusInternalServerError {
+			slog.Error("sse: stream failed", "path", r.URL.Path, "error", err)
+		}
+		stream.Send(Event{Event: "error", Data: body})
+	}
+	close(finished)
+	<-written
+	// the connection may serve more requests.
+	controller.SetWriteDeadline(time.Time{})
+	return nil
+}
+
+// write writes queued events and keep-alives until the stream ends, or finished is
+// closed and the queue is empty.
+func write(w http.ResponseWriter, controller *http.ResponseController, stream *Stream, options Options, finished chan struct{}) {
+	ticker := time.NewTicker(options.GetKeepAlive())
+	defer ticker.Stop()
+	send := func(data []byte) bool {
+		// not every writer supports deadlines, then writes wait for the client.
+		controller.SetWriteDeadline(time.Now().Add(options.GetWriteTimeout()))
+		_, err := w.Write(data)
+		if err == nil {
+			err = controller.Flush()
+		}
+		if err != nil {
+			stream.cancel(err)
+			return false
+		}
+		return true
+	}
+	for {
+		select {
+		case <-stream.ctx.Done():
+			return
+		case event := <-stream.queue:
+			data, err := event.Bytes()
+			if err != nil {
+				slog.Error("sse: encoding event failed", "event", event.Event, "error", err)
+				continue
+			}
+			if !send(data) {
+				return
+			}
+			ticker.Reset(options.GetKeepAlive())
+		case <-ticker.C:
+			if !send([]byte(": keep-alive\n\n")) {
+				return
+			}
+		case <-finished:
+			for {
+				select {
+				case event := <-stream.queue:
+					if data, err := event.Bytes(); err == nil && !send(data) {
+						return
+					}
+				default:
+					return
+				}
+			}
+		}
+	}
+}
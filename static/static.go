@@ -0,0 +1,192 @@
+package static
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/errors"
+	"github.com/rimba47prayoga/gorim.git/conf"
+	"github.com/rimba47prayoga/gorim.git/storages"
+)
+
+// Config serves files under Root at URL Prefix.
+type Config struct {
+	Root		string
+	Prefix		string
+	// MaxAge sets Cache-Control max-age, zero means "no-cache".
+	MaxAge		time.Duration
+	// Index is served for directory requests, empty disables directories.
+	Index		string
+	// Attachment serves files other than images as downloads, so uploaded html isn't
+	// rendered by browsers in the site origin.
+	Attachment	bool
+}
+
+// StaticConfig returns config from conf.STATIC_ROOT and conf.STATIC_URL.
+func StaticConfig() Config {
+	return Config{
+		Root: conf.STATIC_ROOT,
+		Prefix: conf.STATIC_URL,
+		MaxAge: 24 * time.Hour,
+		Index: "index.html",
+	}
+}
+
+// MediaConfig returns config from conf.MEDIA_ROOT and conf.MEDIA_URL,
+// uploaded files may change so they are not cached.
+func MediaConfig() Config {
+	return Config{
+		Root: conf.MEDIA_ROOT,
+		Prefix: conf.MEDIA_URL,
+		Attachment: true,
+	}
+}
+
+// Handler serves files from config.Root, the file path is taken from "*" param.
+// Range and conditional requests are handled by http.ServeContent, content types
+// aren't sniffed by browsers.
+func Handler(config Config) gorim.HandlerFunc {
+	return func(c gorim.Context) error {
+		// path.Clean on rooted path removes any "..", so it can't escape Root.
+		name := path.Clean("/" + c.Param("*"))
+		fullPath := filepath.Join(config.Root, filepath.FromSlash(name))
+		file, err := os.Open(fullPath)
+		if err != nil {
+			return notFound(c)
+		}
+		defer file.Close()
+		info, err := file.Stat()
+		if err != nil {
+			return notFound(c)
+		}
+		if info.IsDir() {
+			if config.Index == "" {
+				return notFound(c)
+			}
+			file.Close()
+			fullPath = filepath.Join(fullPath, config.Index)
+			file, err = os.Open(fullPath)
+			if err != nil {
+				return notFound(c)
+			}
+			defer file.Close()
+			info, err = file.Stat()
+			if err != nil || info.IsDir() {
+				return notFound(c)
+			}
+		}
+		header := c.Response().Header()
+		if config.MaxAge > 0 {
+			header.Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(config.MaxAge.Seconds())))
+		} else {
+			header.Set("Cache-Control", "no-cache")
+		}
+		header.Set("ETag", fmt.Sprintf(`W/"%x-%x"`, info.ModTime().UnixNano(), info.Size()))
+		header.Set("X-Content-Type-Options", "nosniff")
+		if config.Attachment && !isImage(info.Name()) {
+			header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": info.Name()}))
+		}
+		http.ServeContent(c.Response(), c.Request(), info.Name(), info.ModTime(), file)
+		return nil
+	}
+}
+
+// isImage reports whether name has extension of an image, svg isn't since it may run scripts.
+func isImage(name string) bool {
+	contentType := mime.TypeByExtension(filepath.Ext(name))
+	return strings.HasPrefix(contentType, "image/") && !strings.HasPrefix(contentType, "image/svg")
+}
+
+// Serve registers GET and HEAD routes for config on server.
+func Serve(server *gorim.Server, config Config, middleware ...echo.MiddlewareFunc) {
+	pattern := strings.TrimSuffix(config.Prefix, "/") + "/*"
+	handler := Handler(config)
+	server.GET(pattern, handler, middleware...)
+	server.AddRoute(http.MethodHead, pattern, handler, middleware...)
+}
+
+// ServeStatic serves conf.STATIC_ROOT at conf.STATIC_URL.
+func ServeStatic(server *gorim.Server, middleware ...echo.MiddlewareFunc) {
+	Serve(server, StaticConfig(), middleware...)
+}
+
+// ServeMedia serves storages.Default() at conf.MEDIA_URL, by default files of
+// conf.MEDIA_ROOT, see ServeStorage.
+func ServeMedia(server *gorim.Server, middleware ...echo.MiddlewareFunc) {
+	ServeStorage(server, StorageConfig{Storage: storages.Default(), Prefix: conf.MEDIA_URL}, middleware...)
+}
+
+// MediaURL returns URL of uploaded file name in storages.Default(),
+// example: "avatars/1.png" => "/media/avatars/1.png".
+func MediaURL(name string) string {
+	return storages.Default().URL(filepath.ToSlash(name))
+}
+
+// StorageConfig serves files of Storage at URL Prefix.
+type StorageConfig struct {
+	Storage		storages.Storage
+	Prefix		string
+	// Signed requires token of storages.MakeToken, as sent by SignedURL of
+	// storages.FileSystemStorage, example: private uploads. Requests of remote storages
+	// are checked before they're redirected.
+	Signed		bool
+	// Expires of signed URLs requests of remote storages are redirected to, default 1 hour.
+	Expires		time.Duration
+}
+
+// StorageHandler serves files of storages.FileSystemStorage from the disk like Handler,
+// requests of files in remote storages such as S3 are redirected to their signed URL.
+func StorageHandler(config StorageConfig) gorim.HandlerFunc {
+	handler := redirectHandler(config)
+	if fileSystem, ok := config.Storage.(*storages.FileSystemStorage); ok {
+		handler = Handler(Config{Root: fileSystem.GetLocation(), Prefix: config.Prefix, Attachment: true})
+	}
+	if !config.Signed {
+		return handler
+	}
+	return func(c gorim.Context) error {
+		if storages.VerifyToken(c.Param("*"), c.QueryParam("token")) != nil {
+			return notFound(c)
+		}
+		return handler(c)
+	}
+}
+
+// redirectHandler redirects requests of files in remote storages to their signed URL.
+func redirectHandler(config StorageConfig) gorim.HandlerFunc {
+	expires := config.Expires
+	if expires == 0 {
+		expires = time.Hour
+	}
+	return func(c gorim.Context) error {
+		name := storages.CleanName(c.Param("*"))
+		target, err := config.Storage.SignedURL(c.Request().Context(), name, expires)
+		if err != nil {
+			return errors.Handle(err, c)
+		}
+		// the redirect is cached shorter than the signature is valid.
+		c.Response().Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d", int(expires.Seconds()) / 2))
+		return c.Redirect(http.StatusFound, target)
+	}
+}
+
+// ServeStorage registers GET and HEAD routes for config on server,
+// example: static.ServeStorage(server, static.StorageConfig{Storage: storages.Get("private"), Prefix: "/private/", Signed: true})
+func ServeStorage(server *gorim.Server, config StorageConfig, middleware ...echo.MiddlewareFunc) {
+	pattern := strings.TrimSuffix(config.Prefix, "/") + "/*"
+	handler := StorageHandler(config)
+	server.GET(pattern, handler, middleware...)
+	server.AddRoute(http.MethodHead, pattern, handler, middleware...)
+}
+
+func notFound(c gorim.Context) error {
+	return errors.Handle(&errors.ObjectNotFoundError{Message: "Not found."}, c)
+}
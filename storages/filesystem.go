@@ -0,0 +1,123 @@
+package storages
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rimba47prayoga/gorim.git/conf"
+	"github.com/rimba47prayoga/gorim.git/signing"
+)
+
+// FileSystemStorage keeps files in a directory of the local disk, served by
+// static.ServeStorage. Signed URLs carry a token verified by VerifyToken.
+type FileSystemStorage struct {
+	// Location of files, default conf.MEDIA_ROOT.
+	Location	string
+	// BaseURL files are served at, default conf.MEDIA_URL.
+	BaseURL		string
+}
+
+func (s *FileSystemStorage) GetLocation() string {
+	if s.Location != "" {
+		return s.Location
+	}
+	return conf.MEDIA_ROOT
+}
+
+func (s *FileSystemStorage) GetBaseURL() string {
+	if s.BaseURL != "" {
+		return s.BaseURL
+	}
+	return conf.MEDIA_URL
+}
+
+// Path returns path of name on the disk.
+func (s *FileSystemStorage) Path(name string) string {
+	return filepath.Join(s.GetLocation(), filepath.FromSlash(CleanName(name)))
+}
+
+func (s *FileSystemStorage) Save(ctx context.Context, name string, content io.Reader) (string, error) {
+	name = CleanName(name)
+	if err := os.MkdirAll(filepath.Dir(s.Path(name)), 0o755); err != nil {
+		return "", err
+	}
+	candidate := name
+	for {
+		// O_EXCL claims the name, so concurrent saves of the same name don't overwrite.
+		file, err := os.OpenFile(s.Path(candidate), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if errors.Is(err, fs.ErrExist) {
+			candidate = alternateName(name)
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		_, err = io.Copy(file, content)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(file.Name())
+			return "", err
+		}
+		return candidate, nil
+	}
+}
+
+func (s *FileSystemStorage) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	return os.Open(s.Path(name))
+}
+
+func (s *FileSystemStorage) Delete(ctx context.Context, name string) error {
+	if err := os.Remove(s.Path(name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *FileSystemStorage) Exists(ctx context.Context, name string) (bool, error) {
+	_, err := os.Stat(s.Path(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *FileSystemStorage) URL(name string) string {
+	return strings.TrimSuffix(s.GetBaseURL(), "/") + "/" + escapePath(CleanName(name), url.PathEscape)
+}
+
+const signedURLScope = "gorim.storages"
+
+// SignedURL returns URL with token query param, conf.SECRET_KEY signs it.
+func (s *FileSystemStorage) SignedURL(ctx context.Context, name string, expires time.Duration) (string, error) {
+	return s.URL(name) + "?token=" + MakeToken(name, expires), nil
+}
+
+// MakeToken returns token granting access to name until it expires, as sent by SignedURL
+// of FileSystemStorage, example: links to signed routes of static.ServeStorage for remote storages.
+func MakeToken(name string, expires time.Duration) string {
+	return signing.MakeToken(signedURLScope, CleanName(name), expires)
+}
+
+// VerifyToken checks token of SignedURL of FileSystemStorage grants access to name.
+func VerifyToken(name string, token string) error {
+	_, err := signing.VerifyToken(token, signedURLScope, CleanName(name))
+	return err
+}
+
+// escapePath escapes segments of name, keeping slashes.
+func escapePath(name string, escape func(string) string) string {
+	segments := strings.Split(name, "/")
+	for i, segment := range segments {
+		segments[i] = escape(segment)
+	}
+	return strings.Join(segments, "/")
+}
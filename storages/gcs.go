@@ -0,0 +1,71 @@
+package storages
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// GCSStorage keeps files in a bucket of Google Cloud Storage through the XML API,
+// authenticated by HMAC keys of a service account with GOOG4-HMAC-SHA256 signatures.
+type GCSStorage struct {
+	Bucket			string
+	// AccessKeyID and SecretAccessKey of the HMAC key.
+	AccessKeyID		string
+	SecretAccessKey	string
+	// Prefix of object names, example: "media/".
+	Prefix			string
+	// BaseURL of public files, example: CDN, default "https://storage.googleapis.com/<bucket>".
+	BaseURL			string
+	// ACL of saved objects, example: "public-read".
+	ACL				string
+	// Endpoint of the XML API, default "https://storage.googleapis.com".
+	Endpoint		string
+	HTTPClient		*http.Client
+}
+
+func (s *GCSStorage) client() *S3Storage {
+	endpoint := s.Endpoint
+	if endpoint == "" {
+		endpoint = "https://storage.googleapis.com"
+	}
+	return &S3Storage{
+		Bucket: s.Bucket,
+		Region: "auto",
+		Endpoint: endpoint,
+		AccessKeyID: s.AccessKeyID,
+		SecretAccessKey: s.SecretAccessKey,
+		PathStyle: true,
+		Prefix: s.Prefix,
+		BaseURL: s.BaseURL,
+		ACL: s.ACL,
+		HTTPClient: s.HTTPClient,
+		service: "storage",
+		scheme: "GOOG4",
+	}
+}
+
+func (s *GCSStorage) Save(ctx context.Context, name string, content io.Reader) (string, error) {
+	return s.client().Save(ctx, name, content)
+}
+
+func (s *GCSStorage) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	return s.client().Open(ctx, name)
+}
+
+func (s *GCSStorage) Delete(ctx context.Context, name string) error {
+	return s.client().Delete(ctx, name)
+}
+
+func (s *GCSStorage) Exists(ctx context.Context, name string) (bool, error) {
+	return s.client().Exists(ctx, name)
+}
+
+func (s *GCSStorage) URL(name string) string {
+	return s.client().URL(name)
+}
+
+func (s *GCSStorage) SignedURL(ctx context.Context, name string, expires time.Duration) (string, error) {
+	return s.client().SignedURL(ctx, name, expires)
+}
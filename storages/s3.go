@@ -0,0 +1,347 @@
+package storages
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeNow is replaced by tests of signatures.
+var timeNow = time.Now
+
+// S3Storage keeps files in a bucket of S3 or S3 compatible storages such as MinIO,
+// requests are signed with AWS Signature Version 4.
+type S3Storage struct {
+	Bucket			string
+	// Region of the bucket, default "us-east-1".
+	Region			string
+	// Endpoint of the API, default "https://s3.<region>.amazonaws.com".
+	Endpoint		string
+	AccessKeyID		string
+	SecretAccessKey	string
+	// SessionToken of temporary credentials.
+	SessionToken	string
+	// PathStyle addresses the bucket in the path instead of the host, example: MinIO.
+	PathStyle		bool
+	// Prefix of object keys, example: "media/".
+	Prefix			string
+	// BaseURL of public files, example: CDN, default URL of the object.
+	BaseURL			string
+	// ACL of saved objects, example: "public-read".
+	ACL				string
+	HTTPClient		*http.Client
+	// service signed, "s3", and scheme of signatures, AWS4 unless set by GCSStorage.
+	service			string
+	scheme			string
+}
+
+func (s *S3Storage) GetRegion() string {
+	if s.Region != "" {
+		return s.Region
+	}
+	return "us-east-1"
+}
+
+func (s *S3Storage) GetEndpoint() string {
+	if s.Endpoint != "" {
+		return strings.TrimSuffix(s.Endpoint, "/")
+	}
+	return "https://s3." + s.GetRegion() + ".amazonaws.com"
+}
+
+func (s *S3Storage) getHTTPClient() *http.Client {
+	if s.HTTPClient == nil {
+		return &http.Client{Timeout: 60 * time.Second}
+	}
+	return s.HTTPClient
+}
+
+func (s *S3Storage) key(name string) string {
+	return s.Prefix + CleanName(name)
+}
+
+// objectURL returns URL of name, the path is escaped as signatures expect.
+func (s *S3Storage) objectURL(name string) string {
+	key := escapePath(s.key(name), awsEscape)
+	endpoint := s.GetEndpoint()
+	if s.PathStyle {
+		return endpoint + "/" + awsEscape(s.Bucket) + "/" + key
+	}
+	scheme, host, _ := strings.Cut(endpoint, "://")
+	return scheme + "://" + s.Bucket + "." + host + "/" + key
+}
+
+func (s *S3Storage) Save(ctx context.Context, name string, content io.Reader) (string, error) {
+	name, err := availableName(ctx, s, CleanName(name))
+	if err != nil {
+		return "", err
+	}
+	body, size, err := sizedReader(content)
+	if err != nil {
+		return "", err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(name), body)
+	if err != nil {
+		return "", err
+	}
+	request.ContentLength = size
+	if contentType := mime.TypeByExtension(path.Ext(name)); contentType != "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+	if s.ACL != "" {
+		request.Header.Set(s.header("acl"), s.ACL)
+	}
+	if _, err := s.do(request); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// sizedReader returns content with its size, content which can't seek is read into memory.
+func sizedReader(content io.Reader) (io.Reader, int64, error) {
+	if seeker, ok := content.(io.ReadSeeker); ok {
+		current, err := seeker.Seek(0, io.SeekCurrent)
+		if err == nil {
+			end, err := seeker.Seek(0, io.SeekEnd)
+			if err == nil {
+				if _, err := seeker.Seek(current, io.SeekStart); err == nil {
+					return seeker, end - current, nil
+				}
+			}
+		}
+	}
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return nil, 0, err
+	}
+	return bytes.NewReader(data), int64(len(data)), nil
+}
+
+func (s *S3Storage) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, s.objectURL(name), nil)
+	if err != nil {
+		return nil, err
+	}
+	response, err := s.send(request)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode == http.StatusNotFound {
+		response.Body.Close()
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	if response.StatusCode >= http.StatusBadRequest {
+		defer response.Body.Close()
+		return nil, responseError(request, response)
+	}
+	return response.Body, nil
+}
+
+func (s *S3Storage) Delete(ctx context.Context, name string) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectURL(name), nil)
+	if err != nil {
+		return err
+	}
+	status, err := s.do(request)
+	if status == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+func (s *S3Storage) Exists(ctx context.Context, name string) (bool, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodHead, s.objectURL(name), nil)
+	if err != nil {
+		return false, err
+	}
+	status, err := s.do(request)
+	if status == http.StatusNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *S3Storage) URL(name string) string {
+	if s.BaseURL != "" {
+		return strings.TrimSuffix(s.BaseURL, "/") + "/" + escapePath(s.key(name), awsEscape)
+	}
+	return s.objectURL(name)
+}
+
+// SignedURL returns presigned GET URL of name, expires is at most 7 days.
+func (s *S3Storage) SignedURL(ctx context.Context, name string, expires time.Duration) (string, error) {
+	target, err := url.Parse(s.objectURL(name))
+	if err != nil {
+		return "", err
+	}
+	now := timeNow().UTC()
+	stamp := now.Format("20060102T150405Z")
+	query := url.Values{}
+	query.Set(s.header("Algorithm"), s.getScheme() + "-HMAC-SHA256")
+	query.Set(s.header("Credential"), s.AccessKeyID + "/" + s.credentialScope(now))
+	query.Set(s.header("Date"), stamp)
+	query.Set(s.header("Expires"), strconv.Itoa(int(expires.Seconds())))
+	query.Set(s.header("SignedHeaders"), "host")
+	if s.SessionToken != "" {
+		query.Set(s.header("Security-Token"), s.SessionToken)
+	}
+	canonicalQuery := canonicalQueryString(query)
+	canonical := strings.Join([]string{
+		http.MethodGet,
+		target.EscapedPath(),
+		canonicalQuery,
+		"host:" + target.Host + "\n",
+		"host",
+		"UNSIGNED-PAYLOAD",
+	}, "\n")
+	signature := s.signature(now, stamp, canonical)
+	return target.Scheme + "://" + target.Host + target.EscapedPath() + "?" + canonicalQuery + "&" + s.header("Signature") + "=" + signature, nil
+}
+
+func (s *S3Storage) getScheme() string {
+	if s.scheme != "" {
+		return s.scheme
+	}
+	return "AWS4"
+}
+
+// header returns name of signature header or query param, example: "X-Amz-Date".
+func (s *S3Storage) header(name string) string {
+	if s.getScheme() == "GOOG4" {
+		return "X-Goog-" + name
+	}
+	return "X-Amz-" + name
+}
+
+func (s *S3Storage) credentialScope(now time.Time) string {
+	service := s.service
+	if service == "" {
+		service = "s3"
+	}
+	return now.Format("20060102") + "/" + s.GetRegion() + "/" + service + "/" + strings.ToLower(s.getScheme()) + "_request"
+}
+
+// signature signs canonical request with key derived from SecretAccessKey for the day.
+func (s *S3Storage) signature(now time.Time, stamp string, canonical string) string {
+	hash := sha256.Sum256([]byte(canonical))
+	stringToSign := s.getScheme() + "-HMAC-SHA256\n" + stamp + "\n" + s.credentialScope(now) + "\n" + hex.EncodeToString(hash[:])
+	key := []byte(s.getScheme() + s.SecretAccessKey)
+	for _, part := range strings.Split(s.credentialScope(now), "/") {
+		key = hmacSHA256(key, part)
+	}
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, value string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
+
+// sign adds Authorization header signing host and the signature headers of request.
+func (s *S3Storage) sign(request *http.Request) {
+	now := timeNow().UTC()
+	stamp := now.Format("20060102T150405Z")
+	request.Header.Set(s.header("Date"), stamp)
+	request.Header.Set(s.header("Content-Sha256"), "UNSIGNED-PAYLOAD")
+	if s.SessionToken != "" {
+		request.Header.Set(s.header("Security-Token"), s.SessionToken)
+	}
+	headers := map[string]string{"host": request.URL.Host}
+	prefix := strings.ToLower(s.header(""))
+	for name, values := range request.Header {
+		if name = strings.ToLower(name); strings.HasPrefix(name, prefix) {
+			headers[name] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	canonicalHeaders := strings.Builder{}
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+	canonical := strings.Join([]string{
+		request.Method,
+		request.URL.EscapedPath(),
+		canonicalQueryString(request.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		"UNSIGNED-PAYLOAD",
+	}, "\n")
+	request.Header.Set("Authorization", fmt.Sprintf(
+		"%s-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.getScheme(), s.AccessKeyID, s.credentialScope(now), signedHeaders, s.signature(now, stamp, canonical),
+	))
+}
+
+func (s *S3Storage) send(request *http.Request) (*http.Response, error) {
+	s.sign(request)
+	return s.getHTTPClient().Do(request)
+}
+
+// do sends request and returns its status, error statuses are errors.
+func (s *S3Storage) do(request *http.Request) (int, error) {
+	response, err := s.send(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode >= http.StatusBadRequest {
+		return response.StatusCode, responseError(request, response)
+	}
+	io.Copy(io.Discard, response.Body)
+	return response.StatusCode, nil
+}
+
+func responseError(request *http.Request, response *http.Response) error {
+	message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+	return fmt.Errorf("storages: %s %s: %s: %s", request.Method, request.URL.Path, response.Status, message)
+}
+
+func canonicalQueryString(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := []string{}
+	for _, key := range keys {
+		values := append([]string{}, query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, awsEscape(key) + "=" + awsEscape(value))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// awsEscape percent-encodes every byte except unreserved characters, as signatures expect.
+func awsEscape(value string) string {
+	escaped := strings.Builder{}
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.' || c == '~' {
+			escaped.WriteByte(c)
+		} else {
+			fmt.Fprintf(&escaped, "%%%02X", c)
+		}
+	}
+	return escaped.String()
+}
@@ -0,0 +1,118 @@
+package storages
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Storage keeps files by name, names are slash separated paths, example: "avatars/1.png".
+// See FileSystemStorage, S3Storage and GCSStorage.
+type Storage interface {
+	// Save stores content as name and returns the name it's stored as, which differs
+	// from name when a file of name exists, so files aren't overwritten.
+	Save(ctx context.Context, name string, content io.Reader) (string, error)
+	// Open returns content of name, errors of missing files are fs.ErrNotExist.
+	Open(ctx context.Context, name string) (io.ReadCloser, error)
+	// Delete removes name, missing files aren't errors.
+	Delete(ctx context.Context, name string) error
+	Exists(ctx context.Context, name string) (bool, error)
+	// URL returns public URL of name.
+	URL(name string) string
+	// SignedURL returns URL of name granting access until expires passes, for private files.
+	SignedURL(ctx context.Context, name string, expires time.Duration) (string, error)
+}
+
+var (
+	mu				sync.RWMutex
+	defaultStorage	Storage
+	named			= map[string]Storage{}
+)
+
+// SetDefault replaces the storage of uploads and media, default FileSystemStorage of
+// conf.MEDIA_ROOT served at conf.MEDIA_URL, example:
+// storages.SetDefault(&storages.S3Storage{Bucket: "shop-media", Region: "eu-west-1"})
+func SetDefault(storage Storage) {
+	mu.Lock()
+	defer mu.Unlock()
+	defaultStorage = storage
+}
+
+func Default() Storage {
+	mu.RLock()
+	defer mu.RUnlock()
+	if defaultStorage == nil {
+		return &FileSystemStorage{}
+	}
+	return defaultStorage
+}
+
+// Register names storage, so serializer fields select it by storage tag,
+// example: storages.Register("private", &storages.S3Storage{Bucket: "shop-private"})
+func Register(name string, storage Storage) {
+	mu.Lock()
+	defer mu.Unlock()
+	named[name] = storage
+}
+
+// Get returns storage registered by name, Default for empty name, nil when there's none.
+func Get(name string) Storage {
+	if name == "" || name == "default" {
+		return Default()
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	return named[name]
+}
+
+// Qualify prefixes name of file stored in the storage registered as storageName, so
+// fields.File resolves the storage by itself, names of Default are kept as they are,
+// example: Qualify("private", "a.png") => "private://a.png".
+func Qualify(storageName string, name string) string {
+	if storageName == "" || storageName == "default" {
+		return name
+	}
+	return storageName + "://" + name
+}
+
+// Resolve returns storage and name of file qualified by Qualify, storage is nil when it's
+// not registered, example: "private://a.png" => Get("private"), "a.png".
+func Resolve(qualified string) (Storage, string) {
+	// CleanName collapses "//", so names can't contain the separator.
+	storageName, name, ok := strings.Cut(qualified, "://")
+	if !ok {
+		return Default(), qualified
+	}
+	return Get(storageName), name
+}
+
+// CleanName returns name as relative slash separated path without "..",
+// example: "/a/../../b.png" => "b.png".
+func CleanName(name string) string {
+	return strings.TrimPrefix(path.Clean("/" + strings.ReplaceAll(name, "\\", "/")), "/")
+}
+
+// alternateName adds random suffix to name before its extension, example: "a.png" => "a_3f9c1b2.png".
+func alternateName(name string) string {
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	ext := path.Ext(name)
+	return strings.TrimSuffix(name, ext) + "_" + hex.EncodeToString(suffix)[:7] + ext
+}
+
+// availableName returns name or an alternate name which doesn't exist in storage.
+func availableName(ctx context.Context, storage Storage, name string) (string, error) {
+	candidate := name
+	for {
+		exists, err := storage.Exists(ctx, candidate)
+		if err != nil || !exists {
+			return candidate, err
+		}
+		candidate = alternateName(name)
+	}
+}
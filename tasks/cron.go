@@ -0,0 +1,207 @@
+package tasks
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule returns the next run after a time, zero time when it never runs again.
+type Schedule interface {
+	Next(after time.Time) time.Time
+}
+
+// interval runs every duration, aligned to multiples of the duration since the epoch so
+// every replica computes the same runs.
+type interval time.Duration
+
+// Every runs at multiples of duration, example: tasks.Every(15 * time.Minute)
+func Every(duration time.Duration) Schedule {
+	return interval(duration)
+}
+
+func (i interval) Next(after time.Time) time.Time {
+	return after.Truncate(time.Duration(i)).Add(time.Duration(i))
+}
+
+func (i interval) String() string {
+	return "@every " + time.Duration(i).String()
+}
+
+// cronSchedule matches fields of a cron expression as bit sets.
+type cronSchedule struct {
+	spec		string
+	minute		uint64
+	hour		uint64
+	dom			uint64
+	month		uint64
+	dow			uint64
+	// anyDay is true when day of month or day of week is *, then both must match,
+	// otherwise either of them matches like cron does.
+	anyDay		bool
+}
+
+type cronField struct {
+	min		int
+	max		int
+	names	map[string]int
+}
+
+var (
+	minuteField	= cronField{min: 0, max: 59}
+	hourField	= cronField{min: 0, max: 23}
+	domField	= cronField{min: 1, max: 31}
+	monthField	= cronField{min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	dowField	= cronField{min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var cronMacros = map[string]string{
+	"@yearly": "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly": "0 0 1 * *",
+	"@weekly": "0 0 * * 0",
+	"@daily": "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly": "0 * * * *",
+}
+
+// ParseSchedule parses a cron expression of minute, hour, day of month, month and day
+// of week, a macro such as @daily, or "@every <duration>", example: "*/5 9-17 * * mon-fri"
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if value, ok := strings.CutPrefix(spec, "@every "); ok {
+		duration, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || duration <= 0 {
+			return nil, fmt.Errorf("invalid schedule %q: positive duration expected", spec)
+		}
+		return Every(duration), nil
+	}
+	expression := spec
+	if macro, ok := cronMacros[strings.ToLower(spec)]; ok {
+		expression = macro
+	}
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid schedule %q: 5 fields expected", spec)
+	}
+	schedule := &cronSchedule{spec: spec}
+	var err error
+	parsed := []*uint64{&schedule.minute, &schedule.hour, &schedule.dom, &schedule.month, &schedule.dow}
+	for i, field := range []cronField{minuteField, hourField, domField, monthField, dowField} {
+		if *parsed[i], err = field.parse(fields[i]); err != nil {
+			return nil, fmt.Errorf("invalid schedule %q: %w", spec, err)
+		}
+	}
+	// 7 is sunday too.
+	if schedule.dow & (1 << 7) != 0 {
+		schedule.dow |= 1
+	}
+	schedule.anyDay = fields[2] == "*" || fields[4] == "*"
+	return schedule, nil
+}
+
+// MustParseSchedule is ParseSchedule panicking on invalid spec.
+func MustParseSchedule(spec string) Schedule {
+	schedule, err := ParseSchedule(spec)
+	if err != nil {
+		panic(err)
+	}
+	return schedule
+}
+
+// parse returns bit set of values of a comma separated list of *, values and ranges
+// with optional steps.
+func (f cronField) parse(value string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(value, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			number, err := strconv.Atoi(stepPart)
+			if err != nil || number <= 0 {
+				return 0, fmt.Errorf("invalid step %q", part)
+			}
+			step = number
+		}
+		start, end := f.min, f.max
+		if rangePart != "*" {
+			first, last, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if start, err = f.value(first); err != nil {
+				return 0, err
+			}
+			end = start
+			if isRange {
+				if end, err = f.value(last); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				end = f.max
+			}
+			if end < start {
+				return 0, fmt.Errorf("invalid range %q", part)
+			}
+		}
+		for number := start; number <= end; number += step {
+			bits |= 1 << number
+		}
+	}
+	return bits, nil
+}
+
+func (f cronField) value(value string) (int, error) {
+	if number, ok := f.names[strings.ToLower(value)]; ok {
+		return number, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil || number < f.min || number > f.max {
+		return 0, fmt.Errorf("%q isn't between %d and %d", value, f.min, f.max)
+	}
+	return number, nil
+}
+
+func (s *cronSchedule) String() string {
+	return s.spec
+}
+
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	dom := s.dom & (1 << t.Day()) != 0
+	dow := s.dow & (1 << t.Weekday()) != 0
+	if s.anyDay {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// Next skips months, days and hours which don't match before checking minutes,
+// it gives up after 5 years for expressions which never match, such as "0 0 31 2 *".
+func (s *cronSchedule) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case s.month & (1 << t.Month()) == 0:
+			t = time.Date(t.Year(), t.Month() + 1, 1, 0, 0, 0, 0, t.Location())
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day() + 1, 0, 0, 0, 0, t.Location())
+		case s.hour & (1 << t.Hour()) == 0:
+			next := time.Date(t.Year(), t.Month(), t.Day(), t.Hour() + 1, 0, 0, 0, t.Location())
+			// the repeated hour of daylight saving end normalizes back to t.
+			if !next.After(t) {
+				next = t.Add(time.Hour)
+			}
+			t = next
+		case s.minute & (1 << t.Minute()) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,148 @@
+package tasks
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// leaseExpired is the error of runs whose lease expired, their worker was stopped.
+const leaseExpired = "lease expired"
+
+const (
+	StatusPending	= "pending"
+	StatusReserved	= "reserved"
+	StatusDead		= "dead"
+)
+
+// GorimTask is a stored message, RunAt of reserved messages is the end of their lease
+// and of dead messages the time they failed.
+type GorimTask struct {
+	ID			string			`gorm:"type:varchar(36);primarykey"`
+	Task		string			`gorm:"type:varchar(255)"`
+	Queue		string			`gorm:"type:varchar(100);index:idx_gorim_tasks_due,priority:1"`
+	Status		string			`gorm:"type:varchar(20);index:idx_gorim_tasks_due,priority:2"`
+	RunAt		time.Time		`gorm:"index:idx_gorim_tasks_due,priority:3"`
+	Payload		string			`gorm:"type:text"`
+	Attempt		int
+	MaxRetries	int
+	LastError	string			`gorm:"type:text"`
+	CreatedAt	time.Time
+}
+
+func (m GorimTask) TableName() string {
+	return "gorim_tasks"
+}
+
+func (m *GorimTask) message() *Message {
+	return &Message{
+		ID: m.ID,
+		Task: m.Task,
+		Queue: m.Queue,
+		Payload: []byte(m.Payload),
+		Attempt: m.Attempt,
+		MaxRetries: m.MaxRetries,
+		RunAt: m.RunAt,
+		LastError: m.LastError,
+	}
+}
+
+// DBBackend keeps messages in gorim_tasks table, add &tasks.GorimTask{} to migration
+// models. Workers claim messages with conditional updates, so any number of them can
+// share the table.
+type DBBackend struct {
+	DB			*gorm.DB
+}
+
+func NewDBBackend(db *gorm.DB) *DBBackend {
+	return &DBBackend{DB: db}
+}
+
+func (b *DBBackend) Enqueue(ctx context.Context, message *Message) error {
+	record := GorimTask{
+		ID: message.ID,
+		Task: message.Task,
+		Queue: message.Queue,
+		Status: StatusPending,
+		RunAt: message.RunAt,
+		Payload: string(message.Payload),
+		MaxRetries: message.MaxRetries,
+	}
+	return b.DB.WithContext(ctx).Create(&record).Error
+}
+
+// Dequeue reserves a due pending message or a reserved one whose lease expired, expired
+// leases count as failed attempts.
+func (b *DBBackend) Dequeue(ctx context.Context, queues []string, lease time.Duration) (*Message, error) {
+	db := b.DB.WithContext(ctx)
+	now := time.Now()
+	for _, queue := range queues {
+		var candidates []GorimTask
+		err := db.Where("queue = ? AND status IN ? AND run_at <= ?", queue, []string{StatusPending, StatusReserved}, now).
+			Order("run_at").Limit(10).Find(&candidates).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, candidate := range candidates {
+			updates := map[string]interface{}{"status": StatusReserved, "run_at": now.Add(lease)}
+			if candidate.Status == StatusReserved {
+				// the lease expired, the worker running it was stopped so the run counts
+				// as failed, messages past MaxRetries are dead instead of run again.
+				candidate.Attempt++
+				candidate.LastError = leaseExpired
+				updates["attempt"] = candidate.Attempt
+				updates["last_error"] = candidate.LastError
+				if candidate.Attempt > candidate.MaxRetries {
+					updates["status"] = StatusDead
+					updates["run_at"] = now
+				}
+			}
+			// another worker claiming the same message changes its run_at first.
+			result := db.Model(&GorimTask{}).
+				Where("id = ? AND status = ? AND run_at = ?", candidate.ID, candidate.Status, candidate.RunAt).
+				Updates(updates)
+			if result.Error != nil {
+				return nil, result.Error
+			}
+			if result.RowsAffected == 0 {
+				continue
+			}
+			if updates["status"] == StatusDead {
+				slog.Error("tasks: task failed", "task", candidate.Task, "id", candidate.ID, "attempt", candidate.Attempt, "error", candidate.LastError)
+				continue
+			}
+			return candidate.message(), nil
+		}
+	}
+	return nil, nil
+}
+
+func (b *DBBackend) Complete(ctx context.Context, message *Message) error {
+	return b.DB.WithContext(ctx).Delete(&GorimTask{ID: message.ID}).Error
+}
+
+func (b *DBBackend) Retry(ctx context.Context, message *Message) error {
+	return b.update(ctx, message, StatusPending)
+}
+
+func (b *DBBackend) Fail(ctx context.Context, message *Message) error {
+	failed := *message
+	failed.RunAt = time.Now()
+	return b.update(ctx, &failed, StatusDead)
+}
+
+func (b *DBBackend) update(ctx context.Context, message *Message, status string) error {
+	return b.DB.WithContext(ctx).Model(&GorimTask{ID: message.ID}).Updates(map[string]interface{}{
+		"status": status,
+		"run_at": message.RunAt,
+		"attempt": message.Attempt,
+		"last_error": message.LastError,
+	}).Error
+}
+
+// ClearDead removes dead messages failed before.
+func (b *DBBackend) ClearDead(before time.Time) error {
+	return b.DB.Where("status = ? AND run_at <= ?", StatusDead, before).Delete(&GorimTask{}).Error
+}
@@ -0,0 +1,41 @@
+package tasks
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/rimba47prayoga/gorim.git/signals"
+)
+
+// EnqueueOn enqueues task when signal is sent for model T, payload builds the payload
+// from the event and returns false to skip it. Enqueue errors are logged so the request
+// isn't failed by the queue, example enqueueing from viewset saves:
+//
+//	tasks.EnqueueOn(signals.PostSave, SendWelcome, func(event signals.Event[User]) (Welcome, bool) {
+//		return Welcome{Email: event.Instance.Email}, event.Created
+//	})
+func EnqueueOn[T any, P any](signal signals.Signal, task *Task[P], payload func(event signals.Event[T]) (P, bool), options ...EnqueueOption) {
+	signals.Connect(signal, func(event signals.Event[T]) {
+		value, ok := payload(event)
+		if !ok {
+			return
+		}
+		ctx := context.Background()
+		if event.Context != nil {
+			ctx = context.WithoutCancel(event.Context.Request().Context())
+		}
+		if _, err := task.Enqueue(ctx, value, options...); err != nil {
+			slog.Error("tasks: enqueue failed", "task", task.Name, "signal", signal.String(), "error", err)
+		}
+	})
+}
+
+// EnqueueOnSave enqueues task after instances of model T are saved.
+func EnqueueOnSave[T any, P any](task *Task[P], payload func(event signals.Event[T]) (P, bool), options ...EnqueueOption) {
+	EnqueueOn(signals.PostSave, task, payload, options...)
+}
+
+// EnqueueOnDelete enqueues task after instances of model T are deleted.
+func EnqueueOnDelete[T any, P any](task *Task[P], payload func(event signals.Event[T]) (P, bool), options ...EnqueueOption) {
+	EnqueueOn(signals.PostDelete, task, payload, options...)
+}
@@ -0,0 +1,124 @@
+package tasks
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// MemoryBackend keeps messages in the process, for tests and workers of the same process.
+type MemoryBackend struct {
+	mu			sync.Mutex
+	pending		[]*Message
+	reserved	map[string]reservation
+	dead		[]*Message
+}
+
+type reservation struct {
+	message		*Message
+	until		time.Time
+}
+
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{reserved: map[string]reservation{}}
+}
+
+func (b *MemoryBackend) Enqueue(ctx context.Context, message *Message) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	copied := *message
+	b.pending = append(b.pending, &copied)
+	return nil
+}
+
+func (b *MemoryBackend) Dequeue(ctx context.Context, queues []string, lease time.Duration) (*Message, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	for id, reserved := range b.reserved {
+		if now.After(reserved.until) {
+			delete(b.reserved, id)
+			b.expire(reserved.message, now)
+		}
+	}
+	for _, queue := range queues {
+		index := -1
+		for i, message := range b.pending {
+			if message.Queue != queue || message.RunAt.After(now) {
+				continue
+			}
+			if index < 0 || message.RunAt.Before(b.pending[index].RunAt) {
+				index = i
+			}
+		}
+		if index >= 0 {
+			message := b.pending[index]
+			b.pending = append(b.pending[:index], b.pending[index + 1:]...)
+			b.reserved[message.ID] = reservation{message: message, until: now.Add(lease)}
+			copied := *message
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+// expire counts the expired lease of message as failed attempt, like DBBackend.
+func (b *MemoryBackend) expire(message *Message, now time.Time) {
+	message.Attempt++
+	message.LastError = leaseExpired
+	if message.Attempt > message.MaxRetries {
+		slog.Error("tasks: task failed", "task", message.Task, "id", message.ID, "attempt", message.Attempt, "error", message.LastError)
+		message.RunAt = now
+		b.dead = append(b.dead, message)
+		return
+	}
+	b.pending = append(b.pending, message)
+}
+
+func (b *MemoryBackend) Complete(ctx context.Context, message *Message) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.reserved, message.ID)
+	return nil
+}
+
+func (b *MemoryBackend) Retry(ctx context.Context, message *Message) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.reserved, message.ID)
+	copied := *message
+	b.pending = append(b.pending, &copied)
+	return nil
+}
+
+func (b *MemoryBackend) Fail(ctx context.Context, message *Message) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.reserved, message.ID)
+	copied := *message
+	b.dead = append(b.dead, &copied)
+	return nil
+}
+
+// Pending returns messages waiting to run.
+func (b *MemoryBackend) Pending() []Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	messages := make([]Message, len(b.pending))
+	for i, message := range b.pending {
+		messages[i] = *message
+	}
+	return messages
+}
+
+// Dead returns messages which exhausted their retries.
+func (b *MemoryBackend) Dead() []Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	messages := make([]Message, len(b.dead))
+	for i, message := range b.dead {
+		messages[i] = *message
+	}
+	return messages
+}
@@ -0,0 +1,205 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// dequeueScript reserves the first due message of queues. KEYS: messages hash, reserved
+// set. ARGV: now (ms), lease deadline (ms), prefix of queue keys, queues. Returns the
+// message json.
+var dequeueScript = redis.NewScript(`
+for i = 4, #ARGV do
+	local queue = ARGV[3] .. ARGV[i]
+	local ids = redis.call("ZRANGEBYSCORE", queue, "-inf", ARGV[1], "LIMIT", 0, 1)
+	if #ids > 0 then
+		redis.call("ZREM", queue, ids[1])
+		redis.call("ZADD", KEYS[2], ARGV[2], ids[1])
+		return redis.call("HGET", KEYS[1], ids[1])
+	end
+end
+return false
+`)
+
+// expireScript releases a message whose lease expired, unless it was completed or
+// reserved again meanwhile. KEYS: messages hash, reserved set, dead hash, queue. ARGV:
+// id, now (ms), message json with the attempt counted, "1" when it's dead.
+var expireScript = redis.NewScript(`
+local score = redis.call("ZSCORE", KEYS[2], ARGV[1])
+if not score or tonumber(score) > tonumber(ARGV[2]) then
+	return 0
+end
+redis.call("ZREM", KEYS[2], ARGV[1])
+if ARGV[4] == "1" then
+	redis.call("HDEL", KEYS[1], ARGV[1])
+	redis.call("HSET", KEYS[3], ARGV[1], ARGV[3])
+else
+	redis.call("HSET", KEYS[1], ARGV[1], ARGV[3])
+	redis.call("ZADD", KEYS[4], ARGV[2], ARGV[1])
+end
+return 1
+`)
+
+// RedisBackend shares messages between processes. Messages are kept in a hash, queues
+// are sorted sets by run time. Keys share the hash tag of Prefix, so they're in one slot
+// of a cluster.
+type RedisBackend struct {
+	Client		redis.UniversalClient
+	// Prefix of redis keys, default "{gorim:tasks}:".
+	Prefix		string
+}
+
+func NewRedisBackend(client redis.UniversalClient) *RedisBackend {
+	return &RedisBackend{Client: client}
+}
+
+func (b *RedisBackend) GetPrefix() string {
+	if b.Prefix == "" {
+		return "{gorim:tasks}:"
+	}
+	return b.Prefix
+}
+
+func (b *RedisBackend) messagesKey() string {
+	return b.GetPrefix() + "messages"
+}
+
+func (b *RedisBackend) reservedKey() string {
+	return b.GetPrefix() + "reserved"
+}
+
+func (b *RedisBackend) deadKey() string {
+	return b.GetPrefix() + "dead"
+}
+
+func (b *RedisBackend) queueKey(queue string) string {
+	return b.GetPrefix() + "queue:" + queue
+}
+
+// schedule stores message and adds it to its queue.
+func (b *RedisBackend) schedule(ctx context.Context, message *Message) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	_, err = b.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, b.reservedKey(), message.ID)
+		pipe.HSet(ctx, b.messagesKey(), message.ID, data)
+		pipe.ZAdd(ctx, b.queueKey(message.Queue), redis.Z{Score: float64(message.RunAt.UnixMilli()), Member: message.ID})
+		return nil
+	})
+	return err
+}
+
+func (b *RedisBackend) Enqueue(ctx context.Context, message *Message) error {
+	return b.schedule(ctx, message)
+}
+
+// Dequeue releases messages whose lease expired then reserves a due message, expired
+// leases count as failed attempts like DBBackend.
+func (b *RedisBackend) Dequeue(ctx context.Context, queues []string, lease time.Duration) (*Message, error) {
+	now := time.Now()
+	if err := b.expire(ctx, now); err != nil {
+		return nil, err
+	}
+	args := []interface{}{now.UnixMilli(), now.Add(lease).UnixMilli(), b.queueKey("")}
+	for _, queue := range queues {
+		args = append(args, queue)
+	}
+	data, err := dequeueScript.Run(ctx, b.Client, []string{b.messagesKey(), b.reservedKey()}, args...).Text()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	message := &Message{}
+	if err := json.Unmarshal([]byte(data), message); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+// expire counts attempts of messages whose lease expired, messages past MaxRetries are
+// dead instead of queued again.
+func (b *RedisBackend) expire(ctx context.Context, now time.Time) error {
+	ids, err := b.Client.ZRangeByScore(ctx, b.reservedKey(), &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(now.UnixMilli(), 10),
+		Count: 100,
+	}).Result()
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		data, err := b.Client.HGet(ctx, b.messagesKey(), id).Result()
+		if err == redis.Nil {
+			b.Client.ZRem(ctx, b.reservedKey(), id)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		message := &Message{}
+		if err := json.Unmarshal([]byte(data), message); err != nil {
+			return err
+		}
+		message.Attempt++
+		message.LastError = leaseExpired
+		dead := "0"
+		if message.Attempt > message.MaxRetries {
+			dead = "1"
+			message.RunAt = now
+		}
+		updated, err := json.Marshal(message)
+		if err != nil {
+			return err
+		}
+		keys := []string{b.messagesKey(), b.reservedKey(), b.deadKey(), b.queueKey(message.Queue)}
+		released, err := expireScript.Run(ctx, b.Client, keys, id, now.UnixMilli(), updated, dead).Int()
+		if err != nil {
+			return err
+		}
+		if released == 1 && dead == "1" {
+			slog.Error("tasks: task failed", "task", message.Task, "id", message.ID, "attempt", message.Attempt, "error", message.LastError)
+		}
+	}
+	return nil
+}
+
+func (b *RedisBackend) Complete(ctx context.Context, message *Message) error {
+	_, err := b.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, b.reservedKey(), message.ID)
+		pipe.HDel(ctx, b.messagesKey(), message.ID)
+		return nil
+	})
+	return err
+}
+
+func (b *RedisBackend) Retry(ctx context.Context, message *Message) error {
+	return b.schedule(ctx, message)
+}
+
+func (b *RedisBackend) Fail(ctx context.Context, message *Message) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	_, err = b.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, b.reservedKey(), message.ID)
+		pipe.HDel(ctx, b.messagesKey(), message.ID)
+		pipe.HSet(ctx, b.deadKey(), message.ID, data)
+		return nil
+	})
+	return err
+}
+
+// Close closes the redis client.
+func (b *RedisBackend) Close() error {
+	return b.Client.Close()
+}
@@ -0,0 +1,263 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/cache"
+	"github.com/rimba47prayoga/gorim.git/interfaces"
+	"github.com/rimba47prayoga/gorim.git/locks"
+	"github.com/rimba47prayoga/gorim.git/permissions"
+)
+
+// Entry is a schedule of a task.
+type Entry struct {
+	Name		string
+	Spec		string
+	Schedule	Schedule
+	Task		string
+	enqueue		func(ctx context.Context) error
+}
+
+// EntryStatus is the state of an entry shared by replicas through cache.Default.
+type EntryStatus struct {
+	Name		string		`json:"name"`
+	Spec		string		`json:"spec"`
+	Task		string		`json:"task"`
+	NextRun		time.Time	`json:"next_run"`
+	LastRun		*time.Time	`json:"last_run"`
+	LastError	string		`json:"last_error,omitempty"`
+	Runs		int			`json:"runs"`
+}
+
+// Scheduler enqueues tasks of its entries when they're due. Every replica can run it,
+// only the replica holding the lock of LockName enqueues.
+type Scheduler struct {
+	// Locker elects the leader, default locks.Default, which is shared by replicas only
+	// when it has a redis or database backend.
+	Locker		*locks.Locker
+	// LockName default "scheduler".
+	LockName	string
+	// Location of cron expressions, default time.Local.
+	Location	*time.Location
+	// Interval between checks of due entries, default 1 second.
+	Interval	time.Duration
+	// Permission of View, default permissions.IsAdminUser.
+	Permission	interfaces.IPermission
+	mu			sync.RWMutex
+	entries		[]*Entry
+	leader		atomic.Bool
+}
+
+// DefaultScheduler has entries of Periodic and is run by the worker command.
+var DefaultScheduler = &Scheduler{}
+
+// Periodic enqueues task with payload on schedule spec of ParseSchedule, it panics when
+// spec is invalid or name is added twice, example:
+//
+//	tasks.Periodic("nightly_report", "0 2 * * *", SendReport, Report{Days: 1})
+//	tasks.Periodic("sync_rates", "@every 15m", SyncRates, struct{}{})
+func Periodic[P any](name string, spec string, task *Task[P], payload P, options ...EnqueueOption) {
+	err := DefaultScheduler.Add(name, spec, task.Name, func(ctx context.Context) error {
+		_, err := task.Enqueue(ctx, payload, options...)
+		return err
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Add adds entry calling enqueue on schedule spec, task names the enqueued task for
+// the status.
+func (s *Scheduler) Add(name string, spec string, task string, enqueue func(ctx context.Context) error) error {
+	schedule, err := ParseSchedule(spec)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, entry := range s.entries {
+		if entry.Name == name {
+			return fmt.Errorf("tasks: schedule %q is already added", name)
+		}
+	}
+	s.entries = append(s.entries, &Entry{
+		Name: name,
+		Spec: spec,
+		Schedule: schedule,
+		Task: task,
+		enqueue: enqueue,
+	})
+	return nil
+}
+
+// Entries returns added entries in the order they were added.
+func (s *Scheduler) Entries() []*Entry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]*Entry{}, s.entries...)
+}
+
+// IsLeader reports whether this process enqueues the entries.
+func (s *Scheduler) IsLeader() bool {
+	return s.leader.Load()
+}
+
+func (s *Scheduler) GetLocker() *locks.Locker {
+	if s.Locker == nil {
+		return locks.Default
+	}
+	return s.Locker
+}
+
+func (s *Scheduler) GetLockName() string {
+	if s.LockName == "" {
+		return "scheduler"
+	}
+	return s.LockName
+}
+
+func (s *Scheduler) GetLocation() *time.Location {
+	if s.Location == nil {
+		return time.Local
+	}
+	return s.Location
+}
+
+func (s *Scheduler) GetInterval() time.Duration {
+	if s.Interval <= 0 {
+		return time.Second
+	}
+	return s.Interval
+}
+
+// Run competes for leadership and enqueues due entries while leading, until ctx is done.
+func (s *Scheduler) Run(ctx context.Context) error {
+	var lock *locks.Lock
+	var extended time.Time
+	defer func() {
+		if lock != nil {
+			s.leader.Store(false)
+			lock.Release(context.WithoutCancel(ctx))
+		}
+	}()
+	ticker := time.NewTicker(s.GetInterval())
+	defer ticker.Stop()
+	for {
+		now := time.Now()
+		if lock == nil {
+			var err error
+			lock, err = s.GetLocker().TryAcquire(ctx, s.GetLockName())
+			if err == nil {
+				extended = now
+				s.leader.Store(true)
+				slog.Info("tasks: scheduler is leading", "lock", s.GetLockName(), "fence", lock.Fence)
+			} else if err != locks.ErrNotAcquired && ctx.Err() == nil {
+				slog.Error("tasks: scheduler election failed", "error", err)
+			}
+		} else if now.Sub(extended) >= lock.TTL / 3 {
+			if err := lock.Extend(ctx); err != nil {
+				slog.Warn("tasks: scheduler lost leadership", "error", err)
+				lock = nil
+				s.leader.Store(false)
+			} else {
+				extended = now
+			}
+		}
+		if lock != nil {
+			s.Tick(ctx, now)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick enqueues entries due at now, entries seen the first time are scheduled after now.
+func (s *Scheduler) Tick(ctx context.Context, now time.Time) {
+	now = now.In(s.GetLocation())
+	for _, entry := range s.Entries() {
+		status, err := s.load(ctx, entry)
+		if err != nil {
+			slog.Error("tasks: loading schedule failed", "schedule", entry.Name, "error", err)
+			continue
+		}
+		if !status.NextRun.IsZero() && status.NextRun.After(now) {
+			continue
+		}
+		if !status.NextRun.IsZero() {
+			status.LastRun = &now
+			status.Runs++
+			status.LastError = ""
+			if err := entry.enqueue(ctx); err != nil {
+				status.LastError = err.Error()
+				slog.Error("tasks: scheduled enqueue failed", "schedule", entry.Name, "error", err)
+			}
+		}
+		status.NextRun = entry.Schedule.Next(now)
+		if err := s.store().Set(ctx, entry.Name, status, cache.Forever); err != nil {
+			slog.Error("tasks: saving schedule failed", "schedule", entry.Name, "error", err)
+		}
+	}
+}
+
+// store keeps statuses in cache.Default, read at use since it's set by the settings.
+func (s *Scheduler) store() *cache.Cache {
+	return cache.Default.Namespace(s.GetLockName())
+}
+
+func (s *Scheduler) load(ctx context.Context, entry *Entry) (*EntryStatus, error) {
+	status := &EntryStatus{}
+	if _, err := s.store().Get(ctx, entry.Name, status); err != nil {
+		return nil, err
+	}
+	// a changed spec is scheduled again from the next tick.
+	if status.Spec != entry.Spec {
+		status.NextRun = time.Time{}
+	}
+	status.Name = entry.Name
+	status.Spec = entry.Spec
+	status.Task = entry.Task
+	return status, nil
+}
+
+// Status returns statuses of the entries, NextRun is zero until a leader saw the entry.
+func (s *Scheduler) Status(ctx context.Context) ([]EntryStatus, error) {
+	statuses := []EntryStatus{}
+	for _, entry := range s.Entries() {
+		status, err := s.load(ctx, entry)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, *status)
+	}
+	return statuses, nil
+}
+
+// View responds schedules and their last runs to users allowed by Permission,
+// example: api.GET("/schedules", tasks.DefaultScheduler.View)
+func (s *Scheduler) View(c gorim.Context) error {
+	permission := s.Permission
+	if permission == nil {
+		permission = &permissions.IsAdminUser{}
+	}
+	if !permission.HasPermission(c) {
+		return permissions.Deny(c, permission)
+	}
+	statuses, err := s.Status(c.Request().Context())
+	if err != nil {
+		return err
+	}
+	return c.Respond(http.StatusOK, map[string]interface{}{
+		"leader": s.IsLeader(),
+		"schedules": statuses,
+	})
+}
@@ -0,0 +1,37 @@
+package tasks
+
+import (
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rimba47prayoga/gorim.git/conf"
+	"github.com/rimba47prayoga/gorim.git/settings"
+)
+
+// FromSettings returns Backend of settings.Get().Tasks, the database backend uses conf.DB,
+// example: tasks.Default, err = tasks.FromSettings()
+func FromSettings() (Backend, error) {
+	config := settings.Get().Tasks
+	switch config.Backend {
+	case "", "memory":
+		return NewMemoryBackend(), nil
+	case "database":
+		return NewDBBackend(conf.DB), nil
+	case "redis":
+		options, err := redis.ParseURL(config.URL)
+		if err != nil {
+			return nil, err
+		}
+		return NewRedisBackend(redis.NewClient(options)), nil
+	}
+	return nil, fmt.Errorf("unknown tasks backend %q", config.Backend)
+}
+
+// WorkerFromSettings returns Worker of Default with queues and concurrency of settings.
+func WorkerFromSettings() *Worker {
+	config := settings.Get().Tasks
+	return &Worker{
+		Queues: config.Queues,
+		Concurrency: config.Concurrency,
+	}
+}
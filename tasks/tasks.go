@@ -0,0 +1,204 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultQueue is the queue of tasks registered without Queue.
+const DefaultQueue = "default"
+
+// Message is an enqueued call of a task, Payload is the task payload as json.
+type Message struct {
+	ID			string			`json:"id"`
+	Task		string			`json:"task"`
+	Queue		string			`json:"queue"`
+	Payload		json.RawMessage	`json:"payload"`
+	// Attempt counts failed runs.
+	Attempt		int				`json:"attempt"`
+	MaxRetries	int				`json:"max_retries"`
+	RunAt		time.Time		`json:"run_at"`
+	LastError	string			`json:"last_error,omitempty"`
+}
+
+// Backend stores messages shared by the processes enqueueing and the workers.
+type Backend interface {
+	Enqueue(ctx context.Context, message *Message) error
+	// Dequeue reserves a due message of queues for lease, nil when none is due. Reserved
+	// messages which aren't completed, retried or failed within lease are delivered again.
+	Dequeue(ctx context.Context, queues []string, lease time.Duration) (*Message, error)
+	// Complete removes the reserved message after the task succeeded.
+	Complete(ctx context.Context, message *Message) error
+	// Retry releases the reserved message to run again at its RunAt.
+	Retry(ctx context.Context, message *Message) error
+	// Fail keeps the reserved message as dead after its retries are exhausted.
+	Fail(ctx context.Context, message *Message) error
+}
+
+// Default stores enqueued tasks, the memory backend only runs them by a worker of the
+// same process, use tasks.FromSettings for a shared backend.
+var Default Backend = NewMemoryBackend()
+
+// Options of a task.
+type Options struct {
+	// Queue of the task, default DefaultQueue.
+	Queue		string
+	// MaxRetries of failed runs, default 3, negative doesn't retry.
+	MaxRetries	int
+	// Backoff returns delay of retry after attempt failures, default exponential from
+	// 2 seconds up to an hour with jitter.
+	Backoff		func(attempt int) time.Duration
+	// Timeout cancels context of a run, default 5 minutes.
+	Timeout		time.Duration
+}
+
+// runner runs a message of a registered task.
+type runner struct {
+	options		Options
+	run			func(ctx context.Context, payload json.RawMessage) error
+}
+
+var (
+	registryMu	sync.RWMutex
+	registry	= map[string]*runner{}
+)
+
+// Task enqueues calls of a handler with payload P, P is encoded as json.
+type Task[P any] struct {
+	Name		string
+	Options		Options
+	// Backend overrides Default.
+	Backend		Backend
+}
+
+// Register registers handler of task name, workers run it for enqueued messages.
+// It panics when name is registered twice, example:
+//
+//	var SendWelcome = tasks.Register("send_welcome", func(ctx context.Context, payload Welcome) error {
+//		return mail.Send(ctx, payload.Email, "Welcome")
+//	}, tasks.Options{Queue: "emails", MaxRetries: 5})
+//
+//	SendWelcome.Enqueue(ctx, Welcome{Email: user.Email})
+func Register[P any](name string, handler func(ctx context.Context, payload P) error, options ...Options) *Task[P] {
+	task := &Task[P]{Name: name}
+	if len(options) > 0 {
+		task.Options = options[0]
+	}
+	if task.Options.Queue == "" {
+		task.Options.Queue = DefaultQueue
+	}
+	if task.Options.MaxRetries == 0 {
+		task.Options.MaxRetries = 3
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("tasks: task %q is already registered", name))
+	}
+	registry[name] = &runner{
+		options: task.Options,
+		run: func(ctx context.Context, data json.RawMessage) error {
+			var payload P
+			if err := json.Unmarshal(data, &payload); err != nil {
+				return err
+			}
+			return handler(ctx, payload)
+		},
+	}
+	return task
+}
+
+// Registered returns names of registered tasks.
+func Registered() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	return names
+}
+
+func (r *runner) getTimeout() time.Duration {
+	if r.options.Timeout <= 0 {
+		return defaultTimeout
+	}
+	return r.options.Timeout
+}
+
+func getRunner(name string) *runner {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return registry[name]
+}
+
+// EnqueueOption changes a message before it's enqueued.
+type EnqueueOption func(*Message)
+
+// Delay runs the message after duration.
+func Delay(duration time.Duration) EnqueueOption {
+	return func(message *Message) {
+		message.RunAt = time.Now().Add(duration)
+	}
+}
+
+// At runs the message at time.
+func At(at time.Time) EnqueueOption {
+	return func(message *Message) {
+		message.RunAt = at
+	}
+}
+
+// Queue overrides queue of the task.
+func Queue(name string) EnqueueOption {
+	return func(message *Message) {
+		message.Queue = name
+	}
+}
+
+func (t *Task[P]) GetBackend() Backend {
+	if t.Backend == nil {
+		return Default
+	}
+	return t.Backend
+}
+
+// Enqueue stores a call of the task with payload, it runs as soon as a worker is free
+// unless delayed by options.
+func (t *Task[P]) Enqueue(ctx context.Context, payload P, options ...EnqueueOption) (*Message, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	message := &Message{
+		ID: uuid.NewString(),
+		Task: t.Name,
+		Queue: t.Options.Queue,
+		Payload: data,
+		MaxRetries: t.Options.MaxRetries,
+		RunAt: time.Now(),
+	}
+	for _, option := range options {
+		option(message)
+	}
+	if err := t.GetBackend().Enqueue(ctx, message); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+// DefaultBackoff doubles the delay from 2 seconds per attempt up to an hour, with up to
+// 20% of jitter so retries of many messages spread.
+func DefaultBackoff(attempt int) time.Duration {
+	delay := time.Hour
+	if attempt < 12 {
+		delay = min(time.Duration(1 << attempt) * time.Second, time.Hour)
+	}
+	return delay + time.Duration(rand.Int63n(int64(delay / 5) + 1))
+}
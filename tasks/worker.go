@@ -0,0 +1,165 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/rimba47prayoga/gorim.git/utils"
+)
+
+// Worker runs enqueued messages of Queues with Concurrency goroutines.
+type Worker struct {
+	// Backend defaults to Default.
+	Backend			Backend
+	// Queues defaults to DefaultQueue, earlier queues are dequeued first.
+	Queues			[]string
+	// Concurrency is the number of messages run at once, default 4.
+	Concurrency		int
+	// PollInterval is the wait when no message is due, default 1 second.
+	PollInterval	time.Duration
+	// Lease of dequeued messages, the message is delivered again when the worker dies
+	// before it's finished. Default is the longest Timeout of tasks of Queues plus a
+	// minute, so messages aren't delivered again while they run.
+	Lease			time.Duration
+}
+
+func (w *Worker) GetBackend() Backend {
+	if w.Backend == nil {
+		return Default
+	}
+	return w.Backend
+}
+
+func (w *Worker) GetQueues() []string {
+	if len(w.Queues) == 0 {
+		return []string{DefaultQueue}
+	}
+	return w.Queues
+}
+
+func (w *Worker) GetConcurrency() int {
+	if w.Concurrency <= 0 {
+		return 4
+	}
+	return w.Concurrency
+}
+
+func (w *Worker) GetPollInterval() time.Duration {
+	if w.PollInterval <= 0 {
+		return time.Second
+	}
+	return w.PollInterval
+}
+
+func (w *Worker) GetLease() time.Duration {
+	if w.Lease > 0 {
+		return w.Lease
+	}
+	lease := defaultTimeout
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	for _, runner := range registry {
+		if utils.Contains(w.GetQueues(), runner.options.Queue) && runner.getTimeout() > lease {
+			lease = runner.getTimeout()
+		}
+	}
+	return lease + time.Minute
+}
+
+const defaultTimeout = 5 * time.Minute
+
+// Run processes messages until ctx is done, then waits for running messages which
+// finish within their timeout.
+func (w *Worker) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, w.GetConcurrency())
+	defer wg.Wait()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case slots <- struct{}{}:
+		}
+		message, err := w.GetBackend().Dequeue(ctx, w.GetQueues(), w.GetLease())
+		if err != nil || message == nil {
+			<-slots
+			if err != nil && ctx.Err() == nil {
+				slog.Error("tasks: dequeue failed", "error", err)
+			}
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(w.GetPollInterval()):
+			}
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			w.Process(context.WithoutCancel(ctx), message)
+		}()
+	}
+}
+
+// Drain processes due messages one by one until none is left, for tests and commands.
+func (w *Worker) Drain(ctx context.Context) error {
+	for {
+		message, err := w.GetBackend().Dequeue(ctx, w.GetQueues(), w.GetLease())
+		if err != nil || message == nil {
+			return err
+		}
+		w.Process(ctx, message)
+	}
+}
+
+// Process runs a dequeued message and completes, retries or fails it.
+func (w *Worker) Process(ctx context.Context, message *Message) {
+	backend := w.GetBackend()
+	err := w.run(ctx, message)
+	if err == nil {
+		if err := backend.Complete(ctx, message); err != nil {
+			slog.Error("tasks: complete failed", "task", message.Task, "id", message.ID, "error", err)
+		}
+		return
+	}
+	message.Attempt++
+	message.LastError = err.Error()
+	if message.Attempt > message.MaxRetries {
+		slog.Error("tasks: task failed", "task", message.Task, "id", message.ID, "attempt", message.Attempt, "error", err)
+		err = backend.Fail(ctx, message)
+	} else {
+		backoff := DefaultBackoff
+		if runner := getRunner(message.Task); runner != nil && runner.options.Backoff != nil {
+			backoff = runner.options.Backoff
+		}
+		message.RunAt = time.Now().Add(backoff(message.Attempt))
+		slog.Warn("tasks: task will be retried", "task", message.Task, "id", message.ID, "attempt", message.Attempt, "run_at", message.RunAt, "error", message.LastError)
+		err = backend.Retry(ctx, message)
+	}
+	if err != nil {
+		slog.Error("tasks: saving failed message failed", "task", message.Task, "id", message.ID, "error", err)
+	}
+}
+
+// run calls the task of message with its timeout, panics are logged with the stack and
+// returned as errors.
+func (w *Worker) run(ctx context.Context, message *Message) (err error) {
+	runner := getRunner(message.Task)
+	if runner == nil {
+		return fmt.Errorf("task %q isn't registered", message.Task)
+	}
+	ctx, cancel := context.WithTimeout(ctx, runner.getTimeout())
+	defer cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("tasks: task panicked", "task", message.Task, "id", message.ID, "panic", r, "stack", string(debug.Stack()))
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return runner.run(ctx, message.Payload)
+}
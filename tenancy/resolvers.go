@@ -0,0 +1,106 @@
+package tenancy
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git/authentication"
+)
+
+// Resolver returns tenant of the request, empty string when the request doesn't specify it.
+type Resolver interface {
+	Resolve(c echo.Context) string
+}
+
+// TrustedResolver is implemented by resolvers telling whether their tenant can be used
+// without Config.Authorize, resolvers not implementing it are trusted.
+type TrustedResolver interface {
+	Trusted() bool
+}
+
+// HeaderResolver reads tenant from header, default X-Tenant-ID. Clients choose the
+// header, so it's untrusted: Config.Authorize must check the user belongs to the tenant.
+type HeaderResolver struct {
+	Header		string
+}
+
+func (r *HeaderResolver) Trusted() bool {
+	return false
+}
+
+func (r *HeaderResolver) Resolve(c echo.Context) string {
+	header := r.Header
+	if header == "" {
+		header = "X-Tenant-ID"
+	}
+	return c.Request().Header.Get(header)
+}
+
+// SubdomainResolver reads tenant from subdomain of Domain, example: acme.example.com => acme.
+// Without Domain the first label of hosts having at least three labels is used. Clients
+// send the Host header, so it's untrusted like HeaderResolver.
+type SubdomainResolver struct {
+	Domain		string
+}
+
+func (r *SubdomainResolver) Trusted() bool {
+	return false
+}
+
+func (r *SubdomainResolver) Resolve(c echo.Context) string {
+	host := c.Request().Host
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+	host = strings.ToLower(host)
+	if r.Domain != "" {
+		suffix := "." + strings.ToLower(strings.Trim(r.Domain, "."))
+		if !strings.HasSuffix(host, suffix) {
+			return ""
+		}
+		return strings.TrimSuffix(host, suffix)
+	}
+	labels := strings.Split(host, ".")
+	if len(labels) < 3 || net.ParseIP(host) != nil {
+		return ""
+	}
+	return labels[0]
+}
+
+// JWTClaimResolver reads tenant from claim of the access token, default "tenant_id",
+// it must run after authentication.JWTAuthentication.
+type JWTClaimResolver struct {
+	Claim		string
+}
+
+func (r *JWTClaimResolver) Resolve(c echo.Context) string {
+	claim := r.Claim
+	if claim == "" {
+		claim = "tenant_id"
+	}
+	claims, _ := c.Get(authentication.JWTClaimsContextKey).(jwt.MapClaims)
+	value, ok := claims[claim]
+	if !ok || value == nil {
+		return ""
+	}
+	if number, ok := value.(float64); ok {
+		// json numbers are decoded as float64.
+		return fmt.Sprint(int64(number))
+	}
+	return fmt.Sprint(value)
+}
+
+// ResolveFunc is an adapter to use ordinary function as Resolver, it's untrusted since
+// the function may read what the client sends, implement TrustedResolver otherwise.
+type ResolveFunc func(echo.Context) string
+
+func (f ResolveFunc) Trusted() bool {
+	return false
+}
+
+func (f ResolveFunc) Resolve(c echo.Context) string {
+	return f(c)
+}
@@ -0,0 +1,150 @@
+package tenancy
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/conf"
+	"github.com/rimba47prayoga/gorim.git/errors"
+	"github.com/rimba47prayoga/gorim.git/utils"
+	"gorm.io/gorm"
+)
+
+// Config resolves tenant of requests, viewsets then scope models having conf.TENANT_COLUMN
+// to it and serializers assign it on create and update.
+type Config struct {
+	// Resolvers are tried in order, the first non empty tenant wins.
+	Resolvers	[]Resolver
+	// Required responds 400 to requests without tenant.
+	Required	bool
+	// Exists responds 404 when it returns false, example: check the tenant in database.
+	Exists		func(c echo.Context, tenant string) bool
+	// Authorize responds 403 when it returns false, example: check the user is a member
+	// of the tenant. Tenants of untrusted resolvers such as HeaderResolver are refused
+	// without it.
+	Authorize	func(c echo.Context, tenant string) bool
+}
+
+// Default is used by Middleware, resolves tenant from "tenant_id" claim of the access token.
+var Default = &Config{
+	Resolvers: []Resolver{&JWTClaimResolver{}},
+}
+
+// Middleware resolves tenant with Default config,
+// example: router.Group("/api", authentication.Chain(jwtAuth), tenancy.Middleware)
+func Middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return Default.Middleware(next)
+}
+
+// Middleware resolves tenant and stores it on context, read it with gorim.Context.Tenant().
+func (config *Config) Middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		tenant, trusted := config.resolve(c)
+		if tenant == "" {
+			if config.Required {
+				return errors.Handle(&errors.APIError{
+					Status: http.StatusBadRequest,
+					Message: "Tenant is required.",
+					Code: "tenant_required",
+				}, c)
+			}
+			return next(c)
+		}
+		if !config.authorized(c, tenant, trusted) {
+			return errors.Handle(&errors.APIError{
+				Status: http.StatusForbidden,
+				Message: "You do not have access to this tenant.",
+				Code: "tenant_forbidden",
+			}, c)
+		}
+		if config.Exists != nil && !config.Exists(c, tenant) {
+			return errors.Handle(&errors.APIError{
+				Status: http.StatusNotFound,
+				Message: "Tenant not found.",
+				Code: "tenant_not_found",
+			}, c)
+		}
+		c.Set(gorim.TenantContextKey, tenant)
+		return next(c)
+	}
+}
+
+// authorized reports whether the requester may use tenant, tenants of untrusted
+// resolvers require Authorize.
+func (config *Config) authorized(c echo.Context, tenant string, trusted bool) bool {
+	if config.Authorize == nil {
+		return trusted
+	}
+	return config.Authorize(c, tenant)
+}
+
+func (config *Config) Resolve(c echo.Context) string {
+	tenant, _ := config.resolve(c)
+	return tenant
+}
+
+// resolve returns tenant with whether its resolver is trusted, see TrustedResolver.
+func (config *Config) resolve(c echo.Context) (string, bool) {
+	for _, resolver := range config.Resolvers {
+		if tenant := resolver.Resolve(c); tenant != "" {
+			trusted, ok := resolver.(TrustedResolver)
+			return tenant, !ok || trusted.Trusted()
+		}
+	}
+	return "", false
+}
+
+// GetTenant returns tenant stored by Middleware, empty when there's none.
+func GetTenant(c echo.Context) string {
+	if c == nil {
+		return ""
+	}
+	tenant, _ := c.Get(gorim.TenantContextKey).(string)
+	return tenant
+}
+
+// IsTenantModel reports whether model has conf.TENANT_COLUMN, always false when it's empty.
+func IsTenantModel(model interface{}) bool {
+	if conf.TENANT_COLUMN == "" {
+		return false
+	}
+	_, err := utils.LookUpFieldName(model, conf.TENANT_COLUMN)
+	return err == nil
+}
+
+// Scope filters queryset of model to tenant of the request, the queryset is returned
+// as is when model has no conf.TENANT_COLUMN. Without tenant it matches nothing.
+func Scope(c echo.Context, queryset *gorm.DB, model interface{}) *gorm.DB {
+	if !IsTenantModel(model) {
+		return queryset
+	}
+	tenant := GetTenant(c)
+	if tenant == "" {
+		return queryset.Where("1 = 0")
+	}
+	// tenant is parsed into the type of the column, so it's compared as number for integer keys.
+	value, err := utils.ParseFieldString(model, conf.TENANT_COLUMN, tenant)
+	if err != nil {
+		// tenant which isn't valid for the column matches nothing.
+		return queryset.Where("1 = 0")
+	}
+	return queryset.Where(conf.TENANT_COLUMN + " = ?", value)
+}
+
+// Assign sets conf.TENANT_COLUMN of instance to tenant of the request, the tenant sent
+// by the client is never kept, so requests without tenant can't save tenant models.
+func Assign(c echo.Context, instance interface{}) error {
+	if !IsTenantModel(instance) {
+		return nil
+	}
+	tenant := GetTenant(c)
+	if tenant == "" {
+		return &errors.APIError{
+			Status: http.StatusBadRequest,
+			Message: "Tenant is required.",
+			Code: "tenant_required",
+		}
+	}
+	return utils.SetFieldFromString(instance, conf.TENANT_COLUMN, tenant)
+}
@@ -0,0 +1,57 @@
+package throttling
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/rimba47prayoga/gorim.git/cache"
+)
+
+// CacheStore counts requests of fixed windows in a cache, so throttling shares the
+// backend configured for the cache. Windows start at multiples of the rate duration,
+// a client may send up to twice the limit around a window boundary. Requests are
+// allowed when the cache fails.
+type CacheStore struct {
+	// Cache defaults to cache.Default namespaced by "throttle".
+	Cache		*cache.Cache
+	Timeout		time.Duration
+}
+
+func NewCacheStore(c *cache.Cache) *CacheStore {
+	return &CacheStore{
+		Cache: c,
+	}
+}
+
+func (s *CacheStore) GetCache() *cache.Cache {
+	if s.Cache == nil {
+		return cache.Default.Namespace("throttle")
+	}
+	return s.Cache
+}
+
+func (s *CacheStore) Hit(key string, limit int, duration time.Duration, now time.Time) Result {
+	timeout := s.Timeout
+	if timeout == 0 {
+		timeout = time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	start := now.Truncate(duration)
+	reset := start.Add(duration).Sub(now)
+	count, err := s.GetCache().Incr(ctx, key + ":" + strconv.FormatInt(start.UnixMilli(), 10), 1, duration)
+	if err != nil {
+		return Result{Allowed: true, Limit: limit, Remaining: limit}
+	}
+	result := Result{
+		Allowed: count <= int64(limit),
+		Limit: limit,
+		Remaining: max(limit - int(count), 0),
+		Reset: reset,
+	}
+	if !result.Allowed {
+		result.RetryAfter = reset
+	}
+	return result
+}
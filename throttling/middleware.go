@@ -0,0 +1,86 @@
+package throttling
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/authentication"
+	"github.com/rimba47prayoga/gorim.git/errors"
+)
+
+// KeyFunc returns the throttle key of request, empty key is not throttled.
+type KeyFunc func(gorim.Context) string
+
+// KeyByIP throttles by client IP.
+func KeyByIP(ctx gorim.Context) string {
+	return "ip_" + ctx.RealIP()
+}
+
+// KeyByUser throttles by user ID, anonymous requests by IP.
+func KeyByUser(ctx gorim.Context) string {
+	return GetIdent(ctx)
+}
+
+// KeyByAPIKey throttles by the API key set by authentication.APIKeyAuthentication,
+// falls back to KeyByUser.
+func KeyByAPIKey(ctx gorim.Context) string {
+	if apiKey := authentication.GetAPIKey(ctx); apiKey != nil {
+		return fmt.Sprintf("api_key_%d", apiKey.ID)
+	}
+	return KeyByUser(ctx)
+}
+
+// MiddlewareConfig throttles every request passing the middleware.
+type MiddlewareConfig struct {
+	// Scope names the rate in Rates, also part of the store key.
+	Scope		string
+	// Rate overrides Rates[Scope].
+	Rate		string
+	Store		Store
+	// KeyFunc defaults to KeyByUser.
+	KeyFunc		KeyFunc
+	Skipper		func(gorim.Context) bool
+}
+
+// Middleware throttles requests and sets RateLimit-Limit, RateLimit-Remaining
+// and RateLimit-Reset headers, throttled requests get 429 with Retry-After,
+// example: server.Use(throttling.Middleware(throttling.MiddlewareConfig{Scope: "api", Rate: "600/minute"}))
+func Middleware(config MiddlewareConfig) echo.MiddlewareFunc {
+	throttle := &SimpleRateThrottle{
+		Scope: config.Scope,
+		Rate: config.Rate,
+		Store: config.Store,
+	}
+	keyFunc := config.KeyFunc
+	if keyFunc == nil {
+		keyFunc = KeyByUser
+	}
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx := gorim.NewContext(c)
+			if config.Skipper != nil && config.Skipper(ctx) {
+				return next(c)
+			}
+			result := throttle.Check(throttle.GetScope("default"), keyFunc(ctx))
+			if result.Limit == 0 {
+				return next(c)
+			}
+			header := c.Response().Header()
+			header.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+			header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			header.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+			if !result.Allowed {
+				return errors.Handle(&errors.ThrottledError{Wait: result.RetryAfter}, c)
+			}
+			return next(c)
+		}
+	}
+}
+
+func ceilSeconds(duration time.Duration) int {
+	return int(math.Ceil(duration.Seconds()))
+}
@@ -0,0 +1,50 @@
+package throttling
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rimba47prayoga/gorim.git/settings"
+)
+
+// Rates configures rate per scope, example: "100/minute", "1000/day".
+// Throttling.Rates of loaded settings override them.
+var Rates = map[string]string{
+	"anon": "100/day",
+	"user": "1000/day",
+}
+
+// rateOf returns rate of scope from settings, then Rates.
+func rateOf(scope string) string {
+	if rate, ok := settings.Get().Throttling.Rates[scope]; ok {
+		return rate
+	}
+	return Rates[scope]
+}
+
+var durations = map[string]time.Duration{
+	"s":	time.Second,
+	"m":	time.Minute,
+	"h":	time.Hour,
+	"d":	24 * time.Hour,
+}
+
+// ParseRate parses "<requests>/<period>", period is second, minute, hour or day,
+// only the first character is used so "10/s" and "10/sec" are valid too.
+func ParseRate(rate string) (int, time.Duration, error) {
+	num, period, found := strings.Cut(rate, "/")
+	if !found || period == "" {
+		return 0, 0, fmt.Errorf("invalid rate: %s", rate)
+	}
+	requests, err := strconv.Atoi(num)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid rate: %s", rate)
+	}
+	duration, ok := durations[strings.ToLower(period[:1])]
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid rate period: %s", rate)
+	}
+	return requests, duration, nil
+}
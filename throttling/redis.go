@@ -0,0 +1,129 @@
+package throttling
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// slidingWindowScript keeps request timestamps (ms) in sorted set KEYS[1].
+// ARGV: now, window, limit, member. Returns {allowed, count, oldest}.
+var slidingWindowScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now - window)
+local count = redis.call("ZCARD", KEYS[1])
+local allowed = 0
+if count < limit then
+	redis.call("ZADD", KEYS[1], now, ARGV[4])
+	count = count + 1
+	allowed = 1
+end
+redis.call("PEXPIRE", KEYS[1], window)
+local oldest = redis.call("ZRANGE", KEYS[1], 0, 0, "WITHSCORES")
+return {allowed, count, oldest[2] or now}
+`)
+
+// tokenBucketScript keeps tokens and last update (ms) in hash KEYS[1].
+// ARGV: now, window, limit. Returns {allowed, tokens as string}.
+var tokenBucketScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+local state = redis.call("HMGET", KEYS[1], "tokens", "updated")
+local tokens = tonumber(state[1]) or limit
+local updated = tonumber(state[2]) or now
+tokens = math.min(limit, tokens + (now - updated) * limit / window)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "updated", now)
+redis.call("PEXPIRE", KEYS[1], window)
+return {allowed, tostring(tokens)}
+`)
+
+// RedisStore shares throttle state between processes, Algorithm defaults to SlidingWindow.
+// Requests are allowed when redis is unavailable so an outage doesn't take the API down.
+type RedisStore struct {
+	Client		redis.UniversalClient
+	Algorithm	Algorithm
+	// Prefix of redis keys, default "gorim:".
+	Prefix		string
+	Timeout		time.Duration
+}
+
+func NewRedisStore(client redis.UniversalClient) *RedisStore {
+	return &RedisStore{
+		Client: client,
+		Algorithm: SlidingWindow,
+	}
+}
+
+func (s *RedisStore) GetPrefix() string {
+	if s.Prefix == "" {
+		return "gorim:"
+	}
+	return s.Prefix
+}
+
+func (s *RedisStore) Hit(key string, limit int, duration time.Duration, now time.Time) Result {
+	timeout := s.Timeout
+	if timeout == 0 {
+		timeout = time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	key = s.GetPrefix() + key
+	nowMs := now.UnixMilli()
+	window := duration.Milliseconds()
+	if s.Algorithm == TokenBucket {
+		values, err := tokenBucketScript.Run(ctx, s.Client, []string{key}, nowMs, window, limit).Slice()
+		if err != nil || len(values) != 2 {
+			return Result{Allowed: true, Limit: limit, Remaining: limit}
+		}
+		tokens, _ := strconv.ParseFloat(fmt.Sprint(values[1]), 64)
+		return bucketResult(toInt64(values[0]) == 1, tokens, limit, duration)
+	}
+	member := fmt.Sprintf("%d-%d", now.UnixNano(), nextSequence())
+	values, err := slidingWindowScript.Run(ctx, s.Client, []string{key}, nowMs, window, limit, member).Slice()
+	if err != nil || len(values) != 3 {
+		return Result{Allowed: true, Limit: limit, Remaining: limit}
+	}
+	count := int(toInt64(values[1]))
+	oldest := time.UnixMilli(toInt64(values[2]))
+	result := Result{
+		Allowed: toInt64(values[0]) == 1,
+		Limit: limit,
+		Remaining: max(limit - count, 0),
+		Reset: oldest.Add(duration).Sub(now),
+	}
+	if !result.Allowed {
+		result.RetryAfter = result.Reset
+	}
+	return result
+}
+
+var sequence atomic.Uint64
+
+// nextSequence keeps sorted set members unique within the same nanosecond.
+func nextSequence() uint64 {
+	return sequence.Add(1)
+}
+
+func toInt64(value interface{}) int64 {
+	switch v := value.(type) {
+	case int64:
+		return v
+	case string:
+		number, _ := strconv.ParseFloat(v, 64)
+		return int64(number)
+	}
+	return 0
+}
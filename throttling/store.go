@@ -0,0 +1,173 @@
+package throttling
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Algorithm of counting requests within the rate.
+type Algorithm string
+
+const (
+	// SlidingWindow allows limit requests in any window of the rate duration.
+	SlidingWindow	Algorithm = "sliding_window"
+	// TokenBucket refills limit tokens evenly over the rate duration, allowing bursts up to limit.
+	TokenBucket		Algorithm = "token_bucket"
+)
+
+// Result of a Hit, used for RateLimit-* headers.
+type Result struct {
+	Allowed		bool
+	Limit		int
+	Remaining	int
+	// Reset is the duration until quota is restored, for sliding windows when the oldest
+	// request of the window expires.
+	Reset		time.Duration
+	// RetryAfter is the duration until the next request is allowed, zero when allowed.
+	RetryAfter	time.Duration
+}
+
+// Store keeps request history per throttle key.
+type Store interface {
+	// Hit records request at now if allowed.
+	Hit(key string, limit int, duration time.Duration, now time.Time) Result
+}
+
+type bucket struct {
+	tokens		float64
+	updated		time.Time
+	// full is when the bucket is refilled, it's then like a new bucket.
+	full		time.Time
+}
+
+// sweepInterval is how often Hit removes keys of clients which stopped sending requests.
+const sweepInterval = time.Minute
+
+// MemoryStore keeps request history in process memory, Algorithm defaults to SlidingWindow.
+type MemoryStore struct {
+	Algorithm	Algorithm
+	mu			sync.Mutex
+	histories	map[string][]time.Time
+	// expires is when every request of histories is out of the window.
+	expires		map[string]time.Time
+	buckets		map[string]*bucket
+	sweptAt		time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		Algorithm: SlidingWindow,
+	}
+}
+
+func NewMemoryTokenBucketStore() *MemoryStore {
+	return &MemoryStore{
+		Algorithm: TokenBucket,
+	}
+}
+
+func (s *MemoryStore) Hit(key string, limit int, duration time.Duration, now time.Time) Result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+	if s.Algorithm == TokenBucket {
+		return s.hitBucket(key, limit, duration, now)
+	}
+	return s.hitWindow(key, limit, duration, now)
+}
+
+// sweep removes expired histories and refilled buckets once per sweepInterval.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.sweptAt) < sweepInterval {
+		return
+	}
+	s.sweptAt = now
+	for key, expires := range s.expires {
+		if !now.Before(expires) {
+			delete(s.histories, key)
+			delete(s.expires, key)
+		}
+	}
+	for key, b := range s.buckets {
+		if !now.Before(b.full) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func (s *MemoryStore) hitWindow(key string, limit int, duration time.Duration, now time.Time) Result {
+	if s.histories == nil {
+		s.histories = map[string][]time.Time{}
+		s.expires = map[string]time.Time{}
+	}
+	history := s.histories[key]
+	// drop requests older than the window, history is ordered oldest first.
+	start := 0
+	for start < len(history) && !history[start].After(now.Add(-duration)) {
+		start++
+	}
+	history = history[start:]
+	result := Result{Limit: limit}
+	if len(history) >= limit {
+		// limit of zero denies requests without history.
+		result.Reset = duration
+		result.RetryAfter = duration
+		if len(history) > 0 {
+			s.histories[key] = history
+			result.Reset = history[0].Add(duration).Sub(now)
+			result.RetryAfter = result.Reset
+		}
+		return result
+	}
+	history = append(history, now)
+	s.histories[key] = history
+	s.expires[key] = now.Add(duration)
+	result.Allowed = true
+	result.Remaining = limit - len(history)
+	result.Reset = history[0].Add(duration).Sub(now)
+	return result
+}
+
+func (s *MemoryStore) hitBucket(key string, limit int, duration time.Duration, now time.Time) Result {
+	if s.buckets == nil {
+		s.buckets = map[string]*bucket{}
+	}
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit), updated: now}
+		s.buckets[key] = b
+	}
+	allowed, tokens := takeToken(b.tokens, now.Sub(b.updated), limit, duration)
+	b.tokens = tokens
+	b.updated = now
+	result := bucketResult(allowed, tokens, limit, duration)
+	b.full = now.Add(result.Reset)
+	return result
+}
+
+// takeToken refills tokens for elapsed then takes one if available.
+func takeToken(tokens float64, elapsed time.Duration, limit int, duration time.Duration) (bool, float64) {
+	perSecond := float64(limit) / duration.Seconds()
+	tokens = math.Min(float64(limit), tokens + elapsed.Seconds() * perSecond)
+	if tokens < 1 {
+		return false, tokens
+	}
+	return true, tokens - 1
+}
+
+func bucketResult(allowed bool, tokens float64, limit int, duration time.Duration) Result {
+	perSecond := float64(limit) / duration.Seconds()
+	result := Result{
+		Allowed: allowed,
+		Limit: limit,
+		Remaining: int(math.Floor(tokens)),
+		Reset: time.Duration((float64(limit) - tokens) / perSecond * float64(time.Second)),
+	}
+	if !allowed {
+		result.RetryAfter = time.Duration((1 - tokens) / perSecond * float64(time.Second))
+	}
+	return result
+}
+
+var DefaultStore Store = NewMemoryStore()
@@ -0,0 +1,108 @@
+package throttling
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/errors"
+	"github.com/rimba47prayoga/gorim.git/utils"
+)
+
+// SimpleRateThrottle throttles by key within the rate of its scope.
+type SimpleRateThrottle struct {
+	Scope		string
+	// Rate overrides Rates[Scope].
+	Rate		string
+	Store		Store
+}
+
+func (t *SimpleRateThrottle) GetScope(defaultScope string) string {
+	if t.Scope == "" {
+		return defaultScope
+	}
+	return t.Scope
+}
+
+func (t *SimpleRateThrottle) GetRate(scope string) string {
+	if t.Rate != "" {
+		return t.Rate
+	}
+	return rateOf(scope)
+}
+
+func (t *SimpleRateThrottle) GetStore() Store {
+	if t.Store == nil {
+		return DefaultStore
+	}
+	return t.Store
+}
+
+// Allow checks the key within the scope, empty key is never throttled.
+func (t *SimpleRateThrottle) Allow(scope string, key string) (bool, time.Duration) {
+	result := t.Check(scope, key)
+	return result.Allowed, result.RetryAfter
+}
+
+// Check is Allow returning the full Result, Limit is zero when not throttled.
+func (t *SimpleRateThrottle) Check(scope string, key string) Result {
+	rate := t.GetRate(scope)
+	if key == "" || rate == "" {
+		return Result{Allowed: true}
+	}
+	limit, duration, err := ParseRate(rate)
+	if err != nil {
+		errors.Raise(&errors.InternalServerError{
+			Message: err.Error(),
+		})
+	}
+	cacheKey := fmt.Sprintf("throttle_%s_%s", scope, key)
+	return t.GetStore().Hit(cacheKey, limit, duration, time.Now())
+}
+
+// GetIdent returns user ID for authenticated request, client IP otherwise.
+func GetIdent(ctx gorim.Context) string {
+	if ctx.IsAuthenticated() {
+		if userID, ok := utils.GetUserID(ctx.User()); ok {
+			return fmt.Sprintf("user_%d", userID)
+		}
+	}
+	return "ip_" + ctx.RealIP()
+}
+
+// AnonRateThrottle limits anonymous requests by IP with Rates["anon"].
+type AnonRateThrottle struct {
+	SimpleRateThrottle
+}
+
+func (t *AnonRateThrottle) AllowRequest(ctx gorim.Context) (bool, time.Duration) {
+	if ctx.IsAuthenticated() {
+		return true, 0
+	}
+	return t.Allow(t.GetScope("anon"), ctx.RealIP())
+}
+
+// UserRateThrottle limits authenticated requests by user ID with Rates["user"],
+// anonymous requests are limited by IP.
+type UserRateThrottle struct {
+	SimpleRateThrottle
+}
+
+func (t *UserRateThrottle) AllowRequest(ctx gorim.Context) (bool, time.Duration) {
+	return t.Allow(t.GetScope("user"), GetIdent(ctx))
+}
+
+// ScopedRateThrottle limits requests with Rates[Scope], scope is shared across viewsets.
+type ScopedRateThrottle struct {
+	SimpleRateThrottle
+}
+
+func NewScopedRateThrottle(scope string) *ScopedRateThrottle {
+	return &ScopedRateThrottle{
+		SimpleRateThrottle: SimpleRateThrottle{Scope: scope},
+	}
+}
+
+func (t *ScopedRateThrottle) AllowRequest(ctx gorim.Context) (bool, time.Duration) {
+	return t.Allow(t.Scope, GetIdent(ctx))
+}
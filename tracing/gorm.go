@@ -0,0 +1,74 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const spanKey = "gorim:tracing_span"
+
+// GormPlugin creates span per query as child of the query context,
+// viewset and serializer queries use the request context so they're nested in the request span,
+// example: conf.DB.Use(tracing.GormPlugin{})
+type GormPlugin struct{}
+
+func (p GormPlugin) Name() string {
+	return "gorim:tracing"
+}
+
+func (p GormPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	return errors.Join(
+		callback.Create().Before("gorm:create").Register("gorim:tracing_before_create", beforeQuery("create")),
+		callback.Create().After("gorm:create").Register("gorim:tracing_after_create", afterQuery),
+		callback.Query().Before("gorm:query").Register("gorim:tracing_before_query", beforeQuery("query")),
+		callback.Query().After("gorm:query").Register("gorim:tracing_after_query", afterQuery),
+		callback.Update().Before("gorm:update").Register("gorim:tracing_before_update", beforeQuery("update")),
+		callback.Update().After("gorm:update").Register("gorim:tracing_after_update", afterQuery),
+		callback.Delete().Before("gorm:delete").Register("gorim:tracing_before_delete", beforeQuery("delete")),
+		callback.Delete().After("gorm:delete").Register("gorim:tracing_after_delete", afterQuery),
+		callback.Row().Before("gorm:row").Register("gorim:tracing_before_row", beforeQuery("row")),
+		callback.Row().After("gorm:row").Register("gorim:tracing_after_row", afterQuery),
+		callback.Raw().Before("gorm:raw").Register("gorim:tracing_before_raw", beforeQuery("raw")),
+		callback.Raw().After("gorm:raw").Register("gorim:tracing_after_raw", afterQuery),
+	)
+}
+
+func beforeQuery(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		_, span := Tracer().Start(
+			db.Statement.Context, "gorm." + operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBOperationName(operation),
+				semconv.DBCollectionName(db.Statement.Table),
+			),
+		)
+		db.InstanceSet(spanKey, span)
+	}
+}
+
+func afterQuery(db *gorm.DB) {
+	value, ok := db.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+	span.SetAttributes(
+		semconv.DBQueryText(db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.RowsAffected),
+	)
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,122 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/rimba47prayoga/gorim.git"
+
+// Config of Setup, spans are exported by Exporter,
+// example: stdouttrace.New() or otlptracegrpc.New(ctx).
+type Config struct {
+	ServiceName		string
+	Exporter		sdktrace.SpanExporter
+	// SampleRatio of root spans, child spans follow the parent, default 1.
+	SampleRatio		float64
+}
+
+// Setup installs the global tracer provider and W3C trace context propagator,
+// call the returned shutdown on exit to flush spans. Without Setup spans are no-op.
+func Setup(config Config) (func(context.Context) error, error) {
+	if config.Exporter == nil {
+		return nil, fmt.Errorf("tracing: exporter is required")
+	}
+	ratio := config.SampleRatio
+	if ratio == 0 {
+		ratio = 1
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(config.ServiceName),
+	))
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(config.Exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	return provider.Shutdown, nil
+}
+
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// StartSpan starts span as child of the span in request context and
+// replaces request context so later spans are nested in it.
+func StartSpan(c echo.Context, name string, attrs ...attribute.KeyValue) trace.Span {
+	ctx, span := Tracer().Start(c.Request().Context(), name, trace.WithAttributes(attrs...))
+	c.SetRequest(c.Request().WithContext(ctx))
+	return span
+}
+
+// SetError records err on span and marks it failed.
+func SetError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// Middleware starts server span per request, continuing the trace from traceparent header.
+func Middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) (err error) {
+		request := c.Request()
+		ctx := otel.GetTextMapPropagator().Extract(request.Context(), propagation.HeaderCarrier(request.Header))
+		route := c.Path()
+		if route == "" {
+			route = request.URL.Path
+		}
+		ctx, span := Tracer().Start(
+			ctx, request.Method + " " + route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(request.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(request.URL.Path),
+				semconv.ClientAddress(c.RealIP()),
+			),
+		)
+		defer span.End()
+		c.SetRequest(request.WithContext(ctx))
+		defer func() {
+			// panics are recovered by outer middleware, keep them on the span.
+			if r := recover(); r != nil {
+				span.SetStatus(codes.Error, fmt.Sprint(r))
+				span.SetAttributes(semconv.HTTPResponseStatusCode(http.StatusInternalServerError))
+				panic(r)
+			}
+		}()
+		err = next(c)
+		status := c.Response().Status
+		if err != nil && !c.Response().Committed {
+			status = http.StatusInternalServerError
+			if httpErr, ok := err.(*echo.HTTPError); ok {
+				status = httpErr.Code
+			}
+			span.RecordError(err)
+		}
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+		return err
+	}
+}
@@ -0,0 +1,149 @@
+package utils
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+
+	"gorm.io/gorm/schema"
+)
+
+var schemaCache = &sync.Map{}
+
+// ErrUnsupportedType is returned for fields which can't be parsed from string.
+var ErrUnsupportedType = errors.New("unsupported type")
+
+// LookUpFieldName returns struct field name by its name or database column name,
+// example: "user_id" => "UserID".
+func LookUpFieldName(obj interface{}, name string) (string, error) {
+	typ := reflect.TypeOf(obj)
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if _, ok := typ.FieldByName(name); ok {
+		return name, nil
+	}
+	field, err := LookUpField(obj, name)
+	if err != nil {
+		return "", err
+	}
+	return field.Name, nil
+}
+
+// GetFieldValue returns dereferenced value of struct field by its name or database column name.
+func GetFieldValue(obj interface{}, name string) (interface{}, error) {
+	fieldName, err := LookUpFieldName(obj, name)
+	if err != nil {
+		return nil, err
+	}
+	value, err := GetStructValue(obj, fieldName)
+	if err != nil {
+		return nil, err
+	}
+	val := reflect.ValueOf(value)
+	if !val.IsValid() {
+		return nil, nil
+	}
+	if val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return nil, nil
+		}
+		return val.Elem().Interface(), nil
+	}
+	return value, nil
+}
+
+// SetFieldFromString parses value into the type of struct field found by its name or database column name.
+func SetFieldFromString(obj interface{}, name string, value string) error {
+	fieldName, err := LookUpFieldName(obj, name)
+	if err != nil {
+		return err
+	}
+	val := reflect.ValueOf(obj)
+	if val.Kind() != reflect.Ptr || val.IsNil() {
+		return fmt.Errorf("expected a pointer to struct")
+	}
+	field := val.Elem().FieldByName(fieldName)
+	if !field.CanSet() {
+		return fmt.Errorf("field %s cannot be set", fieldName)
+	}
+	if field.Kind() == reflect.Ptr {
+		ptr := reflect.New(field.Type().Elem())
+		if err := setFromString(ptr.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(ptr)
+		return nil
+	}
+	return setFromString(field, value)
+}
+
+// ParseFieldString parses value into the type of struct field found by its name or database column name,
+// the value is returned as is when the type can't be parsed from string.
+func ParseFieldString(obj interface{}, name string, value string) (interface{}, error) {
+	typ := reflect.TypeOf(obj)
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	probe := reflect.New(typ).Interface()
+	if err := SetFieldFromString(probe, name, value); err != nil {
+		if errors.Is(err, ErrUnsupportedType) {
+			return value, nil
+		}
+		return nil, err
+	}
+	return GetFieldValue(probe, name)
+}
+
+// LookUpField returns schema field by struct field name or database column name.
+func LookUpField(obj interface{}, name string) (*schema.Field, error) {
+	modelSchema, err := schema.Parse(obj, schemaCache, schema.NamingStrategy{})
+	if err != nil {
+		return nil, err
+	}
+	field := modelSchema.LookUpField(name)
+	if field == nil {
+		return nil, fmt.Errorf("no such field: %s", name)
+	}
+	return field, nil
+}
+
+func setFromString(field reflect.Value, value string) error {
+	if unmarshaler, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return unmarshaler.UnmarshalText([]byte(value))
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		number, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(number)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		number, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(number)
+	case reflect.Bool:
+		boolean, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(boolean)
+	case reflect.Float32, reflect.Float64:
+		number, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(number)
+	default:
+		return fmt.Errorf("cannot set %s from string: %w", field.Type(), ErrUnsupportedType)
+	}
+	return nil
+}
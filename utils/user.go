@@ -0,0 +1,11 @@
+package utils
+
+// GetUserID returns the value of ID field of the given user.
+func GetUserID(user interface{}) (uint, bool) {
+	value, err := GetStructValue(user, "ID")
+	if err != nil || value == nil {
+		return 0, false
+	}
+	id, ok := value.(uint)
+	return id, ok
+}
@@ -0,0 +1,122 @@
+package versioning
+
+import (
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/errors"
+	"github.com/rimba47prayoga/gorim.git/utils"
+)
+
+// Versioning resolves the requested version, empty string when the request doesn't specify it.
+type Versioning interface {
+	DetermineVersion(c echo.Context) string
+	// InvalidStatus is the response status for versions not allowed.
+	InvalidStatus() int
+}
+
+// URLPathVersioning reads version from path segment, example: /api/v1/users.
+// Param is used when the route declares it (/api/:version/users),
+// otherwise the first segment matching Pattern is used.
+type URLPathVersioning struct {
+	Param		string
+	Pattern		*regexp.Regexp
+}
+
+var defaultPathPattern = regexp.MustCompile(`^v\d+(\.\d+)?$`)
+
+func (v *URLPathVersioning) DetermineVersion(c echo.Context) string {
+	if v.Param != "" {
+		return c.Param(v.Param)
+	}
+	pattern := v.Pattern
+	if pattern == nil {
+		pattern = defaultPathPattern
+	}
+	for _, segment := range strings.Split(c.Request().URL.Path, "/") {
+		if pattern.MatchString(segment) {
+			return segment
+		}
+	}
+	return ""
+}
+
+func (v *URLPathVersioning) InvalidStatus() int {
+	return http.StatusNotFound
+}
+
+// AcceptHeaderVersioning reads version from media type param, example:
+// Accept: application/json; version=1.0
+type AcceptHeaderVersioning struct {
+	Param		string
+}
+
+func (v *AcceptHeaderVersioning) DetermineVersion(c echo.Context) string {
+	param := v.Param
+	if param == "" {
+		param = "version"
+	}
+	for _, mediaRange := range strings.Split(c.Request().Header.Get(echo.HeaderAccept), ",") {
+		_, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil {
+			continue
+		}
+		if version, ok := params[param]; ok {
+			return version
+		}
+	}
+	return ""
+}
+
+func (v *AcceptHeaderVersioning) InvalidStatus() int {
+	return http.StatusNotAcceptable
+}
+
+// HeaderVersioning reads version from custom header, default X-API-Version.
+type HeaderVersioning struct {
+	Header		string
+}
+
+func (v *HeaderVersioning) DetermineVersion(c echo.Context) string {
+	header := v.Header
+	if header == "" {
+		header = "X-API-Version"
+	}
+	return c.Request().Header.Get(header)
+}
+
+func (v *HeaderVersioning) InvalidStatus() int {
+	return http.StatusNotAcceptable
+}
+
+type Config struct {
+	Versioning			Versioning
+	DefaultVersion		string
+	// AllowedVersions, empty allows any version.
+	AllowedVersions		[]string
+}
+
+// Middleware resolves the version and stores it on context, read it with gorim.Context.Version().
+func Middleware(config Config) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			version := config.Versioning.DetermineVersion(c)
+			if version == "" {
+				version = config.DefaultVersion
+			}
+			if len(config.AllowedVersions) > 0 && !utils.Contains(config.AllowedVersions, version) {
+				return errors.Handle(&errors.APIError{
+					Status: config.Versioning.InvalidStatus(),
+					Message: "Invalid version in request.",
+					Code: "invalid_version",
+				}, c)
+			}
+			c.Set(gorim.VersionContextKey, version)
+			return next(c)
+		}
+	}
+}
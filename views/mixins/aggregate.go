@@ -0,0 +1,75 @@
+package mixins
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Metric is an aggregate function of a column, Column is empty for COUNT(*).
+type Metric struct {
+	Name		string
+	Func		string
+	Column		string
+}
+
+func Count(name string) Metric {
+	return Metric{Name: name, Func: "COUNT"}
+}
+
+func CountDistinct(column string, name string) Metric {
+	return Metric{Name: name, Func: "COUNT(DISTINCT", Column: column}
+}
+
+func Sum(column string, name string) Metric {
+	return Metric{Name: name, Func: "SUM", Column: column}
+}
+
+func Avg(column string, name string) Metric {
+	return Metric{Name: name, Func: "AVG", Column: column}
+}
+
+func Min(column string, name string) Metric {
+	return Metric{Name: name, Func: "MIN", Column: column}
+}
+
+func Max(column string, name string) Metric {
+	return Metric{Name: name, Func: "MAX", Column: column}
+}
+
+func (m Metric) expression() string {
+	if m.Column == "" {
+		return m.Func + "(*)"
+	}
+	if strings.HasSuffix(m.Func, "(DISTINCT") {
+		return fmt.Sprintf("%s %s)", m.Func, m.Column)
+	}
+	return fmt.Sprintf("%s(%s)", m.Func, m.Column)
+}
+
+// Aggregation declares summary of the filtered queryset served at /aggregate/:aggregation,
+// rows are objects keyed by GroupBy columns and metric names, a single object without GroupBy.
+//
+//	Aggregations: map[string]mixins.Aggregation{
+//		"by-status": {GroupBy: []string{"status"}, Metrics: []mixins.Metric{mixins.Count("total"), mixins.Sum("amount", "revenue")}},
+//	}
+type Aggregation struct {
+	GroupBy		[]string
+	Metrics		[]Metric
+	// OrderBy of the rows, example: "total DESC".
+	OrderBy		string
+	// Serializer is struct the rows are scanned into, its json tags render them,
+	// nil renders rows as maps.
+	Serializer	interface{}
+}
+
+func (a Aggregation) selects() []string {
+	selects := append([]string{}, a.GroupBy...)
+	for _, metric := range a.Metrics {
+		selects = append(selects, fmt.Sprintf("%s AS %s", metric.expression(), metric.Name))
+	}
+	return selects
+}
+
+func (h *GenericViewSet[T]) HasAggregations() bool {
+	return len(h.Aggregations) > 0
+}
@@ -0,0 +1,70 @@
+package mixins
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/errors"
+	"gorm.io/gorm"
+)
+
+
+// AggregateMixin serves Aggregations of the viewset, routed when it has any.
+type AggregateMixin[T any] struct {
+	GenericViewSet[T]
+}
+
+func NewAggregateMixin[T any](
+	genericViewSet GenericViewSet[T],
+) *AggregateMixin[T] {
+	return &AggregateMixin[T]{
+		GenericViewSet: genericViewSet,
+	}
+}
+
+// @Router [GET] /api/v1/{feature}/aggregate/{aggregation}
+func (h *AggregateMixin[T]) Aggregate(
+	c gorim.Context,
+) error {
+	aggregation, ok := h.Aggregations[c.Param("aggregation")]
+	if !ok {
+		return errors.Handle(&errors.ObjectNotFoundError{Message: "Resource not found"}, c)
+	}
+	// same filters as List, ordering of filters is replaced since it may not be grouped.
+	queryset := h.Child.GetFilteredQuerySet().Session(&gorm.Session{})
+	delete(queryset.Statement.Clauses, "ORDER BY")
+	queryset = queryset.Select(aggregation.selects())
+	if len(aggregation.GroupBy) > 0 {
+		queryset = queryset.Group(strings.Join(aggregation.GroupBy, ", "))
+	}
+	if aggregation.OrderBy != "" {
+		queryset = queryset.Order(aggregation.OrderBy)
+	}
+	var rows reflect.Value
+	if aggregation.Serializer != nil {
+		rows = reflect.New(reflect.SliceOf(indirect(reflect.TypeOf(aggregation.Serializer))))
+	} else {
+		rows = reflect.ValueOf(&[]map[string]interface{}{})
+	}
+	if err := queryset.Scan(rows.Interface()).Error; err != nil {
+		// database errors are reported as 500 without their message, it may carry sql.
+		return errors.Handle(err, c)
+	}
+	results := rows.Elem()
+	if len(aggregation.GroupBy) > 0 {
+		return c.Respond(http.StatusOK, results.Interface())
+	}
+	if results.Len() == 0 {
+		return c.Respond(http.StatusOK, gorim.Response{})
+	}
+	return c.Respond(http.StatusOK, results.Index(0).Interface())
+}
+
+func indirect(typ reflect.Type) reflect.Type {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/errors"
 )
 
 
@@ -25,8 +26,8 @@ func (h *CreateMixin[T]) Create(
 ) error {
 	serializer := *h.Child.GetSerializer()
 	if !serializer.IsValid() {
-		return c.JSON(http.StatusBadRequest, serializer.GetErrors())
+		return errors.Handle(serializer.GetErrors(), c)
 	}
 	data := serializer.Create()
-	return c.JSON(http.StatusCreated, data)
+	return c.Respond(http.StatusCreated, h.Child.ToRepresentation(data))
 }
@@ -0,0 +1,27 @@
+package mixins
+
+import (
+	"net/http"
+
+	"github.com/rimba47prayoga/gorim.git"
+)
+
+
+type DestroyMixin[T any] struct {
+	GenericViewSet[T]
+}
+
+func NewDestroyMixin[T any](
+	genericViewSet GenericViewSet[T],
+) *DestroyMixin[T] {
+	return &DestroyMixin[T]{
+		GenericViewSet: genericViewSet,
+	}
+}
+
+// @Router [DELETE] /api/v1/{feature}/:id
+func (h *DestroyMixin[T]) Destroy(c gorim.Context) error {
+	instance := h.Child.GetObject()
+	h.Child.PerformDestroy(instance)
+	return c.NoContent(http.StatusNoContent)
+}
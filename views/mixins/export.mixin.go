@@ -0,0 +1,28 @@
+package mixins
+
+import (
+	"github.com/rimba47prayoga/gorim.git"
+)
+
+
+// ExportMixin streams the filtered queryset, route it as extra action:
+//
+//	_ routers.ActionTag `action:"Export"`
+type ExportMixin[T any] struct {
+	GenericViewSet[T]
+}
+
+func NewExportMixin[T any](
+	genericViewSet GenericViewSet[T],
+) *ExportMixin[T] {
+	return &ExportMixin[T]{
+		GenericViewSet: genericViewSet,
+	}
+}
+
+// @Router [GET] /api/v1/{feature}/export
+func (h *ExportMixin[T]) Export(
+	c gorim.Context,
+) error {
+	return h.Child.StreamQuerySet(h.Child.GetFilteredQuerySet())
+}
@@ -2,15 +2,25 @@ package mixins
 
 import (
 	"fmt"
+	"net/http"
 	"reflect"
+	"time"
 
+	"github.com/labstack/echo/v4"
 	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/cache"
 	"github.com/rimba47prayoga/gorim.git/conf"
 	"github.com/rimba47prayoga/gorim.git/errors"
 	"github.com/rimba47prayoga/gorim.git/filters"
 	"github.com/rimba47prayoga/gorim.git/interfaces"
 	"github.com/rimba47prayoga/gorim.git/pagination"
+	"github.com/rimba47prayoga/gorim.git/parsers"
+	"github.com/rimba47prayoga/gorim.git/permissions"
+	"github.com/rimba47prayoga/gorim.git/policies"
+	"github.com/rimba47prayoga/gorim.git/renderers"
 	"github.com/rimba47prayoga/gorim.git/serializers"
+	"github.com/rimba47prayoga/gorim.git/signals"
+	"github.com/rimba47prayoga/gorim.git/tenancy"
 	"github.com/rimba47prayoga/gorim.git/utils"
 	"gorm.io/gorm"
 )
@@ -22,7 +32,16 @@ type IGenericViewSet[T any] interface {
 	GetObject() *T
 	GetSerializer() *serializers.IModelSerializer[T]
 	GetSerializerStruct() serializers.IModelSerializer[T]
+	GetPermissions(gorim.Context) []interfaces.IPermission
+	GetThrottles(gorim.Context) []interfaces.IThrottle
+	GetCacheControl(gorim.Context) *cache.Control
+	GetPartialSerializer(*T) *serializers.IModelSerializer[T]
+	ToRepresentation(interface{}) interface{}
+	PerformDestroy(*T)
+	PerformRestore(*T)
 	FilterQuerySet(interface{}, *gorm.DB) *gorm.DB
+	GetFilteredQuerySet() *gorm.DB
+	StreamQuerySet(*gorm.DB) error
 	PaginateQuerySet(interface{}, *gorm.DB) *pagination.Pagination
 }
 
@@ -31,8 +50,32 @@ type GenericViewSetParams[T any] struct {
 	QuerySet		*gorm.DB
 	PKField			string
 	Serializer		serializers.IModelSerializer[T]
+	// VersionSerializers selects serializer by API version, falls back to Serializer.
+	VersionSerializers	map[string]serializers.IModelSerializer[T]
 	Filter			filters.IFilterSet
 	Permissions		[]interfaces.IPermission
+	Throttles		[]interfaces.IThrottle
+	// Renderers negotiated by Accept header, empty uses renderers.Defaults().
+	Renderers		[]renderers.Renderer
+	// Parsers of request body by Content-Type, take precedence over parsers.Register.
+	Parsers			[]parsers.Parser
+	SoftDelete		*SoftDelete
+	// Using names the connection in conf.DATABASES serving reads and writes of the viewset.
+	Using			string
+	// Policies scope every queryset of the viewset and fill fields on writes, see policies.OwnedByUser.
+	Policies		[]policies.Policy
+	// Aggregations are served by Aggregate at /aggregate/:aggregation, see Aggregation.
+	Aggregations	map[string]Aggregation
+	// CachePage caches responses of List and Retrieve, see cache.Page.
+	CachePage		*cache.Page
+	// CacheControl sets Cache-Control, Vary and Expires of GET responses, responses
+	// of other methods get no-store. Override GetCacheControl to vary it by action.
+	CacheControl	*cache.Control
+	// Realtime publishes changes of the model, streamed by Watch at /watch, see Watch.
+	Realtime		*Watch
+	// Links adds "_links" of the detail actions the requester may perform to rendered
+	// objects, see routers.Linker.
+	Links			bool
 	Child			IGenericViewSet[T]
 }
 
@@ -42,8 +85,20 @@ type GenericViewSet[T any] struct {
 	QuerySet		*gorm.DB
 	PKField			string
 	Serializer		serializers.IModelSerializer[T]
+	VersionSerializers	map[string]serializers.IModelSerializer[T]
 	Filter			filters.IFilterSet
 	Permissions		[]interfaces.IPermission
+	Throttles		[]interfaces.IThrottle
+	Renderers		[]renderers.Renderer
+	Parsers			[]parsers.Parser
+	SoftDelete		*SoftDelete
+	Using			string
+	Policies		[]policies.Policy
+	Aggregations	map[string]Aggregation
+	CachePage		*cache.Page
+	CacheControl	*cache.Control
+	Realtime		*Watch
+	Links			bool
 	Action			string
 	Context			gorim.Context
 	Child			IGenericViewSet[T]
@@ -57,12 +112,32 @@ func NewGenericViewSet[T any](
 	if queryset == nil {
 		queryset = conf.DB.Model(&model)
 	}
+	if params.Realtime != nil && params.Realtime.Enabled {
+		pkField := params.PKField
+		if pkField == "" {
+			pkField = "id"
+		}
+		publishChanges[T](params.Realtime.GetGroup(&model), pkField)
+	}
 	return &GenericViewSet[T]{
 		Model: &model,
 		QuerySet: queryset,
+		PKField: params.PKField,
 		Serializer: params.Serializer,
+		VersionSerializers: params.VersionSerializers,
 		Filter: params.Filter,
 		Permissions: params.Permissions,
+		Throttles: params.Throttles,
+		Renderers: params.Renderers,
+		Parsers: params.Parsers,
+		SoftDelete: params.SoftDelete,
+		Using: params.Using,
+		Policies: params.Policies,
+		Aggregations: params.Aggregations,
+		CachePage: params.CachePage,
+		CacheControl: params.CacheControl,
+		Realtime: params.Realtime,
+		Links: params.Links,
 		Child: params.Child,
 	}
 }
@@ -78,22 +153,129 @@ func (h *GenericViewSet[T]) GetPermissions(c gorim.Context) []interfaces.IPermis
 	return h.Permissions
 }
 
+// getPermissions respects GetPermissions overridden by the child viewset.
+func (h *GenericViewSet[T]) getPermissions(c gorim.Context) []interfaces.IPermission {
+	if h.Child != nil {
+		return h.Child.GetPermissions(c)
+	}
+	return h.GetPermissions(c)
+}
+
 func (h *GenericViewSet[T]) HasPermission(c gorim.Context) bool {
-	permissions := h.GetPermissions(c)
+	return h.CheckPermissions(c) == nil
+}
+
+// CheckPermissions returns the first permission denying the request, nil when allowed.
+func (h *GenericViewSet[T]) CheckPermissions(c gorim.Context) interfaces.IPermission {
+	permissions := h.getPermissions(c)
 	for _, permission := range permissions {
 		if !permission.HasPermission(c) {
-			return false
+			return permission
+		}
+	}
+	return nil
+}
+
+func (h *GenericViewSet[T]) GetThrottles(c gorim.Context) []interfaces.IThrottle {
+	return h.Throttles
+}
+
+// CheckThrottles returns false with the longest wait when any throttle denies the request.
+func (h *GenericViewSet[T]) CheckThrottles(c gorim.Context) (bool, time.Duration) {
+	throttles := h.GetThrottles(c)
+	if h.Child != nil {
+		throttles = h.Child.GetThrottles(c)
+	}
+	allowed := true
+	var wait time.Duration
+	for _, throttle := range throttles {
+		if ok, duration := throttle.AllowRequest(c); !ok {
+			allowed = false
+			if duration > wait {
+				wait = duration
+			}
+		}
+	}
+	return allowed, wait
+}
+
+func (h *GenericViewSet[T]) GetCacheControl(c gorim.Context) *cache.Control {
+	return h.CacheControl
+}
+
+// ServeCached applies cache control of the viewset and serves action from CachePage
+// when it caches the action.
+func (h *GenericViewSet[T]) ServeCached(c gorim.Context, action string, run func() error) error {
+	control := h.GetCacheControl(c)
+	if h.Child != nil {
+		control = h.Child.GetCacheControl(c)
+	}
+	if control != nil {
+		if method := c.Request().Method; method == http.MethodGet || method == http.MethodHead {
+			control.Apply(c)
+		} else {
+			cache.NoStore.Apply(c)
 		}
 	}
-	return true
+	if h.CachePage == nil || !h.CachePage.Caches(action) {
+		return run()
+	}
+	return h.CachePage.Serve(c, run)
 }
 
 // TODO: move validation from router to here.
 func (h *GenericViewSet[T]) CheckPermission() {}
 
+// CheckObjectPermissions raises PermissionDeniedError when any object permission denies the instance.
+func (h *GenericViewSet[T]) CheckObjectPermissions(instance *T) {
+	for _, permission := range h.getPermissions(h.Context) {
+		objectPermission, ok := permission.(interfaces.IObjectPermission)
+		if !ok {
+			continue
+		}
+		if !objectPermission.HasObjectPermission(h.Context, instance) {
+			message, code := "You do not have permission to perform this action.", errors.CodePermissionDenied
+			if permissionMessage, ok := permission.(interfaces.IPermissionMessage); ok {
+				if permissionMessage.GetMessage() != "" {
+					message = permissionMessage.GetMessage()
+				}
+				if permissionMessage.GetCode() != "" {
+					code = permissionMessage.GetCode()
+				}
+			}
+			permissions.FireDenied(permissions.DenialEvent{
+				Request: h.Context.Request(),
+				User: h.Context.User(),
+				Permission: permission,
+				Action: h.Action,
+				Status: http.StatusForbidden,
+				Code: code,
+				Reason: message,
+			})
+			errors.Raise(&errors.PermissionDeniedError{
+				Message: message,
+				Code: code,
+			})
+		}
+	}
+}
+
 
 func (h *GenericViewSet[T]) SetContext(c gorim.Context) {
 	h.Context = c
+	if h.Using != "" && c.Context != nil {
+		request := c.Request()
+		c.SetRequest(request.WithContext(conf.Using(request.Context(), h.Using)))
+	}
+	if len(h.Renderers) > 0 {
+		c.Set(renderers.RenderersContextKey, h.Renderers)
+	}
+	if len(h.Parsers) > 0 {
+		c.Set(parsers.ParsersContextKey, h.Parsers)
+	}
+	if len(h.Policies) > 0 {
+		c.Set(policies.PoliciesContextKey, h.Policies)
+	}
 }
 
 
@@ -106,9 +288,7 @@ func(h *GenericViewSet[T]) SetupSerializer(
 ) *serializers.IModelSerializer[T] {
 	serializer.SetContext(h.Context)
 	if err := h.Context.Bind(&serializer); err != nil {
-		errors.Raise(&errors.InternalServerError{
-			Message: err.Error(),
-		})
+		errors.Raise(bindError(err))
 	}
 	serializer.SetChild(serializer)
 	return &serializer
@@ -119,15 +299,75 @@ func(h *GenericViewSet[T]) GetSerializer() *serializers.IModelSerializer[T] {
 	return h.SetupSerializer(serializer)
 }
 
+// GetPartialSerializer fills the serializer from instance before binding the request,
+// so fields missing from the request body keep their current values. Fields the user
+// can't access aren't filled, they'd be rejected as sent by ValidateFieldPermissions.
+func(h *GenericViewSet[T]) GetPartialSerializer(instance *T) *serializers.IModelSerializer[T] {
+	serializer := h.Child.GetSerializerStruct()
+	serializer.SetChild(serializer)
+	denied := serializers.GetDeniedFields(serializer, h.Context.Context)
+	for _, field := range serializer.Fields() {
+		if utils.Contains(denied, field) {
+			continue
+		}
+		value, err := utils.GetStructValue(instance, field)
+		if err != nil {
+			continue
+		}
+		// ignore fields which type differs from the model.
+		utils.SetStructValue(serializer, field, value)
+	}
+	return h.SetupSerializer(serializer)
+}
+
 func(h *GenericViewSet[T]) GetSerializerStruct() serializers.IModelSerializer[T] {
+	if h.Context.Context != nil {
+		if serializer, ok := h.VersionSerializers[h.Context.Version()]; ok {
+			return serializer
+		}
+	}
 	return h.Serializer
 }
 
+// ToRepresentation renders data through the serializer without binding the request.
+func(h *GenericViewSet[T]) ToRepresentation(data interface{}) interface{} {
+	serializer := h.Child.GetSerializerStruct()
+	serializer.SetContext(h.Context)
+	serializer.SetChild(serializer)
+	result := serializer.ToRepresentation(data)
+	if h.Links {
+		return h.withLinks(data, result)
+	}
+	return result
+}
+
 func (h *GenericViewSet[T]) GetQuerySet() *gorm.DB {
-	if h.Action == "ListDeleted" {
-		return h.QuerySet.Unscoped().Where("deleted_at IS NOT NULL")
+	queryset := h.QuerySet
+	if h.Context.Context != nil {
+		request := h.Context.Request()
+		// reads of safe requests are served by replicas, see conf.REPLICAS.
+		if utils.Contains(permissions.SafeMethods, request.Method) {
+			queryset = conf.BindReadDB(queryset, request.Context())
+		} else {
+			queryset = conf.BindDB(queryset, request.Context())
+		}
+	}
+	if h.SoftDeleteEnabled() {
+		queryset = h.softDeleteScope(queryset)
+	}
+	queryset = tenancy.Scope(h.Context.Context, queryset, h.Model)
+	for _, policy := range h.Policies {
+		queryset = policy.Filter(h.Context.Context, queryset, h.Model)
 	}
-	return h.QuerySet
+	return h.FilterParentLookups(queryset)
+}
+
+// FilterParentLookups scopes queryset of nested viewset to its parent.
+func (h *GenericViewSet[T]) FilterParentLookups(queryset *gorm.DB) *gorm.DB {
+	for field, value := range h.Context.GetParentLookups() {
+		queryset = queryset.Where(field + " = ?", value)
+	}
+	return queryset
 }
 
 func (h *GenericViewSet[T]) GetObject() *T {
@@ -143,10 +383,31 @@ func (h *GenericViewSet[T]) GetObject() *T {
 	}
 	pkField := h.GetPKField()
 	queryset := h.GetQuerySet()
-	result := utils.GetObjectOr404[T](queryset, pkField + " = ?", pk)
+	result := utils.GetObjectOr404[T](queryset, pkField + " = ?", h.ParseLookup(pkField, pk))
+	h.CheckObjectPermissions(result)
 	return result
 }
 
+func (h *GenericViewSet[T]) PerformDestroy(instance *T) {
+	db := conf.DB
+	if h.Context.Context != nil {
+		db = conf.GetDB(h.Context.Request().Context())
+	}
+	signals.Send(signals.PreDelete, h.Context.Context, instance, false)
+	var err error
+	if h.SoftDeleteEnabled() {
+		err = h.softDelete(db, instance)
+	} else {
+		err = db.Delete(instance).Error
+	}
+	if err != nil {
+		errors.Raise(&errors.InternalServerError{
+			Message: err.Error(),
+		})
+	}
+	signals.Send(signals.PostDelete, h.Context.Context, instance, false)
+}
+
 func (h *GenericViewSet[T]) GetModelSlice() reflect.Value {
 	// it will dynamically return slice of model specified in BaseHandler.Model
 	// example: []models.User
@@ -168,12 +429,7 @@ func (h *GenericViewSet[T]) FilterQuerySet(
 	if h.Filter == nil {
 		return queryset
 	}
-	if err := h.Context.Bind(h.Filter); err != nil {
-		errors.Raise(&errors.InternalServerError{
-			Message: err.Error(),
-		})
-	}
-	queryset = h.Filter.ApplyFilters(h.Filter, h.Context, queryset)
+	queryset = h.applyFilter(queryset)
 
 	err := queryset.Find(results).Error
 	if err != nil {
@@ -184,6 +440,52 @@ func (h *GenericViewSet[T]) FilterQuerySet(
 	return queryset
 }
 
+// GetFilteredQuerySet returns queryset with filters applied without loading the results.
+func (h *GenericViewSet[T]) GetFilteredQuerySet() *gorm.DB {
+	queryset := h.GetQuerySet()
+	if h.Filter == nil {
+		return queryset
+	}
+	return h.applyFilter(queryset)
+}
+
+func (h *GenericViewSet[T]) applyFilter(queryset *gorm.DB) *gorm.DB {
+	if err := h.Context.Bind(h.Filter); err != nil {
+		errors.Raise(bindError(err))
+	}
+	return h.Filter.ApplyFilters(h.Filter, h.Context, queryset)
+}
+
+// StreamQuerySet writes rows of queryset one by one as NDJSON or server-sent events
+// using Rows, so the results aren't loaded into a slice. Preload isn't applied to streamed rows.
+func (h *GenericViewSet[T]) StreamQuerySet(queryset *gorm.DB) error {
+	rows, err := queryset.Rows()
+	if err != nil {
+		return &errors.InternalServerError{Message: err.Error()}
+	}
+	defer rows.Close()
+	writer := renderers.NewStreamWriter(h.Context, http.StatusOK)
+	child := h.Child
+	for rows.Next() {
+		var instance T
+		if err := queryset.ScanRows(rows, &instance); err != nil {
+			return writer.Error(&errors.InternalServerError{Message: err.Error()})
+		}
+		var item interface{} = &instance
+		if child != nil {
+			item = child.ToRepresentation(&instance)
+		}
+		if err := writer.Write(item); err != nil {
+			// client has gone away.
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return writer.Error(&errors.InternalServerError{Message: err.Error()})
+	}
+	return writer.Close()
+}
+
 func (h *GenericViewSet[T]) PaginateQuerySet(
 	results interface{},
 	queryset *gorm.DB,
@@ -192,3 +494,22 @@ func (h *GenericViewSet[T]) PaginateQuerySet(
 	pagination.PaginateQuery(results)
 	return pagination
 }
+
+// bindError converts malformed body or query into non_field_errors,
+// errors with other status such as too large upload are kept.
+func bindError(err error) error {
+	if apiErr, ok := err.(errors.APIException); ok {
+		return apiErr
+	}
+	message := err.Error()
+	if httpErr, ok := err.(*echo.HTTPError); ok {
+		if httpErr.Code != http.StatusBadRequest {
+			// example: 415 for content type without parser.
+			return httpErr
+		}
+		message = fmt.Sprint(httpErr.Message)
+	}
+	validationErrors := errors.ValidationErrors{}
+	validationErrors.Add(errors.NonFieldErrors, message)
+	return validationErrors
+}
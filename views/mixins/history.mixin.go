@@ -0,0 +1,38 @@
+package mixins
+
+import (
+	"net/http"
+
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/audit"
+	"github.com/rimba47prayoga/gorim.git/conf"
+)
+
+
+// HistoryMixin lists audit entries of the object, the model must be registered
+// with audit.Register, route it as extra action:
+//
+//	_ routers.ActionTag `action:"History" detail:"true"`
+type HistoryMixin[T any] struct {
+	GenericViewSet[T]
+}
+
+func NewHistoryMixin[T any](
+	genericViewSet GenericViewSet[T],
+) *HistoryMixin[T] {
+	return &HistoryMixin[T]{
+		GenericViewSet: genericViewSet,
+	}
+}
+
+// @Router [GET] /api/v1/{feature}/{id}/history
+func (h *HistoryMixin[T]) History(
+	c gorim.Context,
+) error {
+	viewset := h.Child
+	instance := viewset.GetObject()
+	entries := []audit.LogEntry{}
+	queryset := audit.History(conf.GetReadDB(c.Request().Context()), instance)
+	paginate := viewset.PaginateQuerySet(&entries, queryset)
+	return c.Respond(http.StatusOK, paginate.GetPaginatedResponse())
+}
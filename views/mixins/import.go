@@ -0,0 +1,198 @@
+package mixins
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/errors"
+	"github.com/rimba47prayoga/gorim.git/parsers"
+	"github.com/rimba47prayoga/gorim.git/serializers"
+	"github.com/rimba47prayoga/gorim.git/signals"
+	"gorm.io/gorm"
+)
+
+var (
+	// ImportBatchSize is the number of valid rows inserted by each statement of Import.
+	ImportBatchSize	= 500
+	// ImportMaxRows limits rows of imported files, larger files are rejected.
+	ImportMaxRows	= 10000
+)
+
+// ImportResult is the response of Import, rows are numbered like the file, the header is row 1.
+type ImportResult struct {
+	TotalRows	int					`json:"total_rows"`
+	Imported	int					`json:"imported"`
+	Errors		[]ImportRowError	`json:"errors"`
+}
+
+type ImportRowError struct {
+	Row		int						`json:"row"`
+	Errors	errors.ValidationErrors	`json:"errors"`
+}
+
+func (r *ImportResult) addError(row int, rowErrors errors.ValidationErrors) {
+	r.Errors = append(r.Errors, ImportRowError{Row: row, Errors: rowErrors})
+}
+
+// importRow is a validated row waiting for its batch.
+type importRow[T any] struct {
+	number		int
+	serializer	serializers.IModelSerializer[T]
+}
+
+// importBuilder is implemented by serializers embedding ModelSerializer, others are
+// saved by Create one by one.
+type importBuilder[T any] interface {
+	Build() *T
+}
+
+// definesCreate reports whether serializer declares Create instead of the one of the
+// embedded ModelSerializer, methods promoted from embedded fields are wrappers the
+// compiler generates.
+func definesCreate(serializer any) bool {
+	method, ok := reflect.TypeOf(serializer).MethodByName("Create")
+	if !ok {
+		return false
+	}
+	file, _ := runtime.FuncForPC(method.Func.Pointer()).FileLine(method.Func.Pointer())
+	return file != "<autogenerated>"
+}
+
+// newImportSerializer returns empty serializer of viewset for a row.
+func newImportSerializer[T any](viewset IGenericViewSet[T], c gorim.Context) serializers.IModelSerializer[T] {
+	typ := reflect.TypeOf(viewset.GetSerializerStruct())
+	serializer := reflect.New(typ.Elem()).Interface().(serializers.IModelSerializer[T])
+	serializer.SetContext(c)
+	serializer.SetChild(serializer)
+	return serializer
+}
+
+// bindImportRow sets cells of record to serializer fields named by header, cells which
+// can't be parsed are errors of their column.
+func bindImportRow(serializer any, header []string, record []string) errors.ValidationErrors {
+	rowErrors := errors.ValidationErrors{}
+	for column, name := range header {
+		if name == "" || column >= len(record) {
+			continue
+		}
+		cell := strings.TrimSpace(record[column])
+		if err := parsers.BindValues(serializer, url.Values{name: {cell}}); err != nil {
+			rowErrors.Add(name, fmt.Sprintf("Invalid value %q.", cell))
+		}
+	}
+	return rowErrors
+}
+
+func isBlankRow(record []string) bool {
+	for _, cell := range record {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// importBatch inserts rows in one statement, when it fails rows are inserted one by one
+// so errors such as unique constraints are reported by row. Serializers declaring
+// Create save each row by it.
+func importBatch[T any](db *gorm.DB, rows []importRow[T], result *ImportResult) {
+	models := make([]*T, 0, len(rows))
+	for _, row := range rows {
+		builder, ok := row.serializer.(importBuilder[T])
+		if !ok || definesCreate(row.serializer) {
+			break
+		}
+		models = append(models, builder.Build())
+	}
+	if len(models) < len(rows) {
+		for _, row := range rows {
+			if err := importCreate(row); err != nil {
+				result.addError(row.number, importError(row.serializer.GetContext(), db, err))
+				continue
+			}
+			result.Imported++
+		}
+		return
+	}
+	// models are restored as they were before the batch since inserting may have set
+	// their primary keys.
+	saved := make([]T, len(models))
+	for i, model := range models {
+		signals.Send(signals.PreSave, rows[i].serializer.GetContext(), model, true)
+		saved[i] = *model
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		return tx.CreateInBatches(models, len(models)).Error
+	})
+	if err == nil {
+		for i, model := range models {
+			signals.Send(signals.PostSave, rows[i].serializer.GetContext(), model, true)
+		}
+		result.Imported += len(models)
+		return
+	}
+	for i, model := range models {
+		*model = saved[i]
+		context := rows[i].serializer.GetContext()
+		if err := db.Create(model).Error; err != nil {
+			result.addError(rows[i].number, importError(context, db, err))
+			continue
+		}
+		signals.Send(signals.PostSave, context, model, true)
+		result.Imported++
+	}
+}
+
+// importCreate saves row by Create of its serializer, errors it raises are returned.
+func importCreate[T any](row importRow[T]) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			raised, ok := r.(error)
+			if !ok {
+				panic(r)
+			}
+			err = raised
+		}
+	}()
+	row.serializer.Create()
+	return nil
+}
+
+// importError returns errors of row failing to save, API errors keep their message and
+// constraint errors of the database are described, other errors are reported like
+// errors.Handle reports 5xx errors and aren't exposed since they may carry SQL.
+func importError(c echo.Context, db *gorm.DB, err error) errors.ValidationErrors {
+	if validationErrors, ok := err.(errors.ValidationErrors); ok {
+		return validationErrors
+	}
+	if translator, ok := db.Dialector.(gorm.ErrorTranslator); ok {
+		err = translator.Translate(err)
+	}
+	switch {
+	case stderrors.Is(err, gorm.ErrDuplicatedKey):
+		return errors.Invalid(errors.NonFieldErrors, "An object with these values already exists.")
+	case stderrors.Is(err, gorm.ErrForeignKeyViolated):
+		return errors.Invalid(errors.NonFieldErrors, "A related object does not exist.")
+	case stderrors.Is(err, gorm.ErrCheckConstraintViolated):
+		return errors.Invalid(errors.NonFieldErrors, "The values violate a constraint.")
+	}
+	status, body := errors.Resolve(err)
+	if status >= http.StatusInternalServerError {
+		if c != nil {
+			errors.Report(err, c)
+		}
+		return errors.Invalid(errors.NonFieldErrors, "Internal server error.")
+	}
+	message, _ := body["error"].(string)
+	if message == "" {
+		message = http.StatusText(status)
+	}
+	return errors.Invalid(errors.NonFieldErrors, message)
+}
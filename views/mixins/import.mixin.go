@@ -0,0 +1,104 @@
+package mixins
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/errors"
+	"github.com/rimba47prayoga/gorim.git/parsers"
+)
+
+
+// ImportMixin creates objects from rows of CSV or XLSX file sent as "file" of multipart
+// request, the mirror of Export, route it as extra action:
+//
+//	_ routers.ActionTag `action:"Import" method:"POST"`
+//
+// The header row names serializer fields by json tag, unknown columns are ignored.
+// Each row is validated by the serializer, valid rows are inserted in batches of
+// ImportBatchSize and invalid rows are reported by their number, example:
+//
+//	{"total_rows": 3, "imported": 2, "errors": [{"row": 3, "errors": {"price": ["Invalid value \"ten\"."]}}]}
+//
+// ?dry_run=true validates the rows without inserting them.
+type ImportMixin[T any] struct {
+	GenericViewSet[T]
+}
+
+func NewImportMixin[T any](
+	genericViewSet GenericViewSet[T],
+) *ImportMixin[T] {
+	return &ImportMixin[T]{
+		GenericViewSet: genericViewSet,
+	}
+}
+
+// @Router [POST] /api/v1/{feature}/import
+func (h *ImportMixin[T]) Import(
+	c gorim.Context,
+) error {
+	var form struct {
+		File	*parsers.UploadedFile	`form:"file"`
+	}
+	if err := c.Bind(&form); err != nil {
+		return errors.Handle(bindError(err), c)
+	}
+	if form.File == nil {
+		return errors.Handle(errors.Invalid("file", "No file was submitted."), c)
+	}
+	// the header is read besides ImportMaxRows rows.
+	rows, err := parsers.ReadTable(form.File, ImportMaxRows + 1)
+	if stderrors.Is(err, parsers.ErrTooManyRows) {
+		return errors.Handle(errors.Invalid("file", fmt.Sprintf("Ensure the file has no more than %d rows.", ImportMaxRows)), c)
+	}
+	if err != nil {
+		return errors.Handle(errors.Invalid("file", "Upload a valid CSV or XLSX file."), c)
+	}
+	if len(rows) == 0 {
+		return errors.Handle(errors.Invalid("file", "The submitted file is empty."), c)
+	}
+	header := make([]string, len(rows[0]))
+	for i, name := range rows[0] {
+		header[i] = strings.TrimSpace(name)
+	}
+	dryRun, _ := strconv.ParseBool(c.QueryParam("dry_run"))
+	result := &ImportResult{Errors: []ImportRowError{}}
+	batch := []importRow[T]{}
+	for i, record := range rows[1:] {
+		if isBlankRow(record) {
+			continue
+		}
+		result.TotalRows++
+		number := i + 2
+		serializer := newImportSerializer(h.Child, c)
+		if rowErrors := bindImportRow(serializer, header, record); len(rowErrors) > 0 {
+			result.addError(number, rowErrors)
+			continue
+		}
+		if !serializer.IsValid() {
+			result.addError(number, serializer.GetErrors())
+			continue
+		}
+		if dryRun {
+			continue
+		}
+		batch = append(batch, importRow[T]{number: number, serializer: serializer})
+		if len(batch) >= ImportBatchSize {
+			importBatch(serializer.DB(), batch, result)
+			batch = batch[:0]
+		}
+	}
+	if len(batch) > 0 {
+		importBatch(batch[0].serializer.DB(), batch, result)
+	}
+	// rows of failed batches are reported after the rows validated later.
+	sort.SliceStable(result.Errors, func(i, j int) bool {
+		return result.Errors[i].Row < result.Errors[j].Row
+	})
+	return c.Respond(http.StatusOK, result)
+}
@@ -0,0 +1,69 @@
+package mixins
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/rimba47prayoga/gorim.git/routers"
+	"github.com/rimba47prayoga/gorim.git/utils"
+)
+
+// LinksKey is the key of links in objects rendered by viewsets with Links enabled.
+const LinksKey = "_links"
+
+// withLinks adds links of the actions the requester may perform on each object of data,
+// an instance or slice of instances, to result rendered from it, example:
+//
+//	{"id": 1, "_links": {"self": {"href": "/api/v1/users/1", "method": "GET"}}}
+func (h *GenericViewSet[T]) withLinks(data interface{}, result interface{}) interface{} {
+	if h.Context.Context == nil {
+		return result
+	}
+	value := reflect.ValueOf(data)
+	for value.Kind() == reflect.Ptr && value.Elem().Kind() != reflect.Struct {
+		value = value.Elem()
+	}
+	instances := []reflect.Value{value}
+	if value.Kind() == reflect.Slice {
+		instances = make([]reflect.Value, value.Len())
+		for i := range instances {
+			instances[i] = value.Index(i)
+		}
+	}
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return result
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var decoded interface{}
+	if err := decoder.Decode(&decoded); err != nil {
+		return result
+	}
+	objects, ok := decoded.([]interface{})
+	if !ok {
+		objects = []interface{}{decoded}
+	}
+	if len(objects) != len(instances) {
+		return result
+	}
+	linker := routers.GetLinker(h.Context)
+	for i, object := range objects {
+		fields, ok := object.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		instance := instances[i]
+		if instance.Kind() != reflect.Ptr && instance.CanAddr() {
+			instance = instance.Addr()
+		}
+		pk, err := utils.GetFieldValue(instance.Interface(), h.GetPKField())
+		if err != nil {
+			continue
+		}
+		fields[LinksKey] = linker.Links(instance.Interface(), fmt.Sprint(pk))
+	}
+	return decoded
+}
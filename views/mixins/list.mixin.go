@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/renderers"
 )
 
 
@@ -20,13 +21,18 @@ func NewListMixin[T any](
 }
 
 // @Router [GET] /api/v1/{feature}
+// Accept: application/x-ndjson or text/event-stream streams the results without pagination.
 func (h *ListMixin[T]) List(
 	c gorim.Context,
 ) error {
 	viewset := h.Child
+	if renderers.WantsStream(c) {
+		return viewset.StreamQuerySet(viewset.GetFilteredQuerySet())
+	}
 	results := viewset.GetModelSlice()
 	resultsAddr := results.Addr().Interface() //  its like &[]models.User
 	queryset := viewset.FilterQuerySet(resultsAddr, nil)
 	paginate := viewset.PaginateQuerySet(resultsAddr, queryset)
-	return c.JSON(http.StatusOK, paginate.GetPaginatedResponse())
+	paginate.Results = viewset.ToRepresentation(paginate.Results)
+	return c.Respond(http.StatusOK, paginate.GetPaginatedResponse())
 }
@@ -0,0 +1,43 @@
+package mixins
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/rimba47prayoga/gorim.git/errors"
+	"github.com/rimba47prayoga/gorim.git/utils"
+	"gorm.io/gorm/schema"
+)
+
+// ParseLookup converts lookup value of the path into the type of the model field,
+// malformed values such as "abc" for integer or UUID keys raise 404 since no object can match them.
+func (h *GenericViewSet[T]) ParseLookup(field string, value string) interface{} {
+	schemaField, err := utils.LookUpField(h.Model, field)
+	if err != nil {
+		errors.Raise(&errors.InternalServerError{
+			Message: fmt.Sprintf("%s: lookup field %s doesn't exist.", utils.GetStructName(h.Model), field),
+		})
+	}
+	if isUUIDField(schemaField) && !uuidPattern.MatchString(value) {
+		errors.Raise(&errors.ObjectNotFoundError{Message: "Resource not found"})
+	}
+	parsed, err := utils.ParseFieldString(h.Model, field, value)
+	if err != nil {
+		errors.Raise(&errors.ObjectNotFoundError{Message: "Resource not found"})
+	}
+	return parsed
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{12}$`)
+
+// isUUIDField reports whether string field is stored as uuid column, example: `gorm:"type:uuid"`.
+// UUID types such as uuid.UUID are validated by their UnmarshalText instead.
+func isUUIDField(field *schema.Field) bool {
+	if field.FieldType.Kind() != reflect.String {
+		return false
+	}
+	return strings.EqualFold(string(field.DataType), "uuid") ||
+		strings.EqualFold(field.TagSettings["TYPE"], "uuid")
+}
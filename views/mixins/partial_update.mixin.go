@@ -0,0 +1,36 @@
+package mixins
+
+import (
+	"net/http"
+
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/errors"
+)
+
+
+type PartialUpdateMixin[T any] struct {
+	GenericViewSet[T]
+}
+
+
+func NewPartialUpdateMixin[T any](
+	genericViewSet GenericViewSet[T],
+) *PartialUpdateMixin[T] {
+	return &PartialUpdateMixin[T]{
+		GenericViewSet: genericViewSet,
+	}
+}
+
+
+// @Router [PATCH] /api/v1/{feature}/:id
+func (h *PartialUpdateMixin[T]) PartialUpdate(
+	c gorim.Context,
+) error {
+	instance := h.Child.GetObject()
+	serializer := *h.Child.GetPartialSerializer(instance)
+	if !serializer.IsValid() {
+		return errors.Handle(serializer.GetErrors(), c)
+	}
+	data := serializer.Update(instance)
+	return c.Respond(http.StatusOK, h.Child.ToRepresentation(data))
+}
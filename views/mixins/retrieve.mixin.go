@@ -21,5 +21,5 @@ func NewRetrieveMixin[T any](
 
 func (h *RetrieveMixin[T]) Retrieve(c gorim.Context) error {
 	instance := h.Child.GetObject()
-	return c.JSON(http.StatusOK, instance)
+	return c.Respond(http.StatusOK, h.Child.ToRepresentation(instance))
 }
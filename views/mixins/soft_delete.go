@@ -0,0 +1,89 @@
+package mixins
+
+import (
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/rimba47prayoga/gorim.git/conf"
+	"github.com/rimba47prayoga/gorim.git/errors"
+	"github.com/rimba47prayoga/gorim.git/permissions"
+	"github.com/rimba47prayoga/gorim.git/utils"
+	"gorm.io/gorm"
+)
+
+// SoftDelete makes Destroy set the deleted at column instead of deleting the row,
+// deleted rows are hidden unless IncludeDeletedParam is set and true on safe requests.
+// Routers add ListDeleted at GET /deleted and Restore at POST /:pk/restore when enabled.
+type SoftDelete struct {
+	Enabled				bool
+	// Column is the deleted at column, default "deleted_at".
+	// It may be gorm.DeletedAt or nullable time such as *time.Time.
+	Column				string
+	// IncludeDeletedParam is the query param including deleted rows in List and Retrieve,
+	// empty disables it. Permissions of the viewset apply, not those guarding ListDeleted,
+	// example: "include_deleted" on viewsets of staff only.
+	IncludeDeletedParam	string
+}
+
+func (s *SoftDelete) GetColumn() string {
+	if s.Column == "" {
+		return "deleted_at"
+	}
+	return s.Column
+}
+
+var deletedAtType = reflect.TypeOf(gorm.DeletedAt{})
+
+func (h *GenericViewSet[T]) SoftDeleteEnabled() bool {
+	return h.SoftDelete != nil && h.SoftDelete.Enabled
+}
+
+// softDeleteScope hides deleted rows, ListDeleted and Restore only see deleted rows.
+func (h *GenericViewSet[T]) softDeleteScope(queryset *gorm.DB) *gorm.DB {
+	column := h.SoftDelete.GetColumn()
+	switch h.Action {
+	case "ListDeleted", "Restore":
+		return queryset.Unscoped().Where(column + " IS NOT NULL")
+	}
+	if h.includeDeleted() {
+		return queryset.Unscoped()
+	}
+	return queryset.Where(column + " IS NULL")
+}
+
+func (h *GenericViewSet[T]) includeDeleted() bool {
+	param := h.SoftDelete.IncludeDeletedParam
+	if param == "" || h.Context.Context == nil || !utils.Contains(permissions.SafeMethods, h.Context.Request().Method) {
+		return false
+	}
+	include, _ := strconv.ParseBool(h.Context.QueryParam(param))
+	return include
+}
+
+// softDelete deletes with gorm when the column is gorm.DeletedAt so delete hooks run,
+// other column types are updated directly.
+func (h *GenericViewSet[T]) softDelete(db *gorm.DB, instance *T) error {
+	column := h.SoftDelete.GetColumn()
+	statement := &gorm.Statement{DB: db}
+	if err := statement.Parse(instance); err != nil {
+		return err
+	}
+	if field := statement.Schema.LookUpField(column); field != nil && field.FieldType == deletedAtType {
+		return db.Delete(instance).Error
+	}
+	return db.Model(instance).Update(column, time.Now()).Error
+}
+
+// PerformRestore clears the deleted at column of instance.
+func (h *GenericViewSet[T]) PerformRestore(instance *T) {
+	db := conf.DB
+	if h.Context.Context != nil {
+		db = conf.GetDB(h.Context.Request().Context())
+	}
+	if err := db.Unscoped().Model(instance).Update(h.SoftDelete.GetColumn(), nil).Error; err != nil {
+		errors.Raise(&errors.InternalServerError{
+			Message: err.Error(),
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package mixins
+
+import (
+	"net/http"
+
+	"github.com/rimba47prayoga/gorim.git"
+)
+
+
+// SoftDeleteMixin lists and restores soft deleted objects, routed when SoftDelete is enabled.
+type SoftDeleteMixin[T any] struct {
+	GenericViewSet[T]
+}
+
+func NewSoftDeleteMixin[T any](
+	genericViewSet GenericViewSet[T],
+) *SoftDeleteMixin[T] {
+	return &SoftDeleteMixin[T]{
+		GenericViewSet: genericViewSet,
+	}
+}
+
+// @Router [GET] /api/v1/{feature}/deleted
+func (h *SoftDeleteMixin[T]) ListDeleted(
+	c gorim.Context,
+) error {
+	viewset := h.Child
+	results := viewset.GetModelSlice()
+	resultsAddr := results.Addr().Interface()
+	queryset := viewset.FilterQuerySet(resultsAddr, nil)
+	paginate := viewset.PaginateQuerySet(resultsAddr, queryset)
+	paginate.Results = viewset.ToRepresentation(paginate.Results)
+	return c.Respond(http.StatusOK, paginate.GetPaginatedResponse())
+}
+
+// @Router [POST] /api/v1/{feature}/:id/restore
+func (h *SoftDeleteMixin[T]) Restore(
+	c gorim.Context,
+) error {
+	viewset := h.Child
+	instance := viewset.GetObject()
+	viewset.PerformRestore(instance)
+	return c.Respond(http.StatusOK, viewset.ToRepresentation(instance))
+}
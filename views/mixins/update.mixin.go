@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/errors"
 )
 
 
@@ -28,8 +29,8 @@ func (h *UpdateMixin[T]) Update(
 	instance := h.Child.GetObject()
 	serializer := *h.Child.GetSerializer()
 	if !serializer.IsValid() {
-		return c.JSON(http.StatusBadRequest, serializer.GetErrors())
+		return errors.Handle(serializer.GetErrors(), c)
 	}
 	data := serializer.Update(instance)
-	return c.JSON(http.StatusOK, data)
+	return c.Respond(http.StatusOK, h.Child.ToRepresentation(data))
 }
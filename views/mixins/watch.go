@@ -0,0 +1,83 @@
+package mixins
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"sync"
+
+	"github.com/rimba47prayoga/gorim.git/channels"
+	"github.com/rimba47prayoga/gorim.git/signals"
+	"github.com/rimba47prayoga/gorim.git/sse"
+	"github.com/rimba47prayoga/gorim.git/utils"
+	"github.com/rimba47prayoga/gorim.git/websocket"
+)
+
+// Watch publishes changes saved and deleted by serializers and Destroy to the channel
+// layer, routers add Watch at GET /watch streaming them to clients, see WatchMixin.
+// Processes share changes only with the redis backend of channels.
+type Watch struct {
+	Enabled				bool
+	// Group of channels the changes are published to, default "watch:" + model type,
+	// example: "watch:models.Product".
+	Group				string
+	// BroadcastDeletes sends deletes of objects not sent on the connection to every
+	// watcher, deleted rows can't be checked against filters so they're only sent for
+	// objects sent before by default. Enable it only when primary keys aren't secret.
+	BroadcastDeletes	bool
+	// Stream options of server-sent events.
+	Stream				sse.Options
+	// Upgrade options of websocket handshakes.
+	Upgrade				websocket.UpgradeOptions
+}
+
+func (w *Watch) GetGroup(model any) string {
+	if w.Group != "" {
+		return w.Group
+	}
+	return "watch:" + reflect.TypeOf(model).Elem().String()
+}
+
+func (h *GenericViewSet[T]) WatchEnabled() bool {
+	return h.Realtime != nil && h.Realtime.Enabled
+}
+
+// watchChange is published for every change, watchers load the object themselves
+// so filters and permissions apply per connection.
+type watchChange struct {
+	PK	any		`json:"pk"`
+}
+
+var watchedGroups sync.Map
+
+// publishChanges connects signals of model T publishing to group once per group.
+func publishChanges[T any](group string, pkField string) {
+	if _, loaded := watchedGroups.LoadOrStore(group, true); loaded {
+		return
+	}
+	publish := func(event signals.Event[T]) {
+		if event.Instance == nil {
+			return
+		}
+		pk, err := utils.GetFieldValue(event.Instance, pkField)
+		if err != nil {
+			slog.Error("watch: primary key not found", "group", group, "error", err)
+			return
+		}
+		ctx := context.Background()
+		if event.Context != nil {
+			ctx = context.WithoutCancel(event.Context.Request().Context())
+		}
+		changeType := "updated"
+		if event.Signal == signals.PostDelete {
+			changeType = "deleted"
+		} else if event.Created {
+			changeType = "created"
+		}
+		if err := channels.Publish(ctx, group, changeType, watchChange{PK: pk}); err != nil {
+			slog.Error("watch: publishing failed", "group", group, "error", err)
+		}
+	}
+	signals.Connect(signals.PostSave, publish)
+	signals.Connect(signals.PostDelete, publish)
+}
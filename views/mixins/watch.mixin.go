@@ -0,0 +1,160 @@
+package mixins
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/channels"
+	"github.com/rimba47prayoga/gorim.git/conf"
+	"github.com/rimba47prayoga/gorim.git/interfaces"
+	"github.com/rimba47prayoga/gorim.git/sse"
+	"github.com/rimba47prayoga/gorim.git/utils"
+	"github.com/rimba47prayoga/gorim.git/websocket"
+	"gorm.io/gorm"
+)
+
+
+// WatchMixin streams changes of objects in the filtered queryset, routed when Realtime
+// is enabled. Events are named created, updated and deleted, their data is the
+// representation of the object, or {"pk": ...} when deleted. Requests upgrading to
+// websocket receive them as json messages {"type": ..., "data": ...}, others as
+// server-sent events. Objects changed out of the filters or object permissions are
+// sent as deleted when they were sent on the connection before. Objects are loaded when
+// the change is received, so clients get their current state.
+type WatchMixin[T any] struct {
+	GenericViewSet[T]
+}
+
+func NewWatchMixin[T any](
+	genericViewSet GenericViewSet[T],
+) *WatchMixin[T] {
+	return &WatchMixin[T]{
+		GenericViewSet: genericViewSet,
+	}
+}
+
+type watchMessage struct {
+	Type	string	`json:"type"`
+	Data	any		`json:"data"`
+}
+
+// @Router [GET] /api/v1/{feature}/watch
+func (h *WatchMixin[T]) Watch(
+	c gorim.Context,
+) error {
+	viewset := h.Child
+	// objects are loaded right after they're saved, replicas may not have them yet.
+	c.SetRequest(c.Request().WithContext(conf.UsePrimary(c.Request().Context())))
+	// filters are bound before the stream starts, so invalid params are answered with 400.
+	queryset := viewset.GetFilteredQuerySet().Session(&gorm.Session{})
+	group := h.Realtime.GetGroup(h.Model)
+	if strings.EqualFold(c.Request().Header.Get("Upgrade"), "websocket") {
+		handler := &websocket.Handler[[]byte]{
+			UpgradeOptions: h.Realtime.Upgrade,
+			OnOpen: func(s *websocket.Session) {
+				ctx := s.Request().Context()
+				subscription, err := channels.Subscribe(ctx, group)
+				if err != nil {
+					s.SendError(err)
+					s.Close(websocket.CloseInternalError, "")
+					return
+				}
+				go h.watch(ctx, c, queryset, subscription, func(changeType string, data any) bool {
+					return s.Send(watchMessage{Type: changeType, Data: data}) == nil
+				})
+			},
+		}
+		return handler.Serve(c)
+	}
+	return c.EventStream(func(stream *sse.Stream) error {
+		subscription, err := channels.Subscribe(stream.Context(), group)
+		if err != nil {
+			return err
+		}
+		h.watch(stream.Context(), c, queryset, subscription, func(changeType string, data any) bool {
+			// slow clients miss changes instead of holding back the subscription.
+			stream.TrySend(sse.Event{Event: changeType, Data: data})
+			return true
+		})
+		return nil
+	}, h.Realtime.Stream)
+}
+
+// watch sends changes of subscription visible to the request until ctx is done.
+func (h *WatchMixin[T]) watch(
+	ctx context.Context,
+	c gorim.Context,
+	queryset *gorm.DB,
+	subscription channels.Subscription,
+	send func(changeType string, data any) bool,
+) {
+	defer subscription.Close()
+	// primary keys of objects sent on the connection, deletes are sent only for them.
+	sent := map[string]bool{}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case message, ok := <-subscription.Messages():
+			if !ok {
+				return
+			}
+			var change struct {
+				PK	json.RawMessage	`json:"pk"`
+			}
+			if err := message.Decode(&change); err != nil {
+				continue
+			}
+			key := lookupValue(change.PK)
+			deleted := map[string]any{"pk": change.PK}
+			if message.Type != "deleted" {
+				if instance := h.load(ctx, c, queryset, key); instance != nil {
+					sent[key] = true
+					if !send(message.Type, h.Child.ToRepresentation(instance)) {
+						return
+					}
+					continue
+				}
+			}
+			if !sent[key] && !(message.Type == "deleted" && h.Realtime.BroadcastDeletes) {
+				continue
+			}
+			delete(sent, key)
+			if !send("deleted", deleted) {
+				return
+			}
+		}
+	}
+}
+
+// load returns the object of primary key when it's in queryset and allowed by object
+// permissions, nil otherwise.
+func (h *WatchMixin[T]) load(ctx context.Context, c gorim.Context, queryset *gorm.DB, key string) *T {
+	pkField := h.GetPKField()
+	pk, err := utils.ParseFieldString(h.Model, pkField, key)
+	if err != nil {
+		return nil
+	}
+	var instance T
+	if err := queryset.WithContext(ctx).Where(pkField + " = ?", pk).Take(&instance).Error; err != nil {
+		return nil
+	}
+	for _, permission := range h.Child.GetPermissions(c) {
+		objectPermission, ok := permission.(interfaces.IObjectPermission)
+		if ok && !objectPermission.HasObjectPermission(c, &instance) {
+			return nil
+		}
+	}
+	return &instance
+}
+
+// lookupValue returns primary key encoded as json in the form of path params.
+func lookupValue(raw json.RawMessage) string {
+	var value string
+	if err := json.Unmarshal(raw, &value); err == nil {
+		return value
+	}
+	return string(raw)
+}
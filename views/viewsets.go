@@ -1,8 +1,12 @@
 package views
 
 import (
+	"github.com/rimba47prayoga/gorim.git/cache"
 	"github.com/rimba47prayoga/gorim.git/filters"
 	"github.com/rimba47prayoga/gorim.git/interfaces"
+	"github.com/rimba47prayoga/gorim.git/parsers"
+	"github.com/rimba47prayoga/gorim.git/policies"
+	"github.com/rimba47prayoga/gorim.git/renderers"
 	"github.com/rimba47prayoga/gorim.git/serializers"
 	"github.com/rimba47prayoga/gorim.git/views/mixins"
 	"gorm.io/gorm"
@@ -12,8 +16,20 @@ type ModelViewSetParams[T any] struct {
 	QuerySet		*gorm.DB
 	PKField			string
 	Serializer		serializers.IModelSerializer[T]
+	VersionSerializers	map[string]serializers.IModelSerializer[T]
 	Filter			filters.IFilterSet
 	Permissions		[]interfaces.IPermission
+	Throttles		[]interfaces.IThrottle
+	Renderers		[]renderers.Renderer
+	Parsers			[]parsers.Parser
+	SoftDelete		*mixins.SoftDelete
+	Using			string
+	Policies		[]policies.Policy
+	Aggregations	map[string]mixins.Aggregation
+	CachePage		*cache.Page
+	CacheControl	*cache.Control
+	Realtime		*mixins.Watch
+	Links			bool
 	Child			mixins.IGenericViewSet[T]
 }
 
@@ -23,7 +39,21 @@ type ModelViewSet[T any] struct {
 	mixins.CreateMixin[T]
 	mixins.RetrieveMixin[T]
 	mixins.UpdateMixin[T]
+	mixins.PartialUpdateMixin[T]
+	mixins.DestroyMixin[T]
 	mixins.ListMixin[T]
+	// Export isn't routed unless declared with routers.ActionTag.
+	mixins.ExportMixin[T]
+	// Import isn't routed unless declared with routers.ActionTag.
+	mixins.ImportMixin[T]
+	// History isn't routed unless declared with routers.ActionTag.
+	mixins.HistoryMixin[T]
+	// ListDeleted and Restore are routed when SoftDelete is enabled.
+	mixins.SoftDeleteMixin[T]
+	// Aggregate is routed when Aggregations are declared.
+	mixins.AggregateMixin[T]
+	// Watch is routed when Realtime is enabled.
+	mixins.WatchMixin[T]
 	Child	mixins.IGenericViewSet[T]
 }
 
@@ -34,20 +64,48 @@ func NewModelViewSet[T any](
 		QuerySet: params.QuerySet,
 		PKField: params.PKField,
 		Serializer: params.Serializer,
+		VersionSerializers: params.VersionSerializers,
 		Filter: params.Filter,
 		Permissions: params.Permissions,
+		Throttles: params.Throttles,
+		Renderers: params.Renderers,
+		Parsers: params.Parsers,
+		SoftDelete: params.SoftDelete,
+		Using: params.Using,
+		Policies: params.Policies,
+		Aggregations: params.Aggregations,
+		CachePage: params.CachePage,
+		CacheControl: params.CacheControl,
+		Realtime: params.Realtime,
+		Links: params.Links,
 		Child: params.Child,
 	}
 	genericViewSet := mixins.NewGenericViewSet(genericViewSetParams)
 	createMixin := mixins.NewCreateMixin[T](*genericViewSet)
 	updateMixin := mixins.NewUpdateMixin[T](*genericViewSet)
 	retrieveMixin := mixins.NewRetrieveMixin[T](*genericViewSet)
+	partialUpdateMixin := mixins.NewPartialUpdateMixin[T](*genericViewSet)
+	destroyMixin := mixins.NewDestroyMixin[T](*genericViewSet)
 	listMixin := mixins.NewListMixin[T](*genericViewSet)
+	exportMixin := mixins.NewExportMixin[T](*genericViewSet)
+	importMixin := mixins.NewImportMixin[T](*genericViewSet)
+	historyMixin := mixins.NewHistoryMixin[T](*genericViewSet)
+	softDeleteMixin := mixins.NewSoftDeleteMixin[T](*genericViewSet)
+	aggregateMixin := mixins.NewAggregateMixin[T](*genericViewSet)
+	watchMixin := mixins.NewWatchMixin[T](*genericViewSet)
 	return &ModelViewSet[T]{
 		GenericViewSet: *genericViewSet,
 		CreateMixin: *createMixin,
 		UpdateMixin: *updateMixin,
 		RetrieveMixin: *retrieveMixin,
+		PartialUpdateMixin: *partialUpdateMixin,
+		DestroyMixin: *destroyMixin,
 		ListMixin: *listMixin,
+		ExportMixin: *exportMixin,
+		ImportMixin: *importMixin,
+		HistoryMixin: *historyMixin,
+		SoftDeleteMixin: *softDeleteMixin,
+		AggregateMixin: *aggregateMixin,
+		WatchMixin: *watchMixin,
 	}
 }
@@ -0,0 +1,88 @@
+package webhooks
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	StatusPending	= "pending"
+	StatusSuccess	= "success"
+	StatusRetrying	= "retrying"
+	StatusFailed	= "failed"
+)
+
+// Events are names of events a subscription receives, stored as json text. "*" matches
+// every event and "order.*" every event of the order model.
+type Events []string
+
+func (e Events) Value() (driver.Value, error) {
+	data, err := json.Marshal(e)
+	return string(data), err
+}
+
+func (e *Events) Scan(value interface{}) error {
+	switch value := value.(type) {
+	case nil:
+		*e = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(value, e)
+	case string:
+		return json.Unmarshal([]byte(value), e)
+	}
+	return fmt.Errorf("cannot convert %T to Events", value)
+}
+
+// Matches reports whether event is one of the events.
+func (e Events) Matches(event string) bool {
+	for _, pattern := range e {
+		if pattern == "*" || pattern == event {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(pattern, ".*"); ok && strings.HasPrefix(event, prefix + ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscription receives events at URL, payloads are signed with Secret.
+type Subscription struct {
+	ID			uint		`gorm:"primarykey" json:"id"`
+	URL			string		`gorm:"type:varchar(2048)" json:"url"`
+	Events		Events		`gorm:"type:text" json:"events"`
+	Secret		string		`gorm:"type:varchar(255)" json:"-"`
+	Active		bool		`gorm:"default:true" json:"active"`
+	CreatedAt	time.Time	`json:"created_at"`
+	UpdatedAt	time.Time	`json:"updated_at"`
+}
+
+func (Subscription) TableName() string {
+	return "gorim_webhook_subscriptions"
+}
+
+// Delivery logs an event sent to a subscription and the response of its last attempt.
+type Delivery struct {
+	ID				uint		`gorm:"primarykey" json:"id"`
+	SubscriptionID	uint		`gorm:"index" json:"subscription_id"`
+	Event			string		`gorm:"type:varchar(100);index" json:"event"`
+	Payload			string		`gorm:"type:text" json:"payload"`
+	Status			string		`gorm:"type:varchar(20);index" json:"status"`
+	Attempts		int			`json:"attempts"`
+	ResponseStatus	int			`json:"response_status"`
+	// ResponseBody is truncated to MaxResponseBody bytes.
+	ResponseBody	string		`gorm:"type:text" json:"response_body"`
+	Error			string		`gorm:"type:text" json:"error"`
+	// Duration of the last attempt in milliseconds.
+	Duration		int64		`json:"duration"`
+	CreatedAt		time.Time	`json:"created_at"`
+	DeliveredAt		*time.Time	`json:"delivered_at"`
+}
+
+func (Delivery) TableName() string {
+	return "gorim_webhook_deliveries"
+}
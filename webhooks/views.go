@@ -0,0 +1,90 @@
+package webhooks
+
+import (
+	"net/http"
+
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/filters"
+	"github.com/rimba47prayoga/gorim.git/interfaces"
+	"github.com/rimba47prayoga/gorim.git/permissions"
+	"github.com/rimba47prayoga/gorim.git/routers"
+	"github.com/rimba47prayoga/gorim.git/serializers"
+	"github.com/rimba47prayoga/gorim.git/views"
+	"github.com/rimba47prayoga/gorim.git/views/mixins"
+)
+
+// Permissions of the viewsets, default admin users.
+var Permissions = []interfaces.IPermission{&permissions.IsAdminUser{}}
+
+type SubscriptionSerializer struct {
+	serializers.ModelSerializer[Subscription]
+	URL			string		`validate:"required,url" json:"url"`
+	Events		Events		`validate:"required,min=1" json:"events"`
+	// Secret is written, never rendered.
+	Secret		string		`validate:"required,min=16" json:"secret"`
+	Active		bool		`json:"active"`
+}
+
+// SubscriptionViewSet manages subscriptions.
+type SubscriptionViewSet struct {
+	views.ModelViewSet[Subscription]
+}
+
+func NewSubscriptionViewSet() *SubscriptionViewSet {
+	viewset := &SubscriptionViewSet{}
+	viewset.ModelViewSet = *views.NewModelViewSet(views.ModelViewSetParams[Subscription]{
+		Serializer: &SubscriptionSerializer{},
+		Permissions: Permissions,
+		Child: viewset,
+	})
+	return viewset
+}
+
+type DeliverySerializer struct {
+	serializers.ModelSerializer[Delivery]
+}
+
+type DeliveryFilter struct {
+	filters.FilterSet
+	SubscriptionID	*uint		`query:"subscription_id" db:"subscription_id" operator:"eq"`
+	Event			*string		`query:"event" db:"event" operator:"eq"`
+	Status			*string		`query:"status" db:"status" operator:"eq"`
+}
+
+// DeliveryViewSet lists delivery logs and redelivers them, ?sort=-id lists newest first.
+type DeliveryViewSet struct {
+	mixins.GenericViewSet[Delivery]
+	mixins.ListMixin[Delivery]
+	mixins.RetrieveMixin[Delivery]
+	_	routers.ActionTag	`action:"Redeliver" method:"POST" detail:"true"`
+}
+
+func NewDeliveryViewSet() *DeliveryViewSet {
+	viewset := &DeliveryViewSet{}
+	genericViewSet := mixins.NewGenericViewSet(mixins.GenericViewSetParams[Delivery]{
+		Serializer: &DeliverySerializer{},
+		Filter: &DeliveryFilter{},
+		Permissions: Permissions,
+		Child: viewset,
+	})
+	viewset.GenericViewSet = *genericViewSet
+	viewset.ListMixin = *mixins.NewListMixin(*genericViewSet)
+	viewset.RetrieveMixin = *mixins.NewRetrieveMixin(*genericViewSet)
+	return viewset
+}
+
+// Redeliver enqueues the delivery again.
+func (h *DeliveryViewSet) Redeliver(c gorim.Context) error {
+	delivery := h.GetObject()
+	if err := Redeliver(c.Request().Context(), delivery); err != nil {
+		return err
+	}
+	return c.Respond(http.StatusAccepted, delivery)
+}
+
+// Routes mounts subscriptions and deliveries under group, example:
+// webhooks.Routes(api.Group("/webhooks"))
+func Routes(group *gorim.Group) {
+	routers.NewDefaultRouter(group.Group("/subscriptions"), NewSubscriptionViewSet)
+	routers.NewDefaultRouter(group.Group("/deliveries"), NewDeliveryViewSet)
+}
@@ -0,0 +1,282 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/rimba47prayoga/gorim.git/conf"
+	"github.com/rimba47prayoga/gorim.git/signals"
+	"github.com/rimba47prayoga/gorim.git/tasks"
+	"gorm.io/gorm"
+)
+
+const (
+	EventHeader		= "X-Webhook-Event"
+	DeliveryHeader	= "X-Webhook-Delivery"
+	// SignatureHeader is "t=<unix timestamp>,v1=<hex hmac>", see Sign.
+	SignatureHeader	= "X-Webhook-Signature"
+)
+
+var (
+	// MaxAttempts of a delivery, retries back off exponentially by tasks.DefaultBackoff.
+	MaxAttempts		= 8
+	// Timeout of a request to a subscription.
+	Timeout			= 10 * time.Second
+	// MaxResponseBody is how many bytes of responses are logged.
+	MaxResponseBody	= 4096
+	// AllowPrivateNetworks lets subscriptions receive at loopback, private and link-local
+	// addresses, example: receivers of development on localhost.
+	AllowPrivateNetworks	= false
+	// Client posts deliveries, it refuses redirects and, unless AllowPrivateNetworks,
+	// connections to internal addresses, so subscription urls can't reach internal services.
+	Client			= &http.Client{
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			DialContext: (&net.Dialer{Timeout: 30 * time.Second, Control: control}).DialContext,
+			ForceAttemptHTTP2: true,
+			MaxIdleConns: 100,
+			IdleConnTimeout: 90 * time.Second,
+			TLSHandshakeTimeout: 10 * time.Second,
+		},
+		CheckRedirect: func(request *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+)
+
+var errInternalAddress = stderrors.New("webhooks: subscription resolves to an internal address")
+
+// control rejects connections to internal addresses, it runs after names are resolved
+// so names resolving to them are rejected too.
+func control(network string, address string, conn syscall.RawConn) error {
+	if AllowPrivateNetworks {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	ip = ip.Unmap()
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsUnspecified() || ip.IsMulticast() || ip.IsInterfaceLocalMulticast() {
+		return errInternalAddress
+	}
+	return nil
+}
+
+// Payload is the json body posted to subscriptions.
+type Payload struct {
+	ID			uint		`json:"id"`
+	Event		string		`json:"event"`
+	CreatedAt	time.Time	`json:"created_at"`
+	Data		any			`json:"data"`
+}
+
+// deliverTask posts a delivery by id, attempts are counted by the delivery so the task
+// is retried until MaxAttempts.
+var deliverTask = tasks.Register("gorim.webhooks.deliver", func(ctx context.Context, id uint) error {
+	return Deliver(ctx, id)
+}, tasks.Options{MaxRetries: 1 << 10})
+
+// Register dispatches "<name>.created", "<name>.updated" and "<name>.deleted" events of
+// model T saved and deleted by serializers and viewsets, the instance as json is the data
+// of the payload, example: webhooks.Register[Order]("order")
+func Register[T any](name string) {
+	signals.Connect(signals.PostSave, func(event signals.Event[T]) {
+		action := "updated"
+		if event.Created {
+			action = "created"
+		}
+		dispatch(event, name + "." + action)
+	})
+	signals.Connect(signals.PostDelete, func(event signals.Event[T]) {
+		dispatch(event, name + ".deleted")
+	})
+}
+
+func dispatch[T any](event signals.Event[T], name string) {
+	ctx := context.Background()
+	if event.Context != nil {
+		ctx = context.WithoutCancel(event.Context.Request().Context())
+	}
+	if _, err := Dispatch(ctx, name, event.Instance); err != nil {
+		slog.Error("webhooks: dispatch failed", "event", name, "error", err)
+	}
+}
+
+// Dispatch logs a delivery of event for every active subscription receiving it and
+// enqueues them, the worker posts them, example for events which aren't model changes:
+//
+//	webhooks.Dispatch(ctx, "invoice.paid", invoice)
+func Dispatch(ctx context.Context, event string, data any) ([]Delivery, error) {
+	db := conf.GetDB(ctx)
+	var subscriptions []Subscription
+	if err := db.Where("active = ?", true).Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	deliveries := []Delivery{}
+	for _, subscription := range subscriptions {
+		if !subscription.Events.Matches(event) {
+			continue
+		}
+		delivery := Delivery{
+			SubscriptionID: subscription.ID,
+			Event: event,
+			Status: StatusPending,
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&delivery).Error; err != nil {
+				return err
+			}
+			body, err := json.Marshal(Payload{ID: delivery.ID, Event: event, CreatedAt: delivery.CreatedAt, Data: data})
+			if err != nil {
+				return err
+			}
+			delivery.Payload = string(body)
+			return tx.Model(&delivery).Update("payload", delivery.Payload).Error
+		})
+		if err != nil {
+			return deliveries, err
+		}
+		if _, err := deliverTask.Enqueue(ctx, delivery.ID); err != nil {
+			return deliveries, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, nil
+}
+
+// Redeliver enqueues delivery again with its attempts reset.
+func Redeliver(ctx context.Context, delivery *Delivery) error {
+	err := conf.GetDB(ctx).Model(delivery).Updates(map[string]interface{}{
+		"status": StatusPending,
+		"attempts": 0,
+	}).Error
+	if err != nil {
+		return err
+	}
+	_, err = deliverTask.Enqueue(ctx, delivery.ID)
+	return err
+}
+
+// Deliver posts the delivery to its subscription and logs the response, an error is
+// returned to retry it until MaxAttempts.
+func Deliver(ctx context.Context, id uint) error {
+	db := conf.GetDB(ctx)
+	var delivery Delivery
+	if err := db.First(&delivery, id).Error; err != nil {
+		return err
+	}
+	if delivery.Status == StatusSuccess || delivery.Status == StatusFailed {
+		return nil
+	}
+	var subscription Subscription
+	if err := db.First(&subscription, delivery.SubscriptionID).Error; err != nil {
+		return err
+	}
+	start := time.Now()
+	status, body, err := post(ctx, &subscription, &delivery)
+	delivery.Attempts++
+	delivery.ResponseStatus = status
+	delivery.ResponseBody = body
+	delivery.Duration = time.Since(start).Milliseconds()
+	delivery.Error = ""
+	if err == nil && (status < 200 || status >= 300) {
+		err = fmt.Errorf("subscription responded %d", status)
+	}
+	switch {
+	case err == nil:
+		now := time.Now()
+		delivery.Status = StatusSuccess
+		delivery.DeliveredAt = &now
+	case delivery.Attempts >= MaxAttempts || !subscription.Active:
+		delivery.Status = StatusFailed
+		delivery.Error = err.Error()
+	default:
+		delivery.Status = StatusRetrying
+		delivery.Error = err.Error()
+	}
+	if saveErr := db.Save(&delivery).Error; saveErr != nil {
+		return saveErr
+	}
+	if delivery.Status == StatusRetrying {
+		return err
+	}
+	return nil
+}
+
+func post(ctx context.Context, subscription *Subscription, delivery *Delivery) (int, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader([]byte(delivery.Payload)))
+	if err != nil {
+		return 0, "", err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "gorim-webhooks")
+	request.Header.Set(EventHeader, delivery.Event)
+	request.Header.Set(DeliveryHeader, strconv.FormatUint(uint64(delivery.ID), 10))
+	request.Header.Set(SignatureHeader, Sign(subscription.Secret, time.Now(), []byte(delivery.Payload)))
+	response, err := Client.Do(request)
+	if err != nil {
+		return 0, "", err
+	}
+	defer response.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(response.Body, int64(MaxResponseBody)))
+	return response.StatusCode, string(body), nil
+}
+
+// Sign returns the signature header of body sent at timestamp, v1 is hex of HMAC-SHA256
+// of "<timestamp>.<body>" with secret.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	unix := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + unix + ",v1=" + signature(secret, unix, body)
+}
+
+func signature(secret string, unix string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unix + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks signature header of body for receivers, timestamps older than tolerance
+// are rejected against replays.
+func Verify(secret string, header string, body []byte, tolerance time.Duration) bool {
+	var unix, sent string
+	for _, part := range bytes.Split([]byte(header), []byte(",")) {
+		key, value, _ := bytes.Cut(part, []byte("="))
+		switch string(key) {
+		case "t":
+			unix = string(value)
+		case "v1":
+			sent = string(value)
+		}
+	}
+	seconds, err := strconv.ParseInt(unix, 10, 64)
+	if err != nil || sent == "" {
+		return false
+	}
+	if age := time.Since(time.Unix(seconds, 0)); age > tolerance || age < -tolerance {
+		return false
+	}
+	return hmac.Equal([]byte(sent), []byte(signature(secret, unix, body)))
+}
@@ -0,0 +1,389 @@
+package websocket
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+type MessageType int
+
+const (
+	TextMessage		MessageType	= 1
+	BinaryMessage	MessageType	= 2
+	CloseMessage	MessageType	= 8
+	PingMessage		MessageType	= 9
+	PongMessage		MessageType	= 10
+)
+
+// Close codes of RFC 6455.
+const (
+	CloseNormal				= 1000
+	CloseGoingAway			= 1001
+	CloseProtocolError		= 1002
+	CloseUnsupportedData	= 1003
+	CloseNoStatus			= 1005
+	CloseAbnormal			= 1006
+	CloseInvalidPayload		= 1007
+	ClosePolicyViolation	= 1008
+	CloseMessageTooBig		= 1009
+	CloseInternalError		= 1011
+)
+
+// acceptGUID is appended to Sec-WebSocket-Key to compute Sec-WebSocket-Accept.
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// DefaultReadLimit of messages of connections and handlers not setting ReadLimit.
+const DefaultReadLimit int64 = 64 << 10
+
+// ErrBadHandshake is returned by Upgrade for requests which aren't websocket handshakes.
+var ErrBadHandshake = errors.New("websocket: bad handshake")
+
+// CloseError is returned by ReadMessage when the peer closes the connection.
+type CloseError struct {
+	Code	int
+	Reason	string
+}
+
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("websocket: closed %d %s", e.Code, e.Reason)
+}
+
+// UpgradeOptions of the handshake.
+type UpgradeOptions struct {
+	// Origins allowed to connect, "*" allows any origin. Empty allows requests without
+	// Origin header and requests whose origin has the host of the request, browsers
+	// send cookies with cross origin handshakes, so don't allow origins blindly.
+	Origins			[]string
+	// Subprotocols supported by the server in order of preference.
+	Subprotocols	[]string
+}
+
+// Upgrade completes the websocket handshake of the request and hijacks its connection,
+// the response must not be written before.
+func Upgrade(w http.ResponseWriter, r *http.Request, options UpgradeOptions) (*Conn, error) {
+	if r.Method != http.MethodGet ||
+		!headerContains(r.Header, "Connection", "upgrade") ||
+		!headerContains(r.Header, "Upgrade", "websocket") {
+		return nil, fmt.Errorf("%w: not a websocket upgrade request", ErrBadHandshake)
+	}
+	if r.Header.Get("Sec-Websocket-Version") != "13" {
+		return nil, fmt.Errorf("%w: unsupported version", ErrBadHandshake)
+	}
+	key := r.Header.Get("Sec-Websocket-Key")
+	if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != 16 {
+		return nil, fmt.Errorf("%w: invalid Sec-WebSocket-Key", ErrBadHandshake)
+	}
+	if !checkOrigin(r, options.Origins) {
+		return nil, fmt.Errorf("%w: origin not allowed", ErrBadHandshake)
+	}
+	subprotocol := selectSubprotocol(r, options.Subprotocols)
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, errors.New("websocket: response doesn't support hijacking")
+	}
+	netConn, buffered, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + acceptKey(key) + "\r\n"
+	if subprotocol != "" {
+		response += "Sec-WebSocket-Protocol: " + subprotocol + "\r\n"
+	}
+	netConn.SetDeadline(time.Time{})
+	if _, err := netConn.Write([]byte(response + "\r\n")); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+	return &Conn{conn: netConn, reader: buffered.Reader, subprotocol: subprotocol}, nil
+}
+
+func acceptKey(key string) string {
+	hash := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// headerContains reports whether comma separated values of header have token.
+func headerContains(header http.Header, name string, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func checkOrigin(r *http.Request, origins []string) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	for _, allowed := range origins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	if len(origins) > 0 {
+		return false
+	}
+	parsed, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(parsed.Host, r.Host)
+}
+
+func selectSubprotocol(r *http.Request, supported []string) string {
+	requested := []string{}
+	for _, value := range r.Header.Values("Sec-Websocket-Protocol") {
+		for _, part := range strings.Split(value, ",") {
+			requested = append(requested, strings.TrimSpace(part))
+		}
+	}
+	for _, subprotocol := range supported {
+		for _, value := range requested {
+			if value == subprotocol {
+				return subprotocol
+			}
+		}
+	}
+	return ""
+}
+
+// Conn is a server side websocket connection, one goroutine may read while others write.
+type Conn struct {
+	conn			net.Conn
+	reader			*bufio.Reader
+	subprotocol		string
+	// ReadLimit is the maximum size of a message in bytes, zero is DefaultReadLimit and
+	// negative doesn't limit.
+	ReadLimit		int64
+	// ReadTimeout is how long ReadMessage waits for a frame, zero waits forever.
+	ReadTimeout		time.Duration
+	// WriteTimeout of a frame, zero waits forever.
+	WriteTimeout	time.Duration
+	// OnPong is called with pongs of the peer.
+	OnPong			func(data []byte)
+	writeMu			sync.Mutex
+	closeSent		bool
+}
+
+// Subprotocol returns the subprotocol selected in the handshake.
+func (c *Conn) Subprotocol() string {
+	return c.subprotocol
+}
+
+func (c *Conn) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+func (c *Conn) getReadLimit() int64 {
+	if c.ReadLimit == 0 {
+		return DefaultReadLimit
+	}
+	return c.ReadLimit
+}
+
+// ReadMessage returns the next text or binary message, pings are answered and close
+// frames are acknowledged and returned as *CloseError.
+func (c *Conn) ReadMessage() (MessageType, []byte, error) {
+	var messageType MessageType
+	var message []byte
+	for {
+		// after close is sent the deadline of the closing handshake is kept.
+		if c.ReadTimeout > 0 && !c.closing() {
+			c.conn.SetReadDeadline(time.Now().Add(c.ReadTimeout))
+		}
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+		switch opcode {
+		case PingMessage:
+			if err := c.WriteControl(PongMessage, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case PongMessage:
+			if c.OnPong != nil {
+				c.OnPong(payload)
+			}
+			continue
+		case CloseMessage:
+			closeErr := &CloseError{Code: CloseNoStatus}
+			if len(payload) >= 2 {
+				closeErr.Code = int(binary.BigEndian.Uint16(payload))
+				closeErr.Reason = string(payload[2:])
+			}
+			c.WriteClose(closeErr.Code, "")
+			return 0, nil, closeErr
+		case TextMessage, BinaryMessage:
+			if messageType != 0 {
+				return 0, nil, c.fail(CloseProtocolError, "message not finished")
+			}
+			messageType = opcode
+		case 0:
+			if messageType == 0 {
+				return 0, nil, c.fail(CloseProtocolError, "continuation without message")
+			}
+		default:
+			return 0, nil, c.fail(CloseProtocolError, "unknown opcode")
+		}
+		if limit := c.getReadLimit(); limit > 0 && int64(len(message) + len(payload)) > limit {
+			return 0, nil, c.fail(CloseMessageTooBig, "message too big")
+		}
+		message = append(message, payload...)
+		if fin {
+			if messageType == TextMessage && !utf8.Valid(message) {
+				return 0, nil, c.fail(CloseInvalidPayload, "invalid utf-8")
+			}
+			return messageType, message, nil
+		}
+	}
+}
+
+// readFrame reads one frame, client frames must be masked.
+func (c *Conn) readFrame() (bool, MessageType, []byte, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(c.reader, header); err != nil {
+		return false, 0, nil, err
+	}
+	fin := header[0] & 0x80 != 0
+	opcode := MessageType(header[0] & 0x0f)
+	if header[0] & 0x70 != 0 {
+		return false, 0, nil, c.fail(CloseProtocolError, "reserved bits set")
+	}
+	if header[1] & 0x80 == 0 {
+		return false, 0, nil, c.fail(CloseProtocolError, "frame not masked")
+	}
+	length := uint64(header[1] & 0x7f)
+	control := opcode >= CloseMessage
+	if control && (!fin || length > 125) {
+		return false, 0, nil, c.fail(CloseProtocolError, "invalid control frame")
+	}
+	switch length {
+	case 126:
+		extended := make([]byte, 2)
+		if _, err := io.ReadFull(c.reader, extended); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(extended))
+	case 127:
+		extended := make([]byte, 8)
+		if _, err := io.ReadFull(c.reader, extended); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(extended)
+	}
+	if limit := c.getReadLimit(); limit > 0 && length > uint64(limit) {
+		return false, 0, nil, c.fail(CloseMessageTooBig, "message too big")
+	}
+	mask := make([]byte, 4)
+	if _, err := io.ReadFull(c.reader, mask); err != nil {
+		return false, 0, nil, err
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		return false, 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i % 4]
+	}
+	return fin, opcode, payload, nil
+}
+
+// fail closes the connection with code after a protocol violation of the peer.
+func (c *Conn) fail(code int, reason string) error {
+	c.WriteClose(code, reason)
+	return &CloseError{Code: code, Reason: reason}
+}
+
+// WriteMessage writes a text or binary message in one frame.
+func (c *Conn) WriteMessage(messageType MessageType, data []byte) error {
+	if messageType != TextMessage && messageType != BinaryMessage {
+		return fmt.Errorf("websocket: invalid message type %d", messageType)
+	}
+	return c.writeFrame(messageType, data)
+}
+
+// WriteControl writes a ping or pong with data of at most 125 bytes.
+func (c *Conn) WriteControl(messageType MessageType, data []byte) error {
+	if messageType != PingMessage && messageType != PongMessage {
+		return fmt.Errorf("websocket: invalid control type %d", messageType)
+	}
+	if len(data) > 125 {
+		return errors.New("websocket: control data too long")
+	}
+	return c.writeFrame(messageType, data)
+}
+
+// WriteClose starts the closing handshake with code, it's sent once and later writes fail.
+func (c *Conn) WriteClose(code int, reason string) error {
+	payload := []byte{}
+	if code != CloseNoStatus {
+		payload = binary.BigEndian.AppendUint16(payload, uint16(code))
+		if len(reason) > 123 {
+			reason = reason[:123]
+		}
+		payload = append(payload, reason...)
+	}
+	return c.writeFrame(CloseMessage, payload)
+}
+
+var errCloseSent = errors.New("websocket: close sent")
+
+func (c *Conn) closing() bool {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.closeSent
+}
+
+func (c *Conn) writeFrame(opcode MessageType, data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.closeSent {
+		return errCloseSent
+	}
+	if opcode == CloseMessage {
+		c.closeSent = true
+	}
+	frame := []byte{0x80 | byte(opcode)}
+	switch length := len(data); {
+	case length < 126:
+		frame = append(frame, byte(length))
+	case length <= 0xffff:
+		frame = append(frame, 126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(length))
+	default:
+		frame = append(frame, 127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(length))
+	}
+	if c.WriteTimeout > 0 {
+		c.conn.SetWriteDeadline(time.Now().Add(c.WriteTimeout))
+	}
+	_, err := c.conn.Write(append(frame, data...))
+	return err
+}
+
+// SetReadDeadline limits the current read, see net.Conn.
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	return c.conn.SetReadDeadline(t)
+}
+
+// Close closes the connection without the closing handshake, see WriteClose.
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
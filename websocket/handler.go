@@ -0,0 +1,331 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/rimba47prayoga/gorim.git"
+	"github.com/rimba47prayoga/gorim.git/errors"
+	"github.com/rimba47prayoga/gorim.git/i18n"
+	"github.com/rimba47prayoga/gorim.git/interfaces"
+	"github.com/rimba47prayoga/gorim.git/permissions"
+	"github.com/rimba47prayoga/gorim.git/serializers"
+)
+
+// Session is a connection with the context of the request which opened it, the user
+// set by authentication and values set by handlers are kept for the connection.
+// Request().Context() is canceled when the connection closes.
+type Session struct {
+	gorim.Context
+	ID		string
+	conn	*Conn
+}
+
+// Conn returns the connection for raw messages.
+func (s *Session) Conn() *Conn {
+	return s.conn
+}
+
+// Send writes v as json text message.
+func (s *Session) Send(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return s.conn.WriteMessage(TextMessage, data)
+}
+
+// SendError writes err as json text message with the body of the API response of err,
+// unexpected errors are logged and sent without details.
+func (s *Session) SendError(err error) error {
+	status, body := errors.Resolve(err)
+	if status >= http.StatusInternalServerError {
+		slog.Error("websocket: message failed", "path", s.Request().URL.Path, "error", err)
+	}
+	return s.Send(errors.Localize(err, body, s.Context))
+}
+
+// Close starts the closing handshake, the connection is closed when the client
+// acknowledges it or after CloseTimeout.
+func (s *Session) Close(code int, reason string) error {
+	err := s.conn.WriteClose(code, reason)
+	s.conn.SetReadDeadline(time.Now().Add(CloseTimeout))
+	return err
+}
+
+// CloseTimeout is how long closing handshakes wait for the client.
+var CloseTimeout = 5 * time.Second
+
+var validate = validator.New()
+
+// Handler serves websocket connections of messages In decoded from json and validated by
+// its validate tags, authentication middleware of the route applies to the handshake,
+// example:
+//
+//	chat := &websocket.Handler[ChatMessage]{
+//		Permissions: []interfaces.IPermission{&permissions.IsAuthenticated{}},
+//		OnMessage: func(s *websocket.Session, message ChatMessage) error {
+//			return s.Send(Reply{Text: message.Text})
+//		},
+//	}
+//	chat.Register(api, "/chat")
+//
+// Errors of OnConnect reject the connection with the API response of the error, errors
+// of OnMessage are sent to the client as json and the connection stays open.
+type Handler[In any] struct {
+	Permissions		[]interfaces.IPermission
+	Throttles		[]interfaces.IThrottle
+	UpgradeOptions
+	// ReadLimit of a message in bytes, default 64KB.
+	ReadLimit		int64
+	// PingInterval of the server, connections not answering in two intervals are closed,
+	// default 30 seconds.
+	PingInterval	time.Duration
+	// WriteTimeout of a message, default 10 seconds.
+	WriteTimeout	time.Duration
+	// OnConnect is called before the handshake completes.
+	OnConnect		func(s *Session) error
+	// OnOpen is called after the handshake before messages are read, messages can be sent.
+	OnOpen			func(s *Session)
+	OnMessage		func(s *Session, message In) error
+	// OnClose is called after the connection closed, err is nil on normal closure.
+	OnClose			func(s *Session, err error)
+	mu				sync.Mutex
+	sessions		map[*Session]struct{}
+}
+
+func (h *Handler[In]) GetReadLimit() int64 {
+	if h.ReadLimit > 0 {
+		return h.ReadLimit
+	}
+	return DefaultReadLimit
+}
+
+func (h *Handler[In]) GetPingInterval() time.Duration {
+	if h.PingInterval > 0 {
+		return h.PingInterval
+	}
+	return 30 * time.Second
+}
+
+func (h *Handler[In]) GetWriteTimeout() time.Duration {
+	if h.WriteTimeout > 0 {
+		return h.WriteTimeout
+	}
+	return 10 * time.Second
+}
+
+// Register adds route of the handler to group.
+func (h *Handler[In]) Register(group *gorim.Group, path string) {
+	group.Add(http.MethodGet, path, h.Serve)
+}
+
+// Serve checks permissions and throttles of the handshake, upgrades the connection and
+// reads messages until it closes.
+func (h *Handler[In]) Serve(c gorim.Context) error {
+	for _, permission := range h.Permissions {
+		if !permission.HasPermission(c) {
+			return permissions.Deny(c, permission)
+		}
+	}
+	for _, throttle := range h.Throttles {
+		if allowed, wait := throttle.AllowRequest(c); !allowed {
+			return errors.Handle(&errors.ThrottledError{Wait: wait}, c)
+		}
+	}
+	ctx, cancel := context.WithCancel(context.WithoutCancel(c.Request().Context()))
+	c.SetRequest(c.Request().WithContext(ctx))
+	session := &Session{Context: c, ID: uuid.NewString()}
+	defer cancel()
+	if h.OnConnect != nil {
+		if err := h.OnConnect(session); err != nil {
+			return errors.Handle(err, c)
+		}
+	}
+	conn, err := Upgrade(c.Response(), c.Request(), h.UpgradeOptions)
+	if err != nil {
+		if stderrors.Is(err, ErrBadHandshake) {
+			return errors.Handle(&errors.APIError{Status: http.StatusBadRequest, Message: err.Error(), Code: "bad_handshake"}, c)
+		}
+		return err
+	}
+	// the response is hijacked, status is set for access logs.
+	c.Response().Status = http.StatusSwitchingProtocols
+	c.Response().Committed = true
+	conn.ReadLimit = h.GetReadLimit()
+	conn.ReadTimeout = 2 * h.GetPingInterval()
+	conn.WriteTimeout = h.GetWriteTimeout()
+	session.conn = conn
+	h.track(session, true)
+	defer h.track(session, false)
+	go h.ping(ctx, conn)
+	if h.OnOpen != nil {
+		h.OnOpen(session)
+	}
+	err = h.read(session)
+	conn.Close()
+	cancel()
+	var closeErr *CloseError
+	if stderrors.As(err, &closeErr) && (closeErr.Code == CloseNormal || closeErr.Code == CloseGoingAway || closeErr.Code == CloseNoStatus) {
+		err = nil
+	}
+	if h.OnClose != nil {
+		h.OnClose(session, err)
+	}
+	return nil
+}
+
+func (h *Handler[In]) read(session *Session) error {
+	for {
+		_, data, err := session.conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+		var message In
+		if err := h.decode(session, data, &message); err != nil {
+			if err := session.SendError(err); err != nil {
+				return err
+			}
+			continue
+		}
+		if h.OnMessage == nil {
+			continue
+		}
+		if err := h.handle(session, message); err != nil {
+			var closeErr *CloseError
+			if stderrors.As(err, &closeErr) {
+				session.Close(closeErr.Code, closeErr.Reason)
+				continue
+			}
+			if err := session.SendError(err); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// handle calls OnMessage, panics of errors.Raise are returned as errors.
+func (h *Handler[In]) handle(session *Session, message In) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			if raised, ok := recovered.(error); ok {
+				err = raised
+				return
+			}
+			slog.Error("websocket: handler panicked", "panic", recovered)
+			err = &errors.PanicError{Value: recovered}
+		}
+	}()
+	return h.OnMessage(session, message)
+}
+
+// decode unmarshals data into message, []byte and string messages receive data as is.
+func (h *Handler[In]) decode(session *Session, data []byte, message *In) error {
+	switch target := any(message).(type) {
+	case *[]byte:
+		*target = data
+		return nil
+	case *string:
+		*target = string(data)
+		return nil
+	}
+	if err := json.Unmarshal(data, message); err != nil {
+		return &errors.APIError{Status: http.StatusBadRequest, Message: "Invalid message: " + err.Error(), Code: "invalid_message"}
+	}
+	value := reflect.Indirect(reflect.ValueOf(message))
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+	if err := validate.Struct(message); err != nil {
+		return serializers.ToValidationErrors(value.Type(), err, i18n.Language(session.Context))
+	}
+	return nil
+}
+
+func (h *Handler[In]) ping(ctx context.Context, conn *Conn) {
+	ticker := time.NewTicker(h.GetPingInterval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := conn.WriteControl(PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (h *Handler[In]) track(session *Session, open bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.sessions == nil {
+		h.sessions = map[*Session]struct{}{}
+	}
+	if open {
+		h.sessions[session] = struct{}{}
+	} else {
+		delete(h.sessions, session)
+	}
+}
+
+// Sessions returns open connections of the handler.
+func (h *Handler[In]) Sessions() []*Session {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	sessions := make([]*Session, 0, len(h.sessions))
+	for session := range h.sessions {
+		sessions = append(sessions, session)
+	}
+	return sessions
+}
+
+// Shutdown closes open connections with CloseGoingAway and waits for them until ctx is
+// done, hijacked connections aren't closed by the server, example:
+// server.OnShutdown(chat.Shutdown)
+func (h *Handler[In]) Shutdown(ctx context.Context) error {
+	for _, session := range h.Sessions() {
+		session.Close(CloseGoingAway, "server shutting down")
+	}
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for len(h.Sessions()) > 0 {
+		select {
+		case <-ctx.Done():
+			for _, session := range h.Sessions() {
+				session.conn.Close()
+			}
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// TokenFromQuery sets Authorization header of handshakes from query param, browsers can't
+// send headers with websockets, add it before authentication of the route, example:
+// api.Group("/ws", websocket.TokenFromQuery("token", "Bearer"), authentication.Chain(jwtAuth))
+// URLs are logged by proxies, prefer short lived tokens.
+func TokenFromQuery(param string, prefix string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			request := c.Request()
+			token := c.QueryParam(param)
+			if token != "" && request.Header.Get(echo.HeaderAuthorization) == "" && headerContains(request.Header, "Upgrade", "websocket") {
+				request.Header.Set(echo.HeaderAuthorization, strings.TrimSpace(prefix + " " + token))
+			}
+			return next(c)
+		}
+	}
+}